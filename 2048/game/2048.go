@@ -0,0 +1,488 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MODE      = "classic"
+	TOPSCORES = 5 // on the game over screen
+
+	// Tiles are TILEWIDTH by TILEHEIGHT cells with GAP columns and a row
+	// between them.
+	TILEWIDTH  = 7
+	TILEHEIGHT = 3
+	GAP        = 2
+
+	ANIMTICK    = 25 * time.Millisecond
+	SLIDEFRAMES = 4 // frames tiles take to slide into place
+	POPFRAMES   = 3 // frames merged and new tiles stay highlighted
+)
+
+var (
+	boardWidth  = SIZE*TILEWIDTH + (SIZE+1)*GAP
+	boardHeight = SIZE*TILEHEIGHT + SIZE + 1
+)
+
+// tileColors are the background and foreground of every tile up to GOAL.
+// Larger tiles share the last.
+var tileColors = []struct{ bg, fg lipgloss.Color }{
+	{"230", "239"}, // 2
+	{"229", "239"}, // 4
+	{"215", "15"},  // 8
+	{"209", "15"},  // 16
+	{"203", "15"},  // 32
+	{"196", "15"},  // 64
+	{"222", "15"},  // 128
+	{"221", "15"},  // 256
+	{"220", "15"},  // 512
+	{"214", "15"},  // 1024
+	{"208", "15"},  // 2048
+	{"235", "15"},  // beyond
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TileStyle  lipgloss.Style // colored per value, see tileColors
+	EmptyStyle lipgloss.Style
+	BoardStyle lipgloss.Style
+	FlashStyle lipgloss.Style // merged and new tiles, for a few frames
+	ScoreStyle lipgloss.Style
+	QuitStyle  lipgloss.Style
+	BoxStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, keeps the player's runs, which is where their best
+	// score is loaded from.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	best        int // before this run
+	top         []leaderboard.Entry
+
+	board     Board
+	score     int
+	undo      Board // before the last move
+	undoScore int
+	canUndo   bool
+	undone    bool // whether the run used undo at all
+	won       bool // reached GOAL, waiting for the player to go on
+	going     bool // went on after reaching GOAL
+	over      bool
+	submitted bool
+
+	// The last move's animation
+	slides  []Slide
+	spawned grid.Point
+	frame   int
+	anim    int // generation, so ticks of a replaced animation are dropped
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type animMsg int
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:      width,
+		Height:     height,
+		TileStyle:  r.NewStyle().Bold(true),
+		EmptyStyle: r.NewStyle().Background(lipgloss.Color("250")),
+		BoardStyle: r.NewStyle().Background(lipgloss.Color("246")),
+		FlashStyle: r.NewStyle().Background(lipgloss.Color("15")).Foreground(lipgloss.Color("0")).Bold(true),
+		ScoreStyle: r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext binds animations to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetScores keeps runs in store and loads the player's best from it.
+func (m *Model) SetScores(store leaderboard.Store, player, fingerprint string) {
+	m.Scores = store
+	m.Player = player
+	m.Fingerprint = fingerprint
+	if store == nil {
+		return
+	}
+	if e, ok := store.Best(m.bestFilter(), fingerprint); ok {
+		m.best = e.Score
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Restart deals a new board, ranking the abandoned run if it scored.
+func (m *Model) Restart() {
+	m.submitScore()
+	m.best = max(m.best, m.score)
+	m.board = Board{}
+	for i := 0; i < STARTS; i++ {
+		m.board.spawn(m.rng)
+	}
+	m.score, m.canUndo, m.undone = 0, false, false
+	m.won, m.going, m.over, m.submitted = false, false, false, false
+	m.slides = nil
+	m.frame = SLIDEFRAMES + POPFRAMES
+	m.top = nil
+}
+
+// Move slides the tiles towards dir and, if any moved, drops a new one and
+// starts the animation.
+func (m *Model) Move(dir grid.Point) tea.Cmd {
+	next, points, slides := m.board.move(dir)
+	if !moved(slides) {
+		return nil
+	}
+	m.undo, m.undoScore, m.canUndo = m.board, m.score, true
+	m.board = next
+	m.score += points
+	m.spawned, _ = m.board.spawn(m.rng)
+
+	if !m.going && m.board.largest() >= GOAL {
+		m.won = true
+	}
+	if m.board.stuck() {
+		m.over = true
+		m.submitScore()
+	}
+	m.slides = slides
+	return m.animate()
+}
+
+// Undo takes back the last move. Only one move is kept.
+func (m *Model) Undo() {
+	if !m.canUndo || m.over {
+		return
+	}
+	m.board, m.score, m.canUndo = m.undo, m.undoScore, false
+	m.undone = true
+	m.won = false
+	m.slides = nil
+	m.frame = SLIDEFRAMES + POPFRAMES
+}
+
+func (m *Model) animate() tea.Cmd {
+	m.frame = 0
+	m.anim++
+	return m.tick()
+}
+
+func (m Model) animating() bool {
+	return m.frame < SLIDEFRAMES+POPFRAMES
+}
+
+func (m Model) tick() tea.Cmd {
+	gen := m.anim
+	return ui.Every(m.ctx, ANIMTICK, func(time.Time) tea.Msg {
+		return animMsg(gen)
+	})
+}
+
+func (m Model) modifiers() string {
+	if m.undone {
+		return leaderboard.Modifiers("undo")
+	}
+	return leaderboard.NOMODIFIERS
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      MODE,
+		Modifiers: m.modifiers(),
+		Board:     fmt.Sprintf("%dx%d", SIZE, SIZE),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+// bestFilter spans seasons and modifiers: a best score is the player's
+// highest, however it was reached.
+func (m Model) bestFilter() leaderboard.Filter {
+	k := m.scoreKey()
+	return leaderboard.Filter{Game: k.Game, Mode: k.Mode, Board: k.Board}
+}
+
+// submitScore records the run once, when it's over or abandoned.
+func (m *Model) submitScore() {
+	if m.Scores == nil || m.submitted || m.score == 0 {
+		return
+	}
+	m.submitted = true
+	k := m.scoreKey()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Score:       m.score,
+		Points:      m.score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.submitScore()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case m.over:
+			if key.Matches(msg, m.Keys.Continue) {
+				m.Restart()
+			}
+		case m.won:
+			if key.Matches(msg, m.Keys.Continue) {
+				m.won, m.going = false, true
+			} else if key.Matches(msg, m.Keys.Undo) {
+				m.Undo()
+			}
+		case key.Matches(msg, m.Keys.Undo):
+			m.Undo()
+		case key.Matches(msg, m.Keys.Up):
+			return m, m.Move(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			return m, m.Move(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			return m, m.Move(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			return m, m.Move(grid.Right)
+		}
+	case animMsg:
+		if int(msg) != m.anim || !m.animating() {
+			return m, nil
+		}
+		m.frame++
+		if m.animating() {
+			return m, m.tick()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) tileStyle(v int) lipgloss.Style {
+	i := 0
+	for n := v; n > 2 && i < len(tileColors)-1; n /= 2 {
+		i++
+	}
+	c := tileColors[i]
+	return m.TileStyle.Background(c.bg).Foreground(c.fg)
+}
+
+// canvas is the board drawn cell by cell, so sliding tiles can sit between
+// their slots.
+type canvas [][]string
+
+func (m Model) newCanvas() canvas {
+	c := make(canvas, boardHeight)
+	fill := m.BoardStyle.Render(" ")
+	for y := range c {
+		c[y] = make([]string, boardWidth)
+		for x := range c[y] {
+			c[y][x] = fill
+		}
+	}
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			c.tile(slot(grid.Point{X: x, Y: y}), "", m.EmptyStyle)
+		}
+	}
+	return c
+}
+
+// slot is where the tile at p is drawn, in canvas cells.
+func slot(p grid.Point) grid.Point {
+	return grid.Point{X: GAP + p.X*(TILEWIDTH+GAP), Y: 1 + p.Y*(TILEHEIGHT+1)}
+}
+
+// tile draws a tile labelled label with its top left corner at at.
+func (c canvas) tile(at grid.Point, label string, style lipgloss.Style) {
+	if len(label) > TILEWIDTH {
+		label = label[:TILEWIDTH]
+	}
+	pad := (TILEWIDTH - len(label)) / 2
+	for dy := 0; dy < TILEHEIGHT; dy++ {
+		for dx := 0; dx < TILEWIDTH; dx++ {
+			ch := " "
+			if dy == TILEHEIGHT/2 && dx >= pad && dx < pad+len(label) {
+				ch = label[dx-pad : dx-pad+1]
+			}
+			c[at.Y+dy][at.X+dx] = style.Render(ch)
+		}
+	}
+}
+
+func (c canvas) String() string {
+	var s strings.Builder
+	for y, row := range c {
+		for _, cell := range row {
+			s.WriteString(cell)
+		}
+		if y < len(c)-1 {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+// boardView draws the board, mid-slide or with its merges highlighted while
+// the last move animates.
+func (m Model) boardView() string {
+	c := m.newCanvas()
+	if m.frame < SLIDEFRAMES && m.slides != nil {
+		for _, s := range m.slides {
+			from, to := slot(s.From), slot(s.To)
+			at := grid.Point{
+				X: from.X + (to.X-from.X)*(m.frame+1)/(SLIDEFRAMES+1),
+				Y: from.Y + (to.Y-from.Y)*(m.frame+1)/(SLIDEFRAMES+1),
+			}
+			c.tile(at, strconv.Itoa(s.Value), m.tileStyle(s.Value))
+		}
+		return c.String()
+	}
+
+	flash := make(map[grid.Point]bool)
+	if m.animating() && m.slides != nil {
+		for _, s := range m.slides {
+			if s.Merged {
+				flash[s.To] = true
+			}
+		}
+		flash[m.spawned] = true
+	}
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			p := grid.Point{X: x, Y: y}
+			v := m.board.at(p)
+			if v == 0 {
+				continue
+			}
+			style := m.tileStyle(v)
+			if flash[p] {
+				style = m.FlashStyle
+			}
+			c.tile(slot(p), strconv.Itoa(v), style)
+		}
+	}
+	return c.String()
+}
+
+func (m Model) header() string {
+	return fmt.Sprintf("2048 | Score: %s | Best: %s",
+		m.ScoreStyle.Render(strconv.Itoa(m.score)), m.ScoreStyle.Render(strconv.Itoa(max(m.best, m.score))))
+}
+
+func (m Model) overView() string {
+	lines := []string{"No moves left", fmt.Sprintf("%d points, largest tile %d", m.score, m.board.largest())}
+	if m.score > m.best {
+		lines = append(lines, "A new best!")
+	}
+	if len(m.top) > 0 {
+		var s strings.Builder
+		s.WriteString("Top scores this season\n")
+		for i, e := range m.top {
+			fmt.Fprintf(&s, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(s.String(), "\n"))
+	}
+	lines = append(lines, "", fmt.Sprintf("'%s' new game", m.Keys.Continue.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) wonView() string {
+	return m.BoxStyle.Render(fmt.Sprintf("You made %d!\n\n'%s' keep going, '%s' new game",
+		GOAL, m.Keys.Continue.Help().Key, m.Keys.Restart.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	var overlay string
+	switch {
+	case m.over && !m.animating():
+		overlay = m.overView()
+	case m.won && !m.animating():
+		overlay = m.wonView()
+	}
+	if overlay != "" {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			lipgloss.JoinVertical(lipgloss.Center, m.header(), "", overlay),
+		)
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardView(),
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
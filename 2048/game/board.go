@@ -0,0 +1,141 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	SIZE   = 4
+	GOAL   = 2048
+	FOURS  = 10 // percent of new tiles that are 4s rather than 2s
+	STARTS = 2  // tiles on a new board
+)
+
+// Board holds tile values, zero for an empty cell. It's an array so undo
+// can keep a copy.
+type Board [SIZE][SIZE]int
+
+func (b *Board) at(p grid.Point) int {
+	return b[p.Y][p.X]
+}
+
+func (b *Board) set(p grid.Point, v int) {
+	b[p.Y][p.X] = v
+}
+
+// Slide is one tile's part in a move. Tiles that merge both slide to the
+// same cell, and the one that arrives second carries Merged.
+type Slide struct {
+	From, To grid.Point
+	Value    int // before merging
+	Merged   bool
+}
+
+// lines lists the board's cells for a move in dir, one line per row or
+// column, each starting at the edge tiles slide towards.
+func lines(dir grid.Point) [][]grid.Point {
+	var ls [][]grid.Point
+	for i := 0; i < SIZE; i++ {
+		var l []grid.Point
+		for j := 0; j < SIZE; j++ {
+			k := j
+			if dir == grid.Right || dir == grid.Down {
+				k = SIZE - 1 - j
+			}
+			if dir.X != 0 {
+				l = append(l, grid.Point{X: k, Y: i})
+			} else {
+				l = append(l, grid.Point{X: i, Y: k})
+			}
+		}
+		ls = append(ls, l)
+	}
+	return ls
+}
+
+// move slides every tile towards dir, merging equal neighbours once per
+// move. It returns the board after the move, the points the merges scored
+// and every tile's slide. A move that changes nothing has no slide with
+// From and To apart.
+func (b Board) move(dir grid.Point) (Board, int, []Slide) {
+	var next Board
+	var score int
+	var slides []Slide
+	for _, l := range lines(dir) {
+		t := 0
+		merged := false // whether the tile at t-1 already took a merge
+		for _, p := range l {
+			v := b.at(p)
+			if v == 0 {
+				continue
+			}
+			if t > 0 && !merged && next.at(l[t-1]) == v {
+				next.set(l[t-1], 2*v)
+				score += 2 * v
+				merged = true
+				slides = append(slides, Slide{From: p, To: l[t-1], Value: v, Merged: true})
+				continue
+			}
+			next.set(l[t], v)
+			merged = false
+			slides = append(slides, Slide{From: p, To: l[t], Value: v})
+			t++
+		}
+	}
+	return next, score, slides
+}
+
+// moved reports whether any tile left its cell.
+func moved(slides []Slide) bool {
+	for _, s := range slides {
+		if s.From != s.To {
+			return true
+		}
+	}
+	return false
+}
+
+// stuck reports whether no move changes the board.
+func (b Board) stuck() bool {
+	for _, dir := range grid.Dirs4 {
+		if _, _, slides := b.move(dir); moved(slides) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b Board) largest() int {
+	var top int
+	for _, row := range b {
+		for _, v := range row {
+			top = max(top, v)
+		}
+	}
+	return top
+}
+
+// spawn drops a 2, or now and then a 4, on a random empty cell and returns
+// where, or false when the board is full.
+func (b *Board) spawn(rng *rand.Rand) (grid.Point, bool) {
+	var empty []grid.Point
+	for y := range b {
+		for x := range b[y] {
+			if b[y][x] == 0 {
+				empty = append(empty, grid.Point{X: x, Y: y})
+			}
+		}
+	}
+	if len(empty) == 0 {
+		return grid.Point{}, false
+	}
+	p := empty[rng.Intn(len(empty))]
+	v := 2
+	if rng.Intn(100) < FOURS {
+		v = 4
+	}
+	b.set(p, v)
+	return p, true
+}
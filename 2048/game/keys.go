@@ -0,0 +1,64 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Undo     key.Binding
+	Restart  key.Binding
+	Continue key.Binding
+	Layout   key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Undo:     key.NewBinding(key.WithKeys("u", "backspace"), key.WithHelp("u", "undo")),
+		Restart:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new game")),
+		Continue: key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "continue")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Undo, k.Restart, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Undo, k.Restart, k.Continue},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":       &k.Up,
+		"down":     &k.Down,
+		"left":     &k.Left,
+		"right":    &k.Right,
+		"undo":     &k.Undo,
+		"restart":  &k.Restart,
+		"continue": &k.Continue,
+		"layout":   &k.Layout,
+		"help":     &k.Help,
+		"quit":     &k.Quit,
+	}
+}
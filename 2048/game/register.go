@@ -0,0 +1,38 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "2048"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "2048",
+	Description: "Slide and merge tiles until one reaches 2048",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     10 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.SetScores(env.Scores, env.Player, env.Fingerprint)
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,318 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MODE      = "timed"
+	TOPSCORES = 5 // on the round's leaderboard
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TileStyle  lipgloss.Style
+	InputStyle lipgloss.Style
+	WordStyle  lipgloss.Style
+	QuitStyle  lipgloss.Style
+	GoodStyle  lipgloss.Style
+	BadStyle   lipgloss.Style
+	WinStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, ranks every round's players under Player's name and
+	// their key.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	Location    *time.Location // the player's, for the round's start
+	top         []leaderboard.Entry
+
+	// Round state
+	round    string
+	ends     time.Time
+	rack     []rune
+	words    int // in the rack
+	input    []rune
+	found    []string // newest first
+	score    int
+	message  string
+	good     bool // whether message is good news
+	over     bool
+	showHelp bool
+
+	ctx context.Context
+}
+
+type clockMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:      width,
+		Height:     height,
+		TileStyle:  r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("223")).Bold(true).Padding(0, 1),
+		InputStyle: r.NewStyle().Foreground(lipgloss.Color("15")).Underline(true),
+		WordStyle:  r.NewStyle().Foreground(lipgloss.Color("153")),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		GoodStyle:  r.NewStyle().Foreground(lipgloss.Color("10")),
+		BadStyle:   r.NewStyle().Foreground(lipgloss.Color("9")),
+		WinStyle: r.NewStyle().
+			Foreground(lipgloss.Color("10")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join(time.Now())
+	return m
+}
+
+// SetContext binds the clock to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.clock()
+}
+
+func (m Model) clock() tea.Cmd {
+	return ui.Every(m.ctx, time.Second, func(t time.Time) tea.Msg {
+		return clockMsg(t)
+	})
+}
+
+// Join plays the round running at t, with whatever time is left of it.
+func (m *Model) Join(t time.Time) {
+	var start time.Time
+	m.round, start = roundOf(t)
+	m.ends = start.Add(ROUND)
+	m.rack = deal(m.round)
+	m.words = len(solutions(dictionary, string(m.rack)))
+	m.input = nil
+	m.found = nil
+	m.score = 0
+	m.message = ""
+	m.over = false
+	m.top = nil
+}
+
+func (m *Model) say(good bool, format string, args ...any) {
+	m.good = good
+	m.message = fmt.Sprintf(format, args...)
+}
+
+// submit scores the typed word, unless it was already found this round.
+func (m *Model) submit() {
+	word := string(m.input)
+	m.input = nil
+	switch {
+	case word == "":
+	case len(word) < MINWORD:
+		m.say(false, "Words have at least %d letters", MINWORD)
+	case slices.Contains(m.found, word):
+		m.say(false, "You already found %s", strings.ToUpper(word))
+	case !dictionary[word]:
+		m.say(false, "%s isn't in the dictionary", strings.ToUpper(word))
+	default:
+		p := points(word)
+		m.found = append([]string{word}, m.found...)
+		m.score += p
+		m.say(true, "%s +%d", strings.ToUpper(word), p)
+	}
+}
+
+// write takes a letter only while the rack has a copy of it left.
+func (m *Model) write(r rune) {
+	r = unicode.ToLower(r)
+	if fits(string(append(m.input, r)), string(m.rack)) {
+		m.input = append(m.input, r)
+	}
+}
+
+func (m *Model) shuffle() {
+	rand.Shuffle(len(m.rack), func(i, j int) { m.rack[i], m.rack[j] = m.rack[j], m.rack[i] })
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      MODE,
+		Modifiers: leaderboard.NOMODIFIERS,
+		Board:     m.round,
+		Season:    leaderboard.SeasonOf(m.ends),
+	}
+}
+
+// end closes the round, ranking the player against everyone who played it.
+func (m *Model) end() {
+	m.over = true
+	m.input = nil
+	if m.Scores == nil {
+		return
+	}
+	k := m.scoreKey()
+	if m.score > 0 {
+		err := m.Scores.Submit(leaderboard.Entry{
+			Key:         k,
+			Player:      m.Player,
+			Fingerprint: m.Fingerprint,
+			Score:       m.score,
+			Points:      m.score,
+			At:          time.Now(),
+		})
+		if err != nil {
+			log.Warn("Could not submit score", "err", err)
+		}
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case m.over:
+			if key.Matches(msg, m.Keys.Submit) {
+				m.Join(time.Now())
+			}
+		case key.Matches(msg, m.Keys.Submit):
+			m.submit()
+		case key.Matches(msg, m.Keys.Erase):
+			if len(m.input) > 0 {
+				m.input = m.input[:len(m.input)-1]
+			}
+		case key.Matches(msg, m.Keys.Clear):
+			m.input = nil
+		case key.Matches(msg, m.Keys.Shuffle):
+			m.shuffle()
+		case msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && unicode.IsLetter(msg.Runes[0]):
+			m.write(msg.Runes[0])
+		}
+	case clockMsg:
+		if !m.over && !time.Time(msg).Before(m.ends) {
+			m.end()
+		}
+		return m, m.clock()
+	}
+	return m, nil
+}
+
+func (m Model) rackView() string {
+	tiles := make([]string, len(m.rack))
+	for i, r := range m.rack {
+		tiles[i] = m.TileStyle.Render(string(unicode.ToUpper(r)))
+	}
+	return strings.Join(tiles, " ")
+}
+
+// foundView wraps the words found so far, newest first.
+func (m Model) foundView() string {
+	words := make([]string, len(m.found))
+	for i, w := range m.found {
+		words[i] = strings.ToUpper(w)
+	}
+	return m.WordStyle.Width(max(20, min(m.Width-4, 60))).Align(lipgloss.Center).Render(strings.Join(words, " "))
+}
+
+func (m Model) overView() string {
+	lines := []string{
+		"Time's up!",
+		fmt.Sprintf("%d points, %d of %d words", m.score, len(m.found), m.words),
+	}
+	var longest []string
+	for _, w := range solutions(dictionary, string(m.rack)) {
+		if len(w) == RACK {
+			longest = append(longest, strings.ToUpper(w))
+		}
+	}
+	slices.Sort(longest)
+	lines = append(lines, "The whole rack spelled "+strings.Join(longest, ", "))
+
+	if len(m.top) > 0 {
+		var s strings.Builder
+		fmt.Fprintf(&s, "Round of %s\n", clock.Time(m.ends.Add(-ROUND), m.Location))
+		for i, e := range m.top {
+			fmt.Fprintf(&s, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(s.String(), "\n"))
+	}
+	lines = append(lines, "", fmt.Sprintf("'%s' next round", m.Keys.Submit.Help().Key))
+	return m.WinStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.WinStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
+	left := max(0, time.Until(m.ends)).Round(time.Second)
+	if m.over {
+		left = 0
+	}
+	header := fmt.Sprintf("Anagrams | Score: %d | Words: %d/%d | Time: %d:%02d",
+		m.score, len(m.found), m.words, int(left.Minutes()), int(left.Seconds())%60)
+	if m.over {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			lipgloss.JoinVertical(lipgloss.Center, header, "", m.rackView(), "", m.overView()),
+		)
+	}
+
+	input := strings.ToUpper(string(m.input))
+	message := m.BadStyle.Render(m.message)
+	if m.good {
+		message = m.GoodStyle.Render(m.message)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			header,
+			"",
+			m.rackView(),
+			"",
+			m.InputStyle.Render(fmt.Sprintf("%-*s", RACK, input)),
+			message,
+			"",
+			m.foundView(),
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
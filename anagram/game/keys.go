@@ -0,0 +1,49 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// KeyMap keeps its commands off the letters, which spell words.
+type KeyMap struct {
+	Submit  key.Binding
+	Erase   key.Binding
+	Clear   key.Binding
+	Shuffle key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Submit:  key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "submit")),
+		Erase:   key.NewBinding(key.WithKeys("backspace", "delete"), key.WithHelp("backspace", "erase")),
+		Clear:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "clear")),
+		Shuffle: key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "shuffle")),
+		Help:    ui.HelpKey(),
+		Quit:    key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "quit")),
+	}
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Submit, k.Shuffle, k.Clear, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Submit, k.Erase, k.Clear, k.Shuffle},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"submit":  &k.Submit,
+		"erase":   &k.Erase,
+		"clear":   &k.Clear,
+		"shuffle": &k.Shuffle,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
@@ -0,0 +1,35 @@
+package game
+
+import "github.com/debemdeboas/games.debem.dev/games"
+
+const GAMENAME = "anagram"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Anagrams",
+	Description: "Spell as many words as you can from seven letters",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     ROUND,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.Scores = env.Scores
+		m.Player = env.Player
+		m.Fingerprint = env.Fingerprint
+		m.Location = env.Location
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	_ "embed"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/daily"
+)
+
+const (
+	RACK     = 7 // letters dealt each round
+	MINWORD  = 3
+	MINWORDS = 30              // words a rack must allow to be dealt
+	ROUND    = 2 * time.Minute // every player shares the rack of a round
+)
+
+//go:embed words.txt
+var wordList string
+
+var dictionary, racks = mustLoad(wordList)
+
+// mustLoad reads the word list, one lowercase word per line, and picks the
+// words that make good racks: those as long as a rack that hide enough
+// shorter words.
+func mustLoad(list string) (map[string]bool, []string) {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		if len(w) < MINWORD || strings.Trim(w, "abcdefghijklmnopqrstuvwxyz") != "" {
+			panic(fmt.Sprintf("anagram: bad word %q", w))
+		}
+		words[w] = true
+	}
+
+	var rs []string
+	for w := range words {
+		if len(w) == RACK && len(solutions(words, w)) >= MINWORDS {
+			rs = append(rs, w)
+		}
+	}
+	if len(rs) == 0 {
+		panic("anagram: no word makes a rack")
+	}
+	// Map iteration is random; rounds must deal the same rack everywhere.
+	slices.Sort(rs)
+	return words, rs
+}
+
+// fits reports whether word can be spelled with the letters of rack, each
+// used at most once.
+func fits(word, rack string) bool {
+	var left [26]int
+	for _, r := range rack {
+		left[r-'a']++
+	}
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+		if left[r-'a']--; left[r-'a'] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// solutions lists every word of words that fits rack.
+func solutions(words map[string]bool, rack string) []string {
+	var ws []string
+	for w := range words {
+		if fits(w, rack) {
+			ws = append(ws, w)
+		}
+	}
+	return ws
+}
+
+// points grows faster than the word, and using the whole rack earns a bonus.
+func points(word string) int {
+	switch n := len(word); {
+	case n >= RACK:
+		return 2*n + 5
+	case n > MINWORD:
+		return 2*n - 5
+	default:
+		return 1
+	}
+}
+
+// roundOf is the round t falls in, named after when it started.
+func roundOf(t time.Time) (string, time.Time) {
+	start := t.UTC().Truncate(ROUND)
+	return start.Format("2006-01-02 15:04"), start
+}
+
+// deal shuffles the rack of round, the same for every player.
+func deal(round string) []rune {
+	rng := rand.New(rand.NewSource(int64(daily.Seed(GAMENAME, round))))
+	rack := []rune(racks[rng.Intn(len(racks))])
+	rng.Shuffle(len(rack), func(i, j int) { rack[i], rack[j] = rack[j], rack[i] })
+	return rack
+}
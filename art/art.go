@@ -0,0 +1,370 @@
+// Package art shows operator-provided ANSI art, the kind drawn for BBSes:
+// text colored with SGR escapes and placed with cursor movements, in CP437
+// or UTF-8, often wrapping at 80 columns and sometimes ending in a SAUCE
+// record. Art is drawn onto a grid of cells first, so it can be fitted to
+// terminals of any size and shown as splash screens, see Wrap.
+package art
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// WIDTH is where CP437 art wraps unless its SAUCE record says otherwise.
+// UTF-8 art, usually made for terminals, only breaks at newlines.
+const WIDTH = 80
+
+// style is the SGR state of a cell. Colors are kept as the parameters
+// that set them, so art comes out in the colors it was drawn with.
+type style struct {
+	fg, bg string // e.g. "31" and "48;5;17", empty for the default
+	attrs  string // e.g. "1;5"
+}
+
+// sgr is the escape sequence that sets s from any state.
+func (s style) sgr() string {
+	params := []string{"0"}
+	for _, p := range []string{s.attrs, s.fg, s.bg} {
+		if p != "" {
+			params = append(params, p)
+		}
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// Cell is one column of art. Wide runes take their cell and leave the next
+// one empty with Rune 0.
+type Cell struct {
+	Rune  rune
+	style style
+}
+
+var blank = Cell{Rune: ' '}
+
+// inked reports whether the cell shows anything on a plain background.
+func (c Cell) inked() bool {
+	return c.Rune != ' ' || c.style.bg != ""
+}
+
+// Art is a drawing, one row of cells per line.
+type Art [][]Cell
+
+// Size is the widest row and the number of rows, leaving out trailing
+// blanks.
+func (a Art) Size() (width, height int) {
+	for y, row := range a {
+		for x := len(row) - 1; x >= 0; x-- {
+			if row[x].inked() {
+				width = max(width, x+1)
+				height = y + 1
+				break
+			}
+		}
+	}
+	return width, height
+}
+
+// Load reads the art in the file at path.
+func Load(path string) (Art, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data), nil
+}
+
+// Parse draws art from its file contents.
+func Parse(data []byte) Art {
+	if utf8.Valid(data) {
+		data, wrap := sauce(data, 0)
+		return draw(data, wrap, utf8.DecodeRune)
+	}
+	data, wrap := sauce(data, WIDTH)
+	return draw(data, wrap, func(b []byte) (rune, int) {
+		return charmap.CodePage437.DecodeByte(b[0]), 1
+	})
+}
+
+// sauce strips the SAUCE record from the end of data, if there is one, and
+// reads the width the art wraps at from it, keeping wrap otherwise.
+func sauce(data []byte, wrap int) ([]byte, int) {
+	const size = 128
+	if len(data) < size || !bytes.HasPrefix(data[len(data)-size:], []byte("SAUCE00")) {
+		return data, wrap
+	}
+	record := data[len(data)-size:]
+	// Character art, ANSI or ANSiMation, keeps its width in TInfo1.
+	if record[94] == 1 && (record[95] == 1 || record[95] == 2) {
+		if w := int(binary.LittleEndian.Uint16(record[96:])); w > 0 {
+			wrap = w
+		}
+	}
+	return data[:len(data)-size], wrap
+}
+
+// canvas is art being drawn, with a cursor that moves as a terminal's does.
+type canvas struct {
+	art   Art
+	x, y  int
+	wrap  int // zero for never
+	style style
+}
+
+func (c *canvas) put(r rune) {
+	w := runewidth.RuneWidth(r)
+	if c.wrap > 0 && c.x+w > c.wrap {
+		c.x, c.y = 0, c.y+1
+	}
+	c.set(c.x, c.y, Cell{Rune: r, style: c.style})
+	for i := 1; i < w; i++ {
+		c.set(c.x+i, c.y, Cell{style: c.style})
+	}
+	c.x += w
+}
+
+func (c *canvas) set(x, y int, cell Cell) {
+	for len(c.art) <= y {
+		c.art = append(c.art, nil)
+	}
+	for len(c.art[y]) <= x {
+		c.art[y] = append(c.art[y], blank)
+	}
+	c.art[y][x] = cell
+}
+
+func draw(data []byte, wrap int, decode func([]byte) (rune, int)) Art {
+	c := &canvas{wrap: wrap}
+	var savedX, savedY int
+	for i := 0; i < len(data); {
+		switch b := data[i]; b {
+		case 0x1a: // end of file, anything after is metadata
+			return c.art
+		case '\r':
+			c.x = 0
+		case '\n':
+			c.x, c.y = 0, c.y+1
+		case '\t':
+			c.x = (c.x/8 + 1) * 8
+		case 0x1b:
+			if i+1 >= len(data) || data[i+1] != '[' {
+				i += 2
+				continue
+			}
+			end := i + 2
+			for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+				end++
+			}
+			if end == len(data) {
+				return c.art
+			}
+			params := string(data[i+2 : end])
+			switch n := count(params); data[end] {
+			case 'm':
+				c.style = sgr(params, c.style)
+			case 'A':
+				c.y = max(0, c.y-n)
+			case 'B':
+				c.y += n
+			case 'C':
+				c.x += n
+			case 'D':
+				c.x = max(0, c.x-n)
+			case 'H', 'f':
+				row, col, _ := strings.Cut(params, ";")
+				c.y, c.x = max(0, atoi(row, 1)-1), max(0, atoi(col, 1)-1)
+			case 'J':
+				if params == "2" {
+					c.art, c.x, c.y = nil, 0, 0
+				}
+			case 'K':
+				if c.y < len(c.art) && c.x < len(c.art[c.y]) {
+					c.art[c.y] = c.art[c.y][:c.x]
+				}
+			case 's':
+				savedX, savedY = c.x, c.y
+			case 'u':
+				c.x, c.y = savedX, savedY
+			}
+			i = end + 1
+			continue
+		default:
+			r, size := decode(data[i:])
+			i += size
+			if r >= ' ' && r != utf8.RuneError {
+				c.put(r)
+			}
+			continue
+		}
+		i++
+	}
+	return c.art
+}
+
+func atoi(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n == 0 {
+		return def
+	}
+	return n
+}
+
+// count is the repeat parameter of a cursor movement, one when missing.
+func count(params string) int {
+	return atoi(params, 1)
+}
+
+// sgr applies Select Graphic Rendition parameters to s.
+func sgr(params string, s style) style {
+	ps := strings.Split(params, ";")
+	attrs := strings.FieldsFunc(s.attrs, func(r rune) bool { return r == ';' })
+	for i := 0; i < len(ps); i++ {
+		n, _ := strconv.Atoi(ps[i])
+		switch {
+		case n == 0:
+			s, attrs = style{}, nil
+		case n >= 1 && n <= 9:
+			if !slices.Contains(attrs, strconv.Itoa(n)) {
+				attrs = append(attrs, strconv.Itoa(n))
+			}
+		case n >= 21 && n <= 29:
+			// 22 turns off both bold and faint; the rest turn off n-20.
+			off := []string{strconv.Itoa(n - 20)}
+			if n == 22 {
+				off = []string{"1", "2"}
+			}
+			kept := attrs[:0]
+			for _, a := range attrs {
+				if !slices.Contains(off, a) {
+					kept = append(kept, a)
+				}
+			}
+			attrs = kept
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			s.fg = strconv.Itoa(n)
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			s.bg = strconv.Itoa(n)
+		case n == 39:
+			s.fg = ""
+		case n == 49:
+			s.bg = ""
+		case n == 38 || n == 48:
+			take := 0
+			switch {
+			case i+2 < len(ps) && ps[i+1] == "5":
+				take = 2
+			case i+4 < len(ps) && ps[i+1] == "2":
+				take = 4
+			}
+			if take == 0 {
+				i = len(ps)
+				continue
+			}
+			color := strings.Join(ps[i:i+take+1], ";")
+			if n == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+			i += take
+		}
+	}
+	s.attrs = strings.Join(attrs, ";")
+	return s
+}
+
+// Fit shrinks a to width by height cells. Art at least twice as wide as
+// that is scaled down by a whole factor first, keeping a cell of each block,
+// since cropping would leave little of it. Whatever still overflows is
+// cropped around the middle across and from the bottom down.
+func (a Art) Fit(width, height int) Art {
+	w, h := a.Size()
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	if k := w / width; k >= 2 {
+		if w%width != 0 {
+			k++
+		}
+		a = a.scale(k)
+		w, h = a.Size()
+	}
+
+	left := max(0, (w-width)/2)
+	fitted := make(Art, 0, min(h, height))
+	for _, row := range a[:min(h, height)] {
+		row = row[min(left, len(row)):]
+		row = row[:min(width, len(row))]
+		// A wide rune cut in half would show its right side alone.
+		if len(row) > 0 && row[0].Rune == 0 {
+			row = append([]Cell{blank}, row[1:]...)
+		}
+		fitted = append(fitted, row)
+	}
+	return fitted
+}
+
+// scale shrinks a by k in both directions: each k by k block becomes its
+// first inked cell, or its first cell if it's all blank.
+func (a Art) scale(k int) Art {
+	w, h := a.Size()
+	scaled := make(Art, 0, (h+k-1)/k)
+	for y := 0; y < h; y += k {
+		row := make([]Cell, 0, (w+k-1)/k)
+		for x := 0; x < w; x += k {
+			cell := blank
+			found := false
+			for dy := 0; dy < k && y+dy < h && !found; dy++ {
+				src := a[y+dy]
+				for dx := 0; dx < k && x+dx < len(src); dx++ {
+					if c := src[x+dx]; c.Rune != 0 && c.inked() {
+						cell, found = c, true
+						break
+					}
+				}
+			}
+			if runewidth.RuneWidth(cell.Rune) > 1 {
+				cell = Cell{Rune: ' ', style: cell.style}
+			}
+			row = append(row, cell)
+		}
+		scaled = append(scaled, row)
+	}
+	return scaled
+}
+
+// String renders a with SGR escapes, resetting them at the end of every
+// line and leaving out trailing blanks.
+func (a Art) String() string {
+	var s strings.Builder
+	for y, row := range a {
+		if y > 0 {
+			s.WriteByte('\n')
+		}
+		for len(row) > 0 && row[len(row)-1] == blank {
+			row = row[:len(row)-1]
+		}
+		var current style
+		for _, c := range row {
+			if c.Rune == 0 {
+				continue
+			}
+			if c.style != current {
+				s.WriteString(c.style.sgr())
+				current = c.style
+			}
+			s.WriteRune(c.Rune)
+		}
+		if current != (style{}) {
+			s.WriteString("\x1b[0m")
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,141 @@
+package art
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	INTRO = 5 * time.Second // at most, the skip key ends it sooner
+	OUTRO = 3 * time.Second
+)
+
+// doneMsg ends the screen shown since it was sent, if it's still up.
+type doneMsg int
+
+// outroMsg replaces the wrapped model's tea.Quit.
+type outroMsg struct{}
+
+// Splash shows art before a model starts and after it quits.
+type Splash struct {
+	Skip      key.Binding
+	HintStyle lipgloss.Style
+
+	model        tea.Model
+	intro, outro Art
+	showing      Art // nil once the intro is over
+	shown        int // screens shown, so a skipped one's timer is dropped
+	quitting     bool
+	width        int
+	height       int
+}
+
+// Wrap shows intro while m starts, and outro once it quits, on a terminal of
+// width by height to start with. Either may be nil for none.
+func Wrap(m tea.Model, intro, outro Art, width, height int, r *lipgloss.Renderer) *Splash {
+	return &Splash{
+		Skip:      key.NewBinding(key.WithKeys("enter", " ", "esc"), key.WithHelp("enter", "skip")),
+		HintStyle: r.NewStyle().Foreground(lipgloss.Color("8")),
+		model:     m,
+		intro:     intro,
+		outro:     outro,
+		width:     width,
+		height:    height,
+	}
+}
+
+func (s *Splash) Init() tea.Cmd {
+	cmd := quits(s.model.Init())
+	if s.intro == nil {
+		return cmd
+	}
+	return tea.Batch(cmd, s.show(s.intro, INTRO))
+}
+
+// show puts art up for d.
+func (s *Splash) show(a Art, d time.Duration) tea.Cmd {
+	s.showing = a
+	s.shown++
+	shown := s.shown
+	return tea.Tick(d, func(time.Time) tea.Msg { return doneMsg(shown) })
+}
+
+// done takes the screen down, ending the session after the outro.
+func (s *Splash) done() tea.Cmd {
+	s.showing = nil
+	if s.quitting {
+		return tea.Quit
+	}
+	return nil
+}
+
+func (s *Splash) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case doneMsg:
+		if int(msg) == s.shown && s.showing != nil {
+			return s, s.done()
+		}
+		return s, nil
+	case outroMsg:
+		s.quitting = true
+		if s.outro == nil {
+			return s, tea.Quit
+		}
+		return s, s.show(s.outro, OUTRO)
+	case tea.WindowSizeMsg:
+		s.width, s.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if s.showing == nil {
+			break
+		}
+		if msg.Type == tea.KeyCtrlC {
+			return s, tea.Quit
+		}
+		if key.Matches(msg, s.Skip) {
+			return s, s.done()
+		}
+		return s, nil
+	}
+	if s.quitting {
+		return s, nil
+	}
+	var cmd tea.Cmd
+	s.model, cmd = s.model.Update(msg)
+	return s, quits(cmd)
+}
+
+func (s *Splash) View() string {
+	if s.showing == nil {
+		return s.model.View()
+	}
+	hint := s.HintStyle.Render(s.Skip.Help().Key + " to skip")
+	art := s.showing.Fit(s.width, s.height-1).String()
+	return lipgloss.Place(s.width, s.height, lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, art, hint))
+}
+
+// quits wraps a command of the model so its tea.Quit shows the outro
+// instead. Commands nested in tea.Sequence can't be unwrapped, and quit
+// right away.
+func quits(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case tea.QuitMsg:
+			return outroMsg{}
+		case tea.BatchMsg:
+			batch := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				batch[i] = quits(c)
+			}
+			return batch
+		default:
+			return msg
+		}
+	}
+}
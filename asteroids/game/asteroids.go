@@ -0,0 +1,396 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/physics"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MODE      = "classic"
+	TOPSCORES = 5 // on the game over screen
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	RockStyle   lipgloss.Style
+	BulletStyle lipgloss.Style
+	ShipStyle   lipgloss.Style
+	BoardStyle  lipgloss.Style
+	ScoreStyle  lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, keeps the player's runs, which is where their best
+	// score is loaded from.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	best        int // before this run
+	top         []leaderboard.Entry
+	submitted   bool
+
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell *bell.Bell
+
+	space  *Space
+	paused bool
+	slow   bool // see games.Env.Slow
+	run    int  // generation of the clock, so ticks of a stopped one are dropped
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type tickMsg int
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		RockStyle:   r.NewStyle().Foreground(lipgloss.Color("250")),
+		BulletStyle: r.NewStyle().Foreground(lipgloss.Color("226")),
+		ShipStyle:   r.NewStyle().Foreground(lipgloss.Color("51")).Bold(true),
+		BoardStyle:  r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		ScoreStyle:  r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.space = NewSpace(m.rng)
+	return m
+}
+
+// SetContext binds the clock to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetScores keeps runs in store and loads the player's best from it.
+func (m *Model) SetScores(store leaderboard.Store, player, fingerprint string) {
+	m.Scores = store
+	m.Player = player
+	m.Fingerprint = fingerprint
+	if store == nil {
+		return
+	}
+	if e, ok := store.Best(m.bestFilter(), fingerprint); ok {
+		m.best = e.Score
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.tick()
+}
+
+// Restart starts a new game from the first wave, ranking the abandoned run
+// if it scored.
+func (m *Model) Restart() tea.Cmd {
+	m.submitScore()
+	m.best = max(m.best, m.space.score)
+	m.space = NewSpace(m.rng)
+	m.paused, m.submitted = false, false
+	m.top = nil
+	m.run++
+	return m.tick()
+}
+
+// SetSlow turns slow mode on or off, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// tickDuration is how long a tick lasts, longer in slow mode.
+func (m Model) tickDuration() time.Duration {
+	if m.slow {
+		return TICK * games.SLOWDOWN
+	}
+	return TICK
+}
+
+func (m Model) tick() tea.Cmd {
+	run := m.run
+	return ui.Every(m.ctx, m.tickDuration(), func(time.Time) tea.Msg {
+		return tickMsg(run)
+	})
+}
+
+// flying reports whether the clock runs.
+func (m Model) flying() bool {
+	return !m.space.over && !m.paused
+}
+
+// Pause stops the clock mid-game, see games.Pauser.
+func (m *Model) Pause() bool {
+	if !m.flying() {
+		return false
+	}
+	m.paused = true
+	return true
+}
+
+func (m *Model) Resume() tea.Cmd {
+	m.paused = false
+	if m.flying() {
+		m.run++
+		return m.tick()
+	}
+	return nil
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      MODE,
+		Modifiers: m.modifiers(),
+		Board:     fmt.Sprintf("%dx%d", WIDTH, HEIGHT),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+func (m Model) modifiers() string {
+	if m.slow {
+		return leaderboard.Modifiers(leaderboard.ASSISTED)
+	}
+	return leaderboard.Modifiers()
+}
+
+// bestFilter spans seasons: a best score is the player's highest ever.
+func (m Model) bestFilter() leaderboard.Filter {
+	k := m.scoreKey()
+	return leaderboard.Filter{Game: k.Game, Mode: k.Mode, Board: k.Board}
+}
+
+// submitScore records the run once, when it's over or abandoned.
+func (m *Model) submitScore() {
+	if m.Scores == nil || m.submitted || m.space.score == 0 {
+		return
+	}
+	m.submitted = true
+	k := m.scoreKey()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Score:       m.space.score,
+		Points:      m.space.score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.space
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.submitScore()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			return m, m.Restart()
+		case s.over:
+			if key.Matches(msg, m.Keys.Fire) {
+				return m, m.Restart()
+			}
+		case key.Matches(msg, m.Keys.Pause):
+			m.paused = !m.paused
+			if m.flying() {
+				m.run++
+				return m, m.tick()
+			}
+		case m.paused:
+			// The ship stays put until play goes on.
+		case key.Matches(msg, m.Keys.Left):
+			s.Turn(-1)
+		case key.Matches(msg, m.Keys.Right):
+			s.Turn(1)
+		case key.Matches(msg, m.Keys.Up):
+			s.Thrust()
+		case key.Matches(msg, m.Keys.Fire):
+			s.Fire()
+		}
+	case tickMsg:
+		if int(msg) != m.run || !m.flying() {
+			return m, nil
+		}
+		if s.step() {
+			m.Bell.Ring(bell.DEATH)
+		}
+		if s.over {
+			m.submitScore()
+			return m, nil
+		}
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+// dots converts a field position to the dot it falls on.
+func dots(v physics.Vec) (int, int) {
+	return v.X.Round(), v.Y.Round()
+}
+
+// outline is the rock's jagged polygon, in dots.
+func outline(r rock) ([]int, []int) {
+	xs, ys := make([]int, VERTICES), make([]int, VERTICES)
+	cx, cy := dots(r.pos)
+	for i, radius := range r.shape {
+		h := headings[i*ANGLES/VERTICES]
+		xs[i] = cx + h.X.Mul(physics.FromInt(radius)).Round()
+		ys[i] = cy + h.Y.Mul(physics.FromInt(radius)).Round()
+	}
+	return xs, ys
+}
+
+// hull is the ship's triangle, nose first, in dots.
+func hull(sh ship) ([]int, []int) {
+	cx, cy := dots(sh.pos)
+	var xs, ys []int
+	for _, p := range []struct{ turn, size int }{
+		{0, SHIPSIZE + 1},
+		{ANGLES * 3 / 8, SHIPSIZE},
+		{-ANGLES * 3 / 8, SHIPSIZE},
+	} {
+		h := headings[((sh.heading+p.turn)%ANGLES+ANGLES)%ANGLES]
+		xs = append(xs, cx+h.X.Mul(physics.FromInt(p.size)).Round())
+		ys = append(ys, cy+h.Y.Mul(physics.FromInt(p.size)).Round())
+	}
+	return xs, ys
+}
+
+func (m Model) fieldView() string {
+	s := m.space
+	c := newCanvas()
+	for _, r := range s.rocks {
+		xs, ys := outline(r)
+		c.polygon(xs, ys, ROCKINK)
+	}
+	for _, b := range s.bullets {
+		x, y := dots(b.pos)
+		c.dot(x, y, BULLETINK)
+	}
+	// A shielded ship blinks.
+	if s.alive && (s.shield/4)%2 == 0 {
+		xs, ys := hull(s.ship)
+		c.polygon(xs, ys, SHIPINK)
+		if s.ship.flame > 0 {
+			tail := headings[(s.ship.heading+ANGLES/2)%ANGLES]
+			cx, cy := dots(s.ship.pos)
+			n := physics.FromInt(SHIPSIZE + 2)
+			c.dot(cx+tail.X.Mul(n).Round(), cy+tail.Y.Mul(n).Round(), BULLETINK)
+		}
+	}
+	return m.BoardStyle.Render(c.render([INKS]lipgloss.Style{m.RockStyle, m.BulletStyle, m.ShipStyle}))
+}
+
+func (m Model) header() string {
+	s := m.space
+	return strings.Join([]string{
+		fmt.Sprintf("Wave %d", s.wave),
+		m.ScoreStyle.Render(fmt.Sprintf("Score %d", s.score)),
+		fmt.Sprintf("Best %d", max(m.best, s.score)),
+		"Ships " + strings.Repeat("▲", s.lives),
+	}, " | ")
+}
+
+func (m Model) status() string {
+	s := m.space
+	switch {
+	case m.paused:
+		return fmt.Sprintf("Paused, '%s' to go on", m.Keys.Pause.Help().Key)
+	case !s.alive:
+		return "Ship lost, the next one is on its way"
+	case len(s.rocks) == 0:
+		return fmt.Sprintf("Wave %d cleared, the next one is coming", s.wave)
+	}
+	return fmt.Sprintf("Large rocks score %d, medium %d and small %d, a ship every %d", points[LARGE], points[MEDIUM], points[SMALL], EXTRALIFE)
+}
+
+func (m Model) overView() string {
+	s := m.space
+	lines := []string{"Game over", fmt.Sprintf("Score %d, wave %d", s.score, s.wave)}
+	if s.score > m.best && m.best > 0 {
+		lines = append(lines, "New best!")
+	}
+	if len(m.top) > 0 {
+		var b strings.Builder
+		b.WriteString("Top scores\n")
+		for i, e := range m.top {
+			fmt.Fprintf(&b, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(b.String(), "\n"))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to play again", m.Keys.Fire.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	if m.space.over {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.overView(),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			m.fieldView(),
+			m.QuitStyle.Render(m.status()),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
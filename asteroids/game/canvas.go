@@ -0,0 +1,128 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Inks color the dots of the canvas. A cell takes the highest ink of its
+// dots, so the ship shows over the rocks it flies past.
+const (
+	ROCKINK = iota
+	BULLETINK
+	SHIPINK
+	INKS
+)
+
+// brailleDots maps a dot within a cell, by row then column, to its bit in
+// the braille block starting at U+2800.
+var brailleDots = [DOTSY][DOTSX]uint8{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// canvas draws in braille dots on a field of WIDTH by HEIGHT cells, each
+// DOTSX by DOTSY dots. Drawing wraps around the edges, like the field.
+type canvas struct {
+	dots [HEIGHT][WIDTH]uint8
+	ink  [HEIGHT][WIDTH]int8
+}
+
+func newCanvas() *canvas {
+	c := &canvas{}
+	for y := range c.ink {
+		for x := range c.ink[y] {
+			c.ink[y][x] = -1
+		}
+	}
+	return c
+}
+
+func (c *canvas) dot(x, y, ink int) {
+	x = (x%FIELDX + FIELDX) % FIELDX
+	y = (y%FIELDY + FIELDY) % FIELDY
+	cx, cy := x/DOTSX, y/DOTSY
+	c.dots[cy][cx] |= brailleDots[y%DOTSY][x%DOTSX]
+	c.ink[cy][cx] = max(c.ink[cy][cx], int8(ink))
+}
+
+// line draws from x0, y0 to x1, y1, Bresenham's way.
+func (c *canvas) line(x0, y0, x1, y1, ink int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.dot(x0, y0, ink)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e := 2 * err; e >= dy {
+			err += dy
+			x0 += sx
+		} else {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// polygon joins the points in order, closing the outline.
+func (c *canvas) polygon(xs, ys []int, ink int) {
+	for i := range xs {
+		j := (i + 1) % len(xs)
+		c.line(xs[i], ys[i], xs[j], ys[j], ink)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// render draws the canvas a row of cells to a line, styling runs of cells
+// of the same ink together.
+func (c *canvas) render(inks [INKS]lipgloss.Style) string {
+	var s strings.Builder
+	for y := range c.dots {
+		run, ink := []rune{}, int8(-1)
+		flush := func() {
+			if len(run) == 0 {
+				return
+			}
+			if ink < 0 {
+				s.WriteString(string(run))
+			} else {
+				s.WriteString(inks[ink].Render(string(run)))
+			}
+			run = run[:0]
+		}
+		for x := range c.dots[y] {
+			i := c.ink[y][x]
+			if i != ink {
+				flush()
+				ink = i
+			}
+			r := ' '
+			if d := c.dots[y][x]; d != 0 {
+				r = rune(0x2800 + int(d))
+			}
+			run = append(run, r)
+		}
+		flush()
+		if y < HEIGHT-1 {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Fire    key.Binding
+	Pause   key.Binding
+	Restart key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Fire:     key.NewBinding(key.WithKeys(" ", "enter", ui.KEYPADENTER), key.WithHelp("space", "fire")),
+		Pause:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pause")),
+		Restart:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new game")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	k.Left.SetHelp(k.Left.Help().Key, "turn left")
+	k.Right.SetHelp(k.Right.Help().Key, "turn right")
+	k.Up.SetHelp(k.Up.Help().Key, "thrust")
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Left, k.Right, k.Up, k.Fire, k.Pause, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Left, k.Right, k.Up, k.Fire},
+		{k.Pause, k.Restart, k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"up":      &k.Up,
+		"fire":    &k.Fire,
+		"pause":   &k.Pause,
+		"restart": &k.Restart,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
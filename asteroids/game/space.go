@@ -0,0 +1,319 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/physics"
+)
+
+// The field is measured in braille dots, DOTSX by DOTSY to a terminal
+// cell, and wraps around on every side.
+const (
+	WIDTH  = 60 // cells
+	HEIGHT = 22
+	DOTSX  = 2
+	DOTSY  = 4
+	FIELDX = WIDTH * DOTSX
+	FIELDY = HEIGHT * DOTSY
+
+	TICK      = 33 * time.Millisecond
+	ANGLES    = 32 // headings the ship can face
+	LIVES     = 3
+	EXTRALIFE = 10000 // points between extra ships
+	WAVE      = 4     // large asteroids in the first wave, one more each wave
+
+	SHIPSIZE   = 3  // dots from the ship's centre to its tail
+	MAXBULLETS = 4  // in flight at once
+	BULLETLIFE = 20 // ticks
+	RESPAWN    = 45 // ticks the ship is gone after a crash
+	SHIELD     = 90 // ticks a new ship can't be hit, blinking
+	WAVEPAUSE  = 60 // ticks between waves
+	SAFE       = 30 // dots kept clear around the ship as a wave comes in
+	FLAME      = 3  // ticks the exhaust shows after a thrust
+	VERTICES   = 9  // of an asteroid's outline
+)
+
+// Asteroid sizes, largest first: each splits in two of the next size.
+const (
+	LARGE = iota
+	MEDIUM
+	SMALL
+)
+
+var (
+	radii  = []int{10, 5, 3}    // dots, by size
+	points = []int{20, 50, 100} // scored for a hit, by size
+	speeds = []physics.Fixed{   // most dots per tick, by size
+		physics.Frac(3, 5),
+		physics.Frac(1, 1),
+		physics.Frac(3, 2),
+	}
+
+	THRUST      = physics.Frac(2, 5)   // dots per tick gained per press
+	MAXSPEED    = physics.FromInt(3)   // of the ship
+	DRAG        = physics.Frac(62, 64) // share of the ship's speed kept each tick
+	BULLETSPEED = physics.FromInt(4)
+)
+
+// headings are the unit vectors the ship can face, 0 pointing up and
+// turning clockwise. They're rounded once, so every host steers alike.
+var headings = func() [ANGLES]physics.Vec {
+	var h [ANGLES]physics.Vec
+	for i := range h {
+		a := 2 * math.Pi * float64(i) / ANGLES
+		h[i] = physics.V(physics.FromFloat(math.Sin(a)), physics.FromFloat(-math.Cos(a)))
+	}
+	return h
+}()
+
+type ship struct {
+	pos, vel physics.Vec
+	heading  int // into headings
+	flame    int // ticks left of the exhaust
+}
+
+type bullet struct {
+	pos, vel physics.Vec
+	life     int
+}
+
+type rock struct {
+	pos, vel physics.Vec
+	size     int
+	shape    [VERTICES]int // radius of each vertex, in dots
+}
+
+// Space is a game of Asteroids: the ship, its bullets and the rocks, on a
+// field that wraps around. Time only moves in step, one TICK at a time.
+type Space struct {
+	ship    ship
+	bullets []bullet
+	rocks   []rock
+	alive   bool
+	respawn int // ticks until the ship is back, while not alive
+	shield  int // ticks left of the new ship's shield
+	pause   int // ticks until the next wave, while the field is clear
+
+	wave  int
+	lives int
+	score int
+	next  int // score of the next extra ship
+	over  bool
+
+	rng *rand.Rand
+}
+
+func NewSpace(rng *rand.Rand) *Space {
+	s := &Space{lives: LIVES, next: EXTRALIFE, rng: rng}
+	s.spawnShip()
+	s.startWave()
+	return s
+}
+
+// centre is the middle of the field.
+func centre() physics.Vec {
+	return physics.V(physics.FromInt(FIELDX/2), physics.FromInt(FIELDY/2))
+}
+
+func (s *Space) spawnShip() {
+	s.ship = ship{pos: centre()}
+	s.alive = true
+	s.shield = SHIELD
+}
+
+// startWave brings in the next wave of large asteroids, away from the ship.
+func (s *Space) startWave() {
+	n := WAVE + s.wave
+	s.wave++
+	for len(s.rocks) < n {
+		pos := physics.V(physics.FromInt(s.rng.Intn(FIELDX)), physics.FromInt(s.rng.Intn(FIELDY)))
+		if near(pos, s.ship.pos, physics.FromInt(SAFE+radii[LARGE])) {
+			continue
+		}
+		s.rocks = append(s.rocks, s.newRock(pos, LARGE))
+	}
+}
+
+// newRock makes an asteroid of size drifting a random way, with a jagged
+// outline of its own.
+func (s *Space) newRock(pos physics.Vec, size int) rock {
+	r := rock{pos: pos, size: size}
+	dir := headings[s.rng.Intn(ANGLES)]
+	speed := speeds[size]/3 + physics.Fixed(s.rng.Int63n(int64(speeds[size]*2/3)+1))
+	r.vel = dir.Scale(speed)
+	for i := range r.shape {
+		r.shape[i] = max(1, radii[size]*(75+s.rng.Intn(40))/100)
+	}
+	return r
+}
+
+// wrap brings v back onto the field.
+func wrap(v physics.Vec) physics.Vec {
+	w, h := physics.FromInt(FIELDX), physics.FromInt(FIELDY)
+	v.X = (v.X%w + w) % w
+	v.Y = (v.Y%h + h) % h
+	return v
+}
+
+// delta is the shortest way from a to b, around the edges if need be.
+func delta(a, b physics.Vec) physics.Vec {
+	w, h := physics.FromInt(FIELDX), physics.FromInt(FIELDY)
+	d := b.Sub(a)
+	switch {
+	case d.X > w/2:
+		d.X -= w
+	case d.X < -w/2:
+		d.X += w
+	}
+	switch {
+	case d.Y > h/2:
+		d.Y -= h
+	case d.Y < -h/2:
+		d.Y += h
+	}
+	return d
+}
+
+// near reports whether a and b are within r of each other.
+func near(a, b physics.Vec, r physics.Fixed) bool {
+	d := delta(a, b)
+	return int64(d.X)*int64(d.X)+int64(d.Y)*int64(d.Y) <= int64(r)*int64(r)
+}
+
+// length is the magnitude of v. Square roots are exact in IEEE floats, so
+// it's the same on every host.
+func length(v physics.Vec) physics.Fixed {
+	return physics.Fixed(math.Sqrt(float64(int64(v.X)*int64(v.X) + int64(v.Y)*int64(v.Y))))
+}
+
+// Turn rotates the ship by steps of a heading, clockwise when positive.
+func (s *Space) Turn(steps int) {
+	if !s.alive || s.over {
+		return
+	}
+	s.ship.heading = ((s.ship.heading+steps)%ANGLES + ANGLES) % ANGLES
+}
+
+// Thrust pushes the ship forward, up to MAXSPEED.
+func (s *Space) Thrust() {
+	if !s.alive || s.over {
+		return
+	}
+	v := s.ship.vel.Add(headings[s.ship.heading].Scale(THRUST))
+	if speed := length(v); speed > MAXSPEED {
+		v = v.Scale(MAXSPEED.Div(speed))
+	}
+	s.ship.vel = v
+	s.ship.flame = FLAME
+}
+
+// Fire shoots from the ship's nose, unless MAXBULLETS are already out.
+func (s *Space) Fire() {
+	if !s.alive || s.over || len(s.bullets) >= MAXBULLETS {
+		return
+	}
+	dir := headings[s.ship.heading]
+	s.bullets = append(s.bullets, bullet{
+		pos:  wrap(s.ship.pos.Add(dir.Scale(physics.FromInt(SHIPSIZE + 1)))),
+		vel:  s.ship.vel.Add(dir.Scale(BULLETSPEED)),
+		life: BULLETLIFE,
+	})
+}
+
+// step moves everything one tick, and reports whether the ship crashed.
+func (s *Space) step() bool {
+	if s.over {
+		return false
+	}
+	for i := range s.rocks {
+		s.rocks[i].pos = wrap(s.rocks[i].pos.Add(s.rocks[i].vel))
+	}
+	flying := s.bullets[:0]
+	for _, b := range s.bullets {
+		b.pos = wrap(b.pos.Add(b.vel))
+		if b.life--; b.life > 0 && !s.shoot(b.pos) {
+			flying = append(flying, b)
+		}
+	}
+	s.bullets = flying
+
+	crashed := false
+	switch {
+	case s.alive:
+		s.ship.vel = s.ship.vel.Scale(DRAG)
+		s.ship.pos = wrap(s.ship.pos.Add(s.ship.vel))
+		s.ship.flame = max(0, s.ship.flame-1)
+		s.shield = max(0, s.shield-1)
+		if s.shield == 0 && s.crashed() {
+			crashed = true
+			s.alive = false
+			s.respawn = RESPAWN
+			s.bullets = nil
+			if s.lives--; s.lives == 0 {
+				s.over = true
+			}
+		}
+	case s.respawn > 0:
+		s.respawn--
+	default:
+		s.spawnShip()
+	}
+
+	if len(s.rocks) == 0 {
+		if s.pause == 0 {
+			s.pause = WAVEPAUSE
+		}
+		if s.pause--; s.pause == 0 {
+			s.startWave()
+		}
+	}
+	return crashed
+}
+
+// shoot reports whether a bullet at pos hits a rock, splitting the rock.
+func (s *Space) shoot(pos physics.Vec) bool {
+	for i, r := range s.rocks {
+		if !near(pos, r.pos, physics.FromInt(radii[r.size])) {
+			continue
+		}
+		s.rocks = append(s.rocks[:i], s.rocks[i+1:]...)
+		s.split(r)
+		s.award(points[r.size])
+		return true
+	}
+	return false
+}
+
+// split breaks r in two of the next size, flying apart, unless it's small.
+func (s *Space) split(r rock) {
+	if r.size == SMALL {
+		return
+	}
+	for range 2 {
+		s.rocks = append(s.rocks, s.newRock(r.pos, r.size+1))
+	}
+}
+
+func (s *Space) award(n int) {
+	s.score += n
+	for s.score >= s.next {
+		s.lives++
+		s.next += EXTRALIFE
+	}
+}
+
+// crashed reports whether the ship touches a rock, splitting the rock.
+func (s *Space) crashed() bool {
+	for i, r := range s.rocks {
+		if !near(s.ship.pos, r.pos, physics.FromInt(radii[r.size]+SHIPSIZE-1)) {
+			continue
+		}
+		s.rocks = append(s.rocks[:i], s.rocks[i+1:]...)
+		s.split(r)
+		s.award(points[r.size])
+		return true
+	}
+	return false
+}
@@ -0,0 +1,437 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/turns"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 100 * time.Millisecond
+
+var reasons = map[string]string{
+	SUNK:       "Fleet sunk",
+	turns.LEFT: "Opponent left",
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	WaterStyle  lipgloss.Style
+	ShipStyle   lipgloss.Style
+	SunkStyle   lipgloss.Style
+	GhostStyle  lipgloss.Style
+	ClashStyle  lipgloss.Style
+	CursorStyle lipgloss.Style
+	HitStyle    lipgloss.Style
+	MissStyle   lipgloss.Style
+	TitleStyle  lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room  string
+	match *Match
+	side  int
+	snap  Snapshot
+
+	// fleet is the ships placed so far, sent to the match once complete.
+	fleet  Fleet
+	down   bool // the next ship runs down rather than right
+	cursor grid.Point
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		WaterStyle:  r.NewStyle().Background(lipgloss.Color("24")),
+		ShipStyle:   r.NewStyle().Background(lipgloss.Color("245")),
+		SunkStyle:   r.NewStyle().Background(lipgloss.Color("88")),
+		GhostStyle:  r.NewStyle().Background(lipgloss.Color("114")),
+		ClashStyle:  r.NewStyle().Background(lipgloss.Color("167")),
+		CursorStyle: r.NewStyle().Background(lipgloss.Color("75")),
+		HitStyle:    r.NewStyle().Foreground(lipgloss.Color("196")).Bold(true),
+		MissStyle:   r.NewStyle().Foreground(lipgloss.Color("15")),
+		TitleStyle:  r.NewStyle().Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting. Players place
+// their fleet while they wait.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = lobby.Join(m.Room, m.Player, now)
+	m.fleet, m.down = nil, false
+	m.cursor = grid.Point{X: SIZE/2 - 1, Y: SIZE/2 - 1}
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.Opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.Leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.match.Poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	m.snap = snap
+	// Fleets go in one seat after the other, so a complete one waits here
+	// for its turn.
+	if m.snap.Phase == turns.PLAYING && m.placed() == nil && m.fleet.Complete() && m.snap.State.ToMove() == m.seat() {
+		if m.match.Play(m.side, Move{Fleet: m.fleet}) {
+			m.refresh(now)
+		}
+	}
+}
+
+func (m Model) seat() int {
+	if m.snap.Phase == turns.WAITING {
+		return 0
+	}
+	return m.snap.Players[m.side].Seat
+}
+
+// placed is the player's fleet as the match has it, nil until it's in.
+func (m Model) placed() Fleet {
+	return m.snap.State.Fleets[m.seat()]
+}
+
+// placing reports whether the player still has ships to place.
+func (m Model) placing() bool {
+	return m.snap.Phase != turns.OVER && len(m.fleet) < len(Classes)
+}
+
+func (m Model) firing() bool {
+	return m.snap.Phase == turns.PLAYING && !m.snap.State.Placing()
+}
+
+func (m Model) myShot() bool {
+	return m.firing() && m.snap.State.ToMove() == m.seat()
+}
+
+// ghost is the next ship to place, under the cursor and kept on the board.
+func (m Model) ghost() Ship {
+	size := Classes[len(m.fleet)].Size
+	at := m.cursor
+	if m.down {
+		at.Y = min(at.Y, SIZE-size)
+	} else {
+		at.X = min(at.X, SIZE-size)
+	}
+	return Ship{Class: len(m.fleet), At: at, Down: m.down}
+}
+
+func (m *Model) moveCursor(d grid.Point) {
+	p := m.cursor.Add(d)
+	m.cursor = grid.Point{X: max(0, min(SIZE-1, p.X)), Y: max(0, min(SIZE-1, p.Y))}
+}
+
+func (m *Model) place() {
+	switch {
+	case m.placing():
+		if s := m.ghost(); m.fleet.Fits(s) {
+			m.fleet = append(m.fleet, s)
+			m.refresh(time.Now())
+		}
+	case m.myShot():
+		if m.match.Play(m.side, Move{Shot: m.cursor}) {
+			m.refresh(time.Now())
+		}
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.Leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == turns.OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Rotate):
+			m.down = !m.down
+		case key.Matches(msg, m.Keys.Undo):
+			// Once the match has the fleet, it stays where it is.
+			if len(m.fleet) > 0 && m.placed() == nil && m.snap.Phase != turns.OVER {
+				m.fleet = m.fleet[:len(m.fleet)-1]
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(grid.Right)
+		case key.Matches(msg, m.Keys.Place):
+			m.place()
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+// cell draws a square with a mark, or water when blank.
+func cell(style lipgloss.Style, mark string) string {
+	if mark == "" {
+		mark = " "
+	}
+	return style.Render(" " + mark + " ")
+}
+
+// ownCell draws p of the player's own waters: their ships, the one being
+// placed, and where the opponent fired.
+func (m Model) ownCell(p grid.Point, shots []grid.Point) string {
+	fleet := m.fleet
+	if placed := m.placed(); placed != nil {
+		fleet = placed
+	}
+	style := m.WaterStyle
+	i := fleet.At(p)
+	switch {
+	case m.placing() && slices.Contains(m.ghost().Cells(), p):
+		style = m.GhostStyle
+		if i >= 0 {
+			style = m.ClashStyle
+		}
+	case i >= 0 && fleet.Sunk(i, shots):
+		style = m.SunkStyle
+	case i >= 0:
+		style = m.ShipStyle
+	}
+	switch {
+	case slices.Contains(shots, p) && i >= 0:
+		return cell(style.Inherit(m.HitStyle), "X")
+	case slices.Contains(shots, p):
+		return cell(style.Inherit(m.MissStyle), "•")
+	}
+	return cell(style, "")
+}
+
+// enemyCell draws p of the opponent's waters as the player knows them:
+// their shots, the ships they sank, and the whole fleet once it's over.
+func (m Model) enemyCell(p grid.Point, fleet Fleet, shots []grid.Point) string {
+	style := m.WaterStyle
+	i := fleet.At(p)
+	shot := slices.Contains(shots, p)
+	switch {
+	case m.snap.Phase == turns.PLAYING && p == m.cursor && !m.placing():
+		style = m.CursorStyle
+	case i >= 0 && fleet.Sunk(i, shots):
+		style = m.SunkStyle
+	case i >= 0 && m.snap.Phase == turns.OVER:
+		style = m.ShipStyle
+	}
+	switch {
+	case shot && i >= 0:
+		return cell(style.Inherit(m.HitStyle), "X")
+	case shot:
+		return cell(style.Inherit(m.MissStyle), "•")
+	}
+	return cell(style, "")
+}
+
+func (m Model) waters(title string, draw func(p grid.Point) string) string {
+	var s strings.Builder
+	s.WriteString("   ")
+	for x := 0; x < SIZE; x++ {
+		fmt.Fprintf(&s, " %c ", 'A'+x)
+	}
+	s.WriteString("\n")
+	for y := 0; y < SIZE; y++ {
+		fmt.Fprintf(&s, "%2d ", y+1)
+		for x := 0; x < SIZE; x++ {
+			s.WriteString(draw(grid.Point{X: x, Y: y}))
+		}
+		if y < SIZE-1 {
+			s.WriteString("\n")
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, m.TitleStyle.Render(title), s.String())
+}
+
+func (m Model) boardsView() string {
+	seat := m.seat()
+	b := m.snap.State
+	own := m.waters("Your fleet", func(p grid.Point) string { return m.ownCell(p, b.Shots[1-seat]) })
+	enemy := m.waters("Enemy waters", func(p grid.Point) string { return m.enemyCell(p, b.Fleets[1-seat], b.Shots[seat]) })
+	return lipgloss.JoinHorizontal(lipgloss.Top, own, "    ", enemy)
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Players {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		sides = append(sides, name)
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+func square(p grid.Point) string {
+	return fmt.Sprintf("%c%d", 'A'+p.X, p.Y+1)
+}
+
+// lastShot tells how the last shot went, empty before the first.
+func (m Model) lastShot() string {
+	b := m.snap.State
+	shooter := b.LastShooter()
+	if shooter < 0 {
+		return ""
+	}
+	shots := b.Shots[shooter]
+	p := shots[len(shots)-1]
+	who := m.snap.Players[1-m.side].Name
+	if shooter == m.seat() {
+		who = "You"
+	}
+	fleet := b.Fleets[1-shooter]
+	switch i := fleet.At(p); {
+	case i < 0:
+		return fmt.Sprintf("%s fired at %s: miss", who, square(p))
+	case fleet.Sunk(i, shots):
+		return fmt.Sprintf("%s fired at %s: sank the %s!", who, square(p), Classes[i].Name)
+	}
+	return fmt.Sprintf("%s fired at %s: hit!", who, square(p))
+}
+
+func (m Model) status() string {
+	switch {
+	case m.placing():
+		c := Classes[len(m.fleet)]
+		s := fmt.Sprintf("Place your %s (%d) • %s rotate", c.Name, c.Size, m.Keys.Rotate.Help().Key)
+		if m.snap.Phase == turns.WAITING {
+			s = "Waiting for an opponent... • " + s
+		}
+		return s
+	case m.snap.Phase == turns.WAITING:
+		return "Fleet ready • waiting for an opponent..."
+	case !m.firing():
+		return fmt.Sprintf("Fleet ready • waiting for %s to place theirs...", m.snap.Players[1-m.side].Name)
+	}
+	s := fmt.Sprintf("%s is aiming...", m.snap.Players[1-m.side].Name)
+	if m.myShot() {
+		s = "Your shot"
+	}
+	if last := m.lastShot(); last != "" {
+		s = last + " • " + s
+	}
+	return s
+}
+
+func (m Model) resultView() string {
+	outcome := m.snap.Players[m.snap.Winner].Name + " wins"
+	if m.snap.Winner == m.side {
+		outcome = "You win!"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s: %s\n\nPress '%s' for a new match",
+		reasons[m.snap.Reason], outcome, m.Keys.Rematch.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == turns.OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardsView(),
+			"",
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
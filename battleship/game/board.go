@@ -0,0 +1,176 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const SIZE = 10
+
+// Class is a kind of ship.
+type Class struct {
+	Name string
+	Size int
+}
+
+// Classes is every fleet, in the order ships are placed.
+var Classes = []Class{
+	{"Carrier", 5},
+	{"Battleship", 4},
+	{"Cruiser", 3},
+	{"Submarine", 3},
+	{"Destroyer", 2},
+}
+
+type Ship struct {
+	Class int // in Classes
+	At    grid.Point
+	Down  bool // running down from At, rather than right
+}
+
+// Cells are the squares the ship covers.
+func (s Ship) Cells() []grid.Point {
+	d := grid.Right
+	if s.Down {
+		d = grid.Down
+	}
+	cells := make([]grid.Point, Classes[s.Class].Size)
+	for i, p := 0, s.At; i < len(cells); i, p = i+1, p.Add(d) {
+		cells[i] = p
+	}
+	return cells
+}
+
+func inBounds(p grid.Point) bool {
+	return p.X >= 0 && p.X < SIZE && p.Y >= 0 && p.Y < SIZE
+}
+
+// Fleet is a player's ships, Classes[i] at i once complete.
+type Fleet []Ship
+
+// At is the index of the ship covering p, -1 for open water.
+func (f Fleet) At(p grid.Point) int {
+	for i, s := range f {
+		if slices.Contains(s.Cells(), p) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Fits reports whether s can join the fleet: on the board, and clear of
+// the other ships. Ships may touch.
+func (f Fleet) Fits(s Ship) bool {
+	for _, p := range s.Cells() {
+		if !inBounds(p) || f.At(p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Complete reports whether the fleet has every ship, each where it fits.
+func (f Fleet) Complete() bool {
+	if len(f) != len(Classes) {
+		return false
+	}
+	for i, s := range f {
+		if s.Class != i || !f[:i].Fits(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sunk reports whether every square of ship i was shot.
+func (f Fleet) Sunk(i int, shots []grid.Point) bool {
+	for _, p := range f[i].Cells() {
+		if !slices.Contains(shots, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Destroyed reports whether the whole fleet went down.
+func (f Fleet) Destroyed(shots []grid.Point) bool {
+	for i := range f {
+		if !f.Sunk(i, shots) {
+			return false
+		}
+	}
+	return len(f) > 0
+}
+
+// Board is a match: both fleets, nil until placed, and where each seat
+// fired at the other's.
+type Board struct {
+	Fleets [2]Fleet
+	Shots  [2][]grid.Point
+}
+
+// Move places a seat's fleet, before the firing starts, or fires a shot.
+type Move struct {
+	Fleet Fleet
+	Shot  grid.Point
+}
+
+// Placing reports whether ships are still being placed.
+func (b Board) Placing() bool {
+	return b.Fleets[0] == nil || b.Fleets[1] == nil
+}
+
+// ToMove is the seat that places its fleet next, or fires next once both
+// are placed. The first seat fires first.
+func (b Board) ToMove() int {
+	switch {
+	case b.Fleets[0] == nil:
+		return 0
+	case b.Fleets[1] == nil:
+		return 1
+	case len(b.Shots[0]) > len(b.Shots[1]):
+		return 1
+	}
+	return 0
+}
+
+// LastShooter is the seat that fired last, -1 before anyone has.
+func (b Board) LastShooter() int {
+	switch {
+	case len(b.Shots[0]) == 0:
+		return -1
+	case len(b.Shots[0]) > len(b.Shots[1]):
+		return 0
+	}
+	return 1
+}
+
+// Play makes m for the seat to move, if it's legal: a complete fleet while
+// placing, then a shot at a square not shot at before.
+func (b Board) Play(m Move) (Board, bool) {
+	seat := b.ToMove()
+	if b.Placing() {
+		if !m.Fleet.Complete() {
+			return b, false
+		}
+		b.Fleets[seat] = slices.Clone(m.Fleet)
+		return b, true
+	}
+	if !inBounds(m.Shot) || slices.Contains(b.Shots[seat], m.Shot) {
+		return b, false
+	}
+	// Clipped, so earlier snapshots keep the shots they had.
+	b.Shots[seat] = append(slices.Clip(b.Shots[seat]), m.Shot)
+	return b, true
+}
+
+// Winner is the seat that sank the other's fleet, -1 while both float.
+func (b Board) Winner() int {
+	for seat := range b.Fleets {
+		if b.Fleets[1-seat].Destroyed(b.Shots[seat]) {
+			return seat
+		}
+	}
+	return -1
+}
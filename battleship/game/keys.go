@@ -0,0 +1,67 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Place   key.Binding
+	Rotate  key.Binding
+	Undo    key.Binding
+	Rematch key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Place:    key.NewBinding(key.WithKeys("enter", " ", ui.KEYPADENTER), key.WithHelp("enter", "place/fire")),
+		Rotate:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rotate")),
+		Undo:     key.NewBinding(key.WithKeys("u", "backspace"), key.WithHelp("u", "take back a ship")),
+		Rematch:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new match")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Place, k.Rotate, k.Undo, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Place, k.Rotate, k.Undo, k.Rematch},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":      &k.Up,
+		"down":    &k.Down,
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"place":   &k.Place,
+		"rotate":  &k.Rotate,
+		"undo":    &k.Undo,
+		"rematch": &k.Rematch,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
@@ -0,0 +1,23 @@
+package game
+
+import "github.com/debemdeboas/games.debem.dev/turns"
+
+// SUNK is why matches end on the board.
+const SUNK = "fleet sunk"
+
+type (
+	Match    = turns.Match[Board, Move]
+	Snapshot = turns.Snapshot[Board, Move]
+)
+
+var lobby = turns.NewLobby(turns.Rules[Board, Move]{
+	Start:  func() Board { return Board{} },
+	ToMove: Board.ToMove,
+	Play:   Board.Play,
+	Result: func(b Board) (int, string, bool) {
+		if w := b.Winner(); w >= 0 {
+			return w, SUNK, true
+		}
+		return 0, "", false
+	},
+})
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "battleship"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Battleship",
+	Description: "Hide a fleet and sink your opponent's, shot by shot",
+	Category:    games.BOARD,
+	MinPlayers:  2,
+	MaxPlayers:  2,
+	Rooms:       true,
+	Session:     15 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		if env.Room != "" {
+			m.SetRoom(env.Room)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,135 @@
+// Package bell plays audio cues as timed patterns of the terminal bell,
+// BEL, the only sound that reaches players over SSH. Terminals beep or
+// flash on it, so cues are opt-in: players turn them on event by event.
+package bell
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Event string
+
+const (
+	EAT       Event = "eat"       // food eaten
+	DEATH     Event = "death"     // a life or the game lost
+	COUNTDOWN Event = "countdown" // each second before a round starts
+)
+
+// Events lists every event, in the order settings show them.
+var Events = []Event{EAT, DEATH, COUNTDOWN}
+
+// Labels describe the events to players.
+var Labels = map[Event]string{
+	EAT:       "Eating food",
+	DEATH:     "Losing a life or the game",
+	COUNTDOWN: "Countdowns before a round",
+}
+
+// patterns are the pauses before each bell of an event's cue.
+var patterns = map[Event][]time.Duration{
+	EAT:       {0},
+	DEATH:     {0, 150 * time.Millisecond, 150 * time.Millisecond},
+	COUNTDOWN: {0},
+}
+
+const BEL = "\a"
+
+// Bell rings the cues of the events a player turned on into their
+// session's output, alongside the renderer's: a BEL landing in the middle
+// of a frame's escape sequences is still just a bell to terminals, so cues
+// don't garble the screen. Methods are no-ops on a nil Bell.
+type Bell struct {
+	ctx context.Context
+	out io.Writer
+
+	mu      sync.Mutex
+	on      map[Event]bool
+	ringing atomic.Bool // a cue is playing, new ones are dropped until it ends
+}
+
+// New rings the cues of the events named in on into out until ctx is done.
+func New(ctx context.Context, out io.Writer, on []string) *Bell {
+	b := &Bell{ctx: ctx, out: out, on: make(map[Event]bool)}
+	for _, e := range on {
+		b.on[Event(e)] = true
+	}
+	return b
+}
+
+// On reports whether the player turned e's cue on.
+func (b *Bell) On(e Event) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.on[e]
+}
+
+// Set turns e's cue on or off.
+func (b *Bell) Set(e Event, on bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.on[e] = on
+}
+
+// Enabled names the events that are on, in Events order, as profiles keep
+// them.
+func (b *Bell) Enabled() []string {
+	var names []string
+	for _, e := range Events {
+		if b.On(e) {
+			names = append(names, string(e))
+		}
+	}
+	return names
+}
+
+// Ring plays e's cue in the background, if it's on and no other cue is
+// playing.
+func (b *Bell) Ring(e Event) {
+	pattern, ok := patterns[e]
+	if !ok || !b.On(e) || !b.ringing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer b.ringing.Store(false)
+		for _, pause := range pattern {
+			if pause > 0 {
+				select {
+				case <-b.ctx.Done():
+					return
+				case <-time.After(pause):
+				}
+			}
+			if _, err := io.WriteString(b.out, BEL); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// Countdown rings COUNTDOWN once as each second of a countdown starts.
+type Countdown struct {
+	shown int // the second last rung for, 0 when not counting
+}
+
+// Update is told the time left whenever the countdown is polled, zero or
+// less once it's over.
+func (c *Countdown) Update(b *Bell, left time.Duration) {
+	if left <= 0 {
+		c.shown = 0
+		return
+	}
+	if s := int(left.Seconds()) + 1; s != c.shown {
+		c.shown = s
+		b.Ring(COUNTDOWN)
+	}
+}
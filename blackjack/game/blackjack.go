@@ -0,0 +1,589 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	STARTCHIPS = 1000
+	MINBET     = 10
+	MAXBET     = 500
+	BETSTEP    = 10
+	MAXHANDS   = 4 // splitting stops at this many hands
+	DECKS      = 6 // in the shoe, unless the player picks otherwise
+)
+
+// deckOptions are the shoes the player can pick from.
+var deckOptions = []int{1, 2, 4, 6, 8}
+
+// Phases
+const (
+	BETTING = iota // between rounds, showing the last one's outcome
+	PLAYING
+)
+
+// Rows of the rules screen
+const (
+	DECKSRULE = iota
+	SOFT17RULE
+	RULES
+)
+
+// table is what the player keeps between sessions: their chips and the
+// rules they play by.
+type table struct {
+	Chips     int
+	Bet       int
+	Decks     int
+	HitSoft17 bool // whether the dealer hits a soft 17 rather than standing
+	Refills   int  // times the house topped the chips back up
+}
+
+func newTable() table {
+	return table{Chips: STARTCHIPS, Bet: MINBET, Decks: DECKS}
+}
+
+// fix keeps a loaded table within bounds, in case the save is off.
+func (t *table) fix() {
+	t.Chips = max(0, t.Chips)
+	t.Bet = max(MINBET, min(t.Bet, MAXBET))
+	if !slices.Contains(deckOptions, t.Decks) {
+		t.Decks = DECKS
+	}
+}
+
+type hand struct {
+	cards []Card
+	bet   int
+	done  bool
+	split bool // came from a split, so 21 on two cards isn't a blackjack
+}
+
+func (h hand) total() int {
+	total, _ := score(h.cards)
+	return total
+}
+
+func (h hand) bust() bool {
+	return h.total() > BLACKJACK
+}
+
+func (h hand) blackjack() bool {
+	return !h.split && natural(h.cards)
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	CardStyle   lipgloss.Style
+	RedStyle    lipgloss.Style
+	BackStyle   lipgloss.Style
+	ActiveStyle lipgloss.Style // the hand being played
+	WinStyle    lipgloss.Style
+	FailStyle   lipgloss.Style
+	ChipStyle   lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// profile, when set, keeps the table, chips included, saved back to
+	// Profiles after every bet and round.
+	profile     *profile.Profile
+	Profiles    profile.Store
+	Fingerprint string
+
+	table    table
+	shoe     *Shoe
+	phase    int
+	hands    []hand
+	active   int
+	dealer   []Card
+	outcomes []string // of each hand, once the round is over
+	net      int      // chips won or lost in the last round
+	message  string
+
+	showRules bool
+	ruleAt    int
+	showHelp  bool
+
+	rng *rand.Rand
+}
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		CardStyle:   r.NewStyle().Background(lipgloss.Color("255")).Foreground(lipgloss.Color("0")),
+		RedStyle:    r.NewStyle().Background(lipgloss.Color("255")).Foreground(lipgloss.Color("160")),
+		BackStyle:   r.NewStyle().Background(lipgloss.Color("25")).Foreground(lipgloss.Color("33")),
+		ActiveStyle: r.NewStyle().Foreground(lipgloss.Color("220")).Bold(true),
+		WinStyle:    r.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+		FailStyle:   r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+		ChipStyle:   r.NewStyle().Foreground(lipgloss.Color("11")).Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:  DefaultKeyMap(),
+		table: newTable(),
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.shoe = newShoe(m.table.Decks, m.rng)
+	return m
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetProfile seats the player with the chips and rules saved to their
+// profile, and saves them back to store. Players without a key get a
+// profile that's never saved, so they start from STARTCHIPS every time.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+	ui.Rebind(&m.Keys, p.Keys)
+
+	t := newTable()
+	if ok, err := p.GetSave(GAMENAME, &t); err != nil {
+		log.Warn("Could not read blackjack save", "err", err)
+		t = newTable()
+	} else if ok {
+		t.fix()
+	}
+	m.table = t
+	m.shoe = newShoe(t.Decks, m.rng)
+	m.refill()
+}
+
+func (m *Model) save() {
+	if m.profile == nil {
+		return
+	}
+	profile.Update(m.Profiles, m.Fingerprint, m.profile, func(p *profile.Profile) {
+		if err := p.SetSave(GAMENAME, m.table); err != nil {
+			log.Warn("Could not encode blackjack save", "err", err)
+		}
+	})
+}
+
+// refill tops the chips back up when they can't cover the smallest bet.
+func (m *Model) refill() {
+	if m.table.Chips >= MINBET {
+		return
+	}
+	m.table.Chips = STARTCHIPS
+	m.table.Refills++
+	m.message = fmt.Sprintf("The house spots you %d chips", STARTCHIPS)
+	m.save()
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// maxBet is the largest bet the table and the player's chips allow.
+func (m Model) maxBet() int {
+	return max(MINBET, min(MAXBET, m.table.Chips))
+}
+
+func (m *Model) changeBet(delta int) {
+	m.table.Bet = max(MINBET, min(m.table.Bet+delta, m.maxBet()))
+}
+
+// Deal takes the bet and starts a round, shuffling first once the shoe is
+// past its cut card. Blackjacks settle the round right away, the dealer's
+// too since they peek at their hole card.
+func (m *Model) Deal() {
+	if m.shoe.due() {
+		m.shoe.shuffle()
+		m.message = "The shoe is shuffled"
+	} else {
+		m.message = ""
+	}
+	bet := min(m.table.Bet, m.table.Chips)
+	m.table.Chips -= bet
+	m.save()
+
+	m.hands = []hand{{cards: []Card{m.shoe.draw()}, bet: bet}}
+	m.dealer = []Card{m.shoe.draw()}
+	m.hands[0].cards = append(m.hands[0].cards, m.shoe.draw())
+	m.dealer = append(m.dealer, m.shoe.draw())
+	m.active = 0
+	m.outcomes = nil
+	m.phase = PLAYING
+
+	if natural(m.dealer) || m.hands[0].blackjack() {
+		m.settle()
+	}
+}
+
+func (m *Model) current() *hand {
+	return &m.hands[m.active]
+}
+
+func (m *Model) hit() {
+	h := m.current()
+	h.cards = append(h.cards, m.shoe.draw())
+	if h.total() >= BLACKJACK {
+		m.stand()
+	}
+}
+
+func (m *Model) stand() {
+	m.current().done = true
+	m.next()
+}
+
+func (m Model) canDouble() bool {
+	h := m.hands[m.active]
+	return len(h.cards) == 2 && m.table.Chips >= h.bet
+}
+
+// double doubles the bet for exactly one more card.
+func (m *Model) double() {
+	if !m.canDouble() {
+		return
+	}
+	h := m.current()
+	m.table.Chips -= h.bet
+	h.bet *= 2
+	h.cards = append(h.cards, m.shoe.draw())
+	m.save()
+	m.stand()
+}
+
+func (m Model) canSplit() bool {
+	h := m.hands[m.active]
+	return len(h.cards) == 2 && h.cards[0].value() == h.cards[1].value() &&
+		len(m.hands) < MAXHANDS && m.table.Chips >= h.bet
+}
+
+// split makes two hands of a pair, each with the same bet and a new second
+// card. Split aces get their one card and stand.
+func (m *Model) split() {
+	if !m.canSplit() {
+		return
+	}
+	h := m.current()
+	m.table.Chips -= h.bet
+	aces := h.cards[0].Rank == 1
+	second := hand{cards: []Card{h.cards[1], m.shoe.draw()}, bet: h.bet, split: true, done: aces}
+	h.cards = []Card{h.cards[0], m.shoe.draw()}
+	h.split, h.done = true, aces
+	m.hands = slices.Insert(m.hands, m.active+1, second)
+	m.save()
+	if aces || h.total() == BLACKJACK {
+		m.stand()
+	}
+}
+
+// next moves on to the next hand still to play, or to the dealer once
+// there's none.
+func (m *Model) next() {
+	for m.active < len(m.hands) && m.hands[m.active].done {
+		m.active++
+	}
+	if m.active < len(m.hands) {
+		if m.current().total() == BLACKJACK {
+			m.stand()
+		}
+		return
+	}
+	m.active = len(m.hands) - 1
+	if slices.ContainsFunc(m.hands, func(h hand) bool { return !h.bust() }) {
+		for dealerHits(m.dealer, m.table.HitSoft17) {
+			m.dealer = append(m.dealer, m.shoe.draw())
+		}
+	}
+	m.settle()
+}
+
+// settle pays every hand against the dealer's: blackjacks 3 to 2, wins 1
+// to 1 and pushes their bet back.
+func (m *Model) settle() {
+	dealer, _ := score(m.dealer)
+	dealerBust := dealer > BLACKJACK
+	m.net = 0
+	m.outcomes = make([]string, len(m.hands))
+	for i, h := range m.hands {
+		pay := 0
+		switch {
+		case h.blackjack() && natural(m.dealer):
+			pay, m.outcomes[i] = h.bet, "push"
+		case h.blackjack():
+			pay, m.outcomes[i] = h.bet+h.bet*3/2, "blackjack!"
+		case h.bust():
+			m.outcomes[i] = "bust"
+		case natural(m.dealer):
+			m.outcomes[i] = "dealer blackjack"
+		case dealerBust || h.total() > dealer:
+			pay, m.outcomes[i] = 2*h.bet, "win"
+		case h.total() == dealer:
+			pay, m.outcomes[i] = h.bet, "push"
+		default:
+			m.outcomes[i] = "lose"
+		}
+		m.table.Chips += pay
+		m.net += pay - h.bet
+	}
+	m.phase = BETTING
+	m.table.Bet = min(m.table.Bet, m.maxBet())
+	m.save()
+	m.refill()
+}
+
+// changeRule steps the rule under the cursor of the rules screen. A new
+// number of decks takes a fresh shoe.
+func (m *Model) changeRule(delta int) {
+	switch m.ruleAt {
+	case DECKSRULE:
+		i := slices.Index(deckOptions, m.table.Decks)
+		m.table.Decks = deckOptions[(i+delta+len(deckOptions))%len(deckOptions)]
+		m.shoe = newShoe(m.table.Decks, m.rng)
+	case SOFT17RULE:
+		m.table.HitSoft17 = !m.table.HitSoft17
+	}
+	m.save()
+}
+
+func (m *Model) updateRules(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.Keys.Rules):
+		m.showRules = false
+	case key.Matches(msg, m.Keys.Up):
+		m.ruleAt = (m.ruleAt + RULES - 1) % RULES
+	case key.Matches(msg, m.Keys.Down):
+		m.ruleAt = (m.ruleAt + 1) % RULES
+	case key.Matches(msg, m.Keys.Left):
+		m.changeRule(-1)
+	case key.Matches(msg, m.Keys.Right), key.Matches(msg, m.Keys.Deal):
+		m.changeRule(1)
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case m.showRules:
+			m.updateRules(msg)
+		case m.phase == BETTING:
+			switch {
+			case key.Matches(msg, m.Keys.Rules):
+				m.showRules = true
+			case key.Matches(msg, m.Keys.Left), key.Matches(msg, m.Keys.Down):
+				m.changeBet(-BETSTEP)
+			case key.Matches(msg, m.Keys.Right), key.Matches(msg, m.Keys.Up):
+				m.changeBet(BETSTEP)
+			case key.Matches(msg, m.Keys.Deal):
+				m.Deal()
+			}
+		case key.Matches(msg, m.Keys.Hit):
+			m.hit()
+		case key.Matches(msg, m.Keys.Stand):
+			m.stand()
+		case key.Matches(msg, m.Keys.Double):
+			m.double()
+		case key.Matches(msg, m.Keys.Split):
+			m.split()
+		}
+	}
+	return m, nil
+}
+
+// cardView draws a card three lines tall, or its back.
+func (m Model) cardView(c Card, hidden bool) string {
+	style, label := m.BackStyle, "░░░"
+	if !hidden {
+		style, label = m.CardStyle, fmt.Sprintf("%-3s", c)
+		if c.red() {
+			style = m.RedStyle
+		}
+	}
+	return style.Render("┌───┐\n│" + label + "│\n└───┘")
+}
+
+// cardsView lays cards out in a row, the second one face down when hole is
+// set.
+func (m Model) cardsView(cards []Card, hole bool) string {
+	views := make([]string, 0, 2*len(cards))
+	for i, c := range cards {
+		if i > 0 {
+			views = append(views, " ")
+		}
+		views = append(views, m.cardView(c, hole && i == 1))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
+
+func (m Model) dealerView() string {
+	if len(m.dealer) == 0 {
+		return "Dealer"
+	}
+	hole := m.phase == PLAYING
+	title := "Dealer"
+	if hole {
+		up, _ := score(m.dealer[:1])
+		title += fmt.Sprintf(" shows %d", up)
+	} else {
+		total, _ := score(m.dealer)
+		title += fmt.Sprintf(" has %d", total)
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, title, m.cardsView(m.dealer, hole))
+}
+
+func (m Model) handView(i int) string {
+	h := m.hands[i]
+	total, soft := score(h.cards)
+	title := fmt.Sprintf("%d", total)
+	if soft && total < BLACKJACK {
+		title = "soft " + title
+	}
+	title += fmt.Sprintf(" • bet %d", h.bet)
+	switch {
+	case m.outcomes != nil:
+		outcome := m.outcomes[i]
+		style := m.FailStyle
+		if outcome == "win" || outcome == "blackjack!" {
+			style = m.WinStyle
+		} else if outcome == "push" {
+			style = m.QuitStyle
+		}
+		title += " • " + style.Render(outcome)
+	case m.phase == PLAYING && i == m.active && len(m.hands) > 1:
+		title = m.ActiveStyle.Render("▸ " + title)
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, m.cardsView(h.cards, false), title)
+}
+
+func (m Model) handsView() string {
+	if len(m.hands) == 0 {
+		return ""
+	}
+	var views []string
+	for i := range m.hands {
+		if i > 0 {
+			views = append(views, "   ")
+		}
+		views = append(views, m.handView(i))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
+
+// soft17 is what the dealer does on a soft 17.
+func (m Model) soft17() string {
+	if m.table.HitSoft17 {
+		return "hits"
+	}
+	return "stands"
+}
+
+func (m Model) rulesText() string {
+	return fmt.Sprintf("%d deck(s), dealer %s on soft 17", m.table.Decks, m.soft17())
+}
+
+func (m Model) rulesView() string {
+	rows := []string{
+		fmt.Sprintf("Decks in the shoe    ◂ %d ▸", m.table.Decks),
+		fmt.Sprintf("Dealer on soft 17    ◂ %s ▸", m.soft17()),
+	}
+	for i := range rows {
+		if i == m.ruleAt {
+			rows[i] = m.ActiveStyle.Render(rows[i])
+		}
+	}
+	lines := append([]string{"Table rules", ""}, rows...)
+	lines = append(lines, "", m.QuitStyle.Render(fmt.Sprintf("%s back", m.Keys.Rules.Help().Key)))
+	return m.BoxStyle.Align(lipgloss.Left).Render(strings.Join(lines, "\n"))
+}
+
+// status tells the player what they can do, and how the last round went.
+func (m Model) status() string {
+	if m.phase == PLAYING {
+		actions := []string{m.Keys.Hit.Help().Key + " hit", m.Keys.Stand.Help().Key + " stand"}
+		if m.canDouble() {
+			actions = append(actions, m.Keys.Double.Help().Key+" double")
+		}
+		if m.canSplit() {
+			actions = append(actions, m.Keys.Split.Help().Key+" split")
+		}
+		return strings.Join(actions, " • ")
+	}
+	var lines []string
+	switch {
+	case m.outcomes == nil:
+	case m.net > 0:
+		lines = append(lines, m.WinStyle.Render(fmt.Sprintf("You won %d chips", m.net)))
+	case m.net < 0:
+		lines = append(lines, m.FailStyle.Render(fmt.Sprintf("You lost %d chips", -m.net)))
+	default:
+		lines = append(lines, "You broke even")
+	}
+	lines = append(lines, fmt.Sprintf("Bet %s  ←/→ change • %s deal • %s rules",
+		m.ChipStyle.Render(fmt.Sprint(m.table.Bet)), m.Keys.Deal.Help().Key, m.Keys.Rules.Help().Key))
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) header() string {
+	return fmt.Sprintf("Blackjack | Chips: %s | %s",
+		m.ChipStyle.Render(fmt.Sprint(m.table.Chips)), m.rulesText())
+}
+
+// body is the table with what goes around it, as View centers it.
+func (m Model) body() string {
+	if m.showRules {
+		return m.rulesView()
+	}
+	parts := []string{m.header(), "", m.dealerView(), "", m.handsView(), "", m.status()}
+	if m.message != "" {
+		parts = append(parts, m.QuitStyle.Render(m.message))
+	}
+	parts = append(parts, "", m.help.ShortHelpView(m.Keys.ShortHelp()))
+	return lipgloss.JoinVertical(lipgloss.Center, parts...)
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		m.body(),
+	)
+}
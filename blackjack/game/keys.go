@@ -0,0 +1,75 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// KeyMap bets with left and right and walks the table rules with up and
+// down.
+type KeyMap struct {
+	ui.MoveKeys
+	Deal   key.Binding
+	Hit    key.Binding
+	Stand  key.Binding
+	Double key.Binding
+	Split  key.Binding
+	Rules  key.Binding
+	Layout key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Deal:     key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "deal")),
+		Hit:      key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "hit")),
+		Stand:    key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "stand")),
+		Double:   key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "double down")),
+		Split:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "split")),
+		Rules:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "table rules")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Hit, k.Stand, k.Double, k.Split, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Deal, k.Hit, k.Stand, k.Double, k.Split},
+		{k.Rules, k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":     &k.Up,
+		"down":   &k.Down,
+		"left":   &k.Left,
+		"right":  &k.Right,
+		"deal":   &k.Deal,
+		"hit":    &k.Hit,
+		"stand":  &k.Stand,
+		"double": &k.Double,
+		"split":  &k.Split,
+		"rules":  &k.Rules,
+		"layout": &k.Layout,
+		"help":   &k.Help,
+		"quit":   &k.Quit,
+	}
+}
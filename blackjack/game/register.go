@@ -0,0 +1,39 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "blackjack"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Blackjack",
+	Description: "Beat the dealer to 21 with chips that stay with your key",
+	Category:    games.BOARD,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     10 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		if env.Profile != nil {
+			m.SetProfile(env.Profile, env.Profiles, env.Fingerprint)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
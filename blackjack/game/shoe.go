@@ -0,0 +1,107 @@
+package game
+
+import "math/rand"
+
+const (
+	RANKS = 13
+	SUITS = 4
+
+	BLACKJACK = 21
+	// PENETRATION is how much of the shoe is dealt before it's shuffled
+	// again, in percent.
+	PENETRATION = 75
+)
+
+var (
+	suitGlyphs = [SUITS]string{"♠", "♥", "♦", "♣"}
+	rankLabels = [RANKS + 1]string{"", "A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+)
+
+type Card struct {
+	Rank int // 1 for aces to RANKS for kings
+	Suit int
+}
+
+func (c Card) red() bool {
+	return c.Suit == 1 || c.Suit == 2
+}
+
+func (c Card) String() string {
+	return rankLabels[c.Rank] + suitGlyphs[c.Suit]
+}
+
+// value is what the card counts for, aces as 1.
+func (c Card) value() int {
+	return min(c.Rank, 10)
+}
+
+// Shoe holds the decks cards are dealt from, shuffled together.
+type Shoe struct {
+	decks int
+	cards []Card
+	rng   *rand.Rand
+}
+
+func newShoe(decks int, rng *rand.Rand) *Shoe {
+	s := &Shoe{decks: decks, rng: rng}
+	s.shuffle()
+	return s
+}
+
+func (s *Shoe) shuffle() {
+	s.cards = s.cards[:0]
+	for d := 0; d < s.decks; d++ {
+		for suit := 0; suit < SUITS; suit++ {
+			for rank := 1; rank <= RANKS; rank++ {
+				s.cards = append(s.cards, Card{Rank: rank, Suit: suit})
+			}
+		}
+	}
+	s.rng.Shuffle(len(s.cards), func(i, j int) { s.cards[i], s.cards[j] = s.cards[j], s.cards[i] })
+}
+
+func (s *Shoe) size() int {
+	return s.decks * RANKS * SUITS
+}
+
+// due reports whether the shoe is past its cut card, to shuffle before the
+// next round.
+func (s *Shoe) due() bool {
+	return len(s.cards)*100 < s.size()*(100-PENETRATION)
+}
+
+func (s *Shoe) draw() Card {
+	if len(s.cards) == 0 {
+		s.shuffle()
+	}
+	c := s.cards[len(s.cards)-1]
+	s.cards = s.cards[:len(s.cards)-1]
+	return c
+}
+
+// score totals cards, counting an ace as 11 when that doesn't bust. Soft
+// hands have an ace counted so.
+func score(cards []Card) (total int, soft bool) {
+	aces := false
+	for _, c := range cards {
+		total += c.value()
+		aces = aces || c.Rank == 1
+	}
+	if aces && total+10 <= BLACKJACK {
+		return total + 10, true
+	}
+	return total, false
+}
+
+// natural reports a blackjack: 21 on the first two cards.
+func natural(cards []Card) bool {
+	total, _ := score(cards)
+	return len(cards) == 2 && total == BLACKJACK
+}
+
+// dealerHits tells whether the dealer takes another card: below 17, and on
+// soft 17 too when the table says so.
+func dealerHits(cards []Card, hitSoft17 bool) bool {
+	total, soft := score(cards)
+	return total < 17 || total == 17 && soft && hitSoft17
+}
@@ -0,0 +1,43 @@
+// Package block keeps players apart who don't want to meet: matchmaking
+// never pairs two players when either one blocked the other, by key
+// fingerprint or by display name.
+package block
+
+import (
+	"strings"
+
+	"github.com/debemdeboas/games.debem.dev/trophy"
+)
+
+// Player is someone looking for an opponent, as matchmaking compares them.
+type Player struct {
+	Name        string
+	Fingerprint string // empty for players without a key
+	// Blocked lists the fingerprints and display names the player
+	// blocked, see profile.Profile.Blocked.
+	Blocked []string
+	// Title is the trophy title the player wears, shown next to Name on
+	// match screens. Blocks never go by it.
+	Title string
+}
+
+// Label is the player's name as match screens show it, with their title.
+func (p Player) Label() string {
+	return trophy.Label(p.Name, p.Title)
+}
+
+// Blocks reports whether p blocked o. Names match regardless of case,
+// since players can't tell them apart on screen, and fingerprints exactly.
+func (p Player) Blocks(o Player) bool {
+	for _, b := range p.Blocked {
+		if o.Fingerprint != "" && b == o.Fingerprint || strings.EqualFold(b, o.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apart reports whether a and b may not be matched together.
+func Apart(a, b Player) bool {
+	return a.Blocks(b) || b.Blocks(a)
+}
@@ -0,0 +1,417 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MODE      = "classic"
+	TOPSCORES = 5 // on the game over screen
+)
+
+// rowColors tint single-hit bricks by row, top first, repeating.
+var rowColors = []lipgloss.Color{"196", "208", "226", "46", "45", "21", "129"}
+
+// hitColors are the colors of bricks taking more hits, by hits left.
+var hitColors = map[int]lipgloss.Color{2: "250", 3: "255", UNBREAKABLE: "240"}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	BrickStyle  lipgloss.Style // colored per row or hits, see rowColors
+	PaddleStyle lipgloss.Style
+	BallStyle   lipgloss.Style
+	PowerStyle  lipgloss.Style
+	BoardStyle  lipgloss.Style
+	ScoreStyle  lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, keeps the player's runs, which is where their best
+	// score is loaded from.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	best        int // before this run
+	top         []leaderboard.Entry
+	submitted   bool
+
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell *bell.Bell
+
+	court  *Court
+	paused bool
+	slow   bool // see games.Env.Slow
+	run    int  // generation of the ball's flight, so ticks of a stopped one are dropped
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type tickMsg int
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		BrickStyle:  r.NewStyle(),
+		PaddleStyle: r.NewStyle().Foreground(lipgloss.Color("15")),
+		BallStyle:   r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		PowerStyle:  r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("201")).Bold(true),
+		BoardStyle:  r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		ScoreStyle:  r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext binds the ball's ticks to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetScores keeps runs in store and loads the player's best from it.
+func (m *Model) SetScores(store leaderboard.Store, player, fingerprint string) {
+	m.Scores = store
+	m.Player = player
+	m.Fingerprint = fingerprint
+	if store == nil {
+		return
+	}
+	if e, ok := store.Best(m.bestFilter(), fingerprint); ok {
+		m.best = e.Score
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Restart starts over from the first level, ranking the abandoned run if
+// it scored.
+func (m *Model) Restart() {
+	if m.court != nil {
+		m.submitScore()
+		m.best = max(m.best, m.court.score)
+	}
+	m.court = NewCourt(m.rng)
+	m.paused, m.submitted = false, false
+	m.top = nil
+	m.run++
+}
+
+// SetSlow turns slow mode on or off, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// tickDuration is how long a tick lasts, longer in slow mode.
+func (m Model) tickDuration() time.Duration {
+	if m.slow {
+		return TICK * games.SLOWDOWN
+	}
+	return TICK
+}
+
+func (m Model) tick() tea.Cmd {
+	run := m.run
+	return ui.Every(m.ctx, m.tickDuration(), func(time.Time) tea.Msg {
+		return tickMsg(run)
+	})
+}
+
+// flying reports whether the ball needs ticks.
+func (m Model) flying() bool {
+	return !m.court.held && !m.court.over && !m.paused
+}
+
+// resume starts ticking again, as a new flight.
+func (m *Model) resume() tea.Cmd {
+	m.run++
+	return m.tick()
+}
+
+// Pause stops the ball mid-flight, see games.Pauser.
+func (m *Model) Pause() bool {
+	if !m.flying() {
+		return false
+	}
+	m.paused = true
+	return true
+}
+
+func (m *Model) Resume() tea.Cmd {
+	m.paused = false
+	if m.flying() {
+		return m.resume()
+	}
+	return nil
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      MODE,
+		Modifiers: m.modifiers(),
+		Board:     fmt.Sprintf("%dx%d", WIDTH, HEIGHT),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+func (m Model) modifiers() string {
+	if m.slow {
+		return leaderboard.Modifiers(leaderboard.ASSISTED)
+	}
+	return leaderboard.Modifiers()
+}
+
+// bestFilter spans seasons: a best score is the player's highest ever.
+func (m Model) bestFilter() leaderboard.Filter {
+	k := m.scoreKey()
+	return leaderboard.Filter{Game: k.Game, Mode: k.Mode, Board: k.Board}
+}
+
+// submitScore records the run once, when it's over or abandoned.
+func (m *Model) submitScore() {
+	if m.Scores == nil || m.submitted || m.court.score == 0 {
+		return
+	}
+	m.submitted = true
+	k := m.scoreKey()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Score:       m.court.score,
+		Points:      m.court.score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	c := m.court
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.submitScore()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case c.over:
+			if key.Matches(msg, m.Keys.Launch) {
+				m.Restart()
+			}
+		case key.Matches(msg, m.Keys.Pause):
+			m.paused = !m.paused
+			if m.flying() {
+				return m, m.resume()
+			}
+		case m.paused:
+			// The paddle stays put until play goes on.
+		case key.Matches(msg, m.Keys.Left):
+			c.Move(-PADDLESTEP)
+		case key.Matches(msg, m.Keys.Right):
+			c.Move(PADDLESTEP)
+		case key.Matches(msg, m.Keys.Launch):
+			if c.held {
+				c.Launch()
+				return m, m.resume()
+			}
+		}
+	case tickMsg:
+		if int(msg) != m.run || !m.flying() {
+			return m, nil
+		}
+		lives := c.lives
+		c.step()
+		if c.lives < lives {
+			m.Bell.Ring(bell.DEATH)
+		}
+		if c.over {
+			m.submitScore()
+		}
+		if m.flying() {
+			return m, m.tick()
+		}
+	}
+	return m, nil
+}
+
+func (m Model) brickStyle(y int, b brick) lipgloss.Style {
+	color, ok := hitColors[b.hits]
+	if !ok {
+		color = rowColors[y%len(rowColors)]
+	}
+	return m.BrickStyle.Foreground(color)
+}
+
+func (m Model) courtView() string {
+	c := m.court
+	cells := grid.New[string](WIDTH, HEIGHT)
+	cells.Fill(" ")
+	for y, row := range c.bricks {
+		for x, b := range row {
+			if b.hits == 0 {
+				continue
+			}
+			shape := "▐██▌"
+			if b.power != 0 {
+				shape = "▐█" + string(b.power) + "▌"
+			}
+			style := m.brickStyle(y, b)
+			for i, r := range []rune(shape) {
+				cells.Set(grid.Point{X: x*BRICKWIDTH + i, Y: TOP + y}, style.Render(string(r)))
+			}
+		}
+	}
+	for _, d := range c.drops {
+		if p := d.pos.Cell(); cells.InBounds(p) {
+			cells.Set(p, m.PowerStyle.Render(string(d.power)))
+		}
+	}
+	left := c.paddle.Round()
+	for x := left; x < left+c.width; x++ {
+		cells.Set(grid.Point{X: x, Y: PADDLEROW}, m.PaddleStyle.Render("▀"))
+	}
+	for _, b := range c.balls {
+		if p := (grid.Point{X: b.Pos.X.Round(), Y: b.Pos.Y.Round()}); cells.InBounds(p) {
+			cells.Set(p, m.BallStyle.Render("●"))
+		}
+	}
+
+	var s strings.Builder
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			s.WriteString(cells.At(grid.Point{X: x, Y: y}))
+		}
+		if y < HEIGHT-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) header() string {
+	c := m.court
+	parts := []string{
+		fmt.Sprintf("Level %d/%d: %s", c.level+1, len(levels), levels[c.level].Name),
+		m.ScoreStyle.Render(fmt.Sprintf("Score %d", c.score)),
+		fmt.Sprintf("Best %d", max(m.best, c.score)),
+		"Lives " + strings.Repeat("♥", c.lives),
+	}
+	if c.wide > 0 {
+		parts = append(parts, fmt.Sprintf("Wide %ds", int((time.Duration(c.wide)*m.tickDuration()).Seconds())))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (m Model) status() string {
+	c := m.court
+	switch {
+	case m.paused:
+		return fmt.Sprintf("Paused, '%s' to go on", m.Keys.Pause.Help().Key)
+	case c.held:
+		return fmt.Sprintf("Press %s to launch", m.Keys.Launch.Help().Key)
+	case len(c.balls) > 1:
+		return fmt.Sprintf("%d balls in play", len(c.balls))
+	}
+	return fmt.Sprintf("Catch %c for more balls and %c for a wider paddle", MULTIBALL, WIDE)
+}
+
+func (m Model) overView() string {
+	c := m.court
+	title := "Game over"
+	if c.won {
+		title = "Every level cleared!"
+	}
+	lines := []string{title, fmt.Sprintf("Score %d", c.score)}
+	if c.score > m.best && m.best > 0 {
+		lines = append(lines, "New best!")
+	}
+	if len(m.top) > 0 {
+		var s strings.Builder
+		s.WriteString("Top scores\n")
+		for i, e := range m.top {
+			fmt.Fprintf(&s, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(s.String(), "\n"))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to play again", m.Keys.Launch.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	if m.court.over {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.overView(),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			m.courtView(),
+			m.QuitStyle.Render(m.status()),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
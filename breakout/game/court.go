@@ -0,0 +1,293 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/physics"
+)
+
+const (
+	COLS       = 14 // bricks across
+	BRICKWIDTH = 4  // columns of a brick
+	MAXROWS    = 10 // of bricks
+	TOP        = 2  // empty rows above the bricks
+	WIDTH      = COLS * BRICKWIDTH
+	HEIGHT     = 24
+	PADDLEROW  = HEIGHT - 2
+
+	PADDLE     = 8  // columns of the paddle
+	WIDEPADDLE = 12 // while the wide power-up lasts
+	PADDLESTEP = 3  // columns per keypress
+	LIVES      = 3
+	POINTS     = 10 // per hit, times the level number
+
+	TICK      = 20 * time.Millisecond
+	WIDETICKS = 750 // 15 seconds
+	MAXBALLS  = 9
+	POWERODDS = 8 // one broken brick in POWERODDS drops a power-up
+)
+
+// Power-ups, as level files and falling capsules show them.
+const (
+	MULTIBALL = 'M' // splits every ball in three
+	WIDE      = 'W' // widens the paddle for WIDETICKS
+)
+
+// Speeds, in cells per tick. The ball stays under a cell per tick so it
+// can't skip through a brick.
+var (
+	SPEED    = physics.Frac(2, 5)
+	SPEEDUP  = physics.Frac(1, 25) // per level
+	MAXSPEED = physics.Frac(9, 10)
+	FALL     = physics.Frac(1, 6) // of power-ups
+)
+
+// drop is a power-up falling towards the paddle.
+type drop struct {
+	power byte
+	pos   physics.Vec
+}
+
+// Court is a game of Breakout: the bricks of the current level, the balls
+// in play and the paddle at the bottom. Time only moves in step, one TICK
+// at a time.
+type Court struct {
+	level  int // into levels
+	bricks [][]brick
+	balls  []physics.Body
+	drops  []drop
+	held   bool // the ball rests on the paddle until launched
+
+	paddle physics.Fixed // left edge
+	width  int           // of the paddle
+	wide   int           // ticks left of the wide power-up
+
+	speed physics.Fixed
+	lives int
+	score int
+	over  bool
+	won   bool // cleared every level
+
+	rng *rand.Rand
+}
+
+func NewCourt(rng *rand.Rand) *Court {
+	c := &Court{lives: LIVES, rng: rng}
+	c.start(0)
+	return c
+}
+
+// start sets up level i, with the ball on the paddle.
+func (c *Court) start(i int) {
+	c.level = i
+	c.bricks = make([][]brick, len(levels[i].bricks))
+	for y, row := range levels[i].bricks {
+		c.bricks[y] = append([]brick(nil), row...)
+	}
+	c.speed = min(MAXSPEED, SPEED+SPEEDUP*physics.Fixed(i))
+	c.paddle = physics.FromInt(WIDTH-PADDLE) / 2
+	c.serve()
+}
+
+// serve puts a single ball back on a normal paddle.
+func (c *Court) serve() {
+	c.width, c.wide = PADDLE, 0
+	c.drops = nil
+	c.balls = []physics.Body{{Size: physics.V(physics.ONE, physics.ONE)}}
+	c.held = true
+	c.follow()
+}
+
+// follow keeps a held ball on top of the paddle's centre.
+func (c *Court) follow() {
+	c.balls[0].Pos = physics.V(c.paddle+physics.FromInt(c.width)/2-physics.HALF, physics.FromInt(PADDLEROW-1))
+}
+
+// Launch sends the held ball up, leaning a random way.
+func (c *Court) Launch() {
+	if !c.held || c.over {
+		return
+	}
+	c.held = false
+	vx := c.speed / 2
+	if c.rng.Intn(2) == 0 {
+		vx = -vx
+	}
+	c.balls[0].Vel = physics.V(vx, -c.speed)
+}
+
+// Move shifts the paddle by dx columns.
+func (c *Court) Move(dx int) {
+	if c.over {
+		return
+	}
+	c.paddle = physics.Clamp(c.paddle+physics.FromInt(dx), 0, physics.FromInt(WIDTH-c.width))
+	if c.held {
+		c.follow()
+	}
+}
+
+func (c *Court) paddleBox() physics.AABB {
+	return physics.Box(c.paddle, physics.FromInt(PADDLEROW), physics.FromInt(c.width), physics.ONE)
+}
+
+func brickBox(x, y int) physics.AABB {
+	return physics.Box(physics.FromInt(x*BRICKWIDTH), physics.FromInt(TOP+y), physics.FromInt(BRICKWIDTH), physics.ONE)
+}
+
+// walls bound the balls on the top and sides. They reach past the bottom
+// so balls can fall out.
+var walls = physics.AABB{
+	Max: physics.V(physics.FromInt(WIDTH), physics.FromInt(2*HEIGHT)),
+}
+
+// step moves everything one tick. It does nothing while the ball is held.
+func (c *Court) step() {
+	if c.held || c.over {
+		return
+	}
+	if c.wide > 0 {
+		c.wide--
+		if c.wide == 0 {
+			c.resize(PADDLE)
+		}
+	}
+
+	kept := c.balls[:0]
+	for _, b := range c.balls {
+		b.Step()
+		physics.Confine(&b, walls)
+		c.collide(&b)
+		if b.Pos.Y < physics.FromInt(HEIGHT) {
+			kept = append(kept, b)
+		}
+	}
+	c.balls = kept
+	if c.cleared() {
+		if c.level+1 == len(levels) {
+			c.over, c.won = true, true
+			return
+		}
+		c.start(c.level + 1)
+		return
+	}
+
+	fallen := c.drops[:0]
+	for _, d := range c.drops {
+		d.pos.Y += FALL
+		switch {
+		case c.paddleBox().Contains(d.pos):
+			c.apply(d.power)
+		case d.pos.Y < physics.FromInt(HEIGHT):
+			fallen = append(fallen, d)
+		}
+	}
+	c.drops = fallen
+
+	if len(c.balls) == 0 {
+		c.lives--
+		if c.lives == 0 {
+			c.over = true
+			return
+		}
+		c.serve()
+	}
+}
+
+// collide bounces b off the paddle or the first brick it overlaps.
+func (c *Court) collide(b *physics.Body) {
+	if b.Vel.Y > 0 && b.Bounds().Overlaps(c.paddleBox()) {
+		// The ball leaves at an angle set by where on the paddle it
+		// landed, so players can aim, but never straight up, where it
+		// could bounce between the paddle and a wall forever.
+		half := physics.FromInt(c.width) / 2
+		off := physics.Clamp(b.Center().X-(c.paddle+half), -half, half).Div(half)
+		vx := off.Mul(c.speed)
+		if least := c.speed / 4; vx.Abs() < least {
+			vx = least
+			if b.Vel.X < 0 {
+				vx = -least
+			}
+		}
+		b.Pos.Y = physics.FromInt(PADDLEROW) - b.Size.Y
+		b.Vel = physics.V(vx, -c.speed)
+		return
+	}
+
+	box := b.Bounds()
+	for y := box.Min.Y.Int() - TOP; y <= (box.Max.Y-1).Int()-TOP; y++ {
+		if y < 0 || y >= len(c.bricks) {
+			continue
+		}
+		for x := box.Min.X.Int() / BRICKWIDTH; x <= (box.Max.X-1).Int()/BRICKWIDTH && x < COLS; x++ {
+			if x < 0 || c.bricks[y][x].hits == 0 {
+				continue
+			}
+			if physics.Bounce(b, brickBox(x, y)) != physics.NONE {
+				c.hit(x, y)
+				return
+			}
+		}
+	}
+}
+
+// hit takes a hit off the brick at x, y, dropping a power-up when it
+// breaks.
+func (c *Court) hit(x, y int) {
+	br := &c.bricks[y][x]
+	if br.hits == UNBREAKABLE {
+		return
+	}
+	br.hits--
+	c.score += POINTS * (c.level + 1)
+	if br.hits > 0 {
+		return
+	}
+
+	power := br.power
+	if power == 0 && c.rng.Intn(POWERODDS) == 0 {
+		power = []byte{MULTIBALL, WIDE}[c.rng.Intn(2)]
+	}
+	if power != 0 {
+		centre := brickBox(x, y).Min.Add(physics.V(physics.FromInt(BRICKWIDTH/2), 0))
+		c.drops = append(c.drops, drop{power: power, pos: centre})
+	}
+}
+
+func (c *Court) cleared() bool {
+	for _, row := range c.bricks {
+		for _, b := range row {
+			if b.hits > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *Court) apply(power byte) {
+	switch power {
+	case MULTIBALL:
+		for _, b := range c.balls {
+			for _, dx := range []physics.Fixed{-c.speed / 2, c.speed / 2} {
+				if len(c.balls) >= MAXBALLS {
+					return
+				}
+				split := b
+				split.Vel.X = physics.Clamp(b.Vel.X+dx, -c.speed, c.speed)
+				c.balls = append(c.balls, split)
+			}
+		}
+	case WIDE:
+		c.resize(WIDEPADDLE)
+		c.wide = WIDETICKS
+	}
+}
+
+// resize changes the paddle's width around its centre.
+func (c *Court) resize(width int) {
+	centre := c.paddle + physics.FromInt(c.width)/2
+	c.width = width
+	c.paddle = physics.Clamp(centre-physics.FromInt(width)/2, 0, physics.FromInt(WIDTH-width))
+}
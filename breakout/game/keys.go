@@ -0,0 +1,61 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Launch  key.Binding
+	Pause   key.Binding
+	Restart key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Launch:   key.NewBinding(key.WithKeys(" ", "enter", ui.KEYPADENTER), key.WithHelp("space", "launch")),
+		Pause:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pause")),
+		Restart:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new game")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Left, k.Right, k.Launch, k.Pause, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Left, k.Right, k.Launch},
+		{k.Pause, k.Restart, k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"launch":  &k.Launch,
+		"pause":   &k.Pause,
+		"restart": &k.Restart,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
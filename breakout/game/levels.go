@@ -0,0 +1,158 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// Level files draw their bricks a row per line, a column per brick, up to
+// COLS columns and MAXROWS rows:
+//
+//	; Name of the level
+//	33333333333333
+//	2222W222M22222
+//	..11111111##..
+//
+// Digits are bricks taking that many hits, # are bricks that don't break,
+// and M and W are single-hit bricks that always drop multi-ball and wide
+// paddle power-ups. Dots and spaces are gaps. Lines starting with ; are
+// comments, the first naming the level.
+//
+//go:embed levels/*.txt
+var levelFiles embed.FS
+
+const (
+	UNBREAKABLE = -1 // hits of a brick that stays
+	MAXHITS     = 3
+)
+
+type brick struct {
+	hits  int  // left to break it, 0 once broken, UNBREAKABLE if never
+	power byte // dropped when it breaks, 0 for a random chance of one
+}
+
+type Level struct {
+	Name   string
+	bricks [][]brick // by row, then column
+}
+
+var levels = mustLoad(levelFiles)
+
+// mustLoad reads the embedded levels, in file name order.
+func mustLoad(fsys fs.FS) []Level {
+	paths, err := fs.Glob(fsys, "levels/*.txt")
+	if err != nil {
+		panic(fmt.Sprintf("breakout: levels: %v", err))
+	}
+	var ls []Level
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			panic(fmt.Sprintf("breakout: %s: %v", p, err))
+		}
+		l, err := parseLevel(data, strings.TrimSuffix(path.Base(p), ".txt"))
+		if err != nil {
+			panic(fmt.Sprintf("breakout: %s: %v", p, err))
+		}
+		ls = append(ls, l)
+	}
+	return ls
+}
+
+// parseLevel reads a level file, named name unless a comment names it.
+func parseLevel(data []byte, name string) (Level, error) {
+	l := Level{Name: name}
+	named := false
+	breakable := 0
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if comment, ok := strings.CutPrefix(line, ";"); ok {
+			if !named {
+				l.Name, named = strings.TrimSpace(comment), true
+			}
+			continue
+		}
+		if len(l.bricks) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line) > COLS {
+			return l, fmt.Errorf("row %d: %d bricks, at most %d fit", len(l.bricks)+1, len(line), COLS)
+		}
+		row := make([]brick, COLS)
+		for x, c := range line {
+			switch {
+			case c == '.' || c == ' ':
+			case c >= '1' && c <= '0'+MAXHITS:
+				row[x].hits = int(c - '0')
+			case c == '#':
+				row[x].hits = UNBREAKABLE
+			case c == MULTIBALL || c == WIDE:
+				row[x] = brick{hits: 1, power: byte(c)}
+			default:
+				return l, fmt.Errorf("row %d: unknown brick %q", len(l.bricks)+1, c)
+			}
+			if row[x].hits > 0 {
+				breakable++
+			}
+		}
+		l.bricks = append(l.bricks, row)
+	}
+	if err := sc.Err(); err != nil {
+		return l, err
+	}
+	// Blank lines at the end are no rows.
+	for len(l.bricks) > 0 && empty(l.bricks[len(l.bricks)-1]) {
+		l.bricks = l.bricks[:len(l.bricks)-1]
+	}
+	switch {
+	case len(l.bricks) > MAXROWS:
+		return l, fmt.Errorf("%d rows, at most %d fit", len(l.bricks), MAXROWS)
+	case breakable == 0:
+		return l, fmt.Errorf("no bricks to break")
+	}
+	return l, nil
+}
+
+func empty(row []brick) bool {
+	for _, b := range row {
+		if b.hits != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadDir adds every level in dir, one .txt file each, after the built-in
+// ones, in file name order. Invalid levels are skipped. Call it before
+// serving.
+func LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			log.Warn("Skipping breakout level", "path", p, "err", err)
+			continue
+		}
+		l, err := parseLevel(data, strings.TrimSuffix(filepath.Base(p), ".txt"))
+		if err != nil {
+			log.Warn("Skipping breakout level", "path", p, "err", err)
+			continue
+		}
+		levels = append(levels, l)
+		log.Info("Loaded breakout level", "path", p, "name", l.Name)
+	}
+	return nil
+}
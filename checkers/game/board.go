@@ -0,0 +1,266 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	SIZE       = 8
+	DRAWPLIES  = 80 // moves in a row without a capture or a crowning that draw, forty each
+	SEARCHPLY  = 6  // how far ahead the computer looks
+	WINSCORE   = 100_000
+	MANVALUE   = 100
+	KINGVALUE  = 175
+	ADVANCEBIT = 3 // what a man gains per row it advances
+)
+
+// Colors. Dark moves first, from the bottom rows.
+const (
+	NONE = iota
+	DARK
+	LIGHT
+)
+
+type Piece struct {
+	Color int
+	King  bool
+}
+
+// Board is a position, row 0 at the top. Pieces only stand on the dark
+// squares, where x+y is odd.
+type Board struct {
+	Cells [SIZE][SIZE]Piece
+	Turn  int
+	Quiet int // moves since the last capture or crowning
+}
+
+// Move is a piece's path: where it starts, then where it lands after each
+// step or jump.
+type Move struct {
+	Path []grid.Point
+}
+
+// Capture reports whether m jumps, and so takes pieces.
+func (m Move) Capture() bool {
+	return len(m.Path) > 1 && (m.Path[1].X-m.Path[0].X == 2 || m.Path[0].X-m.Path[1].X == 2)
+}
+
+func other(color int) int {
+	return DARK + LIGHT - color
+}
+
+func inBounds(p grid.Point) bool {
+	return p.X >= 0 && p.X < SIZE && p.Y >= 0 && p.Y < SIZE
+}
+
+// NewBoard sets up three rows of men a side.
+func NewBoard() Board {
+	b := Board{Turn: DARK}
+	for y := 0; y < SIZE; y++ {
+		for x := (y + 1) % 2; x < SIZE; x += 2 {
+			switch {
+			case y < 3:
+				b.Cells[y][x] = Piece{Color: LIGHT}
+			case y >= SIZE-3:
+				b.Cells[y][x] = Piece{Color: DARK}
+			}
+		}
+	}
+	return b
+}
+
+func (b *Board) At(p grid.Point) Piece {
+	return b.Cells[p.Y][p.X]
+}
+
+// forward is the way color's men move, up the board for dark.
+func forward(color int) int {
+	if color == DARK {
+		return -1
+	}
+	return 1
+}
+
+// crownRow is the far row, where color's men become kings.
+func crownRow(color int) int {
+	if color == DARK {
+		return 0
+	}
+	return SIZE - 1
+}
+
+// directions are the diagonals a piece moves along: forward ones for men,
+// all four for kings.
+func directions(p Piece) []grid.Point {
+	f := forward(p.Color)
+	dirs := []grid.Point{{X: -1, Y: f}, {X: 1, Y: f}}
+	if p.King {
+		dirs = append(dirs, grid.Point{X: -1, Y: -f}, grid.Point{X: 1, Y: -f})
+	}
+	return dirs
+}
+
+// LegalMoves are the side to move's moves. Captures are forced, and a
+// capture goes on for as long as the piece can keep jumping, unless a man
+// is crowned, which ends the move.
+func (b *Board) LegalMoves() []Move {
+	var jumps, steps []Move
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			from := grid.Point{X: x, Y: y}
+			p := b.At(from)
+			if p.Color != b.Turn {
+				continue
+			}
+			jumps = b.jumps(jumps, p, []grid.Point{from}, nil)
+			if len(jumps) > 0 {
+				continue
+			}
+			for _, d := range directions(p) {
+				if to := from.Add(d); inBounds(to) && b.At(to).Color == NONE {
+					steps = append(steps, Move{Path: []grid.Point{from, to}})
+				}
+			}
+		}
+	}
+	if len(jumps) > 0 {
+		return jumps
+	}
+	return steps
+}
+
+// jumps adds every capture p can finish from the end of path, taken being
+// the pieces it jumped so far, which stay on the board until the move ends.
+func (b *Board) jumps(moves []Move, p Piece, path []grid.Point, taken []grid.Point) []Move {
+	at := path[len(path)-1]
+	extended := false
+	for _, d := range directions(p) {
+		over, to := at.Add(d), at.Add(d).Add(d)
+		if !inBounds(to) || b.At(over).Color != other(p.Color) || slices.Contains(taken, over) {
+			continue
+		}
+		// The piece's own square is free once it sets off.
+		if b.At(to).Color != NONE && to != path[0] {
+			continue
+		}
+		extended = true
+		next := append(slices.Clip(path), to)
+		if !p.King && to.Y == crownRow(p.Color) {
+			moves = append(moves, Move{Path: next})
+			continue
+		}
+		moves = b.jumps(moves, p, next, append(slices.Clip(taken), over))
+	}
+	if !extended && len(path) > 1 {
+		moves = append(moves, Move{Path: path})
+	}
+	return moves
+}
+
+// Play makes m, which must be legal: jumped pieces come off and men
+// reaching the far row are crowned.
+func (b Board) Play(m Move) Board {
+	from, to := m.Path[0], m.Path[len(m.Path)-1]
+	p := b.At(from)
+	b.Cells[from.Y][from.X] = Piece{}
+	quiet := true
+	for i := 1; i < len(m.Path); i++ {
+		a, c := m.Path[i-1], m.Path[i]
+		if c.X-a.X == 2 || a.X-c.X == 2 {
+			b.Cells[(a.Y+c.Y)/2][(a.X+c.X)/2] = Piece{}
+			quiet = false
+		}
+	}
+	if !p.King && to.Y == crownRow(p.Color) {
+		p.King = true
+		quiet = false
+	}
+	b.Cells[to.Y][to.X] = p
+	b.Turn = other(b.Turn)
+	b.Quiet++
+	if !quiet {
+		b.Quiet = 0
+	}
+	return b
+}
+
+// Legal finds m among the legal moves, by its path.
+func (b *Board) Legal(m Move) bool {
+	return slices.ContainsFunc(b.LegalMoves(), func(l Move) bool { return slices.Equal(l.Path, m.Path) })
+}
+
+// eval rates the board for the side to move by its pieces, men counting
+// more the further they got.
+func (b *Board) eval() int {
+	score := 0
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			p := b.Cells[y][x]
+			if p.Color == NONE {
+				continue
+			}
+			v := KINGVALUE
+			if !p.King {
+				rows := y
+				if p.Color == DARK {
+					rows = SIZE - 1 - y
+				}
+				v = MANVALUE + ADVANCEBIT*rows
+			}
+			if p.Color != b.Turn {
+				v = -v
+			}
+			score += v
+		}
+	}
+	return score
+}
+
+// search rates the board for the side to move, looking depth moves ahead
+// by negamax with alpha-beta pruning. Sooner wins score higher.
+func (b *Board) search(depth, alpha, beta int) int {
+	moves := b.LegalMoves()
+	if len(moves) == 0 {
+		return -WINSCORE - depth
+	}
+	if b.Quiet >= DRAWPLIES {
+		return 0
+	}
+	if depth == 0 {
+		return b.eval()
+	}
+	for _, m := range moves {
+		next := b.Play(m)
+		if s := -next.search(depth-1, -beta, -alpha); s > alpha {
+			alpha = s
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return alpha
+}
+
+// Best picks a move for the side to move, at random between moves that
+// rate the same.
+func (b *Board) Best(rng *rand.Rand) Move {
+	var best []Move
+	top := 0
+	for _, m := range b.LegalMoves() {
+		next := b.Play(m)
+		s := -next.search(SEARCHPLY-1, -WINSCORE*2, WINSCORE*2)
+		switch {
+		case len(best) == 0 || s > top:
+			top, best = s, []Move{m}
+		case s == top:
+			best = append(best, m)
+		}
+	}
+	if len(best) == 0 {
+		return Move{}
+	}
+	return best[rng.Intn(len(best))]
+}
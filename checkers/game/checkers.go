@@ -0,0 +1,462 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/review"
+	"github.com/debemdeboas/games.debem.dev/turns"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 100 * time.Millisecond
+
+var colorNames = map[int]string{DARK: "Black", LIGHT: "White"}
+
+var reasons = map[string]string{
+	BLOCKED:        "No moves left",
+	QUIET:          "Forty moves without a capture",
+	turns.RESIGNED: "Resigned",
+	turns.LEFT:     "Opponent left",
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	LightStyle    lipgloss.Style
+	DarkStyle     lipgloss.Style
+	CursorStyle   lipgloss.Style
+	SelectedStyle lipgloss.Style
+	TargetStyle   lipgloss.Style
+	LastStyle     lipgloss.Style
+	QuitStyle     lipgloss.Style
+	BoxStyle      lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room  string
+	match *Match
+	side  int
+	snap  Snapshot
+
+	cursor grid.Point
+	// path is the move the player is putting together, square by square:
+	// the piece, then where it lands so far.
+	path   []grid.Point
+	review *review.Model
+
+	showHelp bool
+
+	r   *lipgloss.Renderer
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:         width,
+		Height:        height,
+		LightStyle:    r.NewStyle().Background(lipgloss.Color("180")),
+		DarkStyle:     r.NewStyle().Background(lipgloss.Color("137")),
+		CursorStyle:   r.NewStyle().Background(lipgloss.Color("75")),
+		SelectedStyle: r.NewStyle().Background(lipgloss.Color("114")),
+		TargetStyle:   r.NewStyle().Background(lipgloss.Color("108")),
+		LastStyle:     r.NewStyle().Background(lipgloss.Color("186")),
+		QuitStyle:     r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		r:      r,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = lobby.Join(m.Room, m.Player, now)
+	m.begin(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.Opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.Leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+// PlayBot gives up waiting and plays the computer instead.
+func (m *Model) PlayBot() {
+	m.match.Leave(m.side)
+	now := time.Now()
+	m.match, m.side = lobby.VersusBot(m.Player, now)
+	m.begin(now)
+}
+
+func (m *Model) begin(now time.Time) {
+	m.path, m.review = nil, nil
+	m.refresh(now)
+	m.cursor = m.home()
+}
+
+// home is where the cursor starts: on the player's front row, mid-board.
+func (m Model) home() grid.Point {
+	return m.flip(grid.Point{X: 2, Y: SIZE - 3})
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.match.Poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	starting := m.snap.Phase == turns.WAITING && snap.Phase == turns.PLAYING
+	m.snap = snap
+	if starting {
+		m.cursor = m.home()
+	}
+}
+
+func (m Model) color() int {
+	if m.snap.Phase == turns.WAITING {
+		return DARK
+	}
+	return seatColors[m.snap.Players[m.side].Seat]
+}
+
+func (m Model) myTurn() bool {
+	return m.snap.Phase == turns.PLAYING && m.snap.State.Turn == m.color()
+}
+
+// flip maps squares between the board and the player's point of view, so
+// their pieces are always at the bottom.
+func (m Model) flip(p grid.Point) grid.Point {
+	if m.color() == LIGHT {
+		return grid.Point{X: SIZE - 1 - p.X, Y: SIZE - 1 - p.Y}
+	}
+	return p
+}
+
+func (m *Model) moveCursor(d grid.Point) {
+	view := m.flip(m.cursor).Add(d)
+	view.X = max(0, min(SIZE-1, view.X))
+	view.Y = max(0, min(SIZE-1, view.Y))
+	m.cursor = m.flip(view)
+}
+
+// candidates are the legal moves that go along the path so far.
+func (m Model) candidates() []Move {
+	if len(m.path) == 0 {
+		return nil
+	}
+	var moves []Move
+	for _, mv := range m.snap.State.LegalMoves() {
+		if len(mv.Path) > len(m.path) && slices.Equal(mv.Path[:len(m.path)], m.path) {
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
+
+// selectSquare picks a piece, or the next square on its way. A move is
+// played once the path reaches its last square; captures must always go
+// on jumping, so no move stops where another goes on.
+func (m *Model) selectSquare() {
+	if !m.myTurn() {
+		return
+	}
+	if len(m.path) > 0 {
+		var along []Move
+		for _, mv := range m.candidates() {
+			if mv.Path[len(m.path)] == m.cursor {
+				along = append(along, mv)
+			}
+		}
+		if len(along) > 0 {
+			next := append(slices.Clip(m.path), m.cursor)
+			if len(along) == 1 && len(along[0].Path) == len(next) {
+				m.match.Play(m.side, along[0])
+				m.path = nil
+				m.refresh(time.Now())
+				return
+			}
+			m.path = next
+			return
+		}
+		// Halfway through a capture, the piece must finish it.
+		if len(m.path) > 1 {
+			return
+		}
+	}
+	movable := slices.ContainsFunc(m.snap.State.LegalMoves(), func(mv Move) bool { return mv.Path[0] == m.cursor })
+	if movable && (len(m.path) == 0 || m.path[0] != m.cursor) {
+		m.path = []grid.Point{m.cursor}
+		return
+	}
+	m.path = nil
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		if m.review != nil {
+			m.review.Update(msg)
+			if m.review.Closed() {
+				m.review = nil
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.Leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Resign):
+			m.match.Resign(m.side)
+			m.refresh(time.Now())
+		case key.Matches(msg, m.Keys.Review):
+			if m.snap.Phase == turns.OVER {
+				render := func(b Board, last *Move) string { return m.boardView(b, last, false) }
+				m.review = review.New(newRecord(m.snap, render), m.r)
+			}
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == turns.OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Bot):
+			if m.snap.Phase == turns.WAITING || m.snap.Phase == turns.OVER {
+				m.PlayBot()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(grid.Right)
+		case key.Matches(msg, m.Keys.Select):
+			m.selectSquare()
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		// The opponent's move may have come in while the player was
+		// picking, so drop paths that lead nowhere now.
+		if len(m.path) > 0 && len(m.candidates()) == 0 {
+			m.path = nil
+		}
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+// square draws p of b, with targets the squares the path can go on to.
+func (m Model) square(b Board, p grid.Point, last *Move, targets []grid.Point, live bool) string {
+	style := m.LightStyle
+	if (p.X+p.Y)%2 == 1 {
+		style = m.DarkStyle
+	}
+	target := slices.Contains(targets, p)
+	switch {
+	case live && p == m.cursor:
+		style = m.CursorStyle
+	case live && slices.Contains(m.path, p):
+		style = m.SelectedStyle
+	case target:
+		style = m.TargetStyle
+	case last != nil && slices.Contains(last.Path, p):
+		style = m.LastStyle
+	}
+
+	piece := b.At(p)
+	switch {
+	case piece.Color == NONE && target:
+		return style.Foreground(lipgloss.Color("0")).Render(" · ")
+	case piece.Color == NONE:
+		return style.Render("   ")
+	}
+	fg, glyph := lipgloss.Color("0"), "●"
+	if piece.Color == LIGHT {
+		fg = lipgloss.Color("15")
+	}
+	if piece.King {
+		glyph = "◉"
+	}
+	return style.Foreground(fg).Render(" " + glyph + " ")
+}
+
+// boardView draws b from the player's side, with the cursor and where the
+// path can go on to when live, for the match's own board.
+func (m Model) boardView(b Board, last *Move, live bool) string {
+	var targets []grid.Point
+	if live {
+		for _, mv := range m.candidates() {
+			targets = append(targets, mv.Path[len(m.path)])
+		}
+	}
+	var s strings.Builder
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			s.WriteString(m.square(b, m.flip(grid.Point{X: x, Y: y}), last, targets, live))
+		}
+		if y < SIZE-1 {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+func (m Model) lastMove() *Move {
+	if n := len(m.snap.Moves); n > 0 {
+		return &m.snap.Moves[n-1]
+	}
+	return nil
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Players {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		sides = append(sides, fmt.Sprintf("%s: %s", colorNames[seatColors[p.Seat]], name))
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case turns.WAITING:
+		return fmt.Sprintf("Waiting for an opponent...\n\nPress '%s' to play the computer", m.Keys.Bot.Help().Key)
+	case turns.PLAYING:
+		if !m.myTurn() {
+			return fmt.Sprintf("%s to move...", m.snap.Players[1-m.side].Name)
+		}
+		moves := m.snap.State.LegalMoves()
+		switch {
+		case len(m.path) > 1:
+			return "Keep jumping"
+		case len(moves) > 0 && moves[0].Capture():
+			return "Your move • you must capture"
+		}
+		return "Your move"
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	var outcome string
+	switch m.snap.Winner {
+	case -1:
+		outcome = "Draw"
+	case m.side:
+		outcome = "You win!"
+	default:
+		outcome = m.snap.Players[m.snap.Winner].Name + " wins"
+	}
+	reason := reasons[m.snap.Reason]
+	if m.snap.Reason == turns.RESIGNED && m.snap.Winner == m.side {
+		reason = m.snap.Players[1-m.side].Name + " resigned"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s: %s %s\n\nPress '%s' to review and copy the PDN, '%s' for a new match or '%s' to play the computer",
+		reason, outcome, result(m.snap), m.Keys.Review.Help().Key, m.Keys.Rematch.Help().Key, m.Keys.Bot.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.review != nil {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.review.View(),
+		)
+	}
+
+	if m.snap.Phase == turns.WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == turns.OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardView(m.snap.State, m.lastMove(), true),
+			"",
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+// Why matches end on the board
+const (
+	BLOCKED = "no moves left"
+	QUIET   = "forty quiet moves"
+)
+
+type (
+	Match    = turns.Match[Board, Move]
+	Snapshot = turns.Snapshot[Board, Move]
+)
+
+// Dark takes the first seat.
+var seatColors = [2]int{DARK, LIGHT}
+
+func toMove(b Board) int {
+	return b.Turn - DARK
+}
+
+var lobby = turns.NewLobby(turns.Rules[Board, Move]{
+	Start:  NewBoard,
+	ToMove: toMove,
+	Play: func(b Board, mv Move) (Board, bool) {
+		if !b.Legal(mv) {
+			return b, false
+		}
+		return b.Play(mv), true
+	},
+	// A side that can't move loses, whether it has pieces left or not.
+	Result: func(b Board) (int, string, bool) {
+		switch {
+		case len(b.LegalMoves()) == 0:
+			return 1 - toMove(b), BLOCKED, true
+		case b.Quiet >= DRAWPLIES:
+			return -1, QUIET, true
+		}
+		return 0, "", false
+	},
+	Bot: func(b Board, rng *rand.Rand) Move {
+		return b.Best(rng)
+	},
+})
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/review"
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+// REVIEWPLY is how far ahead finished matches are searched to annotate
+// them.
+const REVIEWPLY = 4
+
+// evaluate rates b for dark, the first player, by the engine's search: a
+// man is worth MANVALUE, as a pawn is worth 100 in chess reviews.
+func evaluate(b Board) int {
+	eval := b.search(REVIEWPLY, -WINSCORE*2, WINSCORE*2)
+	if b.Turn == LIGHT {
+		eval = -eval
+	}
+	return max(-WINSCORE, min(WINSCORE, eval))
+}
+
+// number is p's square as checkers notation numbers them: 1 to 32 over
+// the dark squares, starting from the corner on dark's right.
+func number(p grid.Point) int {
+	return (SIZE-1-p.Y)*SIZE/2 + (SIZE-1-p.X)/2 + 1
+}
+
+// notation writes m as checkers notation does, e.g. 11-15 for a step and
+// 22x15x8 for a double jump.
+func notation(m Move) string {
+	sep := "-"
+	if m.Capture() {
+		sep = "x"
+	}
+	squares := make([]string, len(m.Path))
+	for i, p := range m.Path {
+		squares[i] = fmt.Sprint(number(p))
+	}
+	return strings.Join(squares, sep)
+}
+
+// record is a finished match as the review screen steps through it.
+type record struct {
+	snap   Snapshot
+	boards []Board // after each move, the starting position first
+	moves  []review.Move
+	render func(b Board, last *Move) string
+}
+
+func newRecord(snap Snapshot, render func(Board, *Move) string) *record {
+	r := &record{snap: snap, render: render}
+	b := NewBoard()
+	r.boards = append(r.boards, b)
+	for _, mv := range snap.Moves {
+		player := toMove(b)
+		b = b.Play(mv)
+		r.boards = append(r.boards, b)
+		r.moves = append(r.moves, review.Move{Player: player, Notation: notation(mv), Eval: evaluate(b)})
+	}
+	return r
+}
+
+func (r *record) Moves() []review.Move {
+	return r.moves
+}
+
+func (r *record) Position(n int) string {
+	var last *Move
+	if n > 0 {
+		last = &r.snap.Moves[n-1]
+	}
+	return r.render(r.boards[n], last)
+}
+
+// Export writes the match as PDN, which is laid out like PGN.
+func (r *record) Export() string {
+	tags := []chess.Tag{
+		{Name: "Event", Value: "Casual game"},
+		{Name: "Site", Value: "games.debem.dev"},
+		{Name: "Date", Value: r.snap.Start.UTC().Format("2006.01.02")},
+	}
+	for _, color := range []int{DARK, LIGHT} {
+		for _, p := range r.snap.Players {
+			if seatColors[p.Seat] == color {
+				tags = append(tags, chess.Tag{Name: colorNames[color], Value: p.Name})
+			}
+		}
+	}
+	var moves []string
+	for _, m := range r.moves {
+		moves = append(moves, m.Notation)
+	}
+	return chess.PGN(tags, moves, result(r.snap))
+}
+
+// result is the match's result as PDN writes it, two points a game.
+func result(snap Snapshot) string {
+	switch {
+	case snap.Phase != turns.OVER:
+		return "*"
+	case snap.Winner < 0:
+		return "1-1"
+	case seatColors[snap.Players[snap.Winner].Seat] == DARK:
+		return "2-0"
+	}
+	return "0-2"
+}
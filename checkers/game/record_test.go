@@ -0,0 +1,57 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+func TestNumbers(t *testing.T) {
+	seen := make(map[int]bool)
+	for y := 0; y < SIZE; y++ {
+		for x := (y + 1) % 2; x < SIZE; x += 2 {
+			n := number(grid.Point{X: x, Y: y})
+			if n < 1 || n > 32 || seen[n] {
+				t.Errorf("(%d, %d) is square %d, want a new one in 1-32", x, y, n)
+			}
+			seen[n] = true
+		}
+	}
+	// Dark's men start on 1-12.
+	start := NewBoard()
+	for _, mv := range start.LegalMoves() {
+		if n := number(mv.Path[0]); n < 9 || n > 12 {
+			t.Errorf("opening move from square %d, want dark's front row, 9-12", n)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	snap := Snapshot{Phase: turns.OVER, Winner: -1, Players: []turns.Player{{Name: "alice", Seat: 0}, {Name: "bob", Seat: 1}}}
+	b := NewBoard()
+	for range 12 {
+		mv := b.Best(rng)
+		snap.Moves = append(snap.Moves, mv)
+		b = b.Play(mv)
+	}
+
+	r := newRecord(snap, func(Board, *Move) string { return "" })
+	for i, mv := range r.Moves() {
+		if mv.Player != i%2 {
+			t.Errorf("move %d by player %d, want %d", i+1, mv.Player, i%2)
+		}
+		if snap.Moves[i].Capture() != strings.Contains(mv.Notation, "x") {
+			t.Errorf("move %d written %q", i+1, mv.Notation)
+		}
+	}
+	pdn := r.Export()
+	for _, want := range []string{`[Black "alice"]`, `[White "bob"]`, `[Result "1-1"]`, "1. " + r.Moves()[0].Notation} {
+		if !strings.Contains(pdn, want) {
+			t.Errorf("PDN lacks %q:\n%s", want, pdn)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "checkers"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Checkers",
+	Description: "English draughts with forced captures, against a player or the computer",
+	Category:    games.BOARD,
+	MinPlayers:  1,
+	MaxPlayers:  2,
+	Rooms:       true,
+	Session:     20 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		if env.Room != "" {
+			m.SetRoom(env.Room)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,258 @@
+// Package chess holds the board model shared by the chess games. It reads
+// positions and plays moves given in UCI notation, e.g. "e2e4" or "e7e8q",
+// and knows which moves are legal, how games end and how to write them down
+// in SAN and PGN.
+package chess
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	WHITE = 'w'
+	BLACK = 'b'
+)
+
+// Square indexes the board from a1 (0) to h8 (63).
+type Square int
+
+func SquareAt(file, rank int) Square {
+	return Square(rank*8 + file)
+}
+
+func ParseSquare(s string) (Square, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return 0, fmt.Errorf("invalid square %q", s)
+	}
+	return SquareAt(int(s[0]-'a'), int(s[1]-'1')), nil
+}
+
+func (s Square) File() int { return int(s) % 8 }
+func (s Square) Rank() int { return int(s) / 8 }
+
+func (s Square) String() string {
+	return string([]byte{byte('a' + s.File()), byte('1' + s.Rank())})
+}
+
+// Piece is a FEN letter: uppercase for white, lowercase for black, 0 for an
+// empty square.
+type Piece byte
+
+func (p Piece) Color() byte {
+	if unicode.IsUpper(rune(p)) {
+		return WHITE
+	}
+	return BLACK
+}
+
+func (p Piece) Kind() byte {
+	return byte(unicode.ToLower(rune(p)))
+}
+
+var glyphs = map[byte]string{'k': "♚", 'q': "♛", 'r': "♜", 'b': "♝", 'n': "♞", 'p': "♟"}
+
+// Glyph is the piece's symbol. Both colors share the filled symbols, so
+// callers tell them apart by color, which reads better on dark terminals.
+func (p Piece) Glyph() string {
+	return glyphs[p.Kind()]
+}
+
+// Castling rights
+const (
+	WHITESHORT = 1 << iota
+	WHITELONG
+	BLACKSHORT
+	BLACKLONG
+)
+
+var castlingLetters = map[rune]int{'K': WHITESHORT, 'Q': WHITELONG, 'k': BLACKSHORT, 'q': BLACKLONG}
+
+// START is the position games begin from.
+const START = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+type Board struct {
+	Squares [64]Piece
+	Turn    byte
+	// Castling holds the castling rights left, an OR of WHITESHORT and the
+	// others.
+	Castling int
+	// EnPassant is the square a pawn just skipped over, where it can be
+	// taken en passant. It is 0 when there's none: a1 never is one.
+	EnPassant Square
+}
+
+// NewBoard is the starting position.
+func NewBoard() Board {
+	b, _ := ParseFEN(START)
+	return b
+}
+
+// ParseFEN reads the placement, side to move, castling rights and en
+// passant target of a FEN position. Only the first two fields are required;
+// the move counters are ignored.
+func ParseFEN(fen string) (Board, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 2 {
+		return Board{}, fmt.Errorf("invalid FEN %q", fen)
+	}
+
+	var b Board
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return Board{}, fmt.Errorf("invalid FEN %q: want 8 ranks", fen)
+	}
+	for i, row := range ranks {
+		rank, file := 7-i, 0
+		for _, c := range row {
+			switch {
+			case c >= '1' && c <= '8':
+				file += int(c - '0')
+			case strings.ContainsRune("kqrbnpKQRBNP", c):
+				if file > 7 {
+					return Board{}, fmt.Errorf("invalid FEN %q: rank %d too long", fen, rank+1)
+				}
+				b.Squares[SquareAt(file, rank)] = Piece(c)
+				file++
+			default:
+				return Board{}, fmt.Errorf("invalid FEN %q: unexpected %q", fen, c)
+			}
+		}
+		if file != 8 {
+			return Board{}, fmt.Errorf("invalid FEN %q: rank %d has %d files", fen, rank+1, file)
+		}
+	}
+
+	switch fields[1] {
+	case "w":
+		b.Turn = WHITE
+	case "b":
+		b.Turn = BLACK
+	default:
+		return Board{}, fmt.Errorf("invalid FEN %q: side to move %q", fen, fields[1])
+	}
+
+	if len(fields) > 2 && fields[2] != "-" {
+		for _, c := range fields[2] {
+			right, ok := castlingLetters[c]
+			if !ok {
+				return Board{}, fmt.Errorf("invalid FEN %q: castling %q", fen, fields[2])
+			}
+			b.Castling |= right
+		}
+	}
+	if len(fields) > 3 && fields[3] != "-" {
+		s, err := ParseSquare(fields[3])
+		if err != nil {
+			return Board{}, fmt.Errorf("invalid FEN %q: %w", fen, err)
+		}
+		b.EnPassant = s
+	}
+	return b, nil
+}
+
+func (b Board) At(s Square) Piece {
+	return b.Squares[s]
+}
+
+// Move is a parsed UCI move.
+type Move struct {
+	From, To  Square
+	Promotion byte // piece kind, 0 if none
+}
+
+func ParseMove(uci string) (Move, error) {
+	if len(uci) != 4 && len(uci) != 5 {
+		return Move{}, fmt.Errorf("invalid move %q", uci)
+	}
+	from, err := ParseSquare(uci[:2])
+	if err != nil {
+		return Move{}, err
+	}
+	to, err := ParseSquare(uci[2:4])
+	if err != nil {
+		return Move{}, err
+	}
+	m := Move{From: from, To: to}
+	if len(uci) == 5 {
+		if !strings.ContainsRune("qrbn", rune(uci[4])) {
+			return Move{}, fmt.Errorf("invalid promotion in %q", uci)
+		}
+		m.Promotion = uci[4]
+	}
+	return m, nil
+}
+
+func (m Move) String() string {
+	s := m.From.String() + m.To.String()
+	if m.Promotion != 0 {
+		s += string(m.Promotion)
+	}
+	return s
+}
+
+// Play makes a move for the side to move, including castling, en passant
+// and promotion, and updates the castling rights and en passant target. It
+// doesn't check the move is legal, see Legal.
+func (b *Board) Play(m Move) error {
+	p := b.Squares[m.From]
+	if p == 0 || p.Color() != b.Turn {
+		return fmt.Errorf("no %c piece on %s", b.Turn, m.From)
+	}
+
+	switch p.Kind() {
+	case 'k':
+		// Castling moves the king two files; bring the rook alongside.
+		if d := m.To.File() - m.From.File(); d == 2 || d == -2 {
+			rook, to := SquareAt(7, m.From.Rank()), SquareAt(5, m.From.Rank())
+			if d < 0 {
+				rook, to = SquareAt(0, m.From.Rank()), SquareAt(3, m.From.Rank())
+			}
+			b.Squares[to], b.Squares[rook] = b.Squares[rook], 0
+		}
+	case 'p':
+		// A pawn moving diagonally onto an empty square captures en passant.
+		if m.From.File() != m.To.File() && b.Squares[m.To] == 0 {
+			b.Squares[SquareAt(m.To.File(), m.From.Rank())] = 0
+		}
+		if m.Promotion != 0 {
+			p = Piece(m.Promotion)
+			if b.Turn == WHITE {
+				p = Piece(unicode.ToUpper(rune(p)))
+			}
+		}
+	}
+
+	b.EnPassant = 0
+	if p.Kind() == 'p' && (m.To.Rank()-m.From.Rank() == 2 || m.From.Rank()-m.To.Rank() == 2) {
+		b.EnPassant = SquareAt(m.From.File(), (m.From.Rank()+m.To.Rank())/2)
+	}
+	if p.Kind() == 'k' {
+		if b.Turn == WHITE {
+			b.Castling &^= WHITESHORT | WHITELONG
+		} else {
+			b.Castling &^= BLACKSHORT | BLACKLONG
+		}
+	}
+	// A rook leaving its corner, or taken there, can't castle anymore.
+	for corner, right := range rookCorners {
+		if m.From == corner || m.To == corner {
+			b.Castling &^= right
+		}
+	}
+
+	b.Squares[m.To], b.Squares[m.From] = p, 0
+	b.Turn = Opponent(b.Turn)
+	return nil
+}
+
+// rookCorners are the squares rooks castle from, with the right each gives.
+var rookCorners = map[Square]int{0: WHITELONG, 7: WHITESHORT, 56: BLACKLONG, 63: BLACKSHORT}
+
+func Opponent(color byte) byte {
+	if color == WHITE {
+		return BLACK
+	}
+	return WHITE
+}
@@ -0,0 +1,485 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/review"
+	"github.com/debemdeboas/games.debem.dev/turns"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 100 * time.Millisecond
+
+var colorNames = map[byte]string{chess.WHITE: "White", chess.BLACK: "Black"}
+
+var reasons = map[string]string{
+	CHECKMATE:      "Checkmate",
+	STALEMATE:      "Stalemate",
+	INSUFFICIENT:   "Insufficient material",
+	REPETITION:     "Threefold repetition",
+	FIFTYMOVES:     "Fifty-move rule",
+	turns.RESIGNED: "Resigned",
+	turns.LEFT:     "Opponent left",
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	LightStyle    lipgloss.Style
+	DarkStyle     lipgloss.Style
+	CursorStyle   lipgloss.Style
+	SelectedStyle lipgloss.Style
+	TargetStyle   lipgloss.Style
+	LastStyle     lipgloss.Style
+	CheckStyle    lipgloss.Style
+	QuitStyle     lipgloss.Style
+	BoxStyle      lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room  string
+	match *Match
+	side  int
+	snap  Snapshot
+
+	cursor   chess.Square
+	selected *chess.Square
+	// promoting is the pawn move waiting for the player to pick a piece,
+	// promotions[promoteAt].
+	promoting *chess.Move
+	promoteAt int
+	review    *review.Model
+
+	showHelp bool
+
+	r   *lipgloss.Renderer
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:         width,
+		Height:        height,
+		LightStyle:    r.NewStyle().Background(lipgloss.Color("180")),
+		DarkStyle:     r.NewStyle().Background(lipgloss.Color("137")),
+		CursorStyle:   r.NewStyle().Background(lipgloss.Color("75")),
+		SelectedStyle: r.NewStyle().Background(lipgloss.Color("114")),
+		TargetStyle:   r.NewStyle().Background(lipgloss.Color("108")),
+		LastStyle:     r.NewStyle().Background(lipgloss.Color("186")),
+		CheckStyle:    r.NewStyle().Background(lipgloss.Color("167")),
+		QuitStyle:     r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		r:      r,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = lobby.Join(m.Room, m.Player, now)
+	m.selected, m.promoting, m.review = nil, nil, nil
+	m.refresh(now)
+	// Start on the king's pawn, from the player's side.
+	m.cursor = m.flip(chess.SquareAt(4, 1))
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.Opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.Leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.match.Poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	starting := m.snap.Phase == turns.WAITING && snap.Phase == turns.PLAYING
+	m.snap = snap
+	if starting {
+		m.cursor = m.flip(chess.SquareAt(4, 1))
+	}
+}
+
+func (m Model) color() byte {
+	if m.snap.Phase == turns.WAITING {
+		return chess.WHITE
+	}
+	return seatColors[m.snap.Players[m.side].Seat]
+}
+
+func (m Model) myTurn() bool {
+	return m.snap.Phase == turns.PLAYING && m.snap.State.Board.Turn == m.color()
+}
+
+// flip maps squares between the board and the player's point of view, so
+// their pieces are always at the bottom.
+func (m Model) flip(s chess.Square) chess.Square {
+	if m.color() == chess.BLACK {
+		return 63 - s
+	}
+	return s
+}
+
+func (m *Model) moveCursor(df, dr int) {
+	view := m.flip(m.cursor)
+	f := max(0, min(7, view.File()+df))
+	r := max(0, min(7, view.Rank()+dr))
+	m.cursor = m.flip(chess.SquareAt(f, r))
+}
+
+// targets are the legal moves of the selected piece.
+func (m Model) targets() []chess.Move {
+	if m.selected == nil {
+		return nil
+	}
+	var moves []chess.Move
+	for _, mv := range m.snap.State.Board.LegalMoves() {
+		if mv.From == *m.selected {
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
+
+func (m *Model) selectSquare() {
+	if !m.myTurn() {
+		return
+	}
+	p := m.snap.State.Board.At(m.cursor)
+	switch {
+	case p != 0 && p.Color() == m.color():
+		if m.selected != nil && *m.selected == m.cursor {
+			m.selected = nil
+			return
+		}
+		s := m.cursor
+		m.selected = &s
+	case m.selected != nil:
+		for _, mv := range m.targets() {
+			if mv.To != m.cursor {
+				continue
+			}
+			if mv.Promotion != 0 {
+				mv.Promotion = 0
+				m.promoting, m.promoteAt = &mv, 0
+				return
+			}
+			m.play(mv)
+			return
+		}
+		m.selected = nil
+	}
+}
+
+func (m *Model) play(mv chess.Move) {
+	m.match.Play(m.side, mv)
+	m.selected, m.promoting = nil, nil
+	m.refresh(time.Now())
+}
+
+func (m *Model) updatePromotion(msg tea.KeyMsg) {
+	n := len(promotions)
+	switch {
+	case key.Matches(msg, m.Keys.Left), key.Matches(msg, m.Keys.Up):
+		m.promoteAt = (m.promoteAt + n - 1) % n
+	case key.Matches(msg, m.Keys.Right), key.Matches(msg, m.Keys.Down):
+		m.promoteAt = (m.promoteAt + 1) % n
+	case key.Matches(msg, m.Keys.Select):
+		mv := *m.promoting
+		mv.Promotion = promotions[m.promoteAt]
+		m.play(mv)
+	}
+}
+
+// promotions are the pieces players promote to, in the order offered.
+var promotions = []byte{'q', 'r', 'b', 'n'}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		if m.review != nil {
+			m.review.Update(msg)
+			if m.review.Closed() {
+				m.review = nil
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.Leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case m.promoting != nil && m.myTurn():
+			m.updatePromotion(msg)
+		case key.Matches(msg, m.Keys.Resign):
+			m.match.Resign(m.side)
+			m.refresh(time.Now())
+		case key.Matches(msg, m.Keys.Review):
+			if m.snap.Phase == turns.OVER {
+				render := func(b chess.Board, last *chess.Move) string { return m.boardView(b, last, false) }
+				m.review = review.New(newRecord(m.snap, render), m.r)
+			}
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == turns.OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(0, 1)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(0, -1)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(-1, 0)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(1, 0)
+		case key.Matches(msg, m.Keys.Select):
+			m.selectSquare()
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+// square draws s of b. Targets are the squares the selected piece can go
+// to, and check the square of a king in check, if any.
+func (m Model) square(b chess.Board, s chess.Square, last *chess.Move, targets []chess.Move, check *chess.Square, live bool) string {
+	style := m.LightStyle
+	if (s.File()+s.Rank())%2 == 0 {
+		style = m.DarkStyle
+	}
+	target := slices.ContainsFunc(targets, func(mv chess.Move) bool { return mv.To == s })
+	switch {
+	case live && s == m.cursor:
+		style = m.CursorStyle
+	case live && m.selected != nil && *m.selected == s:
+		style = m.SelectedStyle
+	case target:
+		style = m.TargetStyle
+	case check != nil && *check == s:
+		style = m.CheckStyle
+	case last != nil && (last.From == s || last.To == s):
+		style = m.LastStyle
+	}
+
+	p := b.At(s)
+	if p == 0 {
+		if target {
+			return style.Foreground(lipgloss.Color("0")).Render(" · ")
+		}
+		return style.Render("   ")
+	}
+	fg := lipgloss.Color("0")
+	if p.Color() == chess.WHITE {
+		fg = lipgloss.Color("15")
+	}
+	return style.Foreground(fg).Render(" " + p.Glyph() + " ")
+}
+
+// boardView draws b from the player's side, with the cursor and the
+// selected piece's moves when live, for the match's own board.
+func (m Model) boardView(b chess.Board, last *chess.Move, live bool) string {
+	var targets []chess.Move
+	if live {
+		targets = m.targets()
+	}
+	var check *chess.Square
+	if king, ok := b.King(b.Turn); ok && b.InCheck() {
+		check = &king
+	}
+	var s strings.Builder
+	for r := 7; r >= 0; r-- {
+		fmt.Fprintf(&s, "%d ", m.flip(chess.SquareAt(0, r)).Rank()+1)
+		for f := 0; f < 8; f++ {
+			s.WriteString(m.square(b, m.flip(chess.SquareAt(f, r)), last, targets, check, live))
+		}
+		s.WriteString("\n")
+	}
+	s.WriteString("  ")
+	for f := 0; f < 8; f++ {
+		fmt.Fprintf(&s, " %c ", 'a'+m.flip(chess.SquareAt(f, 0)).File())
+	}
+	return s.String()
+}
+
+func (m Model) lastMove() *chess.Move {
+	if n := len(m.snap.Moves); n > 0 {
+		return &m.snap.Moves[n-1]
+	}
+	return nil
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Players {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		sides = append(sides, fmt.Sprintf("%s: %s", colorNames[seatColors[p.Seat]], name))
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case turns.WAITING:
+		return "Waiting for an opponent..."
+	case turns.PLAYING:
+		if m.promoting != nil && m.myTurn() {
+			return m.promotionView()
+		}
+		var s string
+		if m.myTurn() {
+			s = "Your move"
+		} else {
+			s = fmt.Sprintf("%s to move...", m.snap.Players[1-m.side].Name)
+		}
+		if n := len(m.snap.State.Sans); n > 0 {
+			s = fmt.Sprintf("%d. %s • %s", (n+1)/2, m.snap.State.Sans[n-1], s)
+		}
+		if m.snap.State.Board.InCheck() {
+			s += " • check!"
+		}
+		return s
+	}
+	return ""
+}
+
+func (m Model) promotionView() string {
+	var choices []string
+	for i, kind := range promotions {
+		glyph := chess.Piece(kind).Glyph()
+		if i == m.promoteAt {
+			glyph = "[" + glyph + "]"
+		} else {
+			glyph = " " + glyph + " "
+		}
+		choices = append(choices, glyph)
+	}
+	return fmt.Sprintf("Promote to %s • %s pick", strings.Join(choices, ""), m.Keys.Select.Help().Key)
+}
+
+func (m Model) resultView() string {
+	var outcome string
+	switch m.snap.Winner {
+	case -1:
+		outcome = "Draw"
+	case m.side:
+		outcome = "You win!"
+	default:
+		outcome = m.snap.Players[m.snap.Winner].Name + " wins"
+	}
+	reason := reasons[m.snap.Reason]
+	if m.snap.Reason == turns.RESIGNED && m.snap.Winner == m.side {
+		reason = m.snap.Players[1-m.side].Name + " resigned"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s: %s %s\n\nPress '%s' to review and copy the PGN, '%s' for a new match",
+		reason, outcome, result(m.snap), m.Keys.Review.Help().Key, m.Keys.Rematch.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.review != nil {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.review.View(),
+		)
+	}
+
+	if m.snap.Phase == turns.WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == turns.OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardView(m.snap.State.Board, m.lastMove(), true),
+			"",
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+// Why matches end on the board
+const (
+	CHECKMATE    = "checkmate"
+	STALEMATE    = "stalemate"
+	INSUFFICIENT = "insufficient material"
+	REPETITION   = "threefold repetition"
+	FIFTYMOVES   = "fifty-move rule"
+)
+
+const (
+	REPETITIONS = 3   // times a position comes up before the match is drawn
+	QUIETPLIES  = 100 // moves in a row, of both sides, without a capture or a pawn move that draw
+)
+
+// position is a match's board, with its moves so far in SAN and the
+// positions since the last capture or pawn move, this one last, which
+// repetitions and the fifty-move rule count. See chess.Board.Position.
+type position struct {
+	Board   chess.Board
+	Sans    []string
+	History []chess.Board
+}
+
+type (
+	Match    = turns.Match[position, chess.Move]
+	Snapshot = turns.Snapshot[position, chess.Move]
+)
+
+// White takes the first seat.
+var seatColors = [2]byte{chess.WHITE, chess.BLACK}
+
+func toMove(p position) int {
+	return slices.Index(seatColors[:], p.Board.Turn)
+}
+
+var rules = turns.Rules[position, chess.Move]{
+	Start: func() position {
+		b := chess.NewBoard()
+		return position{Board: b, History: []chess.Board{b.Position()}}
+	},
+	ToMove: toMove,
+	Play: func(p position, mv chess.Move) (position, bool) {
+		if !p.Board.Legal(mv) {
+			return p, false
+		}
+		// Clipped, so the SANs and history of earlier snapshots stay as
+		// they are.
+		sans := append(slices.Clip(p.Sans), p.Board.SAN(mv))
+		history := slices.Clip(p.History)
+		if p.Board.Squares[mv.From].Kind() == 'p' || p.Board.Squares[mv.To] != 0 {
+			history = nil // no earlier position can come up again
+		}
+		p.Board.Play(mv)
+		return position{Board: p.Board, Sans: sans, History: append(history, p.Board.Position())}, true
+	},
+	Result: func(p position) (int, string, bool) {
+		switch p.Board.Outcome() {
+		case chess.CHECKMATE:
+			return 1 - toMove(p), CHECKMATE, true
+		case chess.STALEMATE:
+			return -1, STALEMATE, true
+		}
+		switch {
+		case p.Board.InsufficientMaterial():
+			return -1, INSUFFICIENT, true
+		case len(p.History) > 0 && repeats(p.History, p.History[len(p.History)-1]) >= REPETITIONS:
+			return -1, REPETITION, true
+		case len(p.History) > QUIETPLIES:
+			return -1, FIFTYMOVES, true
+		}
+		return 0, "", false
+	},
+}
+
+var lobby = turns.NewLobby(rules)
+
+// repeats counts the times b comes up in history.
+func repeats(history []chess.Board, b chess.Board) int {
+	n := 0
+	for _, h := range history {
+		if h == b {
+			n++
+		}
+	}
+	return n
+}
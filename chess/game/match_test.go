@@ -0,0 +1,155 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+// play plays the moves in UCI through the match rules, from p.
+func play(t *testing.T, p position, ucis ...string) position {
+	t.Helper()
+	for i, uci := range ucis {
+		mv, err := chess.ParseMove(uci)
+		if err != nil {
+			t.Fatalf("move %d, %s: %v", i+1, uci, err)
+		}
+		var ok bool
+		if p, ok = rules.Play(p, mv); !ok {
+			t.Fatalf("move %d, %s, isn't legal", i+1, uci)
+		}
+	}
+	return p
+}
+
+// fromFEN is a match that starts at fen.
+func fromFEN(t *testing.T, fen string) position {
+	t.Helper()
+	b, err := chess.ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return position{Board: b, History: []chess.Board{b.Position()}}
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		fen  string
+		draw bool
+	}{
+		{"7k/8/8/8/8/8/8/K7 w - -", true},         // K v K
+		{"7k/8/8/8/8/8/8/KB6 w - -", true},        // K+B v K
+		{"7k/8/8/8/8/8/8/KN6 w - -", true},        // K+N v K
+		{"7k/8/8/8/8/8/8/KB1B4 w - -", true},      // bishops on light squares only
+		{"6bk/8/8/8/8/8/8/KB6 w - -", true},       // bishops of both sides on one color
+		{"7k/8/8/8/8/8/8/KBB5 w - -", false},      // bishops on both colors
+		{"7k/8/8/8/8/8/8/KNN5 w - -", false},      // two knights can mate with help
+		{"7k/8/8/8/8/8/8/KBN5 w - -", false},      // bishop and knight
+		{"7k/8/8/8/8/8/P7/K7 w - -", false},       // a pawn can promote
+		{"7k/8/8/8/8/8/8/KR6 w - -", false},       // K+R v K
+		{"6nk/8/8/8/8/8/8/KN6 w - -", false},      // a knight each
+		{"rnbqkbnr/8/8/8/8/8/8/4K3 w - -", false}, // one side with everything
+	}
+	for _, tt := range tests {
+		winner, reason, over := rules.Result(fromFEN(t, tt.fen))
+		if got := over && reason == INSUFFICIENT; got != tt.draw {
+			t.Errorf("%s: Result() = %d, %q, %t, want a draw %t", tt.fen, winner, reason, over, tt.draw)
+		}
+		if over && winner != -1 {
+			t.Errorf("%s: winner %d, want a draw", tt.fen, winner)
+		}
+	}
+}
+
+func TestRepetition(t *testing.T) {
+	shuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	p := play(t, rules.Start(), shuffle...)
+	p = play(t, p, shuffle[:3]...)
+	if _, reason, over := rules.Result(p); over {
+		t.Fatalf("over after the start came up twice, by %q", reason)
+	}
+	p = play(t, p, shuffle[3])
+	if winner, reason, over := rules.Result(p); !over || winner != -1 || reason != REPETITION {
+		t.Errorf("Result() = %d, %q, %t, want a draw by %q", winner, reason, over, REPETITION)
+	}
+}
+
+func TestHistoryResets(t *testing.T) {
+	p := play(t, rules.Start(), "g1f3", "g8f6", "e2e4")
+	if len(p.History) != 1 {
+		t.Errorf("history after a pawn move has %d positions, want 1", len(p.History))
+	}
+	p = play(t, p, "f6e4")
+	if len(p.History) != 1 {
+		t.Errorf("history after a capture has %d positions, want 1", len(p.History))
+	}
+	p = play(t, p, "b1c3")
+	if len(p.History) != 2 {
+		t.Errorf("history after a quiet move has %d positions, want 2", len(p.History))
+	}
+
+	// The snapshot before the capture keeps its own history.
+	before := play(t, rules.Start(), "g1f3", "g8f6")
+	after := play(t, before, "f3g1")
+	if len(before.History) != 3 || len(after.History) != 4 {
+		t.Errorf("histories have %d and %d positions, want 3 and 4", len(before.History), len(after.History))
+	}
+}
+
+func TestPositionEnPassant(t *testing.T) {
+	// Nothing can take on e3.
+	p := play(t, rules.Start(), "e2e4")
+	if got := p.History[0].EnPassant; got != 0 {
+		t.Errorf("en passant %s kept with no pawn to take there", got)
+	}
+	// The e5 pawn can take on d6.
+	p = play(t, p, "a7a6", "e4e5", "d7d5")
+	if got, want := p.History[0].EnPassant, chess.Square(43); got != want {
+		t.Errorf("en passant = %s, want %s", got, want)
+	}
+}
+
+func TestFiftyMoves(t *testing.T) {
+	// A rook walks the board, every position coming up at most twice.
+	p := fromFEN(t, "7k/8/8/8/8/8/8/K7 w - -")
+	var history []chess.Board
+	free := []chess.Square{}
+	for s := range chess.Square(64) {
+		if p.Board.Squares[s] == 0 {
+			free = append(free, s)
+		}
+	}
+	for i := range QUIETPLIES + 1 {
+		b := p.Board
+		b.Squares[free[i/2]] = 'R'
+		b.Turn = [2]byte{chess.WHITE, chess.BLACK}[i%2]
+		history = append(history, b)
+	}
+
+	q := position{Board: history[QUIETPLIES-1], History: history[:QUIETPLIES]}
+	if _, reason, over := rules.Result(q); over {
+		t.Fatalf("over after %d quiet moves, by %q", QUIETPLIES-1, reason)
+	}
+	q = position{Board: history[QUIETPLIES], History: history}
+	if winner, reason, over := rules.Result(q); !over || winner != -1 || reason != FIFTYMOVES {
+		t.Errorf("Result() = %d, %q, %t, want a draw by %q", winner, reason, over, FIFTYMOVES)
+	}
+}
+
+func TestPGNTermination(t *testing.T) {
+	snap := Snapshot{
+		Phase:   turns.OVER,
+		State:   play(t, rules.Start(), "g1f3"),
+		Players: []turns.Player{{Name: "ann", Seat: 0}, {Name: "bob", Seat: 1}},
+		Winner:  -1,
+		Reason:  REPETITION,
+	}
+	got := pgn(snap)
+	for _, want := range []string{`[Termination "Threefold repetition"]`, `[Result "1/2-1/2"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("PGN has no %s:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/review"
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+// values are the pieces' worth in centipawns, for annotating finished
+// matches.
+var values = map[byte]int{'p': 100, 'n': 300, 'b': 300, 'r': 500, 'q': 900}
+
+const (
+	MATE   = 10000 // the evaluation of a checkmated board, past any material
+	QDEPTH = 4     // captures looked ahead of each evaluated position
+)
+
+// material counts the pieces on b for white.
+func material(b chess.Board) int {
+	eval := 0
+	for _, p := range b.Squares {
+		if p == 0 {
+			continue
+		}
+		if p.Color() == chess.WHITE {
+			eval += values[p.Kind()]
+		} else {
+			eval -= values[p.Kind()]
+		}
+	}
+	return eval
+}
+
+// evaluate rates b for white by the material it comes down to once the
+// side to move made the captures worth making, so a move that leaves a
+// piece hanging counts as losing it. Checkmate is MATE for the side that
+// delivered it.
+func evaluate(b chess.Board) int {
+	eval := quiesce(b, QDEPTH, -MATE, MATE)
+	if b.Turn == chess.BLACK {
+		eval = -eval
+	}
+	return eval
+}
+
+// quiesce rates b for the side to move, which may stand pat or take
+// whatever it can, up to depth captures ahead.
+func quiesce(b chess.Board, depth, alpha, beta int) int {
+	moves := b.LegalMoves()
+	if len(moves) == 0 {
+		if b.InCheck() {
+			return -MATE
+		}
+		return 0
+	}
+	stand := material(b)
+	if b.Turn == chess.BLACK {
+		stand = -stand
+	}
+	if stand >= beta || depth == 0 {
+		return max(alpha, stand)
+	}
+	alpha = max(alpha, stand)
+	for _, mv := range moves {
+		if !capture(b, mv) {
+			continue
+		}
+		next := b
+		next.Play(mv)
+		if score := -quiesce(next, depth-1, -beta, -alpha); score > alpha {
+			if score >= beta {
+				return score
+			}
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// capture reports whether mv takes a piece or promotes.
+func capture(b chess.Board, mv chess.Move) bool {
+	return b.Squares[mv.To] != 0 || mv.Promotion != 0 ||
+		b.Squares[mv.From].Kind() == 'p' && mv.From.File() != mv.To.File()
+}
+
+// record is a finished match as the review screen steps through it,
+// annotated by material and a short capture search: there's no engine
+// behind it, so it flags moves that lose pieces rather than position.
+type record struct {
+	snap   Snapshot
+	boards []chess.Board // after each move, the starting position first
+	moves  []review.Move
+	render func(b chess.Board, last *chess.Move) string
+}
+
+func newRecord(snap Snapshot, render func(chess.Board, *chess.Move) string) *record {
+	r := &record{snap: snap, render: render}
+	b := chess.NewBoard()
+	r.boards = append(r.boards, b)
+	for i, mv := range snap.Moves {
+		b.Play(mv)
+		r.boards = append(r.boards, b)
+		r.moves = append(r.moves, review.Move{Player: i % 2, Notation: snap.State.Sans[i], Eval: evaluate(b)})
+	}
+	return r
+}
+
+func (r *record) Moves() []review.Move {
+	return r.moves
+}
+
+func (r *record) Position(n int) string {
+	var last *chess.Move
+	if n > 0 {
+		last = &r.snap.Moves[n-1]
+	}
+	return r.render(r.boards[n], last)
+}
+
+func (r *record) Export() string {
+	return pgn(r.snap)
+}
+
+// result is the match's result as PGN writes it.
+func result(snap Snapshot) string {
+	switch {
+	case snap.Phase != turns.OVER:
+		return chess.UNFINISHED
+	case snap.Winner < 0:
+		return chess.DRAW
+	case seatColors[snap.Players[snap.Winner].Seat] == chess.WHITE:
+		return chess.WHITEWINS
+	}
+	return chess.BLACKWINS
+}
+
+func pgn(snap Snapshot) string {
+	tags := []chess.Tag{
+		{Name: "Event", Value: "Casual game"},
+		{Name: "Site", Value: "games.debem.dev"},
+		{Name: "Date", Value: snap.Start.UTC().Format("2006.01.02")},
+		{Name: "Round", Value: "-"},
+	}
+	for _, color := range []byte{chess.WHITE, chess.BLACK} {
+		for _, p := range snap.Players {
+			if seatColors[p.Seat] == color {
+				tags = append(tags, chess.Tag{Name: colorNames[color], Value: p.Name})
+			}
+		}
+	}
+	if reason, ok := reasons[snap.Reason]; ok && snap.Phase == turns.OVER {
+		tags = append(tags, chess.Tag{Name: "Termination", Value: reason})
+	}
+	return chess.PGN(tags, snap.State.Sans, result(snap))
+}
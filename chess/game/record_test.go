@@ -0,0 +1,56 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/review"
+)
+
+// annotate plays the moves in UCI from the starting position, evaluating
+// each as the review screen does.
+func annotate(t *testing.T, ucis ...string) []review.Move {
+	t.Helper()
+	b := chess.NewBoard()
+	var moves []review.Move
+	for i, uci := range ucis {
+		mv, err := chess.ParseMove(uci)
+		if err != nil || !b.Legal(mv) {
+			t.Fatalf("move %d, %s, isn't legal", i+1, uci)
+		}
+		b.Play(mv)
+		moves = append(moves, review.Move{Player: i % 2, Notation: uci, Eval: evaluate(b)})
+	}
+	return moves
+}
+
+func TestHangingPieceIsBlunder(t *testing.T) {
+	// 1. e4 d5 2. Qg4?? leaves the queen to the bishop on c8.
+	moves := annotate(t, "e2e4", "d7d5", "d1g4")
+	if loss := review.Loss(moves, 2); loss < review.BLUNDER {
+		t.Errorf("Qg4 lost %d, want a blunder of at least %d", loss, review.BLUNDER)
+	}
+	for i := range 2 {
+		if loss := review.Loss(moves, i); loss >= review.MISTAKE {
+			t.Errorf("%s lost %d, want no mistake", moves[i].Notation, loss)
+		}
+	}
+}
+
+func TestDefendedCaptureIsFair(t *testing.T) {
+	// 1. e4 d5 2. exd5 Qxd5: pawns traded, nobody lost anything.
+	moves := annotate(t, "e2e4", "d7d5", "e4d5", "d8d5")
+	for i := range moves {
+		if loss := review.Loss(moves, i); loss >= review.MISTAKE {
+			t.Errorf("%s lost %d, want no mistake", moves[i].Notation, loss)
+		}
+	}
+}
+
+func TestMateEvaluation(t *testing.T) {
+	// Fool's mate.
+	moves := annotate(t, "f2f3", "e7e5", "g2g4", "d8h4")
+	if got := moves[3].Eval; got != -MATE {
+		t.Errorf("eval after Qh4# = %d, want %d", got, -MATE)
+	}
+}
@@ -0,0 +1,292 @@
+package chess
+
+import "strings"
+
+// Ways a game stands after a move, see Outcome.
+const (
+	ONGOING = iota
+	CHECKMATE
+	STALEMATE
+)
+
+type offset struct{ df, dr int }
+
+var (
+	knightJumps = []offset{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps   = []offset{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	rookRays    = []offset{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	bishopRays  = []offset{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+)
+
+// promotions are the kinds pawns promote to, the likeliest first.
+const promotions = "qrbn"
+
+// step is the square off away from s, if it's on the board.
+func (s Square) step(off offset) (Square, bool) {
+	f, r := s.File()+off.df, s.Rank()+off.dr
+	if f < 0 || f > 7 || r < 0 || r > 7 {
+		return 0, false
+	}
+	return SquareAt(f, r), true
+}
+
+// Attacked reports whether a piece of color by attacks s.
+func (b *Board) Attacked(s Square, by byte) bool {
+	is := func(from Square, kinds string) bool {
+		p := b.Squares[from]
+		return p != 0 && p.Color() == by && strings.IndexByte(kinds, p.Kind()) >= 0
+	}
+
+	// Pawns take towards the far side, so look back from s.
+	back := -1
+	if by == BLACK {
+		back = 1
+	}
+	for _, df := range []int{-1, 1} {
+		if from, ok := s.step(offset{df, back}); ok && is(from, "p") {
+			return true
+		}
+	}
+	for _, off := range knightJumps {
+		if from, ok := s.step(off); ok && is(from, "n") {
+			return true
+		}
+	}
+	for _, off := range kingSteps {
+		if from, ok := s.step(off); ok && is(from, "k") {
+			return true
+		}
+	}
+	for _, ray := range []struct {
+		offs  []offset
+		kinds string
+	}{{rookRays, "rq"}, {bishopRays, "bq"}} {
+		for _, off := range ray.offs {
+			for from, ok := s.step(off); ok; from, ok = from.step(off) {
+				if b.Squares[from] != 0 {
+					if is(from, ray.kinds) {
+						return true
+					}
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+// King is the square of color's king, false when it has none, as in some
+// puzzles.
+func (b *Board) King(color byte) (Square, bool) {
+	for s, p := range b.Squares {
+		if p.Kind() == 'k' && p.Color() == color {
+			return Square(s), true
+		}
+	}
+	return 0, false
+}
+
+// InCheck reports whether the side to move is in check.
+func (b *Board) InCheck() bool {
+	k, ok := b.King(b.Turn)
+	return ok && b.Attacked(k, Opponent(b.Turn))
+}
+
+// pseudoMoves are the side to move's moves, leaving its king in check or
+// not. Castling is only offered when the king doesn't pass through check.
+func (b *Board) pseudoMoves() []Move {
+	var moves []Move
+	for i, p := range b.Squares {
+		from := Square(i)
+		if p == 0 || p.Color() != b.Turn {
+			continue
+		}
+		switch p.Kind() {
+		case 'p':
+			moves = b.pawnMoves(moves, from)
+		case 'n':
+			moves = b.steps(moves, from, knightJumps)
+		case 'k':
+			moves = b.steps(moves, from, kingSteps)
+			moves = b.castlings(moves, from)
+		case 'b':
+			moves = b.slides(moves, from, bishopRays)
+		case 'r':
+			moves = b.slides(moves, from, rookRays)
+		case 'q':
+			moves = b.slides(moves, from, rookRays)
+			moves = b.slides(moves, from, bishopRays)
+		}
+	}
+	return moves
+}
+
+// free reports whether the side to move can land on s: it's empty or holds
+// an opponent's piece.
+func (b *Board) free(s Square) bool {
+	p := b.Squares[s]
+	return p == 0 || p.Color() != b.Turn
+}
+
+func (b *Board) steps(moves []Move, from Square, offs []offset) []Move {
+	for _, off := range offs {
+		if to, ok := from.step(off); ok && b.free(to) {
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+	return moves
+}
+
+func (b *Board) slides(moves []Move, from Square, rays []offset) []Move {
+	for _, off := range rays {
+		for to, ok := from.step(off); ok && b.free(to); to, ok = to.step(off) {
+			moves = append(moves, Move{From: from, To: to})
+			if b.Squares[to] != 0 {
+				break
+			}
+		}
+	}
+	return moves
+}
+
+func (b *Board) pawnMoves(moves []Move, from Square) []Move {
+	dir, start, last := 1, 1, 7
+	if b.Turn == BLACK {
+		dir, start, last = -1, 6, 0
+	}
+	add := func(to Square) {
+		if to.Rank() != last {
+			moves = append(moves, Move{From: from, To: to})
+			return
+		}
+		for i := range len(promotions) {
+			moves = append(moves, Move{From: from, To: to, Promotion: promotions[i]})
+		}
+	}
+
+	if to, ok := from.step(offset{0, dir}); ok && b.Squares[to] == 0 {
+		add(to)
+		if two, ok := to.step(offset{0, dir}); ok && from.Rank() == start && b.Squares[two] == 0 {
+			add(two)
+		}
+	}
+	for _, df := range []int{-1, 1} {
+		to, ok := from.step(offset{df, dir})
+		if !ok {
+			continue
+		}
+		if p := b.Squares[to]; p != 0 && p.Color() != b.Turn || b.EnPassant != 0 && to == b.EnPassant {
+			add(to)
+		}
+	}
+	return moves
+}
+
+func (b *Board) castlings(moves []Move, from Square) []Move {
+	short, long, home := WHITESHORT, WHITELONG, SquareAt(4, 0)
+	if b.Turn == BLACK {
+		short, long, home = BLACKSHORT, BLACKLONG, SquareAt(4, 7)
+	}
+	if from != home || b.InCheck() {
+		return moves
+	}
+	them, rank := Opponent(b.Turn), home.Rank()
+	for _, c := range []struct {
+		right        int
+		empty, safe  []int // files between king and rook, and the king passes
+		rook, target int
+	}{
+		{short, []int{5, 6}, []int{5, 6}, 7, 6},
+		{long, []int{1, 2, 3}, []int{3, 2}, 0, 2},
+	} {
+		if b.Castling&c.right == 0 || b.Squares[SquareAt(c.rook, rank)].Kind() != 'r' {
+			continue
+		}
+		ok := true
+		for _, f := range c.empty {
+			ok = ok && b.Squares[SquareAt(f, rank)] == 0
+		}
+		for _, f := range c.safe {
+			ok = ok && !b.Attacked(SquareAt(f, rank), them)
+		}
+		if ok {
+			moves = append(moves, Move{From: from, To: SquareAt(c.target, rank)})
+		}
+	}
+	return moves
+}
+
+// LegalMoves are the moves the side to move can make.
+func (b Board) LegalMoves() []Move {
+	var legal []Move
+	for _, m := range b.pseudoMoves() {
+		after := b
+		after.Play(m)
+		if k, ok := after.King(b.Turn); !ok || !after.Attacked(k, after.Turn) {
+			legal = append(legal, m)
+		}
+	}
+	return legal
+}
+
+// Legal reports whether the side to move can make m. Promotions must name
+// the piece.
+func (b Board) Legal(m Move) bool {
+	for _, l := range b.LegalMoves() {
+		if l == m {
+			return true
+		}
+	}
+	return false
+}
+
+// InsufficientMaterial reports whether neither side can ever checkmate:
+// kings alone but for a single knight, or for bishops that all stand on
+// squares of one color.
+func (b Board) InsufficientMaterial() bool {
+	var knights int
+	var bishops [2]int // on dark and light squares
+	for s, p := range b.Squares {
+		switch p.Kind() {
+		case 0, 'k':
+		case 'n':
+			knights++
+		case 'b':
+			bishops[(Square(s).File()+Square(s).Rank())%2]++
+		default:
+			return false
+		}
+	}
+	if knights > 0 {
+		return knights == 1 && bishops == [2]int{}
+	}
+	return bishops[0] == 0 || bishops[1] == 0
+}
+
+// Position is b as repetitions compare it: the en passant target only
+// counts when a pawn can take there, since it's the moves that make two
+// positions the same.
+func (b Board) Position() Board {
+	if b.EnPassant == 0 {
+		return b
+	}
+	for _, m := range b.LegalMoves() {
+		if m.To == b.EnPassant && b.Squares[m.From].Kind() == 'p' {
+			return b
+		}
+	}
+	b.EnPassant = 0
+	return b
+}
+
+// Outcome is how the game stands for the side to move: checkmated,
+// stalemated or still going.
+func (b Board) Outcome() int {
+	if len(b.LegalMoves()) > 0 {
+		return ONGOING
+	}
+	if b.InCheck() {
+		return CHECKMATE
+	}
+	return STALEMATE
+}
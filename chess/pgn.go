@@ -0,0 +1,127 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SAN writes m down in standard algebraic notation, e.g. "Nbd7", "exd6",
+// "e8=Q+" or "O-O-O#", for the board it is played on.
+func (b Board) SAN(m Move) string {
+	p := b.Squares[m.From]
+	var s strings.Builder
+	switch d := m.To.File() - m.From.File(); {
+	case p.Kind() == 'k' && d == 2:
+		s.WriteString("O-O")
+	case p.Kind() == 'k' && d == -2:
+		s.WriteString("O-O-O")
+	default:
+		capture := b.Squares[m.To] != 0 || p.Kind() == 'p' && m.From.File() != m.To.File()
+		if p.Kind() == 'p' {
+			if capture {
+				s.WriteByte(byte('a' + m.From.File()))
+			}
+		} else {
+			s.WriteRune(unicode.ToUpper(rune(p.Kind())))
+			s.WriteString(b.disambiguation(m))
+		}
+		if capture {
+			s.WriteByte('x')
+		}
+		s.WriteString(m.To.String())
+		if m.Promotion != 0 {
+			s.WriteString("=" + string(unicode.ToUpper(rune(m.Promotion))))
+		}
+	}
+
+	after := b
+	after.Play(m)
+	if after.InCheck() {
+		if after.Outcome() == CHECKMATE {
+			s.WriteByte('#')
+		} else {
+			s.WriteByte('+')
+		}
+	}
+	return s.String()
+}
+
+// disambiguation tells m's piece apart from others of its kind that could
+// go to the same square: by file if that's enough, else by rank, else both.
+func (b Board) disambiguation(m Move) string {
+	kind := b.Squares[m.From].Kind()
+	var others []Square
+	for _, l := range b.LegalMoves() {
+		if l.To == m.To && l.From != m.From && b.Squares[l.From].Kind() == kind {
+			others = append(others, l.From)
+		}
+	}
+	if len(others) == 0 {
+		return ""
+	}
+	sameFile, sameRank := false, false
+	for _, o := range others {
+		sameFile = sameFile || o.File() == m.From.File()
+		sameRank = sameRank || o.Rank() == m.From.Rank()
+	}
+	switch {
+	case !sameFile:
+		return m.From.String()[:1]
+	case !sameRank:
+		return m.From.String()[1:]
+	}
+	return m.From.String()
+}
+
+// Game results, as PGN writes them.
+const (
+	WHITEWINS  = "1-0"
+	BLACKWINS  = "0-1"
+	DRAW       = "1/2-1/2"
+	UNFINISHED = "*"
+)
+
+// Tag is a PGN header pair, e.g. {"White", "alice"}.
+type Tag struct {
+	Name, Value string
+}
+
+// tagEscaper escapes tag values the way PGN does, backslashes first.
+var tagEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// PGN writes a game from the starting position as PGN: the tags, then the
+// moves in SAN, numbered, and the result. The Result tag is added from
+// result. Lines are kept under 80 columns, as the standard asks.
+func PGN(tags []Tag, sans []string, result string) string {
+	var s strings.Builder
+	for _, t := range append(tags, Tag{"Result", result}) {
+		fmt.Fprintf(&s, "[%s \"%s\"]\n", t.Name, tagEscaper.Replace(t.Value))
+	}
+	s.WriteString("\n")
+
+	var words []string
+	for i, san := range sans {
+		if i%2 == 0 {
+			words = append(words, fmt.Sprintf("%d.", i/2+1))
+		}
+		words = append(words, san)
+	}
+	words = append(words, result)
+
+	width := 0
+	for _, w := range words {
+		if width > 0 && width+1+len(w) > 79 {
+			s.WriteString("\n")
+			width = 0
+		}
+		if width > 0 {
+			s.WriteString(" ")
+			width++
+		}
+		s.WriteString(w)
+		width += len(w)
+	}
+	s.WriteString("\n")
+	return s.String()
+}
@@ -0,0 +1,417 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MODE      = "classic"
+	TOPSCORES = 5 // on the game over screen
+)
+
+// ghostColors tell the ghosts apart, in the order of their digits.
+var ghostColors = [GHOSTS]lipgloss.Color{"196", "213", "51", "214"}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	WallStyle   lipgloss.Style
+	DoorStyle   lipgloss.Style
+	PelletStyle lipgloss.Style
+	PlayerStyle lipgloss.Style
+	GhostStyle  lipgloss.Style // colored per ghost, see ghostColors
+	ScaredStyle lipgloss.Style
+	BoardStyle  lipgloss.Style
+	ScoreStyle  lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, keeps the player's runs, which is where their best
+	// score is loaded from.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	best        int // before this run
+	top         []leaderboard.Entry
+	submitted   bool
+
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell *bell.Bell
+
+	world   *World
+	started bool // by the first move
+	paused  bool
+	slow    bool // see games.Env.Slow
+	run     int  // generation of the ticks, so those of a stopped run are dropped
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type tickMsg int
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		WallStyle:   r.NewStyle().Foreground(lipgloss.Color("27")),
+		DoorStyle:   r.NewStyle().Foreground(lipgloss.Color("213")),
+		PelletStyle: r.NewStyle().Foreground(lipgloss.Color("223")),
+		PlayerStyle: r.NewStyle().Foreground(lipgloss.Color("226")).Bold(true),
+		GhostStyle:  r.NewStyle().Bold(true),
+		ScaredStyle: r.NewStyle().Foreground(lipgloss.Color("21")).Bold(true),
+		BoardStyle:  r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		ScoreStyle:  r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext binds the game's ticks to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetScores keeps runs in store and loads the player's best from it.
+func (m *Model) SetScores(store leaderboard.Store, player, fingerprint string) {
+	m.Scores = store
+	m.Player = player
+	m.Fingerprint = fingerprint
+	if store == nil {
+		return
+	}
+	if e, ok := store.Best(m.bestFilter(), fingerprint); ok {
+		m.best = e.Score
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Restart starts over from the first maze, ranking the abandoned run if
+// it scored.
+func (m *Model) Restart() {
+	if m.world != nil {
+		m.submitScore()
+		m.best = max(m.best, m.world.score)
+	}
+	m.world = NewWorld(m.rng)
+	m.started, m.paused, m.submitted = false, false, false
+	m.top = nil
+	m.run++
+}
+
+// SetSlow turns slow mode on or off, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// tickDuration is how long a tick lasts, longer in slow mode.
+func (m Model) tickDuration() time.Duration {
+	if m.slow {
+		return TICK * games.SLOWDOWN
+	}
+	return TICK
+}
+
+func (m Model) tick() tea.Cmd {
+	run := m.run
+	return ui.Every(m.ctx, m.tickDuration(), func(time.Time) tea.Msg {
+		return tickMsg(run)
+	})
+}
+
+// running reports whether the game needs ticks.
+func (m Model) running() bool {
+	return m.started && !m.world.over && !m.paused
+}
+
+// resume starts ticking again, as a new run of ticks.
+func (m *Model) resume() tea.Cmd {
+	m.run++
+	return m.tick()
+}
+
+// Pause stops the ghosts and the player where they are, see games.Pauser.
+func (m *Model) Pause() bool {
+	if !m.running() {
+		return false
+	}
+	m.paused = true
+	return true
+}
+
+func (m *Model) Resume() tea.Cmd {
+	m.paused = false
+	if m.running() {
+		return m.resume()
+	}
+	return nil
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      MODE,
+		Modifiers: m.modifiers(),
+		Board:     fmt.Sprintf("%d mazes", len(mazes)),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+func (m Model) modifiers() string {
+	if m.slow {
+		return leaderboard.Modifiers(leaderboard.ASSISTED)
+	}
+	return leaderboard.Modifiers()
+}
+
+// bestFilter spans seasons: a best score is the player's highest ever.
+func (m Model) bestFilter() leaderboard.Filter {
+	k := m.scoreKey()
+	return leaderboard.Filter{Game: k.Game, Mode: k.Mode, Board: k.Board}
+}
+
+// submitScore records the run once, when it's over or abandoned.
+func (m *Model) submitScore() {
+	if m.Scores == nil || m.submitted || m.world.score == 0 {
+		return
+	}
+	m.submitted = true
+	k := m.scoreKey()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Score:       m.world.score,
+		Points:      m.world.score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	w := m.world
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.submitScore()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case w.over:
+		case key.Matches(msg, m.Keys.Pause):
+			m.paused = !m.paused
+			if m.running() {
+				return m, m.resume()
+			}
+		case m.paused:
+			// Turns wait until play goes on.
+		default:
+			for _, d := range []struct {
+				b   key.Binding
+				dir grid.Point
+			}{{m.Keys.Up, grid.Up}, {m.Keys.Down, grid.Down}, {m.Keys.Left, grid.Left}, {m.Keys.Right, grid.Right}} {
+				if !key.Matches(msg, d.b) {
+					continue
+				}
+				w.Turn(d.dir)
+				if !m.started {
+					m.started = true
+					return m, m.resume()
+				}
+			}
+		}
+	case tickMsg:
+		if int(msg) != m.run || !m.running() {
+			return m, nil
+		}
+		lives, eaten := w.lives, w.eaten
+		w.step()
+		switch {
+		case w.lives < lives:
+			m.Bell.Ring(bell.DEATH)
+		case w.eaten > eaten:
+			m.Bell.Ring(bell.EAT)
+		}
+		if w.over {
+			m.submitScore()
+			return m, nil
+		}
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+// ghostAt is the ghost drawn on p, the last of those there.
+func (m Model) ghostAt(p grid.Point) (int, bool) {
+	found, ok := 0, false
+	for i, g := range m.world.ghosts {
+		if g.at == p {
+			found, ok = i, true
+		}
+	}
+	return found, ok
+}
+
+// cellView draws a cell, two columns wide.
+func (m Model) cellView(p grid.Point) string {
+	w := m.world
+	if i, ok := m.ghostAt(p); ok {
+		g := w.ghosts[i]
+		switch {
+		case !g.scared:
+			return m.GhostStyle.Foreground(ghostColors[i]).Render("▟▙")
+		case w.Flashing() && w.fright%4 < 2:
+			return m.ScaredStyle.Foreground(lipgloss.Color("15")).Render("▟▙")
+		}
+		return m.ScaredStyle.Render("▟▙")
+	}
+	if p == w.player {
+		return m.PlayerStyle.Render("◖◗")
+	}
+	switch w.cells.At(p) {
+	case WALL:
+		return m.WallStyle.Render("██")
+	case DOOR:
+		return m.DoorStyle.Render("──")
+	case PELLET:
+		return m.PelletStyle.Render("· ")
+	case POWER:
+		return m.PelletStyle.Render("● ")
+	}
+	return "  "
+}
+
+func (m Model) mazeView() string {
+	w := m.world
+	var s strings.Builder
+	for y := 0; y < w.cells.Height(); y++ {
+		for x := 0; x < w.cells.Width(); x++ {
+			s.WriteString(m.cellView(grid.Point{X: x, Y: y}))
+		}
+		if y < w.cells.Height()-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) header() string {
+	w := m.world
+	return strings.Join([]string{
+		fmt.Sprintf("Level %d: %s", w.level+1, w.maze.Name),
+		m.ScoreStyle.Render(fmt.Sprintf("Score %d", w.score)),
+		fmt.Sprintf("Best %d", max(m.best, w.score)),
+		"Lives " + strings.Repeat("♥", w.lives),
+	}, " | ")
+}
+
+func (m Model) status() string {
+	w := m.world
+	switch {
+	case m.paused:
+		return fmt.Sprintf("Paused, '%s' to go on", m.Keys.Pause.Help().Key)
+	case !m.started:
+		return "Move to start"
+	case w.ready > 0:
+		return "Ready!"
+	case w.fright > 0:
+		return "The ghosts are scared, eat them!"
+	}
+	return fmt.Sprintf("%d pellets left", w.pellets)
+}
+
+func (m Model) overView() string {
+	w := m.world
+	lines := []string{"Game over", fmt.Sprintf("Score %d, on level %d", w.score, w.level+1)}
+	if w.score > m.best && m.best > 0 {
+		lines = append(lines, "New best!")
+	}
+	if len(m.top) > 0 {
+		var s strings.Builder
+		s.WriteString("Top scores\n")
+		for i, e := range m.top {
+			fmt.Fprintf(&s, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(s.String(), "\n"))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to play again", m.Keys.Restart.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	if m.world.over {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.overView(),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			m.mazeView(),
+			m.QuitStyle.Render(m.status()),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,127 @@
+package game
+
+import "github.com/debemdeboas/games.debem.dev/grid"
+
+// The ghosts, by the digit that places them in a maze, less one. Each
+// chases the player its own way.
+const (
+	CHASER   = iota // heads straight for the player
+	AMBUSHER        // for AHEAD cells in front of the player
+	FLANKER         // for the player's far side from the chaser
+	SHY             // chases from afar, and wanders off to its corner up close
+	GHOSTS
+)
+
+const (
+	AHEAD    = 4
+	SHYRANGE = 8 // cells, as the crow flies, under which the shy ghost turns away
+)
+
+type mode int
+
+const (
+	SCATTER mode = iota // every ghost heads for its corner
+	CHASE
+)
+
+// schedule is how long, in ticks, each scatter and chase lasts in turn,
+// from the start of a life. Ghosts chase for good after the last one.
+var schedule = []int{56, 160, 56, 160, 40}
+
+// modeAt is the mode t ticks into a life.
+func modeAt(t int) mode {
+	for i, d := range schedule {
+		if t < d {
+			return mode(i % 2)
+		}
+		t -= d
+	}
+	return CHASE
+}
+
+type ghost struct {
+	at, dir grid.Point
+	wait    int  // ticks left in the house before leaving it
+	leaving bool // on the way out of the house, through its door
+	scared  bool // frightened by a power pellet, and edible
+}
+
+// corner is where ghost i heads to scatter: one corner of the maze each.
+func (w *World) corner(i int) grid.Point {
+	right, bottom := w.cells.Width()-1, w.cells.Height()-1
+	return [GHOSTS]grid.Point{{X: right}, {}, {X: right, Y: bottom}, {Y: bottom}}[i]
+}
+
+// target is where ghost i heads for in mode md.
+func (w *World) target(i int, md mode) grid.Point {
+	g := w.ghosts[i]
+	if g.leaving {
+		return w.maze.exit
+	}
+	if md == SCATTER {
+		return w.corner(i)
+	}
+	switch i {
+	case AMBUSHER:
+		return w.player.Add(grid.Point{X: AHEAD * w.dir.X, Y: AHEAD * w.dir.Y})
+	case FLANKER:
+		pivot := w.player.Add(grid.Point{X: 2 * w.dir.X, Y: 2 * w.dir.Y})
+		chaser := w.ghosts[CHASER].at
+		return pivot.Add(pivot.Sub(chaser))
+	case SHY:
+		if dist2(g.at, w.player) < SHYRANGE*SHYRANGE {
+			return w.corner(i)
+		}
+	}
+	return w.player
+}
+
+func dist2(a, b grid.Point) int {
+	d := a.Sub(b)
+	return d.X*d.X + d.Y*d.Y
+}
+
+// turns are the directions ghosts weigh, in the order ties go.
+var turns = []grid.Point{grid.Up, grid.Left, grid.Down, grid.Right}
+
+// passable reports whether ghost g can step onto p.
+func (w *World) passable(g ghost, p grid.Point) bool {
+	if !w.cells.InBounds(p) {
+		return false
+	}
+	switch w.cells.At(p) {
+	case WALL:
+		return false
+	case DOOR:
+		return g.leaving
+	}
+	return true
+}
+
+// steer picks ghost i's next direction: the way closest to its target,
+// or a random one while it's scared. Ghosts only turn back when it's the
+// only way, or on their way out of the house.
+func (w *World) steer(i int, md mode) grid.Point {
+	g := w.ghosts[i]
+	back := grid.Point{X: -g.dir.X, Y: -g.dir.Y}
+	var ways []grid.Point
+	for _, d := range turns {
+		if (d != back || g.leaving) && w.passable(g, w.maze.step(g.at, d)) {
+			ways = append(ways, d)
+		}
+	}
+	switch {
+	case len(ways) == 0:
+		return back
+	case g.scared:
+		return ways[w.rng.Intn(len(ways))]
+	}
+	target := w.target(i, md)
+	best := ways[0]
+	for _, d := range ways[1:] {
+		if dist2(w.maze.step(g.at, d), target) < dist2(w.maze.step(g.at, best), target) {
+			best = d
+		}
+	}
+	return best
+}
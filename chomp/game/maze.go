@@ -0,0 +1,232 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+// Maze files draw a maze a row per line, a column per cell, up to MAXWIDTH
+// columns and MAXHEIGHT rows:
+//
+//	; Name of the maze
+//	#########
+//	#o..1..o#
+//	#.##-##.#
+//	#.#234#.#
+//	#...P...#
+//	#########
+//
+// # are walls and - is the door of the ghosts' house, which only ghosts
+// leaving it go through, upwards. Dots are pellets, o power pellets and spaces
+// empty floor. P is where the player starts and 1 to 4 where the ghosts
+// do, see ghosts. Rows open at both ends are tunnels to the other side.
+// Lines starting with ; are comments, the first naming the maze.
+//
+//go:embed mazes/*.txt
+var mazeFiles embed.FS
+
+const (
+	MAXWIDTH  = 30
+	MAXHEIGHT = 24
+)
+
+type cell byte
+
+const (
+	FLOOR  cell = ' '
+	WALL   cell = '#'
+	DOOR   cell = '-'
+	PELLET cell = '.'
+	POWER  cell = 'o'
+)
+
+type Maze struct {
+	Name   string
+	cells  *grid.Grid[cell]
+	player grid.Point
+	ghosts [GHOSTS]grid.Point
+	door   bool       // whether the ghosts have a house to leave
+	exit   grid.Point // the cell outside its door
+}
+
+var mazes = mustLoad(mazeFiles)
+
+// mustLoad reads the embedded mazes, in file name order.
+func mustLoad(fsys fs.FS) []Maze {
+	paths, err := fs.Glob(fsys, "mazes/*.txt")
+	if err != nil {
+		panic(fmt.Sprintf("chomp: mazes: %v", err))
+	}
+	var ms []Maze
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			panic(fmt.Sprintf("chomp: %s: %v", p, err))
+		}
+		m, err := parseMaze(data, strings.TrimSuffix(path.Base(p), ".txt"))
+		if err != nil {
+			panic(fmt.Sprintf("chomp: %s: %v", p, err))
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// parseMaze reads a maze file, named name unless a comment names it.
+func parseMaze(data []byte, name string) (Maze, error) {
+	m := Maze{Name: name}
+	named := false
+	var rows []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if comment, ok := strings.CutPrefix(line, ";"); ok {
+			if !named {
+				m.Name, named = strings.TrimSpace(comment), true
+			}
+			continue
+		}
+		if len(rows) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		rows = append(rows, line)
+	}
+	if err := sc.Err(); err != nil {
+		return m, err
+	}
+	// Blank lines at the end are no rows.
+	for len(rows) > 0 && strings.TrimSpace(rows[len(rows)-1]) == "" {
+		rows = rows[:len(rows)-1]
+	}
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r))
+	}
+	switch {
+	case len(rows) == 0:
+		return m, fmt.Errorf("no maze")
+	case width > MAXWIDTH:
+		return m, fmt.Errorf("%d columns, at most %d fit", width, MAXWIDTH)
+	case len(rows) > MAXHEIGHT:
+		return m, fmt.Errorf("%d rows, at most %d fit", len(rows), MAXHEIGHT)
+	}
+
+	m.cells = grid.New[cell](width, len(rows))
+	m.cells.Fill(FLOOR)
+	players, ghosts := 0, 0
+	for y, r := range rows {
+		for x, c := range r {
+			p := grid.Point{X: x, Y: y}
+			switch c := cell(c); c {
+			case DOOR:
+				if !m.door {
+					m.door, m.exit = true, p.Add(grid.Up)
+				}
+				m.cells.Set(p, c)
+			case FLOOR, WALL, PELLET, POWER:
+				m.cells.Set(p, c)
+			case 'P':
+				m.player = p
+				players++
+			case '1', '2', '3', '4':
+				m.ghosts[c-'1'] = p
+				ghosts |= 1 << (c - '1')
+			default:
+				return m, fmt.Errorf("row %d: unknown cell %q", y+1, c)
+			}
+		}
+	}
+	switch {
+	case players != 1:
+		return m, fmt.Errorf("%d player starts, want one P", players)
+	case ghosts != 1<<GHOSTS-1:
+		return m, fmt.Errorf("ghost starts missing, want each of 1 to %d once", GHOSTS)
+	case m.door && !m.open(m.exit):
+		return m, fmt.Errorf("row %d: the door opens onto a wall", m.exit.Y+2)
+	}
+
+	// A pellet out of the player's reach would leave the maze uncleared
+	// for good.
+	reach := m.reachable(m.player)
+	pellets := 0
+	var err error
+	m.cells.Each(func(p grid.Point, c cell) {
+		if c != PELLET && c != POWER {
+			return
+		}
+		pellets++
+		if !reach[p] && err == nil {
+			err = fmt.Errorf("row %d: pellet out of reach at column %d", p.Y+1, p.X+1)
+		}
+	})
+	switch {
+	case err != nil:
+		return m, err
+	case pellets == 0:
+		return m, fmt.Errorf("no pellets to eat")
+	}
+	return m, nil
+}
+
+// open reports whether the player can stand on p.
+func (m Maze) open(p grid.Point) bool {
+	return m.cells.InBounds(p) && m.cells.At(p) != WALL && m.cells.At(p) != DOOR
+}
+
+// step is where a move from p in dir lands, through tunnels at the sides.
+func (m Maze) step(p, dir grid.Point) grid.Point {
+	p = p.Add(dir)
+	w := m.cells.Width()
+	p.X = (p.X + w) % w
+	return p
+}
+
+// reachable is every cell the player can get to from start.
+func (m Maze) reachable(start grid.Point) map[grid.Point]bool {
+	seen := map[grid.Point]bool{start: true}
+	queue := []grid.Point{start}
+	for i := 0; i < len(queue); i++ {
+		for _, d := range grid.Dirs4 {
+			if n := m.step(queue[i], d); !seen[n] && m.open(n) {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return seen
+}
+
+// LoadDir adds every maze in dir, one .txt file each, after the built-in
+// ones, in file name order. Invalid mazes are skipped. Call it before
+// serving.
+func LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			log.Warn("Skipping chomp maze", "path", p, "err", err)
+			continue
+		}
+		m, err := parseMaze(data, strings.TrimSuffix(filepath.Base(p), ".txt"))
+		if err != nil {
+			log.Warn("Skipping chomp maze", "path", p, "err", err)
+			continue
+		}
+		mazes = append(mazes, m)
+		log.Info("Loaded chomp maze", "path", p, "name", m.Name)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	TICK    = 125 * time.Millisecond
+	LIVES   = 3
+	READY   = 16 // ticks everyone holds still for at the start of a life
+	RELEASE = 24 // ticks between ghosts leaving the house
+	RESPAWN = 16 // ticks an eaten ghost waits at home
+
+	FRIGHT     = 48 // ticks ghosts stay scared, on the first maze
+	MINFRIGHT  = 16 // however far the player gets
+	FRIGHTLESS = 8  // fewer ticks per maze cleared
+	FLASH      = 12 // ticks before the fright ends that scared ghosts flash
+
+	SLOWEVERY = 5 // ghosts rest one tick in SLOWEVERY, so the player can outrun them
+
+	PELLETPOINTS = 10
+	POWERPOINTS  = 50
+	GHOSTPOINTS  = 200 // doubling for each ghost eaten in one fright
+)
+
+// World is a game of Chomp: the maze of the current level, the pellets
+// left in it, the player and the ghosts. Time only moves in step, one
+// TICK at a time.
+type World struct {
+	level   int // mazes cleared
+	maze    Maze
+	cells   *grid.Grid[cell] // with the pellets left
+	pellets int
+
+	player grid.Point
+	dir    grid.Point // the player's heading, zero while they stand
+	want   grid.Point // the turn the player asked for, taken where it fits
+
+	ghosts [GHOSTS]ghost
+	life   int // ticks since the life started, for the schedule
+	ready  int
+	fright int // ticks left scared
+	eaten  int // ghosts eaten in this fright
+
+	score int
+	lives int
+	over  bool
+
+	rng *rand.Rand
+}
+
+func NewWorld(rng *rand.Rand) *World {
+	w := &World{lives: LIVES, rng: rng}
+	w.start(0)
+	return w
+}
+
+// start sets up level i in its maze, full of pellets.
+func (w *World) start(i int) {
+	w.level = i
+	w.maze = mazes[i%len(mazes)]
+	w.cells = w.maze.cells.Clone()
+	w.pellets = 0
+	w.cells.Each(func(_ grid.Point, c cell) {
+		if c == PELLET || c == POWER {
+			w.pellets++
+		}
+	})
+	w.respawn()
+}
+
+// respawn puts everyone back where the maze starts them, for a new life.
+func (w *World) respawn() {
+	w.player, w.dir, w.want = w.maze.player, grid.Point{}, grid.Point{}
+	for i := range w.ghosts {
+		w.ghosts[i] = ghost{at: w.maze.ghosts[i], dir: grid.Left}
+		w.home(i, i*RELEASE)
+	}
+	w.life, w.ready = 0, READY
+	w.fright, w.eaten = 0, 0
+}
+
+// home puts ghost i in its house to wait for wait ticks.
+func (w *World) home(i, wait int) {
+	g := &w.ghosts[i]
+	g.at, g.wait, g.scared = w.maze.ghosts[i], wait, false
+	g.leaving = w.maze.door && g.at != w.maze.exit
+}
+
+// Turn asks the player to head dir from the next cell that allows it.
+func (w *World) Turn(dir grid.Point) {
+	if w.over {
+		return
+	}
+	w.want = dir
+}
+
+// frightTicks is how long ghosts stay scared on this level.
+func (w *World) frightTicks() int {
+	return max(MINFRIGHT, FRIGHT-FRIGHTLESS*w.level)
+}
+
+// Flashing reports whether scared ghosts are about to recover.
+func (w *World) Flashing() bool {
+	return w.fright > 0 && w.fright <= FLASH
+}
+
+// step moves everything one tick.
+func (w *World) step() {
+	if w.over {
+		return
+	}
+	if w.ready > 0 {
+		w.ready--
+		return
+	}
+
+	md := modeAt(w.life)
+	w.life++
+	if modeAt(w.life) != md {
+		// Ghosts turn back as they change their minds.
+		for i := range w.ghosts {
+			w.ghosts[i].dir = grid.Point{X: -w.ghosts[i].dir.X, Y: -w.ghosts[i].dir.Y}
+		}
+	}
+	if w.fright > 0 {
+		w.fright--
+		if w.fright == 0 {
+			for i := range w.ghosts {
+				w.ghosts[i].scared = false
+			}
+		}
+	}
+
+	w.movePlayer()
+	if w.collide() {
+		return
+	}
+	w.moveGhosts(md)
+	w.collide()
+}
+
+func (w *World) movePlayer() {
+	if w.want != (grid.Point{}) && w.maze.open(w.maze.step(w.player, w.want)) {
+		w.dir = w.want
+	}
+	next := w.maze.step(w.player, w.dir)
+	if !w.maze.open(next) {
+		return
+	}
+	w.player = next
+	switch w.cells.At(next) {
+	case PELLET:
+		w.score += PELLETPOINTS
+	case POWER:
+		w.score += POWERPOINTS
+		w.scare()
+	default:
+		return
+	}
+	w.cells.Set(next, FLOOR)
+	w.pellets--
+	if w.pellets == 0 {
+		w.start(w.level + 1)
+	}
+}
+
+// scare frightens every ghost out of its house, turning them back.
+func (w *World) scare() {
+	w.fright, w.eaten = w.frightTicks(), 0
+	for i := range w.ghosts {
+		g := &w.ghosts[i]
+		if g.wait > 0 || g.leaving {
+			continue
+		}
+		g.scared = true
+		g.dir = grid.Point{X: -g.dir.X, Y: -g.dir.Y}
+	}
+}
+
+func (w *World) moveGhosts(md mode) {
+	for i := range w.ghosts {
+		g := &w.ghosts[i]
+		if g.wait > 0 {
+			g.wait--
+			continue
+		}
+		// Scared ghosts crawl at half speed, the others rest now and then.
+		if g.scared && w.life%2 == 0 || !g.scared && w.life%SLOWEVERY == 0 {
+			continue
+		}
+		g.dir = w.steer(i, md)
+		if next := w.maze.step(g.at, g.dir); w.passable(*g, next) {
+			g.at = next
+		}
+		if g.leaving && g.at == w.maze.exit {
+			g.leaving, g.dir = false, grid.Left
+		}
+	}
+}
+
+// collide settles the player meeting a ghost, which eats the one or the
+// other, and reports whether the player lost a life.
+func (w *World) collide() bool {
+	for i, g := range w.ghosts {
+		if g.at != w.player {
+			continue
+		}
+		if g.scared {
+			w.score += GHOSTPOINTS << w.eaten
+			w.eaten++
+			w.home(i, RESPAWN)
+			continue
+		}
+		w.lives--
+		if w.lives == 0 {
+			w.over = true
+		} else {
+			w.respawn()
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+// Package clock shows the server's schedule in each player's own time. The
+// schedule itself stays in UTC, so everyone shares the same daily puzzle
+// and event start; only how it's displayed changes.
+//
+// Players pick their timezone in the hub, and it's kept in their profile.
+// Until they do, it's read from the TZ variable their SSH client sends, e.g.
+// with `ssh -o SetEnv=TZ=Europe/Paris`.
+package clock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TZENV = "TZ"
+
+// Load finds the timezone a player typed: an IANA name like
+// "America/Sao_Paulo", "UTC", or an offset from UTC like "+02:00", "UTC-3"
+// or "-0530".
+func Load(name string) (*time.Location, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), ":")
+	if name == "" {
+		return nil, fmt.Errorf("no timezone given")
+	}
+	if loc, ok := offset(name); ok {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || loc == time.Local {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// offset parses a fixed offset from UTC, with or without a UTC or GMT
+// prefix.
+func offset(name string) (*time.Location, bool) {
+	s := strings.ToUpper(name)
+	for _, prefix := range []string{"UTC", "GMT"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	if s == "" || s[0] != '+' && s[0] != '-' {
+		return nil, false
+	}
+	sign, s := s[0], s[1:]
+
+	hours, minutes, colon := strings.Cut(s, ":")
+	if !colon && len(s) > 2 {
+		hours, minutes = s[:len(s)-2], s[len(s)-2:]
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h > 14 {
+		return nil, false
+	}
+	m := 0
+	if minutes != "" {
+		if m, err = strconv.Atoi(minutes); err != nil || m >= 60 {
+			return nil, false
+		}
+	}
+
+	secs := (h*60 + m) * 60
+	if sign == '-' {
+		secs = -secs
+	}
+	if secs == 0 {
+		return time.UTC, true
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%c%02d:%02d", sign, h, m), secs), true
+}
+
+// FromEnv reads the timezone from TZENV in a session's environment. It's
+// nil when the variable is unset or names no zone this server knows, as it
+// may if the client sent the POSIX form.
+func FromEnv(environ []string) *time.Location {
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == TZENV {
+			loc, err := Load(v)
+			if err != nil {
+				return nil
+			}
+			return loc
+		}
+	}
+	return nil
+}
+
+// For picks the timezone of a player: the one they chose, else the one
+// their client sent, else UTC.
+func For(chosen string, environ []string) *time.Location {
+	if chosen != "" {
+		if loc, err := Load(chosen); err == nil {
+			return loc
+		}
+	}
+	if loc := FromEnv(environ); loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Time renders the time of day of t in loc, with the zone so players know
+// it's been converted, e.g. "22:00 CEST". A nil loc is UTC.
+func Time(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("15:04 MST")
+}
+
+// At renders t in loc as Time does, with the weekday when it isn't today
+// there, e.g. "Tue 02:00 CEST".
+func At(t, now time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if t.In(loc).Format(time.DateOnly) == now.In(loc).Format(time.DateOnly) {
+		return Time(t, loc)
+	}
+	return t.In(loc).Format("Mon 15:04 MST")
+}
+
+// Until renders the wait from now until t, e.g. "3h05m" when it's an hour or
+// more and to the second, "12m30s", under that.
+func Until(t, now time.Time) string {
+	d := max(0, t.Sub(now))
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,208 @@
+// Package config loads the settings shared by every game server from a YAML
+// file, with environment variables taking precedence:
+//
+//	host: 0.0.0.0          # GAMES_HOST
+//	port: 23232            # GAMES_PORT
+//	host_key: host.key     # GAMES_HOST_KEY
+//	board:                 # board of games that have one, zero for their own
+//	  width: 26            # GAMES_BOARD_WIDTH
+//	  height: 34           # GAMES_BOARD_HEIGHT
+//	tick: 16ms             # GAMES_TICK, zero for the game's own
+//	log_level: debug       # GAMES_LOG_LEVEL
+//	data_dir: .            # GAMES_DATA_DIR, where databases are kept
+//	admins: [SHA256:...]   # GAMES_ADMINS, comma-separated key fingerprints
+//	observer_addr: :8080   # GAMES_OBSERVER_ADDR, HTTP API for tools, empty for none
+//	public_addr: games.debem.dev:22  # GAMES_PUBLIC_ADDR, how players reach the server
+//	web_url: https://games.debem.dev # GAMES_WEB_URL, the leaderboards on the web
+//	intro: art/intro.ans   # GAMES_INTRO, ANSI art shown on connect, empty for none
+//	outro: art/outro.ans   # GAMES_OUTRO, ANSI art shown on quit, empty for none
+//	news: news.md          # GAMES_NEWS, announcements for the lobby, empty for none
+//	feedback_webhook: https://...  # GAMES_FEEDBACK_WEBHOOK, where feedback is posted, empty to only store it
+//	tournaments:           # recurring tournaments, see Tournament
+//	  - name: Friday Snake Cup
+//	    game: snake
+//	    schedule: 0 20 * * 5 # cron-like, in UTC
+//	    daily_seed: true
+//
+// The file is read from GAMES_CONFIG, or config.yaml, and may be missing.
+// Command-line flags, see Flags, override both.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PATHENV     = "GAMES_CONFIG"
+	DEFAULTPATH = "config.yaml"
+)
+
+type Board struct {
+	Width  int `yaml:"width"`
+	Height int `yaml:"height"`
+}
+
+// Tournament schedules a recurring single-elimination cup of one game, see
+// package tournament. Sign-ups and rounds of zero take the defaults, and
+// the webhook, if set, is posted its announcements.
+type Tournament struct {
+	Name      string        `yaml:"name"`
+	Game      string        `yaml:"game"`
+	Schedule  string        `yaml:"schedule"`
+	Signup    time.Duration `yaml:"signup"`
+	Round     time.Duration `yaml:"round"`
+	DailySeed bool          `yaml:"daily_seed"`
+	Webhook   string        `yaml:"webhook"`
+}
+
+type Config struct {
+	Host     string        `yaml:"host"`
+	Port     string        `yaml:"port"`
+	HostKey  string        `yaml:"host_key"`
+	Board    Board         `yaml:"board"`
+	Tick     time.Duration `yaml:"tick"`
+	LogLevel string        `yaml:"log_level"`
+	DataDir  string        `yaml:"data_dir"`
+	Admins   []string      `yaml:"admins"`
+	// ObserverAddr serves the HTTP API: the event stream of package observe
+	// at /events, replay exports of package export at /replays and the
+	// leaderboard widgets of package widget at /widget.
+	ObserverAddr string `yaml:"observer_addr"`
+	// PublicAddr and WebURL are what players share with others, from the
+	// hub's QR codes. Either may be empty to share nothing of it.
+	PublicAddr string `yaml:"public_addr"`
+	WebURL     string `yaml:"web_url"`
+	// Intro and Outro are ANSI art files, see package art, shown as players
+	// connect and leave.
+	Intro string `yaml:"intro"`
+	Outro string `yaml:"outro"`
+	// News is the file of announcements shown in the lobby, see package
+	// news. It's read again as operators edit it.
+	News string `yaml:"news"`
+	// FeedbackWebhook gets the feedback players send from the lobby, as
+	// JSON, see package feedback. It's kept in the data directory either
+	// way.
+	FeedbackWebhook string `yaml:"feedback_webhook"`
+	// Tournaments run on their schedules while the server is up.
+	Tournaments []Tournament `yaml:"tournaments"`
+}
+
+func Default() Config {
+	return Config{
+		Host:       "0.0.0.0",
+		Port:       "23232",
+		HostKey:    "host.key",
+		LogLevel:   "debug",
+		DataDir:    ".",
+		PublicAddr: "games.debem.dev:22",
+		WebURL:     "https://games.debem.dev",
+	}
+}
+
+// Flags registers the command-line overrides of c on fs.
+func (c *Config) Flags(fs *flag.FlagSet) {
+	fs.Func("addr", "listen address as host:port (default "+net.JoinHostPort(c.Host, c.Port)+")", func(addr string) error {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+		c.Host, c.Port = host, port
+		return nil
+	})
+	fs.StringVar(&c.HostKey, "host-key", c.HostKey, "path of the SSH host key, generated if missing")
+	fs.StringVar(&c.LogLevel, "log-level", c.LogLevel, "one of debug, info, warn, error or fatal")
+	fs.StringVar(&c.DataDir, "data-dir", c.DataDir, "directory of the leaderboard and profile databases")
+	fs.StringVar(&c.ObserverAddr, "observer-addr", c.ObserverAddr, "address of the HTTP API for tools and widgets, empty for none")
+	fs.StringVar(&c.PublicAddr, "public-addr", c.PublicAddr, "host:port players connect to, shared from the hub")
+	fs.StringVar(&c.WebURL, "web-url", c.WebURL, "URL of the web leaderboards, shared from the hub")
+	fs.StringVar(&c.Intro, "intro", c.Intro, "ANSI art file shown on connect, empty for none")
+	fs.StringVar(&c.Outro, "outro", c.Outro, "ANSI art file shown on quit, empty for none")
+	fs.StringVar(&c.News, "news", c.News, "news file shown in the lobby, empty for none")
+	fs.StringVar(&c.FeedbackWebhook, "feedback-webhook", c.FeedbackWebhook, "URL player feedback is posted to, empty to only store it")
+}
+
+// Path resolves a data file inside DataDir.
+func (c Config) Path(name string) string {
+	return filepath.Join(c.DataDir, name)
+}
+
+// Load reads the config file over the defaults, then applies the
+// environment overrides.
+func Load() (Config, error) {
+	c := Default()
+	path := DEFAULTPATH
+	if p, ok := os.LookupEnv(PATHENV); ok {
+		path = p
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return c, err
+	default:
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return c, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := c.override(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func (c *Config) override() error {
+	for name, field := range map[string]*string{
+		"GAMES_HOST":             &c.Host,
+		"GAMES_PORT":             &c.Port,
+		"GAMES_HOST_KEY":         &c.HostKey,
+		"GAMES_LOG_LEVEL":        &c.LogLevel,
+		"GAMES_DATA_DIR":         &c.DataDir,
+		"GAMES_OBSERVER_ADDR":    &c.ObserverAddr,
+		"GAMES_PUBLIC_ADDR":      &c.PublicAddr,
+		"GAMES_WEB_URL":          &c.WebURL,
+		"GAMES_INTRO":            &c.Intro,
+		"GAMES_OUTRO":            &c.Outro,
+		"GAMES_NEWS":             &c.News,
+		"GAMES_FEEDBACK_WEBHOOK": &c.FeedbackWebhook,
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			*field = v
+		}
+	}
+	for name, field := range map[string]*int{
+		"GAMES_BOARD_WIDTH":  &c.Board.Width,
+		"GAMES_BOARD_HEIGHT": &c.Board.Height,
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*field = n
+		}
+	}
+	if v, ok := os.LookupEnv("GAMES_ADMINS"); ok {
+		c.Admins = strings.Split(v, ",")
+	}
+	if v, ok := os.LookupEnv("GAMES_TICK"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("GAMES_TICK: %w", err)
+		}
+		c.Tick = d
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	COLS = 7
+	ROWS = 6
+	RUN  = 4 // discs in a row that win
+)
+
+// Discs
+const (
+	EMPTY = iota
+	RED
+	YELLOW
+)
+
+// Board holds a disc per cell, row 0 at the top.
+type Board [ROWS][COLS]int
+
+// directions a line runs in from its first disc.
+var directions = []grid.Point{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: -1}}
+
+func other(disc int) int {
+	return RED + YELLOW - disc
+}
+
+func inBounds(p grid.Point) bool {
+	return p.X >= 0 && p.X < COLS && p.Y >= 0 && p.Y < ROWS
+}
+
+// Row is where a disc dropped in col lands, or -1 when the column is full.
+func (b *Board) Row(col int) int {
+	if col < 0 || col >= COLS {
+		return -1
+	}
+	for y := ROWS - 1; y >= 0; y-- {
+		if b[y][col] == EMPTY {
+			return y
+		}
+	}
+	return -1
+}
+
+func (b *Board) Full() bool {
+	for x := 0; x < COLS; x++ {
+		if b[0][x] == EMPTY {
+			return false
+		}
+	}
+	return true
+}
+
+// Winner is the disc with RUN in a row and the line, or EMPTY when nobody
+// has one.
+func (b *Board) Winner() (int, []grid.Point) {
+	for y := 0; y < ROWS; y++ {
+		for x := 0; x < COLS; x++ {
+			disc := b[y][x]
+			if disc == EMPTY {
+				continue
+			}
+			for _, d := range directions {
+				line := []grid.Point{{X: x, Y: y}}
+				for p := line[0].Add(d); inBounds(p) && b[p.Y][p.X] == disc && len(line) < RUN; p = p.Add(d) {
+					line = append(line, p)
+				}
+				if len(line) == RUN {
+					return disc, line
+				}
+			}
+		}
+	}
+	return EMPTY, nil
+}
+
+// WINSCORE rates a won board, above anything eval gives.
+const WINSCORE = 1_000_000
+
+// eval rates the board for disc without searching: every window of RUN
+// cells one side could still fill counts for it, the fuller the more, and
+// the center column a little since most lines go through it.
+func (b *Board) eval(disc int) int {
+	score := 0
+	for y := 0; y < ROWS; y++ {
+		switch b[y][COLS/2] {
+		case disc:
+			score += 3
+		case other(disc):
+			score -= 3
+		}
+	}
+	for y := 0; y < ROWS; y++ {
+		for x := 0; x < COLS; x++ {
+			for _, d := range directions {
+				end := grid.Point{X: x + d.X*(RUN-1), Y: y + d.Y*(RUN-1)}
+				if !inBounds(end) {
+					continue
+				}
+				mine, theirs := 0, 0
+				for i := 0; i < RUN; i++ {
+					switch b[y+d.Y*i][x+d.X*i] {
+					case disc:
+						mine++
+					case other(disc):
+						theirs++
+					}
+				}
+				score += window(mine, theirs) - window(theirs, mine)
+			}
+		}
+	}
+	return score
+}
+
+func window(mine, theirs int) int {
+	if theirs > 0 {
+		return 0
+	}
+	switch mine {
+	case RUN - 1:
+		return 5
+	case RUN - 2:
+		return 2
+	}
+	return 0
+}
+
+// order tries the center columns first, which prunes the search sooner.
+var order = []int{3, 2, 4, 1, 5, 0, 6}
+
+// search rates the board for disc to play next, looking depth moves ahead
+// by negamax with alpha-beta pruning. Sooner wins score higher.
+func (b *Board) search(disc, depth, alpha, beta int) int {
+	if w, _ := b.Winner(); w != EMPTY {
+		// The last move won, so it was the other disc's.
+		return -WINSCORE - depth
+	}
+	if b.Full() {
+		return 0
+	}
+	if depth == 0 {
+		return b.eval(disc)
+	}
+	for _, x := range order {
+		y := b.Row(x)
+		if y < 0 {
+			continue
+		}
+		b[y][x] = disc
+		s := -b.search(other(disc), depth-1, -beta, -alpha)
+		b[y][x] = EMPTY
+		if s > alpha {
+			alpha = s
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return alpha
+}
+
+// Best picks a column for disc looking depth moves ahead, at random between
+// columns that rate the same. It is -1 only on a full board.
+func (b Board) Best(disc, depth int, rng *rand.Rand) int {
+	var moves []int
+	best := 0
+	for _, x := range order {
+		y := b.Row(x)
+		if y < 0 {
+			continue
+		}
+		b[y][x] = disc
+		s := -b.search(other(disc), depth-1, -WINSCORE*2, WINSCORE*2)
+		b[y][x] = EMPTY
+		switch {
+		case len(moves) == 0 || s > best:
+			best, moves = s, []int{x}
+		case s == best:
+			moves = append(moves, x)
+		}
+	}
+	if len(moves) == 0 {
+		return -1
+	}
+	return moves[rng.Intn(len(moves))]
+}
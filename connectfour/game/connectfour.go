@@ -0,0 +1,334 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	POLL    = 40 * time.Millisecond // also the frame rate of falling discs
+	DROPROW = 45 * time.Millisecond // a falling disc takes to pass a row
+)
+
+// discNames draws the discs, filled and hollow so they tell apart without
+// colors too.
+var discNames = [...]string{EMPTY: "·", RED: "●", YELLOW: "○"}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles, one per disc
+	DiscStyles [3]lipgloss.Style
+	WinStyle   lipgloss.Style
+	BoardStyle lipgloss.Style
+	QuitStyle  lipgloss.Style
+	BoxStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room  string
+	match *Match
+	side  int
+	snap  Snapshot
+	col   int // the column the next disc goes in
+	level int // index in levels, for matches against the computer
+
+	// The last disc dropped falls from dropAt on; now is the last poll.
+	dropAt time.Time
+	now    time.Time
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:  width,
+		Height: height,
+		DiscStyles: [3]lipgloss.Style{
+			EMPTY:  r.NewStyle().Foreground(lipgloss.Color("238")),
+			RED:    r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+			YELLOW: r.NewStyle().Foreground(lipgloss.Color("11")).Bold(true),
+		},
+		WinStyle:   r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("10")).Bold(true),
+		BoardStyle: r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("27")),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		col:    COLS / 2,
+		level:  1,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = join(m.Room, m.Player, now)
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+// PlayBot gives up waiting and plays the computer at the level picked.
+func (m *Model) PlayBot() {
+	m.match.leave(m.side)
+	now := time.Now()
+	m.match, m.side = versusBot(m.Player, levels[m.level], now)
+	m.refresh(now)
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.match.poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	if snap.Moves > 0 && snap.Moves != m.snap.Moves {
+		m.dropAt = now
+	}
+	m.snap = snap
+	m.now = now
+}
+
+// falling is the row the last disc dropped is passing, while it falls.
+func (m Model) falling() (int, bool) {
+	if m.snap.Moves == 0 {
+		return 0, false
+	}
+	row := int(m.now.Sub(m.dropAt) / DROPROW)
+	return row, row < m.snap.Last.Y
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Bot):
+			if m.snap.Phase == WAITING || m.snap.Phase == OVER {
+				m.PlayBot()
+			}
+		case key.Matches(msg, m.Keys.Level):
+			if m.snap.Phase == WAITING || m.snap.Phase == OVER {
+				m.level = (m.level + 1) % len(levels)
+			}
+		case key.Matches(msg, m.Keys.Left):
+			m.col = max(0, m.col-1)
+		case key.Matches(msg, m.Keys.Right):
+			m.col = min(COLS-1, m.col+1)
+		case key.Matches(msg, m.Keys.Drop), key.Matches(msg, m.Keys.Down):
+			m.match.put(m.side, m.col)
+			m.refresh(time.Now())
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func (m Model) myTurn() bool {
+	return m.snap.Phase == PLAYING && m.snap.Players[m.side].Disc == m.snap.Turn
+}
+
+// pointerView marks the column the player is about to drop in, with their
+// disc, on their turn.
+func (m Model) pointerView() string {
+	var s strings.Builder
+	s.WriteString(" ")
+	for x := 0; x < COLS; x++ {
+		if x == m.col && m.myTurn() {
+			disc := m.snap.Players[m.side].Disc
+			s.WriteString(" " + m.DiscStyles[disc].Render(discNames[disc]) + " ")
+		} else {
+			s.WriteString("   ")
+		}
+	}
+	s.WriteString(" ")
+	return s.String()
+}
+
+func (m Model) boardView() string {
+	board := m.snap.Board
+	row, falling := m.falling()
+	if falling {
+		last := m.snap.Last
+		board[row][last.X], board[last.Y][last.X] = board[last.Y][last.X], EMPTY
+	}
+	var line []grid.Point
+	if !falling {
+		_, line = board.Winner()
+	}
+
+	var s strings.Builder
+	for y := 0; y < ROWS; y++ {
+		for x := 0; x < COLS; x++ {
+			disc := board[y][x]
+			style := m.DiscStyles[disc]
+			if slices.Contains(line, grid.Point{X: x, Y: y}) {
+				style = m.WinStyle
+			}
+			s.WriteString(" " + style.Render(discNames[disc]) + " ")
+		}
+		if y < ROWS-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Players {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		sides = append(sides, fmt.Sprintf("%s %s", m.DiscStyles[p.Disc].Render(discNames[p.Disc]), name))
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+// botHint offers a match against the computer at the level picked.
+func (m Model) botHint() string {
+	return fmt.Sprintf("Press '%s' to play the computer (%s, '%s' to change)",
+		m.Keys.Bot.Help().Key, levels[m.level].Name, m.Keys.Level.Help().Key)
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case WAITING:
+		return "Waiting for an opponent...\n\n" + m.botHint()
+	case PLAYING:
+		if m.myTurn() {
+			return "Your turn"
+		}
+		return fmt.Sprintf("%s to play...", m.snap.Players[1-m.side].Name)
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	var result string
+	switch m.snap.Winner {
+	case -1:
+		result = "Draw"
+	case m.side:
+		result = "You win!"
+		// A match that ends before the board does was forfeited.
+		if w, _ := m.snap.Board.Winner(); w == EMPTY {
+			result = m.snap.Players[1-m.side].Name + " left, you win!"
+		}
+	default:
+		result = m.snap.Players[m.snap.Winner].Name + " wins"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s\n\nPress '%s' for a new match\n%s",
+		result, m.Keys.Rematch.Help().Key, m.botHint()))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.snap.Phase == WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if _, falling := m.falling(); m.snap.Phase == OVER && !falling {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.pointerView(),
+			m.boardView(),
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
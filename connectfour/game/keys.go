@@ -0,0 +1,67 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Drop    key.Binding
+	Rematch key.Binding
+	Bot     key.Binding
+	Level   key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Drop:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "drop")),
+		Rematch:  key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "new match")),
+		Bot:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "play the computer")),
+		Level:    key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "difficulty")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Drop, k.Bot, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Drop, k.Rematch, k.Bot, k.Level},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":         &k.Up,
+		"down":       &k.Down,
+		"left":       &k.Left,
+		"right":      &k.Right,
+		"drop":       &k.Drop,
+		"rematch":    &k.Rematch,
+		"bot":        &k.Bot,
+		"difficulty": &k.Level,
+		"layout":     &k.Layout,
+		"help":       &k.Help,
+		"quit":       &k.Quit,
+	}
+}
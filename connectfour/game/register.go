@@ -0,0 +1,41 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "connectfour"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Connect Four",
+	Description: "Four in a row against the computer at three levels, or another player",
+	Category:    games.BOARD,
+	MinPlayers:  1,
+	MaxPlayers:  2,
+	Rooms:       true,
+	Session:     3 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		if env.Room != "" {
+			m.SetRoom(env.Room)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,460 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const DAILYRANKS = 5 // completions listed when the daily puzzle is solved
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	CellStyle     lipgloss.Style
+	WordStyle     lipgloss.Style
+	CursorStyle   lipgloss.Style
+	BlockStyle    lipgloss.Style
+	WrongStyle    lipgloss.Style
+	RevealedStyle lipgloss.Style
+	QuitStyle     lipgloss.Style
+	WinStyle      lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Daily puzzle: day is set while playing it. Completion times go to
+	// Daily under the player's name and key, unless they revealed a word.
+	Daily       daily.Store
+	Player      string
+	Fingerprint string
+	Friends     []string
+	Location    *time.Location // the player's, for when the next puzzle is out
+	day         string
+	streak      int
+	ranking     []daily.Result
+	friends     []daily.Result
+
+	// Puzzle state
+	index    int // into puzzles
+	puzzle   Puzzle
+	fill     [SIZE][SIZE]rune
+	wrong    [SIZE][SIZE]bool
+	revealed [SIZE][SIZE]bool
+	x, y     int
+	across   bool
+	started  time.Time
+	elapsed  time.Duration
+	solved   bool
+	showHelp bool
+
+	ctx context.Context
+}
+
+type clockMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:         width,
+		Height:        height,
+		CellStyle:     r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("255")),
+		WordStyle:     r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("153")),
+		CursorStyle:   r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220")),
+		BlockStyle:    r.NewStyle().Background(lipgloss.Color("236")),
+		WrongStyle:    r.NewStyle().Foreground(lipgloss.Color("160")),
+		RevealedStyle: r.NewStyle().Foreground(lipgloss.Color("27")),
+		QuitStyle:     r.NewStyle().Foreground(lipgloss.Color("8")),
+		WinStyle: r.NewStyle().
+			Foreground(lipgloss.Color("10")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Start(0)
+	return m
+}
+
+// SetContext binds the clock to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.clock()
+}
+
+func (m Model) clock() tea.Cmd {
+	return ui.Every(m.ctx, time.Second, func(t time.Time) tea.Msg {
+		return clockMsg(t)
+	})
+}
+
+// Start plays the i-th puzzle across every pack.
+func (m *Model) Start(i int) {
+	m.index = (i + len(puzzles)) % len(puzzles)
+	m.puzzle = puzzles[m.index]
+	m.fill = [SIZE][SIZE]rune{}
+	m.wrong = [SIZE][SIZE]bool{}
+	m.revealed = [SIZE][SIZE]bool{}
+	m.solved = false
+	m.started = time.Now()
+	m.elapsed = 0
+	m.goTo(m.puzzle.entries[0])
+}
+
+// StartDaily plays today's shared puzzle.
+func (m *Model) StartDaily() {
+	m.day = daily.Today(time.Now())
+	m.Start(int(daily.Seed(GAMENAME, m.day) % uint64(len(puzzles))))
+}
+
+func (m *Model) goTo(e entry) {
+	m.across = e.Across
+	m.x, m.y = e.X, e.Y
+	for _, c := range e.cells() {
+		if m.fill[c[1]][c[0]] == 0 {
+			m.x, m.y = c[0], c[1]
+			return
+		}
+	}
+}
+
+// current is the word under the cursor in the current direction, falling
+// back to the other direction for cells that only belong to one word.
+func (m Model) current() (int, entry) {
+	for _, across := range []bool{m.across, !m.across} {
+		for i, e := range m.puzzle.entries {
+			if e.Across != across {
+				continue
+			}
+			for _, c := range e.cells() {
+				if c == [2]int{m.x, m.y} {
+					return i, e
+				}
+			}
+		}
+	}
+	return 0, m.puzzle.entries[0]
+}
+
+// move steps the cursor over open cells, turning to the arrow's direction.
+func (m *Model) move(dx, dy int) {
+	m.across = dx != 0
+	for x, y := m.x+dx, m.y+dy; x >= 0 && y >= 0 && x < SIZE && y < SIZE; x, y = x+dx, y+dy {
+		if m.puzzle.open(x, y) {
+			m.x, m.y = x, y
+			return
+		}
+	}
+}
+
+// step moves along the current word, staying put at its ends.
+func (m *Model) step(delta int) {
+	_, e := m.current()
+	cells := e.cells()
+	for i, c := range cells {
+		if c == [2]int{m.x, m.y} {
+			if j := i + delta; j >= 0 && j < len(cells) {
+				m.x, m.y = cells[j][0], cells[j][1]
+			}
+			return
+		}
+	}
+}
+
+func (m *Model) nextClue(delta int) {
+	i, _ := m.current()
+	n := len(m.puzzle.entries)
+	m.goTo(m.puzzle.entries[(i+delta+n)%n])
+}
+
+func (m *Model) write(r rune) {
+	m.fill[m.y][m.x] = unicode.ToUpper(r)
+	m.wrong[m.y][m.x] = false
+	m.step(1)
+	m.checkSolved()
+}
+
+func (m *Model) erase() {
+	if m.fill[m.y][m.x] == 0 {
+		m.step(-1)
+	}
+	m.fill[m.y][m.x] = 0
+	m.wrong[m.y][m.x] = false
+}
+
+// check marks every filled cell that doesn't match the solution.
+func (m *Model) check() {
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			m.wrong[y][x] = m.fill[y][x] != 0 && m.fill[y][x] != rune(m.puzzle.Grid[y][x])
+		}
+	}
+}
+
+// reveal fills the current word with its solution.
+func (m *Model) reveal() {
+	_, e := m.current()
+	for _, c := range e.cells() {
+		x, y := c[0], c[1]
+		if m.fill[y][x] != rune(m.puzzle.Grid[y][x]) {
+			m.fill[y][x] = rune(m.puzzle.Grid[y][x])
+			m.revealed[y][x] = true
+		}
+		m.wrong[y][x] = false
+	}
+	m.checkSolved()
+}
+
+func (m Model) usedReveal() bool {
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			if m.revealed[y][x] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *Model) checkSolved() {
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			if m.puzzle.open(x, y) && m.fill[y][x] != rune(m.puzzle.Grid[y][x]) {
+				return
+			}
+		}
+	}
+	m.solved = true
+	m.elapsed = time.Since(m.started)
+	if m.day != "" && !m.usedReveal() {
+		m.completeDaily()
+	}
+}
+
+// completeDaily records today's time and loads the rankings shown with it.
+func (m *Model) completeDaily() {
+	if m.Daily == nil {
+		return
+	}
+	err := m.Daily.Complete(daily.Result{
+		Game:        GAMENAME,
+		Day:         m.day,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Time:        m.elapsed,
+	})
+	if err != nil {
+		log.Warn("Could not record daily crossword", "err", err)
+	}
+	m.ranking = m.Daily.Results(GAMENAME, m.day)
+	m.friends = daily.Friends(m.ranking, m.Friends)
+	m.streak = daily.Streak(m.Daily.Days(GAMENAME, m.Fingerprint), m.day)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Next):
+			m.day = ""
+			m.Start(m.index + 1)
+		case key.Matches(msg, m.Keys.Daily):
+			m.StartDaily()
+		case m.solved:
+		case key.Matches(msg, m.Keys.Up):
+			m.move(0, -1)
+		case key.Matches(msg, m.Keys.Down):
+			m.move(0, 1)
+		case key.Matches(msg, m.Keys.Left):
+			m.move(-1, 0)
+		case key.Matches(msg, m.Keys.Right):
+			m.move(1, 0)
+		case key.Matches(msg, m.Keys.NextClue):
+			m.nextClue(1)
+		case key.Matches(msg, m.Keys.PrevClue):
+			m.nextClue(-1)
+		case key.Matches(msg, m.Keys.Direction):
+			m.across = !m.across
+		case key.Matches(msg, m.Keys.Erase):
+			m.erase()
+		case key.Matches(msg, m.Keys.Check):
+			m.check()
+		case key.Matches(msg, m.Keys.Reveal):
+			m.reveal()
+		case msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && unicode.IsLetter(msg.Runes[0]):
+			m.write(msg.Runes[0])
+		}
+	case clockMsg:
+		return m, m.clock()
+	}
+	return m, nil
+}
+
+func (m Model) gridView() string {
+	numbers := map[[2]int]int{}
+	for _, e := range m.puzzle.entries {
+		numbers[[2]int{e.X, e.Y}] = e.Number
+	}
+	word := map[[2]int]bool{}
+	_, cur := m.current()
+	for _, c := range cur.cells() {
+		word[c] = true
+	}
+
+	var s strings.Builder
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			if !m.puzzle.open(x, y) {
+				s.WriteString(m.BlockStyle.Render("    "))
+				continue
+			}
+
+			style := m.CellStyle
+			switch {
+			case x == m.x && y == m.y && !m.solved:
+				style = m.CursorStyle
+			case word[[2]int{x, y}] && !m.solved:
+				style = m.WordStyle
+			}
+
+			number := "  "
+			if n, ok := numbers[[2]int{x, y}]; ok {
+				number = fmt.Sprintf("%-2d", n)
+			}
+			letter := " "
+			if r := m.fill[y][x]; r != 0 {
+				letter = string(r)
+			}
+			switch {
+			case m.wrong[y][x]:
+				letter = style.Inherit(m.WrongStyle).Render(letter)
+			case m.revealed[y][x]:
+				letter = style.Inherit(m.RevealedStyle).Render(letter)
+			default:
+				letter = style.Render(letter)
+			}
+			s.WriteString(style.Faint(true).Render(number) + letter + style.Render(" "))
+		}
+		if y < SIZE-1 {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+func (m Model) cluesView() string {
+	_, cur := m.current()
+	var across, down strings.Builder
+	across.WriteString("Across\n")
+	down.WriteString("Down\n")
+	for _, e := range m.puzzle.entries {
+		line := fmt.Sprintf("%2d %s", e.Number, e.Clue)
+		if e == cur && !m.solved {
+			line = m.WordStyle.Render(line)
+		}
+		if e.Across {
+			across.WriteString(line + "\n")
+		} else {
+			down.WriteString(line + "\n")
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, across.String(), down.String())
+}
+
+func (m Model) solvedView() string {
+	lines := []string{"Solved!", fmt.Sprintf("in %s", m.elapsed.Truncate(time.Second))}
+	if m.day != "" && m.usedReveal() {
+		lines = append(lines, "Revealed words don't count for the daily ranking")
+	}
+	if m.day != "" && m.Daily != nil && !m.usedReveal() {
+		var s strings.Builder
+		if m.Fingerprint != "" {
+			fmt.Fprintf(&s, "Streak: %d day(s)\n", m.streak)
+		}
+		list := func(title string, results []daily.Result) {
+			fmt.Fprintf(&s, "\n%s\n", title)
+			for i, r := range results[:min(DAILYRANKS, len(results))] {
+				fmt.Fprintf(&s, "%d. %-12s %8s\n", i+1, r.Player, r.Time.Truncate(time.Second))
+			}
+		}
+		list(fmt.Sprintf("Today's fastest (%d solved)", len(m.ranking)), m.ranking)
+		if len(m.friends) > 0 {
+			list("Friends", m.friends)
+		}
+		lines = append(lines, "", strings.TrimRight(s.String(), "\n"))
+	}
+	if m.day != "" {
+		now := time.Now()
+		next := daily.Next(now)
+		lines = append(lines, "", fmt.Sprintf("Next daily puzzle at %s, in %s", clock.At(next, now, m.Location), clock.Until(next, now)))
+	}
+	lines = append(lines, "", fmt.Sprintf("'%s' next puzzle • '%s' daily puzzle", m.Keys.Next.Help().Key, m.Keys.Daily.Help().Key))
+	return m.WinStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.WinStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
+	title := fmt.Sprintf("%s #%d", m.puzzle.pack, m.index+1)
+	if m.day != "" {
+		title = "Daily mini " + m.day
+	}
+	elapsed := time.Since(m.started).Truncate(time.Second)
+	if m.solved {
+		elapsed = m.elapsed.Truncate(time.Second)
+	}
+
+	_, cur := m.current()
+	dir := "Down"
+	if cur.Across {
+		dir = "Across"
+	}
+	clue := fmt.Sprintf("%d %s: %s", cur.Number, dir, cur.Clue)
+	if m.solved {
+		clue = m.solvedView()
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			fmt.Sprintf("%s | Time: %s", title, elapsed),
+			"",
+			lipgloss.JoinHorizontal(lipgloss.Top, m.gridView(), "   ", m.cluesView()),
+			clue,
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// KeyMap moves with the arrows only and keeps its commands off the letters,
+// which fill the grid.
+type KeyMap struct {
+	ui.MoveKeys
+	NextClue  key.Binding
+	PrevClue  key.Binding
+	Direction key.Binding
+	Erase     key.Binding
+	Check     key.Binding
+	Reveal    key.Binding
+	Next      key.Binding
+	Daily     key.Binding
+	Help      key.Binding
+	Quit      key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		MoveKeys: ui.MoveKeys{
+			Up:    key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "up")),
+			Down:  key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "down")),
+			Left:  key.NewBinding(key.WithKeys("left"), key.WithHelp("←", "left")),
+			Right: key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "right")),
+		},
+		NextClue:  key.NewBinding(key.WithKeys("tab", "enter", ui.KEYPADENTER), key.WithHelp("tab", "next clue")),
+		PrevClue:  key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous clue")),
+		Direction: key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "across/down")),
+		Erase:     key.NewBinding(key.WithKeys("backspace", "delete"), key.WithHelp("backspace", "erase")),
+		Check:     key.NewBinding(key.WithKeys("ctrl+k"), key.WithHelp("ctrl+k", "check")),
+		Reveal:    key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "reveal word")),
+		Next:      key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "next puzzle")),
+		Daily:     key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "daily puzzle")),
+		Help:      ui.HelpKey(),
+		Quit:      key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "quit")),
+	}
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.NextClue, k.Direction, k.Check, k.Reveal, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.NextClue, k.PrevClue, k.Direction, k.Erase},
+		{k.Check, k.Reveal, k.Next, k.Daily},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":        &k.Up,
+		"down":      &k.Down,
+		"left":      &k.Left,
+		"right":     &k.Right,
+		"next-clue": &k.NextClue,
+		"prev-clue": &k.PrevClue,
+		"direction": &k.Direction,
+		"erase":     &k.Erase,
+		"check":     &k.Check,
+		"reveal":    &k.Reveal,
+		"next":      &k.Next,
+		"daily":     &k.Daily,
+		"help":      &k.Help,
+		"quit":      &k.Quit,
+	}
+}
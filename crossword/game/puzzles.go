@@ -0,0 +1,133 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	SIZE  = 5
+	BLOCK = '#' // a black square in a grid
+)
+
+//go:embed puzzles.json
+var packSet []byte
+
+type Pack struct {
+	Name    string   `json:"name"`
+	Puzzles []Puzzle `json:"puzzles"`
+}
+
+// Puzzle is a solved grid, row by row, and its clues in numbering order.
+type Puzzle struct {
+	ID     string   `json:"id"`
+	Grid   []string `json:"grid"`
+	Across []string `json:"across"`
+	Down   []string `json:"down"`
+
+	pack    string
+	entries []entry // across first, then down
+}
+
+// entry is a numbered word of the grid.
+type entry struct {
+	Number int
+	Across bool
+	X, Y   int
+	Len    int
+	Clue   string
+}
+
+func (e entry) cells() [][2]int {
+	cells := make([][2]int, e.Len)
+	for i := range cells {
+		cells[i] = [2]int{e.X, e.Y + i}
+		if e.Across {
+			cells[i] = [2]int{e.X + i, e.Y}
+		}
+	}
+	return cells
+}
+
+var puzzles = mustLoad(packSet)
+
+// mustLoad flattens the embedded packs into one list, numbering every grid.
+func mustLoad(data []byte) []Puzzle {
+	var packs []Pack
+	if err := json.Unmarshal(data, &packs); err != nil {
+		panic(fmt.Sprintf("crossword: puzzle packs: %v", err))
+	}
+	var ps []Puzzle
+	for _, pack := range packs {
+		for _, p := range pack.Puzzles {
+			p.pack = pack.Name
+			if err := p.number(); err != nil {
+				panic(fmt.Sprintf("crossword: puzzle %s: %v", p.ID, err))
+			}
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+func (p Puzzle) open(x, y int) bool {
+	return x >= 0 && y >= 0 && x < SIZE && y < SIZE && p.Grid[y][x] != BLOCK
+}
+
+// number finds the words of the grid the standard way: a cell gets the next
+// number if it starts a word across or down, and words are at least two
+// letters long.
+func (p *Puzzle) number() error {
+	if len(p.Grid) != SIZE {
+		return fmt.Errorf("want %d rows", SIZE)
+	}
+	for _, row := range p.Grid {
+		if len(row) != SIZE {
+			return fmt.Errorf("want %d columns", SIZE)
+		}
+	}
+
+	var across, down []entry
+	n := 0
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			if !p.open(x, y) {
+				continue
+			}
+			startsAcross := !p.open(x-1, y) && p.open(x+1, y)
+			startsDown := !p.open(x, y-1) && p.open(x, y+1)
+			if !startsAcross && !startsDown {
+				continue
+			}
+			n++
+			if startsAcross {
+				e := entry{Number: n, Across: true, X: x, Y: y}
+				for p.open(x+e.Len, y) {
+					e.Len++
+				}
+				across = append(across, e)
+			}
+			if startsDown {
+				e := entry{Number: n, X: x, Y: y}
+				for p.open(x, y+e.Len) {
+					e.Len++
+				}
+				down = append(down, e)
+			}
+		}
+	}
+
+	if len(across) != len(p.Across) || len(down) != len(p.Down) {
+		return fmt.Errorf("grid has %d across and %d down words but %d and %d clues",
+			len(across), len(down), len(p.Across), len(p.Down))
+	}
+	for i := range across {
+		across[i].Clue = p.Across[i]
+	}
+	for i := range down {
+		down[i].Clue = p.Down[i]
+	}
+	p.entries = append(across, down...)
+	return nil
+}
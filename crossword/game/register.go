@@ -0,0 +1,46 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/games"
+)
+
+const GAMENAME = "crossword"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Mini Crossword",
+	Description: "Fill a 5x5 grid from its clues",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     5 * time.Minute,
+	Next: func(now time.Time) (string, time.Time) {
+		return "new daily", daily.Next(now)
+	},
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.Daily = env.Daily
+		m.Player = env.Player
+		m.Fingerprint = env.Fingerprint
+		m.Location = env.Location
+		if env.Profile != nil {
+			m.Friends = env.Profile.Friends
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
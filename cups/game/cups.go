@@ -0,0 +1,472 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/tournament"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	POLL     = time.Second
+	ENTRANTS = 12 // listed before the rest are counted
+)
+
+// roundMsg carries a message produced by the round's game, tagged with the
+// run that issued it so stragglers from an earlier round are dropped.
+type roundMsg struct {
+	run int
+	msg tea.Msg
+}
+
+// roundExitMsg replaces the round's tea.Quit: the player sits the rest of
+// the round out.
+type roundExitMsg struct {
+	run int
+}
+
+type pollMsg time.Time
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TitleStyle lipgloss.Style
+	YouStyle   lipgloss.Style
+	QuitStyle  lipgloss.Style
+	BoxStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	env      games.Env // rounds' games start with
+	calendar *tournament.Calendar
+	cups     []*tournament.Cup
+	snaps    []tournament.Snapshot
+	lasts    []tournament.Snapshot // of the cup before each, Name empty for none
+	ids      map[*tournament.Cup]int
+	cursor   int
+
+	cup      *tournament.Cup // whose round is being played
+	game     tea.Model
+	launched int // round of cup last started, -1 for none
+	run      int
+	cancel   context.CancelFunc
+	err      error
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+// NewModel follows the cups of calendar, starting the game of each round
+// the player is drawn in with env.
+func NewModel(env games.Env, calendar *tournament.Calendar) *Model {
+	r := env.Renderer
+	m := &Model{
+		Width:      env.Width,
+		Height:     env.Height,
+		TitleStyle: r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		YouStyle:   r.NewStyle().Bold(true).Foreground(lipgloss.Color("10")),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:     DefaultKeyMap(),
+		env:      env,
+		calendar: calendar,
+		ids:      map[*tournament.Cup]int{},
+		launched: -1,
+		ctx:      context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	return m
+}
+
+// SetContext binds polling, and the rounds' games, to ctx.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.refresh(time.Now()), m.poll())
+}
+
+func (m *Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// refresh follows the calendar to the current cups, and starts or stops the
+// round's game as the player's matches come and go.
+func (m *Model) refresh(now time.Time) tea.Cmd {
+	next, last := m.calendar.Current(now)
+	ids := make(map[*tournament.Cup]int, len(next))
+	m.cups, m.snaps, m.lasts = next, nil, nil
+	playing := -1
+	for i, cup := range next {
+		id := m.ids[cup]
+		if id == 0 {
+			id = cup.Entry(m.env.Fingerprint)
+		}
+		ids[cup] = id
+		s := cup.Poll(id, now)
+		m.snaps = append(m.snaps, s)
+		if s.Playing && playing < 0 {
+			playing = i
+		}
+		var l tournament.Snapshot
+		if last[i] != nil {
+			l = last[i].Poll(last[i].Entry(m.env.Fingerprint), now)
+		}
+		m.lasts = append(m.lasts, l)
+	}
+	m.ids = ids
+	m.cursor = max(0, min(m.cursor, len(m.cups)-1))
+
+	if m.cup != nil {
+		i := m.index(m.cup)
+		if i < 0 || !m.snaps[i].Playing || m.snaps[i].Round != m.launched {
+			m.stop()
+			m.cup, m.launched = nil, -1
+		}
+	}
+	if m.cup == nil && playing >= 0 {
+		return m.launch(playing)
+	}
+	return nil
+}
+
+func (m *Model) index(cup *tournament.Cup) int {
+	for i, c := range m.cups {
+		if c == cup {
+			return i
+		}
+	}
+	return -1
+}
+
+// launch starts the game of cup i's round in its own context, its runs
+// recorded for the cup. It gets a line less than the screen for the cup's
+// status. Players who leave the round early aren't started again until the
+// next.
+func (m *Model) launch(i int) tea.Cmd {
+	m.stop()
+	cup, s := m.cups[i], m.snaps[i]
+	m.cup, m.launched = cup, s.Round
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	env := m.env
+	env.Ctx = ctx
+	env.Width, env.Height = m.Width, m.Height-1
+	env.Scores = scores{Store: m.env.Scores, cup: cup, id: m.ids[cup]}
+	env.Seed = cup.Seed()
+	env.Room = ""
+
+	game, err := games.New(s.Game, env)
+	if err != nil {
+		cancel()
+		m.err = err
+		return nil
+	}
+	m.err = nil
+	m.run++
+	m.game = game
+	m.cancel = cancel
+	return tag(m.run, game.Init())
+}
+
+func (m *Model) stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.game = nil
+	m.cancel = nil
+}
+
+func (m *Model) forward(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+	return tag(m.run, cmd)
+}
+
+const teaPackage = "github.com/charmbracelet/bubbletea"
+
+// tag routes a command's result back to the round's game that issued it,
+// turning its tea.Quit into leaving the round, like the hub does for games.
+func tag(run int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return roundExitMsg{run: run}
+		case tea.BatchMsg:
+			batch := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				batch[i] = tag(run, c)
+			}
+			return batch
+		}
+		if t := reflect.TypeOf(msg); t.PkgPath() == teaPackage || t.Kind() == reflect.Pointer && t.Elem().PkgPath() == teaPackage {
+			return msg
+		}
+		return roundMsg{run: run, msg: msg}
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case roundMsg:
+		if msg.run != m.run || m.game == nil {
+			return m, nil
+		}
+		return m, m.forward(msg.msg)
+	case roundExitMsg:
+		if msg.run == m.run {
+			m.stop()
+		}
+		return m, nil
+	case pollMsg:
+		return m, tea.Batch(m.refresh(time.Time(msg)), m.poll())
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		if m.game != nil {
+			return m, m.forward(tea.WindowSizeMsg{Width: msg.Width, Height: msg.Height - 1})
+		}
+		return m, nil
+	}
+
+	if m.game != nil {
+		return m, m.forward(msg)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.stop()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Up):
+			m.cursor = max(0, m.cursor-1)
+		case key.Matches(msg, m.Keys.Down):
+			m.cursor = max(0, min(m.cursor+1, len(m.cups)-1))
+		case key.Matches(msg, m.Keys.Enter):
+			if m.cursor < len(m.cups) {
+				cup := m.cups[m.cursor]
+				if m.ids[cup] == 0 {
+					m.ids[cup] = cup.Enter(m.env.Player, m.env.Fingerprint, time.Now())
+				}
+			}
+			return m, m.refresh(time.Now())
+		}
+	}
+	return m, nil
+}
+
+// countdown renders the time left until t, to the second.
+func countdown(t time.Time) string {
+	return max(0, time.Until(t)).Round(time.Second).String()
+}
+
+// match is the player's match in s's current round, if they have one.
+func match(s tournament.Snapshot) (tournament.Match, bool) {
+	if s.Round >= len(s.Bracket) {
+		return tournament.Match{}, false
+	}
+	for _, mt := range s.Bracket[s.Round] {
+		if mt.You {
+			return mt, true
+		}
+	}
+	return tournament.Match{}, false
+}
+
+// status is the cup's line under the round's game.
+func (m Model) status() string {
+	s := m.snaps[m.index(m.cup)]
+	mt, _ := match(s)
+	return m.QuitStyle.Render(fmt.Sprintf("%s round %d/%d, %s %d - %d %s, %s left. Best finished run counts.",
+		s.Name, s.Round+1, s.Rounds, mt.A, mt.ScoreA, mt.ScoreB, mt.B, countdown(s.Until)))
+}
+
+func (m Model) roundView(s tournament.Snapshot) string {
+	var lines []string
+	for _, mt := range s.Bracket[s.Round] {
+		line := fmt.Sprintf("%-12s %6d - %-6d %s", mt.A, mt.ScoreA, mt.ScoreB, mt.B)
+		if mt.Bye {
+			line = fmt.Sprintf("%-12s goes through, bye", mt.A)
+		}
+		if mt.You {
+			line = m.YouStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) entrantsView(s tournament.Snapshot) string {
+	if len(s.Entrants) == 0 {
+		return m.QuitStyle.Render("Nobody signed up yet")
+	}
+	names := strings.Join(s.Entrants[:min(len(s.Entrants), ENTRANTS)], ", ")
+	if more := len(s.Entrants) - ENTRANTS; more > 0 {
+		names += fmt.Sprintf(" and %d more", more)
+	}
+	return fmt.Sprintf("%d signed up: %s", len(s.Entrants), names)
+}
+
+func (m Model) lastView(l tournament.Snapshot) string {
+	switch {
+	case l.Name == "":
+		return ""
+	case l.Champion == "":
+		return m.QuitStyle.Render("Last time: too few signed up")
+	}
+	return m.QuitStyle.Render(fmt.Sprintf("Last time: %s won", l.Champion))
+}
+
+// cupView details the selected cup and sums the others up in a line.
+func (m Model) cupView(i int) string {
+	s := m.snaps[i]
+	now := time.Now()
+	head := s.Name
+	if g, ok := games.Lookup(s.Game); ok {
+		head += ", " + g.Title
+	}
+	if i != m.cursor {
+		return "  " + head
+	}
+	body := []string{m.TitleStyle.Render("> " + head)}
+	switch s.Phase {
+	case tournament.UPCOMING:
+		body = append(body,
+			fmt.Sprintf("Starts at %s, in %s", clock.At(s.Start, now, m.env.Location), countdown(s.Start)),
+			fmt.Sprintf("Sign-ups open in %s", countdown(s.Until)),
+		)
+	case tournament.OPEN:
+		body = append(body,
+			fmt.Sprintf("Starts at %s, in %s", clock.At(s.Start, now, m.env.Location), countdown(s.Start)),
+			m.entrantsView(s),
+		)
+		switch {
+		case s.Entered:
+			body = append(body, m.YouStyle.Render("You're in! Stay here for your first match."))
+		case m.env.Fingerprint == "":
+			body = append(body, m.QuitStyle.Render("Connect with a key to sign up"))
+		default:
+			body = append(body, fmt.Sprintf("Press '%s' to sign up", m.Keys.Enter.Help().Key))
+		}
+	case tournament.PLAYING:
+		body = append(body, fmt.Sprintf("Round %d/%d, %s left", s.Round+1, s.Rounds, countdown(s.Until)))
+		if mt, ok := match(s); ok && mt.Bye {
+			body = append(body, m.YouStyle.Render("You have a bye this round."))
+		} else if !s.Entered {
+			body = append(body, m.QuitStyle.Render("Sign-ups are closed, following along"))
+		}
+		body = append(body, "", m.roundView(s))
+	case tournament.INTERVAL:
+		body = append(body, fmt.Sprintf("Round %d/%d done, the next starts in %s", s.Round+1, s.Rounds, countdown(s.Until)), "", m.roundView(s))
+	}
+	if last := m.lastView(m.lasts[i]); last != "" {
+		body = append(body, last)
+	}
+	return strings.Join(body, "\n")
+}
+
+func (m Model) cupsView() string {
+	body := []string{m.TitleStyle.Render("Cups"), ""}
+	if len(m.cups) == 0 {
+		body = append(body, m.QuitStyle.Render("No cups are coming up"))
+	}
+	for i := range m.cups {
+		body = append(body, m.cupView(i))
+	}
+	if m.err != nil {
+		body = append(body, "", m.err.Error())
+	}
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, body...))
+}
+
+func (m Model) View() string {
+	if m.game != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, m.game.View(), m.status())
+	}
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.cupsView(),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
+
+// scores passes the round's runs on to the leaderboard, recording them for
+// the cup on the way.
+type scores struct {
+	leaderboard.Store // may be nil
+	cup               *tournament.Cup
+	id                int
+}
+
+func (s scores) Submit(en leaderboard.Entry) error {
+	s.cup.Record(s.id, en.Game, en.Points, time.Now())
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Submit(en)
+}
+
+func (s scores) Top(f leaderboard.Filter, n int) []leaderboard.Entry {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Top(f, n)
+}
+
+func (s scores) Best(f leaderboard.Filter, fingerprint string) (leaderboard.Entry, bool) {
+	if s.Store == nil {
+		return leaderboard.Entry{}, false
+	}
+	return s.Store.Best(f, fingerprint)
+}
+
+func (s scores) Keys() []leaderboard.Key {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Keys()
+}
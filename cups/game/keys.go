@@ -0,0 +1,51 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Enter key.Binding
+	Help  key.Binding
+	Quit  key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Enter:    key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "sign up")),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Enter, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Enter},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":    &k.Up,
+		"down":  &k.Down,
+		"left":  &k.Left,
+		"right": &k.Right,
+		"enter": &k.Enter,
+		"help":  &k.Help,
+		"quit":  &k.Quit,
+	}
+}
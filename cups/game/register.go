@@ -0,0 +1,49 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/tournament"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "cups"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Cups",
+	Description: "Scheduled knockout tournaments, one game a cup",
+	Category:    games.MULTIPLAYER,
+	MinPlayers:  1,
+	MaxPlayers:  100,
+	Spectating:  true,
+	Delay:       spectate.RANKEDDELAY,
+	Session:     15 * time.Minute,
+}
+
+// Register lists the cups of calendar in the lobby. Unlike the built-in
+// games it isn't registered on import: call it before serving, once the
+// configured tournaments are known.
+func Register(calendar *tournament.Calendar) {
+	i := info
+	i.Next = func(now time.Time) (string, time.Time) {
+		_, at := calendar.Next(now)
+		return "cup", at
+	}
+	games.Register(i, func(env games.Env) (games.Game, error) {
+		m := NewModel(env, calendar)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
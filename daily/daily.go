@@ -0,0 +1,138 @@
+// Package daily publishes one shared puzzle per game per day. Every player
+// gets the same puzzle, seeded from the game and the UTC date, and their
+// completions feed streaks and a daily ranking they can narrow to friends.
+package daily
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const DATEFORMAT = time.DateOnly
+
+// Today names the puzzle day t falls in. Days change at midnight UTC so
+// everyone shares the same puzzle.
+func Today(t time.Time) string {
+	return t.UTC().Format(DATEFORMAT)
+}
+
+// Next is when the puzzle day after the one t falls in starts.
+func Next(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// Seed derives the puzzle of game on day.
+func Seed(game, day string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(game + "/" + day))
+	return h.Sum64()
+}
+
+// Result is a player's completion of a daily puzzle.
+type Result struct {
+	Game        string
+	Day         string
+	Player      string
+	Fingerprint string // empty for anonymous players, who get no streak
+	Time        time.Duration
+	Moves       int
+}
+
+// Store records completions. Only a player's first completion of a day
+// counts.
+type Store interface {
+	Complete(r Result) error
+	// Results lists the completions of game on day, fastest first.
+	Results(game, day string) []Result
+	// Days lists the days the player completed game on.
+	Days(game, fingerprint string) []string
+}
+
+// Streak counts the consecutive days up to today the player completed. A
+// streak lives on through today until it's played.
+func Streak(days []string, today string) int {
+	done := make(map[string]bool, len(days))
+	for _, d := range days {
+		done[d] = true
+	}
+	day, err := time.Parse(DATEFORMAT, today)
+	if err != nil {
+		return 0
+	}
+	if !done[today] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	n := 0
+	for done[day.Format(DATEFORMAT)] {
+		n++
+		day = day.AddDate(0, 0, -1)
+	}
+	return n
+}
+
+// Friends keeps the results of the given players, in order.
+func Friends(results []Result, fingerprints []string) []Result {
+	var kept []Result
+	for _, r := range results {
+		if r.Fingerprint != "" && slices.Contains(fingerprints, r.Fingerprint) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// MemoryStore keeps completions in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	results map[[2]string][]Result // by game and day
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{results: make(map[[2]string][]Result)}
+}
+
+func (s *MemoryStore) Complete(r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := [2]string{r.Game, r.Day}
+	for _, done := range s.results[k] {
+		if r.Fingerprint != "" && done.Fingerprint == r.Fingerprint {
+			return nil
+		}
+	}
+	s.results[k] = append(s.results[k], r)
+	return nil
+}
+
+func (s *MemoryStore) Results(game, day string) []Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := slices.Clone(s.results[[2]string{game, day}])
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Time < results[j].Time })
+	return results
+}
+
+func (s *MemoryStore) Days(game, fingerprint string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var days []string
+	for k, results := range s.results {
+		if k[0] != game {
+			continue
+		}
+		for _, r := range results {
+			if fingerprint != "" && r.Fingerprint == fingerprint {
+				days = append(days, k[1])
+				break
+			}
+		}
+	}
+	return days
+}
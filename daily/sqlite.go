@@ -0,0 +1,99 @@
+package daily
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS completions (
+	game        TEXT NOT NULL,
+	day         TEXT NOT NULL,
+	player      TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	time        INTEGER NOT NULL,
+	moves       INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS completions_day ON completions (game, day);
+CREATE UNIQUE INDEX IF NOT EXISTS completions_first ON completions (game, day, fingerprint)
+	WHERE fingerprint != '';
+`
+
+// SQLiteStore keeps completions in a SQLite database, so streaks survive
+// restarts.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create daily schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Complete(r Result) error {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO completions VALUES (?, ?, ?, ?, ?, ?)`,
+		r.Game, r.Day, r.Player, r.Fingerprint, r.Time.Milliseconds(), r.Moves)
+	return err
+}
+
+func (s *SQLiteStore) Results(game, day string) []Result {
+	rows, err := s.db.Query(`SELECT player, fingerprint, time, moves FROM completions
+		WHERE game = ? AND day = ? ORDER BY time, rowid`, game, day)
+	if err != nil {
+		log.Warn("Could not load daily results", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		r := Result{Game: game, Day: day}
+		var ms int64
+		if err := rows.Scan(&r.Player, &r.Fingerprint, &ms, &r.Moves); err != nil {
+			return results
+		}
+		r.Time = time.Duration(ms) * time.Millisecond
+		results = append(results, r)
+	}
+	return results
+}
+
+func (s *SQLiteStore) Days(game, fingerprint string) []string {
+	if fingerprint == "" {
+		return nil
+	}
+	rows, err := s.db.Query(`SELECT DISTINCT day FROM completions
+		WHERE game = ? AND fingerprint = ?`, game, fingerprint)
+	if err != nil {
+		log.Warn("Could not load daily streak", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var days []string
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return days
+		}
+		days = append(days, day)
+	}
+	return days
+}
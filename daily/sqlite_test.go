@@ -0,0 +1,49 @@
+package daily
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily.db")
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []Result{
+		{Game: "sudoku", Day: "2026-10-13", Player: "ana", Fingerprint: "a", Time: time.Minute},
+		{Game: "sudoku", Day: "2026-10-14", Player: "ana", Fingerprint: "a", Time: 3 * time.Minute},
+		{Game: "sudoku", Day: "2026-10-14", Player: "ana", Fingerprint: "a", Time: time.Second},
+		{Game: "sudoku", Day: "2026-10-14", Player: "bo", Time: 2 * time.Minute},
+		{Game: "sudoku", Day: "2026-10-14", Player: "cy", Time: 2 * time.Minute},
+	} {
+		if err := s.Complete(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	// Streaks and rankings outlive the server.
+	if s, err = OpenSQLite(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var players []string
+	for _, r := range s.Results("sudoku", "2026-10-14") {
+		players = append(players, r.Player)
+	}
+	// Only ana's first completion counts; anonymous ones all do.
+	if want := []string{"bo", "cy", "ana"}; !slices.Equal(players, want) {
+		t.Errorf("ranking %v, want %v", players, want)
+	}
+	if n := Streak(s.Days("sudoku", "a"), "2026-10-14"); n != 2 {
+		t.Errorf("streak %d, want 2", n)
+	}
+	if days := s.Days("sudoku", ""); len(days) != 0 {
+		t.Errorf("anonymous players completed %v, want no streak", days)
+	}
+}
@@ -0,0 +1,361 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	POLL      = time.Second
+	STANDINGS = 10 // entrants listed
+)
+
+// roundMsg carries a message produced by the round's game, tagged with the
+// run that issued it so stragglers from an earlier round are dropped.
+type roundMsg struct {
+	run int
+	msg tea.Msg
+}
+
+// roundExitMsg replaces the round's tea.Quit: the player sits the rest of
+// the round out.
+type roundExitMsg struct {
+	run int
+}
+
+type pollMsg time.Time
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TitleStyle lipgloss.Style
+	YouStyle   lipgloss.Style
+	QuitStyle  lipgloss.Style
+	BoxStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Admin lets the player start the upcoming event early.
+	Admin bool
+
+	env      games.Env // rounds' games start with
+	calendar *Calendar
+	event    *Event
+	id       int // the player's entry, 0 if they didn't sign up
+	snap     Snapshot
+	last     Snapshot // of the event before, if any
+	hasLast  bool
+
+	game     tea.Model // of the round being played
+	launched int       // last round started, -1 for none
+	run      int
+	cancel   context.CancelFunc
+	err      error
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+// NewModel follows the events of calendar, starting each round's game with
+// env.
+func NewModel(env games.Env, calendar *Calendar) *Model {
+	r := env.Renderer
+	m := &Model{
+		Width:      env.Width,
+		Height:     env.Height,
+		TitleStyle: r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		YouStyle:   r.NewStyle().Bold(true).Foreground(lipgloss.Color("10")),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:     DefaultKeyMap(),
+		env:      env,
+		calendar: calendar,
+		launched: -1,
+		ctx:      context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	return m
+}
+
+// SetContext binds polling, and the rounds' games, to ctx.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.refresh(time.Now()), m.poll())
+}
+
+func (m *Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// refresh follows the calendar to the current event, and starts or stops
+// the round's game as the event moves on.
+func (m *Model) refresh(now time.Time) tea.Cmd {
+	next, last := m.calendar.current(now)
+	if next != m.event {
+		m.stop()
+		m.event = next
+		m.id = next.entry(m.env.Fingerprint)
+		m.launched = -1
+	}
+	if m.hasLast = last != nil; m.hasLast {
+		m.last = last.poll(last.entry(m.env.Fingerprint), now)
+	}
+	m.snap = m.event.poll(m.id, now)
+
+	switch {
+	case m.snap.Phase == PLAYING && m.snap.Entered && m.launched < m.snap.Round:
+		return m.launch(m.snap.Round)
+	case m.snap.Phase != PLAYING && m.game != nil:
+		m.stop()
+	}
+	return nil
+}
+
+// launch starts the game of round in its own context, its runs recorded for
+// the event. It gets a line less than the screen for the event's status.
+func (m *Model) launch(round int) tea.Cmd {
+	m.stop()
+	m.launched = round
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	env := m.env
+	env.Ctx = ctx
+	env.Width, env.Height = m.Width, m.Height-1
+	env.Scores = scores{Store: m.env.Scores, event: m.event, id: m.id}
+	env.Room = ""
+
+	game, err := games.New(m.snap.Rounds[round].Game, env)
+	if err != nil {
+		cancel()
+		m.err = err
+		return nil
+	}
+	m.err = nil
+	m.run++
+	m.game = game
+	m.cancel = cancel
+	return tag(m.run, game.Init())
+}
+
+func (m *Model) stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.game = nil
+	m.cancel = nil
+}
+
+func (m *Model) forward(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+	return tag(m.run, cmd)
+}
+
+const teaPackage = "github.com/charmbracelet/bubbletea"
+
+// tag routes a command's result back to the round's game that issued it,
+// turning its tea.Quit into leaving the round, like the hub does for games.
+func tag(run int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return roundExitMsg{run: run}
+		case tea.BatchMsg:
+			batch := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				batch[i] = tag(run, c)
+			}
+			return batch
+		}
+		if t := reflect.TypeOf(msg); t.PkgPath() == teaPackage || t.Kind() == reflect.Pointer && t.Elem().PkgPath() == teaPackage {
+			return msg
+		}
+		return roundMsg{run: run, msg: msg}
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case roundMsg:
+		if msg.run != m.run || m.game == nil {
+			return m, nil
+		}
+		return m, m.forward(msg.msg)
+	case roundExitMsg:
+		if msg.run == m.run {
+			m.stop()
+		}
+		return m, nil
+	case pollMsg:
+		return m, tea.Batch(m.refresh(time.Time(msg)), m.poll())
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		if m.game != nil {
+			return m, m.forward(tea.WindowSizeMsg{Width: msg.Width, Height: msg.Height - 1})
+		}
+		return m, nil
+	}
+
+	if m.game != nil {
+		return m, m.forward(msg)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.stop()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Enter):
+			if m.id == 0 {
+				m.id = m.event.enter(m.env.Player, m.env.Fingerprint, time.Now())
+			}
+			return m, m.refresh(time.Now())
+		case m.Admin && key.Matches(msg, m.Keys.Start):
+			m.event.startSoon(time.Now())
+			return m, m.refresh(time.Now())
+		}
+	}
+	return m, nil
+}
+
+// countdown renders the time left until t, to the second.
+func countdown(t time.Time) string {
+	return max(0, time.Until(t)).Round(time.Second).String()
+}
+
+func (m Model) roundsView() string {
+	var s strings.Builder
+	for i, r := range m.snap.Rounds {
+		fmt.Fprintf(&s, "%d. %-14s %s\n", i+1, r.Title, m.QuitStyle.Render(r.Length.String()))
+	}
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+func (m Model) standingsView(snap Snapshot) string {
+	if len(snap.Standings) == 0 {
+		return m.QuitStyle.Render("Nobody signed up yet")
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "%-3s %-12s", "", "Player")
+	for i := range snap.Rounds {
+		fmt.Fprintf(&s, " %5s", fmt.Sprintf("R%d", i+1))
+	}
+	fmt.Fprintf(&s, " %6s", "Total")
+	for i, st := range snap.Standings[:min(len(snap.Standings), STANDINGS)] {
+		line := fmt.Sprintf("%-3s %-12s", fmt.Sprintf("%d.", i+1), st.Name)
+		for _, p := range st.Points {
+			line += fmt.Sprintf(" %5d", p)
+		}
+		line += fmt.Sprintf(" %6d", st.Total)
+		if st.You {
+			line = m.YouStyle.Render(line)
+		}
+		s.WriteString("\n" + line)
+	}
+	return s.String()
+}
+
+// status is the event's line under the round's game.
+func (m Model) status() string {
+	r := m.snap.Rounds[m.snap.Round]
+	return m.QuitStyle.Render(fmt.Sprintf("Decathlon round %d/%d: %s, %s left. Best finished run counts.",
+		m.snap.Round+1, len(m.snap.Rounds), r.Title, countdown(m.snap.Until)))
+}
+
+func (m Model) eventView() string {
+	title := m.TitleStyle.Render("Decathlon")
+	var body []string
+	switch m.snap.Phase {
+	case SIGNUP:
+		body = append(body,
+			fmt.Sprintf("Next event at %s, in %s", clock.At(m.snap.Until, time.Now(), m.env.Location), countdown(m.snap.Until)),
+			"",
+			m.roundsView(),
+			"",
+			fmt.Sprintf("%d signed up", len(m.snap.Standings)),
+		)
+		if m.snap.Entered {
+			body = append(body, m.YouStyle.Render("You're in! Stay here for the first round."))
+		} else {
+			body = append(body, fmt.Sprintf("Press '%s' to sign up", m.Keys.Enter.Help().Key))
+		}
+		if m.hasLast {
+			body = append(body, "", "Last event", m.standingsView(m.last))
+		}
+	case PLAYING:
+		r := m.snap.Rounds[m.snap.Round]
+		body = append(body, fmt.Sprintf("Round %d/%d: %s, %s left", m.snap.Round+1, len(m.snap.Rounds), r.Title, countdown(m.snap.Until)))
+		if !m.snap.Entered {
+			body = append(body, m.QuitStyle.Render("Signups are closed, following along"))
+		}
+		body = append(body, "", m.standingsView(m.snap))
+	case INTERVAL:
+		next := "Final results"
+		if m.snap.Round+1 < len(m.snap.Rounds) {
+			next = "Up next: " + m.snap.Rounds[m.snap.Round+1].Title
+		}
+		body = append(body, fmt.Sprintf("Round %d done. %s in %s", m.snap.Round+1, next, countdown(m.snap.Until)), "", m.standingsView(m.snap))
+	}
+	if m.err != nil {
+		body = append(body, "", m.err.Error())
+	}
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, append([]string{title, ""}, body...)...))
+}
+
+func (m Model) View() string {
+	if m.game != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, m.game.View(), m.status())
+	}
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.eventView(),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,292 @@
+package game
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+)
+
+const (
+	COUNTDOWN = 30 * time.Second // from an admin starting an event to its first round
+	BREAK     = 30 * time.Second // between rounds, while the standings show
+	MAXPOINTS = 1000             // of a round, for its best score
+)
+
+// Event phases
+const (
+	SIGNUP = iota
+	PLAYING
+	INTERVAL // the break after a round
+	OVER
+)
+
+// Round is one game of an event, played for a fixed time. Only the best
+// run a player finishes within it counts.
+type Round struct {
+	Game   string // as registered
+	Title  string
+	Length time.Duration
+}
+
+// DefaultRounds are short rounds of games that rank runs on the
+// leaderboard, which is where the event reads scores from.
+var DefaultRounds = []Round{
+	{Game: "snake", Title: "Snake sprint", Length: 3 * time.Minute},
+	{Game: "anagram", Title: "Anagrams", Length: 2 * time.Minute},
+	{Game: "yahtzee", Title: "Yahtzee", Length: 5 * time.Minute},
+}
+
+// Schedule runs an event every Every, Offset into each period, e.g. daily
+// at 20:00 UTC.
+type Schedule struct {
+	Every  time.Duration
+	Offset time.Duration
+}
+
+var DefaultSchedule = Schedule{Every: 24 * time.Hour, Offset: 20 * time.Hour}
+
+// Next is when the first event starting after now does.
+func (s Schedule) Next(now time.Time) time.Time {
+	next := now.UTC().Truncate(s.Every).Add(s.Offset)
+	for !next.After(now) {
+		next = next.Add(s.Every)
+	}
+	return next
+}
+
+type entrant struct {
+	id          int
+	name        string
+	fingerprint string
+	best        []int // by round
+}
+
+// Event is a decathlon: players sign up before it starts, then everyone
+// plays the same rounds at the same time. Like trivia rooms it has no
+// goroutine; its phase follows from the clock.
+type Event struct {
+	mu       sync.Mutex
+	start    time.Time // of the first round
+	rounds   []Round
+	entrants []*entrant
+	nextID   int
+}
+
+func newEvent(start time.Time, rounds []Round) *Event {
+	return &Event{start: start, rounds: rounds}
+}
+
+// at locates now in the event: its phase, the round it's on or just played,
+// and when that part ends.
+func (e *Event) at(now time.Time) (phase, round int, until time.Time) {
+	if now.Before(e.start) {
+		return SIGNUP, 0, e.start
+	}
+	t := e.start
+	for i, r := range e.rounds {
+		if t = t.Add(r.Length); now.Before(t) {
+			return PLAYING, i, t
+		}
+		if t = t.Add(BREAK); now.Before(t) {
+			return INTERVAL, i, t
+		}
+	}
+	return OVER, len(e.rounds), t
+}
+
+// over reports whether the event is done at now.
+func (e *Event) over(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	phase, _, _ := e.at(now)
+	return phase == OVER
+}
+
+// enter signs name up before the event starts, returning 0 once it did.
+// Players with a key keep their entry when they reconnect, see entry.
+func (e *Event) enter(name, fingerprint string, now time.Time) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if phase, _, _ := e.at(now); phase != SIGNUP {
+		return 0
+	}
+	e.nextID++
+	e.entrants = append(e.entrants, &entrant{
+		id:          e.nextID,
+		name:        name,
+		fingerprint: fingerprint,
+		best:        make([]int, len(e.rounds)),
+	})
+	return e.nextID
+}
+
+// entry finds the entry of the player with fingerprint, 0 if they didn't
+// sign up or play anonymously.
+func (e *Event) entry(fingerprint string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if fingerprint == "" {
+		return 0
+	}
+	if i := slices.IndexFunc(e.entrants, func(en *entrant) bool { return en.fingerprint == fingerprint }); i >= 0 {
+		return e.entrants[i].id
+	}
+	return 0
+}
+
+// startSoon brings the event forward to start after the countdown, unless it
+// starts sooner anyway.
+func (e *Event) startSoon(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if soon := now.Add(COUNTDOWN); soon.Before(e.start) {
+		e.start = soon
+	}
+}
+
+// record keeps score as entrant id's run in game, if it was played during
+// that game's round.
+func (e *Event) record(id int, game string, score int, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	phase, round, _ := e.at(now)
+	i := slices.IndexFunc(e.entrants, func(en *entrant) bool { return en.id == id })
+	if phase != PLAYING || i < 0 || e.rounds[round].Game != game {
+		return
+	}
+	en := e.entrants[i]
+	en.best[round] = max(en.best[round], score)
+}
+
+// Standing is an entrant's place in the event. Each round scores up to
+// MAXPOINTS, relative to the best score in it, so games with different
+// scales weigh the same.
+type Standing struct {
+	Name   string
+	Points []int // by round
+	Total  int
+	You    bool
+}
+
+type Snapshot struct {
+	Phase     int
+	Round     int
+	Until     time.Time
+	Rounds    []Round
+	Standings []Standing // best first
+	Entered   bool
+}
+
+// poll describes the event to entrant id, who is 0 if they didn't enter.
+func (e *Event) poll(id int, now time.Time) Snapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	phase, round, until := e.at(now)
+	s := Snapshot{Phase: phase, Round: round, Until: until, Rounds: e.rounds}
+
+	best := make([]int, len(e.rounds))
+	for _, en := range e.entrants {
+		for r, score := range en.best {
+			best[r] = max(best[r], score)
+		}
+	}
+	for _, en := range e.entrants {
+		st := Standing{Name: en.name, Points: make([]int, len(e.rounds)), You: en.id == id}
+		for r, score := range en.best {
+			if best[r] > 0 {
+				st.Points[r] = int(math.Round(float64(MAXPOINTS) * float64(score) / float64(best[r])))
+			}
+			st.Total += st.Points[r]
+		}
+		s.Entered = s.Entered || st.You
+		s.Standings = append(s.Standings, st)
+	}
+	sort.SliceStable(s.Standings, func(i, j int) bool { return s.Standings[i].Total > s.Standings[j].Total })
+	return s
+}
+
+// Calendar keeps the upcoming event of a schedule, and the last one for its
+// results.
+type Calendar struct {
+	mu       sync.Mutex
+	schedule Schedule
+	rounds   []Round
+	next     *Event
+	last     *Event
+}
+
+func NewCalendar(s Schedule, rounds []Round) *Calendar {
+	return &Calendar{schedule: s, rounds: rounds}
+}
+
+// current is the event that is next or running at now, along with the one
+// before it, if any.
+func (c *Calendar) current(now time.Time) (next, last *Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.next != nil && c.next.over(now) {
+		c.last, c.next = c.next, nil
+	}
+	if c.next == nil {
+		c.next = newEvent(c.schedule.Next(now), c.rounds)
+	}
+	return c.next, c.last
+}
+
+// Next is when the upcoming event starts, or the one after it if that one
+// already has.
+func (c *Calendar) Next(now time.Time) time.Time {
+	next, _ := c.current(now)
+	next.mu.Lock()
+	start := next.start
+	next.mu.Unlock()
+	if start.After(now) {
+		return start
+	}
+	return c.schedule.Next(now)
+}
+
+// scores passes runs on to the leaderboard while recording them for the
+// event.
+type scores struct {
+	leaderboard.Store // may be nil
+	event             *Event
+	id                int
+}
+
+func (s scores) Submit(en leaderboard.Entry) error {
+	s.event.record(s.id, en.Game, en.Points, time.Now())
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Submit(en)
+}
+
+func (s scores) Top(f leaderboard.Filter, n int) []leaderboard.Entry {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Top(f, n)
+}
+
+func (s scores) Best(f leaderboard.Filter, fingerprint string) (leaderboard.Entry, bool) {
+	if s.Store == nil {
+		return leaderboard.Entry{}, false
+	}
+	return s.Store.Best(f, fingerprint)
+}
+
+func (s scores) Keys() []leaderboard.Key {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Keys()
+}
@@ -0,0 +1,48 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	Enter key.Binding
+	Start key.Binding
+	Help  key.Binding
+	Quit  key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		Enter: key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "sign up")),
+		Start: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start now (admins)")),
+		Help:  ui.HelpKey(),
+		Quit:  ui.QuitKeyFor(l),
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Enter, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Enter, k.Start},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"enter": &k.Enter,
+		"start": &k.Start,
+		"help":  &k.Help,
+		"quit":  &k.Quit,
+	}
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"slices"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "decathlon"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Decathlon",
+	Description: "Short rounds of several games, ranked on combined scores",
+	Category:    games.MULTIPLAYER,
+	MinPlayers:  1,
+	MaxPlayers:  100,
+	Session:     15 * time.Minute,
+	Next: func(now time.Time) (string, time.Time) {
+		return "event", calendar.Next(now)
+	},
+}
+
+var (
+	calendar = NewCalendar(DefaultSchedule, DefaultRounds)
+	admins   []string
+)
+
+// SetAdmins lets the players with the given key fingerprints start events
+// early. Call it before serving.
+func SetAdmins(fingerprints []string) {
+	admins = fingerprints
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env, calendar)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.Admin = env.Fingerprint != "" && slices.Contains(admins, env.Fingerprint)
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
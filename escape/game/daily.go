@@ -0,0 +1,62 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/maze"
+)
+
+const DAILYRANKS = 5 // completions listed on the daily win screen
+
+// StartDaily switches to today's shared maze. Every player gets the same one
+// at NORMAL difficulty.
+func (m *Model) StartDaily() {
+	m.day = daily.Today(time.Now())
+	m.difficulty = maze.NORMAL
+	m.level = 0
+	m.NewMaze()
+}
+
+// completeDaily records today's run and loads the rankings shown when it's
+// over.
+func (m *Model) completeDaily() {
+	if m.Daily == nil {
+		return
+	}
+	err := m.Daily.Complete(daily.Result{
+		Game:        GAMENAME,
+		Day:         m.day,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Time:        m.elapsed,
+		Moves:       m.moves,
+	})
+	if err != nil {
+		log.Warn("Could not record daily maze", "err", err)
+	}
+	m.ranking = m.Daily.Results(GAMENAME, m.day)
+	m.friends = daily.Friends(m.ranking, m.Friends)
+	m.streak = daily.Streak(m.Daily.Days(GAMENAME, m.Fingerprint), m.day)
+}
+
+func (m Model) dailyView() string {
+	var s strings.Builder
+	if m.Fingerprint != "" {
+		fmt.Fprintf(&s, "Streak: %d day(s)\n", m.streak)
+	}
+	list := func(title string, results []daily.Result) {
+		fmt.Fprintf(&s, "\n%s\n", title)
+		for i, r := range results[:min(DAILYRANKS, len(results))] {
+			fmt.Fprintf(&s, "%d. %-12s %8s\n", i+1, r.Player, r.Time.Truncate(time.Millisecond))
+		}
+	}
+	list(fmt.Sprintf("Today's fastest (%d solved)", len(m.ranking)), m.ranking)
+	if len(m.friends) > 0 {
+		list("Friends", m.friends)
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
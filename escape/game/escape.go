@@ -0,0 +1,320 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/hint"
+	"github.com/debemdeboas/games.debem.dev/maze"
+	"github.com/debemdeboas/games.debem.dev/ui"
+	"golang.org/x/exp/rand"
+)
+
+const (
+	CAMERAMARGIN = 4
+
+	hudRows    = 6
+	borderCols = 2
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TxtStyle    lipgloss.Style
+	QuitStyle   lipgloss.Style
+	WallStyle   lipgloss.Style
+	PlayerStyle lipgloss.Style
+	HintStyle   lipgloss.Style
+	WinStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Game state
+	difficulty maze.Difficulty
+	level      int
+	maze       *maze.Maze
+	player     grid.Point
+	moves      int
+	started    time.Time
+	elapsed    time.Duration
+	escaped    bool
+	showHelp   bool
+	hints      hint.Counter
+	hintCell   *grid.Point
+	camera     ui.Viewport
+
+	// Daily maze: day is set while playing it. Completions go to Daily
+	// under the player's name and key.
+	Daily       daily.Store
+	Player      string
+	Fingerprint string
+	Friends     []string
+	day         string
+	streak      int
+	ranking     []daily.Result
+	friends     []daily.Result
+
+	ctx    context.Context
+	frames *ui.FrameCache
+}
+
+type clockMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		TxtStyle:    r.NewStyle().BorderStyle(lipgloss.RoundedBorder()),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		WallStyle:   r.NewStyle().Foreground(lipgloss.Color("4")),
+		PlayerStyle: r.NewStyle().Foreground(lipgloss.Color("11")),
+		HintStyle:   r.NewStyle().Foreground(lipgloss.Color("10")),
+		WinStyle: r.NewStyle().
+			Foreground(lipgloss.Color("10")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(3),
+		Keys:       DefaultKeyMap(),
+		difficulty: maze.NORMAL,
+		ctx:        context.Background(),
+		frames:     &ui.FrameCache{},
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.NewMaze()
+	return m
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.clock()
+}
+
+func (m Model) clock() tea.Cmd {
+	return ui.Every(m.ctx, time.Second, func(t time.Time) tea.Msg {
+		return clockMsg(t)
+	})
+}
+
+// NewMaze generates a fresh, verified-solvable maze at the current
+// difficulty, or today's maze while playing the daily one.
+func (m *Model) NewMaze() {
+	seed := rand.Uint64()
+	if m.day != "" {
+		seed = daily.Seed(GAMENAME, m.day)
+	}
+	mz := maze.Generate(maze.Preset(m.difficulty, seed))
+	for !mz.Solvable() {
+		seed++
+		mz = maze.Generate(maze.Preset(m.difficulty, seed))
+	}
+
+	m.maze = mz
+	m.player = mz.Start
+	m.moves = 0
+	m.started = time.Now()
+	m.elapsed = 0
+	m.escaped = false
+	m.hints.Reset()
+	m.hintCell = nil
+	m.camera = ui.NewViewport(mz.Width(), mz.Height(), CAMERAMARGIN)
+	m.updateCamera()
+}
+
+// SetContext binds the clock to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m *Model) updateCamera() {
+	if m.Width > 0 && m.Height > 0 {
+		m.camera.Resize((m.Width-borderCols)/2, m.Height-hudRows)
+	}
+	m.camera.Follow(m.player.X, m.player.Y)
+}
+
+func (m *Model) move(d grid.Point) {
+	if m.escaped {
+		return
+	}
+
+	next := m.player.Add(d)
+	if !m.maze.Walkable(next) {
+		return
+	}
+
+	m.player = next
+	m.moves++
+	m.hintCell = nil
+	m.updateCamera()
+
+	if m.player == m.maze.Exit {
+		m.escaped = true
+		m.elapsed = time.Since(m.started)
+		if m.day != "" {
+			m.completeDaily()
+		}
+	}
+}
+
+func (m *Model) requestHint() {
+	if m.escaped {
+		return
+	}
+
+	path, ok := m.maze.Path(m.player, m.maze.Exit, func(_ grid.Point, wall bool) bool { return !wall })
+	if ok && len(path) > 1 && m.hints.Take() {
+		m.hintCell = &path[1]
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		m.updateCamera()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Up):
+			m.move(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.move(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.move(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.move(grid.Right)
+		case key.Matches(msg, m.Keys.Hint):
+			m.requestHint()
+		case key.Matches(msg, m.Keys.NewMaze):
+			m.day = ""
+			m.NewMaze()
+		case key.Matches(msg, m.Keys.Daily):
+			m.StartDaily()
+		case key.Matches(msg, m.Keys.Difficulty):
+			m.day = ""
+			m.difficulty = (m.difficulty + 1) % (maze.HARD + 1)
+			m.level = 0
+			m.NewMaze()
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Next):
+			if m.escaped {
+				m.day = ""
+				m.level++
+				m.NewMaze()
+			}
+		}
+	case clockMsg:
+		return m, m.clock()
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	return m.frames.Frame(m.stateHash(), m.render)
+}
+
+// stateHash covers everything render reads. The maze itself only changes
+// through NewMaze, which also resets the start time.
+func (m Model) stateHash() uint64 {
+	h := ui.NewStateHash()
+	h.Int(m.Width)
+	h.Int(m.Height)
+	h.Int(int(m.started.UnixNano()))
+	h.Int(int(time.Since(m.started) / time.Second))
+	h.Int(m.player.X)
+	h.Int(m.player.Y)
+	h.Int(m.moves)
+	h.Bool(m.escaped)
+	h.Bool(m.showHelp)
+	h.Int(int(m.Keys.layout))
+	h.Int(m.hints.Used)
+	h.String(m.day)
+	h.Int(len(m.ranking))
+	if m.hintCell != nil {
+		h.Int(m.hintCell.X)
+		h.Int(m.hintCell.Y)
+	}
+	h.Int(m.camera.X)
+	h.Int(m.camera.Y)
+	return h.Sum()
+}
+
+func (m Model) render() string {
+	board := m.camera.Render(func(x, y int) string {
+		p := grid.Point{X: x, Y: y}
+		switch {
+		case p == m.player:
+			return m.PlayerStyle.Render("🙂")
+		case p == m.maze.Exit:
+			return "🚪"
+		case m.maze.At(p):
+			return m.WallStyle.Render("██")
+		case m.hintCell != nil && *m.hintCell == p:
+			return m.HintStyle.Render("··")
+		default:
+			return "  "
+		}
+	})
+
+	if m.escaped {
+		lines := []string{
+			"You escaped!",
+			fmt.Sprintf("%d moves in %s", m.moves, m.elapsed.Truncate(time.Millisecond)),
+			fmt.Sprintf("Hints used: %d", m.hints.Used),
+		}
+		if m.day != "" && m.Daily != nil {
+			lines = append(lines, "", m.dailyView(), "")
+		}
+		lines = append(lines, fmt.Sprintf("Press '%s' for the next maze", m.Keys.Next.Help().Key))
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.WinStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...)),
+		)
+	}
+
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.WinStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
+	elapsed := time.Since(m.started).Truncate(time.Second)
+	title := fmt.Sprintf("%s maze #%d", m.difficulty, m.level+1)
+	if m.day != "" {
+		title = "Daily maze " + m.day
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			fmt.Sprintf("%s | Moves: %d | Time: %s", title, m.moves, elapsed),
+			m.TxtStyle.Render(board)+"\n",
+			m.QuitStyle.Render(fmt.Sprintf("Hints used: %d", m.hints.Used)),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Hint       key.Binding
+	NewMaze    key.Binding
+	Difficulty key.Binding
+	Next       key.Binding
+	Daily      key.Binding
+	Layout     key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys:   ui.MoveKeysFor(l),
+		Hint:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "hint")),
+		NewMaze:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "new maze")),
+		Difficulty: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "change difficulty")),
+		Next:       key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "next maze")),
+		Daily:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "daily maze")),
+		Layout:     ui.LayoutKey(),
+		Help:       ui.HelpKey(),
+		Quit:       ui.QuitKeyFor(l),
+		layout:     l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Hint, k.NewMaze, k.Difficulty, k.Daily, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Hint, k.NewMaze, k.Difficulty, k.Daily, k.Next},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":         &k.Up,
+		"down":       &k.Down,
+		"left":       &k.Left,
+		"right":      &k.Right,
+		"hint":       &k.Hint,
+		"new-maze":   &k.NewMaze,
+		"difficulty": &k.Difficulty,
+		"next":       &k.Next,
+		"daily":      &k.Daily,
+		"layout":     &k.Layout,
+		"help":       &k.Help,
+		"quit":       &k.Quit,
+	}
+}
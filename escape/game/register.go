@@ -0,0 +1,43 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "escape"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Maze Escape",
+	Description: "Find the way out of a generated maze",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     3 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.Daily = env.Daily
+		m.Player = env.Player
+		m.Fingerprint = env.Fingerprint
+		if env.Profile != nil {
+			m.Friends = env.Profile.Friends
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	escape "github.com/debemdeboas/games.debem.dev/escape/game"
+	"github.com/debemdeboas/games.debem.dev/ui"
+	"golang.org/x/term"
+)
+
+func main() {
+	w, h, _ := term.GetSize(int(os.Stdout.Fd()))
+	m := escape.NewModel(w, h, lipgloss.DefaultRenderer())
+	m.SetLayout(ui.LayoutFromEnv(os.Environ()))
+	p := tea.NewProgram(m, tea.WithAltScreen())
+
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Error running program: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,167 @@
+// Package export renders recorded replays as animated SVGs or GIFs, so
+// players can share their best runs where a terminal can't follow. Only
+// sessions that opted into recording have frames to export.
+//
+// Rendering a few hundred frames is real work for the server, so the HTTP
+// API limits how often each client may ask for one, how many render at
+// once, and keeps the latest renders around for repeat requests.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/record"
+	"github.com/debemdeboas/games.debem.dev/throttle"
+)
+
+const (
+	RATEWINDOW = time.Minute // span a client's renders are counted over
+	RATELIMIT  = 3           // renders a client may ask for per window
+	RENDERING  = 2           // renders running at once, server-wide
+	CACHED     = 32          // renders kept for repeat requests
+)
+
+// Store holds the recordings that can be exported, such as
+// record.MemorySink.
+type Store interface {
+	Find(session string) (*record.Recording, bool)
+	Recent() []*record.Recording
+}
+
+// formats are the exports on offer, by file extension.
+var formats = map[string]struct {
+	contentType string
+	render      func(io.Writer, []record.Frame) error
+}{
+	".svg": {"image/svg+xml", SVG},
+	".gif": {"image/gif", GIF},
+}
+
+// Replay describes a stored recording in the listing.
+type Replay struct {
+	Session string        `json:"session"`
+	User    string        `json:"user"`
+	Started time.Time     `json:"started"`
+	Length  time.Duration `json:"length"` // in nanoseconds, of the frames kept
+	Frames  int           `json:"frames"`
+}
+
+type server struct {
+	store   Store
+	slots   chan struct{}
+	limiter *throttle.Limiter
+
+	mu    sync.Mutex
+	cache map[string][]byte
+	order []string // of the cache, oldest first
+}
+
+// Handler serves the recordings in store: GET /replays lists them and GET
+// /replays/<session>.svg or .gif exports one.
+func Handler(store Store) http.Handler {
+	s := &server{
+		store:   store,
+		slots:   make(chan struct{}, RENDERING),
+		limiter: throttle.NewLimiter(RATELIMIT, RATEWINDOW),
+		cache:   make(map[string][]byte),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /replays", s.list)
+	mux.HandleFunc("GET /replays/{file}", s.export)
+	return mux
+}
+
+func (s *server) list(w http.ResponseWriter, r *http.Request) {
+	var replays []Replay
+	for _, rec := range s.store.Recent() {
+		frames := rec.Frames()
+		if len(frames) == 0 {
+			continue
+		}
+		replays = append(replays, Replay{
+			Session: rec.Session,
+			User:    rec.User,
+			Started: rec.Started,
+			Length:  frames[len(frames)-1].At - frames[0].At,
+			Frames:  len(frames),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(replays); err != nil {
+		log.Debug("Encoding replays failed", "error", err)
+	}
+}
+
+func (s *server) export(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	format, ok := formats[ext]
+	if !ok {
+		http.Error(w, "replays export as .svg or .gif", http.StatusNotFound)
+		return
+	}
+	rec, ok := s.store.Find(strings.TrimSuffix(file, ext))
+	if !ok || len(rec.Frames()) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	out, cached := s.cached(file)
+	if !cached {
+		if !s.limiter.Allow(throttle.Client(r), time.Now()) {
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "too many exports, try again later", http.StatusTooManyRequests)
+			return
+		}
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "the server is busy exporting, try again shortly", http.StatusServiceUnavailable)
+			return
+		}
+		var b bytes.Buffer
+		err := format.render(&b, rec.Frames())
+		<-s.slots
+		if err != nil {
+			log.Error("Exporting replay failed", "session", rec.Session, "format", ext, "error", err)
+			http.Error(w, "export failed", http.StatusInternalServerError)
+			return
+		}
+		out = b.Bytes()
+		s.keep(file, out)
+	}
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400") // recordings don't change once stored
+	_, _ = w.Write(out)
+}
+
+func (s *server) cached(file string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out, ok := s.cache[file]
+	return out, ok
+}
+
+func (s *server) keep(file string, out []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cache[file]; ok {
+		return
+	}
+	s.cache[file] = out
+	s.order = append(s.order, file)
+	if len(s.order) > CACHED {
+		delete(s.cache, s.order[0])
+		s.order = s.order[1:]
+	}
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/record"
+)
+
+// Cell size of GIF exports, in pixels.
+const (
+	GIFCELLWIDTH  = 6
+	GIFCELLHEIGHT = 12
+)
+
+// GIF renders frames as an animated GIF that loops forever. There's no font
+// to draw text with, so each cell is a block of its colors: block and line
+// glyphs keep their shape and any other glyph is a smaller block inside its
+// cell. That reads fine for boards and courts, less so for prose.
+func GIF(w io.Writer, frames []record.Frame) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to export")
+	}
+	screens := make([]Screen, len(frames))
+	var width, height int
+	for i, f := range frames {
+		screens[i] = Parse(f.View)
+		fw, fh := screens[i].Size()
+		width, height = max(width, fw), max(height, fh)
+	}
+
+	p := &painter{index: make(map[color.RGBA]uint8)}
+	anim := &gif.GIF{}
+	bounds := image.Rect(0, 0, width*GIFCELLWIDTH, height*GIFCELLHEIGHT)
+	for i, s := range screens {
+		img := image.NewPaletted(bounds, palette.Plan9)
+		p.fill(img, bounds, BACKGROUND)
+		for y, row := range s {
+			for x, c := range row {
+				p.cell(img, x, y, c)
+			}
+		}
+		shown := HOLD
+		if i+1 < len(frames) {
+			shown = frames[i+1].At - frames[i].At
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay(shown))
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// delay is d in the hundredths of a second GIFs count in. Viewers slow
+// anything under two down, so that's the shortest.
+func delay(d time.Duration) int {
+	return max(2, int(d/(10*time.Millisecond)))
+}
+
+// painter fills paletted images, remembering the palette index of every
+// color it has used.
+type painter struct {
+	index map[color.RGBA]uint8
+}
+
+func (p *painter) fill(img *image.Paletted, r image.Rectangle, c color.RGBA) {
+	i, ok := p.index[c]
+	if !ok {
+		i = uint8(img.Palette.Index(c))
+		p.index[c] = i
+	}
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(r.Min.X, y):img.PixOffset(r.Max.X, y)]
+		for x := range row {
+			row[x] = i
+		}
+	}
+}
+
+// cell paints cell c at column x and row y.
+func (p *painter) cell(img *image.Paletted, x, y int, c Cell) {
+	fg, bg := c.Colors()
+	box := image.Rect(x*GIFCELLWIDTH, y*GIFCELLHEIGHT, (x+1)*GIFCELLWIDTH, (y+1)*GIFCELLHEIGHT)
+	if bg != BACKGROUND {
+		p.fill(img, box, bg)
+	}
+	if c.Rune == 0 || c.Rune == ' ' {
+		return
+	}
+	p.fill(img, glyph(c.Rune, box), fg)
+}
+
+// glyph is the part of box that r covers.
+func glyph(r rune, box image.Rectangle) image.Rectangle {
+	w, h := box.Dx(), box.Dy()
+	mid := box.Min.Add(image.Pt(w/2, h/2))
+	switch r {
+	case '█':
+		return box
+	case '▀':
+		return image.Rect(box.Min.X, box.Min.Y, box.Max.X, mid.Y)
+	case '▄':
+		return image.Rect(box.Min.X, mid.Y, box.Max.X, box.Max.Y)
+	case '▌':
+		return image.Rect(box.Min.X, box.Min.Y, mid.X, box.Max.Y)
+	case '▐':
+		return image.Rect(mid.X, box.Min.Y, box.Max.X, box.Max.Y)
+	case '─', '━', '═', '-':
+		return image.Rect(box.Min.X, mid.Y-1, box.Max.X, mid.Y+1)
+	case '│', '┃', '║', '|':
+		return image.Rect(mid.X-1, box.Min.Y, mid.X+1, box.Max.Y)
+	case '·', '.', '•', '∙':
+		return image.Rect(mid.X-1, mid.Y-1, mid.X+1, mid.Y+1)
+	}
+	return box.Inset(1).Intersect(image.Rect(box.Min.X, box.Min.Y+h/4, box.Max.X, box.Max.Y-h/8))
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Default colors of cells that set none, as on a dark terminal.
+var (
+	FOREGROUND = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	BACKGROUND = color.RGBA{0x1c, 0x1c, 0x1c, 0xff}
+)
+
+// Cell is one column of a rendered line. Wide runes take their cell and
+// leave the next one empty with Rune 0.
+type Cell struct {
+	Rune    rune
+	FG, BG  color.RGBA
+	Bold    bool
+	Reverse bool
+}
+
+// Colors returns the cell's foreground and background as shown, with
+// reverse video applied.
+func (c Cell) Colors() (fg, bg color.RGBA) {
+	if c.Reverse {
+		return c.BG, c.FG
+	}
+	return c.FG, c.BG
+}
+
+// Screen is a frame parsed into cells, one row per line.
+type Screen [][]Cell
+
+// Size is the widest row and the number of rows.
+func (s Screen) Size() (width, height int) {
+	for _, row := range s {
+		width = max(width, len(row))
+	}
+	return width, len(s)
+}
+
+// Parse reads a frame as rendered by bubbletea: lines of text styled with
+// SGR escape sequences. Other escape sequences are skipped.
+func Parse(view string) Screen {
+	var s Screen
+	style := Cell{FG: FOREGROUND, BG: BACKGROUND}
+	for _, line := range strings.Split(view, "\n") {
+		var row []Cell
+		for i := 0; i < len(line); {
+			if line[i] == '\x1b' {
+				i += escape(line[i:], &style)
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(line[i:])
+			i += size
+			if r < ' ' {
+				continue
+			}
+			c := style
+			c.Rune = r
+			row = append(row, c)
+			for w := runewidth.RuneWidth(r); w > 1; w-- {
+				c.Rune = 0
+				row = append(row, c)
+			}
+		}
+		s = append(s, row)
+	}
+	return s
+}
+
+// escape applies the escape sequence at the start of s to style, if it's
+// SGR, and reports its length.
+func escape(s string, style *Cell) int {
+	if len(s) < 2 || s[1] != '[' {
+		return min(len(s), 2)
+	}
+	end := 2
+	for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+		end++
+	}
+	if end == len(s) {
+		return end
+	}
+	if s[end] == 'm' {
+		sgr(s[2:end], style)
+	}
+	return end + 1
+}
+
+// sgr applies Select Graphic Rendition parameters to style.
+func sgr(params string, style *Cell) {
+	var ps []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		ps = append(ps, n)
+	}
+	for i := 0; i < len(ps); i++ {
+		switch p := ps[i]; {
+		case p == 0:
+			*style = Cell{FG: FOREGROUND, BG: BACKGROUND}
+		case p == 1:
+			style.Bold = true
+		case p == 22:
+			style.Bold = false
+		case p == 7:
+			style.Reverse = true
+		case p == 27:
+			style.Reverse = false
+		case p >= 30 && p <= 37:
+			style.FG = xterm(p - 30)
+		case p >= 90 && p <= 97:
+			style.FG = xterm(p - 90 + 8)
+		case p >= 40 && p <= 47:
+			style.BG = xterm(p - 40)
+		case p >= 100 && p <= 107:
+			style.BG = xterm(p - 100 + 8)
+		case p == 39:
+			style.FG = FOREGROUND
+		case p == 49:
+			style.BG = BACKGROUND
+		case p == 38 || p == 48:
+			c, n := extended(ps[i+1:])
+			i += n
+			if p == 38 {
+				style.FG = c
+			} else {
+				style.BG = c
+			}
+		}
+	}
+}
+
+// extended reads a 256-color or true color parameter list, returning the
+// color and how many parameters it took.
+func extended(ps []int) (color.RGBA, int) {
+	switch {
+	case len(ps) >= 2 && ps[0] == 5:
+		return xterm(ps[1]), 2
+	case len(ps) >= 4 && ps[0] == 2:
+		return color.RGBA{uint8(ps[1]), uint8(ps[2]), uint8(ps[3]), 0xff}, 4
+	}
+	return FOREGROUND, len(ps)
+}
+
+// ansi are the 16 base colors, as xterm shows them.
+var ansi = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// xterm is color n of the xterm 256-color palette.
+func xterm(n int) color.RGBA {
+	switch {
+	case n < 0 || n > 255:
+		return FOREGROUND
+	case n < 16:
+		return ansi[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 0xff}
+	}
+	g := uint8(8 + (n-232)*10)
+	return color.RGBA{g, g, g, 0xff}
+}
+
+// hex renders c as CSS does.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
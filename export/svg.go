@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/record"
+)
+
+// Cell size of SVG exports, in pixels.
+const (
+	SVGCELLWIDTH  = 9
+	SVGCELLHEIGHT = 18
+)
+
+// HOLD is how long the last frame stays up before an export loops.
+const HOLD = 2 * time.Second
+
+// SVG renders frames as an animated SVG that loops forever. Every frame is a
+// group of its own, shown for as long as the session showed it.
+func SVG(w io.Writer, frames []record.Frame) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to export")
+	}
+	screens := make([]Screen, len(frames))
+	var width, height int
+	for i, f := range frames {
+		screens[i] = Parse(f.View)
+		fw, fh := screens[i].Size()
+		width, height = max(width, fw), max(height, fh)
+	}
+	total := span(frames)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d" font-family="ui-monospace,Menlo,Consolas,monospace" font-size="%d">`,
+		width*SVGCELLWIDTH, height*SVGCELLHEIGHT, SVGCELLHEIGHT*3/4)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`, hex(BACKGROUND))
+	for i, s := range screens {
+		from := frames[i].At - frames[0].At
+		to := total
+		if i+1 < len(frames) {
+			to = frames[i+1].At - frames[0].At
+		}
+		b.WriteString(`<g visibility="hidden">`)
+		visibility(b, from, to, total)
+		for y, row := range s {
+			backgrounds(b, row, y)
+			texts(b, row, y)
+		}
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</svg>`)
+	return b.Flush()
+}
+
+// span is how long frames take to play, the last one held for HOLD.
+func span(frames []record.Frame) time.Duration {
+	return frames[len(frames)-1].At - frames[0].At + HOLD
+}
+
+// visibility shows its group between from and to of every loop of total.
+func visibility(b *bufio.Writer, from, to, total time.Duration) {
+	at := func(d time.Duration) string {
+		return fmt.Sprintf("%.4f", float64(d)/float64(total))
+	}
+	values, times := []string{"visible"}, []string{"0"}
+	if from > 0 {
+		values, times = []string{"hidden", "visible"}, []string{"0", at(from)}
+	}
+	if to < total {
+		values, times = append(values, "hidden"), append(times, at(to))
+	}
+	fmt.Fprintf(b, `<animate attributeName="visibility" calcMode="discrete" values="%s" keyTimes="%s" dur="%.3fs" repeatCount="indefinite"/>`,
+		strings.Join(values, ";"), strings.Join(times, ";"), total.Seconds())
+}
+
+// backgrounds draws the runs of row y whose background isn't the default.
+func backgrounds(b *bufio.Writer, row []Cell, y int) {
+	for x := 0; x < len(row); {
+		_, bg := row[x].Colors()
+		end := x + 1
+		for end < len(row) {
+			if _, next := row[end].Colors(); next != bg {
+				break
+			}
+			end++
+		}
+		if bg != BACKGROUND {
+			fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+				x*SVGCELLWIDTH, y*SVGCELLHEIGHT, (end-x)*SVGCELLWIDTH, SVGCELLHEIGHT, hex(bg))
+		}
+		x = end
+	}
+}
+
+// texts draws row y as runs of the same style. Each run is stretched to its
+// cells, so glyphs line up whatever the viewer's font.
+func texts(b *bufio.Writer, row []Cell, y int) {
+	for x := 0; x < len(row); {
+		if row[x].Rune == 0 {
+			x++
+			continue
+		}
+		fg, _ := row[x].Colors()
+		bold := row[x].Bold
+		var run strings.Builder
+		end := x
+		for end < len(row) && row[end].Rune != 0 {
+			if next, _ := row[end].Colors(); next != fg || row[end].Bold != bold {
+				break
+			}
+			run.WriteRune(row[end].Rune)
+			end++
+		}
+		// A wide rune's second cell belongs to its run.
+		for end < len(row) && row[end].Rune == 0 {
+			end++
+		}
+		if text := run.String(); strings.TrimSpace(text) != "" {
+			weight := ""
+			if bold {
+				weight = ` font-weight="bold"`
+			}
+			fmt.Fprintf(b, `<text x="%d" y="%d" textLength="%d" lengthAdjust="spacingAndGlyphs" fill="%s"%s xml:space="preserve">`,
+				x*SVGCELLWIDTH, y*SVGCELLHEIGHT+SVGCELLHEIGHT*4/5, (end-x)*SVGCELLWIDTH, hex(fg), weight)
+			xml.EscapeText(b, []byte(text))
+			b.WriteString(`</text>`)
+		}
+		x = end
+	}
+}
@@ -0,0 +1,154 @@
+// Package feedback collects the messages players write to the operators
+// from the lobby, bug reports and feature requests alike. Messages are
+// stored, and forwarded to a webhook when one is configured, and each
+// player may only send a few an hour.
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/throttle"
+)
+
+const (
+	MAXLEN     = 500       // runes a message may have
+	RATELIMIT  = 3         // messages a player may send per window
+	RATEWINDOW = time.Hour // span a player's messages are counted over
+	HOOKWAIT   = 10 * time.Second
+)
+
+var (
+	ErrEmpty   = errors.New("feedback: empty message")
+	ErrLimited = errors.New("feedback: too many messages, try again later")
+)
+
+type Message struct {
+	Player string `json:"player"`
+	// Fingerprint is the player's key, empty for anonymous players.
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Text        string    `json:"message"`
+	At          time.Time `json:"at"`
+}
+
+// Store keeps messages for the operators to read.
+type Store interface {
+	Add(m Message) error
+}
+
+// MemoryStore keeps messages in memory.
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Add(m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	return nil
+}
+
+// Messages are the messages so far, the oldest first.
+func (s *MemoryStore) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Box takes players' messages in: it checks them, counts them against the
+// sender's limit, stores them and forwards them to its webhook.
+type Box struct {
+	store   Store
+	hook    string
+	client  *http.Client
+	limiter *throttle.Limiter
+}
+
+// NewBox stores messages in store and posts them to the webhook at hook,
+// if it's not empty.
+func NewBox(store Store, hook string) *Box {
+	return &Box{
+		store:   store,
+		hook:    hook,
+		client:  &http.Client{Timeout: HOOKWAIT},
+		limiter: throttle.NewLimiter(RATELIMIT, RATEWINDOW),
+	}
+}
+
+// Send takes m in, trimmed and cut to MAXLEN. Players are told apart by
+// their key, or by name when they have none.
+func (b *Box) Send(m Message) error {
+	m.Text = strings.TrimSpace(m.Text)
+	if m.Text == "" {
+		return ErrEmpty
+	}
+	if r := []rune(m.Text); len(r) > MAXLEN {
+		m.Text = string(r[:MAXLEN])
+	}
+	sender := m.Fingerprint
+	if sender == "" {
+		sender = "name:" + m.Player
+	}
+	if !b.limiter.Allow(sender, m.At) {
+		return ErrLimited
+	}
+	if err := b.store.Add(m); err != nil {
+		return err
+	}
+	if b.hook != "" {
+		// The player needn't wait on the webhook, and the message is kept
+		// whether it answers or not.
+		go func() {
+			if err := b.post(m); err != nil {
+				log.Error("Could not forward feedback", "error", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// hookBody is what the webhook gets: the message, and a one-line summary
+// under the names chat webhooks read it from, "text" for Slack and
+// "content" for Discord.
+type hookBody struct {
+	Message
+	Summary string `json:"text"`
+	Content string `json:"content"`
+}
+
+func (b *Box) post(m Message) error {
+	summary := fmt.Sprintf("Feedback from %s: %s", m.Player, m.Text)
+	data, err := json.Marshal(hookBody{Message: m, Summary: summary, Content: summary})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), HOOKWAIT)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.hook, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package feedback
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS feedback (
+	id          INTEGER PRIMARY KEY,
+	player      TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	message     TEXT NOT NULL,
+	at          INTEGER NOT NULL
+);
+`
+
+// SQLiteStore keeps messages in a SQLite database, for operators to read
+// with the sqlite3 shell.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create feedback schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Add(m Message) error {
+	_, err := s.db.Exec(`INSERT INTO feedback (player, fingerprint, message, at) VALUES (?, ?, ?, ?)`,
+		m.Player, m.Fingerprint, m.Text, m.At.UnixMilli())
+	return err
+}
@@ -0,0 +1,380 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MODE      = "classic"
+	TOPSCORES = 5 // on the game over screen
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	BirdStyle  lipgloss.Style
+	PipeStyle  lipgloss.Style
+	BoardStyle lipgloss.Style
+	ScoreStyle lipgloss.Style
+	QuitStyle  lipgloss.Style
+	BoxStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, keeps the player's runs, which is where their best
+	// score is loaded from.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	best        int // before this run
+	top         []leaderboard.Entry
+	submitted   bool
+
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell *bell.Bell
+
+	sky    *Sky
+	paused bool
+	slow   bool // see games.Env.Slow
+	run    int  // generation of the flight, so ticks of a stopped one are dropped
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type tickMsg int
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:      width,
+		Height:     height,
+		BirdStyle:  r.NewStyle().Foreground(lipgloss.Color("226")).Bold(true),
+		PipeStyle:  r.NewStyle().Foreground(lipgloss.Color("34")),
+		BoardStyle: r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		ScoreStyle: r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext binds the flight's ticks to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetScores keeps runs in store and loads the player's best from it.
+func (m *Model) SetScores(store leaderboard.Store, player, fingerprint string) {
+	m.Scores = store
+	m.Player = player
+	m.Fingerprint = fingerprint
+	if store == nil {
+		return
+	}
+	if e, ok := store.Best(m.bestFilter(), fingerprint); ok {
+		m.best = e.Score
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Restart puts the bird back on the ground, ranking the abandoned run if
+// it scored.
+func (m *Model) Restart() {
+	if m.sky != nil {
+		m.submitScore()
+		m.best = max(m.best, m.sky.score)
+	}
+	m.sky = NewSky(m.rng)
+	m.paused, m.submitted = false, false
+	m.top = nil
+	m.run++
+}
+
+// SetSlow turns slow mode on or off, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// tickDuration is how long a tick lasts, longer in slow mode.
+func (m Model) tickDuration() time.Duration {
+	if m.slow {
+		return TICK * games.SLOWDOWN
+	}
+	return TICK
+}
+
+func (m Model) tick() tea.Cmd {
+	run := m.run
+	return ui.Every(m.ctx, m.tickDuration(), func(time.Time) tea.Msg {
+		return tickMsg(run)
+	})
+}
+
+// flying reports whether the bird needs ticks.
+func (m Model) flying() bool {
+	return m.sky.flying && !m.sky.over && !m.paused
+}
+
+// resume starts ticking again, as a new flight.
+func (m *Model) resume() tea.Cmd {
+	m.run++
+	return m.tick()
+}
+
+// Pause stops the bird mid-flight, see games.Pauser.
+func (m *Model) Pause() bool {
+	if !m.flying() {
+		return false
+	}
+	m.paused = true
+	return true
+}
+
+func (m *Model) Resume() tea.Cmd {
+	m.paused = false
+	if m.flying() {
+		return m.resume()
+	}
+	return nil
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      MODE,
+		Modifiers: m.modifiers(),
+		Board:     fmt.Sprintf("%dx%d", WIDTH, HEIGHT),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+func (m Model) modifiers() string {
+	if m.slow {
+		return leaderboard.Modifiers(leaderboard.ASSISTED)
+	}
+	return leaderboard.Modifiers()
+}
+
+// bestFilter spans seasons: a best score is the player's highest ever.
+func (m Model) bestFilter() leaderboard.Filter {
+	k := m.scoreKey()
+	return leaderboard.Filter{Game: k.Game, Mode: k.Mode, Board: k.Board}
+}
+
+// submitScore records the run once, when it's over or abandoned.
+func (m *Model) submitScore() {
+	if m.Scores == nil || m.submitted || m.sky.score == 0 {
+		return
+	}
+	m.submitted = true
+	k := m.scoreKey()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Score:       m.sky.score,
+		Points:      m.sky.score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.sky
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.submitScore()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case s.over:
+			if key.Matches(msg, m.Keys.Flap) {
+				m.Restart()
+			}
+		case key.Matches(msg, m.Keys.Pause):
+			m.paused = !m.paused
+			if m.flying() {
+				return m, m.resume()
+			}
+		case m.paused:
+			// A flap mid-pause would be lost or, worse, saved up.
+		case key.Matches(msg, m.Keys.Flap):
+			took := !s.flying
+			s.Flap()
+			if took {
+				return m, m.resume()
+			}
+		}
+	case tickMsg:
+		if int(msg) != m.run || !m.flying() {
+			return m, nil
+		}
+		s.step()
+		if s.over {
+			m.Bell.Ring(bell.DEATH)
+			m.submitScore()
+			return m, nil
+		}
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+func (m Model) skyView() string {
+	s := m.sky
+	cells := grid.New[string](WIDTH, HEIGHT)
+	cells.Fill(" ")
+	for _, p := range s.pipes {
+		left := p.x.Round()
+		for x := left; x < left+PIPEWIDTH; x++ {
+			for y := 0; y < HEIGHT; y++ {
+				if y >= p.gap && y < p.gap+GAP {
+					continue
+				}
+				if pt := (grid.Point{X: x, Y: y}); cells.InBounds(pt) {
+					cells.Set(pt, m.PipeStyle.Render("█"))
+				}
+			}
+		}
+	}
+	glyph := "▶"
+	switch {
+	case s.over:
+		glyph = "✖"
+	case s.bird.Vel.Y < 0:
+		glyph = "▲"
+	case s.bird.Vel.Y > GRAVITY*4:
+		glyph = "▼"
+	}
+	b := s.bird.Pos
+	if p := (grid.Point{X: b.X.Round(), Y: min(b.Y.Round(), HEIGHT-1)}); cells.InBounds(p) {
+		cells.Set(p, m.BirdStyle.Render(glyph))
+	}
+
+	var out strings.Builder
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			out.WriteString(cells.At(grid.Point{X: x, Y: y}))
+		}
+		if y < HEIGHT-1 {
+			out.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(out.String())
+}
+
+func (m Model) header() string {
+	s := m.sky
+	return strings.Join([]string{
+		m.ScoreStyle.Render(fmt.Sprintf("Score %d", s.score)),
+		fmt.Sprintf("Best %d", max(m.best, s.score)),
+	}, " | ")
+}
+
+func (m Model) status() string {
+	switch {
+	case m.paused:
+		return fmt.Sprintf("Paused, '%s' to go on", m.Keys.Pause.Help().Key)
+	case !m.sky.flying:
+		return fmt.Sprintf("Press %s to take off and keep flapping", m.Keys.Flap.Help().Key)
+	}
+	return "Fly through the gaps"
+}
+
+func (m Model) overView() string {
+	s := m.sky
+	title := "Down in the grass"
+	if s.crashed {
+		title = "Into a pipe"
+	}
+	lines := []string{title, fmt.Sprintf("Score %d", s.score)}
+	if s.score > m.best && m.best > 0 {
+		lines = append(lines, "New best!")
+	}
+	if len(m.top) > 0 {
+		var b strings.Builder
+		b.WriteString("Top scores\n")
+		for i, e := range m.top {
+			fmt.Fprintf(&b, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(b.String(), "\n"))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to play again", m.Keys.Flap.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	if m.sky.over {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.overView(),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			m.skyView(),
+			m.QuitStyle.Render(m.status()),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,57 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	Flap    key.Binding
+	Pause   key.Binding
+	Restart key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		Flap:    key.NewBinding(key.WithKeys(" ", "enter", ui.KEYPADENTER, "up"), key.WithHelp("space", "flap")),
+		Pause:   key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pause")),
+		Restart: key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new game")),
+		Layout:  ui.LayoutKey(),
+		Help:    ui.HelpKey(),
+		Quit:    ui.QuitKeyFor(l),
+		layout:  l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Flap, k.Pause, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Flap, k.Pause},
+		{k.Restart, k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"flap":    &k.Flap,
+		"pause":   &k.Pause,
+		"restart": &k.Restart,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "flappy"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Flappy",
+	Description: "Flap through the gaps between scrolling pipes",
+	Category:    games.ARCADE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Spectating:  true,
+	Session:     10 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.SetScores(env.Scores, env.Player, env.Fingerprint)
+		m.Bell = env.Bell
+		m.SetSlow(env.Slow)
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
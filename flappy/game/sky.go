@@ -0,0 +1,128 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/physics"
+)
+
+const (
+	WIDTH  = 48
+	HEIGHT = 20
+	BIRDX  = 10 // column the bird flies in
+
+	PIPEWIDTH = 4
+	GAP       = 6  // rows between a pair of pipes
+	SPACING   = 16 // columns from one pair of pipes to the next
+	MARGIN    = 2  // rows a gap keeps from the top and bottom
+	MAXSHIFT  = 5  // rows a gap moves from the one before, so it's in reach
+
+	TICK = 40 * time.Millisecond
+)
+
+// Speeds, in cells per tick. The bird falls under a cell per tick so it
+// can't skip through a pipe's rim.
+var (
+	GRAVITY = physics.Frac(1, 20)
+	FLAP    = -physics.Frac(1, 2) // the bird's speed right after a flap
+	MAXFALL = physics.Frac(3, 4)
+	SCROLL  = physics.Frac(1, 4) // of the pipes
+)
+
+// pipe is a pair of pipes, with gap the first open row between them.
+type pipe struct {
+	x      physics.Fixed // left edge
+	gap    int
+	passed bool // scored
+}
+
+func (p pipe) boxes() [2]physics.AABB {
+	return [2]physics.AABB{
+		physics.Box(p.x, 0, physics.FromInt(PIPEWIDTH), physics.FromInt(p.gap)),
+		physics.Box(p.x, physics.FromInt(p.gap+GAP), physics.FromInt(PIPEWIDTH), physics.FromInt(HEIGHT-p.gap-GAP)),
+	}
+}
+
+// Sky is a flight: the bird and the pipes scrolling towards it. Time only
+// moves in step, one TICK at a time, and not before the first flap.
+type Sky struct {
+	bird    physics.Body
+	pipes   []pipe
+	flying  bool
+	score   int
+	over    bool
+	crashed bool // into a pipe, rather than the ground
+
+	rng *rand.Rand
+}
+
+func NewSky(rng *rand.Rand) *Sky {
+	s := &Sky{rng: rng}
+	s.bird = physics.Body{
+		Pos:  physics.V(physics.FromInt(BIRDX), physics.FromInt(HEIGHT/2)),
+		Size: physics.V(physics.ONE, physics.ONE),
+	}
+	s.pipes = []pipe{{x: physics.FromInt(WIDTH), gap: (HEIGHT - GAP) / 2}}
+	return s
+}
+
+// Flap sends the bird up, taking off on the first one.
+func (s *Sky) Flap() {
+	if s.over {
+		return
+	}
+	s.flying = true
+	s.bird.Vel.Y = FLAP
+}
+
+// step moves everything one tick.
+func (s *Sky) step() {
+	if !s.flying || s.over {
+		return
+	}
+	s.bird.Accelerate(physics.V(0, GRAVITY))
+	s.bird.Vel.Y = min(s.bird.Vel.Y, MAXFALL)
+	s.bird.Step()
+	// The sky has no lid: the bird bumps its head and falls.
+	if s.bird.Pos.Y < 0 {
+		s.bird.Pos.Y, s.bird.Vel.Y = 0, 0
+	}
+
+	kept := s.pipes[:0]
+	for _, p := range s.pipes {
+		p.x -= SCROLL
+		if !p.passed && p.x+physics.FromInt(PIPEWIDTH) <= s.bird.Pos.X {
+			p.passed = true
+			s.score++
+		}
+		if p.x+physics.FromInt(PIPEWIDTH) > 0 {
+			kept = append(kept, p)
+		}
+	}
+	s.pipes = kept
+	if last := s.pipes[len(s.pipes)-1]; last.x <= physics.FromInt(WIDTH-SPACING) {
+		s.pipes = append(s.pipes, pipe{x: last.x + physics.FromInt(SPACING), gap: s.nextGap(last.gap)})
+	}
+
+	box := s.bird.Bounds()
+	if box.Max.Y > physics.FromInt(HEIGHT) {
+		s.over = true
+		return
+	}
+	for _, p := range s.pipes {
+		for _, b := range p.boxes() {
+			if box.Overlaps(b) {
+				s.over, s.crashed = true, true
+				return
+			}
+		}
+	}
+}
+
+// nextGap is a random gap at most MAXSHIFT rows from the one before.
+func (s *Sky) nextGap(prev int) int {
+	lo := max(MARGIN, prev-MAXSHIFT)
+	hi := min(HEIGHT-GAP-MARGIN, prev+MAXSHIFT)
+	return lo + s.rng.Intn(hi-lo+1)
+}
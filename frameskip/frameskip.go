@@ -0,0 +1,149 @@
+// Package frameskip keeps a slow client from stalling its game. Frames are
+// queued instead of written inline, and when the client falls behind the
+// queued frames are dropped in favor of a full repaint of the latest state.
+package frameskip
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"io"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/lifecycle"
+)
+
+// LIMIT is how many bytes may queue up for a client before frames are
+// skipped.
+const LIMIT = 64 << 10
+
+// frameStart is how bubbletea's alt-screen renderer begins every frame.
+// Anything else (mode switches, cursor visibility) is never dropped.
+var frameStart = []byte("\x1b[H")
+
+var skipped = expvar.NewInt("frameskip.skipped")
+
+// Writer queues output for a background goroutine. Write never blocks on the
+// client.
+type Writer struct {
+	out    io.Writer
+	limit  int
+	onSkip func()
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	pending    [][]byte
+	size       int
+	closed     bool
+	repainting bool
+}
+
+// NewWriter wraps out. onSkip is called (in its own goroutine) after frames
+// were dropped and must trigger a full repaint, since the renderer only
+// sends the lines that changed since the last frame.
+func NewWriter(out io.Writer, limit int, onSkip func()) *Writer {
+	w := &Writer{out: out, limit: limit, onSkip: onSkip}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return len(p), nil
+	}
+
+	w.pending = append(w.pending, bytes.Clone(p))
+	w.size += len(p)
+	if w.size > w.limit {
+		w.skip()
+	}
+	w.cond.Signal()
+	return len(p), nil
+}
+
+// skip drops every queued frame, keeping control sequences. Callers hold mu.
+func (w *Writer) skip() {
+	kept := w.pending[:0]
+	w.size = 0
+	for _, chunk := range w.pending {
+		if bytes.HasPrefix(chunk, frameStart) {
+			skipped.Add(1)
+			continue
+		}
+		kept = append(kept, chunk)
+		w.size += len(chunk)
+	}
+	w.pending = kept
+
+	if !w.repainting {
+		w.repainting = true
+		go w.onSkip()
+	}
+}
+
+// Run writes queued output until ctx is done.
+func (w *Writer) Run(ctx context.Context) {
+	stop := context.AfterFunc(ctx, func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		w.cond.Broadcast()
+	})
+	defer stop()
+
+	for {
+		w.mu.Lock()
+		for len(w.pending) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.closed {
+			w.mu.Unlock()
+			return
+		}
+		chunk := w.pending[0]
+		w.pending = w.pending[1:]
+		w.size -= len(chunk)
+		if len(w.pending) == 0 {
+			w.repainting = false
+		}
+		w.mu.Unlock()
+
+		if _, err := w.out.Write(chunk); err != nil {
+			return
+		}
+	}
+}
+
+// ProgramHandler builds the session's program with its output going through
+// a Writer. Skipped frames are repainted by re-sending the current window
+// size, which makes the renderer forget the last frame.
+func ProgramHandler(handler bubbletea.Handler) bubbletea.ProgramHandler {
+	return func(s ssh.Session) *tea.Program {
+		m, opts := handler(s)
+		if m == nil {
+			return nil
+		}
+
+		var out io.Writer = s
+		if pty, _, ok := s.Pty(); ok && !s.EmulatedPty() {
+			out = pty.Slave
+		}
+
+		var p *tea.Program
+		w := NewWriter(out, LIMIT, func() {
+			pty, _, _ := s.Pty()
+			p.Send(tea.WindowSizeMsg{Width: pty.Window.Width, Height: pty.Window.Height})
+		})
+		lifecycle.Go(s, "frameskip", w.Run)
+
+		opts = append(opts, bubbletea.MakeOptions(s)...)
+		p = tea.NewProgram(m, append(opts, tea.WithOutput(w))...)
+		return p
+	}
+}
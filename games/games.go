@@ -0,0 +1,208 @@
+// Package games is the registry of every game the server can host. Game
+// packages register themselves from init, so importing a game is all it
+// takes to list and launch it.
+package games
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/config"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/latency"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+// Game is a playable session of a game.
+type Game interface {
+	tea.Model
+	Name() string
+	Description() string
+}
+
+// Pauser is an action game whose clock can be stopped from outside, which
+// the hub does while the player looks away. Games against other players
+// can't stop theirs, so they don't implement it.
+type Pauser interface {
+	Game
+	// Pause stops the clock and reports whether it was running: a game
+	// already paused, over or yet to start has nothing to stop.
+	Pause() bool
+	// Resume goes on from where Pause stopped, returning the command that
+	// restarts the clock, if any.
+	Resume() tea.Cmd
+}
+
+// Versus is a game against other players. The hub asks who they were as
+// it ends, so the player can report them from the lobby.
+type Versus interface {
+	Game
+	// Opponents are the other players of the current match, the computer
+	// left out.
+	Opponents() []block.Player
+}
+
+// Env is what a game needs to know about the player's connection.
+type Env struct {
+	// Ctx ends when the game should stop, usually with the session.
+	Ctx    context.Context
+	Player string
+	// Fingerprint identifies the player's SSH public key, empty when they
+	// connected without one.
+	Fingerprint string
+	Term        string
+	Width       int
+	Height      int
+	Renderer    *lipgloss.Renderer
+	Environ     []string
+	Latency     *latency.Meter    // may be nil
+	Scores      leaderboard.Store // may be nil
+	Daily       daily.Store       // may be nil
+	// Profile is the player's saved profile, kept in Profiles. Both may be
+	// nil.
+	Profile  *profile.Profile
+	Profiles profile.Store
+	// Title is the trophy title the player wears, empty for none. Seat
+	// takes it to match screens.
+	Title string
+	// Board and Tick are the server's defaults for games with a board or a
+	// fixed tick. Zero values leave the game's own.
+	Board config.Board
+	Tick  time.Duration
+	// Seed, when set, is what games with seeded runs play, so everyone
+	// given the same one plays the same run, as in a tournament.
+	Seed uint64
+	// Room is the key shared by players who gathered in a lobby room, empty
+	// otherwise. Games that take rooms seat players with the same key
+	// together.
+	Room string
+	// Location is the player's timezone, for showing the schedule in. Nil
+	// is UTC.
+	Location *time.Location
+	// Bell plays the audio cues the player turned on. It may be nil.
+	Bell *bell.Bell
+	// Slow is the slow-mode accessibility preset: action games run SLOWDOWN
+	// times slower, giving as much longer to react, and their scores rank
+	// as assisted, see leaderboard.ASSISTED. Games against other players
+	// keep their speed.
+	Slow bool
+}
+
+// Seat is the player as matchmaking sees them, for games against others
+// to keep apart the players who blocked each other.
+func (e Env) Seat() block.Player {
+	p := block.Player{Name: e.Player, Fingerprint: e.Fingerprint, Title: e.Title}
+	if e.Profile != nil {
+		p.Blocked = e.Profile.Blocked
+	}
+	return p
+}
+
+// SLOWDOWN is how many times slower action games run in slow mode.
+const SLOWDOWN = 2
+
+// Factory starts a game for env.
+type Factory func(env Env) (Game, error)
+
+type Category string
+
+const (
+	ARCADE      Category = "arcade"
+	PUZZLE      Category = "puzzle"
+	BOARD       Category = "board"
+	MULTIPLAYER Category = "multiplayer"
+)
+
+// Categories lists every category in display order.
+var Categories = []Category{ARCADE, PUZZLE, BOARD, MULTIPLAYER}
+
+// Info describes a game to the lobby.
+type Info struct {
+	ID          string
+	Title       string
+	Description string
+	Category    Category
+	MinPlayers  int
+	MaxPlayers  int
+	Spectating  bool          // whether others can watch a session
+	Delay       time.Duration // how far behind spectators watch, e.g. spectate.RANKEDDELAY for rated play
+	Rooms       bool          // whether players can gather in a room first, see Env.Room
+	Session     time.Duration // typical length of a session
+	// Next, when set, names what the game starts next on its schedule and
+	// when, e.g. an event or a new daily puzzle, for the lobby to show.
+	Next func(now time.Time) (string, time.Time)
+	// Waiting, when set, counts the players queued for an opponent, for the
+	// lobby to show.
+	Waiting func() int
+}
+
+// Players renders the player count, e.g. "1p" or "2-4p".
+func (i Info) Players() string {
+	if i.MaxPlayers <= i.MinPlayers {
+		return fmt.Sprintf("%dp", i.MinPlayers)
+	}
+	return fmt.Sprintf("%d-%dp", i.MinPlayers, i.MaxPlayers)
+}
+
+type entry struct {
+	info    Info
+	factory Factory
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]entry)
+)
+
+// Register adds a game. It panics on a duplicate ID, which is a programming
+// error caught at startup.
+func Register(info Info, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := registry[info.ID]; ok {
+		panic("games: duplicate registration of " + info.ID)
+	}
+	registry[info.ID] = entry{info: info, factory: factory}
+}
+
+func Lookup(id string) (Info, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	e, ok := registry[id]
+	return e.info, ok
+}
+
+// New starts the game registered as id.
+func New(id string, env Env) (Game, error) {
+	mu.RLock()
+	e, ok := registry[id]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no game %q", id)
+	}
+	return e.factory(env)
+}
+
+// All returns every registered game by title.
+func All() []Info {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Info, 0, len(registry))
+	for _, e := range registry {
+		all = append(all, e.info)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Title < all[j].Title })
+	return all
+}
@@ -0,0 +1,58 @@
+package games
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type manifest struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    Category `json:"category"`
+	MinPlayers  int      `json:"min_players"`
+	MaxPlayers  int      `json:"max_players"`
+	Spectating  bool     `json:"spectating"`
+	Session     string   `json:"session"` // e.g. "5m"
+}
+
+// LoadManifest reads the JSON description of a community game. The ID
+// defaults to the manifest's file name without ".json".
+func LoadManifest(path string) (Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+	var mf manifest
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return Info{}, err
+	}
+
+	if mf.ID == "" {
+		mf.ID = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	if mf.Title == "" {
+		mf.Title = mf.ID
+	}
+	var session time.Duration
+	if mf.Session != "" {
+		if session, err = time.ParseDuration(mf.Session); err != nil {
+			return Info{}, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	return Info{
+		ID:          mf.ID,
+		Title:       mf.Title,
+		Description: mf.Description,
+		Category:    mf.Category,
+		MinPlayers:  max(1, mf.MinPlayers),
+		MaxPlayers:  max(1, mf.MinPlayers, mf.MaxPlayers),
+		Spectating:  mf.Spectating,
+		Session:     session,
+	}, nil
+}
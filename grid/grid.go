@@ -0,0 +1,135 @@
+// Package grid provides 2D cell storage and the searches built on it that
+// are shared by game boards, level generators and AI opponents.
+package grid
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Point is a cell coordinate. Y grows downwards, matching terminal rows.
+type Point struct {
+	X, Y int
+}
+
+var (
+	Up    = Point{X: 0, Y: -1}
+	Down  = Point{X: 0, Y: 1}
+	Left  = Point{X: -1, Y: 0}
+	Right = Point{X: 1, Y: 0}
+
+	// Dirs4 are the orthogonal neighbor offsets.
+	Dirs4 = []Point{Up, Down, Left, Right}
+	// Dirs8 adds the diagonals to Dirs4.
+	Dirs8 = []Point{Up, Down, Left, Right, {X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: 1, Y: 1}}
+)
+
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+func (p Point) Sub(q Point) Point {
+	return Point{X: p.X - q.X, Y: p.Y - q.Y}
+}
+
+// Manhattan is the taxicab distance between two points.
+func Manhattan(a, b Point) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+// Grid is a fixed-size, row-major 2D array of cells.
+type Grid[T any] struct {
+	width  int
+	height int
+	cells  []T
+}
+
+func New[T any](width, height int) *Grid[T] {
+	return &Grid[T]{
+		width:  width,
+		height: height,
+		cells:  make([]T, width*height),
+	}
+}
+
+func (g *Grid[T]) Width() int  { return g.width }
+func (g *Grid[T]) Height() int { return g.height }
+
+func (g *Grid[T]) InBounds(p Point) bool {
+	return p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height
+}
+
+// At returns the cell at p, or the zero value when p is out of bounds.
+func (g *Grid[T]) At(p Point) T {
+	if !g.InBounds(p) {
+		var zero T
+		return zero
+	}
+	return g.cells[p.Y*g.width+p.X]
+}
+
+// Set stores v at p. Out-of-bounds writes are ignored.
+func (g *Grid[T]) Set(p Point, v T) {
+	if g.InBounds(p) {
+		g.cells[p.Y*g.width+p.X] = v
+	}
+}
+
+func (g *Grid[T]) Fill(v T) {
+	for i := range g.cells {
+		g.cells[i] = v
+	}
+}
+
+// Each calls fn for every cell in row-major order.
+func (g *Grid[T]) Each(fn func(p Point, v T)) {
+	for i, v := range g.cells {
+		fn(Point{X: i % g.width, Y: i / g.width}, v)
+	}
+}
+
+// Neighbors returns the in-bounds cells reachable from p through dirs.
+func (g *Grid[T]) Neighbors(p Point, dirs []Point) []Point {
+	out := make([]Point, 0, len(dirs))
+	for _, d := range dirs {
+		if n := p.Add(d); g.InBounds(n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (g *Grid[T]) Clone() *Grid[T] {
+	c := New[T](g.width, g.height)
+	copy(c.cells, g.cells)
+	return c
+}
+
+type encoded[T any] struct {
+	Width, Height int
+	Cells         []T
+}
+
+// MarshalJSON encodes the grid with its size, for room snapshots.
+func (g *Grid[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encoded[T]{g.width, g.height, g.cells})
+}
+
+func (g *Grid[T]) UnmarshalJSON(data []byte) error {
+	var e encoded[T]
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	if e.Width < 0 || e.Height < 0 || len(e.Cells) != e.Width*e.Height {
+		return fmt.Errorf("grid: %d cells for %dx%d", len(e.Cells), e.Width, e.Height)
+	}
+	g.width, g.height, g.cells = e.Width, e.Height, e.Cells
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,165 @@
+package grid
+
+import (
+	"slices"
+	"testing"
+)
+
+// parse builds a grid from rows of text, '#' for walls.
+func parse(rows ...string) *Grid[bool] {
+	g := New[bool](len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			g.Set(Point{X: x, Y: y}, c == '#')
+		}
+	}
+	return g
+}
+
+func open(_ Point, wall bool) bool   { return !wall }
+func opaque(_ Point, wall bool) bool { return wall }
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want []Point
+	}{
+		{"same cell", Point{2, 2}, Point{2, 2}, []Point{{2, 2}}},
+		{"neighbour", Point{0, 0}, Point{1, 0}, []Point{{0, 0}, {1, 0}}},
+		{"horizontal", Point{0, 1}, Point{3, 1}, []Point{{0, 1}, {1, 1}, {2, 1}, {3, 1}}},
+		{"vertical up", Point{1, 3}, Point{1, 0}, []Point{{1, 3}, {1, 2}, {1, 1}, {1, 0}}},
+		{"diagonal", Point{0, 0}, Point{2, 2}, []Point{{0, 0}, {1, 1}, {2, 2}}},
+		{"reversed diagonal", Point{2, 0}, Point{0, 2}, []Point{{2, 0}, {1, 1}, {0, 2}}},
+		{"shallow", Point{0, 0}, Point{4, 1}, []Point{{0, 0}, {1, 0}, {2, 1}, {3, 1}, {4, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Line(tt.a, tt.b); !slices.Equal(got, tt.want) {
+				t.Errorf("Line(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	g := parse(
+		".....",
+		"..#..",
+		".....",
+		"#....",
+	)
+	tests := []struct {
+		name string
+		a, b Point
+		want bool
+	}{
+		{"same cell", Point{1, 1}, Point{1, 1}, true},
+		{"same wall cell", Point{2, 1}, Point{2, 1}, true},
+		{"neighbour", Point{1, 1}, Point{2, 1}, true},
+		{"diagonal neighbour", Point{1, 0}, Point{2, 1}, true},
+		{"open row", Point{0, 0}, Point{4, 0}, true},
+		{"blocked row", Point{0, 1}, Point{4, 1}, false},
+		{"blocked column", Point{2, 0}, Point{2, 3}, false},
+		{"into a wall", Point{0, 1}, Point{0, 3}, true},
+		{"past a wall", Point{4, 3}, Point{1, 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.LineOfSight(tt.a, tt.b, opaque); got != tt.want {
+				t.Errorf("LineOfSight(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := g.LineOfSight(tt.b, tt.a, opaque); got != tt.want {
+				t.Errorf("LineOfSight(%v, %v) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	g := parse(
+		"..#..",
+		"..#..",
+		"###..",
+		".....",
+	)
+	tests := []struct {
+		name  string
+		start Point
+		want  int
+	}{
+		{"walled corner", Point{0, 0}, 4},
+		{"open side", Point{4, 0}, 11},
+		{"out of bounds", Point{9, 9}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.FloodFill(tt.start, open)
+			if len(got) != tt.want {
+				t.Fatalf("FloodFill(%v) reached %d cells, want %d: %v", tt.start, len(got), tt.want, got)
+			}
+			if tt.want == 0 {
+				return
+			}
+			if got[0] != tt.start {
+				t.Errorf("FloodFill(%v) starts at %v", tt.start, got[0])
+			}
+			for _, p := range got[1:] {
+				if g.At(p) {
+					t.Errorf("FloodFill(%v) went through the wall at %v", tt.start, p)
+				}
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	g := parse(
+		".....",
+		".###.",
+		"...#.",
+		"##.#.",
+		"...#.",
+	)
+	tests := []struct {
+		name        string
+		start, goal Point
+		steps       int // -1 when unreachable
+	}{
+		{"already there", Point{0, 0}, Point{0, 0}, 0},
+		{"neighbour", Point{0, 0}, Point{1, 0}, 1},
+		{"around the wall", Point{0, 2}, Point{4, 4}, 10},
+		{"dead end", Point{0, 4}, Point{2, 2}, 4},
+		{"goal is a wall", Point{0, 0}, Point{1, 1}, 2},
+		{"winding", Point{0, 0}, Point{0, 4}, 8},
+		{"out of bounds", Point{0, 0}, Point{5, 5}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := g.Path(tt.start, tt.goal, open)
+			if ok != (tt.steps >= 0) {
+				t.Fatalf("Path(%v, %v) ok = %v, want %v", tt.start, tt.goal, ok, tt.steps >= 0)
+			}
+			if !ok {
+				return
+			}
+			if len(path) != tt.steps+1 || path[0] != tt.start || path[len(path)-1] != tt.goal {
+				t.Fatalf("Path(%v, %v) = %v, want %d steps between them", tt.start, tt.goal, path, tt.steps)
+			}
+			for i := 1; i < len(path); i++ {
+				if Manhattan(path[i-1], path[i]) != 1 {
+					t.Errorf("Path(%v, %v) jumps from %v to %v", tt.start, tt.goal, path[i-1], path[i])
+				}
+			}
+		})
+	}
+
+	walled := parse(
+		"..#..",
+		"..#..",
+		"..#..",
+	)
+	if path, ok := walled.Path(Point{0, 0}, Point{4, 2}, open); ok {
+		t.Errorf("Path across the wall = %v, want none", path)
+	}
+}
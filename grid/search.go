@@ -0,0 +1,103 @@
+package grid
+
+import "container/heap"
+
+// Passable decides whether a search may step onto a cell.
+type Passable[T any] func(p Point, v T) bool
+
+// FloodFill returns every cell reachable from start through orthogonal moves
+// onto passable cells. The start cell is always included.
+func (g *Grid[T]) FloodFill(start Point, passable Passable[T]) []Point {
+	if !g.InBounds(start) {
+		return nil
+	}
+
+	seen := map[Point]bool{start: true}
+	queue := []Point{start}
+	for i := 0; i < len(queue); i++ {
+		for _, n := range g.Neighbors(queue[i], Dirs4) {
+			if !seen[n] && passable(n, g.At(n)) {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return queue
+}
+
+// Distances runs a breadth-first search from start and returns the step
+// count to every reachable cell.
+func (g *Grid[T]) Distances(start Point, passable Passable[T]) map[Point]int {
+	dist := map[Point]int{start: 0}
+	queue := []Point{start}
+	for i := 0; i < len(queue); i++ {
+		p := queue[i]
+		for _, n := range g.Neighbors(p, Dirs4) {
+			if _, ok := dist[n]; !ok && passable(n, g.At(n)) {
+				dist[n] = dist[p] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
+
+// Path finds a shortest orthogonal path from start to goal with A* and a
+// Manhattan heuristic. The path includes both ends and goal itself doesn't
+// have to be passable; ok is false when goal can't be reached.
+func (g *Grid[T]) Path(start, goal Point, passable Passable[T]) (path []Point, ok bool) {
+	if !g.InBounds(start) || !g.InBounds(goal) {
+		return nil, false
+	}
+
+	cost := map[Point]int{start: 0}
+	from := map[Point]Point{}
+	open := &frontier{{p: start, f: Manhattan(start, goal)}}
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(node).p
+		if cur == goal {
+			path = []Point{goal}
+			for p := goal; p != start; {
+				p = from[p]
+				path = append(path, p)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, true
+		}
+
+		for _, n := range g.Neighbors(cur, Dirs4) {
+			if n != goal && !passable(n, g.At(n)) {
+				continue
+			}
+			c := cost[cur] + 1
+			if old, seen := cost[n]; seen && c >= old {
+				continue
+			}
+			cost[n] = c
+			from[n] = cur
+			heap.Push(open, node{p: n, f: c + Manhattan(n, goal)})
+		}
+	}
+	return nil, false
+}
+
+type node struct {
+	p Point
+	f int
+}
+
+type frontier []node
+
+func (f frontier) Len() int           { return len(f) }
+func (f frontier) Less(i, j int) bool { return f[i].f < f[j].f }
+func (f frontier) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f *frontier) Push(x any)        { *f = append(*f, x.(node)) }
+func (f *frontier) Pop() any {
+	old := *f
+	n := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return n
+}
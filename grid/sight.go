@@ -0,0 +1,46 @@
+package grid
+
+// Line returns the cells on the Bresenham line from a to b, both included.
+func Line(a, b Point) []Point {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+
+	var line []Point
+	err := dx + dy
+	for p := a; ; {
+		line = append(line, p)
+		if p == b {
+			return line
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			p.Y += sy
+		}
+	}
+}
+
+// LineOfSight reports whether b is visible from a, i.e. no cell strictly
+// between them is opaque. A cell sees itself and its neighbours.
+func (g *Grid[T]) LineOfSight(a, b Point, opaque func(p Point, v T) bool) bool {
+	line := Line(a, b)
+	if len(line) <= 2 {
+		return true
+	}
+	for _, p := range line[1 : len(line)-1] {
+		if !g.InBounds(p) || opaque(p, g.At(p)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+const STALE = 3 * time.Second // players not heard from for this long forfeit
+
+// Duel phases
+const (
+	WAITING  = iota // for an opponent
+	SETTING         // the setter picks a word
+	GUESSING        // the other side guesses it
+	OVER
+)
+
+type duelist struct {
+	name string
+	seat block.Player
+	seen time.Time
+}
+
+// Duel has one player set the word the other guesses, swapping roles each
+// round. Like minesweeper races it has no goroutine: moves and polls
+// settle it as they come.
+type Duel struct {
+	mu     sync.Mutex
+	phase  int
+	sides  []*duelist
+	setter int
+	round  *Round
+	winner int
+	leaver int    // the side that left, or -1
+	scores [2]int // rounds won
+}
+
+var (
+	duelsMu sync.Mutex
+	waiting = make(map[string][]*Duel) // by lobby room, empty for anyone
+)
+
+// join pairs p with the first player waiting for an opponent from the same
+// lobby room who neither blocked nor was blocked by them, or waits for one.
+// Whoever waited sets the first word.
+func join(room string, p block.Player, now time.Time) (*Duel, int) {
+	duelsMu.Lock()
+	defer duelsMu.Unlock()
+
+	// Rooms come and go, so forget the duels nobody waits at anymore.
+	for k, waits := range waiting {
+		waits = slices.DeleteFunc(waits, func(d *Duel) bool {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.drop(now)
+			return d.phase != WAITING || len(d.sides) == 0
+		})
+		if len(waits) == 0 {
+			delete(waiting, k)
+		} else {
+			waiting[k] = waits
+		}
+	}
+
+	for i, d := range waiting[room] {
+		d.mu.Lock()
+		if d.phase == WAITING && len(d.sides) == 1 && !block.Apart(d.sides[0].seat, p) {
+			waiting[room] = slices.Delete(waiting[room], i, i+1)
+			d.sides = append(d.sides, &duelist{name: p.Label(), seat: p, seen: now})
+			d.phase = SETTING
+			d.mu.Unlock()
+			return d, 1
+		}
+		d.mu.Unlock()
+	}
+
+	d := &Duel{phase: WAITING, leaver: -1}
+	d.sides = []*duelist{{name: p.Label(), seat: p, seen: now}}
+	waiting[room] = append(waiting[room], d)
+	return d, 0
+}
+
+// drop removes players that went quiet while waiting, and makes them
+// forfeit once the duel is on.
+func (d *Duel) drop(now time.Time) {
+	if d.phase == WAITING {
+		d.sides = slices.DeleteFunc(d.sides, func(s *duelist) bool { return now.Sub(s.seen) > STALE })
+		return
+	}
+	if d.leaver >= 0 {
+		return
+	}
+	for i, s := range d.sides {
+		if now.Sub(s.seen) > STALE {
+			d.leaver = i
+			if d.phase != OVER {
+				d.end(1 - i)
+			}
+			return
+		}
+	}
+}
+
+func (d *Duel) end(winner int) {
+	d.phase = OVER
+	d.winner = winner
+	d.scores[winner]++
+}
+
+// set has side i, if it's the setter, pick the word, with category as a
+// hint if it isn't empty.
+func (d *Duel) set(i int, word, category string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	word = strings.ToLower(strings.TrimSpace(word))
+	if err := check(word); err != nil {
+		return err
+	}
+	if d.phase != SETTING || i != d.setter {
+		return nil
+	}
+	d.round = NewRound(word, strings.TrimSpace(category))
+	d.phase = GUESSING
+	return nil
+}
+
+// guess tries letter c for side i, if it's the guesser.
+func (d *Duel) guess(i int, c rune) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.phase != GUESSING || i == d.setter {
+		return
+	}
+	d.round.Guess(c)
+	switch {
+	case d.round.Won():
+		d.end(i)
+	case d.round.Lost():
+		d.end(d.setter)
+	}
+}
+
+// next starts another round with the roles swapped, once both are still
+// there.
+func (d *Duel) next() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.phase != OVER || d.leaver >= 0 {
+		return
+	}
+	d.setter = 1 - d.setter
+	d.round = nil
+	d.phase = SETTING
+}
+
+// opponents are the players facing side i.
+func (d *Duel) opponents(i int) []block.Player {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ps []block.Player
+	for j, s := range d.sides {
+		if j != i {
+			ps = append(ps, s.seat)
+		}
+	}
+	return ps
+}
+
+// leave forfeits side i.
+func (d *Duel) leave(i int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if i < len(d.sides) {
+		d.sides[i].seen = time.Time{}
+	}
+	d.drop(time.Now())
+}
+
+type Snapshot struct {
+	Phase  int
+	Names  []string
+	Setter int
+	Round  *Round // nil until the word is set
+	Winner int
+	Leaver int
+	Scores [2]int
+}
+
+// poll marks side i as present, settles the duel and describes it.
+func (d *Duel) poll(i int, now time.Time) (Snapshot, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i >= len(d.sides) {
+		return Snapshot{}, false
+	}
+	d.sides[i].seen = now
+	d.drop(now)
+
+	s := Snapshot{Phase: d.phase, Setter: d.setter, Winner: d.winner, Leaver: d.leaver, Scores: d.scores}
+	for _, side := range d.sides {
+		s.Names = append(s.Names, side.name)
+	}
+	if d.round != nil {
+		s.Round = d.round.clone()
+	}
+	return s, true
+}
@@ -0,0 +1,428 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// Modes
+const (
+	SOLO = iota // words come from the lists
+	DUEL        // players set each other's words
+	MODES
+)
+
+const (
+	ANY  = -1 // category index for words of every category
+	POLL = 250 * time.Millisecond
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	GallowsStyle lipgloss.Style
+	WordStyle    lipgloss.Style
+	MissStyle    lipgloss.Style
+	DimStyle     lipgloss.Style
+	ErrStyle     lipgloss.Style
+	QuitStyle    lipgloss.Style
+	BoxStyle     lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room string
+	mode int
+
+	// Solo
+	category int // into categories, or ANY
+	round    *Round
+	streak   int // words guessed in a row
+	best     int // streak this session
+
+	// Duel
+	duel   *Duel
+	side   int
+	snap   Snapshot
+	word   textinput.Model // the setter's word
+	hint   textinput.Model // and its category
+	setErr string
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player, mode int) *Model {
+	m := &Model{
+		Width:        width,
+		Height:       height,
+		GallowsStyle: r.NewStyle().Foreground(lipgloss.Color("250")),
+		WordStyle:    r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		MissStyle:    r.NewStyle().Foreground(lipgloss.Color("9")),
+		DimStyle:     r.NewStyle().Foreground(lipgloss.Color("8")),
+		ErrStyle:     r.NewStyle().Foreground(lipgloss.Color("9")),
+		QuitStyle:    r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:     DefaultKeyMap(),
+		Player:   player,
+		mode:     mode,
+		category: ANY,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:      context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	if mode == DUEL {
+		m.Keys.Next.SetHelp("enter", "next round")
+		m.Keys.Category.SetHelp("tab", "word/hint")
+		m.Join()
+	} else {
+		m.deal()
+	}
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.mode != DUEL {
+		return nil
+	}
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// deal picks a word of the category for a solo round.
+func (m *Model) deal() {
+	c := m.category
+	if c == ANY {
+		c = m.rng.Intn(len(categories))
+	}
+	words := categories[c].Words
+	m.round = NewRound(words[m.rng.Intn(len(words))], categories[c].Name)
+}
+
+// nextCategory moves on to the next category, after every single one
+// comes ANY. Skipping a word unsolved breaks the streak.
+func (m *Model) nextCategory() {
+	if !m.round.Over() {
+		m.streak = 0
+	}
+	m.category++
+	if m.category == len(categories) {
+		m.category = ANY
+	}
+	m.deal()
+}
+
+// score counts a finished solo round.
+func (m *Model) score() {
+	switch {
+	case m.round.Won():
+		m.streak++
+		m.best = max(m.best, m.streak)
+	case m.round.Lost():
+		m.streak = 0
+	}
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.duel, m.side = join(m.Room, m.Player, now)
+	m.resetInputs()
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.duel.opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	if m.mode != DUEL {
+		return
+	}
+	m.duel.leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.duel.poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	if snap.Phase == SETTING && m.snap.Phase != SETTING {
+		m.resetInputs()
+	}
+	m.snap = snap
+}
+
+func (m *Model) resetInputs() {
+	m.word = textinput.New()
+	m.word.Prompt = "Word: "
+	m.word.Placeholder = "what your opponent guesses"
+	m.word.CharLimit = MAXLETTERS * 2
+	m.word.EchoMode = textinput.EchoPassword // in case they're watched
+	m.word.EchoCharacter = '*'
+	m.word.Focus()
+	m.hint = textinput.New()
+	m.hint.Prompt = "Hint: "
+	m.hint.Placeholder = "a category, optional"
+	m.hint.CharLimit = 24
+	m.setErr = ""
+}
+
+// setting reports whether the player is the one to pick the word now.
+func (m Model) setting() bool {
+	return m.mode == DUEL && m.snap.Phase == SETTING && m.snap.Setter == m.side
+}
+
+// letter is the letter a key guesses, if it's one.
+func letter(msg tea.KeyMsg) (rune, bool) {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || msg.Alt {
+		return 0, false
+	}
+	c := unicode.ToLower(msg.Runes[0])
+	return c, c >= 'a' && c <= 'z'
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			if m.mode == DUEL {
+				m.duel.leave(m.side)
+			}
+			return m, tea.Quit
+		case m.setting():
+			return m, m.updateSetter(msg)
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case m.mode == DUEL:
+			m.updateDuel(msg)
+		default:
+			m.updateSolo(msg)
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func (m *Model) updateSolo(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.Keys.Category):
+		m.nextCategory()
+	case m.round.Over():
+		if key.Matches(msg, m.Keys.Next) {
+			m.deal()
+		}
+	default:
+		if c, ok := letter(msg); ok {
+			if _, ok := m.round.Guess(c); ok && m.round.Over() {
+				m.score()
+			}
+		}
+	}
+}
+
+func (m *Model) updateDuel(msg tea.KeyMsg) {
+	switch m.snap.Phase {
+	case GUESSING:
+		if c, ok := letter(msg); ok && m.snap.Setter != m.side {
+			m.duel.guess(m.side, c)
+			m.refresh(time.Now())
+		}
+	case OVER:
+		if !key.Matches(msg, m.Keys.Next) {
+			return
+		}
+		if m.snap.Leaver >= 0 {
+			m.Join()
+			return
+		}
+		m.duel.next()
+		m.refresh(time.Now())
+	}
+}
+
+// updateSetter types into the word and hint inputs, tab moving between
+// them, until enter sets the word.
+func (m *Model) updateSetter(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.Keys.Category):
+		if m.word.Focused() {
+			m.word.Blur()
+			return m.hint.Focus()
+		}
+		m.hint.Blur()
+		return m.word.Focus()
+	case key.Matches(msg, m.Keys.Next):
+		if err := m.duel.set(m.side, m.word.Value(), m.hint.Value()); err != nil {
+			m.setErr = err.Error()
+			return nil
+		}
+		m.refresh(time.Now())
+		return nil
+	}
+	var cmd tea.Cmd
+	if m.word.Focused() {
+		m.word, cmd = m.word.Update(msg)
+	} else {
+		m.hint, cmd = m.hint.Update(msg)
+	}
+	return cmd
+}
+
+// roundView draws the gallows next to the word and the letters missed.
+func (m Model) roundView(r *Round, reveal bool) string {
+	word := r.Masked()
+	if reveal && !r.Over() {
+		word += "\n" + m.DimStyle.Render(strings.ToUpper(r.Word))
+	}
+	lines := []string{m.WordStyle.Render(word), ""}
+	if r.Category != "" {
+		lines = append(lines, m.DimStyle.Render("Hint: "+r.Category))
+	}
+	missed := "none yet"
+	if s := r.Missed(); s != "" {
+		missed = m.MissStyle.Render(strings.ToUpper(strings.Join(strings.Split(s, ""), " ")))
+	}
+	lines = append(lines,
+		"Missed: "+missed,
+		fmt.Sprintf("%d wrong guesses left", MAXWRONG-r.Wrong()),
+	)
+	return lipgloss.JoinHorizontal(lipgloss.Center,
+		m.GallowsStyle.Render(Gallows(r.Wrong())), "    ",
+		lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m Model) soloView() string {
+	category := "Any category"
+	if m.category != ANY {
+		category = categories[m.category].Name
+	}
+	header := fmt.Sprintf("%s | Streak %d | Best %d", category, m.streak, m.best)
+	status := "Type a letter to guess"
+	switch {
+	case m.round.Won():
+		status = "You got it!"
+	case m.round.Lost():
+		status = "Hanged! The word was " + strings.ToUpper(m.round.Word)
+	}
+	if m.round.Over() {
+		status += fmt.Sprintf(". Press %s for another word", m.Keys.Next.Help().Key)
+	}
+	return lipgloss.JoinVertical(lipgloss.Center,
+		header, "",
+		m.roundView(m.round, false), "",
+		m.QuitStyle.Render(status),
+	)
+}
+
+func (m Model) duelView() string {
+	s := m.snap
+	if s.Phase == WAITING {
+		return m.BoxStyle.Render("Waiting for an opponent...")
+	}
+	other := 1 - m.side
+	header := fmt.Sprintf("%s %d - %d %s", s.Names[m.side], s.Scores[m.side], s.Scores[other], s.Names[other])
+
+	var body, status string
+	switch s.Phase {
+	case SETTING:
+		if s.Setter != m.side {
+			body = m.BoxStyle.Render(fmt.Sprintf("%s is picking a word for you...", s.Names[s.Setter]))
+			break
+		}
+		lines := []string{"Pick a word for " + s.Names[other] + " to guess", "", m.word.View(), m.hint.View()}
+		if m.setErr != "" {
+			lines = append(lines, "", m.ErrStyle.Render(m.setErr))
+		}
+		body = lipgloss.JoinVertical(lipgloss.Left, lines...)
+		status = fmt.Sprintf("%s word/hint • %s set the word", m.Keys.Category.Help().Key, m.Keys.Next.Help().Key)
+	case GUESSING:
+		body = m.roundView(s.Round, s.Setter == m.side)
+		status = "Type a letter to guess"
+		if s.Setter == m.side {
+			status = s.Names[other] + " is guessing your word"
+		}
+	case OVER:
+		if s.Round != nil {
+			body = m.roundView(s.Round, true)
+		}
+		switch {
+		case s.Leaver >= 0 && s.Leaver != m.side:
+			status = s.Names[s.Leaver] + " left. Press " + m.Keys.Next.Help().Key + " to wait for a new opponent"
+		case s.Winner == m.side:
+			status = "You win the round! Press " + m.Keys.Next.Help().Key + " to swap roles"
+		default:
+			status = s.Names[s.Winner] + " wins the round. Press " + m.Keys.Next.Help().Key + " to swap roles"
+		}
+		if s.Round != nil && s.Round.Lost() {
+			status = "The word was " + strings.ToUpper(s.Round.Word) + ". " + status
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, header, "", body, "", m.QuitStyle.Render(status))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	var body string
+	if m.mode == DUEL {
+		body = m.duelView()
+	} else {
+		body = m.soloView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, body, m.help.ShortHelpView(m.Keys.ShortHelp())),
+	)
+}
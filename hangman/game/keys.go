@@ -0,0 +1,43 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// KeyMap keeps its commands off the letters, which are guesses.
+type KeyMap struct {
+	Next     key.Binding
+	Category key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next:     key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "next word")),
+		Category: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "category")),
+		Help:     ui.HelpKey(),
+		Quit:     key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "quit")),
+	}
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Category, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Next, k.Category},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"next":     &k.Next,
+		"category": &k.Category,
+		"help":     &k.Help,
+		"quit":     &k.Quit,
+	}
+}
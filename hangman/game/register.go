@@ -0,0 +1,56 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+)
+
+const (
+	GAMENAME = "hangman"
+	DUELNAME = "hangman-duel"
+)
+
+// infos describes the game of each mode.
+var infos = [MODES]games.Info{
+	SOLO: {
+		ID:          GAMENAME,
+		Title:       "Hangman",
+		Description: "Guess the word a letter at a time before the gallows is done",
+		Category:    games.PUZZLE,
+		MinPlayers:  1,
+		MaxPlayers:  1,
+		Session:     5 * time.Minute,
+	},
+	DUEL: {
+		ID:          DUELNAME,
+		Title:       "Hangman Duel",
+		Description: "Take turns setting a word for your opponent to guess",
+		Category:    games.MULTIPLAYER,
+		MinPlayers:  2,
+		MaxPlayers:  2,
+		Rooms:       true,
+		Session:     5 * time.Minute,
+	},
+}
+
+func init() {
+	for mode, info := range infos {
+		games.Register(info, func(env games.Env) (games.Game, error) {
+			m := NewModel(env.Width, env.Height, env.Renderer, env.Seat(), mode)
+			m.SetContext(env.Ctx)
+			if env.Room != "" {
+				m.SetRoom(env.Room)
+			}
+			return m, nil
+		})
+	}
+}
+
+func (m Model) Name() string {
+	return infos[m.mode].Title
+}
+
+func (m Model) Description() string {
+	return infos[m.mode].Description
+}
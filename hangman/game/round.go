@@ -0,0 +1,116 @@
+package game
+
+import (
+	"strings"
+)
+
+const MAXWRONG = 6 // wrong guesses that complete the gallows
+
+// gallows is drawn with the part of each wrong guess in place of its
+// number, so the figure grows guess by guess.
+const gallows = `  +---+
+  |   |
+  1   |
+ 324  |
+ 5 6  |
+      |
+=========`
+
+var parts = map[rune]rune{'1': 'O', '2': '|', '3': '/', '4': '\\', '5': '/', '6': '\\'}
+
+// Gallows draws the gallows after wrong guesses.
+func Gallows(wrong int) string {
+	return strings.Map(func(c rune) rune {
+		part, ok := parts[c]
+		switch {
+		case !ok:
+			return c
+		case int(c-'0') <= wrong:
+			return part
+		}
+		return ' '
+	}, gallows)
+}
+
+// Round is a word being guessed a letter at a time.
+type Round struct {
+	Word     string
+	Category string // a hint, empty for none
+	guessed  [26]bool
+	wrong    int
+}
+
+func NewRound(word, category string) *Round {
+	return &Round{Word: word, Category: category}
+}
+
+// Guess tries letter c, reporting whether it's in the word. Guesses of
+// letters already tried, or once the round is over, are ignored, with ok
+// false.
+func (r *Round) Guess(c rune) (hit, ok bool) {
+	if c < 'a' || c > 'z' || r.guessed[c-'a'] || r.Over() {
+		return false, false
+	}
+	r.guessed[c-'a'] = true
+	if !strings.ContainsRune(r.Word, c) {
+		r.wrong++
+		return false, true
+	}
+	return true, true
+}
+
+func (r *Round) Wrong() int {
+	return r.wrong
+}
+
+// Missed lists the wrong guesses, in alphabetical order.
+func (r *Round) Missed() string {
+	var s strings.Builder
+	for i, tried := range r.guessed {
+		if c := rune('a' + i); tried && !strings.ContainsRune(r.Word, c) {
+			s.WriteRune(c)
+		}
+	}
+	return s.String()
+}
+
+// Masked is the word as the guesser sees it, letters not found yet as
+// underscores, spaced out so they can be counted. Everything shows once
+// the round is lost.
+func (r *Round) Masked() string {
+	cells := make([]string, 0, len(r.Word))
+	for _, c := range r.Word {
+		switch {
+		case c == ' ':
+			cells = append(cells, " ")
+		case gap(c), r.guessed[c-'a'], r.Lost():
+			cells = append(cells, string(c))
+		default:
+			cells = append(cells, "_")
+		}
+	}
+	return strings.ToUpper(strings.Join(cells, " "))
+}
+
+func (r *Round) Won() bool {
+	for _, c := range r.Word {
+		if !gap(c) && !r.guessed[c-'a'] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Round) Lost() bool {
+	return r.wrong >= MAXWRONG
+}
+
+func (r *Round) Over() bool {
+	return r.Won() || r.Lost()
+}
+
+// clone copies the round for another session to render.
+func (r *Round) clone() *Round {
+	c := *r
+	return &c
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Word lists are text files of a word per line, the first line a comment
+// naming the category:
+//
+//	; Animals
+//	aardvark
+//	polar bear
+//
+// Words are lowercase letters, with spaces and hyphens between them shown
+// from the start.
+//
+//go:embed words/*.txt
+var wordFiles embed.FS
+
+const (
+	MINLETTERS = 4
+	MAXLETTERS = 24
+)
+
+type Category struct {
+	Name  string
+	Words []string
+}
+
+var categories = mustLoad(wordFiles)
+
+// mustLoad reads the embedded word lists, in file name order.
+func mustLoad(fsys fs.FS) []Category {
+	paths, err := fs.Glob(fsys, "words/*.txt")
+	if err != nil {
+		panic(fmt.Sprintf("hangman: words: %v", err))
+	}
+	var cs []Category
+	for _, p := range paths {
+		f, err := fsys.Open(p)
+		if err != nil {
+			panic(fmt.Sprintf("hangman: %s: %v", p, err))
+		}
+		c := Category{Name: strings.TrimSuffix(path.Base(p), ".txt")}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if comment, ok := strings.CutPrefix(line, ";"); ok {
+				if len(c.Words) == 0 {
+					c.Name = strings.TrimSpace(comment)
+				}
+				continue
+			}
+			if line == "" {
+				continue
+			}
+			if err := check(line); err != nil {
+				panic(fmt.Sprintf("hangman: %s: %v", p, err))
+			}
+			c.Words = append(c.Words, line)
+		}
+		f.Close()
+		if err := sc.Err(); err != nil {
+			panic(fmt.Sprintf("hangman: %s: %v", p, err))
+		}
+		if len(c.Words) == 0 {
+			panic(fmt.Sprintf("hangman: %s: no words", p))
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// check reports why word can't be guessed, if it can't: it must be made of
+// lowercase letters, with single spaces or hyphens between them.
+func check(word string) error {
+	letters := 0
+	for i, c := range word {
+		switch {
+		case c >= 'a' && c <= 'z':
+			letters++
+		case (c == ' ' || c == '-') && i > 0 && i < len(word)-1 && !gap(rune(word[i-1])):
+		default:
+			return fmt.Errorf("%q: only letters, with spaces or hyphens between words", word)
+		}
+	}
+	if letters < MINLETTERS || letters > MAXLETTERS {
+		return fmt.Errorf("%q: %d letters, want %d to %d", word, letters, MINLETTERS, MAXLETTERS)
+	}
+	return nil
+}
+
+// gap reports whether c separates words, showing from the start.
+func gap(c rune) bool {
+	return c == ' ' || c == '-'
+}
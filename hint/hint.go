@@ -0,0 +1,82 @@
+// Package hint suggests safe next moves and solution steps for practice
+// modes and puzzles.
+package hint
+
+import "github.com/debemdeboas/games.debem.dev/grid"
+
+// Counter tracks how many hints a run has used. A zero Limit is unlimited.
+type Counter struct {
+	Used  int
+	Limit int
+}
+
+// Take consumes a hint, reporting false when the limit is exhausted.
+func (c *Counter) Take() bool {
+	if c.Limit > 0 && c.Used >= c.Limit {
+		return false
+	}
+	c.Used++
+	return true
+}
+
+func (c *Counter) Reset() {
+	c.Used = 0
+}
+
+// SafeMove suggests the next cell for a mover at from that wants to reach
+// target. It follows the shortest path when the cell it lands on still leaves
+// at least room cells to move around in, and otherwise falls back to the
+// neighbor with the most open space so the mover doesn't box itself in.
+func SafeMove[T any](g *grid.Grid[T], from, target grid.Point, room int, passable grid.Passable[T]) (grid.Point, bool) {
+	free := func(p grid.Point, v T) bool { return p != from && passable(p, v) }
+
+	if path, ok := g.Path(from, target, passable); ok && len(path) > 1 {
+		next := path[1]
+		if len(g.FloodFill(next, free)) >= room {
+			return next, true
+		}
+	}
+
+	best, bestSpace := from, 0
+	for _, n := range g.Neighbors(from, grid.Dirs4) {
+		if !passable(n, g.At(n)) {
+			continue
+		}
+		if space := len(g.FloodFill(n, free)); space > bestSpace {
+			best, bestSpace = n, space
+		}
+	}
+	return best, bestSpace > 0
+}
+
+// Solve runs a breadth-first search over a puzzle's state space and returns
+// the states from start to the first one satisfying done. Puzzles show
+// path[1] as their hint.
+func Solve[S comparable](start S, next func(S) []S, done func(S) bool, limit int) ([]S, bool) {
+	from := map[S]S{}
+	seen := map[S]bool{start: true}
+	queue := []S{start}
+
+	for i := 0; i < len(queue) && (limit <= 0 || i < limit); i++ {
+		s := queue[i]
+		if done(s) {
+			path := []S{s}
+			for s != start {
+				s = from[s]
+				path = append(path, s)
+			}
+			for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+				path[l], path[r] = path[r], path[l]
+			}
+			return path, true
+		}
+		for _, n := range next(s) {
+			if !seen[n] {
+				seen[n] = true
+				from[n] = s
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,59 @@
+package hint
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+func TestCounter(t *testing.T) {
+	c := Counter{Limit: 2}
+	if !c.Take() || !c.Take() || c.Take() {
+		t.Errorf("Take past a limit of 2: used %d", c.Used)
+	}
+	c.Reset()
+	if !c.Take() {
+		t.Error("Take after Reset = false")
+	}
+	unlimited := Counter{}
+	for range 100 {
+		if !unlimited.Take() {
+			t.Fatal("unlimited counter ran out")
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	// Reach 10 from 1 by doubling or adding one: 1 2 4 5 10.
+	next := func(n int) []int { return []int{n * 2, n + 1} }
+	done := func(n int) bool { return n == 10 }
+	path, ok := Solve(1, next, done, 0)
+	if !ok || !slices.Equal(path, []int{1, 2, 4, 5, 10}) {
+		t.Errorf("Solve = %v, %v, want the shortest way", path, ok)
+	}
+	if path, ok := Solve(1, next, done, 3); ok {
+		t.Errorf("Solve within 3 states = %v, want none", path)
+	}
+	if path, ok := Solve(10, next, done, 0); !ok || !slices.Equal(path, []int{10}) {
+		t.Errorf("Solve from the end = %v, %v, want just it", path, ok)
+	}
+}
+
+func TestSafeMove(t *testing.T) {
+	// T is walled off, so a mover heading for it falls back to open space.
+	//   . . . . #
+	//   . . . # T
+	//   . . . . #
+	g := grid.New[bool](5, 3)
+	for _, p := range []grid.Point{{X: 4, Y: 0}, {X: 3, Y: 1}, {X: 4, Y: 2}} {
+		g.Set(p, true)
+	}
+	open := func(_ grid.Point, wall bool) bool { return !wall }
+	if next, ok := SafeMove(g, grid.Point{X: 0, Y: 1}, grid.Point{X: 2, Y: 1}, 3, open); !ok || next != (grid.Point{X: 1, Y: 1}) {
+		t.Errorf("SafeMove towards an open target = %v, %v, want a step along the path", next, ok)
+	}
+	if _, ok := SafeMove(g, grid.Point{X: 0, Y: 1}, grid.Point{X: 4, Y: 1}, 3, open); !ok {
+		t.Error("SafeMove towards a walled-off target found no move")
+	}
+}
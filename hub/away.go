@@ -0,0 +1,114 @@
+package hub
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	RESUMEIN  = 3 * time.Second // counted down before a held game goes on
+	STORM     = 3               // resizes within STORMSPAN that hold a game
+	STORMSPAN = time.Second
+	SETTLE    = time.Second            // without resizes before the countdown starts
+	AWAYCHECK = 250 * time.Millisecond // how often a held game is checked on
+)
+
+// awayMsg checks on a game held while the player looked away.
+type awayMsg struct {
+	run int
+	at  time.Time
+}
+
+func (m *Model) awayTick() tea.Cmd {
+	run := m.run
+	return ui.Every(m.env.Ctx, AWAYCHECK, func(t time.Time) tea.Msg {
+		return awayMsg{run: run, at: t}
+	})
+}
+
+// hold pauses the game for the hub, if it's an action game that was
+// running. Holding a held game starts its wait over.
+func (m *Model) hold() tea.Cmd {
+	m.resumeAt = time.Time{}
+	if m.held {
+		return nil
+	}
+	if m.pauser == nil || !m.pauser.Pause() {
+		return nil
+	}
+	m.held = true
+	return m.awayTick()
+}
+
+// updateAway follows the terminal's focus reports and size changes while a
+// game runs, holding the game when the player tabs away or a resize storm
+// breaks, which some terminals send by the dozen while a window is dragged.
+func (m *Model) updateAway(msg tea.Msg, now time.Time) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.BlurMsg:
+		m.blurred = true
+		return m.hold()
+	case tea.FocusMsg:
+		m.blurred = false
+	case tea.WindowSizeMsg:
+		m.resizes = append(m.resizes, now)
+		for len(m.resizes) > 0 && now.Sub(m.resizes[0]) > STORMSPAN {
+			m.resizes = m.resizes[1:]
+		}
+		if len(m.resizes) >= STORM {
+			m.storm = true
+			return m.hold()
+		}
+	case tea.KeyMsg:
+		// Terminals that report focus going may not report it coming
+		// back, but a keypress means the player is here.
+		m.blurred = false
+	case awayMsg:
+		if msg.run != m.run || !m.held {
+			return nil
+		}
+		if m.storm && msg.at.Sub(m.resizes[len(m.resizes)-1]) >= SETTLE {
+			m.storm = false
+		}
+		switch {
+		case m.blurred || m.storm:
+		case m.resumeAt.IsZero():
+			m.resumeAt = msg.at.Add(RESUMEIN)
+		case !msg.at.Before(m.resumeAt):
+			m.held, m.resumeAt = false, time.Time{}
+			return tag(m.run, m.pauser.Resume())
+		}
+		return m.awayTick()
+	}
+	return nil
+}
+
+// leaveAway forgets about the game that's exiting.
+func (m *Model) leaveAway() {
+	m.pauser = nil
+	m.held, m.blurred, m.storm = false, false, false
+	m.resizes, m.resumeAt = nil, time.Time{}
+}
+
+// awayView is drawn over a held game: why it's held, or how soon it goes
+// on.
+func (m *Model) awayView() string {
+	text := "Paused while you're away"
+	switch {
+	case m.storm:
+		text = "Paused while the window resizes"
+	case !m.resumeAt.IsZero():
+		left := max(1, int((time.Until(m.resumeAt)+time.Second-1)/time.Second))
+		text = fmt.Sprintf("Resuming in %d", left)
+	}
+	return m.env.Renderer.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("15")).
+		Background(lipgloss.Color("#363636")).
+		Padding(1, 3).
+		Render(text)
+}
@@ -0,0 +1,109 @@
+package hub
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+// unblockKey lifts the block under the cursor. The arrows move it, since
+// letters go to the input.
+var unblockKey = key.NewBinding(key.WithKeys("delete"), key.WithHelp("del", "unblock"))
+
+// showBlocks lists the players blocked, under an input to block another.
+func (m *Model) showBlocks() tea.Cmd {
+	m.blocking = true
+	m.blockAt = 0
+	m.blockErr = ""
+	m.block = textinput.New()
+	m.block.Prompt = "> "
+	m.block.Placeholder = "a player's name or key fingerprint"
+	m.block.Width = max(20, min(60, m.env.Width-8))
+	return m.block.Focus()
+}
+
+// updateBlocks passes every message on to the input. Enter blocks the
+// player typed, and every change is kept in the player's profile, for the
+// games started from now on to match them with.
+func (m *Model) updateBlocks(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		p := m.env.Profile
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return tea.Quit
+		case key.Matches(msg, m.lobby.Keys.Close):
+			m.blocking = false
+			return nil
+		case msg.Type == tea.KeyUp:
+			m.blockAt = max(0, m.blockAt-1)
+			return nil
+		case msg.Type == tea.KeyDown:
+			m.blockAt = max(0, min(len(p.Blocked)-1, m.blockAt+1))
+			return nil
+		case key.Matches(msg, unblockKey):
+			if m.blockAt < len(p.Blocked) {
+				p.Blocked = slices.Delete(p.Blocked, m.blockAt, m.blockAt+1)
+				m.blockAt = max(0, min(len(p.Blocked)-1, m.blockAt))
+				profile.Save(m.env.Profiles, m.env.Fingerprint, p)
+			}
+			return nil
+		case key.Matches(msg, m.lobby.Keys.Select):
+			who := strings.TrimSpace(m.block.Value())
+			switch {
+			case who == "":
+				m.blockErr = "Type who to block first."
+			case strings.EqualFold(who, m.env.Player) || who == m.env.Fingerprint:
+				m.blockErr = "That's you!"
+			case slices.ContainsFunc(p.Blocked, func(b string) bool { return strings.EqualFold(b, who) }):
+				m.blockErr = who + " is blocked already."
+			default:
+				m.blockErr = ""
+				p.Blocked = append(p.Blocked, who)
+				m.blockAt = len(p.Blocked) - 1
+				m.block.SetValue("")
+				profile.Save(m.env.Profiles, m.env.Fingerprint, p)
+			}
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.block, cmd = m.block.Update(msg)
+	return cmd
+}
+
+func (m *Model) blocksView() string {
+	lines := []string{
+		"Blocked players",
+		"",
+		"Games never match you with the players you block, by name or by",
+		"the fingerprint of their key, nor them with you.",
+		"",
+		m.block.View(),
+	}
+	if m.blockErr != "" {
+		lines = append(lines, "", m.env.Renderer.NewStyle().Foreground(lipgloss.Color("9")).Render(m.blockErr))
+	}
+	lines = append(lines, "")
+	if len(m.env.Profile.Blocked) == 0 {
+		lines = append(lines, m.style.Render("Nobody is blocked."))
+	}
+	for i, b := range m.env.Profile.Blocked {
+		cursor := "  "
+		if i == m.blockAt {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+b)
+	}
+	if m.env.Fingerprint == "" {
+		lines = append(lines, "", m.style.Render("Connect with a key to keep them for next time."))
+	}
+	hint := fmt.Sprintf("%s block • ↑/↓ pick • %s unblock • %s back",
+		m.lobby.Keys.Select.Help().Key, unblockKey.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/art"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	breakout "github.com/debemdeboas/games.debem.dev/breakout/game"
+	chomp "github.com/debemdeboas/games.debem.dev/chomp/game"
+	"github.com/debemdeboas/games.debem.dev/config"
+	cups "github.com/debemdeboas/games.debem.dev/cups/game"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	decathlon "github.com/debemdeboas/games.debem.dev/decathlon/game"
+	"github.com/debemdeboas/games.debem.dev/export"
+	"github.com/debemdeboas/games.debem.dev/feedback"
+	"github.com/debemdeboas/games.debem.dev/frameskip"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/hub"
+	"github.com/debemdeboas/games.debem.dev/latency"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/lifecycle"
+	"github.com/debemdeboas/games.debem.dev/lobby"
+	"github.com/debemdeboas/games.debem.dev/news"
+	"github.com/debemdeboas/games.debem.dev/observe"
+	"github.com/debemdeboas/games.debem.dev/proc"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/record"
+	"github.com/debemdeboas/games.debem.dev/report"
+	sokoban "github.com/debemdeboas/games.debem.dev/sokoban/game"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/tournament"
+	trivia "github.com/debemdeboas/games.debem.dev/trivia/game"
+	"github.com/debemdeboas/games.debem.dev/trophy"
+	"github.com/debemdeboas/games.debem.dev/wasm"
+	"github.com/debemdeboas/games.debem.dev/widget"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/net/context"
+
+	// Built-in games register themselves.
+	_ "github.com/debemdeboas/games.debem.dev/2048/game"
+	_ "github.com/debemdeboas/games.debem.dev/anagram/game"
+	_ "github.com/debemdeboas/games.debem.dev/asteroids/game"
+	_ "github.com/debemdeboas/games.debem.dev/battleship/game"
+	_ "github.com/debemdeboas/games.debem.dev/blackjack/game"
+	_ "github.com/debemdeboas/games.debem.dev/checkers/game"
+	_ "github.com/debemdeboas/games.debem.dev/chess/game"
+	_ "github.com/debemdeboas/games.debem.dev/connectfour/game"
+	_ "github.com/debemdeboas/games.debem.dev/crossword/game"
+	_ "github.com/debemdeboas/games.debem.dev/escape/game"
+	_ "github.com/debemdeboas/games.debem.dev/flappy/game"
+	_ "github.com/debemdeboas/games.debem.dev/hangman/game"
+	_ "github.com/debemdeboas/games.debem.dev/idle/game"
+	_ "github.com/debemdeboas/games.debem.dev/life/game"
+	_ "github.com/debemdeboas/games.debem.dev/minesweeper/game"
+	_ "github.com/debemdeboas/games.debem.dev/pong/game"
+	_ "github.com/debemdeboas/games.debem.dev/reversi/game"
+	_ "github.com/debemdeboas/games.debem.dev/snake/duel"
+	_ "github.com/debemdeboas/games.debem.dev/snake/game"
+	_ "github.com/debemdeboas/games.debem.dev/solitaire/game"
+	_ "github.com/debemdeboas/games.debem.dev/sudoku/game"
+	tactics "github.com/debemdeboas/games.debem.dev/tactics/game"
+	_ "github.com/debemdeboas/games.debem.dev/tetris/game"
+	_ "github.com/debemdeboas/games.debem.dev/tictactoe/game"
+	_ "github.com/debemdeboas/games.debem.dev/typing/game"
+	_ "github.com/debemdeboas/games.debem.dev/yahtzee/game"
+
+	// Players pick timezones by name, whatever zone database the host
+	// has, if any.
+	_ "time/tzdata"
+)
+
+const (
+	procDir   = "community/bin"      // executables speaking the proc protocol
+	wasmDir   = "community/wasm"     // WASM modules
+	triviaDir = "community/trivia"   // extra trivia packs, one JSON file each
+	levelDir  = "community/breakout" // extra Breakout levels, one text file each
+	mazeDir   = "community/chomp"    // extra Chomp mazes, one text file each
+	packDir   = "community/sokoban"  // extra Sokoban level packs, in XSB
+
+	keptRecordings = 100
+	scoresFile     = "scores.db"
+	profilesFile   = "profiles.db"
+	feedbackFile   = "feedback.db"
+	reportsFile    = "reports.db"
+	trophiesFile   = "trophies.db"
+	dailyFile      = "daily.db"
+	tacticsFile    = "tactics.db"
+	wasmFile       = "wasm.db"
+)
+
+var (
+	recordings = record.NewMemorySink(keptRecordings)
+	cfg        config.Config
+	scores     leaderboard.Store
+	profiles   profile.Store
+	puzzles    daily.Store
+	live       = spectate.NewDirectory()
+	rooms      = lobby.NewRooms()
+	intro      art.Art
+	outro      art.Art
+	announce   *news.Feed
+	mailbox    *feedback.Box
+	desk       *report.Desk
+	calendar   *tournament.Calendar
+	trophies   *trophy.Case
+)
+
+func main() {
+	var err error
+	if cfg, err = config.Load(); err != nil {
+		log.Fatal("Could not load config", "error", err)
+	}
+	cfg.Flags(flag.CommandLine)
+	flag.Parse()
+
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatal("Invalid log level", "level", cfg.LogLevel, "error", err)
+	}
+	log.SetLevel(level)
+
+	if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+		log.Fatal("Could not create data directory", "dir", cfg.DataDir, "error", err)
+	}
+	db, err := leaderboard.OpenSQLite(cfg.Path(scoresFile))
+	if err != nil {
+		log.Fatal("Could not open leaderboard", "path", cfg.Path(scoresFile), "error", err)
+	}
+	defer db.Close()
+	scores = db
+
+	pdb, err := profile.OpenSQLite(cfg.Path(profilesFile))
+	if err != nil {
+		log.Fatal("Could not open profiles", "path", cfg.Path(profilesFile), "error", err)
+	}
+	defer pdb.Close()
+	profiles = pdb
+
+	fdb, err := feedback.OpenSQLite(cfg.Path(feedbackFile))
+	if err != nil {
+		log.Fatal("Could not open feedback", "path", cfg.Path(feedbackFile), "error", err)
+	}
+	defer fdb.Close()
+	mailbox = feedback.NewBox(fdb, cfg.FeedbackWebhook)
+
+	rdb, err := report.OpenSQLite(cfg.Path(reportsFile))
+	if err != nil {
+		log.Fatal("Could not open reports", "path", cfg.Path(reportsFile), "error", err)
+	}
+	defer rdb.Close()
+	desk = report.NewDesk(rdb)
+
+	tdb, err := trophy.OpenSQLite(cfg.Path(trophiesFile))
+	if err != nil {
+		log.Fatal("Could not open trophies", "path", cfg.Path(trophiesFile), "error", err)
+	}
+	defer tdb.Close()
+	trophies = trophy.NewCase(tdb, profiles)
+
+	ddb, err := daily.OpenSQLite(cfg.Path(dailyFile))
+	if err != nil {
+		log.Fatal("Could not open daily puzzles", "path", cfg.Path(dailyFile), "error", err)
+	}
+	defer ddb.Close()
+	puzzles = ddb
+
+	pzdb, err := tactics.OpenSQLite(cfg.Path(tacticsFile))
+	if err != nil {
+		log.Fatal("Could not open puzzle ratings", "path", cfg.Path(tacticsFile), "error", err)
+	}
+	defer pzdb.Close()
+	if err := tactics.SetRatings(pzdb); err != nil {
+		log.Error("Could not load puzzle ratings", "path", cfg.Path(tacticsFile), "error", err)
+	}
+
+	wdb, err := wasm.OpenSQLite(cfg.Path(wasmFile))
+	if err != nil {
+		log.Fatal("Could not open WASM game saves", "path", cfg.Path(wasmFile), "error", err)
+	}
+	defer wdb.Close()
+
+	if err := proc.RegisterDir(procDir, proc.DefaultLimits); err != nil {
+		log.Error("Could not load community games", "dir", procDir, "error", err)
+	}
+	decathlon.SetAdmins(cfg.Admins)
+	if err := trivia.LoadDir(triviaDir); err != nil {
+		log.Error("Could not load trivia packs", "dir", triviaDir, "error", err)
+	}
+	if err := breakout.LoadDir(levelDir); err != nil {
+		log.Error("Could not load Breakout levels", "dir", levelDir, "error", err)
+	}
+	if err := chomp.LoadDir(mazeDir); err != nil {
+		log.Error("Could not load Chomp mazes", "dir", mazeDir, "error", err)
+	}
+	if err := sokoban.LoadDir(packDir); err != nil {
+		log.Error("Could not load Sokoban packs", "dir", packDir, "error", err)
+	}
+	if err := wasm.RegisterDir(context.Background(), wasmDir, wasm.DefaultLimits, wdb); err != nil {
+		log.Error("Could not load WASM games", "dir", wasmDir, "error", err)
+	}
+	if calendar = loadTournaments(cfg.Tournaments); calendar != nil {
+		calendar.Trophies = trophies
+		cups.Register(calendar)
+		go calendar.Run(context.Background())
+	}
+	go trophies.Seasons(context.Background(), scores, gameTitle)
+	intro, outro = loadArt(cfg.Intro), loadArt(cfg.Outro)
+	if cfg.News != "" {
+		announce = news.Open(cfg.News)
+	}
+
+	s, err := wish.NewServer(
+		wish.WithAddress(net.JoinHostPort(cfg.Host, cfg.Port)),
+		wish.WithHostKeyPath(cfg.HostKey),
+		// Any key is welcome: it only identifies the player on the
+		// leaderboard. Players without one still get in, anonymously.
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
+		wish.WithMiddleware(
+			bubbletea.MiddlewareWithProgramHandler(
+				frameskip.ProgramHandler(record.Handler(teaHandler, recordings, record.EnvConsent)),
+				termenv.Ascii,
+			),
+			activeterm.Middleware(),
+			lifecycle.Middleware(),
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		log.Error("Could not start server", "error", err)
+	}
+
+	startDebugServer()
+	startAPI()
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	log.Info("Starting SSH server", "host", cfg.Host, "port", cfg.Port)
+	go func() {
+		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			log.Error("Server error", "error", err)
+			done <- nil
+		}
+	}()
+	<-done
+
+	log.Info("Shutting down SSH server")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer func() { cancel() }()
+	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		log.Error("Server shutdown error", "error", err)
+	}
+}
+
+// startAPI streams rooms and sessions to outside tools, exports recorded
+// replays and serves leaderboard widgets, if configured.
+func startAPI() {
+	if cfg.ObserverAddr == "" {
+		return
+	}
+	feed := observe.NewFeed()
+	live.Feed = feed
+	rooms.Feed = feed
+
+	mux := http.NewServeMux()
+	mux.Handle("/events", observe.Handler(feed, live.Current, rooms.Current))
+	replays := export.Handler(recordings)
+	mux.Handle("/replays", replays)
+	mux.Handle("/replays/", replays)
+	mux.Handle("/widget/", widget.Handler(trophies.Scores(scores)))
+	go func() {
+		log.Info("Starting HTTP API", "addr", cfg.ObserverAddr)
+		if err := http.ListenAndServe(cfg.ObserverAddr, mux); err != nil {
+			log.Error("HTTP API error", "error", err)
+		}
+	}()
+}
+
+func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+	pty, _, _ := s.Pty()
+
+	fp := fingerprint(s)
+	p := profile.Load(profiles, fp, s.User())
+
+	meter := latency.NewMeter()
+	lifecycle.Go(s, "latency", func(ctx context.Context) {
+		meter.Run(ctx, s, latency.INTERVAL)
+	})
+
+	renderer := bubbletea.MakeRenderer(s)
+	m := hub.New(games.Env{
+		Ctx:         lifecycle.Context(s),
+		Player:      p.Name,
+		Fingerprint: fp,
+		Term:        pty.Term,
+		Width:       pty.Window.Width,
+		Height:      pty.Window.Height,
+		Renderer:    renderer,
+		Environ:     s.Environ(),
+		Latency:     meter,
+		Scores:      trophies.Scores(scores),
+		Daily:       puzzles,
+		Profile:     p,
+		Profiles:    profiles,
+		Title:       trophies.Title(fp),
+		Board:       cfg.Board,
+		Tick:        cfg.Tick,
+		Bell:        bell.New(lifecycle.Context(s), s, p.Sounds),
+	}, live, rooms)
+	m.Links = shareLinks()
+	m.News = announce
+	m.Feedback = mailbox
+	m.Reports = desk
+	m.Moderator = fp != "" && slices.Contains(cfg.Admins, fp)
+	m.Replays = recordings
+	m.Session = s.Context().SessionID()
+	m.Tournaments = calendar
+	m.Trophies = trophies
+
+	splash := art.Wrap(m, intro, outro, pty.Window.Width, pty.Window.Height, renderer)
+	return splash, []tea.ProgramOption{tea.WithAltScreen(), tea.WithReportFocus()}
+}
+
+// loadArt reads the ANSI art file at path, if one is configured. Sessions
+// go on without art that can't be read.
+func loadArt(path string) art.Art {
+	if path == "" {
+		return nil
+	}
+	a, err := art.Load(path)
+	if err != nil {
+		log.Error("Could not load ANSI art", "path", path, "error", err)
+	}
+	return a
+}
+
+// gameTitle names the game registered as id, for the trophies of its
+// seasons, by its ID if it's gone since.
+func gameTitle(id string) string {
+	if info, ok := games.Lookup(id); ok {
+		return info.Title
+	}
+	return id
+}
+
+// loadTournaments schedules the configured tournaments, nil when there are
+// none. The server won't start with one it can't run.
+func loadTournaments(ts []config.Tournament) *tournament.Calendar {
+	if len(ts) == 0 {
+		return nil
+	}
+	var specs []tournament.Spec
+	for _, t := range ts {
+		sched, err := tournament.Parse(t.Schedule)
+		if err != nil {
+			log.Fatal("Invalid tournament schedule", "name", t.Name, "error", err)
+		}
+		info, ok := games.Lookup(t.Game)
+		if !ok {
+			log.Fatal("Unknown tournament game", "name", t.Name, "game", t.Game)
+		}
+		name := t.Name
+		if name == "" {
+			name = info.Title + " Cup"
+		}
+		specs = append(specs, tournament.Spec{
+			Name:      name,
+			Game:      t.Game,
+			Schedule:  sched,
+			Signup:    t.Signup,
+			Round:     t.Round,
+			DailySeed: t.DailySeed,
+			Webhook:   t.Webhook,
+		})
+	}
+	return tournament.NewCalendar(specs)
+}
+
+// shareLinks are the configured ways in, for players to pass on.
+func shareLinks() []hub.Link {
+	var links []hub.Link
+	if cfg.PublicAddr != "" {
+		links = append(links, hub.SSHLink(cfg.PublicAddr))
+	}
+	if cfg.WebURL != "" {
+		links = append(links, hub.WebLink(cfg.WebURL))
+	}
+	return links
+}
+
+// fingerprint identifies the key the player authenticated with, if any.
+func fingerprint(s ssh.Session) string {
+	if s.PublicKey() == nil {
+		return ""
+	}
+	return gossh.FingerprintSHA256(s.PublicKey())
+}
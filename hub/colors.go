@@ -0,0 +1,99 @@
+package hub
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// sample is a spread of colors that shows how well each profile keeps
+// them apart.
+var sample = []string{"#ff0000", "#ff8700", "#ffd700", "#5fd700", "#00afaf", "#005fff", "#8700ff", "#ff00af"}
+
+// chosenColors is the profile the player forced, empty for the detected one.
+func (m *Model) chosenColors() string {
+	if m.env.Profile == nil {
+		return ""
+	}
+	return m.env.Profile.Colors
+}
+
+// contrastRow is the high contrast toggle, after the profiles.
+var contrastRow = len(ui.ColorProfiles)
+
+func (m *Model) showColors() {
+	m.coloring = true
+	m.colorAt = max(0, slices.Index(ui.ColorProfiles, m.chosenColors()))
+}
+
+// updateColors moves between the profiles and high contrast, applying the
+// one picked right away to the lobby and every game after, and keeping it
+// in the player's profile.
+func (m *Model) updateColors(msg tea.KeyMsg) {
+	n := contrastRow + 1
+	switch {
+	case key.Matches(msg, m.lobby.Keys.Close):
+		m.coloring = false
+	case key.Matches(msg, m.lobby.Keys.Up):
+		m.colorAt = (m.colorAt + n - 1) % n
+	case key.Matches(msg, m.lobby.Keys.Down):
+		m.colorAt = (m.colorAt + 1) % n
+	case key.Matches(msg, m.lobby.Keys.Select), key.Matches(msg, toggleKey):
+		if m.colorAt == contrastRow {
+			m.contrast = !m.contrast
+		} else {
+			ui.SetColors(m.env.Renderer, ui.ColorProfiles[m.colorAt], m.detected)
+		}
+		if m.env.Profile != nil {
+			if m.colorAt != contrastRow {
+				m.env.Profile.Colors = ui.ColorProfiles[m.colorAt]
+			}
+			m.env.Profile.Contrast = m.contrast
+			profile.Save(m.env.Profiles, m.env.Fingerprint, m.env.Profile)
+		}
+	}
+}
+
+func (m *Model) colorsView() string {
+	r := m.env.Renderer
+	lines := []string{
+		"Colors",
+		"",
+		fmt.Sprintf("Your terminal was detected as %s. If colors look wrong,", strings.ToLower(ui.ColorLabel(m.detected))),
+		"pick what it supports instead.",
+		"",
+	}
+	for i, name := range ui.ColorProfiles {
+		cursor := "  "
+		if i == m.colorAt {
+			cursor = "> "
+		}
+		label := "Automatic"
+		if p, ok := ui.ColorProfile(name); ok {
+			label = ui.ColorLabel(p)
+		}
+		mark := "( )"
+		if name == m.chosenColors() {
+			mark = "(•)"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s", cursor, mark, label))
+	}
+
+	var swatch strings.Builder
+	for _, c := range sample {
+		swatch.WriteString(r.NewStyle().Background(lipgloss.Color(c)).Render("   "))
+	}
+	lines = append(lines, "", swatch.String(), "",
+		toggleView(m.colorAt == contrastRow, m.contrast, "High contrast: bold, heavier lines and the starkest colors"))
+	if m.env.Fingerprint == "" {
+		lines = append(lines, "", m.style.Render("Connect with a key to keep them for next time."))
+	}
+	hint := fmt.Sprintf("%s pick • %s back", m.lobby.Keys.Select.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
@@ -0,0 +1,143 @@
+package hub
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/lobby"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// layouts are the control presets players pick from, by their
+// ui.LAYOUTENV names, empty for the one their client sends.
+var layouts = []string{"", "qwerty", "ijkl", "azerty", "numpad"}
+
+var layoutLabels = map[string]string{
+	"":       "Automatic",
+	"qwerty": "QWERTY: arrows, WASD or HJKL",
+	"ijkl":   "One-handed: IJKL with space, and o",
+	"azerty": "AZERTY: arrows or ZQSD, esc to quit",
+	"numpad": "Numpad only: 8 4 6 2 to move, 0 and 5 to act",
+}
+
+// The toggles come after the presets.
+var (
+	mouseRow = len(layouts)
+	slowRow  = len(layouts) + 1
+)
+
+// withLayout has environ send the preset named, as if the client had set
+// ui.LAYOUTENV, which games read their keys from. Later entries win.
+func withLayout(environ []string, name string) []string {
+	if name == "" {
+		return environ
+	}
+	return append(slices.Clip(environ), ui.LAYOUTENV+"="+name)
+}
+
+// setLayout moves the lobby and the games started from now on to the
+// preset named.
+func (m *Model) setLayout(name string) {
+	m.layout = name
+	m.env.Environ = withLayout(m.environ, name)
+	l := ui.LayoutFromEnv(m.env.Environ)
+	m.lobby.Keys = lobby.KeyMapFor(l)
+	if m.hall != nil {
+		m.hall.Keys = lobby.HallKeyMapFor(l)
+	}
+	m.Keys.Quit = ui.QuitKeyFor(l)
+}
+
+// mouseCmd turns the terminal's mouse reporting on or off to match.
+func (m *Model) mouseCmd() tea.Cmd {
+	if m.mouse {
+		return tea.EnableMouseCellMotion
+	}
+	return tea.DisableMouse
+}
+
+func (m *Model) showControls() {
+	m.controlling = true
+	m.controlAt = max(0, slices.Index(layouts, m.layout))
+}
+
+// updateControls moves between the presets, and the mouse and slow mode
+// after them, switching to the one picked right away. Every change is kept
+// in the player's profile.
+func (m *Model) updateControls(msg tea.KeyMsg) tea.Cmd {
+	n := slowRow + 1
+	switch {
+	case key.Matches(msg, m.lobby.Keys.Close):
+		m.controlling = false
+	case key.Matches(msg, m.lobby.Keys.Up):
+		m.controlAt = (m.controlAt + n - 1) % n
+	case key.Matches(msg, m.lobby.Keys.Down):
+		m.controlAt = (m.controlAt + 1) % n
+	case key.Matches(msg, m.lobby.Keys.Select), key.Matches(msg, toggleKey):
+		switch m.controlAt {
+		case mouseRow:
+			m.mouse = !m.mouse
+		case slowRow:
+			m.env.Slow = !m.env.Slow
+		default:
+			m.setLayout(layouts[m.controlAt])
+		}
+		if m.env.Profile != nil {
+			m.env.Profile.Layout = m.layout
+			m.env.Profile.Mouse = m.mouse
+			m.env.Profile.Slow = m.env.Slow
+			profile.Save(m.env.Profiles, m.env.Fingerprint, m.env.Profile)
+		}
+		if m.controlAt == mouseRow {
+			return m.mouseCmd()
+		}
+	}
+	return nil
+}
+
+// toggleView draws a checkbox row, with the cursor when selected.
+func toggleView(selected, on bool, label string) string {
+	cursor := "  "
+	if selected {
+		cursor = "> "
+	}
+	box := "[ ]"
+	if on {
+		box = "[x]"
+	}
+	return fmt.Sprintf("%s%s %s", cursor, box, label)
+}
+
+func (m *Model) controlsView() string {
+	lines := []string{
+		"Controls",
+		"",
+		fmt.Sprintf("Games use %s keys. Numpad digits and arrows always move.", ui.LayoutFromEnv(m.env.Environ)),
+		"",
+	}
+	for i, name := range layouts {
+		cursor := "  "
+		if i == m.controlAt {
+			cursor = "> "
+		}
+		mark := "( )"
+		if name == m.layout {
+			mark = "(•)"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s", cursor, mark, layoutLabels[name]))
+	}
+	lines = append(lines,
+		"",
+		toggleView(m.controlAt == mouseRow, m.mouse, "Mouse: scroll and click the lobby, and the games that take it"),
+		toggleView(m.controlAt == slowRow, m.env.Slow, "Slow mode: action games at half speed, scores ranked as assisted"),
+	)
+	if m.env.Fingerprint == "" {
+		lines = append(lines, "", m.style.Render("Connect with a key to keep them for next time."))
+	}
+	hint := fmt.Sprintf("%s pick • %s back", m.lobby.Keys.Select.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
@@ -0,0 +1,86 @@
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/feedback"
+)
+
+// showFeedback asks for the player's message.
+func (m *Model) showFeedback() tea.Cmd {
+	m.writing = true
+	m.thanked = false
+	m.noteErr = ""
+	m.note = textinput.New()
+	m.note.Prompt = "> "
+	m.note.Placeholder = "a bug, an idea, a game you'd like to see..."
+	m.note.CharLimit = feedback.MAXLEN
+	m.note.Width = max(20, min(60, m.env.Width-8))
+	return m.note.Focus()
+}
+
+// updateFeedback passes every message on to the input. Enter sends what
+// was typed, and once it's in, any key goes back to the lobby.
+func (m *Model) updateFeedback(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return tea.Quit
+		case m.thanked, key.Matches(msg, m.lobby.Keys.Close):
+			m.writing = false
+			return nil
+		case key.Matches(msg, m.lobby.Keys.Select):
+			err := m.Feedback.Send(feedback.Message{
+				Player:      m.env.Player,
+				Fingerprint: m.env.Fingerprint,
+				Text:        m.note.Value(),
+				At:          time.Now(),
+			})
+			switch {
+			case errors.Is(err, feedback.ErrEmpty):
+				m.noteErr = "Write something first."
+			case errors.Is(err, feedback.ErrLimited):
+				m.noteErr = fmt.Sprintf("That's %d messages this hour already, thank you! Try again later.", feedback.RATELIMIT)
+			case err != nil:
+				m.noteErr = "Could not send it: " + err.Error()
+			default:
+				m.thanked = true
+			}
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.note, cmd = m.note.Update(msg)
+	return cmd
+}
+
+func (m *Model) feedbackView() string {
+	if m.thanked {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			"Feedback",
+			"",
+			fmt.Sprintf("Thanks, %s! Your message reached the operators.", m.env.Player),
+			"",
+			m.style.Render("any key back"),
+		)
+	}
+	lines := []string{
+		"Feedback",
+		"",
+		"Found a bug, or wish for a game? Tell the operators,",
+		"they read every message.",
+		"",
+		m.note.View(),
+	}
+	if m.noteErr != "" {
+		lines = append(lines, "", m.env.Renderer.NewStyle().Foreground(lipgloss.Color("9")).Render(m.noteErr))
+	}
+	hint := fmt.Sprintf("%s send • %s back", m.lobby.Keys.Select.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
@@ -0,0 +1,604 @@
+// Package hub is the launcher players land in on connect: it lists every
+// registered game, starts the one they pick and brings them back to the list
+// when it exits.
+package hub
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/feedback"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/lobby"
+	"github.com/debemdeboas/games.debem.dev/news"
+	"github.com/debemdeboas/games.debem.dev/quota"
+	"github.com/debemdeboas/games.debem.dev/report"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/tournament"
+	"github.com/debemdeboas/games.debem.dev/trophy"
+	"github.com/debemdeboas/games.debem.dev/ui"
+	"github.com/muesli/termenv"
+)
+
+type KeyMap struct {
+	Rooms      key.Binding
+	Live       key.Binding
+	Cast       key.Binding // in the list of live games
+	Share      key.Binding
+	Zone       key.Binding
+	Sounds     key.Binding
+	Colors     key.Binding
+	Controls   key.Binding
+	Blocks     key.Binding
+	Trophies   key.Binding
+	Report     key.Binding
+	Moderation key.Binding
+	News       key.Binding
+	Feedback   key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+}
+
+// helpKeys shows the lobby's bindings next to the hub's own.
+type helpKeys struct {
+	lobby lobby.KeyMap
+	hub   KeyMap
+}
+
+func (k helpKeys) ShortHelp() []key.Binding {
+	return append(k.lobby.ShortHelp(), k.hub.Rooms, k.hub.Live, k.hub.Share, k.hub.Zone, k.hub.Sounds, k.hub.Colors, k.hub.Controls, k.hub.Blocks, k.hub.Trophies, k.hub.Report, k.hub.Moderation, k.hub.News, k.hub.Feedback, k.hub.Help, k.hub.Quit)
+}
+
+func (k helpKeys) FullHelp() [][]key.Binding {
+	return append(k.lobby.FullHelp(), []key.Binding{k.hub.Rooms, k.hub.Live, k.hub.Share, k.hub.Zone, k.hub.Sounds, k.hub.Colors, k.hub.Controls, k.hub.Blocks, k.hub.Trophies, k.hub.Report, k.hub.Moderation, k.hub.News, k.hub.Feedback, k.hub.Help, k.hub.Quit})
+}
+
+// gameMsg carries a message produced by a game's commands, tagged with the
+// run that issued it so stragglers from a finished game are dropped.
+type gameMsg struct {
+	run int
+	msg tea.Msg
+}
+
+// gameExitMsg replaces a game's tea.Quit.
+type gameExitMsg struct {
+	run int
+}
+
+type Model struct {
+	Keys KeyMap
+	// Links are what players can share as QR codes, none to turn sharing
+	// off.
+	Links []Link
+	// News is what operators announce in the lobby, nil for nothing.
+	News *news.Feed
+	// Feedback takes what players write to the operators, nil to turn the
+	// form off.
+	Feedback *feedback.Box
+	// Reports takes the reports players file against the ones they met,
+	// nil to turn reporting off. Moderators review them in the lobby, with
+	// links to the replays kept in Replays, which may be nil.
+	Reports   *report.Desk
+	Moderator bool
+	Replays   ReplayStore
+	// Session identifies the player's SSH session, whose replay reports
+	// point at.
+	Session string
+	// Tournaments, nil for none, headline the lobby with the cups taking
+	// sign-ups, being played or just won.
+	Tournaments *tournament.Calendar
+	// Trophies, nil for none, keeps the trophies players won and the
+	// titles they wear. Players with a key pick theirs in the lobby.
+	Trophies *trophy.Case
+
+	env   games.Env
+	lobby *lobby.Model
+	help  help.Model
+	style lipgloss.Style
+
+	game   tea.Model
+	run    int
+	cancel context.CancelFunc
+	err    error
+
+	// live lists the sessions spectators can watch, this one's included
+	// while it plays a spectatable game.
+	live       *spectate.Directory
+	watching   bool // picking a session to watch
+	sessions   []spectate.Session
+	liveCursor int
+
+	// hall, when rooms are on, is where players gather before a game.
+	hall   *lobby.Hall
+	inHall bool
+
+	sharing bool // showing a link's QR code
+	shareAt int
+
+	zoning  bool // picking a timezone
+	zone    textinput.Model
+	zoneErr string
+
+	sounding bool // turning audio cues on and off
+	soundAt  int
+
+	coloring bool // forcing a color profile
+	colorAt  int
+	detected termenv.Profile // the renderer's own guess
+	contrast bool            // redrawing everything in high contrast
+
+	controlling bool // picking a control preset
+	controlAt   int
+	layout      string   // the preset picked, empty for the client's
+	mouse       bool     // whether mouse reporting is on
+	environ     []string // as the client sent it
+
+	blocking bool // editing the block list
+	block    textinput.Model
+	blockAt  int
+	blockErr string
+
+	trophying bool // looking through the trophy case
+	trophies  []trophy.Trophy
+	trophyAt  int
+
+	// The opponents of the multiplayer games played this session, the
+	// latest first, and of the one playing, for reports.
+	met        []met
+	versus     games.Versus
+	versusGame string
+	versusRoom string
+
+	reporting  bool // filing a report
+	reportStep int
+	reportAt   int
+	reported   met
+	reason     string
+	reportNote textinput.Model
+	reportErr  string
+
+	moderating bool // reviewing reports
+	queue      []report.Report
+	modAt      int
+	modErr     string
+
+	reading  bool // reading the news
+	newsAt   int  // the first line shown
+	newsSeen time.Time
+	newsFrom time.Time // what newsSeen was as the player opened the news
+
+	writing bool // writing feedback
+	note    textinput.Model
+	noteErr string
+	thanked bool // the feedback was sent
+
+	// A game is held, paused by the hub, while the player looks away, and
+	// goes on after a countdown once they're back.
+	pauser   games.Pauser // the game, if it can be held
+	held     bool
+	blurred  bool        // the terminal lost focus
+	storm    bool        // resizes keep coming
+	resizes  []time.Time // within STORMSPAN
+	resumeAt time.Time   // as the countdown ends, zero before it starts
+
+	inputAt time.Time // the player's last keypress or click
+	saver   *ui.Rain  // the screensaver, while the player is away
+}
+
+// New lists the registered games for the session, keeping lobby favorites
+// and history in the player's profile. Games are started with env, in the timezone the
+// player chose or their client sent unless env has one, and in the color
+// profile, contrast and with the controls they picked, if any. Spectatable
+// ones are listed in live. Players gather in rooms, so that they play
+// together. Live and rooms may be nil to turn spectating and rooms off.
+func New(env games.Env, live *spectate.Directory, rooms *lobby.Rooms) *Model {
+	environ := env.Environ
+	var preset string
+	var mouse bool
+	if env.Profile != nil {
+		preset, mouse, env.Slow = env.Profile.Layout, env.Profile.Mouse, env.Profile.Slow
+	}
+	env.Environ = withLayout(environ, preset)
+	layout := ui.LayoutFromEnv(env.Environ)
+	l := lobby.New(games.All(), env.Profiles, env.Fingerprint, env.Profile)
+	l.Keys = lobby.KeyMapFor(layout)
+
+	if env.Location == nil {
+		var chosen string
+		if env.Profile != nil {
+			chosen = env.Profile.Timezone
+		}
+		env.Location = clock.For(chosen, env.Environ)
+	}
+	l.Location = env.Location
+
+	detected := env.Renderer.ColorProfile()
+	var contrast bool
+	var newsSeen time.Time
+	if env.Profile != nil {
+		ui.SetColors(env.Renderer, env.Profile.Colors, detected)
+		contrast, newsSeen = env.Profile.Contrast, env.Profile.NewsSeen
+	}
+
+	style := env.Renderer.NewStyle().Foreground(lipgloss.Color("8"))
+	var hall *lobby.Hall
+	if rooms != nil {
+		hall = lobby.NewHall(env.Ctx, rooms, trophy.Label(env.Player, env.Title), games.All(), env.Renderer)
+		hall.Keys = lobby.HallKeyMapFor(layout)
+	}
+	return &Model{
+		Keys: KeyMap{
+			Rooms:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rooms")),
+			Live:       key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "watch live games")),
+			Cast:       key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "caster view")),
+			Share:      key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "share")),
+			Zone:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "timezone")),
+			Sounds:     key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "sounds")),
+			Colors:     key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "colors")),
+			Controls:   key.NewBinding(key.WithKeys("K"), key.WithHelp("K", "controls")),
+			Blocks:     key.NewBinding(key.WithKeys("B"), key.WithHelp("B", "blocked players")),
+			Trophies:   key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "trophies")),
+			Report:     key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "report a player")),
+			Moderation: key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "review reports")),
+			News:       key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "news")),
+			Feedback:   key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "feedback")),
+			Help:       ui.HelpKey(),
+			Quit:       ui.QuitKeyFor(layout),
+		},
+		env:      env,
+		lobby:    l,
+		help:     ui.NewHelp(style),
+		style:    style,
+		live:     live,
+		hall:     hall,
+		detected: detected,
+		contrast: contrast,
+		layout:   preset,
+		mouse:    mouse,
+		environ:  environ,
+		newsSeen: newsSeen,
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	m.inputAt = time.Now()
+	if m.mouse {
+		return tea.Batch(m.mouseCmd(), m.idleTick())
+	}
+	return m.idleTick()
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case gameMsg:
+		if msg.run != m.run || m.game == nil {
+			return m, nil
+		}
+		return m, m.forward(msg.msg)
+	case gameExitMsg:
+		if msg.run == m.run {
+			m.exit()
+		}
+		return m, nil
+	case idleMsg:
+		return m, m.updateIdle(time.Time(msg))
+	case awayMsg:
+		return m, m.updateAway(msg, msg.at)
+	case tea.KeyMsg, tea.MouseMsg:
+		if m.wake(time.Now()) {
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		m.env.Width, m.env.Height = msg.Width, msg.Height
+		if m.saver != nil {
+			m.saver.Resize(msg.Width, msg.Height)
+		}
+	}
+
+	if m.game != nil {
+		away := m.updateAway(msg, time.Now())
+		// A held game waits for the countdown, so a keypress can't move
+		// it on unseen.
+		if msg, ok := msg.(tea.KeyMsg); ok && m.held && msg.Type != tea.KeyCtrlC {
+			return m, away
+		}
+		return m, tea.Batch(away, m.forward(msg))
+	}
+
+	if m.inHall {
+		return m, m.updateHall(msg)
+	}
+	if m.zoning {
+		return m, m.updateZone(msg)
+	}
+	if m.writing {
+		return m, m.updateFeedback(msg)
+	}
+	if m.blocking {
+		return m, m.updateBlocks(msg)
+	}
+	if m.reporting {
+		return m, m.updateReport(msg)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && !m.lobby.Searching() {
+		switch {
+		case msg.Type == tea.KeyCtrlC, key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+			return m, nil
+		case m.watching:
+			return m, m.updateLive(msg)
+		case m.sharing:
+			m.updateShare(msg)
+			return m, nil
+		case m.sounding:
+			m.updateSounds(msg)
+			return m, nil
+		case m.coloring:
+			m.updateColors(msg)
+			return m, nil
+		case m.controlling:
+			return m, m.updateControls(msg)
+		case m.reading:
+			m.updateNews(msg)
+			return m, nil
+		case m.moderating:
+			m.updateModeration(msg)
+			return m, nil
+		case m.trophying:
+			m.updateTrophies(msg)
+			return m, nil
+		case len(m.Links) > 0 && key.Matches(msg, m.Keys.Share):
+			m.showShare()
+			return m, nil
+		case key.Matches(msg, m.Keys.Zone):
+			return m, m.showZone()
+		case m.env.Bell != nil && key.Matches(msg, m.Keys.Sounds):
+			m.showSounds()
+			return m, nil
+		case key.Matches(msg, m.Keys.Colors):
+			m.showColors()
+			return m, nil
+		case key.Matches(msg, m.Keys.Controls):
+			m.showControls()
+			return m, nil
+		case m.env.Profile != nil && key.Matches(msg, m.Keys.Blocks):
+			return m, m.showBlocks()
+		case m.canWear() && key.Matches(msg, m.Keys.Trophies):
+			m.showTrophies()
+			return m, nil
+		case m.Reports != nil && len(m.met) > 0 && key.Matches(msg, m.Keys.Report):
+			m.showReport()
+			return m, nil
+		case m.Reports != nil && m.Moderator && key.Matches(msg, m.Keys.Moderation):
+			m.showModeration()
+			return m, nil
+		case m.Feedback != nil && key.Matches(msg, m.Keys.Feedback):
+			return m, m.showFeedback()
+		case key.Matches(msg, m.Keys.News):
+			if entries := m.News.Entries(); len(entries) > 0 {
+				m.showNews(entries)
+			}
+			return m, nil
+		case m.live != nil && key.Matches(msg, m.Keys.Live):
+			m.showLive()
+			return m, nil
+		case m.hall != nil && key.Matches(msg, m.Keys.Rooms):
+			m.inHall = true
+			return m, m.hall.Open()
+		}
+	}
+
+	// Clicks only reach the list while it shows.
+	if _, ok := msg.(tea.MouseMsg); ok && (m.watching || m.sharing || m.sounding || m.coloring || m.controlling || m.reading || m.moderating || m.trophying) {
+		return m, nil
+	}
+	picked, cmd := m.lobby.Update(msg)
+	if picked != nil {
+		return m, m.launch(picked.ID, "")
+	}
+	return m, cmd
+}
+
+// updateHall passes every message on to the rooms hall, keystrokes
+// included since players type join codes there, and launches the game of
+// the room once it starts.
+func (m *Model) updateHall(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyCtrlC {
+		return tea.Quit
+	}
+	start, cmd := m.hall.Update(msg)
+	switch {
+	case start != nil:
+		m.inHall = false
+		return m.launch(start.Game.ID, start.Room)
+	case m.hall.Done():
+		m.inHall = false
+	}
+	return cmd
+}
+
+func (m *Model) forward(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.game, cmd = m.game.Update(msg)
+	return tag(m.run, cmd)
+}
+
+// launch starts a game in its own context, so its timers and processes stop
+// when it exits even though the session goes on. Players coming from a room
+// carry its key into the game.
+func (m *Model) launch(id, room string) tea.Cmd {
+	ctx, cancel := context.WithCancel(m.env.Ctx)
+	env := m.env
+	env.Ctx = ctx
+	env.Room = room
+
+	game, err := games.New(id, env)
+	if err != nil {
+		cancel()
+		m.err = err
+		return nil
+	}
+
+	var model tea.Model = game
+	if info, _ := games.Lookup(id); info.Spectating && m.live != nil {
+		b := spectate.NewBroadcast(info.Delay)
+		session := m.live.Open(info.Title, trophy.Label(env.Player, env.Title), b)
+		context.AfterFunc(ctx, func() { m.live.Close(session) })
+		model = spectate.Wrap(game, b)
+	}
+
+	m.err = nil
+	m.run++
+	m.leaveAway()
+	m.pauser, _ = game.(games.Pauser)
+	m.versus, _ = game.(games.Versus)
+	if info, ok := games.Lookup(id); ok {
+		m.versusGame = info.Title
+	}
+	m.versusRoom = room
+	m.game = quota.Wrap(ctx, id, model, quota.DefaultLimits)
+	m.cancel = cancel
+	return tag(m.run, m.game.Init())
+}
+
+func (m *Model) exit() {
+	if m.versus != nil {
+		m.meet(m.versus, m.versusGame, m.versusRoom)
+		m.versus = nil
+	}
+	m.cancel()
+	m.game = nil
+	m.leaveAway()
+	m.inputAt = time.Now()
+}
+
+const teaPackage = "github.com/charmbracelet/bubbletea"
+
+// tag wraps a game's command so its result is routed back to the game that
+// issued it, and so its tea.Quit returns to the lobby instead of ending the
+// session. Messages for bubbletea itself, like screen commands, go through
+// untouched. Commands nested in tea.Sequence can't be unwrapped, so games
+// shouldn't quit from one.
+func tag(run int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		switch msg := msg.(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return gameExitMsg{run: run}
+		case tea.BatchMsg:
+			batch := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				batch[i] = tag(run, c)
+			}
+			return batch
+		}
+		if t := reflect.TypeOf(msg); t.PkgPath() == teaPackage || t.Kind() == reflect.Pointer && t.Elem().PkgPath() == teaPackage {
+			return msg
+		}
+		return gameMsg{run: run, msg: msg}
+	}
+}
+
+// View draws the lobby or the game, in high contrast if the player turned
+// it on.
+func (m *Model) View() string {
+	if m.contrast {
+		return ui.HighContrast(m.view(), m.env.Renderer.ColorProfile())
+	}
+	return m.view()
+}
+
+func (m *Model) view() string {
+	if m.game != nil {
+		if m.held {
+			return ui.Overlay(m.game.View(), m.awayView())
+		}
+		return m.game.View()
+	}
+	if m.saver != nil {
+		return m.saver.View()
+	}
+
+	title := m.env.Renderer.NewStyle().Bold(true).Foreground(lipgloss.Color("10")).Render("games.debem.dev")
+	list := m.lobby.View()
+	switch {
+	case m.inHall:
+		list = m.hall.View()
+	case m.watching:
+		list = m.liveView()
+	case m.sharing:
+		list = m.shareView()
+	case m.zoning:
+		list = m.zoneView()
+	case m.sounding:
+		list = m.soundsView()
+	case m.coloring:
+		list = m.colorsView()
+	case m.controlling:
+		list = m.controlsView()
+	case m.reading:
+		list = m.newsView()
+	case m.writing:
+		list = m.feedbackView()
+	case m.blocking:
+		list = m.blocksView()
+	case m.reporting:
+		list = m.reportView()
+	case m.moderating:
+		list = m.moderationView()
+	case m.trophying:
+		list = m.trophiesView()
+	}
+	if !m.reading {
+		title += m.newsBadge()
+	}
+	body := []string{title, ""}
+	if lines := m.Tournaments.Headlines(time.Now(), m.env.Location); len(lines) > 0 {
+		style := m.env.Renderer.NewStyle().Foreground(lipgloss.Color("11"))
+		for _, line := range lines {
+			body = append(body, style.Render("★ "+line))
+		}
+		body = append(body, "")
+	}
+	body = append(body, list, "")
+	if m.err != nil {
+		body = append(body, m.env.Renderer.NewStyle().Foreground(lipgloss.Color("9")).Render("Could not start: "+m.err.Error()), "")
+	}
+	keys := m.Keys
+	keys.Share.SetEnabled(len(m.Links) > 0)
+	keys.Sounds.SetEnabled(m.env.Bell != nil)
+	keys.News.SetEnabled(len(m.News.Entries()) > 0)
+	keys.Feedback.SetEnabled(m.Feedback != nil)
+	keys.Blocks.SetEnabled(m.env.Profile != nil)
+	keys.Trophies.SetEnabled(m.canWear())
+	keys.Report.SetEnabled(m.Reports != nil && len(m.met) > 0)
+	keys.Moderation.SetEnabled(m.Reports != nil && m.Moderator)
+	body = append(body, m.help.View(helpKeys{lobby: m.lobby.Keys, hub: keys}))
+
+	return lipgloss.Place(
+		m.env.Width, m.env.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Left, body...),
+	)
+}
+
+// canWear reports whether the player can look through their trophies and
+// pick a title, which takes a key to keep them by.
+func (m *Model) canWear() bool {
+	return m.Trophies != nil && m.env.Profile != nil && m.env.Fingerprint != ""
+}
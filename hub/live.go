@@ -0,0 +1,76 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+)
+
+// showLive lists the sessions open to spectators.
+func (m *Model) showLive() {
+	m.watching = true
+	m.sessions = m.live.Sessions()
+	m.liveCursor = 0
+}
+
+func (m *Model) updateLive(msg tea.KeyMsg) tea.Cmd {
+	keys := m.lobby.Keys
+	switch {
+	case key.Matches(msg, keys.Close), key.Matches(msg, m.Keys.Live):
+		m.watching = false
+	case key.Matches(msg, keys.Up):
+		m.liveCursor = max(0, m.liveCursor-1)
+	case key.Matches(msg, keys.Down):
+		m.liveCursor = min(len(m.sessions)-1, m.liveCursor+1)
+	case key.Matches(msg, keys.Select), key.Matches(msg, m.Keys.Cast):
+		if len(m.sessions) == 0 {
+			m.showLive()
+			return nil
+		}
+		m.watching = false
+		return m.watch(m.sessions[m.liveCursor], key.Matches(msg, m.Keys.Cast))
+	}
+	return nil
+}
+
+// watch attaches to s read-only, in the caster view if cast. The viewer
+// takes no input but its own quit key, which brings the spectator back to
+// the lobby.
+func (m *Model) watch(s spectate.Session, cast bool) tea.Cmd {
+	ctx, cancel := context.WithCancel(m.env.Ctx)
+	s.Broadcast.Attach(ctx)
+
+	m.err = nil
+	m.run++
+	m.game = spectate.Watch(s.Broadcast)
+	if cast {
+		m.game = spectate.Cast(s.Broadcast, m.env.Renderer)
+	}
+	m.cancel = cancel
+	return tag(m.run, m.game.Init())
+}
+
+func (m *Model) liveView() string {
+	var s strings.Builder
+	s.WriteString("Live games\n\n")
+	if len(m.sessions) == 0 {
+		s.WriteString("  nobody is playing a game you can watch\n\n")
+		fmt.Fprintf(&s, "%s refresh • %s back", m.lobby.Keys.Select.Help().Key, m.lobby.Keys.Close.Help().Key)
+		return s.String()
+	}
+	for i, ls := range m.sessions {
+		cursor := "  "
+		if i == m.liveCursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&s, "%s%-16s %-12s %s, %d watching\n", cursor, ls.Game, ls.Player,
+			time.Since(ls.Started).Round(time.Second), ls.Broadcast.Spectators())
+	}
+	fmt.Fprintf(&s, "\n%s watch • %s caster view • %s back", m.lobby.Keys.Select.Help().Key, m.Keys.Cast.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return s.String()
+}
@@ -0,0 +1,134 @@
+package hub
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/news"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+// NEWSWIDTH is where news is wrapped, on terminals wide enough for it.
+const NEWSWIDTH = 64
+
+// showNews opens the news, marking what's new as it was before and every
+// entry read from now on.
+func (m *Model) showNews(entries []news.Entry) {
+	m.reading = true
+	m.newsAt = 0
+	m.newsFrom = m.newsSeen
+	updated := news.Updated(entries)
+	if !updated.After(m.newsSeen) {
+		return
+	}
+	m.newsSeen = updated
+	if m.env.Profile != nil {
+		m.env.Profile.NewsSeen = updated
+		profile.Save(m.env.Profiles, m.env.Fingerprint, m.env.Profile)
+	}
+}
+
+func (m *Model) updateNews(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.lobby.Keys.Close), key.Matches(msg, m.Keys.News):
+		m.reading = false
+	case key.Matches(msg, m.lobby.Keys.Up):
+		m.newsAt = max(0, m.newsAt-1)
+	case key.Matches(msg, m.lobby.Keys.Down):
+		// Scrolling stops once the last line shows.
+		m.newsAt = min(m.newsAt+1, max(0, len(m.newsLines())-m.newsHeight()))
+	}
+}
+
+// newsHeight is how many lines of news fit on screen.
+func (m *Model) newsHeight() int {
+	return max(5, m.env.Height-12)
+}
+
+// newsDate writes an entry's date, with the time when the heading has one.
+func newsDate(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("Jan 2, 2006")
+	}
+	return t.Format("Jan 2, 2006 15:04 UTC")
+}
+
+// newsText sets a line's **bold** and `code` spans.
+func (m *Model) newsText(text string) string {
+	var s strings.Builder
+	for _, span := range news.Spans(text) {
+		switch {
+		case span.Bold:
+			s.WriteString(m.env.Renderer.NewStyle().Bold(true).Render(span.Text))
+		case span.Code:
+			s.WriteString(m.env.Renderer.NewStyle().Foreground(lipgloss.Color("11")).Render(span.Text))
+		default:
+			s.WriteString(span.Text)
+		}
+	}
+	return s.String()
+}
+
+// newsLines are the entries, drawn to scroll through.
+func (m *Model) newsLines() []string {
+	width := max(20, min(NEWSWIDTH, m.env.Width-4))
+	wrap := m.env.Renderer.NewStyle().Width(width)
+	bullet := m.env.Renderer.NewStyle().Width(width - 2)
+	fresh := m.env.Renderer.NewStyle().Foreground(lipgloss.Color("10")).Render("● new")
+
+	var lines []string
+	for i, e := range m.News.Entries() {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		heading := m.env.Renderer.NewStyle().Bold(true).Render(e.Title) + "  " + m.style.Render(newsDate(e.Date))
+		if e.Date.After(m.newsFrom) {
+			heading += "  " + fresh
+		}
+		lines = append(lines, heading)
+		for _, l := range e.Body {
+			text := m.newsText(l.Text)
+			if l.Bullet {
+				text = lipgloss.JoinHorizontal(lipgloss.Top, "• ", bullet.Render(text))
+			} else {
+				text = wrap.Render(text)
+			}
+			lines = append(lines, strings.Split(text, "\n")...)
+		}
+	}
+	return lines
+}
+
+func (m *Model) newsView() string {
+	lines := m.newsLines()
+	height := m.newsHeight()
+	// The feed may have shrunk since the player scrolled.
+	at := max(0, min(m.newsAt, len(lines)-height))
+	shown := lines[at:min(len(lines), at+height)]
+
+	header := "News"
+	if updated := news.Updated(m.News.Entries()); !updated.IsZero() {
+		header += m.style.Render(" • last updated " + newsDate(updated))
+	}
+	hint := m.lobby.Keys.Close.Help().Key + " back"
+	if len(lines) > height {
+		hint = fmt.Sprintf("%s/%s scroll • %s", m.lobby.Keys.Up.Help().Key, m.lobby.Keys.Down.Help().Key, hint)
+	}
+	body := append([]string{header, ""}, shown...)
+	return lipgloss.JoinVertical(lipgloss.Left, append(body, "", m.style.Render(hint))...)
+}
+
+// newsBadge tells players about the news they haven't read, next to the
+// lobby's title.
+func (m *Model) newsBadge() string {
+	n := news.Unread(m.News.Entries(), m.newsSeen)
+	if n == 0 {
+		return ""
+	}
+	return m.env.Renderer.NewStyle().Foreground(lipgloss.Color("11")).
+		Render(fmt.Sprintf("  ● %d new in the news, press %s", n, m.Keys.News.Help().Key))
+}
@@ -0,0 +1,267 @@
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/record"
+	"github.com/debemdeboas/games.debem.dev/report"
+)
+
+// MET is how many of the players met this session the report screen lists,
+// the latest ones.
+const MET = 10
+
+// Steps of a report
+const (
+	PICKPLAYER = iota
+	PICKREASON
+	WRITENOTE
+	FILED
+)
+
+// ReplayStore holds the recordings reports link to, such as
+// record.MemorySink.
+type ReplayStore interface {
+	Find(session string) (*record.Recording, bool)
+}
+
+// resolveKey takes the report under the cursor off the moderation queue.
+var resolveKey = key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "resolve"))
+
+// met is a player met in a multiplayer game this session.
+type met struct {
+	block.Player
+	game string
+	room string
+}
+
+// meet remembers the opponents of the game that just ended, for the player
+// to report.
+func (m *Model) meet(g games.Versus, game, room string) {
+	for _, p := range g.Opponents() {
+		p.Blocked = nil
+		m.met = slices.DeleteFunc(m.met, func(o met) bool {
+			return o.Name == p.Name && o.Fingerprint == p.Fingerprint && o.game == game
+		})
+		m.met = append([]met{{Player: p, game: game, room: room}}, m.met...)
+	}
+	m.met = m.met[:min(len(m.met), MET)]
+}
+
+func (m *Model) showReport() {
+	m.reporting = true
+	m.reportStep = PICKPLAYER
+	m.reportAt = 0
+	m.reportErr = ""
+}
+
+// updateReport walks the player through a report: who, why and, optionally,
+// a note. Every message goes to the note's input while it's written.
+func (m *Model) updateReport(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if m.reportStep == WRITENOTE {
+			var cmd tea.Cmd
+			m.reportNote, cmd = m.reportNote.Update(msg)
+			return cmd
+		}
+		return nil
+	}
+	keys := m.lobby.Keys
+	switch {
+	case keyMsg.Type == tea.KeyCtrlC:
+		return tea.Quit
+	case m.reportStep == FILED, key.Matches(keyMsg, keys.Close):
+		m.reporting = false
+		return nil
+	case m.reportStep == WRITENOTE:
+		if key.Matches(keyMsg, keys.Select) {
+			m.fileReport()
+			return nil
+		}
+		var cmd tea.Cmd
+		m.reportNote, cmd = m.reportNote.Update(msg)
+		return cmd
+	}
+
+	n := len(m.met)
+	if m.reportStep == PICKREASON {
+		n = len(report.Reasons)
+	}
+	switch {
+	case key.Matches(keyMsg, keys.Up):
+		m.reportAt = (m.reportAt + n - 1) % n
+	case key.Matches(keyMsg, keys.Down):
+		m.reportAt = (m.reportAt + 1) % n
+	case key.Matches(keyMsg, keys.Select) && m.reportStep == PICKPLAYER:
+		m.reported = m.met[m.reportAt]
+		m.reportStep, m.reportAt = PICKREASON, 0
+	case key.Matches(keyMsg, keys.Select):
+		m.reason = report.Reasons[m.reportAt]
+		m.reportStep = WRITENOTE
+		m.reportNote = textinput.New()
+		m.reportNote.Prompt = "> "
+		m.reportNote.Placeholder = "what happened, if you'd like to say (optional)"
+		m.reportNote.CharLimit = report.MAXNOTE
+		m.reportNote.Width = max(20, min(60, m.env.Width-8))
+		return m.reportNote.Focus()
+	}
+	return nil
+}
+
+func (m *Model) fileReport() {
+	err := m.Reports.File(report.Report{
+		Reporter:            m.env.Player,
+		ReporterFingerprint: m.env.Fingerprint,
+		Player:              m.reported.Name,
+		Fingerprint:         m.reported.Fingerprint,
+		Game:                m.reported.game,
+		Room:                m.reported.room,
+		Session:             m.Session,
+		Reason:              m.reason,
+		Note:                m.reportNote.Value(),
+		At:                  time.Now(),
+	})
+	switch {
+	case errors.Is(err, report.ErrLimited):
+		m.reportErr = fmt.Sprintf("That's %d reports this hour already. Try again later.", report.RATELIMIT)
+	case err != nil:
+		m.reportErr = "Could not send it: " + err.Error()
+	default:
+		m.reportStep = FILED
+	}
+}
+
+func (m *Model) reportView() string {
+	keys := m.lobby.Keys
+	lines := []string{"Report a player", ""}
+	cursor := func(i int) string {
+		if i == m.reportAt {
+			return "> "
+		}
+		return "  "
+	}
+	switch m.reportStep {
+	case PICKPLAYER:
+		lines = append(lines, "Who from your games this session?", "")
+		for i, p := range m.met {
+			lines = append(lines, fmt.Sprintf("%s%s %s", cursor(i), p.Name, m.style.Render("in "+p.game)))
+		}
+	case PICKREASON:
+		lines = append(lines, fmt.Sprintf("What did %s do?", m.reported.Name), "")
+		for i, r := range report.Reasons {
+			lines = append(lines, cursor(i)+r)
+		}
+	case WRITENOTE:
+		lines = append(lines, fmt.Sprintf("%s: %s", m.reported.Name, m.reason), "", m.reportNote.View())
+		if m.reportErr != "" {
+			lines = append(lines, "", m.env.Renderer.NewStyle().Foreground(lipgloss.Color("9")).Render(m.reportErr))
+		}
+		hint := fmt.Sprintf("%s send • %s cancel", keys.Select.Help().Key, keys.Close.Help().Key)
+		return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+	case FILED:
+		lines = append(lines, fmt.Sprintf("Thanks. The moderators will look into %s.", m.reported.Name),
+			"Block them with "+m.Keys.Blocks.Help().Key+" so you're not matched again.", "", m.style.Render("any key back"))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+	hint := fmt.Sprintf("%s pick • %s cancel", keys.Select.Help().Key, keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
+
+// showModeration opens the queue of reports, for moderators.
+func (m *Model) showModeration() {
+	m.moderating = true
+	m.modAt = 0
+	m.modErr = ""
+	m.loadQueue()
+}
+
+func (m *Model) loadQueue() {
+	queue, err := m.Reports.Queue()
+	if err != nil {
+		m.modErr = "Could not read the queue: " + err.Error()
+	}
+	m.queue = queue
+	m.modAt = max(0, min(len(queue)-1, m.modAt))
+}
+
+// updateModeration moves through the queue and resolves reports.
+func (m *Model) updateModeration(msg tea.KeyMsg) {
+	keys := m.lobby.Keys
+	switch {
+	case key.Matches(msg, keys.Close), key.Matches(msg, m.Keys.Moderation):
+		m.moderating = false
+	case key.Matches(msg, keys.Up):
+		m.modAt = max(0, m.modAt-1)
+	case key.Matches(msg, keys.Down):
+		m.modAt = max(0, min(len(m.queue)-1, m.modAt+1))
+	case key.Matches(msg, keys.Select):
+		m.loadQueue()
+	case key.Matches(msg, resolveKey) && len(m.queue) > 0:
+		if err := m.Reports.Resolve(m.queue[m.modAt].ID); err != nil {
+			m.modErr = "Could not resolve it: " + err.Error()
+		}
+		m.loadQueue()
+	}
+}
+
+// replayLink points at the export of session's recording, if it was kept.
+func (m *Model) replayLink(session string) string {
+	if m.Replays == nil || session == "" {
+		return "none"
+	}
+	if _, ok := m.Replays.Find(session); !ok {
+		return "not recorded"
+	}
+	return "/replays/" + session + ".gif"
+}
+
+func (m *Model) moderationView() string {
+	keys := m.lobby.Keys
+	lines := []string{fmt.Sprintf("Reports (%d open)", len(m.queue)), ""}
+	if m.modErr != "" {
+		lines = append(lines, m.env.Renderer.NewStyle().Foreground(lipgloss.Color("9")).Render(m.modErr), "")
+	}
+	if len(m.queue) == 0 {
+		lines = append(lines, m.style.Render("Nothing to review."))
+	}
+	for i, r := range m.queue {
+		cursor := "  "
+		if i == m.modAt {
+			cursor = "> "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s  %s reported %s: %s", cursor,
+			r.At.In(m.env.Location).Format("Jan 2 15:04"), r.Reporter, r.Player, r.Reason))
+	}
+	if len(m.queue) > 0 {
+		r := m.queue[m.modAt]
+		fingerprint := r.Fingerprint
+		if fingerprint == "" {
+			fingerprint = "no key"
+		}
+		room := "matchmaking"
+		if r.Room != "" {
+			room = "lobby room " + strings.TrimPrefix(r.Room, "room/")
+		}
+		lines = append(lines, "",
+			fmt.Sprintf("Player  %s (%s)", r.Player, fingerprint),
+			fmt.Sprintf("Game    %s, %s", r.Game, room),
+			fmt.Sprintf("Replay  %s", m.replayLink(r.Session)),
+		)
+		if r.Note != "" {
+			lines = append(lines, fmt.Sprintf("Note    %s", r.Note))
+		}
+	}
+	hint := fmt.Sprintf("%s resolve • %s refresh • %s back", resolveKey.Help().Key, keys.Select.Help().Key, keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
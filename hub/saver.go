@@ -0,0 +1,55 @@
+package hub
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	SAVERIDLE  = 3 * time.Minute       // without input in the lobby before the screensaver starts
+	SAVERCHECK = time.Second           // how often the lobby checks for that
+	SAVERFRAME = 80 * time.Millisecond // between screensaver frames
+)
+
+// idleMsg checks whether the player went idle, and moves the screensaver
+// on while it shows.
+type idleMsg time.Time
+
+// idleTick schedules the next idleMsg, sooner while the screensaver runs.
+func (m *Model) idleTick() tea.Cmd {
+	d := SAVERCHECK
+	if m.saver != nil {
+		d = SAVERFRAME
+	}
+	return ui.Every(m.env.Ctx, d, func(t time.Time) tea.Msg {
+		return idleMsg(t)
+	})
+}
+
+// updateIdle starts the screensaver once the lobby has gone without input
+// long enough, and steps it while it runs. Games keep the screen busy
+// themselves, so it never covers one.
+func (m *Model) updateIdle(now time.Time) tea.Cmd {
+	switch {
+	case m.game != nil:
+		m.saver = nil
+	case m.saver != nil:
+		m.saver.Step()
+	case now.Sub(m.inputAt) >= SAVERIDLE:
+		m.saver = ui.NewRain(m.env.Renderer, m.env.Width, m.env.Height, now.UnixNano())
+	}
+	return m.idleTick()
+}
+
+// wake notes input from the player, ending the screensaver if it showed,
+// and reports whether it did: the keypress that wakes it does nothing else.
+func (m *Model) wake(now time.Time) bool {
+	m.inputAt = now
+	if m.saver == nil {
+		return false
+	}
+	m.saver = nil
+	return true
+}
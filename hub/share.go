@@ -0,0 +1,85 @@
+package hub
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/qr"
+)
+
+// Link is something players can share from the hub as a QR code.
+type Link struct {
+	Title string
+	Text  string // what the code is shown with, for people without a camera
+	Data  string // what the code encodes
+}
+
+// SSHLink shares the server at addr, a host with an optional port. The code
+// holds an ssh:// URL, which phone SSH clients open directly.
+func SSHLink(addr string) Link {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, "22"
+	}
+	text := "ssh " + host
+	if port != "22" {
+		text = fmt.Sprintf("ssh -p %s %s", port, host)
+	}
+	return Link{Title: "Play", Text: text, Data: "ssh://" + net.JoinHostPort(host, port)}
+}
+
+// WebLink shares the leaderboards at url.
+func WebLink(url string) Link {
+	return Link{Title: "Leaderboards", Text: url, Data: url}
+}
+
+// showShare opens the first link's code.
+func (m *Model) showShare() {
+	m.sharing = true
+	m.shareAt = 0
+}
+
+func (m *Model) updateShare(msg tea.KeyMsg) {
+	keys := m.lobby.Keys
+	switch {
+	case key.Matches(msg, keys.Close), key.Matches(msg, m.Keys.Share):
+		m.sharing = false
+	case key.Matches(msg, keys.Left), key.Matches(msg, keys.Up):
+		m.shareAt = (m.shareAt + len(m.Links) - 1) % len(m.Links)
+	case key.Matches(msg, keys.Right), key.Matches(msg, keys.Down), key.Matches(msg, keys.Select):
+		m.shareAt = (m.shareAt + 1) % len(m.Links)
+	}
+}
+
+func (m *Model) shareView() string {
+	l := m.Links[m.shareAt]
+	var tabs []string
+	for i, other := range m.Links {
+		if i == m.shareAt {
+			tabs = append(tabs, "["+other.Title+"]")
+		} else {
+			tabs = append(tabs, " "+other.Title+" ")
+		}
+	}
+
+	code, err := qr.Encode(l.Data, qr.M)
+	if err != nil {
+		return fmt.Sprintf("%s\n\n%s\n\n(too long for a QR code)", strings.Join(tabs, " "), l.Text)
+	}
+	// Scanners want dark modules on a light background, whatever the
+	// terminal's colors.
+	drawn := m.env.Renderer.NewStyle().
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color("#ffffff")).
+		Render(code.String())
+
+	hint := fmt.Sprintf("%s next • %s back", m.lobby.Keys.Right.Help().Key, m.lobby.Keys.Close.Help().Key)
+	if len(m.Links) == 1 {
+		hint = m.lobby.Keys.Close.Help().Key + " back"
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, strings.Join(tabs, " "), "", drawn, "", l.Text, "", hint)
+}
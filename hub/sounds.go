@@ -0,0 +1,70 @@
+package hub
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+// toggleKey flips the sound under the cursor, next to the lobby's select.
+var toggleKey = key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle"))
+
+func (m *Model) showSounds() {
+	m.sounding = true
+	m.soundAt = 0
+}
+
+// updateSounds moves between the events and turns their cues on and off,
+// playing a cue as it's turned on so players hear what they picked. Every
+// change is kept in the player's profile.
+func (m *Model) updateSounds(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.lobby.Keys.Close):
+		m.sounding = false
+	case key.Matches(msg, m.lobby.Keys.Up):
+		m.soundAt = (m.soundAt + len(bell.Events) - 1) % len(bell.Events)
+	case key.Matches(msg, m.lobby.Keys.Down):
+		m.soundAt = (m.soundAt + 1) % len(bell.Events)
+	case key.Matches(msg, m.lobby.Keys.Select), key.Matches(msg, toggleKey):
+		e := bell.Events[m.soundAt]
+		on := !m.env.Bell.On(e)
+		m.env.Bell.Set(e, on)
+		if on {
+			m.env.Bell.Ring(e)
+		}
+		if m.env.Profile != nil {
+			m.env.Profile.Sounds = m.env.Bell.Enabled()
+			profile.Save(m.env.Profiles, m.env.Fingerprint, m.env.Profile)
+		}
+	}
+}
+
+func (m *Model) soundsView() string {
+	lines := []string{
+		"Sounds",
+		"",
+		"Cues ring your terminal's bell, the one sound SSH carries.",
+		"Terminals may beep or flash, so every cue starts off.",
+		"",
+	}
+	for i, e := range bell.Events {
+		cursor := "  "
+		if i == m.soundAt {
+			cursor = "> "
+		}
+		box := "[ ]"
+		if m.env.Bell.On(e) {
+			box = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s", cursor, box, bell.Labels[e]))
+	}
+	if m.env.Fingerprint == "" {
+		lines = append(lines, "", m.style.Render("Connect with a key to keep them for next time."))
+	}
+	hint := fmt.Sprintf("%s toggle • %s back", toggleKey.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
@@ -0,0 +1,85 @@
+package hub
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+// setLocation shows the schedule in loc, in the lobby and the games started
+// from now on.
+func (m *Model) setLocation(loc *time.Location) {
+	m.env.Location = loc
+	m.lobby.Location = loc
+}
+
+// showZone asks for the player's timezone.
+func (m *Model) showZone() tea.Cmd {
+	m.zoning = true
+	m.zoneErr = ""
+	m.zone = textinput.New()
+	m.zone.Prompt = "> "
+	m.zone.Placeholder = "e.g. Europe/Lisbon or UTC-3"
+	if m.env.Profile != nil {
+		m.zone.SetValue(m.env.Profile.Timezone)
+	}
+	return m.zone.Focus()
+}
+
+// updateZone passes every message on to the input. Enter keeps the typed
+// zone in the player's profile, and an empty one goes back to their
+// client's.
+func (m *Model) updateZone(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return tea.Quit
+		case key.Matches(msg, m.lobby.Keys.Close):
+			m.zoning = false
+			return nil
+		case key.Matches(msg, m.lobby.Keys.Select):
+			name := m.zone.Value()
+			if name != "" {
+				if _, err := clock.Load(name); err != nil {
+					m.zoneErr = err.Error()
+					return nil
+				}
+			}
+			if m.env.Profile != nil {
+				m.env.Profile.Timezone = name
+				profile.Save(m.env.Profiles, m.env.Fingerprint, m.env.Profile)
+			}
+			m.setLocation(clock.For(name, m.env.Environ))
+			m.zoning = false
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.zone, cmd = m.zone.Update(msg)
+	return cmd
+}
+
+func (m *Model) zoneView() string {
+	lines := []string{
+		"Timezone",
+		"",
+		fmt.Sprintf("Times show in %s. Type a zone name or an offset from UTC,", m.env.Location),
+		"or leave it empty to use the TZ your client sends.",
+		"",
+		m.zone.View(),
+	}
+	if m.zoneErr != "" {
+		lines = append(lines, "", m.env.Renderer.NewStyle().Foreground(lipgloss.Color("9")).Render(m.zoneErr))
+	}
+	if m.env.Fingerprint == "" {
+		lines = append(lines, "", m.style.Render("Connect with a key to keep it for next time."))
+	}
+	hint := fmt.Sprintf("%s save • %s back", m.lobby.Keys.Select.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
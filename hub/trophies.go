@@ -0,0 +1,84 @@
+package hub
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/trophy"
+)
+
+// showTrophies lists the player's trophies under the titles they can wear,
+// the cursor on the one they chose.
+func (m *Model) showTrophies() {
+	m.trophying = true
+	m.trophies = m.Trophies.Trophies(m.env.Fingerprint)
+	m.trophyAt = max(0, slices.Index(m.titleChoices(), m.env.Profile.Title))
+}
+
+// titleChoices are what the player can wear: their best, whichever it is,
+// each title they won and none.
+func (m *Model) titleChoices() []string {
+	return append(append([]string{""}, trophy.Titles(m.trophies)...), trophy.NOTITLE)
+}
+
+// updateTrophies moves between the titles and wears the one picked, from
+// the games started from now on.
+func (m *Model) updateTrophies(msg tea.KeyMsg) {
+	choices := m.titleChoices()
+	switch {
+	case key.Matches(msg, m.lobby.Keys.Close):
+		m.trophying = false
+	case key.Matches(msg, m.lobby.Keys.Up):
+		m.trophyAt = (m.trophyAt + len(choices) - 1) % len(choices)
+	case key.Matches(msg, m.lobby.Keys.Down):
+		m.trophyAt = (m.trophyAt + 1) % len(choices)
+	case key.Matches(msg, m.lobby.Keys.Select):
+		m.env.Title = m.Trophies.Wear(m.env.Fingerprint, m.env.Profile, choices[m.trophyAt])
+		if m.hall != nil {
+			m.hall.SetPlayer(trophy.Label(m.env.Player, m.env.Title))
+		}
+	}
+}
+
+func (m *Model) trophiesView() string {
+	lines := []string{
+		"Trophy case",
+		"",
+		"Place in a tournament or finish a season on a leaderboard's podium",
+		"to win a trophy. Its title shows next to your name in the lobby, on",
+		"leaderboards and on match screens.",
+		"",
+	}
+	for i, c := range m.titleChoices() {
+		cursor := "  "
+		if i == m.trophyAt {
+			cursor = "> "
+		}
+		label := c
+		switch c {
+		case "":
+			label = "Your best"
+			if best := trophy.Worn(m.trophies, ""); best != "" {
+				label += " " + m.style.Render("("+best+")")
+			}
+		case trophy.NOTITLE:
+			label = "No title"
+		}
+		if c == m.env.Profile.Title {
+			label += m.style.Render(" • worn")
+		}
+		lines = append(lines, cursor+label)
+	}
+	lines = append(lines, "")
+	if len(m.trophies) == 0 {
+		lines = append(lines, m.style.Render("No trophies yet."))
+	}
+	for _, t := range m.trophies {
+		lines = append(lines, fmt.Sprintf("  %-4s %s", trophy.Ordinal(t.Place), t.Name))
+	}
+	hint := fmt.Sprintf("%s wear • ↑/↓ pick • %s back", m.lobby.Keys.Select.Help().Key, m.lobby.Keys.Close.Help().Key)
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, "", m.style.Render(hint))...)
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	GROWTH        = 1.15           // each generator costs this much more than the last
+	OFFLINECAP    = 24 * time.Hour // longest absence that still earns
+	OFFLINERATE   = 0.5            // share of the production earned while away
+	PRESTIGEBASE  = 1e6            // earnings for the first prestige point
+	PRESTIGEBONUS = 0.1            // production added by each prestige point
+)
+
+// Generator produces coins on its own once bought.
+type Generator struct {
+	Name string
+	Cost float64 // of the first one
+	Rate float64 // coins per second, each
+}
+
+var generators = []Generator{
+	{Name: "Intern", Cost: 15, Rate: 0.1},
+	{Name: "Script", Cost: 100, Rate: 1},
+	{Name: "Server", Cost: 1100, Rate: 8},
+	{Name: "Rack", Cost: 12000, Rate: 47},
+	{Name: "Datacenter", Cost: 130000, Rate: 260},
+	{Name: "Orbital array", Cost: 1.4e6, Rate: 1400},
+}
+
+// State is the progress kept in the player's save.
+type State struct {
+	Coins    float64   `json:"coins"`
+	Earned   float64   `json:"earned"` // over every run, drives prestige
+	Clicks   int       `json:"clicks"`
+	Owned    []int     `json:"owned"` // by generator
+	Prestige int       `json:"prestige"`
+	Saved    time.Time `json:"saved"`
+}
+
+func newState() State {
+	return State{Owned: make([]int, len(generators))}
+}
+
+// fix grows a save from an older version to cover every generator.
+func (s *State) fix() {
+	for len(s.Owned) < len(generators) {
+		s.Owned = append(s.Owned, 0)
+	}
+}
+
+func (s State) multiplier() float64 {
+	return 1 + PRESTIGEBONUS*float64(s.Prestige)
+}
+
+// Rate is the coins produced per second.
+func (s State) Rate() float64 {
+	rate := 0.0
+	for i, g := range generators {
+		rate += g.Rate * float64(s.Owned[i])
+	}
+	return rate * s.multiplier()
+}
+
+func (s State) ClickValue() float64 {
+	return s.multiplier()
+}
+
+func (s *State) earn(coins float64) {
+	s.Coins += coins
+	s.Earned += coins
+}
+
+func (s *State) Click() {
+	s.Clicks++
+	s.earn(s.ClickValue())
+}
+
+// Advance produces d worth of coins.
+func (s *State) Advance(d time.Duration) {
+	s.earn(s.Rate() * d.Seconds())
+}
+
+// Offline credits the time since the save was written, capped and at a
+// reduced rate, and returns how long that was and what it earned.
+func (s *State) Offline(now time.Time) (time.Duration, float64) {
+	if s.Saved.IsZero() || !now.After(s.Saved) {
+		return 0, 0
+	}
+	away := min(now.Sub(s.Saved), OFFLINECAP)
+	coins := s.Rate() * away.Seconds() * OFFLINERATE
+	s.earn(coins)
+	return away, coins
+}
+
+func (s State) Cost(g int) float64 {
+	return generators[g].Cost * math.Pow(GROWTH, float64(s.Owned[g]))
+}
+
+// Buy buys up to n of generator g, as many as the coins allow, and returns
+// how many it bought.
+func (s *State) Buy(g, n int) int {
+	bought := 0
+	for ; bought < n && s.Coins >= s.Cost(g); bought++ {
+		s.Coins -= s.Cost(g)
+		s.Owned[g]++
+	}
+	return bought
+}
+
+// PrestigeGain is the prestige points a reset would award now. Points grow
+// with the square root of everything ever earned, so each one costs more.
+func (s State) PrestigeGain() int {
+	return int(math.Sqrt(s.Earned/PRESTIGEBASE)) - s.Prestige
+}
+
+// Reset starts over with the prestige points the run earned, which boost
+// all production from then on.
+func (s *State) Reset() {
+	gain := s.PrestigeGain()
+	if gain <= 0 {
+		return
+	}
+	*s = State{
+		Earned:   s.Earned,
+		Clicks:   s.Clicks,
+		Owned:    make([]int, len(generators)),
+		Prestige: s.Prestige + gain,
+	}
+}
+
+var suffixes = []string{"", "K", "M", "B", "T", "Qa", "Qi", "Sx", "Sp", "Oc", "No", "Dc"}
+
+// Format shortens a number to three significant digits with a suffix, or
+// scientific notation past the last suffix.
+func Format(n float64) string {
+	if n < 1000 {
+		if n == math.Trunc(n) {
+			return fmt.Sprintf("%.0f", n)
+		}
+		return fmt.Sprintf("%.1f", n)
+	}
+	exp := int(math.Log10(n)) / 3
+	if exp >= len(suffixes) {
+		return fmt.Sprintf("%.2e", n)
+	}
+	v := n / math.Pow(1000, float64(exp))
+	switch {
+	case v >= 100:
+		return fmt.Sprintf("%.0f%s", math.Floor(v), suffixes[exp])
+	case v >= 10:
+		return fmt.Sprintf("%.1f%s", math.Floor(v*10)/10, suffixes[exp])
+	}
+	return fmt.Sprintf("%.2f%s", math.Floor(v*100)/100, suffixes[exp])
+}
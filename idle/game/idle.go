@@ -0,0 +1,280 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	TICK      = 100 * time.Millisecond
+	SAVEEVERY = 30 * time.Second
+	BUYMAX    = 1000 // generators bought at once by buy max
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TxtStyle    lipgloss.Style
+	CursorStyle lipgloss.Style
+	DimStyle    lipgloss.Style
+	CoinStyle   lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Profiles, when set, keeps the farm in the player's save, so it keeps
+	// producing between sessions.
+	Profiles    profile.Store
+	Fingerprint string
+	profile     *profile.Profile
+
+	state    State
+	last     time.Time // when production was last counted
+	saved    time.Time
+	cursor   int
+	message  string
+	confirm  bool // whether the next prestige press resets
+	showHelp bool
+
+	ctx context.Context
+}
+
+type tickMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		TxtStyle:    r.NewStyle().Foreground(lipgloss.Color("15")),
+		CursorStyle: r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220")),
+		DimStyle:    r.NewStyle().Foreground(lipgloss.Color("8")),
+		CoinStyle:   r.NewStyle().Foreground(lipgloss.Color("220")).Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Align(lipgloss.Left).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:    DefaultKeyMap(),
+		profile: &profile.Profile{},
+		state:   newState(),
+		last:    time.Now(),
+		saved:   time.Now(),
+		ctx:     context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	return m
+}
+
+// SetContext binds production ticks to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetProfile restores the farm from the player's save, crediting what it
+// produced while they were away, and saves back to store.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+
+	s := newState()
+	if ok, err := p.GetSave(GAMENAME, &s); err != nil {
+		log.Warn("Could not read idle save", "err", err)
+		s = newState()
+	} else if ok {
+		s.fix()
+	}
+	m.state = s
+
+	now := time.Now()
+	if away, coins := m.state.Offline(now); coins > 0 {
+		m.message = fmt.Sprintf("Welcome back! Your farm mined %s coins in %s", Format(coins), away.Round(time.Minute))
+	}
+	m.last = now
+	m.save(now)
+}
+
+func (m *Model) save(now time.Time) {
+	m.saved = now
+	m.state.Saved = now
+	if err := m.profile.SetSave(GAMENAME, m.state); err != nil {
+		log.Warn("Could not encode idle save", "err", err)
+		return
+	}
+	profile.Save(m.Profiles, m.Fingerprint, m.profile)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m Model) tick() tea.Cmd {
+	return ui.Every(m.ctx, TICK, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m *Model) buy(n int) {
+	cost := m.state.Cost(m.cursor)
+	if m.state.Buy(m.cursor, n) == 0 {
+		m.message = fmt.Sprintf("%s costs %s coins", generators[m.cursor].Name, Format(cost))
+		return
+	}
+	m.message = ""
+}
+
+// prestige asks for confirmation before resetting the farm.
+func (m *Model) prestige() {
+	gain := m.state.PrestigeGain()
+	switch {
+	case gain <= 0:
+		next := math.Pow(float64(m.state.Prestige+1), 2) * PRESTIGEBASE
+		m.message = fmt.Sprintf("Mine %s more coins for a prestige point", Format(next-m.state.Earned))
+	case !m.confirm:
+		m.confirm = true
+		m.message = fmt.Sprintf("Reset the farm for %d prestige point(s), +%.0f%% production? Press '%s' again",
+			gain, 100*PRESTIGEBONUS*float64(gain), m.Keys.Prestige.Help().Key)
+		return
+	default:
+		m.state.Reset()
+		m.cursor = 0
+		m.message = fmt.Sprintf("Prestige %d! Production is now x%.1f", m.state.Prestige, m.state.multiplier())
+		m.save(time.Now())
+	}
+	m.confirm = false
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		if !key.Matches(msg, m.Keys.Prestige) {
+			m.confirm = false
+		}
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.save(time.Now())
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Up):
+			m.cursor = (m.cursor + len(generators) - 1) % len(generators)
+		case key.Matches(msg, m.Keys.Down):
+			m.cursor = (m.cursor + 1) % len(generators)
+		case key.Matches(msg, m.Keys.Click):
+			m.state.Click()
+		case key.Matches(msg, m.Keys.Buy):
+			m.buy(1)
+		case key.Matches(msg, m.Keys.BuyMax):
+			m.buy(BUYMAX)
+		case key.Matches(msg, m.Keys.Prestige):
+			m.prestige()
+		}
+	case tea.MouseMsg:
+		// Clicks anywhere click, the wheel picks a generator and the
+		// right button buys one.
+		if msg.Action != tea.MouseActionPress {
+			break
+		}
+		switch msg.Button {
+		case tea.MouseButtonLeft:
+			m.state.Click()
+		case tea.MouseButtonRight:
+			m.buy(1)
+		case tea.MouseButtonWheelUp:
+			m.cursor = (m.cursor + len(generators) - 1) % len(generators)
+		case tea.MouseButtonWheelDown:
+			m.cursor = (m.cursor + 1) % len(generators)
+		}
+	case tickMsg:
+		now := time.Time(msg)
+		if now.After(m.last) {
+			m.state.Advance(now.Sub(m.last))
+			m.last = now
+		}
+		if now.Sub(m.saved) >= SAVEEVERY {
+			m.save(now)
+		}
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+func (m Model) generatorsView() string {
+	var s strings.Builder
+	for i, g := range generators {
+		owned := m.state.Owned[i]
+		rate := g.Rate * float64(owned) * m.state.multiplier()
+		line := fmt.Sprintf("%-14s x%-4d %9s  %9s/s", g.Name, owned, Format(m.state.Cost(i)), Format(rate))
+		switch {
+		case i == m.cursor:
+			line = m.CursorStyle.Render(line)
+		case m.state.Coins < m.state.Cost(i):
+			line = m.DimStyle.Render(line)
+		}
+		s.WriteString(line + "\n")
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
+	header := fmt.Sprintf("%s coins • %s/s • %s per mine",
+		m.CoinStyle.Render(Format(m.state.Coins)), Format(m.state.Rate()), Format(m.state.ClickValue()))
+	if m.state.Prestige > 0 {
+		header += fmt.Sprintf(" • prestige %d (x%.1f)", m.state.Prestige, m.state.multiplier())
+	}
+	saveNote := "Progress is saved to your key"
+	if m.Fingerprint == "" || m.Profiles == nil {
+		saveNote = "Connect with an SSH key to keep your farm"
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			"Coin Farm",
+			"",
+			header,
+			"",
+			m.BoxStyle.Render(m.TxtStyle.Render(fmt.Sprintf("%-14s %-5s %9s  %11s", "Generator", "Owned", "Cost", "Output"))+"\n"+m.generatorsView()),
+			"",
+			m.TxtStyle.Render(m.message),
+			m.DimStyle.Render(saveNote),
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
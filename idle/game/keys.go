@@ -0,0 +1,67 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Click    key.Binding
+	Buy      key.Binding
+	BuyMax   key.Binding
+	Prestige key.Binding
+	Layout   key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Click:    key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mine")),
+		Buy:      key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "buy")),
+		BuyMax:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "buy max")),
+		Prestige: key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "prestige")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Click, k.Buy, k.BuyMax, k.Prestige, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Click, k.Buy, k.BuyMax, k.Prestige},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":       &k.Up,
+		"down":     &k.Down,
+		"left":     &k.Left,
+		"right":    &k.Right,
+		"click":    &k.Click,
+		"buy":      &k.Buy,
+		"buy-max":  &k.BuyMax,
+		"prestige": &k.Prestige,
+		"layout":   &k.Layout,
+		"help":     &k.Help,
+		"quit":     &k.Quit,
+	}
+}
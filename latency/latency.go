@@ -0,0 +1,68 @@
+// Package latency measures how long input takes to reach the server and for
+// its effect to show up, so games can surface it in their HUD.
+package latency
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+)
+
+const (
+	INTERVAL = 2 * time.Second
+
+	// probeRequest is answered (with a failure) by every OpenSSH client,
+	// which makes it a cheap echo probe on the session channel.
+	probeRequest = "keepalive@openssh.com"
+)
+
+// Meter keeps a smoothed round-trip time for one session.
+type Meter struct {
+	rtt atomic.Int64
+}
+
+func NewMeter() *Meter {
+	return &Meter{}
+}
+
+// Run probes the session every interval until ctx is done or the session
+// goes away.
+func (m *Meter) Run(ctx context.Context, s ssh.Session, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		start := time.Now()
+		if _, err := s.SendRequest(probeRequest, true, nil); err != nil {
+			return
+		}
+		m.observe(time.Since(start))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RTT returns the smoothed round-trip time, or zero before the first probe.
+func (m *Meter) RTT() time.Duration {
+	if m == nil {
+		return 0
+	}
+	return time.Duration(m.rtt.Load())
+}
+
+func (m *Meter) observe(d time.Duration) {
+	m.rtt.Store(int64(Smooth(time.Duration(m.rtt.Load()), d)))
+}
+
+// Smooth is an exponential moving average weighting the new sample by 1/4.
+func Smooth(avg, sample time.Duration) time.Duration {
+	if avg == 0 {
+		return sample
+	}
+	return avg + (sample-avg)/4
+}
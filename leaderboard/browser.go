@@ -0,0 +1,151 @@
+package leaderboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+var dimensions = []string{"Game", "Mode", "Modifiers", "Board", "Season"}
+
+func (f *Filter) field(i int) *string {
+	switch i {
+	case 0:
+		return &f.Game
+	case 1:
+		return &f.Mode
+	case 2:
+		return &f.Modifiers
+	case 3:
+		return &f.Board
+	default:
+		return &f.Season
+	}
+}
+
+// Browser is a leaderboard screen that filters entries along every key
+// dimension. Up and down pick a dimension, left and right cycle its values.
+type Browser struct {
+	store       Store
+	filter      Filter
+	cursor      int
+	limit       int
+	fingerprint string
+	values      [][]string // per dimension, "" (any) first
+	entries     []Entry
+	best        *Entry
+}
+
+// NewBrowser shows the top limit entries matching f and, unless fingerprint
+// is empty, that player's personal best.
+func NewBrowser(store Store, f Filter, limit int, fingerprint string) *Browser {
+	b := &Browser{store: store, filter: f, limit: limit, fingerprint: fingerprint}
+	b.Refresh()
+	return b
+}
+
+func (b *Browser) Filter() Filter {
+	return b.filter
+}
+
+// Refresh reloads the available values and the matching entries.
+func (b *Browser) Refresh() {
+	b.values = make([][]string, len(dimensions))
+	for i := range dimensions {
+		seen := map[string]bool{"": true}
+		values := []string{""}
+		for _, k := range b.store.Keys() {
+			f := Filter(k)
+			v := *f.field(i)
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+		sort.Strings(values[1:])
+		b.values[i] = values
+	}
+	b.load()
+}
+
+func (b *Browser) load() {
+	b.entries = b.store.Top(b.filter, b.limit)
+	b.best = nil
+	if e, ok := b.store.Best(b.filter, b.fingerprint); ok {
+		b.best = &e
+	}
+}
+
+func (b *Browser) Update(msg tea.KeyMsg, keys ui.MoveKeys) {
+	switch {
+	case key.Matches(msg, keys.Up):
+		b.cursor = (b.cursor + len(dimensions) - 1) % len(dimensions)
+	case key.Matches(msg, keys.Down):
+		b.cursor = (b.cursor + 1) % len(dimensions)
+	case key.Matches(msg, keys.Left):
+		b.cycle(-1)
+	case key.Matches(msg, keys.Right):
+		b.cycle(1)
+	}
+}
+
+func (b *Browser) cycle(delta int) {
+	values := b.values[b.cursor]
+	field := b.filter.field(b.cursor)
+	i := 0
+	for j, v := range values {
+		if v == *field {
+			i = j
+		}
+	}
+	*field = values[(i+delta+len(values))%len(values)]
+	b.load()
+}
+
+func (b *Browser) View() string {
+	var s strings.Builder
+	s.WriteString("Leaderboard\n\n")
+	for i, name := range dimensions {
+		cursor := "  "
+		if i == b.cursor {
+			cursor = "> "
+		}
+		v := *b.filter.field(i)
+		if v == "" {
+			v = "all"
+		}
+		fmt.Fprintf(&s, "%s%-10s ‹ %s ›\n", cursor, name, v)
+	}
+
+	column := "Score"
+	if b.filter.Combined() {
+		column = "Points"
+	}
+	fmt.Fprintf(&s, "\n    %-12s %7s  %-8s %s\n", "Player", column, "Board", "Mode")
+	if len(b.entries) == 0 {
+		s.WriteString("    no runs yet\n")
+	}
+	for i, e := range b.entries {
+		marker := ""
+		if b.fingerprint != "" && e.Fingerprint == b.fingerprint {
+			marker = " *"
+		}
+		fmt.Fprintf(&s, "%2d. %-12s %7d  %-8s %s%s\n", i+1, e.Player, b.score(e), e.Board, e.Mode, marker)
+	}
+	if b.best != nil {
+		fmt.Fprintf(&s, "\n    %-12s %7d  %-8s %s\n", "Your best", b.score(*b.best), b.best.Board, b.best.Mode)
+	}
+	s.WriteString("\n↑/↓ select • ←/→ filter • esc close")
+	return s.String()
+}
+
+func (b *Browser) score(e Entry) int {
+	if b.filter.Combined() {
+		return e.Points
+	}
+	return e.Score
+}
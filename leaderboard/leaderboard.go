@@ -0,0 +1,210 @@
+// Package leaderboard keeps the best scores of every game. Entries are keyed
+// by everything that makes runs comparable (game, mode, modifiers, board and
+// season) so boards can be browsed at any level of detail.
+package leaderboard
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Key identifies a leaderboard. Runs only compete on raw score when every
+// field matches.
+type Key struct {
+	Game      string
+	Mode      string
+	Modifiers string // canonical set, see Modifiers
+	Board     string // e.g. "26x34"
+	Season    string // see SeasonOf
+}
+
+// NOMODIFIERS is the Modifiers field of an unmodified run.
+const NOMODIFIERS = "none"
+
+// ASSISTED is the modifier of runs played in slow mode. They rank on boards
+// of their own, and are flagged where boards are combined.
+const ASSISTED = "assisted"
+
+// Modifiers canonicalizes a set of run modifiers into a Key field.
+func Modifiers(mods ...string) string {
+	if len(mods) == 0 {
+		return NOMODIFIERS
+	}
+	mods = append([]string(nil), mods...)
+	sort.Strings(mods)
+	return strings.Join(mods, "+")
+}
+
+// Assisted reports whether the run was played in slow mode.
+func (k Key) Assisted() bool {
+	return slices.Contains(strings.Split(k.Modifiers, "+"), ASSISTED)
+}
+
+// SeasonOf names the leaderboard season t falls in. Seasons are calendar
+// quarters.
+func SeasonOf(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+type Entry struct {
+	Key
+	Player      string // display name
+	Fingerprint string // the player's SSH public key, empty when anonymous
+	Score       int
+	Points      int // Score normalized across boards, see Normalize
+	At          time.Time
+}
+
+// Normalize scales a raw score so runs on different boards and speeds rank
+// fairly: the score is multiplied by the square root of how much smaller the
+// board is than the reference, since room to grow scales with area but the
+// distance to each food only with its side, and by how much faster than the
+// reference the run was played. A run on the reference board at reference
+// speed keeps its score, times 100 to keep some precision.
+func Normalize(score, area, refArea int, speed float64) int {
+	if area <= 0 || speed <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(score) * 100 * math.Sqrt(float64(refArea)/float64(area)) * speed))
+}
+
+// Filter selects entries by key. Empty fields match anything.
+type Filter Key
+
+func (f Filter) Match(k Key) bool {
+	return (f.Game == "" || f.Game == k.Game) &&
+		(f.Mode == "" || f.Mode == k.Mode) &&
+		(f.Modifiers == "" || f.Modifiers == k.Modifiers) &&
+		(f.Board == "" || f.Board == k.Board) &&
+		(f.Season == "" || f.Season == k.Season)
+}
+
+// Combined reports whether the filter spans boards, in which case entries
+// rank by Points rather than Score.
+func (f Filter) Combined() bool {
+	return f.Board == ""
+}
+
+// Store receives finished runs and ranks them.
+type Store interface {
+	Submit(e Entry) error
+	// Top returns up to n of the best entries matching f, best first.
+	Top(f Filter, n int) []Entry
+	// Best returns the best entry matching f by the player with fingerprint.
+	Best(f Filter, fingerprint string) (Entry, bool)
+	// Keys lists every leaderboard that has entries.
+	Keys() []Key
+}
+
+// Rank sorts entries best first for f.
+func Rank(f Filter, entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if f.Combined() {
+			return entries[i].Points > entries[j].Points
+		}
+		return entries[i].Score > entries[j].Score
+	})
+}
+
+// MemoryStore keeps every leaderboard's top entries in memory.
+type MemoryStore struct {
+	mu     sync.Mutex
+	limit  int
+	boards map[Key][]Entry
+}
+
+// NewMemoryStore keeps up to limit entries per leaderboard, ranked by score
+// and by points so both views keep their best runs.
+func NewMemoryStore(limit int) *MemoryStore {
+	return &MemoryStore{limit: limit, boards: make(map[Key][]Entry)}
+}
+
+func (s *MemoryStore) Submit(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := append(s.boards[e.Key], e)
+	if len(entries) > s.limit {
+		entries = keepBest(entries, s.limit)
+	}
+	s.boards[e.Key] = entries
+	return nil
+}
+
+// keepBest keeps the union of the limit best entries by score and by points.
+func keepBest(entries []Entry, limit int) []Entry {
+	keep := make(map[int]bool)
+	idx := make([]int, len(entries))
+	for _, by := range []func(Entry) int{
+		func(e Entry) int { return e.Score },
+		func(e Entry) int { return e.Points },
+	} {
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(i, j int) bool { return by(entries[idx[i]]) > by(entries[idx[j]]) })
+		for _, i := range idx[:limit] {
+			keep[i] = true
+		}
+	}
+
+	kept := entries[:0]
+	for i, e := range entries {
+		if keep[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func (s *MemoryStore) Top(f Filter, n int) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for k, board := range s.boards {
+		if f.Match(k) {
+			entries = append(entries, board...)
+		}
+	}
+	Rank(f, entries)
+	return entries[:min(n, len(entries))]
+}
+
+func (s *MemoryStore) Keys() []Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]Key, 0, len(s.boards))
+	for k := range s.boards {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (s *MemoryStore) Best(f Filter, fingerprint string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for k, board := range s.boards {
+		if !f.Match(k) {
+			continue
+		}
+		for _, e := range board {
+			if fingerprint != "" && e.Fingerprint == fingerprint {
+				entries = append(entries, e)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+	Rank(f, entries)
+	return entries[0], true
+}
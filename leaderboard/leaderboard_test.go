@@ -0,0 +1,131 @@
+package leaderboard
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, tc := range []struct {
+		score, area, refArea int
+		speed                float64
+		want                 int
+	}{
+		{10, 100, 100, 1, 1000},   // the reference board keeps its score
+		{10, 25, 100, 1, 2000},    // a quarter of the area doubles it
+		{10, 400, 100, 1, 500},    // four times the area halves it
+		{10, 100, 100, 1.5, 1500}, // faster runs earn more
+		{7, 300, 100, 1, 404},     // rounded
+		{10, 0, 100, 1, 0},
+		{10, 100, 100, 0, 0},
+	} {
+		if got := Normalize(tc.score, tc.area, tc.refArea, tc.speed); got != tc.want {
+			t.Errorf("Normalize(%d, %d, %d, %v) = %d, want %d", tc.score, tc.area, tc.refArea, tc.speed, got, tc.want)
+		}
+	}
+}
+
+func TestModifiers(t *testing.T) {
+	if got := Modifiers(); got != NOMODIFIERS {
+		t.Errorf("Modifiers() = %q, want %q", got, NOMODIFIERS)
+	}
+	if got := Modifiers("walls", ASSISTED); got != "assisted+walls" {
+		t.Errorf("Modifiers = %q, want them sorted", got)
+	}
+	if !(Key{Modifiers: "assisted+walls"}).Assisted() || (Key{Modifiers: "walls"}).Assisted() {
+		t.Error("Assisted doesn't find the modifier")
+	}
+	if got := SeasonOf(time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC)); got != "2026-Q4" {
+		t.Errorf("SeasonOf = %q, want 2026-Q4", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	k := Key{Game: "snake", Mode: "classic", Modifiers: NOMODIFIERS, Board: "20x20", Season: "2026-Q4"}
+	for _, tc := range []struct {
+		f     Filter
+		match bool
+	}{
+		{Filter{}, true},
+		{Filter{Game: "snake"}, true},
+		{Filter(k), true},
+		{Filter{Game: "snake", Modifiers: NOMODIFIERS, Season: "2026-Q4"}, true},
+		{Filter{Game: "tetris"}, false},
+		{Filter{Game: "snake", Mode: "campaign"}, false},
+		{Filter{Game: "snake", Modifiers: "walls"}, false},
+		{Filter{Game: "snake", Board: "30x30"}, false},
+		{Filter{Game: "snake", Season: "2026-Q3"}, false},
+	} {
+		if got := tc.f.Match(k); got != tc.match {
+			t.Errorf("%+v.Match = %v, want %v", tc.f, got, tc.match)
+		}
+	}
+	if (Filter{Board: "20x20"}).Combined() || !(Filter{Game: "snake"}).Combined() {
+		t.Error("Combined should be true exactly when the filter spans boards")
+	}
+}
+
+func TestTop(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "scores.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	at := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	small := Key{Game: "snake", Mode: "classic", Modifiers: NOMODIFIERS, Board: "10x10", Season: "2026-Q4"}
+	big := small
+	big.Board = "40x40"
+	entries := []Entry{
+		{Key: small, Player: "ana", Fingerprint: "a", Score: 30, Points: 6000},
+		{Key: big, Player: "bob", Fingerprint: "b", Score: 50, Points: 2500},
+		{Key: small, Player: "cy", Fingerprint: "c", Score: 20, Points: 4000},
+		{Key: Key{Game: "tetris", Modifiers: NOMODIFIERS}, Player: "dee", Score: 900, Points: 90000},
+	}
+
+	for name, s := range map[string]Store{"memory": NewMemoryStore(10), "sqlite": db} {
+		t.Run(name, func(t *testing.T) {
+			for i, e := range entries {
+				e.At = at.Add(time.Duration(i) * time.Minute)
+				if err := s.Submit(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			players := func(entries []Entry) []string {
+				var names []string
+				for _, e := range entries {
+					names = append(names, e.Player)
+				}
+				return names
+			}
+			for _, tc := range []struct {
+				f    Filter
+				n    int
+				want []string
+			}{
+				// Across boards, by points: ana's small board beats bob's big score.
+				{Filter{Game: "snake"}, 10, []string{"ana", "cy", "bob"}},
+				// On one board, by score.
+				{Filter{Game: "snake", Board: "10x10"}, 10, []string{"ana", "cy"}},
+				{Filter{Game: "snake"}, 1, []string{"ana"}},
+				{Filter{Game: "snake", Board: "40x40"}, 10, []string{"bob"}},
+				{Filter{Game: "pong"}, 10, nil},
+			} {
+				if got := players(s.Top(tc.f, tc.n)); !slices.Equal(got, tc.want) {
+					t.Errorf("Top(%+v, %d) = %v, want %v", tc.f, tc.n, got, tc.want)
+				}
+			}
+			if e, ok := s.Best(Filter{Game: "snake"}, "c"); !ok || e.Player != "cy" {
+				t.Errorf("Best = %+v, %v, want cy's run", e, ok)
+			}
+			if _, ok := s.Best(Filter{Game: "snake"}, ""); ok {
+				t.Error("Best found a run of an anonymous player")
+			}
+			if keys := s.Keys(); len(keys) != 3 {
+				t.Errorf("Keys = %v, want 3 boards", keys)
+			}
+		})
+	}
+}
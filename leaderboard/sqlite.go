@@ -0,0 +1,140 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS scores (
+	game        TEXT NOT NULL,
+	mode        TEXT NOT NULL,
+	modifiers   TEXT NOT NULL,
+	board       TEXT NOT NULL,
+	season      TEXT NOT NULL,
+	player      TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	score       INTEGER NOT NULL,
+	points      INTEGER NOT NULL,
+	at          INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS scores_key ON scores (game, mode, modifiers, board, season);
+CREATE INDEX IF NOT EXISTS scores_fingerprint ON scores (fingerprint);
+`
+
+// match is the WHERE clause of a Filter, see filterArgs.
+const match = `(?1 = '' OR game = ?1) AND (?2 = '' OR mode = ?2) AND (?3 = '' OR modifiers = ?3)
+	AND (?4 = '' OR board = ?4) AND (?5 = '' OR season = ?5)`
+
+// SQLiteStore keeps every run in a SQLite database, so leaderboards survive
+// restarts and personal bests are never trimmed.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; sharing one connection avoids busy errors.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create leaderboard schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Submit(e Entry) error {
+	_, err := s.db.Exec(`INSERT INTO scores VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Game, e.Mode, e.Modifiers, e.Board, e.Season,
+		e.Player, e.Fingerprint, e.Score, e.Points, e.At.UnixMilli())
+	return err
+}
+
+func filterArgs(f Filter) []any {
+	return []any{f.Game, f.Mode, f.Modifiers, f.Board, f.Season}
+}
+
+func order(f Filter) string {
+	if f.Combined() {
+		return "points DESC"
+	}
+	return "score DESC"
+}
+
+func (s *SQLiteStore) Top(f Filter, n int) []Entry {
+	entries, err := s.query(`SELECT * FROM scores WHERE `+match+` ORDER BY `+order(f)+`, at LIMIT ?6`,
+		append(filterArgs(f), n)...)
+	if err != nil {
+		log.Warn("Could not load leaderboard", "err", err)
+		return nil
+	}
+	return entries
+}
+
+func (s *SQLiteStore) Best(f Filter, fingerprint string) (Entry, bool) {
+	if fingerprint == "" {
+		return Entry{}, false
+	}
+	entries, err := s.query(`SELECT * FROM scores WHERE `+match+` AND fingerprint = ?6 ORDER BY `+order(f)+`, at LIMIT 1`,
+		append(filterArgs(f), fingerprint)...)
+	if err != nil {
+		log.Warn("Could not load personal best", "err", err)
+	}
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+	return entries[0], true
+}
+
+func (s *SQLiteStore) Keys() []Key {
+	rows, err := s.db.Query(`SELECT DISTINCT game, mode, modifiers, board, season FROM scores`)
+	if err != nil {
+		log.Warn("Could not list leaderboards", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var keys []Key
+	for rows.Next() {
+		var k Key
+		if err := rows.Scan(&k.Game, &k.Mode, &k.Modifiers, &k.Board, &k.Season); err != nil {
+			return keys
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (s *SQLiteStore) query(q string, args ...any) ([]Entry, error) {
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var at int64
+		err := rows.Scan(&e.Game, &e.Mode, &e.Modifiers, &e.Board, &e.Season,
+			&e.Player, &e.Fingerprint, &e.Score, &e.Points, &at)
+		if err != nil {
+			return nil, err
+		}
+		e.At = time.UnixMilli(at)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
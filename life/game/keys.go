@@ -0,0 +1,86 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Toggle  key.Binding
+	Run     key.Binding
+	Step    key.Binding
+	Faster  key.Binding
+	Slower  key.Binding
+	Pattern key.Binding
+	Stamp   key.Binding
+	Random  key.Binding
+	Clear   key.Binding
+	Wrap    key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Toggle:   key.NewBinding(key.WithKeys("x", "enter", ui.KEYPADENTER), key.WithHelp("x", "toggle cell")),
+		Run:      key.NewBinding(key.WithKeys(" ", "p"), key.WithHelp("space", "run/pause")),
+		Step:     key.NewBinding(key.WithKeys("n", "."), key.WithHelp("n", "step")),
+		Faster:   key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "faster")),
+		Slower:   key.NewBinding(key.WithKeys("-", "_"), key.WithHelp("-", "slower")),
+		Pattern:  key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next pattern")),
+		Stamp:    key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "place pattern")),
+		Random:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "random fill")),
+		Clear:    key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "clear")),
+		Wrap:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "wrap edges")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Toggle, k.Run, k.Step, k.Pattern, k.Stamp, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		append(k.MoveKeys.All(), k.Toggle),
+		{k.Run, k.Step, k.Faster, k.Slower},
+		{k.Pattern, k.Stamp, k.Random, k.Clear, k.Wrap},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":      &k.Up,
+		"down":    &k.Down,
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"toggle":  &k.Toggle,
+		"run":     &k.Run,
+		"step":    &k.Step,
+		"faster":  &k.Faster,
+		"slower":  &k.Slower,
+		"pattern": &k.Pattern,
+		"stamp":   &k.Stamp,
+		"random":  &k.Random,
+		"clear":   &k.Clear,
+		"wrap":    &k.Wrap,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
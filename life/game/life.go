@@ -0,0 +1,262 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	// The world fills the terminal within these bounds. Cells are two
+	// columns wide so they come out square.
+	MINWIDTH  = 40 // the glider gun's width and some room
+	MINHEIGHT = 16
+	MAXWIDTH  = 100
+	MAXHEIGHT = 50
+
+	RANDOMFILL = 30 // percent of cells a random fill brings to life
+)
+
+// SPEEDS are the delays between generations while running, slowest first.
+var SPEEDS = []time.Duration{
+	time.Second,
+	500 * time.Millisecond,
+	250 * time.Millisecond,
+	100 * time.Millisecond,
+	50 * time.Millisecond,
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	AliveStyle  lipgloss.Style
+	DeadStyle   lipgloss.Style
+	CursorStyle lipgloss.Style
+	BoardStyle  lipgloss.Style
+	StatusStyle lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	world      *World
+	cursor     grid.Point
+	generation int
+	running    bool
+	speed      int // into SPEEDS
+	pattern    int // into Patterns
+	run        int // generation of the run, so ticks of a paused one are dropped
+
+	showHelp bool
+	rng      *rand.Rand
+	ctx      context.Context
+}
+
+type tickMsg int
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		AliveStyle:  r.NewStyle().Foreground(lipgloss.Color("10")),
+		DeadStyle:   r.NewStyle().Foreground(lipgloss.Color("236")),
+		CursorStyle: r.NewStyle().Reverse(true),
+		BoardStyle:  r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		StatusStyle: r.NewStyle().Foreground(lipgloss.Color("15")),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:  DefaultKeyMap(),
+		speed: 2,
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:   context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	w, h := fit(width, height)
+	m.world = NewWorld(w, h)
+	m.cursor = grid.Point{X: w / 2, Y: h / 2}
+	m.world.Stamp(Patterns[m.pattern], m.cursor)
+	return m
+}
+
+// fit is the world size for a terminal, leaving room for the border, the
+// status line and help.
+func fit(width, height int) (int, int) {
+	w := max(MINWIDTH, min(MAXWIDTH, (width-2)/2))
+	h := max(MINHEIGHT, min(MAXHEIGHT, height-5))
+	return w, h
+}
+
+// SetContext binds the simulation's ticks to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) tick() tea.Cmd {
+	run := m.run
+	return ui.Every(m.ctx, SPEEDS[m.speed], func(time.Time) tea.Msg {
+		return tickMsg(run)
+	})
+}
+
+// toggleRun starts or pauses the simulation. Every start is a new run, so
+// a tick still pending from before a pause doesn't double the pace.
+func (m *Model) toggleRun() tea.Cmd {
+	m.running = !m.running
+	if !m.running {
+		return nil
+	}
+	m.run++
+	return m.tick()
+}
+
+func (m *Model) step() {
+	m.world.Step()
+	m.generation++
+}
+
+func (m *Model) moveCursor(d grid.Point) {
+	m.cursor.X = (m.cursor.X + d.X + m.world.Width()) % m.world.Width()
+	m.cursor.Y = (m.cursor.Y + d.Y + m.world.Height()) % m.world.Height()
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		m.world.Resize(fit(m.Width, m.Height))
+		m.cursor.X = min(m.cursor.X, m.world.Width()-1)
+		m.cursor.Y = min(m.cursor.Y, m.world.Height()-1)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(grid.Right)
+		case key.Matches(msg, m.Keys.Toggle):
+			m.world.Toggle(m.cursor)
+		case key.Matches(msg, m.Keys.Run):
+			return m, m.toggleRun()
+		case key.Matches(msg, m.Keys.Step):
+			if !m.running {
+				m.step()
+			}
+		case key.Matches(msg, m.Keys.Faster):
+			m.speed = min(len(SPEEDS)-1, m.speed+1)
+		case key.Matches(msg, m.Keys.Slower):
+			m.speed = max(0, m.speed-1)
+		case key.Matches(msg, m.Keys.Pattern):
+			m.pattern = (m.pattern + 1) % len(Patterns)
+		case key.Matches(msg, m.Keys.Stamp):
+			m.world.Stamp(Patterns[m.pattern], m.cursor)
+		case key.Matches(msg, m.Keys.Random):
+			m.world.Randomize(m.rng, RANDOMFILL)
+			m.generation = 0
+		case key.Matches(msg, m.Keys.Clear):
+			m.world.Clear()
+			m.generation = 0
+		case key.Matches(msg, m.Keys.Wrap):
+			m.world.Wrap = !m.world.Wrap
+		}
+	case tickMsg:
+		if int(msg) != m.run || !m.running {
+			return m, nil
+		}
+		m.step()
+		return m, m.tick()
+	}
+	return m, nil
+}
+
+func (m Model) worldView() string {
+	alive := m.AliveStyle.Render("██")
+	dead := m.DeadStyle.Render("· ")
+	var s strings.Builder
+	for y := 0; y < m.world.Height(); y++ {
+		for x := 0; x < m.world.Width(); x++ {
+			p := grid.Point{X: x, Y: y}
+			switch {
+			case p == m.cursor && m.world.Alive(p):
+				s.WriteString(m.CursorStyle.Render(m.AliveStyle.Render("██")))
+			case p == m.cursor:
+				s.WriteString(m.CursorStyle.Render("  "))
+			case m.world.Alive(p):
+				s.WriteString(alive)
+			default:
+				s.WriteString(dead)
+			}
+		}
+		if y < m.world.Height()-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) status() string {
+	state := "paused"
+	if m.running {
+		state = "running"
+	}
+	edges := "wrapping"
+	if !m.world.Wrap {
+		edges = "walled"
+	}
+	return m.StatusStyle.Render(fmt.Sprintf("Generation %d | Population %d | %s, %s a step | Edges %s | Pattern: %s",
+		m.generation, m.world.Population(), state, SPEEDS[m.speed], edges, Patterns[m.pattern].Name))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.status(),
+			m.worldView(),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
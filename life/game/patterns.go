@@ -0,0 +1,96 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+// Pattern is a named arrangement of live cells, drawn with O for live and
+// . for dead cells, one row per line.
+type Pattern struct {
+	Name string
+	Rows []string
+}
+
+func (p Pattern) Width() int {
+	w := 0
+	for _, r := range p.Rows {
+		w = max(w, len(r))
+	}
+	return w
+}
+
+func (p Pattern) Height() int {
+	return len(p.Rows)
+}
+
+// Cells lists the live cells, relative to the pattern's top left.
+func (p Pattern) Cells() []grid.Point {
+	var cells []grid.Point
+	for y, r := range p.Rows {
+		for x, c := range r {
+			if c == 'O' {
+				cells = append(cells, grid.Point{X: x, Y: y})
+			}
+		}
+	}
+	return cells
+}
+
+func pattern(name, rows string) Pattern {
+	return Pattern{Name: name, Rows: strings.Fields(rows)}
+}
+
+// Patterns is the library players stamp from.
+var Patterns = []Pattern{
+	pattern("Glider", `
+		.O.
+		..O
+		OOO`),
+	pattern("Lightweight spaceship", `
+		.O..O
+		O....
+		O...O
+		OOOO.`),
+	pattern("Pulsar", `
+		..OOO...OOO..
+		.............
+		O....O.O....O
+		O....O.O....O
+		O....O.O....O
+		..OOO...OOO..
+		.............
+		..OOO...OOO..
+		O....O.O....O
+		O....O.O....O
+		O....O.O....O
+		.............
+		..OOO...OOO..`),
+	pattern("Pentadecathlon", `
+		..O....O..
+		OO.OOOO.OO
+		..O....O..`),
+	pattern("Gosper glider gun", `
+		........................O...........
+		......................O.O...........
+		............OO......OO............OO
+		...........O...O....OO............OO
+		OO........O.....O...OO..............
+		OO........O...O.OO....O.O...........
+		..........O.....O.......O...........
+		...........O...O....................
+		............OO......................`),
+	pattern("R-pentomino", `
+		.OO
+		OO.
+		.O.`),
+	pattern("Acorn", `
+		.O.....
+		...O...
+		OO..OOO`),
+	pattern("Diehard", `
+		......O.
+		OO......
+		.O...OOO`),
+}
@@ -0,0 +1,38 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "life"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Game of Life",
+	Description: "Draw cells and patterns, then watch Conway's automaton run",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Spectating:  true,
+	Session:     5 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+// World is a Game of Life board. With Wrap on its edges join up, so
+// gliders leaving one side come back on the other; off, everything past
+// the edges stays dead.
+type World struct {
+	cells *grid.Grid[bool]
+	next  *grid.Grid[bool] // swapped with cells every step
+	Wrap  bool
+}
+
+func NewWorld(width, height int) *World {
+	return &World{cells: grid.New[bool](width, height), next: grid.New[bool](width, height), Wrap: true}
+}
+
+func (w *World) Width() int  { return w.cells.Width() }
+func (w *World) Height() int { return w.cells.Height() }
+
+func (w *World) Alive(p grid.Point) bool {
+	return w.cells.At(p)
+}
+
+func (w *World) Toggle(p grid.Point) {
+	w.cells.Set(p, !w.cells.At(p))
+}
+
+func (w *World) Clear() {
+	w.cells.Fill(false)
+}
+
+// Randomize brings every cell to life with a chance of percent in a hundred.
+func (w *World) Randomize(rng *rand.Rand, percent int) {
+	w.cells.Each(func(p grid.Point, _ bool) {
+		w.cells.Set(p, rng.Intn(100) < percent)
+	})
+}
+
+func (w *World) Population() int {
+	n := 0
+	w.cells.Each(func(_ grid.Point, alive bool) {
+		if alive {
+			n++
+		}
+	})
+	return n
+}
+
+// neighbors counts the live cells around p.
+func (w *World) neighbors(p grid.Point) int {
+	n := 0
+	for _, d := range grid.Dirs8 {
+		q := p.Add(d)
+		if w.Wrap {
+			q.X = (q.X + w.Width()) % w.Width()
+			q.Y = (q.Y + w.Height()) % w.Height()
+		}
+		if w.cells.At(q) {
+			n++
+		}
+	}
+	return n
+}
+
+// Step advances the world a generation: live cells with two or three
+// neighbours live on and dead cells with exactly three come to life.
+func (w *World) Step() {
+	w.cells.Each(func(p grid.Point, alive bool) {
+		n := w.neighbors(p)
+		w.next.Set(p, n == 3 || alive && n == 2)
+	})
+	w.cells, w.next = w.next, w.cells
+}
+
+// Stamp brings pattern to life centred on at. Cells that fall off the world
+// wrap or are dropped, as the world's edges do.
+func (w *World) Stamp(pattern Pattern, at grid.Point) {
+	origin := at.Sub(grid.Point{X: pattern.Width() / 2, Y: pattern.Height() / 2})
+	for _, c := range pattern.Cells() {
+		p := origin.Add(c)
+		if w.Wrap {
+			p.X = (p.X%w.Width() + w.Width()) % w.Width()
+			p.Y = (p.Y%w.Height() + w.Height()) % w.Height()
+		}
+		w.cells.Set(p, true)
+	}
+}
+
+// Resize changes the world's size, keeping the cells at its top left.
+func (w *World) Resize(width, height int) {
+	if width == w.Width() && height == w.Height() {
+		return
+	}
+	cells := grid.New[bool](width, height)
+	w.cells.Each(func(p grid.Point, alive bool) {
+		cells.Set(p, alive)
+	})
+	w.cells, w.next = cells, grid.New[bool](width, height)
+}
@@ -0,0 +1,132 @@
+// Package lifecycle ties per-session goroutines to the session that started
+// them and checks they're all gone once it ends, counting the ones that
+// aren't as leaks.
+package lifecycle
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// GRACE is how long a finished session's goroutines get to wind down
+// before they're reported as leaked.
+const GRACE = 5 * time.Second
+
+var (
+	metrics        = expvar.NewMap("lifecycle")
+	liveSessions   = new(expvar.Int)
+	liveGoroutines = new(expvar.Int)
+	leaked         = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("sessions", liveSessions)
+	metrics.Set("goroutines", liveGoroutines)
+	metrics.Set("leaked", leaked)
+}
+
+// Scope owns the goroutines of one session. Its context is canceled as soon
+// as the session handler returns.
+type Scope struct {
+	id     string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	live   atomic.Int64
+}
+
+func newScope(parent context.Context, id string) *Scope {
+	ctx, cancel := context.WithCancel(parent)
+	return &Scope{id: id, ctx: ctx, cancel: cancel}
+}
+
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Go runs fn in a tracked goroutine. fn must return once ctx is done.
+func (s *Scope) Go(name string, fn func(ctx context.Context)) {
+	s.wg.Add(1)
+	s.live.Add(1)
+	liveGoroutines.Add(1)
+	go func() {
+		defer func() {
+			s.live.Add(-1)
+			liveGoroutines.Add(-1)
+			s.wg.Done()
+		}()
+		log.Debug("Session goroutine started", "session", s.id, "name", name)
+		fn(s.ctx)
+	}()
+}
+
+// audit waits for the scope's goroutines and counts stragglers as leaked.
+func (s *Scope) audit() {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(GRACE):
+		n := s.live.Load()
+		leaked.Add(n)
+		log.Warn("Session goroutines outlived their session", "session", s.id, "count", n)
+	}
+}
+
+type scopeKey struct{}
+
+// FromSession returns the session's scope, if the middleware is installed.
+func FromSession(s ssh.Session) (*Scope, bool) {
+	scope, ok := s.Context().Value(scopeKey{}).(*Scope)
+	return scope, ok
+}
+
+// Go runs fn under the session's scope, or in a plain goroutine bound to the
+// session context when there is none.
+func Go(s ssh.Session, name string, fn func(ctx context.Context)) {
+	if scope, ok := FromSession(s); ok {
+		scope.Go(name, fn)
+		return
+	}
+	go fn(s.Context())
+}
+
+// Middleware gives every session a Scope, tears it down when the handler
+// returns and audits it for leaks. It also coalesces window changes, see
+// coalescingSession.
+func Middleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			scope := newScope(s.Context(), s.Context().SessionID())
+			s.Context().SetValue(scopeKey{}, scope)
+			liveSessions.Add(1)
+			defer func() {
+				liveSessions.Add(-1)
+				scope.cancel()
+				go scope.audit()
+			}()
+
+			next(coalesce(s, scope))
+		}
+	}
+}
+
+// Context returns the scope's context, which ends as soon as the session
+// handler returns, falling back to the session context.
+func Context(s ssh.Session) context.Context {
+	if scope, ok := FromSession(s); ok {
+		return scope.Context()
+	}
+	return s.Context()
+}
@@ -0,0 +1,74 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// coalescingSession drains the session's window changes as soon as they
+// arrive and keeps only the latest one for whoever reads Pty's channel.
+//
+// The ssh server delivers window-change requests on an unbuffered path: if
+// nobody reads them (e.g. while the renderer is still querying the client's
+// terminal), request handling blocks, the connection stops processing
+// packets and a client that disconnects mid-storm leaves the session wedged
+// forever. Draining here keeps the connection responsive.
+type coalescingSession struct {
+	ssh.Session
+
+	winch chan ssh.Window
+
+	mu     sync.Mutex
+	window *ssh.Window
+}
+
+func coalesce(s ssh.Session, scope *Scope) ssh.Session {
+	_, winch, ok := s.Pty()
+	if !ok {
+		return s
+	}
+
+	cs := &coalescingSession{Session: s, winch: make(chan ssh.Window, 1)}
+	scope.Go("window changes", func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case w, ok := <-winch:
+				if !ok {
+					return
+				}
+				cs.push(w)
+			}
+		}
+	})
+	return cs
+}
+
+func (cs *coalescingSession) push(w ssh.Window) {
+	cs.mu.Lock()
+	cs.window = &w
+	cs.mu.Unlock()
+
+	// Only this goroutine sends, so after dropping the stale size the send
+	// can't block.
+	select {
+	case <-cs.winch:
+	default:
+	}
+	cs.winch <- w
+}
+
+func (cs *coalescingSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) {
+	pty, _, ok := cs.Session.Pty()
+
+	cs.mu.Lock()
+	if cs.window != nil {
+		pty.Window = *cs.window
+	}
+	cs.mu.Unlock()
+
+	return pty, cs.winch, ok
+}
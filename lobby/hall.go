@@ -0,0 +1,325 @@
+package lobby
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const HALLPOLL = 500 * time.Millisecond
+
+// Hall screens
+const (
+	BROWSING = iota // the rooms waiting for players
+	CODE            // typing a join code
+	CREATING        // picking the game of a new room
+	INROOM
+)
+
+type HallKeyMap struct {
+	ui.MoveKeys
+	Select key.Binding
+	Create key.Binding
+	Code   key.Binding
+	Ready  key.Binding
+	Back   key.Binding
+}
+
+func HallKeyMapFor(l ui.Layout) HallKeyMap {
+	return HallKeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Select:   key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "join")),
+		Create:   key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new room")),
+		Code:     key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "join by code")),
+		Ready:    key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "ready")),
+		Back:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	}
+}
+
+// Launch is a game a room started, for the player to be routed to.
+type Launch struct {
+	Game games.Info
+	Room string // see games.Env.Room
+}
+
+type hallPollMsg struct {
+	gen int
+}
+
+// Hall is where players browse the rooms waiting for players, open their
+// own or join one by code, and ready up.
+type Hall struct {
+	Keys HallKeyMap
+	Dim  lipgloss.Style
+	You  lipgloss.Style
+
+	rooms  *Rooms
+	player string
+	games  []games.Info // the ones rooms can be opened for
+	ctx    context.Context
+	gen    int // of the poll loop, so a reopened hall runs only one
+
+	screen  int
+	waiting []Summary
+	cursor  int
+	code    textinput.Model
+	room    *Room
+	id      int
+	snap    RoomSnapshot
+	ready   bool
+	err     error
+	done    bool
+}
+
+// NewHall opens the rooms of rs to player. Rooms can be opened for any of
+// items that take them.
+func NewHall(ctx context.Context, rs *Rooms, player string, items []games.Info, r *lipgloss.Renderer) *Hall {
+	h := &Hall{
+		Keys:   HallKeyMapFor(ui.QWERTY),
+		Dim:    r.NewStyle().Foreground(lipgloss.Color("8")),
+		You:    r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		rooms:  rs,
+		player: player,
+		ctx:    ctx,
+		code:   textinput.New(),
+	}
+	for _, it := range items {
+		if Roomable(it) {
+			h.games = append(h.games, it)
+		}
+	}
+	h.code.Prompt = "code: "
+	h.code.CharLimit = CODELEN
+	h.refresh(time.Now())
+	return h
+}
+
+// SetPlayer changes the name the player joins rooms with from now on.
+func (h *Hall) SetPlayer(player string) {
+	h.player = player
+}
+
+// Open starts polling the hall again, after it was closed.
+func (h *Hall) Open() tea.Cmd {
+	h.done = false
+	h.gen++
+	h.refresh(time.Now())
+	return h.poll()
+}
+
+func (h *Hall) poll() tea.Cmd {
+	gen := h.gen
+	return ui.Every(h.ctx, HALLPOLL, func(time.Time) tea.Msg { return hallPollMsg{gen: gen} })
+}
+
+// Done reports whether the player left the hall.
+func (h *Hall) Done() bool {
+	return h.done
+}
+
+// Close leaves the room the player is in, if any, and the hall.
+func (h *Hall) Close() {
+	if h.room != nil {
+		h.room.Leave(h.id)
+		h.room = nil
+	}
+	h.screen = BROWSING
+	h.done = true
+	h.gen++
+}
+
+func (h *Hall) refresh(now time.Time) {
+	if h.room == nil {
+		h.waiting = h.rooms.Waiting(now)
+		h.cursor = min(h.cursor, max(0, len(h.waiting)-1))
+		return
+	}
+	snap, ok := h.room.Poll(h.id, now)
+	if !ok {
+		h.room = nil
+		h.screen = BROWSING
+		h.refresh(now)
+		return
+	}
+	h.snap = snap
+}
+
+func (h *Hall) enter(r *Room, id int) {
+	h.room, h.id = r, id
+	h.ready = false
+	h.screen = INROOM
+	h.err = nil
+	h.refresh(time.Now())
+}
+
+// Update handles a message and reports the game to route the player to once
+// their room starts.
+func (h *Hall) Update(msg tea.Msg) (*Launch, tea.Cmd) {
+	switch msg := msg.(type) {
+	case hallPollMsg:
+		if msg.gen != h.gen {
+			return nil, nil
+		}
+		h.refresh(time.Now())
+		return h.launch(), h.poll()
+	case tea.KeyMsg:
+		return h.updateKeys(msg)
+	}
+	return nil, nil
+}
+
+// launch hands the player over to the game once their room started.
+func (h *Hall) launch() *Launch {
+	if h.room == nil || !h.snap.Started {
+		return nil
+	}
+	l := &Launch{Game: h.snap.Game, Room: h.room.Key()}
+	h.Close()
+	return l
+}
+
+func (h *Hall) updateKeys(msg tea.KeyMsg) (*Launch, tea.Cmd) {
+	switch h.screen {
+	case CODE:
+		switch {
+		case key.Matches(msg, h.Keys.Back):
+			h.screen = BROWSING
+		case key.Matches(msg, h.Keys.Select):
+			h.join(strings.ToUpper(strings.TrimSpace(h.code.Value())))
+		default:
+			var cmd tea.Cmd
+			h.code, cmd = h.code.Update(msg)
+			return nil, cmd
+		}
+	case CREATING:
+		switch {
+		case key.Matches(msg, h.Keys.Back):
+			h.screen = BROWSING
+		case key.Matches(msg, h.Keys.Up):
+			h.cursor = max(0, h.cursor-1)
+		case key.Matches(msg, h.Keys.Down):
+			h.cursor = min(len(h.games)-1, h.cursor+1)
+		case key.Matches(msg, h.Keys.Select):
+			if len(h.games) > 0 {
+				h.enter(h.rooms.Create(h.games[h.cursor], h.player, time.Now()))
+			}
+		}
+	case INROOM:
+		switch {
+		case key.Matches(msg, h.Keys.Back):
+			h.room.Leave(h.id)
+			h.room = nil
+			h.screen = BROWSING
+			h.refresh(time.Now())
+		case key.Matches(msg, h.Keys.Ready):
+			h.ready = !h.ready
+			h.room.SetReady(h.id, h.ready)
+			h.refresh(time.Now())
+			return h.launch(), nil
+		}
+	default:
+		switch {
+		case key.Matches(msg, h.Keys.Back):
+			h.Close()
+		case key.Matches(msg, h.Keys.Up):
+			h.cursor = max(0, h.cursor-1)
+		case key.Matches(msg, h.Keys.Down):
+			h.cursor = min(len(h.waiting)-1, h.cursor+1)
+		case key.Matches(msg, h.Keys.Create):
+			h.screen = CREATING
+			h.cursor = 0
+		case key.Matches(msg, h.Keys.Code):
+			h.screen = CODE
+			h.err = nil
+			h.code.Reset()
+			return nil, h.code.Focus()
+		case key.Matches(msg, h.Keys.Select):
+			if len(h.waiting) > 0 {
+				h.join(h.waiting[h.cursor].Code)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (h *Hall) join(code string) {
+	r, id, err := h.rooms.Join(code, h.player, time.Now())
+	if err != nil {
+		h.err = err
+		h.screen = BROWSING
+		h.refresh(time.Now())
+		return
+	}
+	h.enter(r, id)
+}
+
+func (h *Hall) help(bindings ...key.Binding) string {
+	var parts []string
+	for _, b := range bindings {
+		parts = append(parts, b.Help().Key+" "+b.Help().Desc)
+	}
+	return h.Dim.Render(strings.Join(parts, " • "))
+}
+
+func (h *Hall) View() string {
+	var s strings.Builder
+	switch h.screen {
+	case CODE:
+		s.WriteString("Join a room\n\n" + h.code.View() + "\n\n")
+		s.WriteString(h.help(h.Keys.Select, h.Keys.Back))
+	case CREATING:
+		s.WriteString("Open a room for\n\n")
+		if len(h.games) == 0 {
+			s.WriteString("  no game takes rooms\n")
+		}
+		for i, it := range h.games {
+			cursor := "  "
+			if i == h.cursor {
+				cursor = "> "
+			}
+			fmt.Fprintf(&s, "%s%-16s %s\n", cursor, it.Title, h.Dim.Render(it.Players()))
+		}
+		s.WriteString("\n" + h.help(h.Keys.Select, h.Keys.Back))
+	case INROOM:
+		fmt.Fprintf(&s, "Room %s • %s\n\n", h.You.Render(h.snap.Code), h.snap.Game.Title)
+		for _, m := range h.snap.Members {
+			mark := "·"
+			if m.Ready {
+				mark = "✓"
+			}
+			line := fmt.Sprintf("%s %s", mark, m.Name)
+			if m.You {
+				line = h.You.Render(line)
+			}
+			s.WriteString("  " + line + "\n")
+		}
+		fmt.Fprintf(&s, "\n%s\n\n", h.Dim.Render(fmt.Sprintf("%d/%d players, %s", len(h.snap.Members), h.snap.Game.MaxPlayers, h.snap.Need())))
+		s.WriteString(h.help(h.Keys.Ready, h.Keys.Back))
+	default:
+		s.WriteString("Rooms\n\n")
+		if len(h.waiting) == 0 {
+			s.WriteString("  no rooms are waiting for players\n")
+		}
+		for i, w := range h.waiting {
+			cursor := "  "
+			if i == h.cursor {
+				cursor = "> "
+			}
+			fmt.Fprintf(&s, "%s%s %-16s %d/%d %s\n", cursor, w.Code, w.Game.Title, len(w.Players), w.Game.MaxPlayers,
+				h.Dim.Render(strings.Join(w.Players, ", ")))
+		}
+		if h.err != nil {
+			s.WriteString("\n" + h.err.Error() + "\n")
+		}
+		s.WriteString("\n" + h.help(h.Keys.Select, h.Keys.Create, h.Keys.Code, h.Keys.Back))
+	}
+	return s.String()
+}
@@ -0,0 +1,306 @@
+// Package lobby is the game picker: a searchable list with the player's
+// favorites pinned to the top and recently played games next, filterable by
+// the registry's metadata. Its hall lets players gather in rooms, by short
+// join code, before being routed into a multiplayer game together.
+package lobby
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Search   key.Binding
+	Favorite key.Binding
+	Category key.Binding
+	Watch    key.Binding
+	Select   key.Binding
+	Close    key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	return KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Search:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		Favorite: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "favorite")),
+		Category: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "category")),
+		Watch:    key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "spectatable only")),
+		Select:   key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "play")),
+		Close:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear search")),
+	}
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Select, k.Search, k.Favorite}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Select},
+		{k.Search, k.Close, k.Favorite, k.Category, k.Watch},
+	}
+}
+
+type Model struct {
+	Keys KeyMap
+	// Location is the player's timezone, which scheduled games show their
+	// next start in. Nil is UTC.
+	Location *time.Location
+
+	items []games.Info
+	// The player's favorites and recently played games are kept in their
+	// profile, saved to profiles by fingerprint.
+	profiles    profile.Store
+	fingerprint string
+	profile     *profile.Profile
+
+	search    textinput.Model
+	searching bool
+	cursor    int
+	visible   []games.Info
+
+	category  games.Category // empty shows every category
+	watchable bool
+}
+
+// New lists items for the player with fingerprint and profile p, keeping
+// their favorites and history in it and saving them to profiles. Anonymous
+// players, and a nil profiles or p, keep them for the session only.
+func New(items []games.Info, profiles profile.Store, fingerprint string, p *profile.Profile) *Model {
+	if p == nil {
+		p = &profile.Profile{}
+	}
+	m := &Model{
+		Keys:        DefaultKeyMap(),
+		items:       items,
+		profiles:    profiles,
+		fingerprint: fingerprint,
+		profile:     p,
+		search:      textinput.New(),
+	}
+	m.search.Prompt = "/ "
+	m.search.Placeholder = "search games"
+	m.refresh()
+	return m
+}
+
+// Searching reports whether keystrokes go to the search box, so callers
+// don't treat them as their own shortcuts.
+func (m *Model) Searching() bool {
+	return m.searching
+}
+
+// Update handles a message and reports the game the player picked, if any.
+func (m *Model) Update(msg tea.Msg) (*games.Info, tea.Cmd) {
+	if mouse, ok := msg.(tea.MouseMsg); ok {
+		return m.updateMouse(mouse), nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.search, cmd = m.search.Update(msg)
+		return nil, cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.Keys.Select):
+		m.searching = false
+		m.search.Blur()
+		return m.pick(), nil
+	case key.Matches(keyMsg, m.Keys.Close):
+		m.searching = false
+		m.search.Blur()
+		m.search.Reset()
+		m.refresh()
+	case keyMsg.Type == tea.KeyUp:
+		m.move(-1)
+	case keyMsg.Type == tea.KeyDown:
+		m.move(1)
+	case m.searching:
+		var cmd tea.Cmd
+		m.search, cmd = m.search.Update(msg)
+		m.refresh()
+		return nil, cmd
+	case key.Matches(keyMsg, m.Keys.Search):
+		m.searching = true
+		return nil, m.search.Focus()
+	case key.Matches(keyMsg, m.Keys.Up):
+		m.move(-1)
+	case key.Matches(keyMsg, m.Keys.Down):
+		m.move(1)
+	case key.Matches(keyMsg, m.Keys.Category):
+		m.category = nextCategory(m.category)
+		m.refresh()
+	case key.Matches(keyMsg, m.Keys.Watch):
+		m.watchable = !m.watchable
+		m.refresh()
+	case key.Matches(keyMsg, m.Keys.Favorite):
+		if len(m.visible) > 0 {
+			id := m.visible[m.cursor].ID
+			profile.Update(m.profiles, m.fingerprint, m.profile, func(p *profile.Profile) { toggleFavorite(p, id) })
+			m.refresh()
+			m.cursor = max(0, slices.IndexFunc(m.visible, func(it games.Info) bool { return it.ID == id }))
+		}
+	}
+	return nil, nil
+}
+
+// updateMouse scrolls through the list with the wheel and plays the game
+// under the cursor on a left click, so the lobby works without a keyboard.
+func (m *Model) updateMouse(msg tea.MouseMsg) *games.Info {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.move(-1)
+	case tea.MouseButtonWheelDown:
+		m.move(1)
+	case tea.MouseButtonLeft:
+		m.searching = false
+		m.search.Blur()
+		return m.pick()
+	}
+	return nil
+}
+
+func (m *Model) move(delta int) {
+	if len(m.visible) == 0 {
+		return
+	}
+	m.cursor = (m.cursor + delta + len(m.visible)) % len(m.visible)
+}
+
+// nextCategory cycles through every category and back to all of them.
+func nextCategory(c games.Category) games.Category {
+	i := slices.Index(games.Categories, c)
+	if i+1 >= len(games.Categories) {
+		return ""
+	}
+	return games.Categories[i+1]
+}
+
+func (m *Model) pick() *games.Info {
+	if len(m.visible) == 0 {
+		return nil
+	}
+	it := m.visible[m.cursor]
+	profile.Update(m.profiles, m.fingerprint, m.profile, func(p *profile.Profile) { played(p, it.ID) })
+	m.refresh()
+	m.cursor = max(0, slices.IndexFunc(m.visible, func(v games.Info) bool { return v.ID == it.ID }))
+	return &it
+}
+
+// refresh orders the list. Favorites always come first. Without a query the
+// rest follow by how recently they were played, then by title; with one,
+// by how well they match.
+func (m *Model) refresh() {
+	query := m.search.Value()
+	recent := func(id string) int {
+		if i := slices.Index(m.profile.Recent, id); i >= 0 {
+			return i
+		}
+		return len(m.profile.Recent)
+	}
+
+	scores := make(map[string]int)
+	m.visible = m.visible[:0]
+	for _, it := range m.items {
+		if m.category != "" && it.Category != m.category || m.watchable && !it.Spectating {
+			continue
+		}
+		score, ok := Fuzzy(query, it.Title)
+		if !ok {
+			continue
+		}
+		scores[it.ID] = score
+		m.visible = append(m.visible, it)
+	}
+
+	sort.SliceStable(m.visible, func(i, j int) bool {
+		a, b := m.visible[i], m.visible[j]
+		if fa, fb := favorite(m.profile, a.ID), favorite(m.profile, b.ID); fa != fb {
+			return fa
+		}
+		if query != "" && scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if ra, rb := recent(a.ID), recent(b.ID); query == "" && ra != rb {
+			return ra < rb
+		}
+		return a.Title < b.Title
+	})
+	m.cursor = min(m.cursor, max(0, len(m.visible)-1))
+}
+
+func (m Model) View() string {
+	var s strings.Builder
+	category := "all"
+	if m.category != "" {
+		category = string(m.category)
+	}
+	fmt.Fprintf(&s, "Category: %s", category)
+	if m.watchable {
+		s.WriteString(" • spectatable only")
+	}
+	s.WriteString("\n\n")
+	if m.searching || m.search.Value() != "" {
+		s.WriteString(m.search.View())
+		s.WriteString("\n\n")
+	}
+	if len(m.visible) == 0 {
+		s.WriteString("  no games match\n")
+	}
+	now := time.Now()
+	for i, it := range m.visible {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		badge := " "
+		switch {
+		case favorite(m.profile, it.ID):
+			badge = "★"
+		case slices.Contains(m.profile.Recent, it.ID):
+			badge = "↺"
+		}
+		fmt.Fprintf(&s, "%s%s %-16s %s\n    %s\n", cursor, badge, it.Title, it.Description, m.badges(it, now))
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+// badges summarizes a game's metadata, e.g. "[arcade] 1p ~5m", when its
+// next scheduled start is and how many wait for an opponent.
+func (m Model) badges(it games.Info, now time.Time) string {
+	b := fmt.Sprintf("[%s] %s", it.Category, it.Players())
+	if it.Session > 0 {
+		b += fmt.Sprintf(" ~%dm", int(it.Session.Round(time.Minute).Minutes()))
+	}
+	if it.Spectating {
+		b += " spectatable"
+	}
+	if it.Next != nil {
+		what, at := it.Next(now)
+		b += fmt.Sprintf(" • %s %s", what, clock.At(at, now, m.Location))
+	}
+	if it.Waiting != nil {
+		b += fmt.Sprintf(" • %d in queue", it.Waiting())
+	}
+	return b
+}
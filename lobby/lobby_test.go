@@ -0,0 +1,135 @@
+package lobby
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+var items = []games.Info{
+	{ID: "anagram", Title: "Anagram"},
+	{ID: "breakout", Title: "Breakout"},
+	{ID: "chess", Title: "Chess"},
+}
+
+func press(m *Model, keys ...string) *games.Info {
+	var picked *games.Info
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		picked, _ = m.Update(msg)
+	}
+	return picked
+}
+
+func ids(m *Model) []string {
+	var ids []string
+	for _, it := range m.visible {
+		ids = append(ids, it.ID)
+	}
+	return ids
+}
+
+func TestPrefsPersist(t *testing.T) {
+	const fingerprint = "SHA256:ana"
+	store := profile.NewMemoryStore()
+	m := New(items, store, fingerprint, profile.Load(store, fingerprint, "ana"))
+	press(m, "down", "down", "f") // pin chess
+	if picked := press(m, "down", "enter"); picked == nil || picked.ID != "anagram" {
+		t.Fatalf("picked %v, want anagram", picked)
+	}
+
+	// A later session of the same player, by key, sees both.
+	again := New(items, store, fingerprint, profile.Load(store, fingerprint, "ana"))
+	if got := ids(again); got[0] != "chess" || got[1] != "anagram" {
+		t.Errorf("order %v, want chess pinned, then anagram as played last", got)
+	}
+	// Someone else, even by the same name, doesn't.
+	other := New(items, store, "SHA256:bob", profile.Load(store, "SHA256:bob", "ana"))
+	if got := ids(other); got[0] != "anagram" || favorite(other.profile, "chess") {
+		t.Errorf("order %v for another player, want by title", got)
+	}
+}
+
+func TestPrefsAnonymous(t *testing.T) {
+	store := profile.NewMemoryStore()
+	m := New(items, store, "", profile.Load(store, "", "guest"))
+	press(m, "down", "f")
+	if got := ids(m); got[0] != "breakout" {
+		t.Errorf("order %v, want breakout pinned for the session", got)
+	}
+	if _, ok, _ := store.Load(""); ok {
+		t.Error("anonymous favorites saved")
+	}
+	// A nil profile still keeps them for the session.
+	m = New(items, nil, "", nil)
+	press(m, "down", "f")
+	if got := ids(m); got[0] != "breakout" {
+		t.Errorf("order %v without a profile, want breakout pinned", got)
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	for _, tc := range []struct {
+		query, s string
+		score    int
+		ok       bool
+	}{
+		{"", "Snake", 0, true},
+		{"s", "Snake", 4, true},
+		{"sn", "Snake", 6, true},
+		{"SNK", "snake", 7, true}, // k isn't next to n, so its run starts over
+		{"ttt", "Tic-Tac-Toe", 12, true},
+		{"sn", "Minesweeper notes", 5, true},
+		{"ks", "Snake", 0, false},
+		{"snakes", "Snake", 0, false},
+		{"2048", "2048", 13, true},
+	} {
+		score, ok := Fuzzy(tc.query, tc.s)
+		if ok != tc.ok || ok && score != tc.score {
+			t.Errorf("Fuzzy(%q, %q) = %d, %v, want %d, %v", tc.query, tc.s, score, ok, tc.score, tc.ok)
+		}
+	}
+}
+
+func TestSearchOrder(t *testing.T) {
+	items := []games.Info{
+		{ID: "sudoku", Title: "Sudoku"},
+		{ID: "minesweeper", Title: "Minesweeper notes"},
+		{ID: "duel", Title: "Snake Duel"},
+		{ID: "snake", Title: "Snake"},
+		{ID: "tetris", Title: "Tetris Test"},
+		{ID: "tictactoe", Title: "Tic-Tac-Toe"},
+	}
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"sn", []string{"snake", "duel", "minesweeper"}},
+		{"ttt", []string{"tictactoe", "tetris"}},
+		{"zz", nil},
+	} {
+		m := New(items, nil, "", nil)
+		press(m, "/")
+		press(m, strings.Split(tc.query, "")...)
+		if got := ids(m); !slices.Equal(got, tc.want) {
+			t.Errorf("search %q = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	// Favorites stay on top of better matches.
+	m := New(items, nil, "", &profile.Profile{Favorites: []string{"minesweeper"}})
+	press(m, "/", "s", "n")
+	if got := ids(m); !slices.Equal(got, []string{"minesweeper", "snake", "duel"}) {
+		t.Errorf("search with a favorite = %v, want it first", got)
+	}
+}
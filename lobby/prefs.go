@@ -0,0 +1,35 @@
+package lobby
+
+import (
+	"slices"
+
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+// RECENTLIMIT is how many recently played games are remembered per player.
+const RECENTLIMIT = 10
+
+// favorite reports whether the player pinned game id.
+func favorite(p *profile.Profile, id string) bool {
+	return slices.Contains(p.Favorites, id)
+}
+
+// toggleFavorite pins or unpins a game.
+func toggleFavorite(p *profile.Profile, id string) {
+	if i := slices.Index(p.Favorites, id); i >= 0 {
+		p.Favorites = slices.Delete(p.Favorites, i, i+1)
+		return
+	}
+	p.Favorites = append(p.Favorites, id)
+}
+
+// played moves a game to the front of the recent list.
+func played(p *profile.Profile, id string) {
+	if i := slices.Index(p.Recent, id); i >= 0 {
+		p.Recent = slices.Delete(p.Recent, i, i+1)
+	}
+	p.Recent = append([]string{id}, p.Recent...)
+	if len(p.Recent) > RECENTLIMIT {
+		p.Recent = p.Recent[:RECENTLIMIT]
+	}
+}
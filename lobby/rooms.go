@@ -0,0 +1,324 @@
+package lobby
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/observe"
+)
+
+const (
+	CODELEN   = 4
+	CODECHARS = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no look-alikes like 0 and O
+	ROOMSTALE = 10 * time.Second                   // members not heard from for this long left
+)
+
+var (
+	ErrNoRoom  = errors.New("no room with that code")
+	ErrFull    = errors.New("the room is full")
+	ErrStarted = errors.New("the game already started")
+)
+
+type member struct {
+	id    int
+	name  string
+	ready bool
+	seen  time.Time
+}
+
+// Room gathers players for one multiplayer game. Once everyone in it is
+// ready, and there are enough of them, it starts: every member is routed to
+// the game with the same room key, which the game uses to seat them
+// together instead of with strangers.
+type Room struct {
+	mu      sync.Mutex
+	code    string
+	game    games.Info
+	members []*member
+	nextID  int
+	started bool
+	closed  bool // once everybody left
+	feed    *observe.Feed
+}
+
+// Rooms keeps the rooms open on the server, by code.
+type Rooms struct {
+	// Feed, when set, is told about rooms opening, filling up, starting
+	// and closing.
+	Feed *observe.Feed
+
+	mu    sync.Mutex
+	rooms map[string]*Room
+	rng   *rand.Rand
+}
+
+func NewRooms() *Rooms {
+	return &Rooms{rooms: make(map[string]*Room), rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Roomable reports whether players can gather in a room for game.
+func Roomable(game games.Info) bool {
+	return game.Rooms && game.MaxPlayers > 1
+}
+
+// Create opens a room for game with name in it.
+func (rs *Rooms) Create(game games.Info, name string, now time.Time) (*Room, int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.prune(now)
+	code := rs.code()
+	for rs.rooms[code] != nil {
+		code = rs.code()
+	}
+	r := &Room{code: code, game: game, feed: rs.Feed}
+	rs.rooms[code] = r
+	id := r.add(name, now)
+	r.publish(observe.ROOMOPEN, now)
+	return r, id
+}
+
+func (rs *Rooms) code() string {
+	b := make([]byte, CODELEN)
+	for i := range b {
+		b[i] = CODECHARS[rs.rng.Intn(len(CODECHARS))]
+	}
+	return string(b)
+}
+
+// Join adds name to the room with code.
+func (rs *Rooms) Join(code, name string, now time.Time) (*Room, int, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.prune(now)
+	r := rs.rooms[code]
+	if r == nil {
+		return nil, 0, ErrNoRoom
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.started:
+		return nil, 0, ErrStarted
+	case len(r.members) >= r.game.MaxPlayers:
+		return nil, 0, ErrFull
+	}
+	id := r.addLocked(name, now)
+	r.publishLocked(observe.ROOMUPDATE, now)
+	return r, id, nil
+}
+
+// prune closes the rooms everybody left.
+func (rs *Rooms) prune(now time.Time) {
+	for code, r := range rs.rooms {
+		r.mu.Lock()
+		r.drop(now)
+		empty := len(r.members) == 0
+		if empty {
+			r.close(now)
+		}
+		r.mu.Unlock()
+		if empty {
+			delete(rs.rooms, code)
+		}
+	}
+}
+
+// Summary describes a room to players looking for one.
+type Summary struct {
+	Code    string
+	Game    games.Info
+	Players []string
+}
+
+// Waiting lists the rooms that haven't started, by code.
+func (rs *Rooms) Waiting(now time.Time) []Summary {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.prune(now)
+	var all []Summary
+	for _, r := range rs.rooms {
+		r.mu.Lock()
+		if !r.started {
+			s := Summary{Code: r.code, Game: r.game}
+			for _, m := range r.members {
+				s.Players = append(s.Players, m.name)
+			}
+			all = append(all, s)
+		}
+		r.mu.Unlock()
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+	return all
+}
+
+func (r *Room) add(name string, now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addLocked(name, now)
+}
+
+func (r *Room) addLocked(name string, now time.Time) int {
+	r.nextID++
+	r.members = append(r.members, &member{id: r.nextID, name: name, seen: now})
+	return r.nextID
+}
+
+func (r *Room) drop(now time.Time) {
+	n := len(r.members)
+	r.members = slices.DeleteFunc(r.members, func(m *member) bool { return now.Sub(m.seen) > ROOMSTALE })
+	if len(r.members) < n && len(r.members) > 0 {
+		r.publishLocked(observe.ROOMUPDATE, now)
+	}
+}
+
+// close tells observers the room is gone, once.
+func (r *Room) close(now time.Time) {
+	if !r.closed {
+		r.closed = true
+		r.publishLocked(observe.ROOMCLOSE, now)
+	}
+}
+
+func (r *Room) publish(kind string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.publishLocked(kind, now)
+}
+
+func (r *Room) publishLocked(kind string, now time.Time) {
+	r.feed.Publish(r.event(kind, now))
+}
+
+func (r *Room) event(kind string, now time.Time) observe.Event {
+	e := observe.Event{Kind: kind, At: now, Game: r.game.Title, Room: r.code}
+	for _, m := range r.members {
+		e.Players = append(e.Players, m.name)
+		if m.ready {
+			e.Ready = append(e.Ready, m.name)
+		}
+	}
+	return e
+}
+
+func (r *Room) find(id int) *member {
+	if i := slices.IndexFunc(r.members, func(m *member) bool { return m.id == id }); i >= 0 {
+		return r.members[i]
+	}
+	return nil
+}
+
+// SetReady marks member id as ready to start, or not.
+func (r *Room) SetReady(id int, ready bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m := r.find(id); m != nil && !r.started && m.ready != ready {
+		m.ready = ready
+		r.publishLocked(observe.ROOMUPDATE, time.Now())
+	}
+}
+
+// Leave takes member id out of the room.
+func (r *Room) Leave(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.members)
+	r.members = slices.DeleteFunc(r.members, func(m *member) bool { return m.id == id })
+	switch {
+	case len(r.members) == 0:
+		r.close(time.Now())
+	case len(r.members) < n:
+		r.publishLocked(observe.ROOMUPDATE, time.Now())
+	}
+}
+
+// Key is what the room's members share once in the game.
+func (r *Room) Key() string {
+	return "room/" + r.code
+}
+
+// Member is a player in a room as sessions render them.
+type Member struct {
+	Name  string
+	Ready bool
+	You   bool
+}
+
+type RoomSnapshot struct {
+	Code    string
+	Game    games.Info
+	Members []Member
+	Started bool
+}
+
+// Poll marks member id as present, starts the game when everyone is ready
+// and describes the room. It reports false once the member is gone.
+func (r *Room) Poll(id int, now time.Time) (RoomSnapshot, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	me := r.find(id)
+	if me == nil {
+		return RoomSnapshot{}, false
+	}
+	me.seen = now
+	r.drop(now)
+
+	ready := len(r.members) >= needed(r.game)
+	for _, m := range r.members {
+		ready = ready && m.ready
+	}
+	if ready && !r.started {
+		r.started = true
+		r.publishLocked(observe.ROOMSTART, now)
+	}
+
+	s := RoomSnapshot{Code: r.code, Game: r.game, Started: r.started}
+	for _, m := range r.members {
+		s.Members = append(s.Members, Member{Name: m.name, Ready: m.ready, You: m.id == id})
+	}
+	return s, true
+}
+
+// needed is how many players a room of game starts with at least. Rooms are
+// for playing together, so even games open to one player need two.
+func needed(game games.Info) int {
+	return max(2, game.MinPlayers)
+}
+
+// Need describes what the room is waiting for.
+func (s RoomSnapshot) Need() string {
+	if missing := needed(s.Game) - len(s.Members); missing > 0 {
+		return fmt.Sprintf("waiting for %d more", missing)
+	}
+	return "waiting for everyone to be ready"
+}
+
+// Current reports the rooms waiting for players to observers, see
+// observe.Source.
+func (rs *Rooms) Current() []observe.Event {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	rs.prune(now)
+	var events []observe.Event
+	for _, r := range rs.rooms {
+		r.mu.Lock()
+		if !r.started {
+			events = append(events, r.event(observe.ROOMOPEN, now))
+		}
+		r.mu.Unlock()
+	}
+	slices.SortFunc(events, func(a, b observe.Event) int { return strings.Compare(a.Room, b.Room) })
+	return events
+}
@@ -0,0 +1,35 @@
+package lobby
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fuzzy matches query as a subsequence of s, ignoring case. Higher scores
+// are better: consecutive matches and matches at the start of a word are
+// rewarded, so "sn" ranks "Snake" above "Minesweeper notes".
+func Fuzzy(query, s string) (score int, ok bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	prev := ' '
+	run := 0
+	i := 0
+	for _, r := range s {
+		lower := unicode.ToLower(r)
+		if i < len(q) && lower == q[i] {
+			i++
+			run++
+			score += run
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 3
+			}
+		} else {
+			run = 0
+		}
+		prev = r
+	}
+	return score, i == len(q)
+}
@@ -0,0 +1,191 @@
+// Package maze generates perfect and braided mazes on a grid of wall cells.
+package maze
+
+import (
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"golang.org/x/exp/rand"
+)
+
+type Algorithm int
+
+const (
+	// BACKTRACKER carves with a randomized depth-first search, giving long
+	// winding corridors.
+	BACKTRACKER Algorithm = iota
+	// PRIM grows the maze from a random frontier, giving many short dead ends.
+	PRIM
+)
+
+// Options are the difficulty knobs. Width and Height count rooms; the
+// resulting grid is 2*Width+1 by 2*Height+1 cells including outer walls.
+type Options struct {
+	Width     int
+	Height    int
+	Algorithm Algorithm
+	// Braid is the fraction of dead ends, from 0 to 1, that get knocked
+	// through to create loops. Loops make a maze easier to escape.
+	Braid float64
+	Seed  uint64
+}
+
+type Difficulty int
+
+const (
+	EASY Difficulty = iota
+	NORMAL
+	HARD
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case EASY:
+		return "Easy"
+	case NORMAL:
+		return "Normal"
+	default:
+		return "Hard"
+	}
+}
+
+// Preset returns the options for a difficulty.
+func Preset(d Difficulty, seed uint64) Options {
+	switch d {
+	case EASY:
+		return Options{Width: 8, Height: 6, Algorithm: PRIM, Braid: 0.5, Seed: seed}
+	case NORMAL:
+		return Options{Width: 14, Height: 10, Algorithm: BACKTRACKER, Braid: 0.15, Seed: seed}
+	default:
+		return Options{Width: 24, Height: 16, Algorithm: BACKTRACKER, Seed: seed}
+	}
+}
+
+// Maze is a grid where true cells are walls. Start and Exit are open cells
+// on opposite corners.
+type Maze struct {
+	*grid.Grid[bool]
+	Start grid.Point
+	Exit  grid.Point
+}
+
+func Generate(opts Options) *Maze {
+	opts.Width = max(1, opts.Width)
+	opts.Height = max(1, opts.Height)
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	m := &Maze{
+		Grid:  grid.New[bool](2*opts.Width+1, 2*opts.Height+1),
+		Start: grid.Point{X: 1, Y: 1},
+		Exit:  grid.Point{X: 2*opts.Width - 1, Y: 2*opts.Height - 1},
+	}
+	m.Fill(true)
+
+	switch opts.Algorithm {
+	case PRIM:
+		m.prim(rng)
+	default:
+		m.backtrack(rng)
+	}
+	if opts.Braid > 0 {
+		m.braid(rng, opts.Braid)
+	}
+	return m
+}
+
+// Walkable reports whether p is an open cell.
+func (m *Maze) Walkable(p grid.Point) bool {
+	return m.InBounds(p) && !m.At(p)
+}
+
+// Solution returns the shortest path from Start to Exit.
+func (m *Maze) Solution() ([]grid.Point, bool) {
+	return m.Path(m.Start, m.Exit, func(_ grid.Point, wall bool) bool { return !wall })
+}
+
+// Solvable verifies the exit can be reached from the start.
+func (m *Maze) Solvable() bool {
+	_, ok := m.Solution()
+	return ok
+}
+
+// rooms are the odd cells; walls sit between them two cells apart.
+var roomSteps = []grid.Point{{X: 0, Y: -2}, {X: 0, Y: 2}, {X: -2, Y: 0}, {X: 2, Y: 0}}
+
+func (m *Maze) carve(a, b grid.Point) {
+	m.Set(a, false)
+	m.Set(grid.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}, false)
+	m.Set(b, false)
+}
+
+func (m *Maze) uncarved(p grid.Point) []grid.Point {
+	var out []grid.Point
+	for _, d := range roomSteps {
+		if n := p.Add(d); m.InBounds(n) && m.At(n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (m *Maze) backtrack(rng *rand.Rand) {
+	m.Set(m.Start, false)
+	stack := []grid.Point{m.Start}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		next := m.uncarved(cur)
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		n := next[rng.Intn(len(next))]
+		m.carve(cur, n)
+		stack = append(stack, n)
+	}
+}
+
+func (m *Maze) prim(rng *rand.Rand) {
+	type edge struct{ from, to grid.Point }
+
+	m.Set(m.Start, false)
+	var frontier []edge
+	for _, n := range m.uncarved(m.Start) {
+		frontier = append(frontier, edge{m.Start, n})
+	}
+	for len(frontier) > 0 {
+		i := rng.Intn(len(frontier))
+		e := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		if !m.At(e.to) {
+			continue
+		}
+		m.carve(e.from, e.to)
+		for _, n := range m.uncarved(e.to) {
+			frontier = append(frontier, edge{e.to, n})
+		}
+	}
+}
+
+// braid knocks a wall out of the given fraction of dead ends.
+func (m *Maze) braid(rng *rand.Rand, fraction float64) {
+	m.Each(func(p grid.Point, wall bool) {
+		if wall || p.X%2 == 0 || p.Y%2 == 0 || rng.Float64() >= fraction {
+			return
+		}
+
+		var walls, inner []grid.Point
+		for _, d := range grid.Dirs4 {
+			w := p.Add(d)
+			if !m.At(w) {
+				continue
+			}
+			walls = append(walls, w)
+			if m.InBounds(w.Add(d)) {
+				inner = append(inner, w)
+			}
+		}
+		if len(walls) == 3 && len(inner) > 0 {
+			m.Set(inner[rng.Intn(len(inner))], false)
+		}
+	})
+}
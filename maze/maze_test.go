@@ -0,0 +1,47 @@
+package maze
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const SEEDS = 200
+
+func open(_ grid.Point, wall bool) bool { return !wall }
+
+func TestExitReachable(t *testing.T) {
+	var options []Options
+	for seed := range uint64(SEEDS) {
+		for _, d := range []Difficulty{EASY, NORMAL, HARD} {
+			options = append(options, Preset(d, seed))
+		}
+		// Degenerate sizes, down to a single room.
+		options = append(options,
+			Options{Width: 1, Height: 1, Seed: seed},
+			Options{Width: 1 + int(seed%5), Height: 7, Algorithm: PRIM, Braid: 1, Seed: seed},
+			Options{Width: 9, Height: 1 + int(seed%3), Seed: seed},
+		)
+	}
+
+	for _, opts := range options {
+		m := Generate(opts)
+		reached := m.FloodFill(m.Start, open)
+		if !slices.Contains(reached, m.Exit) {
+			t.Fatalf("%+v: exit %v not reachable from %v", opts, m.Exit, m.Start)
+		}
+		if !m.Solvable() {
+			t.Fatalf("%+v: no solution though the exit is reachable", opts)
+		}
+		// Every room is carved into the maze, and the outer walls stand.
+		m.Each(func(p grid.Point, wall bool) {
+			switch {
+			case p.X%2 == 1 && p.Y%2 == 1 && !slices.Contains(reached, p):
+				t.Fatalf("%+v: room %v cut off", opts, p)
+			case (p.X == 0 || p.Y == 0 || p.X == m.Width()-1 || p.Y == m.Height()-1) && !wall:
+				t.Fatalf("%+v: outer wall open at %v", opts, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	WIDTH  = 16
+	HEIGHT = 12
+	MINES  = 30
+)
+
+// START is opened for both racers before play, so nobody loses to a first
+// guess and neither gets a luckier opening.
+var START = grid.Point{X: WIDTH / 2, Y: HEIGHT / 2}
+
+// Board is one racer's copy of the minefield.
+type Board struct {
+	mines    *grid.Grid[bool]
+	near     *grid.Grid[int] // mines around each cell
+	revealed *grid.Grid[bool]
+	flagged  *grid.Grid[bool]
+	left     int // safe cells still hidden
+	lost     bool
+}
+
+// newBoard lays out the same minefield for everyone given the same seed,
+// keeping the cells around START clear.
+func newBoard(seed int64) *Board {
+	b := &Board{
+		mines:    grid.New[bool](WIDTH, HEIGHT),
+		near:     grid.New[int](WIDTH, HEIGHT),
+		revealed: grid.New[bool](WIDTH, HEIGHT),
+		flagged:  grid.New[bool](WIDTH, HEIGHT),
+		left:     WIDTH*HEIGHT - MINES,
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for placed := 0; placed < MINES; {
+		p := grid.Point{X: rng.Intn(WIDTH), Y: rng.Intn(HEIGHT)}
+		if b.mines.At(p) || nearStart(p) {
+			continue
+		}
+		b.mines.Set(p, true)
+		for _, n := range b.mines.Neighbors(p, grid.Dirs8) {
+			b.near.Set(n, b.near.At(n)+1)
+		}
+		placed++
+	}
+	b.reveal(START)
+	return b
+}
+
+func nearStart(p grid.Point) bool {
+	d := p.Sub(START)
+	return d.X >= -1 && d.X <= 1 && d.Y >= -1 && d.Y <= 1
+}
+
+func (b *Board) Won() bool {
+	return b.left == 0 && !b.lost
+}
+
+func (b *Board) over() bool {
+	return b.lost || b.left == 0
+}
+
+// reveal opens p, flooding through cells with no mines around them.
+func (b *Board) reveal(p grid.Point) {
+	if b.over() || !b.revealed.InBounds(p) || b.revealed.At(p) || b.flagged.At(p) {
+		return
+	}
+	if b.mines.At(p) {
+		b.revealed.Set(p, true)
+		b.lost = true
+		return
+	}
+	queue := []grid.Point{p}
+	b.revealed.Set(p, true)
+	b.left--
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if b.near.At(c) > 0 {
+			continue
+		}
+		for _, n := range b.revealed.Neighbors(c, grid.Dirs8) {
+			if !b.revealed.At(n) && !b.flagged.At(n) {
+				b.revealed.Set(n, true)
+				b.left--
+				queue = append(queue, n)
+			}
+		}
+	}
+}
+
+// chord opens the neighbors of a revealed number once as many of them are
+// flagged, the usual shortcut for clearing around a solved cell.
+func (b *Board) chord(p grid.Point) {
+	if !b.revealed.At(p) || b.near.At(p) == 0 {
+		return
+	}
+	neighbors := b.revealed.Neighbors(p, grid.Dirs8)
+	flags := 0
+	for _, n := range neighbors {
+		if b.flagged.At(n) {
+			flags++
+		}
+	}
+	if flags != b.near.At(p) {
+		return
+	}
+	for _, n := range neighbors {
+		b.reveal(n)
+	}
+}
+
+// open reveals a hidden cell or chords a revealed one.
+func (b *Board) open(p grid.Point) {
+	if b.revealed.At(p) {
+		b.chord(p)
+		return
+	}
+	b.reveal(p)
+}
+
+func (b *Board) flag(p grid.Point) {
+	if !b.over() && b.flagged.InBounds(p) && !b.revealed.At(p) {
+		b.flagged.Set(p, !b.flagged.At(p))
+	}
+}
+
+// Flags counts the flags placed.
+func (b *Board) Flags() int {
+	n := 0
+	b.flagged.Each(func(_ grid.Point, f bool) {
+		if f {
+			n++
+		}
+	})
+	return n
+}
+
+// Progress is the share of safe cells opened, in percent.
+func (b *Board) Progress() int {
+	safe := WIDTH*HEIGHT - MINES
+	return (safe - b.left) * 100 / safe
+}
+
+func (b *Board) clone() *Board {
+	c := *b
+	c.revealed = b.revealed.Clone()
+	c.flagged = b.flagged.Clone()
+	return &c
+}
+
+// savedBoard is a board as a race's snapshot keeps it.
+type savedBoard struct {
+	Mines, Revealed, Flagged *grid.Grid[bool]
+	Near                     *grid.Grid[int]
+	Left                     int
+	Lost                     bool
+}
+
+func (b *Board) save() savedBoard {
+	return savedBoard{Mines: b.mines, Revealed: b.revealed, Flagged: b.flagged, Near: b.near, Left: b.left, Lost: b.lost}
+}
+
+func (s savedBoard) restore() *Board {
+	return &Board{mines: s.Mines, near: s.Near, revealed: s.Revealed, flagged: s.Flagged, left: s.Left, lost: s.Lost}
+}
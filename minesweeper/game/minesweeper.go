@@ -0,0 +1,407 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 200 * time.Millisecond
+
+// numberColors tints the mine counts, from 1 up.
+var numberColors = []string{"12", "10", "9", "13", "11", "14", "15", "7"}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	HiddenStyle lipgloss.Style
+	FogStyle    lipgloss.Style
+	FlagStyle   lipgloss.Style
+	MineStyle   lipgloss.Style
+	CursorStyle lipgloss.Style
+	BoardStyle  lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+	numbers     []lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room   string
+	race   *Race
+	side   int
+	snap   Snapshot
+	cursor grid.Point
+	// cast follows the race for casters, see Scoreboard.
+	cast spectate.Match
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		HiddenStyle: r.NewStyle().Foreground(lipgloss.Color("245")),
+		FogStyle:    r.NewStyle().Foreground(lipgloss.Color("238")),
+		FlagStyle:   r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+		MineStyle:   r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("9")),
+		CursorStyle: r.NewStyle().Reverse(true),
+		BoardStyle:  r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		ctx:    context.Background(),
+	}
+	for _, c := range numberColors {
+		m.numbers = append(m.numbers, r.NewStyle().Foreground(lipgloss.Color(c)))
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.race, m.side = join(m.Room, m.Player, now)
+	m.cursor = START
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.race.opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.race.leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.race.poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	m.snap = snap
+	m.score()
+}
+
+// score follows the race for casters, who see how much of their board each
+// racer cleared.
+func (m *Model) score() {
+	if m.snap.Phase == COUNTING {
+		m.cast = spectate.Match{Title: m.Name()}
+	}
+	if !m.dealt() {
+		return
+	}
+	var progress []int
+	for _, rc := range m.snap.Racers {
+		progress = append(progress, rc.Board.Progress())
+	}
+	m.cast.Track(progress...)
+	for i, rc := range m.snap.Racers {
+		m.cast.Players[i].Name = rc.Name
+	}
+}
+
+// Scoreboard is the race for casters, see spectate.Scoreboard.
+func (m Model) Scoreboard() (spectate.Match, bool) {
+	return m.cast, m.dealt()
+}
+
+func (m *Model) moveCursor(d grid.Point) {
+	m.cursor.X = max(0, min(WIDTH-1, m.cursor.X+d.X))
+	m.cursor.Y = max(0, min(HEIGHT-1, m.cursor.Y+d.Y))
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.race.leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(grid.Right)
+		case key.Matches(msg, m.Keys.Open):
+			m.race.open(m.side, m.cursor)
+			m.refresh(time.Now())
+		case key.Matches(msg, m.Keys.Flag):
+			m.race.flag(m.side, m.cursor)
+			m.refresh(time.Now())
+		}
+	case tea.MouseMsg:
+		m.updateMouse(msg)
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+// updateMouse opens the cell clicked with the left button and flags the
+// one clicked with the right, and starts a new race on a click once it's
+// over. Clicks do nothing while the race waits for an opponent.
+func (m *Model) updateMouse(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress || m.showHelp || !m.dealt() {
+		return
+	}
+	if m.snap.Phase == OVER {
+		if msg.Button == tea.MouseButtonLeft {
+			m.Join()
+		}
+		return
+	}
+	p, ok := m.cellAt(msg.X, msg.Y)
+	if !ok {
+		return
+	}
+	m.cursor = p
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		m.race.open(m.side, p)
+	case tea.MouseButtonRight:
+		m.race.flag(m.side, p)
+	}
+	m.refresh(time.Now())
+}
+
+// cellAt finds the cell of the player's own board at screen column x and
+// row y, retracing how View centers the boards.
+func (m Model) cellAt(x, y int) (grid.Point, bool) {
+	if !m.dealt() {
+		return grid.Point{}, false
+	}
+	body, boards := m.raceView()
+	w, h := lipgloss.Width(body), lipgloss.Height(body)
+	left := max(0, m.Width-w)/2 + (w-lipgloss.Width(boards)+1)/2
+	top := max(0, m.Height-h) / 2
+	// The player's board comes first, under its title and inside its
+	// border, two columns a cell.
+	x, y = x-left-1, y-top-2
+	if x < 0 || y < 0 {
+		return grid.Point{}, false
+	}
+	p := grid.Point{X: x / 2, Y: y}
+	return p, p.X < WIDTH && p.Y < HEIGHT
+}
+
+// cell draws p of the player's own board. Mines only show once the race is
+// over.
+func (m Model) cell(b *Board, p grid.Point) string {
+	var s string
+	switch {
+	case b.revealed.At(p) && b.mines.At(p):
+		s = m.MineStyle.Render("**")
+	case b.revealed.At(p) && b.near.At(p) > 0:
+		n := b.near.At(p)
+		s = m.numbers[n-1].Render(fmt.Sprintf(" %d", n))
+	case b.revealed.At(p):
+		s = "  "
+	case b.flagged.At(p):
+		s = m.FlagStyle.Render(" !")
+	case m.snap.Phase == OVER && b.mines.At(p):
+		s = m.FlagStyle.Render(" *")
+	default:
+		s = m.HiddenStyle.Render("░░")
+	}
+	if p == m.cursor && m.snap.Phase != OVER {
+		s = m.CursorStyle.Render(s)
+	}
+	return s
+}
+
+// mirror draws the opponent's board under fog: only which cells they
+// cleared shows, never the numbers or their flags.
+func (m Model) mirror(b *Board, p grid.Point) string {
+	switch {
+	case b.revealed.At(p) && b.mines.At(p):
+		return m.MineStyle.Render("**")
+	case b.revealed.At(p):
+		return "  "
+	}
+	return m.FogStyle.Render("▓▓")
+}
+
+func (m Model) boardView(b *Board, cell func(*Board, grid.Point) string) string {
+	var s strings.Builder
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			s.WriteString(cell(b, grid.Point{X: x, Y: y}))
+		}
+		if y < HEIGHT-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+// dealt reports whether the race is on or over, with the player's board
+// dealt.
+func (m Model) dealt() bool {
+	return m.snap.Phase != WAITING && m.side < len(m.snap.Racers) && m.snap.Racers[m.side].Board != nil
+}
+
+func (m Model) racerView(i int) string {
+	if i < 0 || i >= len(m.snap.Racers) || m.snap.Racers[i].Board == nil {
+		return m.BoardStyle.Render(m.QuitStyle.Render("Waiting for an opponent..."))
+	}
+	rc := m.snap.Racers[i]
+	name := rc.Name
+	draw := m.mirror
+	if i == m.side {
+		name += " (you)"
+		draw = m.cell
+	}
+	title := fmt.Sprintf("%s  %d%%", name, rc.Board.Progress())
+	if i == m.side {
+		title += fmt.Sprintf("  mines %d/%d", rc.Board.Flags(), MINES)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, title, m.boardView(rc.Board, draw))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case WAITING:
+		return "Waiting for an opponent..."
+	case COUNTING:
+		left := max(0, time.Until(m.snap.Start))
+		return fmt.Sprintf("Starting in %d", int(left.Seconds())+1)
+	case PLAYING:
+		if m.snap.Hiccup != "" {
+			return m.snap.Hiccup
+		}
+		elapsed := max(0, time.Since(m.snap.Start)).Truncate(time.Second)
+		return fmt.Sprintf("Board #%d | %s", m.snap.Seed%10000, elapsed)
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	if m.snap.Winner == VOID {
+		return m.BoxStyle.Render(fmt.Sprintf("The race was called off after it kept crashing\n\nPress '%s' for a new race", m.Keys.Rematch.Help().Key))
+	}
+	winner := m.snap.Racers[m.snap.Winner]
+	result := winner.Name + " wins"
+	if m.snap.Winner == m.side {
+		result = "You win!"
+	}
+	switch loser := m.snap.Racers[1-m.snap.Winner]; {
+	case winner.Board.Won():
+		result += fmt.Sprintf(", cleared in %s", winner.Done.Round(time.Second/10))
+	case loser.Board.lost:
+		result += fmt.Sprintf(": %s hit a mine", loser.Name)
+	default:
+		result += fmt.Sprintf(": %s left", loser.Name)
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s\n\nPress '%s' for a new race", result, m.Keys.Rematch.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.snap.Phase == WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	body, _ := m.raceView()
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		body,
+	)
+}
+
+// raceView puts both boards side by side over the status, returning the
+// row of boards too so clicks can be placed on them.
+func (m Model) raceView() (body, boards string) {
+	bottom := m.status()
+	if m.snap.Phase == OVER {
+		bottom = m.resultView()
+	}
+	boards = lipgloss.JoinHorizontal(lipgloss.Top, m.racerView(m.side), "  ", m.racerView(1-m.side))
+	body = lipgloss.JoinVertical(
+		lipgloss.Center,
+		boards,
+		bottom,
+		m.help.ShortHelpView(m.Keys.ShortHelp()),
+	)
+	return body, boards
+}
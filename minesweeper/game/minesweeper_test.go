@@ -0,0 +1,29 @@
+package game
+
+import (
+	"io"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+func TestClickWhileWaiting(t *testing.T) {
+	m := NewModel(80, 30, lipgloss.NewRenderer(io.Discard), block.Player{Name: "alice"})
+	m.SetRoom(t.Name())
+	defer m.race.leave(m.side)
+	if m.snap.Phase != WAITING {
+		t.Fatalf("phase = %d, want WAITING", m.snap.Phase)
+	}
+	for _, button := range []tea.MouseButton{tea.MouseButtonLeft, tea.MouseButtonRight} {
+		for _, at := range [][2]int{{0, 0}, {40, 15}, {79, 29}} {
+			m.Update(tea.MouseMsg{X: at[0], Y: at[1], Button: button, Action: tea.MouseActionPress})
+		}
+	}
+	if _, ok := m.cellAt(40, 15); ok {
+		t.Error("cellAt found a cell before the race was dealt")
+	}
+	m.View()
+	m.racerView(1)
+}
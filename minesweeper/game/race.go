@@ -0,0 +1,310 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/supervisor"
+)
+
+const (
+	COUNTDOWN = 3 * time.Second
+	STALE     = 3 * time.Second // players not heard from for this long forfeit
+	HICCUP    = 3 * time.Second // how long players are told the race was rewound
+)
+
+// Race phases
+const (
+	WAITING = iota
+	COUNTING
+	PLAYING
+	OVER
+)
+
+// VOID is the winner of a race its supervisor gave up on.
+const VOID = -1
+
+type racer struct {
+	name  string
+	seat  block.Player
+	board *Board
+	done  time.Duration // time to clear the board, once cleared
+	seen  time.Time
+}
+
+// Race pits two players against copies of the same minefield. Like snake
+// duels it has no goroutine: moves and polls settle it as they come, under
+// a supervisor once the boards are dealt.
+type Race struct {
+	mu     sync.Mutex
+	phase  int
+	seed   int64
+	racers []*racer
+	start  time.Time // of play, once both joined
+	winner int
+	sup    *supervisor.Supervisor
+	hiccup string    // what players are told after a rewind
+	until  time.Time // of the hiccup
+}
+
+var (
+	racesMu sync.Mutex
+	waiting = make(map[string][]*Race) // by lobby room, empty for anyone
+)
+
+// join pairs p with the first player waiting for an opponent from the same
+// lobby room who neither blocked nor was blocked by them, or waits for one.
+func join(room string, p block.Player, now time.Time) (*Race, int) {
+	racesMu.Lock()
+	defer racesMu.Unlock()
+
+	// Rooms come and go, so forget the races nobody waits at anymore.
+	for k, waits := range waiting {
+		waits = slices.DeleteFunc(waits, func(r *Race) bool {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.drop(now)
+			return r.phase != WAITING || len(r.racers) == 0
+		})
+		if len(waits) == 0 {
+			delete(waiting, k)
+		} else {
+			waiting[k] = waits
+		}
+	}
+
+	for i, r := range waiting[room] {
+		r.mu.Lock()
+		if r.phase == WAITING && len(r.racers) == 1 && !block.Apart(r.racers[0].seat, p) {
+			waiting[room] = slices.Delete(waiting[room], i, i+1)
+			r.racers = append(r.racers, &racer{name: p.Label(), seat: p, seen: now})
+			r.countdown(now)
+			r.mu.Unlock()
+			return r, 1
+		}
+		r.mu.Unlock()
+	}
+
+	r := &Race{phase: WAITING, seed: now.UnixNano()}
+	r.racers = []*racer{{name: p.Label(), seat: p, seen: now}}
+	waiting[room] = append(waiting[room], r)
+	return r, 0
+}
+
+// countdown deals both racers the race's minefield.
+func (r *Race) countdown(now time.Time) {
+	r.phase = COUNTING
+	r.start = now.Add(COUNTDOWN)
+	for _, rc := range r.racers {
+		rc.board = newBoard(r.seed)
+	}
+	sup, err := supervisor.New("minesweeper race "+r.racers[0].name+" vs "+r.racers[1].name, r, supervisor.Options{
+		Notify: func(h supervisor.Hiccup) { r.hiccup, r.until = h.String(), time.Now().Add(HICCUP) },
+	})
+	if err != nil {
+		log.Warn("Could not supervise race", "err", err)
+	}
+	r.sup = sup
+}
+
+// tick settles the race as of now under the supervisor.
+func (r *Race) tick(now time.Time) {
+	if r.sup == nil {
+		r.advance(now)
+		return
+	}
+	r.void(r.sup.Step(now))
+}
+
+// guard plays fn under the supervisor.
+func (r *Race) guard(fn func()) {
+	if r.sup == nil {
+		fn()
+		return
+	}
+	r.void(r.sup.Apply(func(supervisor.Loop) { fn() }))
+}
+
+// void calls the race off once the supervisor gives up on it.
+func (r *Race) void(err error) {
+	if err == nil {
+		return
+	}
+	log.Error("Race given up", "err", err)
+	r.sup = nil
+	r.end(VOID)
+}
+
+// drop removes players that went quiet while waiting, and makes them
+// forfeit once the race is on.
+func (r *Race) drop(now time.Time) {
+	if r.phase == WAITING {
+		r.racers = slices.DeleteFunc(r.racers, func(rc *racer) bool { return now.Sub(rc.seen) > STALE })
+		return
+	}
+	if r.phase == OVER {
+		return
+	}
+	for i, rc := range r.racers {
+		if now.Sub(rc.seen) > STALE {
+			r.end(1 - i)
+			return
+		}
+	}
+}
+
+func (r *Race) advance(now time.Time) {
+	r.drop(now)
+	if r.phase == COUNTING && !now.Before(r.start) {
+		r.phase = PLAYING
+	}
+}
+
+func (r *Race) end(winner int) {
+	r.phase = OVER
+	r.winner = winner
+}
+
+// settle ends the race when racer i cleared their board or hit a mine.
+func (r *Race) settle(i int, now time.Time) {
+	b := r.racers[i].board
+	switch {
+	case b.lost:
+		r.end(1 - i)
+	case b.Won():
+		r.racers[i].done = now.Sub(r.start)
+		r.end(i)
+	}
+}
+
+// move applies a move of racer i to their board, if the race is on.
+func (r *Race) move(i int, now time.Time, f func(b *Board)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tick(now)
+	if r.phase != PLAYING || i >= len(r.racers) {
+		return
+	}
+	r.guard(func() {
+		f(r.racers[i].board)
+		r.settle(i, now)
+	})
+}
+
+func (r *Race) open(i int, p grid.Point) {
+	r.move(i, time.Now(), func(b *Board) { b.open(p) })
+}
+
+func (r *Race) flag(i int, p grid.Point) {
+	r.move(i, time.Now(), func(b *Board) { b.flag(p) })
+}
+
+// opponents are the players facing side i.
+func (r *Race) opponents(i int) []block.Player {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ps []block.Player
+	for j, s := range r.racers {
+		if j != i {
+			ps = append(ps, s.seat)
+		}
+	}
+	return ps
+}
+
+// leave forfeits racer i.
+func (r *Race) leave(i int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i < len(r.racers) {
+		r.racers[i].seen = time.Time{}
+	}
+	r.drop(time.Now())
+}
+
+// Racer is one side of the race as sessions render it.
+type Racer struct {
+	Name  string
+	Board *Board // nil until the race is dealt
+	Done  time.Duration
+}
+
+type Snapshot struct {
+	Phase  int
+	Seed   int64
+	Start  time.Time
+	Racers []Racer
+	Winner int
+	Hiccup string // set for a while after the race was rewound
+}
+
+// poll marks racer i as present, settles the race and describes it.
+func (r *Race) poll(i int, now time.Time) (Snapshot, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i >= len(r.racers) {
+		return Snapshot{}, false
+	}
+	r.racers[i].seen = now
+	r.tick(now)
+
+	s := Snapshot{Phase: r.phase, Seed: r.seed, Start: r.start, Winner: r.winner}
+	if now.Before(r.until) {
+		s.Hiccup = r.hiccup
+	}
+	for _, rc := range r.racers {
+		rr := Racer{Name: rc.name, Done: rc.done}
+		if rc.board != nil {
+			rr.Board = rc.board.clone()
+		}
+		s.Racers = append(s.Racers, rr)
+	}
+	return s, true
+}
+
+// saved is the part of a race its supervisor rewinds: the boards, not who
+// races on them or when they were last heard from.
+type saved struct {
+	Phase  int
+	Start  time.Time
+	Winner int
+	Boards []savedBoard
+	Done   []time.Duration
+}
+
+// Tick, Snapshot and Restore make the race a supervisor.Loop. The
+// supervisor only calls them from tick and guard, under the race's lock.
+func (r *Race) Tick(now time.Time) {
+	r.advance(now)
+}
+
+func (r *Race) Snapshot() ([]byte, error) {
+	s := saved{Phase: r.phase, Start: r.start, Winner: r.winner}
+	for _, rc := range r.racers {
+		s.Boards, s.Done = append(s.Boards, rc.board.save()), append(s.Done, rc.done)
+	}
+	return json.Marshal(s)
+}
+
+func (r *Race) Restore(snapshot []byte) error {
+	var s saved
+	if err := json.Unmarshal(snapshot, &s); err != nil {
+		return err
+	}
+	if len(s.Boards) != len(r.racers) {
+		return errors.New("snapshot of another race")
+	}
+	r.phase, r.start, r.winner = s.Phase, s.Start, s.Winner
+	for i, rc := range r.racers {
+		rc.board, rc.done = s.Boards[i].restore(), s.Done[i]
+	}
+	return nil
+}
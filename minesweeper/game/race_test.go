@@ -0,0 +1,33 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+func TestPanicRewindsRace(t *testing.T) {
+	// Deal the race a countdown ago, so it's on as of now.
+	then := time.Now().Add(-COUNTDOWN)
+	r, _ := join(t.Name(), block.Player{Name: "alice"}, then)
+	if other, _ := join(t.Name(), block.Player{Name: "bob"}, then); other != r {
+		t.Fatal("the racers weren't paired")
+	}
+	for _, rc := range r.racers {
+		rc.seen = time.Now()
+	}
+	left := r.racers[0].board.left
+
+	// A board without its flags panics on the first click.
+	r.racers[0].board.flagged = nil
+	r.flag(0, grid.Point{})
+	if r.racers[0].board.flagged == nil || r.racers[0].board.left != left {
+		t.Fatal("the board wasn't restored")
+	}
+	snap, _ := r.poll(0, time.Now())
+	if snap.Phase != PLAYING || snap.Hiccup == "" {
+		t.Errorf("phase %d, hiccup %q: want the race on and players told it was rewound", snap.Phase, snap.Hiccup)
+	}
+}
@@ -0,0 +1,239 @@
+// Package news reads the announcements operators post to the lobby from a
+// markdown-ish file they edit in place:
+//
+//	# News
+//
+//	## 2026-10-14 Checkers is here
+//	Play **checkers** against a friend or the computer.
+//
+//	- captures are forced
+//	- men reaching the far row are crowned
+//
+//	## 2026-10-01 18:00 Weekend tournament
+//	...
+//
+// Each "##" heading starts an entry with its date, and optionally the UTC
+// time, then its title. Lines before the first entry, like a "#" title, are
+// left out. Bodies are paragraphs and "-" or "*" bullets, with **bold** and
+// `code` spans.
+package news
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// RECHECK is how often a feed looks at its file for changes.
+const RECHECK = 10 * time.Second
+
+// Date layouts of entry headings, the longest first.
+var layouts = []string{"2006-01-02 15:04", "2006-01-02"}
+
+// Line is one line of an entry's body, blank between paragraphs.
+type Line struct {
+	Text   string
+	Bullet bool
+}
+
+type Entry struct {
+	Date  time.Time // in UTC
+	Title string
+	Body  []Line
+}
+
+// Parse reads the entries of a news file, the newest first.
+func Parse(data []byte) ([]Entry, error) {
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			e, err := parseHeading(strings.TrimSpace(heading))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			entries = append(entries, e)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		e := &entries[len(entries)-1]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			// Paragraphs are apart by one blank line, however many the
+			// file has.
+			if len(e.Body) > 0 && e.Body[len(e.Body)-1].Text != "" {
+				e.Body = append(e.Body, Line{})
+			}
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			e.Body = append(e.Body, Line{Text: strings.TrimSpace(trimmed[2:]), Bullet: true})
+		default:
+			e.Body = append(e.Body, Line{Text: trimmed})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Body = trimBlank(entries[i].Body)
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int { return b.Date.Compare(a.Date) })
+	return entries, nil
+}
+
+func parseHeading(heading string) (Entry, error) {
+	for _, layout := range layouts {
+		if len(heading) < len(layout) {
+			continue
+		}
+		date, err := time.Parse(layout, heading[:len(layout)])
+		if err != nil {
+			continue
+		}
+		title := strings.TrimSpace(heading[len(layout):])
+		if title == "" {
+			return Entry{}, errors.New("entry has no title")
+		}
+		return Entry{Date: date, Title: title}, nil
+	}
+	return Entry{}, fmt.Errorf("heading %q doesn't start with a date like 2006-01-02", heading)
+}
+
+// trimBlank drops a body's trailing blank line, if any.
+func trimBlank(body []Line) []Line {
+	if n := len(body); n > 0 && body[n-1].Text == "" {
+		return body[:n-1]
+	}
+	return body
+}
+
+// Unread counts the entries posted after seen, the date of the newest one
+// a player read.
+func Unread(entries []Entry, seen time.Time) int {
+	n := 0
+	for _, e := range entries {
+		if e.Date.After(seen) {
+			n++
+		}
+	}
+	return n
+}
+
+// Updated is the date of the newest entry, zero when there's none.
+func Updated(entries []Entry) time.Time {
+	if len(entries) == 0 {
+		return time.Time{}
+	}
+	return entries[0].Date
+}
+
+// Span is a run of a line's text set one way.
+type Span struct {
+	Text string
+	Bold bool
+	Code bool
+}
+
+// Spans splits text at its **bold** and `code` markers. Markers left open
+// are kept as they are.
+func Spans(text string) []Span {
+	var spans []Span
+	for text != "" {
+		i := strings.IndexAny(text, "*`")
+		if i < 0 {
+			spans = append(spans, Span{Text: text})
+			break
+		}
+		marker := "`"
+		if text[i] == '*' {
+			marker = "**"
+		}
+		end := -1
+		if strings.HasPrefix(text[i:], marker) {
+			end = strings.Index(text[i+len(marker):], marker)
+		}
+		if end <= 0 {
+			spans = append(spans, Span{Text: text[:i+1]})
+			text = text[i+1:]
+			continue
+		}
+		if i > 0 {
+			spans = append(spans, Span{Text: text[:i]})
+		}
+		inner := text[i+len(marker) : i+len(marker)+end]
+		spans = append(spans, Span{Text: inner, Bold: marker == "**", Code: marker == "`"})
+		text = text[i+2*len(marker)+end:]
+	}
+	return spans
+}
+
+// Feed is a news file, read again whenever operators change it.
+type Feed struct {
+	path string
+
+	mu      sync.Mutex
+	entries []Entry
+	mod     time.Time // of the file as last read
+	checked time.Time
+}
+
+// Open reads the news file at path. A missing file is an empty feed until
+// it's written.
+func Open(path string) *Feed {
+	f := &Feed{path: path}
+	f.reload(time.Now())
+	return f
+}
+
+// Entries are the feed's entries, the newest first. A file that stops
+// parsing keeps its last good entries, so typos don't blank the news.
+func (f *Feed) Entries() []Entry {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if now := time.Now(); now.Sub(f.checked) >= RECHECK {
+		f.reload(now)
+	}
+	return f.entries
+}
+
+func (f *Feed) reload(now time.Time) {
+	f.checked = now
+	info, err := os.Stat(f.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		f.entries, f.mod = nil, time.Time{}
+		return
+	case err != nil:
+		log.Error("Could not read news", "path", f.path, "error", err)
+		return
+	case info.ModTime().Equal(f.mod):
+		return
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		log.Error("Could not read news", "path", f.path, "error", err)
+		return
+	}
+	f.mod = info.ModTime()
+	entries, err := Parse(data)
+	if err != nil {
+		log.Error("Could not parse news", "path", f.path, "error", err)
+		return
+	}
+	f.entries = entries
+}
@@ -0,0 +1,143 @@
+// Package observe streams what happens on the server, rooms gathering and
+// sessions starting and ending, to outside tools such as scoreboards, chat
+// bots or stream overlays. The stream is read-only and carries events, never
+// frames, so tools don't have to scrape terminals.
+package observe
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/net/websocket"
+)
+
+// BUFFER is how many events a subscriber can fall behind by before it
+// misses some. Publishing never waits on a slow tool.
+const BUFFER = 64
+
+// Event kinds
+const (
+	SESSIONSTART = "session.start"
+	SESSIONEND   = "session.end"
+	ROOMOPEN     = "room.open"
+	ROOMUPDATE   = "room.update" // someone joined, left or readied up
+	ROOMSTART    = "room.start"
+	ROOMCLOSE    = "room.close"
+)
+
+// Event is one thing that happened, as tools receive it in JSON.
+type Event struct {
+	Kind    string    `json:"kind"`
+	At      time.Time `json:"at"`
+	Game    string    `json:"game,omitempty"` // title
+	Player  string    `json:"player,omitempty"`
+	Session int       `json:"session,omitempty"` // see spectate.Session
+	Room    string    `json:"room,omitempty"`    // join code
+	Players []string  `json:"players,omitempty"` // of a room
+	Ready   []string  `json:"ready,omitempty"`   // of a room's players
+}
+
+// Feed fans events out to subscribers. A nil feed drops everything, so
+// publishers don't need to check whether anyone observes.
+type Feed struct {
+	mu   sync.Mutex
+	subs map[chan Event]bool
+}
+
+func NewFeed() *Feed {
+	return &Feed{subs: make(map[chan Event]bool)}
+}
+
+// Publish sends e to every subscriber with room for it.
+func (f *Feed) Publish(e Event) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of the events published from now on, and a
+// function to stop receiving them.
+func (f *Feed) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, BUFFER)
+	f.mu.Lock()
+	f.subs[ch] = true
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.subs[ch] {
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Source describes what is going on right now as events, e.g. a
+// session.start for every session still running, so tools that connect
+// midway know where things stand.
+type Source func() []Event
+
+// Handler streams f over WebSocket, one JSON event per message, starting
+// with what sources report is already going on. Any origin may connect:
+// the stream is public and read-only, and most tools aren't browsers.
+func Handler(f *Feed, sources ...Source) http.Handler {
+	accept := func(*websocket.Config, *http.Request) error { return nil }
+	return websocket.Server{Handshake: accept, Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		events, stop := f.Subscribe()
+		defer stop()
+
+		// Tools only listen, so anything they send is ignored, but reading
+		// is how a closed connection is noticed.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			var discard []byte
+			for websocket.Message.Receive(ws, &discard) == nil {
+			}
+		}()
+
+		for _, src := range sources {
+			for _, e := range src() {
+				if send(ws, e) != nil {
+					return
+				}
+			}
+		}
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok || send(ws, e) != nil {
+					return
+				}
+			case <-closed:
+				return
+			case <-ws.Request().Context().Done():
+				return
+			}
+		}
+	}}
+}
+
+func send(ws *websocket.Conn, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Warn("Could not encode event", "kind", e.Kind, "err", err)
+		return nil
+	}
+	return websocket.Message.Send(ws, string(data))
+}
@@ -0,0 +1,67 @@
+package physics
+
+import "github.com/debemdeboas/games.debem.dev/grid"
+
+type Vec struct {
+	X, Y Fixed
+}
+
+func V(x, y Fixed) Vec { return Vec{X: x, Y: y} }
+
+func (v Vec) Add(w Vec) Vec { return Vec{X: v.X + w.X, Y: v.Y + w.Y} }
+
+func (v Vec) Sub(w Vec) Vec { return Vec{X: v.X - w.X, Y: v.Y - w.Y} }
+
+func (v Vec) Scale(f Fixed) Vec { return Vec{X: v.X.Mul(f), Y: v.Y.Mul(f)} }
+
+// Cell is the grid cell containing v.
+func (v Vec) Cell() grid.Point { return grid.Point{X: v.X.Int(), Y: v.Y.Int()} }
+
+// AABB is an axis-aligned box spanning [Min, Max).
+type AABB struct {
+	Min, Max Vec
+}
+
+func Box(x, y, w, h Fixed) AABB {
+	return AABB{Min: Vec{X: x, Y: y}, Max: Vec{X: x + w, Y: y + h}}
+}
+
+// CellBox is the box covering a single grid cell.
+func CellBox(p grid.Point) AABB {
+	return Box(FromInt(p.X), FromInt(p.Y), ONE, ONE)
+}
+
+func (a AABB) Overlaps(b AABB) bool {
+	return a.Min.X < b.Max.X && b.Min.X < a.Max.X &&
+		a.Min.Y < b.Max.Y && b.Min.Y < a.Max.Y
+}
+
+func (a AABB) Contains(v Vec) bool {
+	return v.X >= a.Min.X && v.X < a.Max.X && v.Y >= a.Min.Y && v.Y < a.Max.Y
+}
+
+// Body is a moving box. Pos is its top-left corner and Vel is measured in
+// cells per tick.
+type Body struct {
+	Pos  Vec
+	Vel  Vec
+	Size Vec
+}
+
+func (b Body) Bounds() AABB {
+	return AABB{Min: b.Pos, Max: b.Pos.Add(b.Size)}
+}
+
+func (b Body) Center() Vec {
+	return Vec{X: b.Pos.X + b.Size.X/2, Y: b.Pos.Y + b.Size.Y/2}
+}
+
+// Step integrates one fixed timestep.
+func (b *Body) Step() {
+	b.Pos = b.Pos.Add(b.Vel)
+}
+
+// Accelerate applies a per-tick acceleration such as gravity or thrust.
+func (b *Body) Accelerate(a Vec) {
+	b.Vel = b.Vel.Add(a)
+}
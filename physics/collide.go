@@ -0,0 +1,86 @@
+package physics
+
+// Axis identifies the side of a box a body hit.
+type Axis int
+
+const (
+	NONE Axis = iota
+	HORIZONTAL
+	VERTICAL
+)
+
+// Bounce resolves an overlap between b and an obstacle: the body is pushed
+// out along the axis of least penetration and its velocity is reflected on
+// that axis. It returns NONE when they don't touch.
+func Bounce(b *Body, obstacle AABB) Axis {
+	box := b.Bounds()
+	if !box.Overlaps(obstacle) {
+		return NONE
+	}
+
+	left := box.Max.X - obstacle.Min.X
+	right := obstacle.Max.X - box.Min.X
+	top := box.Max.Y - obstacle.Min.Y
+	bottom := obstacle.Max.Y - box.Min.Y
+
+	dx, dy := min(left, right), min(top, bottom)
+	if dx < dy {
+		if left < right {
+			b.Pos.X -= left
+			b.Vel.X = -b.Vel.X.Abs()
+		} else {
+			b.Pos.X += right
+			b.Vel.X = b.Vel.X.Abs()
+		}
+		return HORIZONTAL
+	}
+
+	if top < bottom {
+		b.Pos.Y -= top
+		b.Vel.Y = -b.Vel.Y.Abs()
+	} else {
+		b.Pos.Y += bottom
+		b.Vel.Y = b.Vel.Y.Abs()
+	}
+	return VERTICAL
+}
+
+// Confine keeps b inside bounds, bouncing off the walls it crosses. The
+// returned axes report which walls were hit this step.
+func Confine(b *Body, bounds AABB) (hitX, hitY bool) {
+	if b.Pos.X < bounds.Min.X {
+		b.Pos.X = bounds.Min.X
+		b.Vel.X = b.Vel.X.Abs()
+		hitX = true
+	} else if b.Pos.X+b.Size.X > bounds.Max.X {
+		b.Pos.X = bounds.Max.X - b.Size.X
+		b.Vel.X = -b.Vel.X.Abs()
+		hitX = true
+	}
+
+	if b.Pos.Y < bounds.Min.Y {
+		b.Pos.Y = bounds.Min.Y
+		b.Vel.Y = b.Vel.Y.Abs()
+		hitY = true
+	} else if b.Pos.Y+b.Size.Y > bounds.Max.Y {
+		b.Pos.Y = bounds.Max.Y - b.Size.Y
+		b.Vel.Y = -b.Vel.Y.Abs()
+		hitY = true
+	}
+	return hitX, hitY
+}
+
+// Wrap teleports b to the opposite edge when it leaves a w×h playfield, as
+// in Asteroids.
+func Wrap(b *Body, w, h Fixed) {
+	b.Pos.X = wrap(b.Pos.X, w)
+	b.Pos.Y = wrap(b.Pos.Y, h)
+}
+
+func wrap(f, size Fixed) Fixed {
+	f %= size
+	if f < 0 {
+		f += size
+	}
+	return f
+}
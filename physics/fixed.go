@@ -0,0 +1,44 @@
+// Package physics has the fixed-point helpers used by games whose objects
+// move in sub-cell steps: integration, AABB collisions and bounces.
+package physics
+
+import "math"
+
+// Fixed is a 24.8 fixed-point number. One cell is ONE; fixed-point keeps the
+// simulation deterministic across hosts, which replays and versus rooms need.
+type Fixed int32
+
+const (
+	SHIFT = 8
+	ONE   = Fixed(1 << SHIFT)
+	HALF  = ONE / 2
+)
+
+func FromInt(n int) Fixed { return Fixed(n) << SHIFT }
+
+func FromFloat(f float64) Fixed { return Fixed(math.Round(f * float64(ONE))) }
+
+// Frac builds num/den without going through floats.
+func Frac(num, den int) Fixed { return Fixed(int64(num) << SHIFT / int64(den)) }
+
+// Int truncates towards negative infinity, so -0.5 lands in cell -1.
+func (f Fixed) Int() int { return int(f >> SHIFT) }
+
+func (f Fixed) Round() int { return int((f + HALF) >> SHIFT) }
+
+func (f Fixed) Float() float64 { return float64(f) / float64(ONE) }
+
+func (f Fixed) Mul(g Fixed) Fixed { return Fixed(int64(f) * int64(g) >> SHIFT) }
+
+func (f Fixed) Div(g Fixed) Fixed { return Fixed(int64(f) << SHIFT / int64(g)) }
+
+func (f Fixed) Abs() Fixed {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func Clamp(f, lo, hi Fixed) Fixed {
+	return max(lo, min(f, hi))
+}
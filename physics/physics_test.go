@@ -0,0 +1,97 @@
+package physics
+
+import "testing"
+
+func body(x, y, vx, vy float64) Body {
+	return Body{Pos: V(FromFloat(x), FromFloat(y)), Vel: V(FromFloat(vx), FromFloat(vy)), Size: V(ONE, ONE)}
+}
+
+func TestFixed(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		got, want Fixed
+	}{
+		{"FromInt", FromInt(3), 3 * ONE},
+		{"Frac", Frac(1, 4), ONE / 4},
+		{"Mul", FromFloat(1.5).Mul(FromInt(2)), FromInt(3)},
+		{"Div", FromInt(3).Div(FromInt(2)), FromFloat(1.5)},
+		{"Abs", FromFloat(-0.5).Abs(), HALF},
+		{"Clamp", Clamp(FromInt(5), 0, ONE), ONE},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got.Float(), tc.want.Float())
+		}
+	}
+	if got := FromFloat(-0.5).Int(); got != -1 {
+		t.Errorf("Int(-0.5) = %d, want -1", got)
+	}
+	if got := FromFloat(2.5).Round(); got != 3 {
+		t.Errorf("Round(2.5) = %d, want 3", got)
+	}
+}
+
+func TestBounce(t *testing.T) {
+	wall := Box(FromInt(2), 0, ONE, FromInt(10)) // x in [2, 3)
+	for _, tc := range []struct {
+		name string
+		b    Body
+		axis Axis
+		want Body
+	}{
+		{"apart", body(0, 0, 1, 0), NONE, body(0, 0, 1, 0)},
+		// Touching edges don't overlap, as boxes are half open.
+		{"touching", body(1, 0, 1, 0), NONE, body(1, 0, 1, 0)},
+		{"from the left", body(1.25, 4, 0.5, 0.25), HORIZONTAL, body(1, 4, -0.5, 0.25)},
+		{"from the right", body(2.75, 4, -0.5, 0), HORIZONTAL, body(3, 4, 0.5, 0)},
+		{"from above", body(2, -0.75, 0, 0.5), VERTICAL, body(2, -1, 0, -0.5)},
+		// Already moving away on the axis, it keeps its direction.
+		{"moving away", body(1.25, 4, -0.5, 0), HORIZONTAL, body(1, 4, -0.5, 0)},
+	} {
+		b := tc.b
+		if axis := Bounce(&b, wall); axis != tc.axis || b != tc.want {
+			t.Errorf("%s: Bounce = %v, %+v, want %v, %+v", tc.name, axis, b, tc.axis, tc.want)
+		}
+	}
+}
+
+func TestConfine(t *testing.T) {
+	bounds := Box(0, 0, FromInt(10), FromInt(5))
+	for _, tc := range []struct {
+		name       string
+		b          Body
+		hitX, hitY bool
+		want       Body
+	}{
+		{"inside", body(4, 2, 1, 1), false, false, body(4, 2, 1, 1)},
+		{"left", body(-0.5, 2, -1, 0), true, false, body(0, 2, 1, 0)},
+		{"right", body(9.5, 2, 1, 0), true, false, body(9, 2, -1, 0)},
+		{"top", body(4, -1, 0, -1), false, true, body(4, 0, 0, 1)},
+		{"corner", body(9.5, 4.5, 1, 1), true, true, body(9, 4, -1, -1)},
+	} {
+		b := tc.b
+		hitX, hitY := Confine(&b, bounds)
+		if hitX != tc.hitX || hitY != tc.hitY || b != tc.want {
+			t.Errorf("%s: Confine = %v, %v, %+v, want %v, %v, %+v", tc.name, hitX, hitY, b, tc.hitX, tc.hitY, tc.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	w, h := FromInt(10), FromInt(5)
+	for _, tc := range []struct {
+		x, y   float64
+		wx, wy float64
+	}{
+		{3, 2, 3, 2},
+		{10, 5, 0, 0},
+		{10.5, 2, 0.5, 2},
+		{-0.5, -1, 9.5, 4},
+		{-20, 12, 0, 2},
+	} {
+		b := body(tc.x, tc.y, 0, 0)
+		Wrap(&b, w, h)
+		if want := V(FromFloat(tc.wx), FromFloat(tc.wy)); b.Pos != want {
+			t.Errorf("Wrap(%v, %v) = %v, %v, want %v, %v", tc.x, tc.y, b.Pos.X.Float(), b.Pos.Y.Float(), tc.wx, tc.wy)
+		}
+	}
+}
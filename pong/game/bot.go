@@ -0,0 +1,21 @@
+package game
+
+import "github.com/debemdeboas/games.debem.dev/physics"
+
+// BOTSPEED is how far the computer's paddle moves per tick. It's slower
+// than a steep ball so angled shots beat it.
+var BOTSPEED = physics.Frac(1, 4)
+
+// steer moves side i's paddle towards where it means to meet the ball: a
+// little off its centre, picked at every hit so the computer misses now and
+// then. It drifts back to the middle while the ball heads away.
+func (m *Match) steer(i int) {
+	s := m.sides[i]
+	half := physics.FromInt(PADDLE) / 2
+	target := physics.FromInt(HEIGHT)/2 - half
+	if coming := (m.ball.Vel.X < 0) == (i == 0); coming && m.serving == 0 {
+		target = m.ball.Center().Y - half + m.aim
+	}
+	delta := physics.Clamp(target-s.y, -BOTSPEED, BOTSPEED)
+	s.y = physics.Clamp(s.y+delta, 0, physics.FromInt(HEIGHT-PADDLE))
+}
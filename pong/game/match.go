@@ -0,0 +1,362 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/physics"
+	"github.com/debemdeboas/games.debem.dev/timesync"
+)
+
+const (
+	WIDTH     = 60
+	HEIGHT    = 20
+	PADDLE    = 4                     // rows a paddle covers
+	TICK      = 20 * time.Millisecond // of the simulation, whatever the frame rate
+	SERVE     = 50                    // ticks the ball rests before a serve
+	WINSCORE  = 11
+	COUNTDOWN = 3 * time.Second
+	STALE     = 3 * time.Second // players not heard from for this long forfeit
+)
+
+// Ball speeds, in cells per tick. The ball stays under a cell per tick so it
+// can't skip through a paddle.
+var (
+	SPEED    = physics.Frac(2, 5)
+	SPEEDUP  = physics.Frac(21, 20) // per paddle hit
+	MAXSPEED = physics.Frac(9, 10)
+)
+
+// Match phases
+const (
+	WAITING = iota
+	COUNTING
+	PLAYING
+	OVER
+)
+
+// BOTNAME names the computer opponent.
+const BOTNAME = "Bot"
+
+// paddleX is the column of each side's paddle.
+var paddleX = [2]int{1, WIDTH - 2}
+
+type side struct {
+	name  string
+	seat  block.Player  // who sat down, empty for the computer
+	y     physics.Fixed // top of the paddle
+	score int
+	bot   bool // steered by the match itself, never goes quiet
+	seen  time.Time
+}
+
+// Match is a court shared by two sessions, or by a player and the computer.
+// Like snake duels it has no goroutine: whichever session polls runs the
+// ticks that passed, so both always see the same ball. Paddles only move
+// through the input buffer, on the tick it schedules each press for.
+type Match struct {
+	mu      sync.Mutex
+	phase   int
+	sides   []*side
+	ball    physics.Body
+	speed   physics.Fixed
+	serving int           // ticks left before the ball moves
+	aim     physics.Fixed // where the bot means to meet the ball, off its paddle's centre
+	start   time.Time     // of play, once both joined
+	next    time.Time     // of the next tick
+	tick    time.Duration // between ticks, TICK unless in slow mode
+	inputs  *timesync.Buffer
+	winner  int
+	rng     *rand.Rand
+}
+
+var (
+	matchesMu sync.Mutex
+	waiting   = make(map[string][]*Match) // by lobby room, empty for anyone
+)
+
+func newMatch(p block.Player, now time.Time) *Match {
+	m := &Match{phase: WAITING, tick: TICK, inputs: timesync.NewBuffer(TICK), rng: rand.New(rand.NewSource(now.UnixNano()))}
+	m.sides = []*side{{name: p.Label(), seat: p, seen: now}}
+	return m
+}
+
+// join pairs p with the first player waiting for an opponent from the same
+// lobby room who neither blocked nor was blocked by them, or waits for one.
+func join(room string, p block.Player, now time.Time) (*Match, int) {
+	matchesMu.Lock()
+	defer matchesMu.Unlock()
+
+	// Rooms come and go, so forget the matches nobody waits at anymore.
+	for k, waits := range waiting {
+		waits = slices.DeleteFunc(waits, func(m *Match) bool {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.drop(now)
+			return m.phase != WAITING || len(m.sides) == 0
+		})
+		if len(waits) == 0 {
+			delete(waiting, k)
+		} else {
+			waiting[k] = waits
+		}
+	}
+
+	for i, m := range waiting[room] {
+		m.mu.Lock()
+		if m.phase == WAITING && len(m.sides) == 1 && !block.Apart(m.sides[0].seat, p) {
+			waiting[room] = slices.Delete(waiting[room], i, i+1)
+			m.sides = append(m.sides, &side{name: p.Label(), seat: p, seen: now})
+			m.countdown(now)
+			m.mu.Unlock()
+			return m, 1
+		}
+		m.mu.Unlock()
+	}
+
+	m := newMatch(p, now)
+	waiting[room] = append(waiting[room], m)
+	return m, 0
+}
+
+// versusBot starts a match between p and the computer, slowed down
+// for slow mode.
+func versusBot(p block.Player, now time.Time, slow bool) (*Match, int) {
+	m := newMatch(p, now)
+	if slow {
+		m.tick *= games.SLOWDOWN
+		m.inputs.Clock.Period = m.tick
+	}
+	m.sides = append(m.sides, &side{name: BOTNAME, bot: true})
+	m.countdown(now)
+	return m, 0
+}
+
+// countdown centres the paddles and readies the first serve, towards a
+// random side.
+func (m *Match) countdown(now time.Time) {
+	m.phase = COUNTING
+	m.start = now.Add(COUNTDOWN)
+	m.next = m.start
+	m.inputs.Pending = nil
+	for _, s := range m.sides {
+		s.y = physics.FromInt(HEIGHT-PADDLE) / 2
+		s.score = 0
+	}
+	m.serve(m.rng.Intn(2))
+}
+
+// serve puts the ball back in the middle, to head for side towards once it
+// has rested.
+func (m *Match) serve(towards int) {
+	m.speed = SPEED
+	m.ball = physics.Body{
+		Pos:  physics.V(physics.FromInt(WIDTH/2), physics.FromInt(HEIGHT/2)),
+		Size: physics.V(physics.ONE, physics.ONE),
+	}
+	m.ball.Vel.X = m.speed
+	if towards == 0 {
+		m.ball.Vel.X = -m.speed
+	}
+	m.ball.Vel.Y = physics.Fixed(m.rng.Intn(int(m.speed))) - m.speed/2
+	m.serving = SERVE
+}
+
+// drop removes players that went quiet while waiting, and makes them
+// forfeit once the match is on.
+func (m *Match) drop(now time.Time) {
+	if m.phase == WAITING {
+		m.sides = slices.DeleteFunc(m.sides, func(s *side) bool { return m.quiet(s, now) })
+		return
+	}
+	if m.phase == OVER {
+		return
+	}
+	for i, s := range m.sides {
+		if m.quiet(s, now) {
+			m.end(1 - i)
+			return
+		}
+	}
+}
+
+func (m *Match) quiet(s *side, now time.Time) bool {
+	return !s.bot && now.Sub(s.seen) > STALE
+}
+
+func (m *Match) end(winner int) {
+	m.phase = OVER
+	m.winner = winner
+}
+
+// advance runs every tick due by now.
+func (m *Match) advance(now time.Time) {
+	m.drop(now)
+	if m.phase == COUNTING && !now.Before(m.start) {
+		m.phase = PLAYING
+	}
+	for m.phase == PLAYING && !now.Before(m.next) {
+		m.step()
+		m.next = m.next.Add(m.tick)
+	}
+}
+
+func paddleBox(i int, y physics.Fixed) physics.AABB {
+	return physics.Box(physics.FromInt(paddleX[i]), y, physics.ONE, physics.FromInt(PADDLE))
+}
+
+// court bounds the ball between the top and bottom walls. It reaches past
+// the sides so the ball can leave for a point.
+var court = physics.AABB{
+	Min: physics.V(-physics.FromInt(WIDTH), 0),
+	Max: physics.V(physics.FromInt(2*WIDTH), physics.FromInt(HEIGHT)),
+}
+
+// step moves the paddles by the inputs due and the ball one tick: off the
+// walls, off the paddles and out for a point.
+func (m *Match) step() {
+	m.inputs.Clock.Advance()
+	for _, in := range m.inputs.Due() {
+		i, _ := strconv.Atoi(in.Player)
+		dy, _ := strconv.Atoi(in.Value)
+		s := m.sides[i]
+		s.y = physics.Clamp(s.y+physics.FromInt(dy), 0, physics.FromInt(HEIGHT-PADDLE))
+	}
+	for i, s := range m.sides {
+		if s.bot {
+			m.steer(i)
+		}
+	}
+	if m.serving > 0 {
+		m.serving--
+		return
+	}
+
+	b := &m.ball
+	b.Step()
+	physics.Confine(b, court)
+	for i, s := range m.sides {
+		if physics.Bounce(b, paddleBox(i, s.y)) == physics.HORIZONTAL {
+			m.hit(i)
+		}
+	}
+
+	switch {
+	case b.Pos.X+b.Size.X < 0:
+		m.point(1)
+	case b.Pos.X > physics.FromInt(WIDTH):
+		m.point(0)
+	}
+}
+
+// hit returns the ball from side i's paddle, faster, at an angle set by how
+// far from the paddle's centre it landed.
+func (m *Match) hit(i int) {
+	m.speed = min(MAXSPEED, m.speed.Mul(SPEEDUP))
+	half := physics.FromInt(PADDLE) / 2
+	off := physics.Clamp(m.ball.Center().Y-(m.sides[i].y+half), -half, half).Div(half)
+
+	m.ball.Vel.X = m.speed
+	if i == 1 {
+		m.ball.Vel.X = -m.speed
+	}
+	m.ball.Vel.Y = off.Mul(m.speed) * 3 / 4
+	m.aim = physics.Fixed(m.rng.Intn(int(physics.FromInt(PADDLE)))) - half
+}
+
+// point scores for side i, ending the match at WINSCORE.
+func (m *Match) point(i int) {
+	m.sides[i].score++
+	if m.sides[i].score >= WINSCORE {
+		m.end(i)
+		return
+	}
+	m.serve(1 - i)
+}
+
+// move has side i's paddle shift by dy rows, once the input buffer lets
+// the press land. The ticks due by now run first, so it's scheduled from
+// the tick it arrived on.
+func (m *Match) move(i, dy int, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.advance(now)
+	if m.phase == OVER || i >= len(m.sides) {
+		return
+	}
+	m.inputs.Submit(strconv.Itoa(i), strconv.Itoa(dy))
+}
+
+// measure records side i's round trip, for the input buffer to make up
+// for.
+func (m *Match) measure(i int, rtt time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < len(m.sides) && !m.sides[i].bot {
+		m.inputs.SetLatency(strconv.Itoa(i), rtt)
+	}
+}
+
+// opponents are the players facing side i, the computer left out.
+func (m *Match) opponents(i int) []block.Player {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ps []block.Player
+	for j, s := range m.sides {
+		if !s.bot && j != i {
+			ps = append(ps, s.seat)
+		}
+	}
+	return ps
+}
+
+// leave forfeits side i.
+func (m *Match) leave(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < len(m.sides) {
+		m.sides[i].seen = time.Time{}
+		m.inputs.Leave(strconv.Itoa(i))
+	}
+	m.drop(time.Now())
+}
+
+// Paddle is one side of the court as sessions render it.
+type Paddle struct {
+	Name  string
+	Y     physics.Fixed
+	Score int
+}
+
+type Snapshot struct {
+	Phase   int
+	Start   time.Time
+	Ball    physics.Vec
+	Serving bool
+	Paddles []Paddle
+	Winner  int
+}
+
+// poll marks side i as present, runs the ticks due and describes the court.
+func (m *Match) poll(i int, now time.Time) (Snapshot, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i >= len(m.sides) {
+		return Snapshot{}, false
+	}
+	m.sides[i].seen = now
+	m.advance(now)
+
+	s := Snapshot{Phase: m.phase, Start: m.start, Ball: m.ball.Pos, Serving: m.serving > 0, Winner: m.winner}
+	for _, sd := range m.sides {
+		s.Paddles = append(s.Paddles, Paddle{Name: sd.name, Y: sd.y, Score: sd.score})
+	}
+	return s, true
+}
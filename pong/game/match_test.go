@@ -0,0 +1,30 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+func TestMoveLandsThroughBuffer(t *testing.T) {
+	now := time.Now()
+	m, side := versusBot(block.Player{Name: "alice"}, now, false)
+	m.measure(side, 0)
+	now = m.start
+	m.poll(side, now)
+	y := m.sides[side].y
+
+	m.move(side, 1, now)
+	if m.sides[side].y != y {
+		t.Fatal("the paddle moved before the tick the buffer scheduled")
+	}
+	due := m.inputs.Pending[0].Tick
+	for m.inputs.Clock.Tick < due {
+		now = now.Add(m.tick)
+		m.poll(side, now)
+	}
+	if m.sides[side].y == y {
+		t.Errorf("the paddle hadn't moved by tick %d", due)
+	}
+}
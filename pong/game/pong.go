@@ -0,0 +1,318 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/latency"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 2 * TICK
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles, one paddle style per side
+	PaddleStyles [2]lipgloss.Style
+	BallStyle    lipgloss.Style
+	NetStyle     lipgloss.Style
+	BoardStyle   lipgloss.Style
+	QuitStyle    lipgloss.Style
+	BoxStyle     lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room string
+	// Latency, when set, reports the session's network round trip, which
+	// the match makes up for.
+	Latency *latency.Meter
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell  *bell.Bell
+	count bell.Countdown
+	match *Match
+	side  int
+	snap  Snapshot
+	slow  bool // see SetSlow
+	// cast follows the match for casters, see Scoreboard.
+	cast spectate.Match
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:  width,
+		Height: height,
+		PaddleStyles: [2]lipgloss.Style{
+			r.NewStyle().Foreground(lipgloss.Color("10")),
+			r.NewStyle().Foreground(lipgloss.Color("39")),
+		},
+		BallStyle:  r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		NetStyle:   r.NewStyle().Foreground(lipgloss.Color("238")),
+		BoardStyle: r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = join(m.Room, m.Player, now)
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+// SetSlow has matches against the computer run slower, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// PlayBot gives up waiting and plays the computer instead.
+func (m *Model) PlayBot() {
+	m.match.leave(m.side)
+	now := time.Now()
+	m.match, m.side = versusBot(m.Player, now, m.slow)
+	m.refresh(now)
+}
+
+func (m *Model) refresh(now time.Time) {
+	m.match.measure(m.side, m.Latency.RTT())
+	snap, ok := m.match.poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	m.cue(m.snap, snap, now)
+	m.snap = snap
+	m.score()
+}
+
+// score follows the match for casters, who see the points of each side.
+func (m *Model) score() {
+	if m.snap.Phase == COUNTING {
+		m.cast = spectate.Match{Title: m.Name()}
+	}
+	var points []int
+	for _, p := range m.snap.Paddles {
+		points = append(points, p.Score)
+	}
+	m.cast.Track(points...)
+	for i, p := range m.snap.Paddles {
+		m.cast.Players[i].Name = p.Name
+	}
+}
+
+// Scoreboard is the match for casters, see spectate.Scoreboard.
+func (m Model) Scoreboard() (spectate.Match, bool) {
+	return m.cast, m.snap.Phase != WAITING
+}
+
+// cue rings for what changed between snapshots: the countdown's seconds,
+// and the player losing the match.
+func (m *Model) cue(prev, snap Snapshot, now time.Time) {
+	left := time.Duration(0)
+	if snap.Phase == COUNTING {
+		left = snap.Start.Sub(now)
+	}
+	m.count.Update(m.Bell, left)
+
+	if prev.Phase == PLAYING && snap.Phase == OVER && snap.Winner != m.side {
+		m.Bell.Ring(bell.DEATH)
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Bot):
+			if m.snap.Phase == WAITING || m.snap.Phase == OVER {
+				m.PlayBot()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.match.move(m.side, -1, time.Now())
+		case key.Matches(msg, m.Keys.Down):
+			m.match.move(m.side, 1, time.Now())
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func (m Model) courtView() string {
+	court := grid.New[string](WIDTH, HEIGHT)
+	court.Fill(" ")
+	for y := 0; y < HEIGHT; y += 2 {
+		court.Set(grid.Point{X: WIDTH / 2, Y: y}, m.NetStyle.Render("│"))
+	}
+	for i, p := range m.snap.Paddles {
+		top := p.Y.Round()
+		for y := top; y < top+PADDLE; y++ {
+			court.Set(grid.Point{X: paddleX[i], Y: y}, m.PaddleStyles[i].Render("█"))
+		}
+	}
+	if ball := (grid.Point{X: m.snap.Ball.X.Round(), Y: m.snap.Ball.Y.Round()}); court.InBounds(ball) {
+		court.Set(ball, m.BallStyle.Render("●"))
+	}
+
+	var s strings.Builder
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			s.WriteString(court.At(grid.Point{X: x, Y: y}))
+		}
+		if y < HEIGHT-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Paddles {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		sides = append(sides, m.PaddleStyles[i].Render(fmt.Sprintf("%s %d", name, p.Score)))
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case WAITING:
+		return fmt.Sprintf("Waiting for an opponent...\n\nPress '%s' to play the computer", m.Keys.Bot.Help().Key)
+	case COUNTING:
+		left := max(0, time.Until(m.snap.Start))
+		return fmt.Sprintf("Starting in %d", int(left.Seconds())+1)
+	case PLAYING:
+		if m.snap.Serving {
+			return "Get ready..."
+		}
+		return fmt.Sprintf("First to %d", WINSCORE)
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	result := m.snap.Paddles[m.snap.Winner].Name + " wins"
+	if m.snap.Winner == m.side {
+		result = "You win!"
+	}
+	score := fmt.Sprintf("%d - %d", m.snap.Paddles[m.side].Score, m.snap.Paddles[1-m.side].Score)
+	return m.BoxStyle.Render(fmt.Sprintf("%s %s\n\nPress '%s' for a new match or '%s' to play the computer",
+		result, score, m.Keys.Rematch.Help().Key, m.Keys.Bot.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.snap.Phase == WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			m.courtView(),
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
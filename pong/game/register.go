@@ -0,0 +1,45 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "pong"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Pong",
+	Description: "First to 11 against the computer or another player",
+	Category:    games.ARCADE,
+	MinPlayers:  1,
+	MaxPlayers:  2,
+	Spectating:  true,
+	Rooms:       true,
+	Session:     5 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.Bell = env.Bell
+		m.Latency = env.Latency
+		m.SetSlow(env.Slow)
+		if env.Room != "" {
+			m.SetRoom(env.Room)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
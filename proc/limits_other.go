@@ -0,0 +1,25 @@
+//go:build !unix
+
+package proc
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/charmbracelet/log"
+)
+
+// command starts the game directly: resource limits need ulimit, so they
+// aren't enforced on this platform.
+func command(ctx context.Context, g Game) *exec.Cmd {
+	log.Warn("Resource limits are not enforced on this platform", "game", g.Info.ID)
+	return exec.CommandContext(ctx, g.Path)
+}
+
+func limitExceeded(err error) string {
+	return ""
+}
+
+func residentMemory(pid int) int64 {
+	return 0
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// CPUGRACE is how many CPU seconds past the soft limit a child that ignores
+// SIGXCPU gets before the kernel kills it.
+const CPUGRACE = 2
+
+// command starts the game under a shell that applies the limits with ulimit
+// before exec'ing it, in its own process group so everything it spawns is
+// killed with it. The hard CPU limit sits a little above the soft one so the
+// child gets SIGXCPU, which tells a CPU overrun apart from other kills.
+func command(ctx context.Context, g Game) *exec.Cmd {
+	var limits string
+	if g.Limits.CPU > 0 {
+		cpu := max(1, int(g.Limits.CPU.Seconds()))
+		limits += fmt.Sprintf("ulimit -S -t %d && ulimit -H -t %d && ", cpu, cpu+CPUGRACE)
+	}
+	if g.Limits.Memory > 0 {
+		limits += fmt.Sprintf("ulimit -v %d && ", max(1, g.Limits.Memory>>10))
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", limits+`exec "$0"`, g.Path)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd
+}
+
+// limitExceeded explains an exit caused by a resource limit.
+func limitExceeded(err error) string {
+	exit, ok := err.(*exec.ExitError)
+	if !ok {
+		return ""
+	}
+	status, ok := exit.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return "the game used up its CPU time"
+	case syscall.SIGSEGV, syscall.SIGABRT:
+		return "the game crashed, possibly out of memory"
+	}
+	return ""
+}
+
+// residentMemory reads a process's resident set from /proc, reporting 0
+// where there's no /proc.
+func residentMemory(pid int) int64 {
+	statm, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, _ := strconv.ParseInt(fields[1], 10, 64)
+	return pages * int64(os.Getpagesize())
+}
@@ -0,0 +1,204 @@
+package proc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	INPUTQUEUE = 64              // messages buffered for a child that reads slowly
+	QUITGRACE  = 2 * time.Second // how long a child gets to exit after "quit"
+)
+
+type frameMsg string
+
+type exitMsg struct{ err error }
+
+type killMsg struct{}
+
+// Model runs a community game for one player. It shows the child's latest
+// frame and forwards keys and window sizes to it.
+type Model struct {
+	game   Game
+	input  chan Message
+	frames chan string
+	done   chan error
+	cancel context.CancelFunc
+	pid    int
+
+	frame    string
+	exited   bool
+	err      error
+	quitting bool
+}
+
+// Start launches g for player. The child is killed when ctx is done, so
+// pass the session's context.
+func Start(ctx context.Context, g Game, player string, width, height int) (*Model, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := command(ctx, g)
+	cmd.WaitDelay = QUITGRACE
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	cmd.Stderr = log.StandardLog(log.StandardLogOptions{ForceLevel: log.WarnLevel}).Writer()
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	m := &Model{
+		game:   g,
+		input:  make(chan Message, INPUTQUEUE),
+		frames: make(chan string, 1),
+		done:   make(chan error, 1),
+		cancel: cancel,
+		pid:    cmd.Process.Pid,
+	}
+	m.send(Message{Type: INIT, Player: player, Width: width, Height: height})
+
+	// Writer: ends when the child stops reading or the session ends.
+	go func() {
+		enc := json.NewEncoder(stdin)
+		defer stdin.Close()
+		for {
+			select {
+			case msg := <-m.input:
+				if err := enc.Encode(msg); err != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Reader: ends when the child exits or sends something too large.
+	go func() {
+		sc := bufio.NewScanner(stdout)
+		if g.Limits.Frame > 0 {
+			// The scanner takes the larger of the two as its limit.
+			sc.Buffer(make([]byte, 0, min(4096, g.Limits.Frame)), g.Limits.Frame)
+		}
+		for sc.Scan() {
+			var msg Message
+			if err := json.Unmarshal(sc.Bytes(), &msg); err != nil || msg.Type != FRAME {
+				continue
+			}
+			// Keep only the latest frame if the host falls behind.
+			select {
+			case <-m.frames:
+			default:
+			}
+			m.frames <- msg.Frame
+		}
+		if err := sc.Err(); err != nil {
+			cancel()
+			cmd.Wait()
+			m.done <- fmt.Errorf("the game sent a frame over %d bytes", g.Limits.Frame)
+			return
+		}
+		m.done <- cmd.Wait()
+	}()
+
+	return m, nil
+}
+
+// send queues a message, dropping it if the child isn't keeping up.
+func (m *Model) send(msg Message) {
+	select {
+	case m.input <- msg:
+	default:
+	}
+}
+
+func (m *Model) wait() tea.Msg {
+	select {
+	case f := <-m.frames:
+		return frameMsg(f)
+	case err := <-m.done:
+		return exitMsg{err}
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.wait
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case frameMsg:
+		m.frame = string(msg)
+		return m, m.wait
+	case exitMsg:
+		m.exited = true
+		m.cancel()
+		if msg.err != nil && !m.quitting {
+			m.err = msg.err
+			log.Warn("Community game stopped", "game", m.game.Info.ID, "err", msg.err)
+			return m, nil
+		}
+		return m, tea.Quit
+	case killMsg:
+		m.cancel()
+	case tea.WindowSizeMsg:
+		m.send(Message{Type: RESIZE, Width: msg.Width, Height: msg.Height})
+	case tea.KeyMsg:
+		switch {
+		case m.exited:
+			// Any key dismisses the error screen.
+			return m, tea.Quit
+		case msg.Type == tea.KeyCtrlC:
+			// The host always owns ctrl+c, whatever the game does with it.
+			m.quitting = true
+			m.send(Message{Type: QUIT})
+			return m, tea.Tick(QUITGRACE, func(time.Time) tea.Msg { return killMsg{} })
+		default:
+			m.send(Message{Type: KEY, Key: msg.String()})
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) View() string {
+	if m.err != nil {
+		reason := limitExceeded(m.err)
+		if reason == "" {
+			reason = m.err.Error()
+		}
+		return fmt.Sprintf("%s stopped: %s\n\nPress any key to leave.", m.game.Info.Title, reason)
+	}
+	return m.frame
+}
+
+// Stop kills the child.
+func (m *Model) Stop() {
+	m.cancel()
+}
+
+// MemoryUsage reports the child's resident memory.
+func (m *Model) MemoryUsage() int64 {
+	return residentMemory(m.pid)
+}
+
+func (m *Model) Name() string {
+	return m.game.Info.Title
+}
+
+func (m *Model) Description() string {
+	return m.game.Info.Description
+}
@@ -0,0 +1,118 @@
+// Package proc serves community games run as child processes, so a game
+// dropped into the games directory is playable without rebuilding the
+// server.
+//
+// A game is an executable next to a manifest named after it plus ".json".
+// The host and the child exchange one JSON message per line: the host writes
+// "init", "key", "resize" and "quit" messages to the child's stdin, and the
+// child answers with "frame" messages on stdout, each holding the whole
+// screen. The session ends when the child exits. Stderr goes to the server
+// log.
+package proc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/games"
+)
+
+// Message types.
+const (
+	INIT   = "init"   // host: first message, with the player and window size
+	KEY    = "key"    // host: a key press, named like bubbletea's KeyMsg.String
+	RESIZE = "resize" // host: the window changed size
+	QUIT   = "quit"   // host: the player is leaving; exit promptly
+	FRAME  = "frame"  // child: the screen to show
+)
+
+type Message struct {
+	Type   string `json:"type"`
+	Player string `json:"player,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Frame  string `json:"frame,omitempty"`
+}
+
+// Limits bound what one child may use. Zero fields are unlimited.
+type Limits struct {
+	CPU    time.Duration // CPU time before the kernel stops the child
+	Memory int64         // bytes of address space
+	Frame  int           // largest message the host reads, in bytes
+}
+
+var DefaultLimits = Limits{
+	CPU:    5 * time.Minute,
+	Memory: 256 << 20,
+	Frame:  256 << 10,
+}
+
+// Game is a discovered community game.
+type Game struct {
+	Info   games.Info
+	Path   string
+	Limits Limits
+}
+
+// Discover reads every manifest in dir. Manifests without an executable
+// next to them, or that don't parse, are logged and skipped.
+func Discover(dir string, limits Limits) ([]Game, error) {
+	manifests, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []Game
+	for _, path := range manifests {
+		g, err := load(path, limits)
+		if err != nil {
+			log.Warn("Skipping community game", "manifest", path, "err", err)
+			continue
+		}
+		found = append(found, g)
+	}
+	return found, nil
+}
+
+func load(path string, limits Limits) (Game, error) {
+	info, err := games.LoadManifest(path)
+	if err != nil {
+		return Game{}, err
+	}
+
+	bin := strings.TrimSuffix(path, ".json")
+	st, err := os.Stat(bin)
+	if err != nil {
+		return Game{}, err
+	}
+	if st.IsDir() || st.Mode()&0o111 == 0 {
+		return Game{}, fmt.Errorf("%s is not executable", bin)
+	}
+	return Game{Info: info, Path: bin, Limits: limits}, nil
+}
+
+// RegisterDir discovers the games in dir and adds them to the games
+// registry.
+func RegisterDir(dir string, limits Limits) error {
+	found, err := Discover(dir, limits)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range found {
+		if _, taken := games.Lookup(g.Info.ID); taken {
+			log.Warn("Skipping community game with a taken ID", "id", g.Info.ID, "path", g.Path)
+			continue
+		}
+		games.Register(g.Info, func(env games.Env) (games.Game, error) {
+			return Start(env.Ctx, g, env.Player, env.Width, env.Height)
+		})
+		log.Info("Registered community game", "id", g.Info.ID, "path", g.Path)
+	}
+	return nil
+}
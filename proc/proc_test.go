@@ -0,0 +1,124 @@
+//go:build unix
+
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// echo shows "ready", then "key <n>" for the nth key, and exits on quit.
+const echo = `#!/bin/sh
+read init
+echo '{"type":"frame","frame":"ready"}'
+n=0
+while read msg; do
+	case "$msg" in
+	*'"quit"'*) exit 0 ;;
+	*'"key"'*) n=$((n+1)); echo '{"type":"frame","frame":"key '$n'"}' ;;
+	esac
+done
+`
+
+// write puts a game named name in dir, with the script as its executable
+// unless it's empty.
+func write(t *testing.T, dir, name, manifest, script string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if script != "" {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "echo", `{"title": "Echo", "max_players": 2}`, echo, 0o755)
+	write(t, dir, "missing", `{}`, "", 0)
+	write(t, dir, "plain", `{}`, echo, 0o644)
+	write(t, dir, "broken", `{`, echo, 0o755)
+
+	found, err := Discover(dir, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("Discover found %d games, want only the executable one with a manifest", len(found))
+	}
+	g := found[0]
+	if g.Info.ID != "echo" || g.Info.Title != "Echo" || g.Info.MaxPlayers != 2 || g.Path != filepath.Join(dir, "echo") {
+		t.Errorf("Discover = %+v", g)
+	}
+}
+
+// next waits for the child's next message and hands it to m.
+func next(t *testing.T, m *Model) tea.Cmd {
+	t.Helper()
+	msg := make(chan tea.Msg, 1)
+	go func() { msg <- m.wait() }()
+	select {
+	case msg := <-msg:
+		_, cmd := m.Update(msg)
+		return cmd
+	case <-time.After(5 * time.Second):
+		t.Fatal("the child sent nothing")
+		return nil
+	}
+}
+
+func TestStart(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "echo", `{}`, echo, 0o755)
+	g := Game{Path: filepath.Join(dir, "echo"), Limits: DefaultLimits}
+	m, err := Start(context.Background(), g, "ana", 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	next(t, m)
+	if m.View() != "ready" {
+		t.Fatalf("first frame %q, want ready", m.View())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	next(t, m)
+	if m.View() != "key 1" && m.View() != "key 2" {
+		t.Fatalf("frame %q after the keys, want them echoed", m.View())
+	}
+	// The child exits on quit, which ends the session cleanly.
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	var cmd tea.Cmd
+	for !m.exited {
+		cmd = next(t, m)
+	}
+	if m.err != nil || cmd == nil {
+		t.Errorf("after quitting: err %v, quit %v", m.err, cmd != nil)
+	}
+}
+
+func TestFrameLimit(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nread init\nprintf '{\"type\":\"frame\",\"frame\":\"" + strings.Repeat("x", 2048) + "\"}\\n'\nsleep 10\n"
+	write(t, dir, "big", `{}`, script, 0o755)
+	g := Game{Path: filepath.Join(dir, "big"), Limits: Limits{Frame: 1024}}
+	m, err := Start(context.Background(), g, "ana", 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	next(t, m)
+	if !m.exited || m.err == nil || !strings.Contains(m.View(), "over 1024 bytes") {
+		t.Errorf("after an oversized frame: exited %v, view %q", m.exited, m.View())
+	}
+}
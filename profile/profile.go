@@ -0,0 +1,248 @@
+// Package profile keeps each player's settings and stats across sessions,
+// keyed by the fingerprint of their SSH public key.
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Stats summarizes a player's runs of one game.
+type Stats struct {
+	Played int
+	Best   int
+	Total  int // sum of every score, or of the seconds timed runs took
+	// Fastest is the quickest timed run, see RecordTime.
+	Fastest time.Duration
+}
+
+type Profile struct {
+	Name  string
+	Theme string // empty for the game's default
+	// Colors forces a color profile on the player's terminal, see
+	// ui.ColorProfiles. Empty uses the detected one.
+	Colors string
+	// Contrast turns on high contrast, see ui.HighContrast.
+	Contrast bool
+	// Timezone is the zone the player chose to see times in, see clock.Load.
+	// Empty uses the one their client sends.
+	Timezone string
+	Keys     map[string][]string // action name to keys, see ui.Rebind
+	Stats    map[string]Stats    // by game ID
+	// Sounds names the events the player turned bell cues on for, see
+	// bell. None are on until they do.
+	Sounds []string
+	// Layout is the control preset the player picked, by its ui.LAYOUTENV
+	// name. Empty uses the one their client sends, or their locale's.
+	Layout string
+	// Mouse turns on mouse input, for the lobby and the games that take it.
+	Mouse bool
+	// Slow turns on slow mode, see games.Env.Slow.
+	Slow bool
+	// Ratings holds the player's Elo ratings, by kind, see rating.
+	Ratings map[string]int
+	// Rated counts the rated matches behind each rating, by kind, for
+	// placements, see rating.PLACEMENTS.
+	Rated map[string]int
+	// Abandons are when the player left rated matches before the end, by
+	// kind, for queue cooldowns, see rating.Cooldown.
+	Abandons map[string][]time.Time
+	// Friends lists the fingerprints of the players they compare with.
+	Friends []string
+	// Blocked lists the fingerprints and display names of the players they
+	// never want to be matched with, see block.
+	Blocked []string
+	// NewsSeen is the date of the newest lobby news entry the player read,
+	// see news.Unread.
+	NewsSeen time.Time
+	// Favorites are the games the player pinned to the top of the lobby,
+	// and Recent the ones they played last, the latest first, by game ID.
+	Favorites []string
+	Recent    []string
+	// Title is the trophy title the player chose to wear next to their
+	// name, see trophy.Worn. Empty wears their best.
+	Title string
+	// Saves holds the progress of games that keep it, by game ID, in each
+	// game's own encoding.
+	Saves map[string]json.RawMessage
+}
+
+// Record adds a finished run of game to the stats.
+func (p *Profile) Record(game string, score int) {
+	if p.Stats == nil {
+		p.Stats = make(map[string]Stats)
+	}
+	s := p.Stats[game]
+	s.Played++
+	s.Best = max(s.Best, score)
+	s.Total += score
+	p.Stats[game] = s
+}
+
+// RecordTime adds a finished run of game that's timed rather than scored,
+// like a solved puzzle, to the stats.
+func (p *Profile) RecordTime(game string, d time.Duration) {
+	if p.Stats == nil {
+		p.Stats = make(map[string]Stats)
+	}
+	s := p.Stats[game]
+	s.Played++
+	if s.Fastest == 0 || d < s.Fastest {
+		s.Fastest = d
+	}
+	s.Total += int(d.Seconds())
+	p.Stats[game] = s
+}
+
+// SetSave encodes v as the save of game.
+func (p *Profile) SetSave(game string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if p.Saves == nil {
+		p.Saves = make(map[string]json.RawMessage)
+	}
+	p.Saves[game] = data
+	return nil
+}
+
+// GetSave decodes the save of game into v, reporting false if there's none.
+func (p Profile) GetSave(game string, v any) (bool, error) {
+	data, ok := p.Saves[game]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (p Profile) clone() Profile {
+	p.Keys = maps.Clone(p.Keys)
+	p.Stats = maps.Clone(p.Stats)
+	p.Ratings = maps.Clone(p.Ratings)
+	p.Rated = maps.Clone(p.Rated)
+	if p.Abandons != nil {
+		abandons := make(map[string][]time.Time, len(p.Abandons))
+		for kind, at := range p.Abandons {
+			abandons[kind] = slices.Clone(at)
+		}
+		p.Abandons = abandons
+	}
+	p.Friends = slices.Clone(p.Friends)
+	p.Blocked = slices.Clone(p.Blocked)
+	p.Sounds = slices.Clone(p.Sounds)
+	p.Favorites = slices.Clone(p.Favorites)
+	p.Recent = slices.Clone(p.Recent)
+	if p.Saves != nil {
+		saves := make(map[string]json.RawMessage, len(p.Saves))
+		for game, data := range p.Saves {
+			saves[game] = bytes.Clone(data)
+		}
+		p.Saves = saves
+	}
+	return p
+}
+
+// Store persists profiles by fingerprint.
+type Store interface {
+	// Load returns the player's profile, or false if they have none yet.
+	Load(fingerprint string) (Profile, bool, error)
+	Save(fingerprint string, p Profile) error
+	// Update changes the player's stored profile with fn, in one step, so
+	// what other sessions saved since it was loaded isn't lost. A player
+	// without one has p changed and saved instead. It returns the result.
+	Update(fingerprint string, p Profile, fn func(*Profile)) (Profile, error)
+}
+
+// Load fetches a player's profile, starting a new one named name if they
+// don't have one. Players without a key get a profile that is never saved.
+func Load(s Store, fingerprint, name string) *Profile {
+	p := Profile{Name: name}
+	if s == nil || fingerprint == "" {
+		return &p
+	}
+	stored, ok, err := s.Load(fingerprint)
+	if err != nil {
+		log.Warn("Could not load profile", "fingerprint", fingerprint, "err", err)
+	}
+	if ok {
+		p = stored
+	}
+	return &p
+}
+
+// Save stores a player's profile, unless they are anonymous.
+func Save(s Store, fingerprint string, p *Profile) {
+	if s == nil || fingerprint == "" || p == nil {
+		return
+	}
+	if err := s.Save(fingerprint, *p); err != nil {
+		log.Warn("Could not save profile", "fingerprint", fingerprint, "err", err)
+	}
+}
+
+// Update changes a player's profile p with fn, both the stored one and p,
+// which picks up what other sessions saved since, unless they are anonymous.
+// Only the fields fn touches are written, so callers that run alongside
+// others, like the same player's other sessions, don't undo their saves.
+func Update(s Store, fingerprint string, p *Profile, fn func(*Profile)) {
+	if p == nil {
+		return
+	}
+	if s == nil || fingerprint == "" {
+		fn(p)
+		return
+	}
+	stored, err := s.Update(fingerprint, *p, fn)
+	if err != nil {
+		log.Warn("Could not update profile", "fingerprint", fingerprint, "err", err)
+		fn(p)
+		return
+	}
+	*p = stored
+}
+
+// MemoryStore keeps profiles in memory.
+type MemoryStore struct {
+	mu       sync.Mutex
+	profiles map[string]Profile
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{profiles: make(map[string]Profile)}
+}
+
+func (s *MemoryStore) Load(fingerprint string) (Profile, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.profiles[fingerprint]
+	return p.clone(), ok, nil
+}
+
+func (s *MemoryStore) Save(fingerprint string, p Profile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.profiles[fingerprint] = p.clone()
+	return nil
+}
+
+func (s *MemoryStore) Update(fingerprint string, p Profile, fn func(*Profile)) (Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.profiles[fingerprint]; ok {
+		p = stored
+	}
+	p = p.clone()
+	fn(&p)
+	s.profiles[fingerprint] = p.clone()
+	return p, nil
+}
@@ -0,0 +1,43 @@
+package profile
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "profiles.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "sqlite": db} {
+		t.Run(name, func(t *testing.T) {
+			const fingerprint = "SHA256:test"
+			// Two sessions of the same player, each changing its own field.
+			games, lobby := Load(s, fingerprint, "ana"), Load(s, fingerprint, "ana")
+			Update(s, fingerprint, games, func(p *Profile) {
+				if err := p.SetSave("blackjack", 500); err != nil {
+					t.Fatal(err)
+				}
+			})
+			Update(s, fingerprint, lobby, func(p *Profile) { p.Title = "Champion" })
+
+			stored, ok, err := s.Load(fingerprint)
+			if err != nil || !ok {
+				t.Fatalf("Load() = %v, %v", ok, err)
+			}
+			var chips int
+			if ok, err := stored.GetSave("blackjack", &chips); !ok || err != nil || chips != 500 {
+				t.Errorf("chips = %d (%v, %v), want 500 kept after the title changed", chips, ok, err)
+			}
+			if stored.Title != "Champion" {
+				t.Errorf("title = %q, want Champion", stored.Title)
+			}
+			if lobby.Name != "ana" || lobby.Title != "Champion" || len(lobby.Saves) == 0 {
+				t.Errorf("updated profile %+v, want the stored one", *lobby)
+			}
+		})
+	}
+}
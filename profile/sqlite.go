@@ -0,0 +1,102 @@
+package profile
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS profiles (
+	fingerprint TEXT PRIMARY KEY,
+	profile     TEXT NOT NULL
+);
+`
+
+// SQLiteStore keeps profiles as JSON in a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create profile schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Load(fingerprint string) (Profile, bool, error) {
+	return load(s.db, fingerprint)
+}
+
+func load(db execer, fingerprint string) (Profile, bool, error) {
+	var data string
+	err := db.QueryRow(`SELECT profile FROM profiles WHERE fingerprint = ?`, fingerprint).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Profile{}, false, nil
+	}
+	if err != nil {
+		return Profile{}, false, err
+	}
+
+	var p Profile
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return Profile{}, false, fmt.Errorf("decode profile: %w", err)
+	}
+	return p, true, nil
+}
+
+func (s *SQLiteStore) Save(fingerprint string, p Profile) error {
+	return save(s.db, fingerprint, p)
+}
+
+func (s *SQLiteStore) Update(fingerprint string, p Profile, fn func(*Profile)) (Profile, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Profile{}, err
+	}
+	defer tx.Rollback()
+
+	stored, ok, err := load(tx, fingerprint)
+	if err != nil {
+		return Profile{}, err
+	}
+	if ok {
+		p = stored
+	}
+	fn(&p)
+	if err := save(tx, fingerprint, p); err != nil {
+		return Profile{}, err
+	}
+	return p, tx.Commit()
+}
+
+// execer is what load and save need of a database or a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func save(db execer, fingerprint string, p Profile) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO profiles VALUES (?, ?)
+		ON CONFLICT (fingerprint) DO UPDATE SET profile = excluded.profile`, fingerprint, string(data))
+	return err
+}
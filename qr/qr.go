@@ -0,0 +1,468 @@
+// Package qr encodes short texts, like connection strings and links, as QR
+// codes and draws them with Unicode half blocks so they scan off a terminal.
+//
+// It covers what the server needs and no more: byte mode only, versions 1
+// to 10, which hold up to 213 bytes at level M.
+package qr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Level is how much of a code can be damaged and still read.
+type Level int
+
+const (
+	L Level = iota // 7%
+	M              // 15%
+	Q              // 25%
+	H              // 30%
+)
+
+// formatBits are the levels as format information writes them.
+var formatBits = [4]int{L: 1, M: 0, Q: 3, H: 2}
+
+// MAXVERSION is the largest version Encode makes.
+const MAXVERSION = 10
+
+// ErrTooLong is returned for texts that don't fit in MAXVERSION.
+var ErrTooLong = errors.New("qr: text too long")
+
+// blocks describes how a version's codewords split into error correction
+// blocks at one level: every block has ec error correction codewords and
+// the groups have count blocks of data codewords each.
+type blocks struct {
+	ec     int
+	groups [][2]int // {count, data}
+}
+
+func (b blocks) data() int {
+	var n int
+	for _, g := range b.groups {
+		n += g[0] * g[1]
+	}
+	return n
+}
+
+// table is ISO/IEC 18004's error correction table, by version and level.
+var table = [MAXVERSION + 1][4]blocks{
+	1:  {{7, [][2]int{{1, 19}}}, {10, [][2]int{{1, 16}}}, {13, [][2]int{{1, 13}}}, {17, [][2]int{{1, 9}}}},
+	2:  {{10, [][2]int{{1, 34}}}, {16, [][2]int{{1, 28}}}, {22, [][2]int{{1, 22}}}, {28, [][2]int{{1, 16}}}},
+	3:  {{15, [][2]int{{1, 55}}}, {26, [][2]int{{1, 44}}}, {18, [][2]int{{2, 17}}}, {22, [][2]int{{2, 13}}}},
+	4:  {{20, [][2]int{{1, 80}}}, {18, [][2]int{{2, 32}}}, {26, [][2]int{{2, 24}}}, {16, [][2]int{{4, 9}}}},
+	5:  {{26, [][2]int{{1, 108}}}, {24, [][2]int{{2, 43}}}, {18, [][2]int{{2, 15}, {2, 16}}}, {22, [][2]int{{2, 11}, {2, 12}}}},
+	6:  {{18, [][2]int{{2, 68}}}, {16, [][2]int{{4, 27}}}, {24, [][2]int{{4, 19}}}, {28, [][2]int{{4, 15}}}},
+	7:  {{20, [][2]int{{2, 78}}}, {18, [][2]int{{4, 31}}}, {18, [][2]int{{2, 14}, {4, 15}}}, {26, [][2]int{{4, 13}, {1, 14}}}},
+	8:  {{24, [][2]int{{2, 97}}}, {22, [][2]int{{2, 38}, {2, 39}}}, {22, [][2]int{{4, 18}, {2, 19}}}, {26, [][2]int{{4, 14}, {2, 15}}}},
+	9:  {{30, [][2]int{{2, 116}}}, {22, [][2]int{{3, 36}, {2, 37}}}, {20, [][2]int{{4, 16}, {4, 17}}}, {24, [][2]int{{4, 12}, {4, 13}}}},
+	10: {{18, [][2]int{{2, 68}, {2, 69}}}, {26, [][2]int{{4, 43}, {1, 44}}}, {24, [][2]int{{6, 19}, {2, 20}}}, {28, [][2]int{{6, 15}, {2, 16}}}},
+}
+
+// alignment lists the centres of every version's alignment patterns, in
+// both directions.
+var alignment = [MAXVERSION + 1][]int{
+	2: {6, 18}, 3: {6, 22}, 4: {6, 26}, 5: {6, 30}, 6: {6, 34},
+	7: {6, 22, 38}, 8: {6, 24, 42}, 9: {6, 26, 46}, 10: {6, 28, 50},
+}
+
+// Code is an encoded QR code.
+type Code struct {
+	Version int
+	Size    int // modules per side
+	dark    [][]bool
+	fixed   [][]bool // function patterns, which masks leave alone
+}
+
+// Dark reports whether the module at column x and row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.dark[y][x]
+}
+
+// Encode makes the smallest code that holds text at level, with the mask
+// that reads best.
+func Encode(text string, level Level) (*Code, error) {
+	version := 0
+	for v := 1; v <= MAXVERSION; v++ {
+		if bitLength(len(text), v) <= table[v][level].data()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := interleave(encodeData(text, version, level), table[version][level])
+	c := newCode(version)
+	c.place(codewords)
+
+	best, bestPenalty := -1, 0
+	for mask := 0; mask < 8; mask++ {
+		c.apply(mask)
+		c.drawFormat(level, mask)
+		if p := c.penalty(); best < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.apply(mask) // masks undo themselves
+	}
+	c.apply(best)
+	c.drawFormat(level, best)
+	return c, nil
+}
+
+// countBits is the length of the byte mode character count.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func bitLength(n, version int) int {
+	return 4 + countBits(version) + 8*n
+}
+
+// bits collects a bit stream, most significant bit first.
+type bits []bool
+
+func (b *bits) add(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+// encodeData is text in byte mode, terminated and padded to the version's
+// data capacity.
+func encodeData(text string, version int, level Level) []byte {
+	capacity := table[version][level].data()
+	var b bits
+	b.add(0b0100, 4)
+	b.add(len(text), countBits(version))
+	for i := 0; i < len(text); i++ {
+		b.add(int(text[i]), 8)
+	}
+	b.add(0, min(4, capacity*8-len(b)))
+	b.add(0, (8-len(b)%8)%8)
+
+	data := make([]byte, 0, capacity)
+	for i := 0; i < len(b); i += 8 {
+		var v byte
+		for _, bit := range b[i : i+8] {
+			v <<= 1
+			if bit {
+				v |= 1
+			}
+		}
+		data = append(data, v)
+	}
+	for pad := byte(0xec); len(data) < capacity; pad ^= 0xec ^ 0x11 {
+		data = append(data, pad)
+	}
+	return data
+}
+
+// interleave splits data into blocks, adds their error correction and
+// weaves the blocks together codeword by codeword.
+func interleave(data []byte, bl blocks) []byte {
+	var datas, ecs [][]byte
+	gen := generator(bl.ec)
+	for _, g := range bl.groups {
+		for i := 0; i < g[0]; i++ {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			datas = append(datas, block)
+			ecs = append(ecs, remainder(block, gen))
+		}
+	}
+
+	var out []byte
+	for i := 0; ; i++ {
+		took := false
+		for _, block := range datas {
+			if i < len(block) {
+				out = append(out, block[i])
+				took = true
+			}
+		}
+		if !took {
+			break
+		}
+	}
+	for i := 0; i < bl.ec; i++ {
+		for _, block := range ecs {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+func newCode(version int) *Code {
+	size := 17 + 4*version
+	c := &Code{Version: version, Size: size}
+	c.dark = make([][]bool, size)
+	c.fixed = make([][]bool, size)
+	for y := range c.dark {
+		c.dark[y] = make([]bool, size)
+		c.fixed[y] = make([]bool, size)
+	}
+	c.drawFunctions()
+	return c
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.dark[y][x] = dark
+	c.fixed[y][x] = true
+}
+
+// drawFunctions draws everything but the data: finders, timing, alignment,
+// and room for the format and version information.
+func (c *Code) drawFunctions() {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	centres := alignment[c.Version]
+	for i, x := range centres {
+		for j, y := range centres {
+			// The corners under the finders have none.
+			if i == 0 && j == 0 || i == 0 && j == len(centres)-1 || i == len(centres)-1 && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormat(L, 0) // reserves the cells, Encode overwrites them
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern centred on x, y with its separator.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			c.set(xx, yy, d != 2 && d != 4)
+		}
+	}
+}
+
+// drawFormat writes the level and mask, protected by a BCH code, next to
+// the finders.
+func (c *Code) drawFormat(level Level, mask int) {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	v := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return v>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true)
+}
+
+// drawVersion writes the version, from version 7 up, by the bottom left
+// and top right finders.
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+	v := c.Version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := v>>i&1 == 1
+		a, b := c.Size-11+i%3, i/3
+		c.set(a, b, dark)
+		c.set(b, a, dark)
+	}
+}
+
+// place lays codewords out in the zigzag of two-module columns, right to
+// left, skipping the function patterns.
+func (c *Code) place(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // the vertical timing pattern
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.fixed[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.dark[y][x] = codewords[i>>3]>>(7-i&7)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// apply flips the data modules the mask selects.
+func (c *Code) apply(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.fixed[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				c.dark[y][x] = !c.dark[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to read, by the standard's four
+// rules: long runs, 2x2 blocks, finder lookalikes and imbalance.
+func (c *Code) penalty() int {
+	var p, dark int
+	line := func(at func(i int) bool) {
+		run := 1
+		for i := 1; i < c.Size; i++ {
+			if at(i) == at(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				p += run - 2
+			}
+			run = 1
+		}
+		if run >= 5 {
+			p += run - 2
+		}
+		// Finder lookalikes: dark, light, dark x3, light, dark with four
+		// light modules on either side.
+		finder := []bool{true, false, true, true, true, false, true}
+		for i := 0; i+7 <= c.Size; i++ {
+			match := true
+			for k, d := range finder {
+				if at(i+k) != d {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			light := func(from, to int) bool {
+				for k := from; k < to; k++ {
+					if k >= 0 && k < c.Size && at(k) {
+						return false
+					}
+				}
+				return true
+			}
+			if light(i-4, i) || light(i+7, i+11) {
+				p += 40
+			}
+		}
+	}
+	for y := 0; y < c.Size; y++ {
+		line(func(x int) bool { return c.dark[y][x] })
+	}
+	for x := 0; x < c.Size; x++ {
+		line(func(y int) bool { return c.dark[y][x] })
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			d := c.dark[y][x]
+			if d {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size && d == c.dark[y][x+1] && d == c.dark[y+1][x] && d == c.dark[y+1][x+1] {
+				p += 3
+			}
+		}
+	}
+	total := c.Size * c.Size
+	p += (abs(dark*20-total*10)+total-1)/total*10 - 10
+	return p
+}
+
+// String draws the code with a quiet zone of two modules, two rows of
+// modules per line: dark modules are blocks, so it wants dark text on a
+// light background.
+func (c *Code) String() string {
+	const quiet = 2
+	var s strings.Builder
+	for y := -quiet; y < c.Size+quiet; y += 2 {
+		for x := -quiet; x < c.Size+quiet; x++ {
+			top, bottom := c.Dark(x, y), c.Dark(x, y+1)
+			switch {
+			case top && bottom:
+				s.WriteString("█")
+			case top:
+				s.WriteString("▀")
+			case bottom:
+				s.WriteString("▄")
+			default:
+				s.WriteString(" ")
+			}
+		}
+		if y+2 < c.Size+quiet {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,206 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// formats are the format information strings of ISO/IEC 18004 Annex C, by
+// level and mask, most significant bit first.
+var formats = [4][8]string{
+	L: {"111011111000100", "111001011110011", "111110110101010", "111100010011101", "110011000101111", "110001100011000", "110110001000001", "110100101110110"},
+	M: {"101010000010010", "101000100100101", "101111001111100", "101101101001011", "100010111111001", "100000011001110", "100111110010111", "100101010100000"},
+	Q: {"011010101011111", "011000001101000", "011111100110001", "011101000000110", "010010010110100", "010000110000011", "010111011011010", "010101111101101"},
+	H: {"001011010001001", "001001110111110", "001110011100111", "001100111010000", "000011101100010", "000001001010101", "000110100001100", "000100000111011"},
+}
+
+// versions are the version information strings of Annex D.
+var versions = map[int]string{
+	7:  "000111110010010100",
+	8:  "001000010110111100",
+	9:  "001001101010011001",
+	10: "001010010011010011",
+}
+
+func TestRemainder(t *testing.T) {
+	// HELLO WORLD at 1-M, the standard's worked example.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := remainder(data, generator(10)); !bytes.Equal(got, want) {
+		t.Errorf("remainder = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeData(t *testing.T) {
+	// Byte mode, a count of 5, "hello", the terminator and the pad bytes.
+	want := []byte{0x40, 0x56, 0x86, 0x56, 0xc6, 0xc6, 0xf0, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec}
+	if got := encodeData("hello", 1, M); !bytes.Equal(got, want) {
+		t.Errorf("encodeData = % x, want % x", got, want)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	for _, tc := range []struct {
+		n       int
+		level   Level
+		version int
+	}{
+		{14, M, 1},
+		{15, M, 2},
+		{17, L, 1},
+		{7, H, 1},
+		{213, M, 10},
+	} {
+		c, err := Encode(strings.Repeat("a", tc.n), tc.level)
+		if err != nil || c.Version != tc.version || c.Size != 17+4*tc.version {
+			t.Errorf("Encode of %d bytes at %d = %v, %v, want version %d", tc.n, tc.level, c, err, tc.version)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", 214), M); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode of 214 bytes at M = %v, want ErrTooLong", err)
+	}
+}
+
+// read decodes c as a reader would: the format information from both of
+// its copies, then the codewords under the mask it names.
+func read(t *testing.T, c *Code) (Level, []byte) {
+	bits := func(at func(i int) bool) string {
+		var s strings.Builder
+		for i := 14; i >= 0; i-- {
+			if at(i) {
+				s.WriteByte('1')
+			} else {
+				s.WriteByte('0')
+			}
+		}
+		return s.String()
+	}
+	first := bits(func(i int) bool {
+		switch {
+		case i <= 5:
+			return c.Dark(8, i)
+		case i == 6:
+			return c.Dark(8, 7)
+		case i == 7:
+			return c.Dark(8, 8)
+		case i == 8:
+			return c.Dark(7, 8)
+		}
+		return c.Dark(14-i, 8)
+	})
+	second := bits(func(i int) bool {
+		if i < 8 {
+			return c.Dark(c.Size-1-i, 8)
+		}
+		return c.Dark(8, c.Size-15+i)
+	})
+	if first != second {
+		t.Fatalf("format copies differ: %s and %s", first, second)
+	}
+	level, mask := Level(-1), -1
+	for l, row := range formats {
+		for m, f := range row {
+			if f == first {
+				level, mask = Level(l), m
+			}
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format %s isn't a valid one", first)
+	}
+
+	c.apply(mask)
+	defer c.apply(mask)
+	var codewords []byte
+	var b, n byte
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.fixed[y][x] {
+					continue
+				}
+				b <<= 1
+				if c.dark[y][x] {
+					b |= 1
+				}
+				if n++; n == 8 {
+					codewords, b, n = append(codewords, b), 0, 0
+				}
+			}
+		}
+	}
+	return level, codewords
+}
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		level Level
+	}{
+		{"hello", M},
+		{"ssh games.debem.dev", L},
+		{"https://games.debem.dev/replays/0123456789abcdef", Q},
+		{strings.Repeat("x", 40), H},
+		{strings.Repeat("ssh -p 2222 games.debem.dev ", 5), M}, // version 8, with version information
+		{strings.Repeat("z", 213), M},
+	} {
+		t.Run(fmt.Sprint(len(tc.text)), func(t *testing.T) {
+			c, err := Encode(tc.text, tc.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+				for i := range 7 {
+					if !c.Dark(corner[0]+i, corner[1]) || !c.Dark(corner[0], corner[1]+i) || c.Dark(corner[0]+1, corner[1]+1) {
+						t.Fatalf("no finder at %v", corner)
+					}
+				}
+			}
+			for i := 8; i < c.Size-8; i++ {
+				if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+					t.Fatalf("timing pattern broken at %d", i)
+				}
+			}
+			if !c.Dark(8, c.Size-8) {
+				t.Error("no dark module")
+			}
+			if want, ok := versions[c.Version]; ok {
+				var got strings.Builder
+				for i := 17; i >= 0; i-- {
+					a, b := c.Size-11+i%3, i/3
+					if c.Dark(a, b) != c.Dark(b, a) {
+						t.Fatalf("version copies differ at bit %d", i)
+					}
+					if c.Dark(a, b) {
+						got.WriteByte('1')
+					} else {
+						got.WriteByte('0')
+					}
+				}
+				if got.String() != want {
+					t.Errorf("version %d information %s, want %s", c.Version, got.String(), want)
+				}
+			}
+
+			level, codewords := read(t, c)
+			if level != tc.level {
+				t.Errorf("format names level %d, want %d", level, tc.level)
+			}
+			bl := table[c.Version][level]
+			want := interleave(encodeData(tc.text, c.Version, level), bl)
+			if len(codewords) < len(want) || !bytes.Equal(codewords[:len(want)], want) {
+				t.Errorf("codewords read back % x, want % x", codewords, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package qr
+
+// Reed-Solomon error correction over GF(256), with the field QR codes use:
+// x^8 + x^4 + x^3 + x^2 + 1.
+
+func mul(a, b byte) byte {
+	var p byte
+	for ; b > 0; b >>= 1 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		carry := a&0x80 != 0
+		a <<= 1
+		if carry {
+			a ^= 0x1d
+		}
+	}
+	return p
+}
+
+// generator is the product of (x - 2^i) for i below degree, highest power
+// first without the leading one.
+func generator(degree int) []byte {
+	g := make([]byte, degree)
+	g[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range g {
+			g[j] = mul(g[j], root)
+			if j+1 < len(g) {
+				g[j] ^= g[j+1]
+			}
+		}
+		root = mul(root, 2)
+	}
+	return g
+}
+
+// remainder is the error correction of data: its remainder when divided by
+// the generator gen.
+func remainder(data, gen []byte) []byte {
+	r := make([]byte, len(gen))
+	for _, b := range data {
+		factor := b ^ r[0]
+		copy(r, r[1:])
+		r[len(r)-1] = 0
+		for i, g := range gen {
+			r[i] ^= mul(g, factor)
+		}
+	}
+	return r
+}
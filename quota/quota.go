@@ -0,0 +1,228 @@
+// Package quota accounts the CPU time and memory every session's game uses
+// and stops sessions, with a message to the player, once they exceed their
+// own limits or their game's share of the host.
+//
+// CPU time is measured as the time spent in the game's Update and View,
+// which run on the program's own goroutine. Commands are not counted since
+// most of them just wait on timers or I/O. Memory is whatever the game
+// reports through Sizer; in-process games that don't implement it only
+// count towards CPU.
+package quota
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	WINDOW     = 10 * time.Second // span a game's CPU share is averaged over
+	MEMORYPOLL = time.Second      // how often Sizer is asked for memory use
+)
+
+var stopped = expvar.NewMap("quota.stopped")
+
+// Limits bound what sessions may use. Zero fields are unlimited.
+type Limits struct {
+	CPU        float64 // cores a session may keep busy, averaged over WINDOW
+	Memory     int64   // bytes, per session
+	GameCPU    float64 // cores a game's sessions may keep busy together
+	GameMemory int64   // bytes, all of a game's sessions together
+}
+
+var DefaultLimits = Limits{
+	CPU:        0.5,
+	Memory:     64 << 20,
+	GameCPU:    2,
+	GameMemory: 1 << 30,
+}
+
+// Sizer is implemented by games that can report their memory use, such as
+// sandboxed and out-of-process ones.
+type Sizer interface {
+	MemoryUsage() int64
+}
+
+// Stopper is implemented by games that hold resources outside the program,
+// like a child process, so they're released as soon as the session is
+// stopped rather than when the player leaves.
+type Stopper interface {
+	Stop()
+}
+
+// Unwrapper is implemented by models that wrap a game, like spectate.Model,
+// so Sizer and Stopper are found on the game inside.
+type Unwrapper interface {
+	Unwrap() tea.Model
+}
+
+// unwrap is the game m wraps, if any, or m.
+func unwrap(m tea.Model) tea.Model {
+	for {
+		u, ok := m.(Unwrapper)
+		if !ok {
+			return m
+		}
+		m = u.Unwrap()
+	}
+}
+
+// window is the CPU time spent since it started, WINDOW at most ago.
+type window struct {
+	cpu   time.Duration
+	start time.Time
+}
+
+// share adds d to the CPU time and returns how many cores were kept busy on
+// average over the current window.
+func (w *window) share(now time.Time, d time.Duration) float64 {
+	if now.Sub(w.start) > WINDOW {
+		w.start, w.cpu = now, 0
+	}
+	w.cpu += d
+	return float64(w.cpu) / float64(WINDOW)
+}
+
+// usage is the live total of one game's sessions.
+type usage struct {
+	mu     sync.Mutex
+	memory map[*Model]int64
+	window window
+}
+
+func (u *usage) share(now time.Time, d time.Duration) float64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.window.share(now, d)
+}
+
+func (u *usage) setMemory(m *Model, bytes int64) int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if bytes < 0 {
+		delete(u.memory, m)
+	} else {
+		u.memory[m] = bytes
+	}
+	var total int64
+	for _, b := range u.memory {
+		total += b
+	}
+	return total
+}
+
+var (
+	mu    sync.Mutex
+	games = make(map[string]*usage)
+)
+
+func usageOf(game string) *usage {
+	mu.Lock()
+	defer mu.Unlock()
+
+	u, ok := games[game]
+	if !ok {
+		u = &usage{memory: make(map[*Model]int64)}
+		games[game] = u
+	}
+	return u
+}
+
+// Model wraps a session's game and stops it once it goes over its limits.
+type Model struct {
+	inner  tea.Model
+	game   string
+	limits Limits
+	usage  *usage
+
+	cpu      time.Duration // over the session's whole life, for the log
+	window   window
+	polled   time.Time
+	exceeded string
+}
+
+// Wrap accounts m as a session of game until ctx, the session's context, is
+// done.
+func Wrap(ctx context.Context, game string, m tea.Model, limits Limits) *Model {
+	w := &Model{inner: m, game: game, limits: limits, usage: usageOf(game)}
+	context.AfterFunc(ctx, func() { w.usage.setMemory(w, -1) })
+	return w
+}
+
+func (w *Model) Init() tea.Cmd {
+	defer w.account(time.Now())
+	return w.inner.Init()
+}
+
+func (w *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if w.exceeded != "" {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return w, tea.Quit
+		}
+		// Dropping everything else lets the game's timers run out.
+		return w, nil
+	}
+
+	defer w.account(time.Now())
+	var cmd tea.Cmd
+	w.inner, cmd = w.inner.Update(msg)
+	return w, cmd
+}
+
+func (w *Model) View() string {
+	if w.exceeded != "" {
+		return fmt.Sprintf("This session was stopped because %s.\n\nPress any key to leave.", w.exceeded)
+	}
+
+	defer w.account(time.Now())
+	return w.inner.View()
+}
+
+// account charges the time since start and checks every limit.
+func (w *Model) account(start time.Time) {
+	now := time.Now()
+	w.charge(now, now.Sub(start))
+}
+
+// charge adds d, spent until now, to the session's CPU time and checks every
+// limit.
+func (w *Model) charge(now time.Time, d time.Duration) {
+	w.cpu += d
+
+	switch {
+	case w.limits.CPU > 0 && w.window.share(now, d) > w.limits.CPU:
+		w.stop("it used more than its share of processing time")
+	case w.limits.GameCPU > 0 && w.usage.share(now, d) > w.limits.GameCPU:
+		w.stop(fmt.Sprintf("%s is using more of the server than it's allowed", w.game))
+	}
+
+	sizer, ok := unwrap(w.inner).(Sizer)
+	if !ok || w.exceeded != "" || now.Sub(w.polled) < MEMORYPOLL {
+		return
+	}
+	w.polled = now
+	bytes := sizer.MemoryUsage()
+	total := w.usage.setMemory(w, bytes)
+	switch {
+	case w.limits.Memory > 0 && bytes > w.limits.Memory:
+		w.stop("it used more than its share of memory")
+	case w.limits.GameMemory > 0 && total > w.limits.GameMemory:
+		w.stop(fmt.Sprintf("%s is using more of the server's memory than it's allowed", w.game))
+	}
+}
+
+func (w *Model) stop(reason string) {
+	w.exceeded = reason
+	w.usage.setMemory(w, -1)
+	if s, ok := unwrap(w.inner).(Stopper); ok {
+		s.Stop()
+	}
+	stopped.Add(w.game, 1)
+	log.Warn("Session over quota", "game", w.game, "reason", reason, "cpu", w.cpu)
+}
@@ -0,0 +1,53 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+)
+
+type game struct {
+	memory  int64
+	stopped bool
+}
+
+func (g *game) Init() tea.Cmd                       { return nil }
+func (g *game) Update(tea.Msg) (tea.Model, tea.Cmd) { return g, nil }
+func (g *game) View() string                        { return "" }
+func (g *game) MemoryUsage() int64                  { return g.memory }
+func (g *game) Stop()                               { g.stopped = true }
+
+func TestCPURate(t *testing.T) {
+	w := Wrap(context.Background(), "test-rate", &game{}, Limits{CPU: 0.5})
+	now := time.Now()
+	// A game kept busy 40% of the time for far longer than any lifetime
+	// budget would allow stays under a 0.5 core rate.
+	for range 100 {
+		now = now.Add(WINDOW + time.Millisecond)
+		w.charge(now, WINDOW*4/10)
+	}
+	if w.exceeded != "" {
+		t.Fatalf("stopped at 40%% busy: %s", w.exceeded)
+	}
+	now = now.Add(WINDOW + time.Millisecond)
+	w.charge(now, WINDOW*6/10)
+	if w.exceeded == "" {
+		t.Error("not stopped at 60% busy over a window")
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	g := &game{memory: 2 << 20}
+	b := spectate.NewBroadcast(0)
+	w := Wrap(context.Background(), "test-unwrap", spectate.Wrap(g, b), Limits{Memory: 1 << 20})
+	w.charge(time.Now(), 0)
+	if w.exceeded == "" {
+		t.Fatal("memory of a broadcast game not accounted")
+	}
+	if !g.stopped {
+		t.Error("broadcast game not stopped")
+	}
+}
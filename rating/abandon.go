@@ -0,0 +1,31 @@
+package rating
+
+import (
+	"slices"
+	"time"
+)
+
+// ABANDONSPAN is how long leaving a rated match early counts against a
+// player.
+const ABANDONSPAN = 24 * time.Hour
+
+// COOLDOWNS are how long a player waits to queue for rated matches after
+// their latest abandon, by how many they have within ABANDONSPAN. The first
+// one is forgiven, since connections drop; the last applies to any more.
+var COOLDOWNS = []time.Duration{0, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour}
+
+// Recent keeps the abandons within ABANDONSPAN of now, the oldest first.
+func Recent(abandons []time.Time, now time.Time) []time.Time {
+	return slices.DeleteFunc(slices.Clone(abandons), func(t time.Time) bool { return now.Sub(t) >= ABANDONSPAN })
+}
+
+// Cooldown is how long from now a player who abandoned rated matches at
+// abandons has to wait before queueing again, zero when they may.
+func Cooldown(abandons []time.Time, now time.Time) time.Duration {
+	recent := Recent(abandons, now)
+	if len(recent) == 0 {
+		return 0
+	}
+	wait := COOLDOWNS[min(len(recent), len(COOLDOWNS))-1]
+	return max(0, recent[len(recent)-1].Add(wait).Sub(now))
+}
@@ -0,0 +1,47 @@
+// Package rating implements Elo ratings. Each kind of rating, such as match
+// play or tactics puzzles, is kept separately so one can't inflate another.
+package rating
+
+import "math"
+
+const (
+	DEFAULT = 1200 // rating of a newcomer
+	K       = 32   // how far a single result moves a rating
+
+	PLACEMENTS = 5     // rated matches a newcomer plays before their rating shows
+	PLACEMENTK = 2 * K // how far a placement result moves it, to find their level sooner
+
+	TACTICS = "tactics"
+	TETRIS  = "tetris-versus"
+)
+
+// Expected is the score a player rated r is expected to get against opp,
+// from 0 to 1.
+func Expected(r, opp int) float64 {
+	return 1 / (1 + math.Pow(10, float64(opp-r)/400))
+}
+
+// Update rates a player after scoring score (1 win, 0.5 draw, 0 loss)
+// against opp.
+func Update(r, opp int, score float64) int {
+	return update(r, opp, score, K)
+}
+
+// UpdatePlayed is Update for a player with played rated matches behind
+// them, moving the rating further while they're placed.
+func UpdatePlayed(r, opp int, score float64, played int) int {
+	if Placing(played) {
+		return update(r, opp, score, PLACEMENTK)
+	}
+	return update(r, opp, score, K)
+}
+
+// Placing reports whether a player with played rated matches is still
+// playing their placement matches.
+func Placing(played int) bool {
+	return played < PLACEMENTS
+}
+
+func update(r, opp int, score, k float64) int {
+	return r + int(math.Round(k*(score-Expected(r, opp))))
+}
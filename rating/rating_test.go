@@ -0,0 +1,77 @@
+package rating
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExpected(t *testing.T) {
+	for _, tc := range []struct {
+		r, opp int
+		want   float64
+	}{
+		{1200, 1200, 0.5},
+		{1600, 1200, 10.0 / 11}, // 400 points up is 10 to 1
+		{1200, 1600, 1.0 / 11},
+	} {
+		if got := Expected(tc.r, tc.opp); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Expected(%d, %d) = %v, want %v", tc.r, tc.opp, got, tc.want)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	for _, tc := range []struct {
+		r, opp int
+		score  float64
+		played int
+		want   int
+	}{
+		{1200, 1200, 1, PLACEMENTS, 1216},
+		{1200, 1200, 0, PLACEMENTS, 1184},
+		{1200, 1200, 0.5, PLACEMENTS, 1200},
+		{1600, 1200, 1, PLACEMENTS, 1603}, // a win the favorite was expected to get
+		{1200, 1600, 1, PLACEMENTS, 1229},
+		{1200, 1200, 1, 0, 1232}, // placements move twice as far
+		{1200, 1200, 0, PLACEMENTS - 1, 1168},
+	} {
+		if got := UpdatePlayed(tc.r, tc.opp, tc.score, tc.played); got != tc.want {
+			t.Errorf("UpdatePlayed(%d, %d, %v, %d) = %d, want %d", tc.r, tc.opp, tc.score, tc.played, got, tc.want)
+		}
+	}
+	if got := Update(1200, 1200, 1); got != 1216 {
+		t.Errorf("Update = %d, want 1216", got)
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 12, 0, 0, 0, time.UTC)
+	ago := func(d ...time.Duration) []time.Time {
+		var at []time.Time
+		for _, d := range d {
+			at = append(at, now.Add(-d))
+		}
+		return at
+	}
+	for _, tc := range []struct {
+		name     string
+		abandons []time.Time
+		want     time.Duration
+	}{
+		{"none", nil, 0},
+		{"the first is forgiven", ago(time.Minute), 0},
+		{"a second", ago(time.Hour, time.Minute), 4 * time.Minute},
+		{"a second served", ago(time.Hour, 10*time.Minute), 0},
+		{"a third", ago(2*time.Hour, time.Hour, 10*time.Minute), 20 * time.Minute},
+		{"many", ago(5*time.Hour, 4*time.Hour, 3*time.Hour, 2*time.Hour, time.Hour), time.Hour},
+		{"old ones expire", ago(30*time.Hour, 25*time.Hour, time.Minute), 0},
+	} {
+		if got := Cooldown(tc.abandons, now); got != tc.want {
+			t.Errorf("%s: Cooldown = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if got := Recent(ago(ABANDONSPAN, time.Hour), now); len(got) != 1 {
+		t.Errorf("Recent = %v, want the abandon from an hour ago", got)
+	}
+}
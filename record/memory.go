@@ -0,0 +1,44 @@
+package record
+
+import "sync"
+
+// MemorySink keeps the most recent recordings in memory.
+type MemorySink struct {
+	mu     sync.Mutex
+	limit  int
+	recent []*Recording
+}
+
+func NewMemorySink(limit int) *MemorySink {
+	return &MemorySink{limit: limit}
+}
+
+func (m *MemorySink) Save(r *Recording) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recent = append(m.recent, r)
+	if len(m.recent) > m.limit {
+		m.recent = m.recent[len(m.recent)-m.limit:]
+	}
+	return nil
+}
+
+// Find returns the stored recording of session, if it's still kept.
+func (m *MemorySink) Find(session string) (*Recording, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.recent {
+		if r.Session == session {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Recent returns the stored recordings, oldest first.
+func (m *MemorySink) Recent() []*Recording {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Recording(nil), m.recent...)
+}
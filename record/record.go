@@ -0,0 +1,170 @@
+// Package record captures keystroke timings for every game session so the
+// replay, anti-cheat and bug-report tooling share one source of input data
+// instead of each game hooking its own Update. It also keeps the session's
+// last frames, which is what replays are exported from.
+package record
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/debemdeboas/games.debem.dev/lifecycle"
+)
+
+// CONSENTENV is the client environment variable that opts a session into
+// recording, e.g. `ssh -o SetEnv=GAMES_RECORD=1 ...`.
+const CONSENTENV = "GAMES_RECORD"
+
+const (
+	FRAMEEVERY = 100 * time.Millisecond // at most one frame is kept this often
+	MAXFRAMES  = 900                    // frames kept, the latest ones
+	// MAXEVENTS bounds the keystrokes kept, the latest ones, so a session
+	// left open for days doesn't grow without end.
+	MAXEVENTS = 20_000
+)
+
+// Event is a single keystroke, timed from the start of the session.
+type Event struct {
+	At  time.Duration
+	Key string
+}
+
+// Frame is a rendered screen, timed from the start of the session.
+type Frame struct {
+	At   time.Duration
+	View string
+}
+
+type Recording struct {
+	Session string
+	User    string
+	Started time.Time
+
+	mu     sync.Mutex
+	events []Event
+	frames []Frame
+}
+
+func (r *Recording) add(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, Event{At: time.Since(r.Started), Key: key})
+	if len(r.events) > MAXEVENTS {
+		r.events = r.events[len(r.events)-MAXEVENTS:]
+	}
+}
+
+// frame keeps view unless the last one kept is too recent or the same.
+func (r *Recording) frame(view string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	at := time.Since(r.Started)
+	if n := len(r.frames); n > 0 && (at-r.frames[n-1].At < FRAMEEVERY || r.frames[n-1].View == view) {
+		return
+	}
+	r.frames = append(r.frames, Frame{At: at, View: view})
+	if len(r.frames) > MAXFRAMES {
+		r.frames = r.frames[len(r.frames)-MAXFRAMES:]
+	}
+}
+
+// Frames returns a copy of the frames kept so far, oldest first.
+func (r *Recording) Frames() []Frame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Frame(nil), r.frames...)
+}
+
+// Events returns a copy of the keystrokes recorded so far, the latest
+// MAXEVENTS of them, oldest first.
+func (r *Recording) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// Sink receives a recording once its session ends.
+type Sink interface {
+	Save(r *Recording) error
+}
+
+// ConsentFunc decides whether a session may be recorded.
+type ConsentFunc func(s ssh.Session) bool
+
+// EnvConsent accepts sessions whose client set CONSENTENV to 1, true or yes.
+func EnvConsent(s ssh.Session) bool {
+	prefix := CONSENTENV + "="
+	for _, kv := range s.Environ() {
+		if len(kv) > len(prefix) && kv[:len(prefix)] == prefix {
+			switch kv[len(prefix):] {
+			case "1", "true", "yes":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// FromSession returns the recording attached to a session, if any.
+func FromSession(s ssh.Session) (*Recording, bool) {
+	r, ok := s.Context().Value(contextKey{}).(*Recording)
+	return r, ok
+}
+
+// Handler wraps a bubbletea handler so every key message reaching the
+// program is timestamped. It works on the tea message stream rather than the
+// raw channel, so it behaves the same with emulated and allocated PTYs.
+// The recording is handed to sink when the session ends.
+func Handler(next bubbletea.Handler, sink Sink, consent ConsentFunc) bubbletea.Handler {
+	return func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+		m, opts := next(s)
+		if m == nil || !consent(s) {
+			return m, opts
+		}
+
+		rec := &Recording{
+			Session: s.Context().SessionID(),
+			User:    s.User(),
+			Started: time.Now(),
+		}
+		s.Context().SetValue(contextKey{}, rec)
+
+		lifecycle.Go(s, "record", func(ctx context.Context) {
+			<-ctx.Done()
+			_ = sink.Save(rec)
+		})
+
+		filter := func(_ tea.Model, msg tea.Msg) tea.Msg {
+			if key, ok := msg.(tea.KeyMsg); ok {
+				rec.add(key.String())
+			}
+			return msg
+		}
+		return frames{Model: m, rec: rec}, append(opts, tea.WithFilter(filter))
+	}
+}
+
+// frames keeps the frames a model renders in its recording.
+type frames struct {
+	tea.Model
+	rec *Recording
+}
+
+func (f frames) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := f.Model.Update(msg)
+	f.Model = m
+	return f, cmd
+}
+
+func (f frames) View() string {
+	v := f.Model.View()
+	f.rec.frame(v)
+	return v
+}
@@ -0,0 +1,43 @@
+package record
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEventsCapped(t *testing.T) {
+	r := &Recording{Started: time.Now()}
+	for i := range MAXEVENTS + 10 {
+		r.add(fmt.Sprint(i))
+	}
+	events := r.Events()
+	if len(events) != MAXEVENTS {
+		t.Fatalf("kept %d events, want %d", len(events), MAXEVENTS)
+	}
+	if first, last := events[0].Key, events[len(events)-1].Key; first != "10" || last != fmt.Sprint(MAXEVENTS+9) {
+		t.Errorf("kept %s to %s, want the latest", first, last)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	r := &Recording{Started: time.Now()}
+	r.frame("a")
+	r.frame("b") // too soon after a
+	r.Started = r.Started.Add(-FRAMEEVERY)
+	r.frame("a") // the same as the last
+	r.Started = r.Started.Add(-FRAMEEVERY)
+	r.frame("c")
+	frames := r.Frames()
+	if len(frames) != 2 || frames[0].View != "a" || frames[1].View != "c" {
+		t.Errorf("frames %v, want a then c", frames)
+	}
+
+	for i := range MAXFRAMES + 1 {
+		r.Started = r.Started.Add(-FRAMEEVERY)
+		r.frame(fmt.Sprint(i))
+	}
+	if frames := r.Frames(); len(frames) != MAXFRAMES || frames[len(frames)-1].View != fmt.Sprint(MAXFRAMES) {
+		t.Errorf("kept %d frames, want the latest %d", len(frames), MAXFRAMES)
+	}
+}
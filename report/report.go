@@ -0,0 +1,139 @@
+// Package report takes in the reports players file against the ones they
+// met in multiplayer games, for moderators to review from the lobby.
+// Reports stay in the queue until a moderator resolves them, and each
+// player may only file a few an hour.
+package report
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/throttle"
+)
+
+const (
+	MAXNOTE    = 300       // runes a note may have
+	RATELIMIT  = 5         // reports a player may file per window
+	RATEWINDOW = time.Hour // span a player's reports are counted over
+)
+
+// Reasons are what players pick from to say what went wrong.
+var Reasons = []string{
+	"Cheating or exploiting a bug",
+	"Offensive name",
+	"Harassment",
+	"Stalling or leaving on purpose",
+	"Something else",
+}
+
+var (
+	ErrReason  = errors.New("report: no such reason")
+	ErrLimited = errors.New("report: too many reports, try again later")
+)
+
+// Report is a player's complaint about another.
+type Report struct {
+	ID       int64 // set by the store
+	Reporter string
+	// ReporterFingerprint is the reporter's key, empty for anonymous
+	// players.
+	ReporterFingerprint string
+	Player              string // the player reported
+	Fingerprint         string // their key, empty if they had none
+	Game                string // the title of the game they met in
+	// Room is the lobby room key they gathered with, empty when
+	// matchmaking paired them.
+	Room string
+	// Session is the reporter's SSH session, whose replay shows the game
+	// if they opted into recording.
+	Session string
+	Reason  string // one of Reasons
+	Note    string // optional
+	At      time.Time
+}
+
+// Store keeps reports until they're resolved.
+type Store interface {
+	Add(r Report) error
+	// Open lists the reports nobody resolved yet, the oldest first.
+	Open() ([]Report, error)
+	// Resolve takes the report with id off the queue.
+	Resolve(id int64) error
+}
+
+// MemoryStore keeps reports in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	nextID  int64
+	reports []Report
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Add(r Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	r.ID = s.nextID
+	s.reports = append(s.reports, r)
+	return nil
+}
+
+func (s *MemoryStore) Open() ([]Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reports), nil
+}
+
+func (s *MemoryStore) Resolve(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = slices.DeleteFunc(s.reports, func(r Report) bool { return r.ID == id })
+	return nil
+}
+
+// Desk takes players' reports in, counting them against the reporter's
+// limit, and hands moderators the queue.
+type Desk struct {
+	store   Store
+	limiter *throttle.Limiter
+}
+
+func NewDesk(store Store) *Desk {
+	return &Desk{store: store, limiter: throttle.NewLimiter(RATELIMIT, RATEWINDOW)}
+}
+
+// File takes r in, its note trimmed and cut to MAXNOTE. Reporters are told
+// apart by their key, or by name when they have none.
+func (d *Desk) File(r Report) error {
+	if !slices.Contains(Reasons, r.Reason) {
+		return ErrReason
+	}
+	r.Note = strings.TrimSpace(r.Note)
+	if n := []rune(r.Note); len(n) > MAXNOTE {
+		r.Note = string(n[:MAXNOTE])
+	}
+	reporter := r.ReporterFingerprint
+	if reporter == "" {
+		reporter = "name:" + r.Reporter
+	}
+	if !d.limiter.Allow(reporter, r.At) {
+		return ErrLimited
+	}
+	return d.store.Add(r)
+}
+
+// Queue lists the reports left to review, the oldest first.
+func (d *Desk) Queue() ([]Report, error) {
+	return d.store.Open()
+}
+
+// Resolve takes the report with id off the queue.
+func (d *Desk) Resolve(id int64) error {
+	return d.store.Resolve(id)
+}
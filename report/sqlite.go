@@ -0,0 +1,83 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS reports (
+	id                   INTEGER PRIMARY KEY,
+	reporter             TEXT NOT NULL,
+	reporter_fingerprint TEXT NOT NULL,
+	player               TEXT NOT NULL,
+	fingerprint          TEXT NOT NULL,
+	game                 TEXT NOT NULL,
+	room                 TEXT NOT NULL,
+	session              TEXT NOT NULL,
+	reason               TEXT NOT NULL,
+	note                 TEXT NOT NULL,
+	at                   INTEGER NOT NULL,
+	resolved             INTEGER NOT NULL DEFAULT 0
+);
+`
+
+// SQLiteStore keeps reports in a SQLite database. Resolved ones stay in
+// it, for operators to look back on with the sqlite3 shell.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create reports schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Add(r Report) error {
+	_, err := s.db.Exec(`INSERT INTO reports (reporter, reporter_fingerprint, player, fingerprint, game, room, session, reason, note, at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Reporter, r.ReporterFingerprint, r.Player, r.Fingerprint, r.Game, r.Room, r.Session, r.Reason, r.Note, r.At.UnixMilli())
+	return err
+}
+
+func (s *SQLiteStore) Open() ([]Report, error) {
+	rows, err := s.db.Query(`SELECT id, reporter, reporter_fingerprint, player, fingerprint, game, room, session, reason, note, at
+		FROM reports WHERE resolved = 0 ORDER BY at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []Report
+	for rows.Next() {
+		var r Report
+		var at int64
+		if err := rows.Scan(&r.ID, &r.Reporter, &r.ReporterFingerprint, &r.Player, &r.Fingerprint, &r.Game, &r.Room, &r.Session, &r.Reason, &r.Note, &at); err != nil {
+			return nil, err
+		}
+		r.At = time.UnixMilli(at)
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+func (s *SQLiteStore) Resolve(id int64) error {
+	_, err := s.db.Exec(`UPDATE reports SET resolved = 1 WHERE id = ?`, id)
+	return err
+}
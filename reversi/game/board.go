@@ -0,0 +1,240 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	SIZE        = 8
+	SEARCHPLY   = 4 // how far ahead the computer looks
+	WINSCORE    = 100_000
+	MOBILITY    = 10 // what each move more than the opponent's is worth
+	CORNERVALUE = 40 // a corner can't be flipped back
+	XVALUE      = 15 // what a square next to an empty corner costs, since it gives the corner away
+)
+
+// Colors. Dark moves first.
+const (
+	NONE = iota
+	DARK
+	LIGHT
+)
+
+// directions discs flip in from the one placed.
+var directions = []grid.Point{
+	{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1},
+	{X: -1, Y: 0}, {X: 1, Y: 0},
+	{X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1},
+}
+
+var corners = []grid.Point{{X: 0, Y: 0}, {X: SIZE - 1, Y: 0}, {X: 0, Y: SIZE - 1}, {X: SIZE - 1, Y: SIZE - 1}}
+
+// Board is a position, row 0 at the top.
+type Board struct {
+	Cells [SIZE][SIZE]int
+	Turn  int
+	// Passed is set when the last move left the other side without one,
+	// so the side that made it moves again.
+	Passed bool
+}
+
+// Move is the square a disc goes on.
+type Move = grid.Point
+
+func other(color int) int {
+	return DARK + LIGHT - color
+}
+
+func inBounds(p grid.Point) bool {
+	return p.X >= 0 && p.X < SIZE && p.Y >= 0 && p.Y < SIZE
+}
+
+// NewBoard sets the four middle discs crosswise, dark to move.
+func NewBoard() Board {
+	b := Board{Turn: DARK}
+	b.Cells[SIZE/2-1][SIZE/2-1] = LIGHT
+	b.Cells[SIZE/2][SIZE/2] = LIGHT
+	b.Cells[SIZE/2-1][SIZE/2] = DARK
+	b.Cells[SIZE/2][SIZE/2-1] = DARK
+	return b
+}
+
+func (b *Board) At(p grid.Point) int {
+	return b.Cells[p.Y][p.X]
+}
+
+// flips are the discs color placing on p would turn, none when p isn't a
+// move for color.
+func (b *Board) flips(color int, p grid.Point) []grid.Point {
+	if !inBounds(p) || b.At(p) != NONE {
+		return nil
+	}
+	var flipped []grid.Point
+	for _, d := range directions {
+		var line []grid.Point
+		q := p.Add(d)
+		for inBounds(q) && b.At(q) == other(color) {
+			line = append(line, q)
+			q = q.Add(d)
+		}
+		if len(line) > 0 && inBounds(q) && b.At(q) == color {
+			flipped = append(flipped, line...)
+		}
+	}
+	return flipped
+}
+
+// movesFor lists the squares color can place on, row by row.
+func (b *Board) movesFor(color int) []Move {
+	var moves []Move
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			if p := (grid.Point{X: x, Y: y}); len(b.flips(color, p)) > 0 {
+				moves = append(moves, p)
+			}
+		}
+	}
+	return moves
+}
+
+// Moves lists the squares the side to move can place on. None means the
+// game is over, since Play passes for sides that can't move.
+func (b *Board) Moves() []Move {
+	return b.movesFor(b.Turn)
+}
+
+func (b *Board) Legal(m Move) bool {
+	return len(b.flips(b.Turn, m)) > 0
+}
+
+// Play is the board after the side to move places on m, which must be
+// legal. The turn passes back when the other side has no move.
+func (b Board) Play(m Move) Board {
+	for _, p := range b.flips(b.Turn, m) {
+		b.Cells[p.Y][p.X] = b.Turn
+	}
+	b.Cells[m.Y][m.X] = b.Turn
+	b.Passed = false
+	if len(b.movesFor(other(b.Turn))) > 0 || len(b.movesFor(b.Turn)) == 0 {
+		b.Turn = other(b.Turn)
+	} else {
+		b.Passed = true
+	}
+	return b
+}
+
+// Count is how many discs each side has.
+func (b *Board) Count() (dark, light int) {
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			switch b.Cells[y][x] {
+			case DARK:
+				dark++
+			case LIGHT:
+				light++
+			}
+		}
+	}
+	return dark, light
+}
+
+// discs is how many more discs color has than the other side.
+func (b *Board) discs(color int) int {
+	dark, light := b.Count()
+	if color == LIGHT {
+		return light - dark
+	}
+	return dark - light
+}
+
+// eval rates the board for the side to move by mobility: the moves it has
+// over the other side's, then the corners, which never flip, and the
+// squares beside empty corners, which give them away. Discs themselves
+// hardly count before the end, when most change hands again.
+func (b *Board) eval() int {
+	me, them := b.Turn, other(b.Turn)
+	score := MOBILITY * (len(b.movesFor(me)) - len(b.movesFor(them)))
+	for _, c := range corners {
+		switch b.At(c) {
+		case me:
+			score += CORNERVALUE
+		case them:
+			score -= CORNERVALUE
+		case NONE:
+			// The diagonal neighbor, toward the middle.
+			x := grid.Point{X: c.X + 1 - 2*(c.X/(SIZE-1)), Y: c.Y + 1 - 2*(c.Y/(SIZE-1))}
+			switch b.At(x) {
+			case me:
+				score -= XVALUE
+			case them:
+				score += XVALUE
+			}
+		}
+	}
+	return score
+}
+
+// search rates the board for the side to move, looking depth moves ahead
+// by negamax with alpha-beta pruning. A finished game scores by its discs,
+// beyond anything eval gives.
+func (b *Board) search(depth, alpha, beta int) int {
+	moves := b.Moves()
+	if len(moves) == 0 {
+		d := b.discs(b.Turn)
+		switch {
+		case d > 0:
+			return WINSCORE + d
+		case d < 0:
+			return -WINSCORE + d
+		}
+		return 0
+	}
+	if depth == 0 {
+		return b.eval()
+	}
+	for _, m := range moves {
+		next := b.Play(m)
+		var s int
+		// A pass keeps the turn, so the score is already ours.
+		if next.Turn == b.Turn {
+			s = next.search(depth-1, alpha, beta)
+		} else {
+			s = -next.search(depth-1, -beta, -alpha)
+		}
+		if s > alpha {
+			alpha = s
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return alpha
+}
+
+// Best picks a move for the side to move, at random between moves that
+// rate the same.
+func (b *Board) Best(rng *rand.Rand) Move {
+	var best []Move
+	top := 0
+	for _, m := range b.Moves() {
+		next := b.Play(m)
+		var s int
+		if next.Turn == b.Turn {
+			s = next.search(SEARCHPLY-1, -WINSCORE*2, WINSCORE*2)
+		} else {
+			s = -next.search(SEARCHPLY-1, -WINSCORE*2, WINSCORE*2)
+		}
+		switch {
+		case len(best) == 0 || s > top:
+			top, best = s, []Move{m}
+		case s == top:
+			best = append(best, m)
+		}
+	}
+	if len(best) == 0 {
+		return Move{X: -1, Y: -1}
+	}
+	return best[rng.Intn(len(best))]
+}
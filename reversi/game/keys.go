@@ -0,0 +1,70 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Place   key.Binding
+	Resign  key.Binding
+	Rematch key.Binding
+	Review  key.Binding
+	Bot     key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Place:    key.NewBinding(key.WithKeys("enter", " ", ui.KEYPADENTER), key.WithHelp("enter", "place")),
+		Resign:   key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "resign")),
+		Rematch:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new match")),
+		Review:   key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "review")),
+		Bot:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "play the computer")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Place, k.Resign, k.Bot, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Place, k.Resign, k.Review, k.Rematch, k.Bot},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":      &k.Up,
+		"down":    &k.Down,
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"place":   &k.Place,
+		"resign":  &k.Resign,
+		"rematch": &k.Rematch,
+		"review":  &k.Review,
+		"bot":     &k.Bot,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+// Why matches end on the board
+const (
+	FULL    = "board full"
+	BLOCKED = "no moves left"
+)
+
+type (
+	Match    = turns.Match[Board, Move]
+	Snapshot = turns.Snapshot[Board, Move]
+)
+
+// Dark takes the first seat.
+var seatColors = [2]int{DARK, LIGHT}
+
+func toMove(b Board) int {
+	return b.Turn - DARK
+}
+
+var lobby = turns.NewLobby(turns.Rules[Board, Move]{
+	Start:  NewBoard,
+	ToMove: toMove,
+	Play: func(b Board, mv Move) (Board, bool) {
+		if !b.Legal(mv) {
+			return b, false
+		}
+		return b.Play(mv), true
+	},
+	// Once neither side can move, the most discs win.
+	Result: func(b Board) (int, string, bool) {
+		if len(b.Moves()) > 0 {
+			return 0, "", false
+		}
+		reason := BLOCKED
+		if dark, light := b.Count(); dark+light == SIZE*SIZE {
+			reason = FULL
+		}
+		switch d := b.discs(DARK); {
+		case d > 0:
+			return DARK - DARK, reason, true
+		case d < 0:
+			return LIGHT - DARK, reason, true
+		}
+		return -1, reason, true
+	},
+	Bot: func(b Board, rng *rand.Rand) Move {
+		return b.Best(rng)
+	},
+})
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/review"
+)
+
+// REVIEWPLY is how far ahead finished matches are searched to annotate
+// them.
+const REVIEWPLY = 4
+
+// evaluate rates b for dark, the first player, by the engine's search,
+// in its units: a move of mobility is worth MOBILITY.
+func evaluate(b Board) int {
+	eval := b.search(REVIEWPLY, -WINSCORE*2, WINSCORE*2)
+	if b.Turn == LIGHT {
+		eval = -eval
+	}
+	return eval
+}
+
+// notation names p as Othello transcripts do, a1 at the top left.
+func notation(p grid.Point) string {
+	return fmt.Sprintf("%c%d", 'a'+p.X, p.Y+1)
+}
+
+// record is a finished match as the review screen steps through it.
+type record struct {
+	snap   Snapshot
+	boards []Board // after each move, the starting position first
+	moves  []review.Move
+	render func(b Board, last *Move) string
+}
+
+func newRecord(snap Snapshot, render func(Board, *Move) string) *record {
+	r := &record{snap: snap, render: render}
+	b := NewBoard()
+	r.boards = append(r.boards, b)
+	for _, mv := range snap.Moves {
+		// Passes keep the turn, so players don't simply alternate.
+		player := toMove(b)
+		b = b.Play(mv)
+		r.boards = append(r.boards, b)
+		r.moves = append(r.moves, review.Move{Player: player, Notation: notation(mv), Eval: evaluate(b)})
+	}
+	return r
+}
+
+func (r *record) Moves() []review.Move {
+	return r.moves
+}
+
+func (r *record) Position(n int) string {
+	var last *Move
+	if n > 0 {
+		last = &r.snap.Moves[n-1]
+	}
+	return r.render(r.boards[n], last)
+}
+
+// Export writes the match as an Othello transcript, the squares played one
+// after the other, which Othello programs read.
+func (r *record) Export() string {
+	var s strings.Builder
+	for _, m := range r.moves {
+		s.WriteString(m.Notation)
+	}
+	return s.String()
+}
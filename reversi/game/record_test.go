@@ -0,0 +1,42 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/turns"
+)
+
+func TestRecord(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	snap := Snapshot{Phase: turns.OVER}
+	b := NewBoard()
+	var players []int
+	for len(b.Moves()) > 0 {
+		mv := b.Best(rng)
+		snap.Moves = append(snap.Moves, mv)
+		players = append(players, toMove(b))
+		b = b.Play(mv)
+	}
+
+	r := newRecord(snap, func(Board, *Move) string { return "" })
+	moves := r.Moves()
+	if len(moves) != len(snap.Moves) {
+		t.Fatalf("%d moves recorded, want %d", len(moves), len(snap.Moves))
+	}
+	// Dark opens on one of the same four squares in every game.
+	if first := moves[0].Notation; !slices.Contains([]string{"d3", "c4", "f5", "e6"}, first) {
+		t.Errorf("opening written %q, want d3, c4, f5 or e6", first)
+	}
+	transcript := ""
+	for i, mv := range moves {
+		if mv.Player != players[i] {
+			t.Errorf("move %d by player %d, want %d", i+1, mv.Player, players[i])
+		}
+		transcript += mv.Notation
+	}
+	if got := r.Export(); got != transcript {
+		t.Errorf("Export() = %q, want %q", got, transcript)
+	}
+}
@@ -0,0 +1,470 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/review"
+	"github.com/debemdeboas/games.debem.dev/turns"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	POLL      = 100 * time.Millisecond
+	TOPSCORES = 5 // shown after a match
+)
+
+var colorNames = map[int]string{DARK: "Black", LIGHT: "White"}
+
+// discNames draws the discs, filled and hollow so they tell apart without
+// colors too.
+var discNames = map[int]string{DARK: "●", LIGHT: "○"}
+
+var reasons = map[string]string{
+	FULL:           "Board full",
+	BLOCKED:        "Neither side can move",
+	turns.RESIGNED: "Resigned",
+	turns.LEFT:     "Opponent left",
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	BoardStyle   lipgloss.Style
+	CursorStyle  lipgloss.Style
+	LastStyle    lipgloss.Style
+	FlippedStyle lipgloss.Style
+	QuitStyle    lipgloss.Style
+	YouStyle     lipgloss.Style
+	BoxStyle     lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, ranks the discs each player ends matches with
+	// under Player's name and fingerprint.
+	Scores    leaderboard.Store
+	top       []leaderboard.Entry
+	submitted bool
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room  string
+	match *Match
+	side  int
+	snap  Snapshot
+
+	cursor grid.Point
+	// flipped are the discs the last move turned, found by comparing the
+	// board to the one before it.
+	flipped []grid.Point
+	review  *review.Model
+
+	showHelp bool
+
+	r   *lipgloss.Renderer
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:        width,
+		Height:       height,
+		BoardStyle:   r.NewStyle().Background(lipgloss.Color("28")),
+		CursorStyle:  r.NewStyle().Background(lipgloss.Color("75")),
+		LastStyle:    r.NewStyle().Background(lipgloss.Color("142")),
+		FlippedStyle: r.NewStyle().Background(lipgloss.Color("65")),
+		QuitStyle:    r.NewStyle().Foreground(lipgloss.Color("8")),
+		YouStyle:     r.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		r:      r,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = lobby.Join(m.Room, m.Player, now)
+	m.begin(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.Opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.Leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+// PlayBot gives up waiting and plays the computer instead.
+func (m *Model) PlayBot() {
+	m.match.Leave(m.side)
+	now := time.Now()
+	m.match, m.side = lobby.VersusBot(m.Player, now)
+	m.begin(now)
+}
+
+func (m *Model) begin(now time.Time) {
+	m.snap = Snapshot{}
+	m.flipped, m.review = nil, nil
+	m.top = nil
+	m.submitted = false
+	m.cursor = grid.Point{X: SIZE/2 - 1, Y: SIZE/2 - 1}
+	m.refresh(now)
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.match.Poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	if len(snap.Moves) != len(m.snap.Moves) {
+		m.flipped = changed(m.snap.State, snap.State)
+	}
+	m.snap = snap
+	if snap.Phase == turns.OVER && !m.submitted {
+		m.submit()
+	}
+}
+
+// changed are the discs on both boards that changed color between them.
+func changed(before, after Board) []grid.Point {
+	var ps []grid.Point
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			if was := before.Cells[y][x]; was != NONE && was != after.Cells[y][x] {
+				ps = append(ps, grid.Point{X: x, Y: y})
+			}
+		}
+	}
+	return ps
+}
+
+func (m Model) color() int {
+	if m.snap.Phase == turns.WAITING {
+		return DARK
+	}
+	return seatColors[m.snap.Players[m.side].Seat]
+}
+
+func (m Model) myTurn() bool {
+	return m.snap.Phase == turns.PLAYING && m.snap.State.Turn == m.color()
+}
+
+// discs is how many discs side i has.
+func (m Model) discs(i int) int {
+	dark, light := m.snap.State.Count()
+	if seatColors[m.snap.Players[i].Seat] == LIGHT {
+		return light
+	}
+	return dark
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	mode := "versus"
+	if slices.ContainsFunc(m.snap.Players, func(p turns.Player) bool { return p.Bot }) {
+		mode = "computer"
+	}
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      mode,
+		Modifiers: leaderboard.NOMODIFIERS,
+		Board:     fmt.Sprintf("%dx%d", SIZE, SIZE),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+// submit records the discs the player ended a match with on the
+// leaderboard. Matches given up before the board settled don't count.
+func (m *Model) submit() {
+	m.submitted = true
+	if m.Scores == nil || m.snap.Reason == turns.RESIGNED || m.snap.Reason == turns.LEFT {
+		return
+	}
+	k := m.scoreKey()
+	score := m.discs(m.side)
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player.Name,
+		Fingerprint: m.Player.Fingerprint,
+		Score:       score,
+		Points:      score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) moveCursor(d grid.Point) {
+	p := m.cursor.Add(d)
+	m.cursor = grid.Point{X: max(0, min(SIZE-1, p.X)), Y: max(0, min(SIZE-1, p.Y))}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		if m.review != nil {
+			m.review.Update(msg)
+			if m.review.Closed() {
+				m.review = nil
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.Leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Resign):
+			m.match.Resign(m.side)
+			m.refresh(time.Now())
+		case key.Matches(msg, m.Keys.Review):
+			if m.snap.Phase == turns.OVER {
+				render := func(b Board, last *Move) string { return m.boardView(b, last, false) }
+				m.review = review.New(newRecord(m.snap, render), m.r)
+			}
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == turns.OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Bot):
+			if m.snap.Phase == turns.WAITING || m.snap.Phase == turns.OVER {
+				m.PlayBot()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(grid.Right)
+		case key.Matches(msg, m.Keys.Place):
+			if m.myTurn() {
+				m.match.Play(m.side, m.cursor)
+				m.refresh(time.Now())
+			}
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+// square draws p of b, with moves the squares the player may place on.
+func (m Model) square(b Board, p grid.Point, last *Move, moves []Move, live bool) string {
+	style := m.BoardStyle
+	switch {
+	case live && p == m.cursor:
+		style = m.CursorStyle
+	case last != nil && p == *last:
+		style = m.LastStyle
+	case live && slices.Contains(m.flipped, p):
+		style = m.FlippedStyle
+	}
+
+	switch disc := b.At(p); {
+	case disc == DARK:
+		return style.Foreground(lipgloss.Color("0")).Render(" " + discNames[disc] + " ")
+	case disc == LIGHT:
+		return style.Foreground(lipgloss.Color("15")).Render(" " + discNames[disc] + " ")
+	case slices.Contains(moves, p):
+		return style.Foreground(lipgloss.Color("0")).Render(" · ")
+	}
+	return style.Render("   ")
+}
+
+// boardView draws b, with the cursor, the discs just flipped and the
+// player's moves when live, for the match's own board.
+func (m Model) boardView(b Board, last *Move, live bool) string {
+	var moves []Move
+	if live && m.myTurn() {
+		moves = b.Moves()
+	}
+	var s strings.Builder
+	for y := 0; y < SIZE; y++ {
+		for x := 0; x < SIZE; x++ {
+			s.WriteString(m.square(b, grid.Point{X: x, Y: y}, last, moves, live))
+		}
+		if y < SIZE-1 {
+			s.WriteString("\n")
+		}
+	}
+	return s.String()
+}
+
+func (m Model) lastMove() *Move {
+	if n := len(m.snap.Moves); n > 0 {
+		return &m.snap.Moves[n-1]
+	}
+	return nil
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Players {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		color := seatColors[p.Seat]
+		sides = append(sides, fmt.Sprintf("%s %s: %s %d", discNames[color], colorNames[color], name, m.discs(i)))
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case turns.WAITING:
+		return fmt.Sprintf("Waiting for an opponent...\n\nPress '%s' to play the computer", m.Keys.Bot.Help().Key)
+	case turns.PLAYING:
+		them := m.snap.Players[1-m.side].Name
+		switch {
+		case m.myTurn() && m.snap.State.Passed:
+			return fmt.Sprintf("Your move • %s has none and passes", them)
+		case m.myTurn():
+			return "Your move"
+		case m.snap.State.Passed:
+			return fmt.Sprintf("You have no move • %s goes again...", them)
+		}
+		return fmt.Sprintf("%s to move...", them)
+	}
+	return ""
+}
+
+func (m Model) topView() string {
+	if len(m.top) == 0 {
+		return ""
+	}
+	lines := []string{"", "Most discs"}
+	for i, e := range m.top {
+		line := fmt.Sprintf("%d. %-12s %2d", i+1, e.Player, e.Score)
+		if e.Fingerprint != "" && e.Fingerprint == m.Player.Fingerprint {
+			line = m.YouStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) resultView() string {
+	var outcome string
+	switch m.snap.Winner {
+	case -1:
+		outcome = "Draw"
+	case m.side:
+		outcome = "You win!"
+	default:
+		outcome = m.snap.Players[m.snap.Winner].Name + " wins"
+	}
+	reason := reasons[m.snap.Reason]
+	if m.snap.Reason == turns.RESIGNED && m.snap.Winner == m.side {
+		reason = m.snap.Players[1-m.side].Name + " resigned"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s: %s, %d to %d\n%s\n\nPress '%s' to review and copy the transcript, '%s' for a new match or '%s' to play the computer",
+		reason, outcome, m.discs(m.side), m.discs(1-m.side), m.topView(), m.Keys.Review.Help().Key, m.Keys.Rematch.Help().Key, m.Keys.Bot.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.review != nil {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.review.View(),
+		)
+	}
+
+	if m.snap.Phase == turns.WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == turns.OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardView(m.snap.State, m.lastMove(), true),
+			"",
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,174 @@
+// Package review is the post-match analysis screen of turn-based board
+// games. A game hands over its finished match, already annotated by its
+// engine; the screen steps through the moves, highlights blunders and copies
+// the match to the player's clipboard in the game's own notation (PGN for
+// chess, PDN for checkers, a transcript for reversi) over OSC 52.
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	BLUNDER = 200 // evaluation lost by a move, in centipawns, to count as a blunder
+	MISTAKE = 80
+	LISTED  = 10 // moves listed around the current one
+)
+
+// Move is a played move with the engine's evaluation of the position after
+// it, in centipawns from the first player's point of view.
+type Move struct {
+	Player   int // 0 for the first player
+	Notation string
+	Eval     int
+}
+
+// Match is a finished match as the review screen needs it.
+type Match interface {
+	Moves() []Move
+	// Position renders the board after the first n moves.
+	Position(n int) string
+	// Export encodes the match in the game's notation.
+	Export() string
+}
+
+// Loss is how much evaluation move i gave away for the player who made it.
+func Loss(moves []Move, i int) int {
+	before := 0
+	if i > 0 {
+		before = moves[i-1].Eval
+	}
+	loss := before - moves[i].Eval
+	if moves[i].Player != 0 {
+		loss = -loss
+	}
+	return max(0, loss)
+}
+
+type KeyMap struct {
+	ui.MoveKeys
+	Blunder key.Binding
+	Export  key.Binding
+	Close   key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		MoveKeys: ui.DefaultMoveKeys(),
+		Blunder:  key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "next blunder")),
+		Export:   key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "copy notation")),
+		Close:    key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc", "close")),
+	}
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Left, k.Right, k.Blunder, k.Export, k.Close}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// Model steps through a match. Position 0 is the starting position and
+// position n is the board after n moves.
+type Model struct {
+	Keys KeyMap
+
+	match  Match
+	moves  []Move
+	cursor int
+	r      *lipgloss.Renderer
+	copied bool
+	closed bool
+
+	blunder lipgloss.Style
+	mistake lipgloss.Style
+	current lipgloss.Style
+	dim     lipgloss.Style
+}
+
+func New(m Match, r *lipgloss.Renderer) *Model {
+	return &Model{
+		Keys:    DefaultKeyMap(),
+		match:   m,
+		moves:   m.Moves(),
+		r:       r,
+		blunder: r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+		mistake: r.NewStyle().Foreground(lipgloss.Color("11")),
+		current: r.NewStyle().Reverse(true),
+		dim:     r.NewStyle().Foreground(lipgloss.Color("8")),
+	}
+}
+
+// Closed reports whether the player left the review.
+func (m *Model) Closed() bool {
+	return m.closed
+}
+
+func (m *Model) Update(msg tea.KeyMsg) {
+	m.copied = false
+	switch {
+	case key.Matches(msg, m.Keys.Left), key.Matches(msg, m.Keys.Up):
+		m.cursor = max(0, m.cursor-1)
+	case key.Matches(msg, m.Keys.Right), key.Matches(msg, m.Keys.Down):
+		m.cursor = min(len(m.moves), m.cursor+1)
+	case key.Matches(msg, m.Keys.Blunder):
+		m.nextBlunder()
+	case key.Matches(msg, m.Keys.Export):
+		m.r.Output().Copy(m.match.Export())
+		m.copied = true
+	case key.Matches(msg, m.Keys.Close):
+		m.closed = true
+	}
+}
+
+// nextBlunder jumps to the position right after the next blunder, wrapping
+// around to the first one.
+func (m *Model) nextBlunder() {
+	for step := 1; step <= len(m.moves); step++ {
+		i := (m.cursor + step - 1) % len(m.moves)
+		if Loss(m.moves, i) >= BLUNDER {
+			m.cursor = i + 1
+			return
+		}
+	}
+}
+
+func (m *Model) View() string {
+	eval := 0
+	if m.cursor > 0 {
+		eval = m.moves[m.cursor-1].Eval
+	}
+
+	var list strings.Builder
+	from := max(0, m.cursor-LISTED/2)
+	for i := from; i < min(len(m.moves), from+LISTED); i++ {
+		mv := m.moves[i]
+		line := fmt.Sprintf("%3d. %-8s %+6.2f", i+1, mv.Notation, float64(mv.Eval)/100)
+		switch loss := Loss(m.moves, i); {
+		case loss >= BLUNDER:
+			line = m.blunder.Render(line + " ??")
+		case loss >= MISTAKE:
+			line = m.mistake.Render(line + " ?")
+		}
+		if i == m.cursor-1 {
+			line = m.current.Render(line)
+		}
+		list.WriteString(line + "\n")
+	}
+
+	status := fmt.Sprintf("Move %d/%d • eval %+.2f", m.cursor, len(m.moves), float64(eval)/100)
+	if m.copied {
+		status += " • copied to clipboard"
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top, m.match.Position(m.cursor), "  ", list.String()),
+		m.dim.Render(status),
+	)
+}
@@ -0,0 +1,63 @@
+// Package season schedules date-driven cosmetic events such as holiday food
+// and borders.
+package season
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Day is a day of the year, independent of the year itself.
+type Day struct {
+	Month time.Month
+	Day   int
+}
+
+func (d Day) before(o Day) bool {
+	return d.Month < o.Month || d.Month == o.Month && d.Day < o.Day
+}
+
+// Event overrides some cosmetics between From and To, inclusive. Ranges may
+// wrap around the new year. Empty fields leave the game's own look alone.
+type Event struct {
+	Name        string
+	From, To    Day
+	Food        string // two columns wide
+	Border      *lipgloss.Border
+	BorderColor lipgloss.Color
+}
+
+// Covers reports whether the event runs on day d.
+func (e Event) Covers(d Day) bool {
+	if e.To.before(e.From) {
+		return !d.before(e.From) || !e.To.before(d)
+	}
+	return !d.before(e.From) && !e.To.before(d)
+}
+
+// Schedule lists events in priority order; the first one that covers a date
+// wins.
+type Schedule []Event
+
+var snowBorder = lipgloss.Border{
+	Top: "*", Bottom: "*", Left: "*", Right: "*",
+	TopLeft: "*", TopRight: "*", BottomLeft: "*", BottomRight: "*",
+}
+
+// DefaultSchedule runs pumpkins through October and snow through December.
+var DefaultSchedule = Schedule{
+	{Name: "Halloween", From: Day{time.October, 1}, To: Day{time.October, 31}, Food: "🎃"},
+	{Name: "Winter", From: Day{time.December, 1}, To: Day{time.December, 31}, Border: &snowBorder, BorderColor: "15"},
+}
+
+// Active returns the event running at t, if any.
+func (s Schedule) Active(t time.Time) (Event, bool) {
+	d := Day{Month: t.Month(), Day: t.Day()}
+	for _, e := range s {
+		if e.Covers(d) {
+			return e, true
+		}
+	}
+	return Event{}, false
+}
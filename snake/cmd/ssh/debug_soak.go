@@ -0,0 +1,26 @@
+//go:build soak
+
+package main
+
+import (
+	"expvar"
+	"net/http"
+	_ "net/http/pprof"
+	"runtime"
+
+	"github.com/charmbracelet/log"
+)
+
+const debugAddr = "localhost:6060"
+
+// startDebugServer exposes expvar and pprof on debugAddr for the soak tool in builds
+// made with `-tags soak`.
+func startDebugServer() {
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+	go func() {
+		log.Info("Starting debug server", "addr", debugAddr)
+		if err := http.ListenAndServe(debugAddr, nil); err != nil {
+			log.Error("Debug server error", "error", err)
+		}
+	}()
+}
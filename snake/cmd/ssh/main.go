@@ -2,39 +2,94 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/debemdeboas/games.debem.dev/config"
+	"github.com/debemdeboas/games.debem.dev/frameskip"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/latency"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/lifecycle"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/quota"
+	"github.com/debemdeboas/games.debem.dev/record"
 	snake "github.com/debemdeboas/games.debem.dev/snake/game"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/activeterm"
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/net/context"
 )
 
 const (
-	host = "0.0.0.0"
-	port = "23232"
+	keptRecordings = 100
+	scoresFile     = "scores.db"
+	profilesFile   = "profiles.db"
+)
+
+var (
+	recordings = record.NewMemorySink(keptRecordings)
+	cfg        config.Config
+	scores     leaderboard.Store
+	profiles   profile.Store
 )
 
 func main() {
-	log.SetLevel(log.DebugLevel)
+	var err error
+	if cfg, err = config.Load(); err != nil {
+		log.Fatal("Could not load config", "error", err)
+	}
+	cfg.Flags(flag.CommandLine)
+	flag.Parse()
+
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatal("Invalid log level", "level", cfg.LogLevel, "error", err)
+	}
+	log.SetLevel(level)
+
+	if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+		log.Fatal("Could not create data directory", "dir", cfg.DataDir, "error", err)
+	}
+	db, err := leaderboard.OpenSQLite(cfg.Path(scoresFile))
+	if err != nil {
+		log.Fatal("Could not open leaderboard", "path", cfg.Path(scoresFile), "error", err)
+	}
+	defer db.Close()
+	scores = db
+
+	pdb, err := profile.OpenSQLite(cfg.Path(profilesFile))
+	if err != nil {
+		log.Fatal("Could not open profiles", "path", cfg.Path(profilesFile), "error", err)
+	}
+	defer pdb.Close()
+	profiles = pdb
 
 	s, err := wish.NewServer(
-		wish.WithAddress(net.JoinHostPort(host, port)),
-		wish.WithHostKeyPath("host.key"),
+		wish.WithAddress(net.JoinHostPort(cfg.Host, cfg.Port)),
+		wish.WithHostKeyPath(cfg.HostKey),
+		// Any key is welcome: it only identifies the player on the
+		// leaderboard. Players without one still get in, anonymously.
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
 		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
+			bubbletea.MiddlewareWithProgramHandler(
+				frameskip.ProgramHandler(record.Handler(teaHandler, recordings, record.EnvConsent)),
+				termenv.Ascii,
+			),
 			activeterm.Middleware(),
+			lifecycle.Middleware(),
 			logging.Middleware(),
 		),
 	)
@@ -42,9 +97,11 @@ func main() {
 		log.Error("Could not start server", "error", err)
 	}
 
+	startDebugServer()
+
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	log.Info("Starting SSH server", "host", host, "port", port)
+	log.Info("Starting SSH server", "host", cfg.Host, "port", cfg.Port)
 	go func() {
 		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 			log.Error("Server error", "error", err)
@@ -64,36 +121,42 @@ func main() {
 func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 	pty, _, _ := s.Pty()
 
-	renderer := bubbletea.MakeRenderer(s)
-	txtStyle := renderer.NewStyle().Foreground(lipgloss.Color("10")).BorderStyle(lipgloss.RoundedBorder())
-	quitStyle := renderer.NewStyle().Foreground(lipgloss.Color("8"))
-	foodStyle := renderer.NewStyle().Foreground(lipgloss.Color("9"))
-	snakeStyle := renderer.NewStyle().Foreground(lipgloss.Color("10"))
+	fp := fingerprint(s)
+	p := profile.Load(profiles, fp, s.User())
+
+	meter := latency.NewMeter()
+	lifecycle.Go(s, "latency", func(ctx context.Context) {
+		meter.Run(ctx, s, latency.INTERVAL)
+	})
 
-	bg := "light"
-	if renderer.HasDarkBackground() {
-		bg = "dark"
+	m, err := games.New(snake.GAMENAME, games.Env{
+		Ctx:         lifecycle.Context(s),
+		Player:      p.Name,
+		Fingerprint: fp,
+		Term:        pty.Term,
+		Width:       pty.Window.Width,
+		Height:      pty.Window.Height,
+		Renderer:    bubbletea.MakeRenderer(s),
+		Environ:     s.Environ(),
+		Latency:     meter,
+		Scores:      scores,
+		Profile:     p,
+		Profiles:    profiles,
+		Board:       cfg.Board,
+		Tick:        cfg.Tick,
+	})
+	if err != nil {
+		wish.Fatalln(s, err)
+		return nil, nil
 	}
 
-	m := snake.NewModel(
-		pty.Term,
-		renderer.ColorProfile().Name(),
-		pty.Window.Width,
-		pty.Window.Height,
-		bg,
-		txtStyle,
-		quitStyle,
-		foodStyle,
-		snakeStyle,
-		lipgloss.NewStyle().SetString("  "),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
-		lipgloss.
-			NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
-			Align(lipgloss.Center).
-			Background(lipgloss.Color("#363636")).
-			Padding(3),
-	)
+	return quota.Wrap(lifecycle.Context(s), snake.GAMENAME, m, quota.DefaultLimits), []tea.ProgramOption{tea.WithAltScreen()}
+}
 
-	return m, []tea.ProgramOption{tea.WithAltScreen()}
+// fingerprint identifies the key the player authenticated with, if any.
+func fingerprint(s ssh.Session) string {
+	if s.PublicKey() == nil {
+		return ""
+	}
+	return gossh.FingerprintSHA256(s.PublicKey())
 }
@@ -0,0 +1,28 @@
+package duel
+
+import "github.com/debemdeboas/games.debem.dev/grid"
+
+// steer points a bot towards the open cell with the most room around it,
+// keeping its heading on ties. It doesn't chase food or its opponent: it
+// just tries to outlast them.
+func (m *Match) steer(s *side) {
+	board := grid.New[bool](WIDTH, HEIGHT)
+	for _, o := range m.sides {
+		for _, p := range o.body {
+			board.Set(p, true)
+		}
+	}
+	free := func(_ grid.Point, taken bool) bool { return !taken }
+
+	best, room := s.dir, -1
+	for _, d := range append([]grid.Point{s.dir}, grid.Dirs4...) {
+		next := s.body[0].Add(d)
+		if d.Add(s.dir) == (grid.Point{}) || !board.InBounds(next) || board.At(next) {
+			continue
+		}
+		if space := len(board.FloodFill(next, free)); space > room {
+			best, room = d, space
+		}
+	}
+	s.dir = best
+}
@@ -0,0 +1,337 @@
+package duel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/latency"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = STEP / 2
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles, one snake style per side
+	SnakeStyles [2]lipgloss.Style
+	FoodStyle   lipgloss.Style
+	BoardStyle  lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room string
+	// Latency, when set, reports the session's network round trip, which
+	// the match makes up for.
+	Latency *latency.Meter
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell  *bell.Bell
+	count bell.Countdown
+	mode  int
+	match *Match
+	side  int
+	snap  Snapshot
+	slow  bool // see SetSlow
+	// cast follows the match for casters, see Scoreboard.
+	cast spectate.Match
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player, mode int) *Model {
+	m := &Model{
+		Width:  width,
+		Height: height,
+		SnakeStyles: [2]lipgloss.Style{
+			r.NewStyle().Foreground(lipgloss.Color("10")),
+			r.NewStyle().Foreground(lipgloss.Color("39")),
+		},
+		FoodStyle:  r.NewStyle().Foreground(lipgloss.Color("9")),
+		BoardStyle: r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		QuitStyle:  r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		mode:   mode,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = join(m.mode, m.Room, m.Player, now)
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+// SetSlow has matches against the computer run slower, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// PlayBot gives up waiting and plays the computer instead.
+func (m *Model) PlayBot() {
+	m.match.leave(m.side)
+	now := time.Now()
+	m.match, m.side = versusBot(m.mode, m.Player, now, m.slow)
+	m.refresh(now)
+}
+
+func (m *Model) refresh(now time.Time) {
+	m.match.measure(m.side, m.Latency.RTT())
+	snap, ok := m.match.poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	m.cue(m.snap, snap, now)
+	m.snap = snap
+	m.score()
+}
+
+// score follows the match for casters, who see the length of each snake.
+func (m *Model) score() {
+	if m.snap.Phase == COUNTING {
+		m.cast = spectate.Match{Title: m.Name()}
+	}
+	var lengths []int
+	for _, s := range m.snap.Snakes {
+		lengths = append(lengths, len(s.Body))
+	}
+	m.cast.Track(lengths...)
+	for i, s := range m.snap.Snakes {
+		m.cast.Players[i].Name = s.Name
+	}
+}
+
+// Scoreboard is the match for casters, see spectate.Scoreboard.
+func (m Model) Scoreboard() (spectate.Match, bool) {
+	return m.cast, m.snap.Phase != WAITING
+}
+
+// cue rings for what changed between snapshots: the countdown's seconds,
+// and the player's snake eating or dying.
+func (m *Model) cue(prev, snap Snapshot, now time.Time) {
+	left := time.Duration(0)
+	if snap.Phase == COUNTING {
+		left = snap.Start.Sub(now)
+	}
+	m.count.Update(m.Bell, left)
+
+	if prev.Phase != PLAYING || m.side >= len(prev.Snakes) || m.side >= len(snap.Snakes) {
+		return
+	}
+	was, is := prev.Snakes[m.side], snap.Snakes[m.side]
+	switch {
+	case was.Alive && !is.Alive:
+		m.Bell.Ring(bell.DEATH)
+	case snap.Mode == SNAKE && len(is.Body) > len(was.Body):
+		// Light cycles grow every step, so only snakes eat.
+		m.Bell.Ring(bell.EAT)
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Bot):
+			if m.snap.Phase == WAITING || m.snap.Phase == OVER {
+				m.PlayBot()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.match.turn(m.side, grid.Up, time.Now())
+		case key.Matches(msg, m.Keys.Down):
+			m.match.turn(m.side, grid.Down, time.Now())
+		case key.Matches(msg, m.Keys.Left):
+			m.match.turn(m.side, grid.Left, time.Now())
+		case key.Matches(msg, m.Keys.Right):
+			m.match.turn(m.side, grid.Right, time.Now())
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func (m Model) boardView() string {
+	board := grid.New[string](WIDTH, HEIGHT)
+	board.Fill("  ")
+	if m.snap.Mode == SNAKE {
+		board.Set(m.snap.Food, m.FoodStyle.Render("██"))
+	}
+	for i, s := range m.snap.Snakes {
+		style := m.SnakeStyles[i%len(m.SnakeStyles)]
+		if !s.Alive {
+			style = style.Faint(true)
+		}
+		for j, p := range s.Body {
+			cell := "▒▒"
+			if j == 0 {
+				cell = "██"
+			}
+			board.Set(p, style.Render(cell))
+		}
+	}
+
+	var s strings.Builder
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			s.WriteString(board.At(grid.Point{X: x, Y: y}))
+		}
+		if y < HEIGHT-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) header() string {
+	var names []string
+	for i, s := range m.snap.Snakes {
+		name := s.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		names = append(names, m.SnakeStyles[i%len(m.SnakeStyles)].Render(fmt.Sprintf("%s %d", name, len(s.Body))))
+	}
+	return strings.Join(names, m.QuitStyle.Render("  vs  "))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case WAITING:
+		return fmt.Sprintf("Waiting for an opponent...\n\nPress '%s' to play the computer", m.Keys.Bot.Help().Key)
+	case COUNTING:
+		left := max(0, time.Until(m.snap.Start))
+		return fmt.Sprintf("Starting in %d", int(left.Seconds())+1)
+	case PLAYING:
+		if m.snap.Hiccup != "" {
+			return m.snap.Hiccup
+		}
+		return "Go!"
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	result := "Draw!"
+	switch m.snap.Winner {
+	case m.side:
+		result = "You win!"
+	case DRAW:
+	default:
+		result = m.snap.Snakes[m.snap.Winner].Name + " wins"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s\n\nPress '%s' for a new match or '%s' to play the computer",
+		result, m.Keys.Rematch.Help().Key, m.Keys.Bot.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.snap.Phase == WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			m.boardView(),
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,479 @@
+package duel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/supervisor"
+	"github.com/debemdeboas/games.debem.dev/timesync"
+)
+
+const (
+	WIDTH     = 30
+	HEIGHT    = 20
+	STEP      = 120 * time.Millisecond // between moves
+	COUNTDOWN = 3 * time.Second
+	STARTSIZE = 4
+	TURNS     = 2               // turns a snake can queue ahead of its moves
+	STALE     = 3 * time.Second // players not heard from for this long forfeit
+	HICCUP    = 3 * time.Second // how long players are told the match was rewound
+)
+
+// Match phases
+const (
+	WAITING = iota
+	COUNTING
+	PLAYING
+	OVER
+)
+
+// DRAW is the winner of a match both snakes lost at once.
+const DRAW = -1
+
+// Modes, each with its own queue of waiting players
+const (
+	SNAKE = iota // snakes grow by eating
+	TRON         // light cycles: trails never shrink and there's no food
+	MODES
+)
+
+// BOTNAME names the computer opponent.
+const BOTNAME = "Bot"
+
+type side struct {
+	name  string
+	seat  block.Player // who sat down, empty for the computer
+	body  []grid.Point // head first
+	dir   grid.Point
+	turns []grid.Point
+	alive bool
+	bot   bool // steered by the match itself, never goes quiet
+	seen  time.Time
+}
+
+// Match is a board shared by two sessions. Like trivia rooms it has no
+// goroutine: whichever session polls moves the snakes for the time that
+// passed, so both always see the same board. Turns only reach the snakes
+// through the input buffer, on the move it schedules each press for. Once
+// play begins the moves run under a supervisor, so a panic rewinds the
+// board instead of ending both sessions.
+type Match struct {
+	mu     sync.Mutex
+	mode   int
+	phase  int
+	sides  []*side
+	food   grid.Point
+	start  time.Time     // of play, once both joined
+	next   time.Time     // of the next move
+	pace   time.Duration // between moves, STEP unless in slow mode
+	inputs *timesync.Buffer
+	winner int
+	rng    *rand.Rand
+	sup    *supervisor.Supervisor
+	hiccup string    // what players are told after a rewind
+	until  time.Time // of the hiccup
+}
+
+// queue tells apart the players waiting for an opponent: by mode, and by
+// the lobby room they came from so friends only meet each other.
+type queue struct {
+	mode int
+	room string
+}
+
+var (
+	matchesMu sync.Mutex
+	waiting   = make(map[queue][]*Match)
+)
+
+func newMatch(mode int, p block.Player, now time.Time) *Match {
+	m := &Match{mode: mode, phase: WAITING, pace: STEP, inputs: timesync.NewBuffer(STEP), rng: rand.New(rand.NewSource(now.UnixNano()))}
+	m.sides = []*side{{name: p.Label(), seat: p, seen: now}}
+	return m
+}
+
+// join pairs p with the first player waiting for an opponent in mode and
+// room who neither blocked nor was blocked by them, or waits for one.
+func join(mode int, room string, p block.Player, now time.Time) (*Match, int) {
+	matchesMu.Lock()
+	defer matchesMu.Unlock()
+
+	// Rooms come and go, so forget the matches nobody waits at anymore.
+	for k, waits := range waiting {
+		waits = slices.DeleteFunc(waits, func(m *Match) bool {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.drop(now)
+			return m.phase != WAITING || len(m.sides) == 0
+		})
+		if len(waits) == 0 {
+			delete(waiting, k)
+		} else {
+			waiting[k] = waits
+		}
+	}
+
+	q := queue{mode: mode, room: room}
+	for i, m := range waiting[q] {
+		m.mu.Lock()
+		if m.phase == WAITING && len(m.sides) == 1 && !block.Apart(m.sides[0].seat, p) {
+			waiting[q] = slices.Delete(waiting[q], i, i+1)
+			m.sides = append(m.sides, &side{name: p.Label(), seat: p, seen: now})
+			m.countdown(now)
+			m.mu.Unlock()
+			return m, 1
+		}
+		m.mu.Unlock()
+	}
+
+	m := newMatch(mode, p, now)
+	waiting[q] = append(waiting[q], m)
+	return m, 0
+}
+
+// versusBot starts a match in mode between p and the computer, slowed
+// down for slow mode.
+func versusBot(mode int, p block.Player, now time.Time, slow bool) (*Match, int) {
+	m := newMatch(mode, p, now)
+	if slow {
+		m.pace *= games.SLOWDOWN
+		m.inputs.Clock.Period = m.pace
+	}
+	m.sides = append(m.sides, &side{name: BOTNAME, bot: true})
+	m.countdown(now)
+	return m, 0
+}
+
+// countdown lays out both snakes facing each other from opposite sides.
+func (m *Match) countdown(now time.Time) {
+	m.phase = COUNTING
+	m.start = now.Add(COUNTDOWN)
+	m.next = m.start
+	m.inputs.Pending = nil
+	y := HEIGHT / 2
+	for i, s := range m.sides {
+		s.dir = grid.Right
+		x0, dx := STARTSIZE, -1
+		if i == 1 {
+			s.dir = grid.Left
+			x0, dx = WIDTH-1-STARTSIZE, 1
+		}
+		s.body = nil
+		for j := 0; j < STARTSIZE; j++ {
+			s.body = append(s.body, grid.Point{X: x0 + j*dx, Y: y})
+		}
+		s.turns = nil
+		s.alive = true
+	}
+	if m.mode == SNAKE {
+		m.food = grid.Point{X: WIDTH / 2, Y: y}
+	}
+	m.supervise(now)
+}
+
+// supervise puts the match under a supervisor, which takes its first
+// snapshot of the board just laid out.
+func (m *Match) supervise(now time.Time) {
+	var names []string
+	for _, s := range m.sides {
+		names = append(names, s.name)
+	}
+	sup, err := supervisor.New("snake duel "+strings.Join(names, " vs "), m, supervisor.Options{
+		Notify: func(h supervisor.Hiccup) { m.hiccup, m.until = h.String(), time.Now().Add(HICCUP) },
+	})
+	if err != nil {
+		log.Warn("Could not supervise match", "err", err)
+		return
+	}
+	m.sup = sup
+}
+
+// drop removes players that went quiet while waiting, and makes them
+// forfeit once the match is on.
+func (m *Match) drop(now time.Time) {
+	if m.phase == WAITING {
+		m.sides = slices.DeleteFunc(m.sides, func(s *side) bool { return m.quiet(s, now) })
+		return
+	}
+	if m.phase == OVER {
+		return
+	}
+	for _, s := range m.sides {
+		if m.quiet(s, now) {
+			s.alive = false
+		}
+	}
+	m.settle()
+}
+
+func (m *Match) quiet(s *side, now time.Time) bool {
+	return !s.bot && now.Sub(s.seen) > STALE
+}
+
+// tick plays the moves due by now under the supervisor, calling the match
+// a draw once it gives up on the room.
+func (m *Match) tick(now time.Time) {
+	if m.sup == nil {
+		m.advance(now)
+		return
+	}
+	if err := m.sup.Step(now); err != nil {
+		log.Error("Match given up", "err", err)
+		m.sup = nil
+		m.phase, m.winner = OVER, DRAW
+	}
+}
+
+// advance plays every move due by now.
+func (m *Match) advance(now time.Time) {
+	m.drop(now)
+	if m.phase == COUNTING && !now.Before(m.start) {
+		m.phase = PLAYING
+	}
+	for m.phase == PLAYING && !now.Before(m.next) {
+		m.step()
+		m.next = m.next.Add(m.pace)
+	}
+}
+
+func (m *Match) occupied(p grid.Point) bool {
+	for _, s := range m.sides {
+		if slices.Contains(s.body, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// step moves both snakes at once. A snake dies running into a wall or any
+// body, and both die when their heads meet. Light cycles grow every step.
+func (m *Match) step() {
+	m.inputs.Clock.Advance()
+	for _, in := range m.inputs.Due() {
+		i, _ := strconv.Atoi(in.Player)
+		d, _ := strconv.Atoi(in.Value)
+		m.queue(m.sides[i], grid.Dirs4[d])
+	}
+	heads := make([]grid.Point, len(m.sides))
+	ate := make([]bool, len(m.sides))
+	for i, s := range m.sides {
+		if s.bot {
+			m.steer(s)
+		}
+		if len(s.turns) > 0 {
+			s.dir, s.turns = s.turns[0], s.turns[1:]
+		}
+		heads[i] = s.body[0].Add(s.dir)
+		ate[i] = m.mode == TRON || heads[i] == m.food
+	}
+	// Tails move out of the way first, unless their snake is growing.
+	for i, s := range m.sides {
+		if !ate[i] {
+			s.body = s.body[:len(s.body)-1]
+		}
+	}
+	for i, s := range m.sides {
+		h := heads[i]
+		if h.X < 0 || h.Y < 0 || h.X >= WIDTH || h.Y >= HEIGHT || m.occupied(h) {
+			s.alive = false
+		}
+		for j := range m.sides {
+			if j != i && heads[j] == h {
+				s.alive = false
+			}
+		}
+	}
+	for i, s := range m.sides {
+		s.body = append([]grid.Point{heads[i]}, s.body...)
+	}
+	if m.mode == SNAKE && slices.Contains(ate, true) {
+		m.placeFood()
+	}
+	m.settle()
+}
+
+// settle ends the match once a snake is down.
+func (m *Match) settle() {
+	alive := -1
+	for i, s := range m.sides {
+		if !s.alive {
+			continue
+		}
+		if alive >= 0 {
+			return
+		}
+		alive = i
+	}
+	m.phase = OVER
+	m.winner = alive
+	if alive < 0 {
+		m.winner = DRAW
+	}
+}
+
+func (m *Match) placeFood() {
+	for {
+		p := grid.Point{X: m.rng.Intn(WIDTH), Y: m.rng.Intn(HEIGHT)}
+		if !m.occupied(p) {
+			m.food = p
+			return
+		}
+	}
+}
+
+// turn has side i change direction, once the input buffer lets the press
+// land. The moves due by now are played first, so it's scheduled from the
+// move it arrived on.
+func (m *Match) turn(i int, dir grid.Point, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tick(now)
+	if m.phase == OVER || i >= len(m.sides) {
+		return
+	}
+	m.inputs.Submit(strconv.Itoa(i), strconv.Itoa(slices.Index(grid.Dirs4, dir)))
+}
+
+// queue adds a change of direction to the turns s makes on its next moves,
+// ignoring reversals.
+func (m *Match) queue(s *side, dir grid.Point) {
+	last := s.dir
+	if len(s.turns) > 0 {
+		last = s.turns[len(s.turns)-1]
+	}
+	if len(s.turns) >= TURNS || dir == last || dir.Add(last) == (grid.Point{}) {
+		return
+	}
+	s.turns = append(s.turns, dir)
+}
+
+// measure records side i's round trip, for the input buffer to make up
+// for.
+func (m *Match) measure(i int, rtt time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < len(m.sides) && !m.sides[i].bot {
+		m.inputs.SetLatency(strconv.Itoa(i), rtt)
+	}
+}
+
+// opponents are the players facing side i, the computer left out.
+func (m *Match) opponents(i int) []block.Player {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ps []block.Player
+	for j, s := range m.sides {
+		if !s.bot && j != i {
+			ps = append(ps, s.seat)
+		}
+	}
+	return ps
+}
+
+// leave forfeits side i.
+func (m *Match) leave(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < len(m.sides) {
+		m.sides[i].seen = time.Time{}
+		m.inputs.Leave(strconv.Itoa(i))
+	}
+	m.drop(time.Now())
+}
+
+// Snake is one side of the board as sessions render it.
+type Snake struct {
+	Name  string
+	Body  []grid.Point
+	Alive bool
+}
+
+type Snapshot struct {
+	Mode   int
+	Phase  int
+	Start  time.Time
+	Food   grid.Point
+	Snakes []Snake
+	Winner int
+	Hiccup string // set for a while after the match was rewound
+}
+
+// poll marks side i as present, plays the moves due and describes the board.
+func (m *Match) poll(i int, now time.Time) (Snapshot, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i >= len(m.sides) {
+		return Snapshot{}, false
+	}
+	m.sides[i].seen = now
+	m.tick(now)
+
+	s := Snapshot{Mode: m.mode, Phase: m.phase, Start: m.start, Food: m.food, Winner: m.winner}
+	if now.Before(m.until) {
+		s.Hiccup = m.hiccup
+	}
+	for _, sd := range m.sides {
+		s.Snakes = append(s.Snakes, Snake{Name: sd.name, Body: slices.Clone(sd.body), Alive: sd.alive})
+	}
+	return s, true
+}
+
+// saved is the part of a match its supervisor rewinds: the board and the
+// inputs on their way to it, not who sits at it or when they were last
+// heard from.
+type saved struct {
+	Phase       int
+	Food        grid.Point
+	Start, Next time.Time
+	Winner      int
+	Inputs      *timesync.Buffer
+	Sides       []savedSide
+}
+
+type savedSide struct {
+	Body  []grid.Point
+	Dir   grid.Point
+	Turns []grid.Point
+	Alive bool
+}
+
+// Tick, Snapshot and Restore make the match a supervisor.Loop. The
+// supervisor only calls them from tick, under the match's lock.
+func (m *Match) Tick(now time.Time) {
+	m.advance(now)
+}
+
+func (m *Match) Snapshot() ([]byte, error) {
+	s := saved{Phase: m.phase, Food: m.food, Start: m.start, Next: m.next, Winner: m.winner, Inputs: m.inputs}
+	for _, sd := range m.sides {
+		s.Sides = append(s.Sides, savedSide{Body: sd.body, Dir: sd.dir, Turns: sd.turns, Alive: sd.alive})
+	}
+	return json.Marshal(s)
+}
+
+func (m *Match) Restore(snapshot []byte) error {
+	var s saved
+	if err := json.Unmarshal(snapshot, &s); err != nil {
+		return err
+	}
+	if len(s.Sides) != len(m.sides) || s.Inputs == nil {
+		return fmt.Errorf("snapshot of %d sides for %d", len(s.Sides), len(m.sides))
+	}
+	m.phase, m.food, m.start, m.next, m.winner, m.inputs = s.Phase, s.Food, s.Start, s.Next, s.Winner, s.Inputs
+	for i, sd := range s.Sides {
+		m.sides[i].body, m.sides[i].dir, m.sides[i].turns, m.sides[i].alive = sd.Body, sd.Dir, sd.Turns, sd.Alive
+	}
+	return nil
+}
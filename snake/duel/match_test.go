@@ -0,0 +1,33 @@
+package duel
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+func TestPanicRewindsBoard(t *testing.T) {
+	// Start the countdown a countdown ago, so the match plays as of now.
+	m, side := versusBot(SNAKE, block.Player{Name: "alice"}, time.Now().Add(-COUNTDOWN), false)
+	body := slices.Clone(m.sides[side].body)
+
+	// A snake without a body can't move, so the first step panics.
+	m.sides[side].body = nil
+	snap, ok := m.poll(side, time.Now())
+	if !ok {
+		t.Fatal("the panic took the match down")
+	}
+	if !slices.Equal(m.sides[side].body, body) {
+		t.Errorf("body = %v after the rewind, want the snapshot's %v", m.sides[side].body, body)
+	}
+	if snap.Hiccup == "" {
+		t.Error("players weren't told the match was rewound")
+	}
+
+	snap, _ = m.poll(side, time.Now())
+	if snap.Phase != PLAYING || snap.Snakes[side].Body[0] == body[0] {
+		t.Errorf("phase %d, head at %v: the match didn't play on from the snapshot", snap.Phase, snap.Snakes[side].Body[0])
+	}
+}
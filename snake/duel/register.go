@@ -0,0 +1,64 @@
+package duel
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	GAMENAME = "snake-duel"
+	TRONNAME = "tron"
+)
+
+// infos describes the game of each mode.
+var infos = [MODES]games.Info{
+	SNAKE: {
+		ID:          GAMENAME,
+		Title:       "Snake Duel",
+		Description: "Two snakes, one board: outlast your opponent",
+		Category:    games.MULTIPLAYER,
+		MinPlayers:  1,
+		MaxPlayers:  2,
+		Spectating:  true,
+		Rooms:       true,
+		Session:     2 * time.Minute,
+	},
+	TRON: {
+		ID:          TRONNAME,
+		Title:       "Light Cycles",
+		Description: "Tron on the snake board: trails never fade, the last one riding wins",
+		Category:    games.MULTIPLAYER,
+		MinPlayers:  1,
+		MaxPlayers:  2,
+		Spectating:  true,
+		Rooms:       true,
+		Session:     2 * time.Minute,
+	},
+}
+
+func init() {
+	for mode, info := range infos {
+		games.Register(info, func(env games.Env) (games.Game, error) {
+			m := NewModel(env.Width, env.Height, env.Renderer, env.Seat(), mode)
+			m.SetContext(env.Ctx)
+			m.SetLayout(ui.LayoutFromEnv(env.Environ))
+			m.Bell = env.Bell
+			m.Latency = env.Latency
+			m.SetSlow(env.Slow)
+			if env.Room != "" {
+				m.SetRoom(env.Room)
+			}
+			return m, nil
+		})
+	}
+}
+
+func (m Model) Name() string {
+	return infos[m.mode].Title
+}
+
+func (m Model) Description() string {
+	return infos[m.mode].Description
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const LEVELFOOD = 10 // food to eat before the campaign moves to the next level
+
+// Biome is a board theme: its palette, the texture sprinkled over empty
+// cells and an optional gameplay modifier.
+type Biome struct {
+	Name         string
+	SnakeColor   lipgloss.Color
+	Texture      string // two columns wide; empty means a plain board
+	TextureColor lipgloss.Color
+	// Slippery boards make every turn take effect one move late.
+	Slippery bool
+}
+
+var (
+	classicBiome = Biome{Name: "Classic"}
+
+	// campaignBiomes rotate once per campaign level.
+	campaignBiomes = []Biome{
+		{Name: "Grass", SnakeColor: "10", Texture: "ʷ ", TextureColor: "22"},
+		{Name: "Desert", SnakeColor: "130", Texture: "∙ ", TextureColor: "180"},
+		{Name: "Ice", SnakeColor: "39", Texture: "* ", TextureColor: "153", Slippery: true},
+	}
+)
+
+func (m Model) biome() Biome {
+	if m.gameMode != CAMPAIGN {
+		return classicBiome
+	}
+	return campaignBiomes[m.level%len(campaignBiomes)]
+}
+
+// toggleCampaign switches campaign mode and starts a fresh run.
+func (m *Model) toggleCampaign() {
+	if m.gameMode == CAMPAIGN {
+		m.gameMode = CLASSIC
+	} else {
+		m.gameMode = CAMPAIGN
+	}
+	m.RestartGame()
+}
+
+// checkLevelUp advances the campaign every LEVELFOOD points. Multiplied
+// food can jump past a multiple, so the level follows the score.
+func (m *Model) checkLevelUp() {
+	if m.gameMode != CAMPAIGN || m.score/LEVELFOOD <= m.level {
+		return
+	}
+	m.level = m.score / LEVELFOOD
+	m.slide = -1
+	m.updateWalls()
+	m.effects = append(m.effects, effect{
+		pos:  m.snake[0],
+		text: fmt.Sprintf("Level %d: %s, %s", m.level+1, m.biome().Name, m.stage().Name),
+		ttl:  POPUPTTL * 2,
+	})
+}
+
+func (m Model) snakeStyle() lipgloss.Style {
+	if c := m.biome().SnakeColor; c != "" {
+		return m.SnakeStyle.Foreground(c)
+	}
+	return m.SnakeStyle
+}
+
+// emptyCell renders an empty board cell, sprinkling the biome's texture
+// over a fixed scattering of cells.
+func (m Model) emptyCell(x, y int) string {
+	b := m.biome()
+	if b.Texture == "" || (x*7+y*13)%5 != 0 {
+		return m.GameBoardStyle.Render()
+	}
+	return m.SnakeStyle.Foreground(b.TextureColor).Render(b.Texture)
+}
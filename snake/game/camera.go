@@ -0,0 +1,103 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	CAMERAMARGIN = 4  // cells kept between the head and the edge of the viewport
+	MINIMAPCELLS = 12 // target size of the minimap's longest side, in characters
+
+	hudRows    = 6 // score line, board border, spacing and help lines
+	borderCols = 2
+)
+
+// viewportSize returns how many board cells fit on screen and whether the
+// board has to scroll because it's larger than the terminal.
+func (m Model) viewportSize() (cols, rows int, scrolling bool) {
+	cols, rows = m.boardWidth, m.boardHeight
+	if m.Width <= 0 || m.Height <= 0 {
+		return cols, rows, false
+	}
+
+	availRows := m.Height - hudRows
+	availCols := (m.Width - borderCols) / 2
+	if availRows >= rows && availCols >= cols {
+		return cols, rows, false
+	}
+
+	mmW, _ := m.minimapSize()
+	availCols = (m.Width - borderCols - (mmW + borderCols + 1)) / 2
+
+	return max(1, min(cols, availCols)), max(1, min(rows, availRows)), true
+}
+
+// updateCamera fits the viewport to the terminal and scrolls it so the head
+// stays at least CAMERAMARGIN cells away from its edges.
+func (m *Model) updateCamera() {
+	cols, rows, _ := m.viewportSize()
+	m.camera.Resize(cols, rows)
+
+	head := m.snake[0]
+	m.camera.Follow(head.X, head.Y)
+}
+
+func (m Model) minimapScale() int {
+	longest := max(m.boardWidth, m.boardHeight)
+	return max(2, (longest+MINIMAPCELLS-1)/MINIMAPCELLS)
+}
+
+func (m Model) minimapSize() (w, h int) {
+	scale := m.minimapScale()
+	return (m.boardWidth + scale - 1) / scale, (m.boardHeight + scale - 1) / scale
+}
+
+// minimap renders a scaled-down view of the whole board with the head, the
+// food and the portion currently on screen.
+func (m Model) minimap() string {
+	scale := m.minimapScale()
+	w, h := m.minimapSize()
+
+	cells := make([][]rune, h)
+	for y := range cells {
+		cells[y] = make([]rune, w)
+		for x := range cells[y] {
+			cells[y][x] = ' '
+		}
+	}
+	for y := m.camera.Y; y < m.camera.Y+m.camera.Height; y++ {
+		for x := m.camera.X; x < m.camera.X+m.camera.Width; x++ {
+			cells[y/scale][x/scale] = '·'
+		}
+	}
+	for _, pos := range m.snake[1:] {
+		cells[pos.Y/scale][pos.X/scale] = '▪'
+	}
+	for _, f := range m.foods {
+		cells[f.pos.Y/scale][f.pos.X/scale] = '●'
+	}
+
+	head := m.snake[0]
+	var s strings.Builder
+	for y, row := range cells {
+		if y > 0 {
+			s.WriteString("\n")
+		}
+		for x, cell := range row {
+			switch {
+			case head.X/scale == x && head.Y/scale == y:
+				s.WriteString(m.SnakeStyle.Render("@"))
+			case cell == '●':
+				s.WriteString(m.FoodStyle.Render(string(cell)))
+			case cell == '▪':
+				s.WriteString(m.SnakeStyle.Render(string(cell)))
+			default:
+				s.WriteString(m.QuitStyle.Render(string(cell)))
+			}
+		}
+	}
+
+	return lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).Render(s.String())
+}
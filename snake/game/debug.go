@@ -0,0 +1,35 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pendingDirections peeks at the direction buffer. The model is only touched
+// from the program's update loop, so draining and refilling is safe.
+func (m Model) pendingDirections() []string {
+	var pending []string
+	for range len(m.dirChan) {
+		dir := <-m.dirChan
+		pending = append(pending, dirNames[dir])
+		m.dirChan <- dir
+	}
+	return pending
+}
+
+func (m Model) debugPanel() string {
+	lines := m.debug.Lines()
+	lines = append(lines,
+		fmt.Sprintf("move every  %d ticks", m.moveSpeed),
+		fmt.Sprintf("direction   %s", dirNames[m.direction]),
+		fmt.Sprintf("buffer      %d/%d [%s]", len(m.dirChan), cap(m.dirChan), strings.Join(m.pendingDirections(), " ")),
+		fmt.Sprintf("camera      %d,%d %dx%d", m.camera.X, m.camera.Y, m.camera.Width, m.camera.Height),
+		fmt.Sprintf("frame cache %d hits %d misses", m.frames.Hits, m.frames.Misses),
+	)
+
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		Render(m.QuitStyle.Render(strings.Join(lines, "\n")))
+}
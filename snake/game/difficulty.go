@@ -0,0 +1,86 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Difficulty is a preset picked before the first run: how fast the snake
+// starts, how fast it can get, how quickly it gets there and the board it
+// plays on.
+type Difficulty struct {
+	Name         string
+	InitialSpeed int
+	TopSpeed     int
+	Ramp         float64
+	Board        BoardSize
+}
+
+var difficulties = []Difficulty{
+	{Name: "Easy", InitialSpeed: 12, TopSpeed: 5, Ramp: 0.5, Board: boardSizes[2]},
+	{Name: "Normal", InitialSpeed: INITIALSPEED, TopSpeed: TOPSPEED, Ramp: RAMP, Board: boardSizes[1]},
+	{Name: "Hard", InitialSpeed: 6, TopSpeed: 2, Ramp: 1.5, Board: boardSizes[1]},
+	{Name: "Insane", InitialSpeed: 4, TopSpeed: MINMOVESPAN, Ramp: 2, Board: boardSizes[0]},
+}
+
+// timing applies the difficulty to a mode's timing, keeping its tick.
+func (d Difficulty) timing(t Timing) Timing {
+	t.InitialSpeed = d.InitialSpeed
+	t.TopSpeed = d.TopSpeed
+	t.Ramp = d.Ramp
+	return t.Clamp()
+}
+
+// SetDifficulty retimes every mode and starts a fresh run on the
+// difficulty's board.
+func (m *Model) SetDifficulty(d Difficulty) {
+	for mode, t := range m.timings {
+		m.timings[mode] = d.timing(t)
+	}
+	m.SetBoardSize(d.Board.Width, d.Board.Height)
+}
+
+// difficulty names the preset the current timing matches, if any.
+func (m Model) difficulty() (Difficulty, bool) {
+	t := m.timing()
+	for _, d := range difficulties {
+		if d.timing(t) == t {
+			return d, true
+		}
+	}
+	return Difficulty{}, false
+}
+
+func (m *Model) updateDifficulties(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.Keys.Close):
+		m.choosingDifficulty = false
+	case key.Matches(msg, m.Keys.Up):
+		m.difficultyCursor = (m.difficultyCursor + len(difficulties) - 1) % len(difficulties)
+	case key.Matches(msg, m.Keys.Down):
+		m.difficultyCursor = (m.difficultyCursor + 1) % len(difficulties)
+	case key.Matches(msg, m.Keys.Select):
+		m.choosingDifficulty = false
+		m.SetDifficulty(difficulties[m.difficultyCursor])
+	}
+}
+
+func (m Model) difficultiesView() string {
+	var s strings.Builder
+	s.WriteString("Difficulty\n\n")
+	for i, d := range difficulties {
+		cursor := "  "
+		if i == m.difficultyCursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&s, "%s%-8s %2d→%d ticks/move  ramp x%.1f  %dx%d\n",
+			cursor, d.Name, d.InitialSpeed, d.TopSpeed, d.Ramp, d.Board.Width, d.Board.Height)
+	}
+	fmt.Fprintf(&s, "\n↑/↓ select • enter play • esc keep current • '%s' board size", m.Keys.Size.Help().Key)
+
+	return m.GameOverStyle.Foreground(lipgloss.Color("15")).Align(lipgloss.Left).Render(s.String())
+}
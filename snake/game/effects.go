@@ -0,0 +1,72 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	POPUPTTL  = 40 // ticks a score popup stays on screen
+	POPUPRISE = 3  // cells a score popup climbs over its lifetime
+)
+
+// popupFade is the color ramp a popup walks through as it ages, bright to dim.
+var popupFade = []lipgloss.Color{"15", "229", "228", "186", "144", "102", "59"}
+
+// effect is a transient overlay drawn on top of the board for a few frames.
+type effect struct {
+	pos  Position
+	text string
+	age  int
+	ttl  int
+}
+
+type overlayCell struct {
+	text  string
+	style lipgloss.Style
+}
+
+func newPopup(pos Position, points int) effect {
+	return effect{pos: pos, text: fmt.Sprintf("+%d", points), ttl: POPUPTTL}
+}
+
+// advanceEffects ages every effect by one tick and drops the expired ones.
+func (m *Model) advanceEffects() {
+	alive := m.effects[:0]
+	for _, fx := range m.effects {
+		fx.age++
+		if fx.age < fx.ttl {
+			alive = append(alive, fx)
+		}
+	}
+	m.effects = alive
+}
+
+// overlay lays the active effects out on board cells. Text is split into
+// two-character chunks so it lines up with the double-width cells.
+func (m Model) overlay() map[Position]overlayCell {
+	cells := make(map[Position]overlayCell)
+	for _, fx := range m.effects {
+		y := fx.pos.Y - fx.age*POPUPRISE/fx.ttl
+		if y < 0 || y >= m.boardHeight {
+			continue
+		}
+
+		shade := popupFade[fx.age*len(popupFade)/fx.ttl]
+		style := m.FoodStyle.Foreground(shade)
+
+		text := fx.text
+		if len(text)%2 != 0 {
+			text += " "
+		}
+		for i := 0; i < len(text); i += 2 {
+			x := fx.pos.X + i/2
+			if x >= m.boardWidth {
+				break
+			}
+			cells[Position{X: x, Y: y}] = overlayCell{text: text[i : i+2], style: style}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,91 @@
+package game
+
+const (
+	FOODCOUNT    = 3  // regular food on the board at once
+	GOLDENODDS   = 12 // one in this many meals drops a golden apple, if none is out
+	GOLDENPOINTS = 5
+	GOLDENTTL    = 400 // ticks a golden apple waits before it despawns
+	GOLDENGLYPH  = "🌟"
+)
+
+// food is an item on the board. Regular food stays until eaten and is
+// replaced right away; golden apples are worth more but despawn.
+type food struct {
+	pos    Position
+	points int
+	golden bool
+	ttl    int // ticks left before it despawns, for golden apples
+}
+
+// resetFood lays out a fresh board's food, the first item right in front of
+// the snake.
+func (m *Model) resetFood(first Position) {
+	m.foods = []food{{pos: first, points: 1}}
+	for len(m.foods) < FOODCOUNT {
+		m.foods = append(m.foods, food{pos: m.newFoodPosition(), points: 1})
+	}
+}
+
+// foodAt returns the index of the food at pos, or -1.
+func (m Model) foodAt(pos Position) int {
+	for i, f := range m.foods {
+		if f.pos == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+// eat removes the i-th food, replacing regular food and sometimes dropping a
+// golden apple with it.
+func (m *Model) eat(i int) food {
+	f := m.foods[i]
+	m.foods = append(m.foods[:i:i], m.foods[i+1:]...)
+	if f.golden {
+		return f
+	}
+	m.foods = append(m.foods, food{pos: m.newFoodPosition(), points: 1})
+	if !m.hasGolden() && m.rng.Intn(GOLDENODDS) == 0 {
+		m.foods = append(m.foods, food{pos: m.newFoodPosition(), points: GOLDENPOINTS, golden: true, ttl: GOLDENTTL})
+	}
+	return f
+}
+
+func (m Model) hasGolden() bool {
+	for _, f := range m.foods {
+		if f.golden {
+			return true
+		}
+	}
+	return false
+}
+
+// advanceFood runs every tick, despawning golden apples whose time ran out.
+func (m *Model) advanceFood() {
+	kept := m.foods[:0]
+	for _, f := range m.foods {
+		if f.golden {
+			if f.ttl--; f.ttl <= 0 {
+				continue
+			}
+		}
+		kept = append(kept, f)
+	}
+	m.foods = kept
+}
+
+// nearestFood is the food closest to the head, which hints lead to.
+func (m Model) nearestFood() Position {
+	head := m.snake[0]
+	best := m.foods[0].pos
+	for _, f := range m.foods[1:] {
+		if dist(f.pos, head) < dist(best, head) {
+			best = f.pos
+		}
+	}
+	return best
+}
+
+func dist(a, b Position) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Pause      key.Binding
+	Restart    key.Binding
+	Replay     key.Binding
+	Practice   key.Binding
+	Campaign   key.Binding
+	Size       key.Binding
+	Difficulty key.Binding
+	Select     key.Binding
+	Scores     key.Binding
+	Hint       key.Binding
+	Hold       key.Binding
+	Options    key.Binding
+	Close      key.Binding
+	Layout     key.Binding
+	Debug      key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys:   ui.MoveKeysFor(l),
+		Pause:      key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "pause")),
+		Restart:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restart")),
+		Replay:     key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "replay seed")),
+		Practice:   key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "practice mode")),
+		Campaign:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "campaign mode")),
+		Size:       key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "board size")),
+		Difficulty: key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "difficulty")),
+		Select:     key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "select")),
+		Scores:     key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "leaderboard")),
+		Hint:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "hint (practice)")),
+		Hold:       key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "hold-to-steer")),
+		Options:    key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "options")),
+		Close:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close")),
+		Layout:     ui.LayoutKey(),
+		Debug:      ui.DebugKey(),
+		Help:       ui.HelpKey(),
+		Quit:       ui.QuitKeyFor(l),
+		layout:     l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Pause, k.Restart, k.Options, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Pause, k.Restart, k.Replay, k.Practice, k.Campaign, k.Hint},
+		{k.Size, k.Difficulty, k.Scores, k.Hold, k.Options, k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":         &k.Up,
+		"down":       &k.Down,
+		"left":       &k.Left,
+		"right":      &k.Right,
+		"pause":      &k.Pause,
+		"restart":    &k.Restart,
+		"replay":     &k.Replay,
+		"practice":   &k.Practice,
+		"campaign":   &k.Campaign,
+		"size":       &k.Size,
+		"difficulty": &k.Difficulty,
+		"select":     &k.Select,
+		"scores":     &k.Scores,
+		"hint":       &k.Hint,
+		"hold":       &k.Hold,
+		"options":    &k.Options,
+		"layout":     &k.Layout,
+		"debug":      &k.Debug,
+		"help":       &k.Help,
+		"quit":       &k.Quit,
+	}
+}
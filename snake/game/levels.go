@@ -0,0 +1,104 @@
+package game
+
+import "github.com/charmbracelet/lipgloss"
+
+const WALLCLEARANCE = 2 // cells around the head kept free when walls appear
+
+// Level is a campaign stage's wall layout. Layouts are drawn relative to the
+// board so they fit every size.
+type Level struct {
+	Name  string
+	Walls func(w, h int) []Position
+}
+
+// levels unlock in order as the campaign score grows; the last one stays
+// for the rest of the run.
+var levels = []Level{
+	{Name: "Open"},
+	{Name: "Pillars", Walls: pillars},
+	{Name: "Corridors", Walls: corridors},
+	{Name: "Cross", Walls: cross},
+}
+
+func pillars(w, h int) []Position {
+	var walls []Position
+	for _, c := range []Position{{X: w / 4, Y: h / 4}, {X: 3 * w / 4, Y: h / 4}, {X: w / 4, Y: 3 * h / 4}, {X: 3 * w / 4, Y: 3 * h / 4}} {
+		walls = append(walls, c, Position{X: c.X - 1, Y: c.Y}, Position{X: c.X, Y: c.Y - 1}, Position{X: c.X - 1, Y: c.Y - 1})
+	}
+	return walls
+}
+
+func corridors(w, h int) []Position {
+	var walls []Position
+	for _, y := range []int{h / 3, 2 * h / 3} {
+		for x := w / 5; x < 4*w/5; x++ {
+			walls = append(walls, Position{X: x, Y: y})
+		}
+	}
+	return walls
+}
+
+// cross splits the board into quadrants joined by a gap at the center.
+func cross(w, h int) []Position {
+	var walls []Position
+	for y := h / 6; y < 5*h/6; y++ {
+		if abs(y-h/2) > 2 {
+			walls = append(walls, Position{X: w / 2, Y: y})
+		}
+	}
+	for x := w / 6; x < 5*w/6; x++ {
+		if abs(x-w/2) > 2 {
+			walls = append(walls, Position{X: x, Y: h / 2})
+		}
+	}
+	return walls
+}
+
+func abs(n int) int {
+	return max(n, -n)
+}
+
+func (m Model) stage() Level {
+	if m.gameMode != CAMPAIGN {
+		return levels[0]
+	}
+	return levels[min(m.level, len(levels)-1)]
+}
+
+// updateWalls lays out the current level's walls, leaving out any that
+// would land on the snake, the food or right next to the head.
+func (m *Model) updateWalls() {
+	m.obstacles = nil
+	l := m.stage()
+	if l.Walls == nil {
+		return
+	}
+
+	taken := make(map[Position]bool, len(m.snake)+1)
+	for _, p := range m.snake {
+		taken[p] = true
+	}
+	for _, f := range m.foods {
+		taken[f.pos] = true
+	}
+	head := m.snake[0]
+	for _, p := range l.Walls(m.boardWidth, m.boardHeight) {
+		if taken[p] || abs(p.X-head.X)+abs(p.Y-head.Y) <= WALLCLEARANCE {
+			continue
+		}
+		m.obstacles = append(m.obstacles, p)
+	}
+}
+
+func (m Model) wallStyle() lipgloss.Style {
+	return m.SnakeStyle.Foreground(lipgloss.Color("244"))
+}
+
+func (m Model) isWall(pos Position) bool {
+	for _, w := range m.obstacles {
+		if w == pos {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/games"
+)
+
+const (
+	MINTICK     = 8 * time.Millisecond
+	MAXTICK     = 100 * time.Millisecond
+	TICKSTEP    = 2 * time.Millisecond
+	MINMOVESPAN = 1  // fastest allowed ticks per move
+	MAXMOVESPAN = 30 // slowest allowed ticks per move
+	MINRAMP     = 0.25
+	MAXRAMP     = 4
+	RAMPSTEP    = 0.25
+
+	CLASSIC  = "classic"
+	PRACTICE = "practice"
+	CAMPAIGN = "campaign"
+)
+
+// Timing sets how fast a mode runs. Speeds are in ticks per move, so lower
+// is faster. Ramp scales how quickly the snake speeds up as it scores.
+type Timing struct {
+	Tick         time.Duration
+	InitialSpeed int
+	TopSpeed     int
+	Ramp         float64
+}
+
+func DefaultTiming() Timing {
+	return Timing{Tick: TICKDURATION, InitialSpeed: INITIALSPEED, TopSpeed: TOPSPEED, Ramp: RAMP}
+}
+
+// Clamp keeps a timing within bounds that stay playable and don't waste
+// server time on needlessly fine ticks.
+func (t Timing) Clamp() Timing {
+	t.Tick = max(MINTICK, min(t.Tick, MAXTICK))
+	t.InitialSpeed = max(MINMOVESPAN, min(t.InitialSpeed, MAXMOVESPAN))
+	t.TopSpeed = max(MINMOVESPAN, min(t.TopSpeed, t.InitialSpeed))
+	t.Ramp = max(MINRAMP, min(t.Ramp, MAXRAMP))
+	return t
+}
+
+// SetTiming overrides the timing of a mode (CLASSIC, PRACTICE or CAMPAIGN).
+func (m *Model) SetTiming(mode string, t Timing) {
+	m.timings[mode] = t.Clamp()
+	if mode == m.mode() {
+		m.updateSpeed()
+	}
+}
+
+func (m Model) mode() string {
+	return m.gameMode
+}
+
+func (m Model) timing() Timing {
+	if t, ok := m.timings[m.mode()]; ok {
+		return t
+	}
+	return DefaultTiming()
+}
+
+// SetSlow turns slow mode on or off, see games.Env.Slow.
+func (m *Model) SetSlow(on bool) {
+	m.slow = on
+}
+
+// tickDuration is how long a tick of the timing lasts, longer in slow mode.
+func (m Model) tickDuration() time.Duration {
+	if m.slow {
+		return m.timing().Tick * games.SLOWDOWN
+	}
+	return m.timing().Tick
+}
+
+var optionNames = []string{"Tick duration", "Starting speed", "Top speed", "Speed ramp", "Starting length", "Spawn", "Safe moves", "Seasonal events", "Theme", "Seed"}
+
+// SEEDOPTION is the index of the seed, typed rather than adjusted.
+const SEEDOPTION = 9
+
+func (m *Model) adjustOption(delta int) {
+	t := m.timing()
+	switch m.optionCursor {
+	case 0:
+		t.Tick += time.Duration(delta) * TICKSTEP
+	case 1:
+		t.InitialSpeed += delta
+	case 2:
+		t.TopSpeed += delta
+	case 3:
+		t.Ramp += float64(delta) * RAMPSTEP
+	case 4, 5, 6:
+		m.adjustStart(delta)
+		return
+	case 7:
+		m.SetSeasonal(!m.seasonal)
+		return
+	case 8:
+		m.cycleTheme(delta)
+		return
+	}
+	m.SetTiming(m.mode(), t)
+}
+
+// adjustStart changes the start option under the cursor, for the next run.
+func (m *Model) adjustStart(delta int) {
+	s := m.start()
+	switch m.optionCursor {
+	case 4:
+		s.Length += delta
+	case 5:
+		i := slices.Index(spawns, s.Spawn)
+		s.Spawn = spawns[(i+len(spawns)+delta)%len(spawns)]
+	case 6:
+		s.SafeMoves += delta
+	}
+	m.SetStart(m.mode(), s)
+}
+
+func (m *Model) updateOptions(msg tea.KeyMsg) {
+	// Digits are movement keys on the numpad, so the seed takes them first.
+	if m.optionCursor == SEEDOPTION && m.typeSeed(msg.String()) {
+		return
+	}
+	switch {
+	case key.Matches(msg, m.Keys.Options), key.Matches(msg, m.Keys.Close):
+		m.showOptions = false
+	case key.Matches(msg, m.Keys.Up):
+		m.optionCursor = (m.optionCursor + len(optionNames) - 1) % len(optionNames)
+	case key.Matches(msg, m.Keys.Down):
+		m.optionCursor = (m.optionCursor + 1) % len(optionNames)
+	case key.Matches(msg, m.Keys.Left):
+		m.adjustOption(-1)
+	case key.Matches(msg, m.Keys.Right):
+		m.adjustOption(1)
+	}
+}
+
+func (m Model) optionsView() string {
+	t, st := m.timing(), m.start()
+	values := []string{
+		t.Tick.String(),
+		fmt.Sprintf("%d ticks/move", t.InitialSpeed),
+		fmt.Sprintf("%d ticks/move", t.TopSpeed),
+		fmt.Sprintf("x%.2f", t.Ramp),
+		fmt.Sprintf("%d", st.Length),
+		st.Spawn,
+		fmt.Sprintf("%d moves", st.SafeMoves),
+		onOff(m.seasonal),
+		m.theme().Name,
+		m.seedInput,
+	}
+	if m.seedInput == "" {
+		values[SEEDOPTION] = "random"
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "Options (%s mode)\n\n", m.mode())
+	for i, name := range optionNames {
+		cursor := "  "
+		if i == m.optionCursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&s, "%s%-16s %14s\n", cursor, name, values[i])
+	}
+	s.WriteString("\nStarting options and the seed apply from the next run.\n")
+	if m.optionCursor == SEEDOPTION {
+		s.WriteString("type digits, backspace to clear • ↑/↓ select • esc close")
+	} else {
+		s.WriteString("←/→ adjust • ↑/↓ select • esc close")
+	}
+
+	return m.GameOverStyle.Foreground(lipgloss.Color("15")).Align(lipgloss.Left).Render(s.String())
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
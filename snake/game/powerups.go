@@ -0,0 +1,124 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	POWERUPODDS  = 120 // one in this many moves spawns a power-up, if none is out
+	POWERUPTTL   = 500 // ticks a power-up waits on the board to be picked up
+	POWERUPTIME  = 600 // ticks an effect lasts once picked up
+	SLOWDOWN     = 2   // slow-down multiplies the ticks between moves by this
+	MULTIPLIER   = 2   // points per food under the multiplier
+	SHRINKBY     = 3   // segments a shrink cuts off the tail
+	MINSNAKESIZE = 2
+)
+
+type powerKind int
+
+const (
+	SLOW powerKind = iota
+	MULTIPLY
+	SHRINK
+	PHASE
+	POWERUPS // number of kinds
+)
+
+// power describes a kind of power-up: how it's drawn and named in the status
+// line. Shrink applies at once; the others last POWERUPTIME ticks.
+type power struct {
+	Name  string
+	Glyph string // two columns wide
+	Color lipgloss.Color
+}
+
+var powers = [POWERUPS]power{
+	SLOW:     {Name: "slow", Glyph: "~~", Color: "39"},
+	MULTIPLY: {Name: fmt.Sprintf("x%d", MULTIPLIER), Glyph: fmt.Sprintf("x%d", MULTIPLIER), Color: "220"},
+	SHRINK:   {Name: "shrink", Glyph: "><", Color: "205"},
+	PHASE:    {Name: "phase", Glyph: "░░", Color: "147"},
+}
+
+// powerUp is a power-up waiting on the board.
+type powerUp struct {
+	kind powerKind
+	pos  Position
+	ttl  int
+}
+
+// advancePowerUps runs every tick: active effects and the power-up on the
+// board count down, and expire when they reach zero.
+func (m *Model) advancePowerUps() {
+	for k := range m.boosts {
+		m.boosts[k] = max(0, m.boosts[k]-1)
+	}
+	if m.powerUp != nil {
+		if m.powerUp.ttl--; m.powerUp.ttl <= 0 {
+			m.powerUp = nil
+		}
+	}
+}
+
+// spawnPowerUp occasionally drops a random power-up on a free cell.
+func (m *Model) spawnPowerUp() {
+	if m.powerUp != nil || m.rng.Intn(POWERUPODDS) != 0 {
+		return
+	}
+	m.powerUp = &powerUp{kind: powerKind(m.rng.Intn(int(POWERUPS))), pos: m.newFoodPosition(), ttl: POWERUPTTL}
+}
+
+// collectPowerUp applies the power-up under the new head, if any.
+func (m *Model) collectPowerUp(head Position) {
+	if m.powerUp == nil || m.powerUp.pos != head {
+		return
+	}
+	p := powers[m.powerUp.kind]
+	switch m.powerUp.kind {
+	case SHRINK:
+		m.snake = m.snake[:max(MINSNAKESIZE, len(m.snake)-SHRINKBY)]
+	default:
+		m.boosts[m.powerUp.kind] = POWERUPTIME
+	}
+	m.effects = append(m.effects, effect{pos: head, text: p.Name, ttl: POPUPTTL})
+	m.powerUp = nil
+}
+
+func (m Model) boosted(k powerKind) bool {
+	return m.boosts[k] > 0
+}
+
+// moveTicks is how many ticks pass between moves.
+func (m Model) moveTicks() int {
+	if m.boosted(SLOW) {
+		return m.moveSpeed * SLOWDOWN
+	}
+	return m.moveSpeed
+}
+
+// foodPoints is what eating f is worth.
+func (m Model) foodPoints(f food) int {
+	if m.boosted(MULTIPLY) {
+		return f.points * MULTIPLIER
+	}
+	return f.points
+}
+
+// boostStatus lists the active effects with the seconds they have left.
+func (m Model) boostStatus() string {
+	var active []string
+	for k, left := range m.boosts {
+		if left > 0 {
+			secs := (time.Duration(left) * m.tickDuration()).Round(time.Second)
+			active = append(active, fmt.Sprintf("%s %ds", powers[k].Name, int(secs.Seconds())))
+		}
+	}
+	return strings.Join(active, " ")
+}
+
+func (m Model) powerUpStyle(k powerKind) lipgloss.Style {
+	return m.FoodStyle.Foreground(powers[k].Color).Bold(true)
+}
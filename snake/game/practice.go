@@ -0,0 +1,62 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/hint"
+)
+
+// togglePractice switches practice mode and starts a fresh run, so practice
+// runs (which may use hints) are never mixed with regular ones.
+func (m *Model) togglePractice() {
+	if m.gameMode == PRACTICE {
+		m.gameMode = CLASSIC
+	} else {
+		m.gameMode = PRACTICE
+	}
+	m.RestartGame()
+}
+
+// requestHint marks a safe next cell towards the nearest food. Hints are only
+// available in practice mode and are counted per run.
+func (m *Model) requestHint() {
+	if m.gameMode != PRACTICE || m.gameOver || !m.hints.Take() {
+		return
+	}
+
+	occupied := grid.New[bool](m.boardWidth, m.boardHeight)
+	for _, pos := range m.snake[:len(m.snake)-1] {
+		occupied.Set(pos, true)
+	}
+
+	next, ok := hint.SafeMove(occupied, m.snake[0], m.nearestFood(), len(m.snake), func(_ grid.Point, body bool) bool {
+		return !body
+	})
+	if ok {
+		m.hintCell = &next
+	}
+}
+
+func (m Model) hintLine() string {
+	if m.gameMode != PRACTICE {
+		return fmt.Sprintf("Press '%s' for practice mode or '%s' for the campaign",
+			m.Keys.Practice.Help().Key, m.Keys.Campaign.Help().Key)
+	}
+
+	arrow := ""
+	if m.hintCell != nil {
+		switch m.hintCell.Sub(m.snake[0]) {
+		case grid.Up:
+			arrow = " ↑"
+		case grid.Down:
+			arrow = " ↓"
+		case grid.Left:
+			arrow = " ←"
+		case grid.Right:
+			arrow = " →"
+		}
+	}
+	return fmt.Sprintf("Practice mode | Press '%s' for a hint%s (used %d) | '%s' to leave",
+		m.Keys.Hint.Help().Key, arrow, m.hints.Used, m.Keys.Practice.Help().Key)
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Snake",
+	Description: "Eat, grow and don't bite yourself",
+	Category:    games.ARCADE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     5 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		return New(env), nil
+	})
+}
+
+// New builds a game for a player's connection, styled with its renderer.
+func New(env games.Env) *Model {
+	r := env.Renderer
+	bg := "light"
+	if r.HasDarkBackground() {
+		bg = "dark"
+	}
+
+	m := NewModel(
+		env.Term,
+		r.ColorProfile().Name(),
+		env.Width,
+		env.Height,
+		bg,
+		r.NewStyle().Foreground(lipgloss.Color("10")).BorderStyle(lipgloss.RoundedBorder()),
+		r.NewStyle().Foreground(lipgloss.Color("8")),
+		r.NewStyle().Foreground(lipgloss.Color("9")),
+		r.NewStyle().Foreground(lipgloss.Color("10")),
+		lipgloss.NewStyle().SetString("  "),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
+		lipgloss.
+			NewStyle().
+			Foreground(lipgloss.Color("#FF0000")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(3),
+	)
+	m.SetContext(env.Ctx)
+	m.SetLayout(ui.LayoutFromEnv(env.Environ))
+	m.Scores = env.Scores
+	m.Player = env.Player
+	m.Fingerprint = env.Fingerprint
+	m.Latency = env.Latency
+	m.Bell = env.Bell
+	m.SetSlow(env.Slow)
+	if env.Board.Width > 0 && env.Board.Height > 0 {
+		m.SetBoardSize(env.Board.Width, env.Board.Height)
+	}
+	if env.Tick > 0 {
+		for _, mode := range []string{CLASSIC, PRACTICE, CAMPAIGN} {
+			t := m.timings[mode]
+			t.Tick = env.Tick
+			m.SetTiming(mode, t)
+		}
+	}
+	if env.Profile != nil {
+		m.SetProfile(env.Profile, env.Profiles)
+	}
+	if env.Seed != 0 {
+		m.SetSeed(env.Seed)
+	}
+	return m
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,167 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+)
+
+const (
+	GAMENAME     = "snake"
+	TOPSCORES    = 5  // on the game over screen
+	GLOBALSCORES = 10 // on the leaderboard screen
+	// COMBINEDDEPTH is how many of the best runs across boards are looked
+	// through for the unassisted ones the combined board ranks.
+	COMBINEDDEPTH = 50
+	// PACESCORE is the score a timing's pace is averaged up to, see pace.
+	PACESCORE = 50
+)
+
+// boardKey names the board for the leaderboard. Runs are segmented by their
+// actual dimensions, so a fitted board competes with presets of its size.
+func (m Model) boardKey() string {
+	return fmt.Sprintf("%dx%d", m.boardWidth, m.boardHeight)
+}
+
+// modifiers lists what changes how a run plays beyond its mode and board.
+func (m Model) modifiers() string {
+	var mods []string
+	if m.holdMode {
+		mods = append(mods, "hold")
+	}
+	if m.slow {
+		mods = append(mods, leaderboard.ASSISTED)
+	}
+	if t := m.timing(); t != DefaultTiming() {
+		// A difficulty preset ranks on its own boards; anything else is
+		// custom, including an operator's tick.
+		if d, ok := m.difficulty(); ok && t.Tick == TICKDURATION {
+			mods = append(mods, strings.ToLower(d.Name))
+		} else {
+			mods = append(mods, "custom-speed")
+		}
+	}
+	if m.start() != DefaultStart() {
+		mods = append(mods, "custom-start")
+	}
+	if m.seeded {
+		mods = append(mods, "seeded")
+	}
+	return leaderboard.Modifiers(mods...)
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      m.gameMode,
+		Modifiers: m.modifiers(),
+		Board:     m.boardKey(),
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+// submitScore records a finished run and remembers the best runs on its
+// leaderboard and across every board this season.
+func (m *Model) submitScore() {
+	if m.Scores == nil {
+		return
+	}
+	k := m.scoreKey()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Score:       m.score,
+		Points:      m.points(),
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+	m.combined = combined(m.Scores, m.combinedFilter(k.Season))
+}
+
+// combinedFilter selects the runs of every board and speed that compete on
+// points with this one: those of its mode, or of classic for practice runs,
+// which don't compete.
+func (m Model) combinedFilter(season string) leaderboard.Filter {
+	mode := m.gameMode
+	if mode == PRACTICE {
+		mode = CLASSIC
+	}
+	return leaderboard.Filter{Game: GAMENAME, Mode: mode, Season: season}
+}
+
+// combined ranks the best unassisted runs matching f, which spans boards.
+// Slow mode runs rank on their own boards only.
+func combined(scores leaderboard.Store, f leaderboard.Filter) []leaderboard.Entry {
+	top := slices.DeleteFunc(scores.Top(f, COMBINEDDEPTH), func(e leaderboard.Entry) bool { return e.Assisted() })
+	return top[:min(TOPSCORES, len(top))]
+}
+
+// points normalizes the score against the medium board at default speed.
+// Speed compares the pace of the run's timing, from its first move to its
+// top speed, with the default one's.
+func (m Model) points() int {
+	ref := DefaultTiming()
+	speed := pace(ref, ref.Tick) / pace(m.timing(), m.tickDuration())
+	return leaderboard.Normalize(m.score, m.boardWidth*m.boardHeight, BOARDWIDTH*BOARDHEIGHT, speed)
+}
+
+// pace is how long a move of t takes on average, ticks lasting tick, while
+// the score grows to PACESCORE.
+func pace(t Timing, tick time.Duration) float64 {
+	ticks := 0
+	for score := range PACESCORE {
+		ticks += moveSpan(t, score)
+	}
+	return float64(tick) * float64(ticks) / PACESCORE
+}
+
+// topView lists the board's best runs, and the best runs of any size, for
+// the game over screen.
+func (m Model) topView() string {
+	if len(m.top) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "\nTop scores on %s\n", m.boardKey())
+	for i, e := range m.top {
+		fmt.Fprintf(&s, "%d. %-12s %6d\n", i+1, e.Player, e.Score)
+	}
+	fmt.Fprintf(&s, "\nYour points: %d\nTop points this season\n", m.points())
+	for i, e := range m.combined {
+		fmt.Fprintf(&s, "%d. %-12s %6d\n", i+1, e.Player, e.Points)
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+// openScores shows the leaderboard browser, starting at this run's board,
+// with the player's personal best.
+func (m *Model) openScores() {
+	if m.Scores == nil {
+		return
+	}
+	m.scores = leaderboard.NewBrowser(m.Scores, leaderboard.Filter(m.scoreKey()), GLOBALSCORES, m.Fingerprint)
+}
+
+func (m *Model) updateScores(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.Keys.Scores), key.Matches(msg, m.Keys.Close):
+		m.scores = nil
+	default:
+		m.scores.Update(msg, m.Keys.MoveKeys)
+	}
+}
+
+func (m Model) scoresView() string {
+	return m.GameOverStyle.Foreground(lipgloss.Color("15")).Align(lipgloss.Left).Render(m.scores.View())
+}
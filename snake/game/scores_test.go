@@ -0,0 +1,77 @@
+package game
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+)
+
+// run is a finished run of mode with t, without the rest of a session.
+func run(mode string, t Timing, score int) Model {
+	return Model{
+		gameMode:    mode,
+		timings:     map[string]Timing{mode: t},
+		boardWidth:  BOARDWIDTH,
+		boardHeight: BOARDHEIGHT,
+		score:       score,
+	}
+}
+
+func TestPointsSpeed(t *testing.T) {
+	ref := run(CLASSIC, DefaultTiming(), 40)
+	if got, want := ref.points(), 40*100; got != want {
+		t.Errorf("default run points = %d, want %d", got, want)
+	}
+
+	// The same start, reaching top speed sooner and a faster one.
+	quick := DefaultTiming()
+	quick.TopSpeed, quick.Ramp = MINMOVESPAN, MAXRAMP
+	if ramped := run(CLASSIC, quick, 40); ramped.points() <= ref.points() {
+		t.Errorf("faster ramp points = %d, want more than the default's %d", ramped.points(), ref.points())
+	}
+
+	// Difficulty presets differ in start, top speed and ramp: the same
+	// score is worth more on harder ones.
+	var last int
+	for i, d := range difficulties {
+		p := run(CLASSIC, d.timing(DefaultTiming()), 40).points()
+		if i > 0 && p <= last {
+			t.Errorf("%s points = %d, want more than the easier preset's %d", d.Name, p, last)
+		}
+		last = p
+	}
+}
+
+func TestCombined(t *testing.T) {
+	scores := leaderboard.NewMemoryStore(10)
+	season := leaderboard.SeasonOf(time.Now())
+	for _, e := range []leaderboard.Entry{
+		{Key: leaderboard.Key{Mode: CLASSIC, Modifiers: leaderboard.NOMODIFIERS, Board: "20x20"}, Player: "classic", Points: 100},
+		{Key: leaderboard.Key{Mode: CLASSIC, Modifiers: "hard", Board: "30x15"}, Player: "hard", Points: 200},
+		{Key: leaderboard.Key{Mode: CLASSIC, Modifiers: leaderboard.ASSISTED, Board: "20x20"}, Player: "assisted", Points: 900},
+		{Key: leaderboard.Key{Mode: PRACTICE, Modifiers: leaderboard.NOMODIFIERS, Board: "20x20"}, Player: "practice", Points: 800},
+		{Key: leaderboard.Key{Mode: CAMPAIGN, Modifiers: leaderboard.NOMODIFIERS, Board: "20x20"}, Player: "campaign", Points: 700},
+	} {
+		e.Game, e.Season = GAMENAME, season
+		if err := scores.Submit(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for mode, want := range map[string][]string{
+		CLASSIC:  {"hard", "classic"},
+		PRACTICE: {"hard", "classic"},
+		CAMPAIGN: {"campaign"},
+	} {
+		m := run(mode, DefaultTiming(), 0)
+		var got []string
+		for _, e := range combined(scores, m.combinedFilter(season)) {
+			got = append(got, e.Player)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s combined board %v, want %v", mode, got, want)
+		}
+	}
+}
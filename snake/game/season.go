@@ -0,0 +1,47 @@
+package game
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/season"
+)
+
+// SetSchedule replaces the seasonal events the game draws from.
+func (m *Model) SetSchedule(s season.Schedule) {
+	m.schedule = s
+	m.updateSeason()
+}
+
+// SetSeasonal lets a player opt out of seasonal cosmetics.
+func (m *Model) SetSeasonal(on bool) {
+	m.seasonal = on
+	m.updateSeason()
+}
+
+// updateSeason picks the event for the current date. It runs once per game
+// so a run never changes look halfway through.
+func (m *Model) updateSeason() {
+	m.event = season.Event{}
+	if m.seasonal {
+		m.event, _ = m.schedule.Active(time.Now())
+	}
+}
+
+func (m Model) foodGlyph() string {
+	if m.event.Food != "" {
+		return m.event.Food
+	}
+	return "🍎"
+}
+
+func (m Model) boardStyle() lipgloss.Style {
+	s := m.TxtStyle
+	if m.event.Border != nil {
+		s = s.BorderStyle(*m.event.Border)
+	}
+	if m.event.BorderColor != "" {
+		s = s.BorderForeground(m.event.BorderColor)
+	}
+	return s
+}
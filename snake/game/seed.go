@@ -0,0 +1,64 @@
+package game
+
+import (
+	"strconv"
+
+	"golang.org/x/exp/rand"
+)
+
+const (
+	SEEDS      = 1000000 // random seeds are below SEEDS, so they're quick to pass on
+	SEEDDIGITS = 9       // at most, typed on the options screen
+)
+
+// seedRun seeds the run about to start: again with the last run's seed
+// when replaying it, with the seed typed on the options screen if there's
+// one, or with a fresh random one. Friends racing a seed get the same food
+// and power-ups as long as they eat them in the same order.
+func (m *Model) seedRun() {
+	switch {
+	case m.replay:
+		m.replay, m.seeded = false, true
+	case m.seedInput != "":
+		m.seed, _ = strconv.ParseUint(m.seedInput, 10, 64)
+		m.seeded = true
+	default:
+		m.seed, m.seeded = uint64(rand.Intn(SEEDS)), false
+	}
+	m.rng = rand.New(rand.NewSource(m.seed))
+}
+
+// SetSeed has every run play seed, as if typed on the options screen but
+// for good: events give every entrant the same one. It's cut below SEEDS,
+// so players can type it again later.
+func (m *Model) SetSeed(seed uint64) {
+	m.seedInput = strconv.FormatUint(seed%SEEDS, 10)
+	m.seedFixed = true
+	m.RestartGame()
+}
+
+// ReplaySeed starts the run over on the seed it had.
+func (m *Model) ReplaySeed() {
+	m.replay = true
+	m.RestartGame()
+}
+
+// typeSeed edits the seed on the options screen: digits add to it and
+// backspace takes the last one off. It reports whether the key was one of
+// those.
+func (m *Model) typeSeed(key string) bool {
+	switch {
+	case m.seedFixed && (key == "backspace" || len(key) == 1 && key[0] >= '0' && key[0] <= '9'):
+	case key == "backspace":
+		if n := len(m.seedInput); n > 0 {
+			m.seedInput = m.seedInput[:n-1]
+		}
+	case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+		if len(m.seedInput) < SEEDDIGITS {
+			m.seedInput += key
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	MINBOARD = 12 // smallest side that still fits the starting snake and food
+)
+
+// BoardSize is a board the player can pick before a run. A zero size fits
+// the board to the terminal.
+type BoardSize struct {
+	Name          string
+	Width, Height int
+}
+
+var boardSizes = []BoardSize{
+	{Name: "Small", Width: 16, Height: 16},
+	{Name: "Medium", Width: BOARDWIDTH, Height: BOARDHEIGHT},
+	{Name: "Large", Width: 48, Height: 48},
+	{Name: "Fit to terminal"},
+}
+
+// dimensions resolves a size against the terminal.
+func (m Model) dimensions(s BoardSize) (w, h int) {
+	if s.Width > 0 {
+		return s.Width, s.Height
+	}
+	if m.Width <= 0 || m.Height <= 0 {
+		return BOARDWIDTH, BOARDHEIGHT
+	}
+	return max(MINBOARD, (m.Width-borderCols)/2), max(MINBOARD, m.Height-hudRows)
+}
+
+// SetBoardSize resizes the board and starts a fresh run on it.
+func (m *Model) SetBoardSize(w, h int) {
+	m.boardWidth = max(MINBOARD, w)
+	m.boardHeight = max(MINBOARD, h)
+	m.camera = ui.NewViewport(m.boardWidth, m.boardHeight, CAMERAMARGIN)
+	m.RestartGame()
+}
+
+func (m *Model) updateSizes(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.Keys.Close):
+		m.choosingSize = false
+	case key.Matches(msg, m.Keys.Up):
+		m.sizeCursor = (m.sizeCursor + len(boardSizes) - 1) % len(boardSizes)
+	case key.Matches(msg, m.Keys.Down):
+		m.sizeCursor = (m.sizeCursor + 1) % len(boardSizes)
+	case key.Matches(msg, m.Keys.Select):
+		m.choosingSize = false
+		m.SetBoardSize(m.dimensions(boardSizes[m.sizeCursor]))
+	}
+}
+
+func (m Model) sizesView() string {
+	var s strings.Builder
+	s.WriteString("Board size\n\n")
+	for i, size := range boardSizes {
+		cursor := "  "
+		if i == m.sizeCursor {
+			cursor = "> "
+		}
+		w, h := m.dimensions(size)
+		fmt.Fprintf(&s, "%s%-16s %7s\n", cursor, size.Name, fmt.Sprintf("%dx%d", w, h))
+	}
+	s.WriteString("\n↑/↓ select • enter play • esc keep current")
+
+	return m.GameOverStyle.Foreground(lipgloss.Color("15")).Align(lipgloss.Left).Render(s.String())
+}
@@ -1,20 +1,32 @@
 package game
 
 import (
+	"context"
 	"fmt"
 	"math"
-	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/bell"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/hint"
+	"github.com/debemdeboas/games.debem.dev/latency"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/season"
+	"github.com/debemdeboas/games.debem.dev/ui"
 	"golang.org/x/exp/rand"
 )
 
 const (
 	TICKDURATION = 16 * time.Millisecond
 	INITIALSPEED = 8
+	TOPSPEED     = 3
+	RAMP         = 1.0
 
 	UP    = 1
 	DOWN  = 2
@@ -27,9 +39,7 @@ const (
 	BUFFEREDDIRECTIONCHANGES = 5
 )
 
-type Position struct {
-	X, Y int
-}
+type Position = grid.Point
 
 type Model struct {
 	Term    string
@@ -47,6 +57,31 @@ type Model struct {
 	ScoreStyle     lipgloss.Style
 	GameOverStyle  lipgloss.Style
 
+	Keys KeyMap
+	help help.Model
+
+	// ctx bounds the game's timers to the session that runs it.
+	ctx context.Context
+
+	// Latency, when set, reports the session's network round trip.
+	Latency *latency.Meter
+	// Bell, when set, plays the audio cues the player turned on.
+	Bell *bell.Bell
+
+	// Scores, when set, ranks finished runs under Player's name and their
+	// key's Fingerprint.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	top         []leaderboard.Entry
+	combined    []leaderboard.Entry
+	scores      *leaderboard.Browser
+
+	// Profiles, when set, keeps the player's profile across sessions.
+	Profiles   profile.Store
+	profile    *profile.Profile
+	themeIndex int
+
 	// Game state
 	tickCount int
 	moveSpeed int
@@ -54,16 +89,75 @@ type Model struct {
 	direction int
 	dirChan   chan int
 	lastDir   int
-	food      Position
+	foods     []food
 	score     int
 	gameOver  bool
 	pause     bool
+	showHelp  bool
+	effects   []effect
+
+	// Input latency: when the oldest pending turn was pressed and how long
+	// turns take to apply, smoothed.
+	inputAt  time.Time
+	inputLag time.Duration
+
+	// Seeds: the current run's, whether it was chosen rather than drawn,
+	// and the one typed on the options screen for the next runs, if any
+	rng       *rand.Rand
+	seed      uint64
+	seeded    bool
+	replay    bool // the next run replays seed
+	seedInput string
+	seedFixed bool // set by SetSeed, the seed can't be typed over
+
+	// Options screen
+	timings      map[string]Timing
+	starts       map[string]Start
+	showOptions  bool
+	optionCursor int
+
+	// Debug overlay
+	showDebug bool
+	debug     *ui.DebugStats
+
+	frames *ui.FrameCache
+
+	// Hold-to-steer input mode
+	holdMode bool
+	hold     ui.HoldTracker
+
+	slow bool // see games.Env.Slow
+
+	// Modes: practice runs get hints, campaign runs move through levels
+	gameMode string
+	level    int
+	slide    int // turn waiting to apply on a slippery board, or -1
+	hints    hint.Counter
+	hintCell *Position
+
+	// Walls of the current campaign level
+	obstacles []Position
+
+	// Power-ups: the one waiting on the board, if any, and the ticks left
+	// on each active effect
+	powerUp *powerUp
+	boosts  [POWERUPS]int
+
+	// Seasonal cosmetics, chosen when a run starts
+	schedule season.Schedule
+	seasonal bool
+	event    season.Event
 
 	// Board
-	boardWidth  int
-	boardHeight int
-	offsetX     int
-	offsetY     int
+	boardWidth   int
+	boardHeight  int
+	camera       ui.Viewport
+	choosingSize bool
+	sizeCursor   int
+
+	// Difficulty picker, shown before the first run
+	choosingDifficulty bool
+	difficultyCursor   int
 }
 
 type tickMsg time.Time
@@ -77,10 +171,29 @@ func NewModel(term string, profile string, width, height int, bg string, styles
 		Bg:          bg,
 		boardWidth:  BOARDWIDTH,
 		boardHeight: BOARDHEIGHT,
-		offsetX:     (BOARDWIDTH - width) / 2,
-		offsetY:     (BOARDHEIGHT - height) / 2,
+		camera:      ui.NewViewport(BOARDWIDTH, BOARDHEIGHT, CAMERAMARGIN),
+		debug:       &ui.DebugStats{},
+		frames:      &ui.FrameCache{},
+		ctx:         context.Background(),
+		gameMode:    CLASSIC,
+		schedule:    season.DefaultSchedule,
+		seasonal:    true,
+		sizeCursor:  1,
+		timings: map[string]Timing{
+			CLASSIC:  DefaultTiming(),
+			PRACTICE: DefaultTiming(),
+			CAMPAIGN: DefaultTiming(),
+		},
+		starts: map[string]Start{
+			CLASSIC:  DefaultStart(),
+			PRACTICE: DefaultStart(),
+			CAMPAIGN: DefaultStart(),
+		},
 	}
 
+	m.choosingDifficulty = true
+	m.difficultyCursor = 1
+
 	// Apply styles if provided
 	if len(styles) >= 7 {
 		m.TxtStyle = styles[0]
@@ -91,48 +204,86 @@ func NewModel(term string, profile string, width, height int, bg string, styles
 		m.ScoreStyle = styles[5]
 		m.GameOverStyle = styles[6]
 	}
+	m.Keys = DefaultKeyMap()
+	m.help = ui.NewHelp(m.QuitStyle)
 
 	m.RestartGame()
 	return m
 }
 
+// SetContext binds the game loop to ctx, usually the SSH session's, so ticks
+// stop as soon as the player disconnects.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout, keeping
+// the player's own bindings.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+	if m.profile != nil {
+		ui.Rebind(&m.Keys, m.profile.Keys)
+	}
+}
+
 func (m Model) Init() tea.Cmd {
 	return m.tick()
 }
 
 func (m *Model) RestartGame() {
-	initialX := m.boardWidth / 2
-	initialY := m.boardHeight / 2
-
-	initialSnake := []Position{
-		{X: initialX, Y: initialY}, // head
-		{X: initialX - 1, Y: initialY},
-		{X: initialX - 2, Y: initialY},
-		{X: initialX - 3, Y: initialY}, // tail
-	}
-
+	m.seedRun()
 	m.tickCount = 0
-	m.moveSpeed = INITIALSPEED
-	m.snake = initialSnake
+	m.moveSpeed = m.timing().InitialSpeed
+	m.snake = m.spawn()
 	m.direction = RIGHT
 	m.dirChan = make(chan int, BUFFEREDDIRECTIONCHANGES)
-	m.food = Position{X: initialX + 5, Y: initialY}
 	m.score = 0
 	m.gameOver = false
 	m.pause = false
+	m.effects = nil
+	m.foods = nil
+	m.powerUp = nil
+	m.boosts = [POWERUPS]int{}
+	m.level = 0
+	m.slide = -1
+	m.resetFood(m.firstFood())
+	m.updateWalls()
+	m.clearLane()
+	m.hints.Reset()
+	m.hintCell = nil
+	m.updateSeason()
+	m.updateCamera()
 }
 
+// updateSpeed speeds the snake up as it scores, see moveSpan.
 func (m *Model) updateSpeed() {
-	speedReduction := int(math.Log2(float64(m.score + 1)))
-	newSpeed := INITIALSPEED - speedReduction
-	if newSpeed < 3 {
-		newSpeed = 3
+	m.moveSpeed = moveSpan(m.timing(), m.score)
+}
+
+// moveSpan is how many ticks a move of t takes at score: fewer with every
+// doubling of the score, by the timing's ramp, down to its top speed.
+func moveSpan(t Timing, score int) int {
+	return max(t.TopSpeed, t.InitialSpeed-int(t.Ramp*math.Log2(float64(score+1))))
+}
+
+// Pause stops the snake where it is, see games.Pauser. Menus and the help
+// stop it already.
+func (m *Model) Pause() bool {
+	if m.pause || m.gameOver || m.showHelp || m.showOptions || m.choosingSize || m.choosingDifficulty || m.scores != nil {
+		return false
 	}
-	m.moveSpeed = newSpeed
+	m.pause = true
+	return true
+}
+
+// Resume lets the snake go on. Ticks keep coming while it's paused.
+func (m *Model) Resume() tea.Cmd {
+	m.pause = false
+	return nil
 }
 
 func (m Model) tick() tea.Cmd {
-	return tea.Every(TICKDURATION, func(t time.Time) tea.Msg {
+	return ui.Every(m.ctx, m.tickDuration(), func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
@@ -140,14 +291,15 @@ func (m Model) tick() tea.Cmd {
 func (m Model) newFoodPosition() Position {
 	for {
 		foodOnSnake := false
-		food := Position{X: rand.Intn(m.boardWidth), Y: rand.Intn(m.boardHeight)}
+		food := Position{X: m.rng.Intn(m.boardWidth), Y: m.rng.Intn(m.boardHeight)}
 		for _, pos := range m.snake {
 			if pos.X == food.X && pos.Y == food.Y {
 				foodOnSnake = true
 				break
 			}
 		}
-		if !foodOnSnake {
+		taken := m.foodAt(food) >= 0 || (m.powerUp != nil && m.powerUp.pos == food)
+		if !foodOnSnake && !taken && !m.isWall(food) {
 			return food
 		}
 	}
@@ -182,7 +334,7 @@ func (m Model) checkCollision(pos Position) bool {
 		}
 	}
 
-	return false
+	return m.isWall(pos) && !m.boosted(PHASE)
 }
 
 func isOppositeDirection(a, b int) bool {
@@ -192,15 +344,22 @@ func isOppositeDirection(a, b int) bool {
 		(a == RIGHT && b == LEFT)
 }
 
-func (m *Model) handleFood(newHead Position) {
-	m.score++
+func (m *Model) handleFood(newHead Position, i int) {
+	points := m.foodPoints(m.eat(i))
+	m.Bell.Ring(bell.EAT)
+	m.score += points
 	m.updateSpeed()
-	m.food = m.newFoodPosition()
+	m.effects = append(m.effects, newPopup(newHead, points))
 	m.snake = append([]Position{newHead}, m.snake...)
+	m.checkLevelUp()
 }
 
 func (m *Model) handleTick() {
-	if m.tickCount >= m.moveSpeed {
+	m.advanceEffects()
+	m.advancePowerUps()
+	m.advanceFood()
+
+	if m.tickCount >= m.moveTicks() {
 		m.tickCount = 0
 		lastValidDir := -1
 
@@ -218,6 +377,22 @@ func (m *Model) handleTick() {
 			default:
 			}
 
+			if lastValidDir < 0 {
+				lastValidDir = m.heldDirection()
+			}
+
+			if m.biome().Slippery {
+				lastValidDir, m.slide = m.slide, lastValidDir
+				if lastValidDir >= 0 && isOppositeDirection(lastValidDir, m.direction) {
+					lastValidDir = -1
+				}
+			}
+
+			if !m.inputAt.IsZero() && len(m.dirChan) == 0 {
+				m.inputLag = latency.Smooth(m.inputLag, time.Since(m.inputAt))
+				m.inputAt = time.Time{}
+			}
+
 			if lastValidDir >= 0 {
 				m.direction = lastValidDir
 				m.lastDir = lastValidDir
@@ -228,80 +403,147 @@ func (m *Model) handleTick() {
 
 			if m.checkCollision(newHead) {
 				m.gameOver = true
+				m.Bell.Ring(bell.DEATH)
+				m.submitScore()
+				m.recordRun()
 				return
 			}
 
-			if newHead.X == m.food.X && newHead.Y == m.food.Y {
-				m.handleFood(newHead)
+			if i := m.foodAt(newHead); i >= 0 {
+				m.handleFood(newHead, i)
 			} else {
 				m.snake = append([]Position{newHead}, m.snake[:len(m.snake)-1]...)
 			}
+			m.collectPowerUp(newHead)
+			m.spawnPowerUp()
+			m.hintCell = nil
+			m.updateCamera()
 
 			break
 		}
 	}
 }
 
+var dirNames = map[int]string{UP: "up", DOWN: "down", LEFT: "left", RIGHT: "right"}
+
+// heldDirection returns the direction key held down in hold-to-steer mode,
+// so a turn that was rejected when first pressed still applies on a later
+// move while the key stays down. It returns -1 when nothing applies.
+func (m *Model) heldDirection() int {
+	if !m.holdMode {
+		return -1
+	}
+	held, ok := m.hold.Held(time.Now())
+	if !ok {
+		return -1
+	}
+	for dir, name := range dirNames {
+		if name == held && dir != m.direction && !isOppositeDirection(dir, m.direction) {
+			return dir
+		}
+	}
+	return -1
+}
+
+func (m *Model) queueDirection(dir int) {
+	if m.holdMode && m.hold.Press(dirNames[dir], time.Now()) {
+		return
+	}
+	if m.lastDir == dir {
+		return
+	}
+	select {
+	case m.dirChan <- dir:
+		m.lastDir = dir
+		if m.inputAt.IsZero() {
+			m.inputAt = time.Now()
+		}
+		log.Debug("Direction " + dirNames[dir])
+	default:
+		log.Warn("Buffer full, dropping " + dirNames[dir])
+	}
+}
+
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.Height = msg.Height
 		m.Width = msg.Width
+		m.updateCamera()
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "w", "k", "up":
-			if m.lastDir == UP {
-				break
-			}
-			select {
-			case m.dirChan <- UP:
-				m.lastDir = UP
-				log.Debug("Direction UP")
-			default:
-				log.Warn("Buffer full, dropping up")
-			}
-		case "s", "j", "down":
-			if m.lastDir == DOWN {
-				break
-			}
-			select {
-			case m.dirChan <- DOWN:
-				m.lastDir = DOWN
-				log.Debug("Direction DOWN")
-			default:
-				log.Warn("Buffer full, dropping down")
-			}
-		case "a", "h", "left":
-			if m.lastDir == LEFT {
-				break
-			}
-			select {
-			case m.dirChan <- LEFT:
-				m.lastDir = LEFT
-				log.Debug("Direction LEFT")
+		if m.showOptions {
+			m.updateOptions(msg)
+			break
+		}
+		if m.scores != nil {
+			m.updateScores(msg)
+			break
+		}
+		if m.choosingDifficulty {
+			switch {
+			case key.Matches(msg, m.Keys.Quit):
+				return m, tea.Quit
+			case key.Matches(msg, m.Keys.Size):
+				m.choosingDifficulty = false
+				m.choosingSize = true
 			default:
-				log.Warn("Buffer full, dropping left")
-			}
-		case "d", "l", "right":
-			if m.lastDir == RIGHT {
-				break
+				m.updateDifficulties(msg)
 			}
-			select {
-			case m.dirChan <- RIGHT:
-				m.lastDir = RIGHT
-				log.Debug("Direction RIGHT")
-			default:
-				log.Warn("Buffer full, dropping right")
+			break
+		}
+		if m.choosingSize {
+			if key.Matches(msg, m.Keys.Quit) {
+				return m, tea.Quit
 			}
-		case " ":
+			m.updateSizes(msg)
+			break
+		}
+
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Up):
+			m.queueDirection(UP)
+		case key.Matches(msg, m.Keys.Down):
+			m.queueDirection(DOWN)
+		case key.Matches(msg, m.Keys.Left):
+			m.queueDirection(LEFT)
+		case key.Matches(msg, m.Keys.Right):
+			m.queueDirection(RIGHT)
+		case key.Matches(msg, m.Keys.Pause):
 			m.pause = !m.pause
-		case "r":
+		case key.Matches(msg, m.Keys.Restart):
 			m.RestartGame()
+		case key.Matches(msg, m.Keys.Replay):
+			m.ReplaySeed()
+		case key.Matches(msg, m.Keys.Practice):
+			m.togglePractice()
+		case key.Matches(msg, m.Keys.Campaign):
+			m.toggleCampaign()
+		case key.Matches(msg, m.Keys.Hint):
+			m.requestHint()
+		case key.Matches(msg, m.Keys.Hold):
+			m.holdMode = !m.holdMode
+			m.hold.Release()
+		case key.Matches(msg, m.Keys.Size):
+			m.choosingSize = true
+		case key.Matches(msg, m.Keys.Difficulty):
+			m.choosingDifficulty = true
+		case key.Matches(msg, m.Keys.Scores):
+			m.openScores()
+		case key.Matches(msg, m.Keys.Options):
+			m.showOptions = true
+		case key.Matches(msg, m.Keys.Debug):
+			m.showDebug = !m.showDebug
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
 		}
 	case tickMsg:
-		if m.pause {
+		m.debug.Tick(time.Time(msg), m.tickDuration())
+
+		if m.pause || m.showHelp || m.showOptions || m.choosingSize || m.choosingDifficulty || m.scores != nil {
 			return m, m.tick()
 		}
 
@@ -316,43 +558,171 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) statusLine() string {
+	status := fmt.Sprintf("Score: %d", m.score)
+	if m.gameMode == CAMPAIGN {
+		status += fmt.Sprintf(" | Level %d: %s", m.level+1, m.biome().Name)
+	}
+	if boosts := m.boostStatus(); boosts != "" {
+		status += " | " + boosts
+	}
+	if m.holdMode {
+		status += " | Hold-to-steer"
+	}
+	if rtt := m.Latency.RTT(); rtt > 0 {
+		status += fmt.Sprintf(" | ping %dms", rtt.Milliseconds())
+	}
+	if m.inputLag > 0 {
+		status += fmt.Sprintf(" | input %dms", m.inputLag.Milliseconds())
+	}
+	return status
+}
+
 func (m Model) View() string {
-	board := make([][]string, m.boardHeight)
-	for i := range board {
-		board[i] = make([]string, m.boardWidth)
-		for j := range board[i] {
-			board[i][j] = ""
-		}
+	defer m.debug.Frame(time.Now())
+
+	// The debug panel changes every frame, so there's nothing to cache. The
+	// leaderboard is cheap and its filters live in the browser.
+	if m.showDebug || m.scores != nil {
+		return m.render()
 	}
+	return m.frames.Frame(m.stateHash(), m.render)
+}
 
-	// Draw snake and food
-	for _, pos := range m.snake[1:] {
-		board[pos.Y][pos.X] = "S"
+// stateHash covers everything render reads.
+func (m Model) stateHash() uint64 {
+	h := ui.NewStateHash()
+	h.Int(m.Width)
+	h.Int(m.Height)
+	h.Int(m.score)
+	h.Bool(m.gameOver)
+	h.Bool(m.showHelp)
+	h.Bool(m.showOptions)
+	h.Int(m.optionCursor)
+	h.Bool(m.choosingSize)
+	h.Bool(m.choosingDifficulty)
+	h.Int(m.difficultyCursor)
+	h.Int(m.sizeCursor)
+	h.Int(m.boardWidth)
+	h.Int(m.boardHeight)
+	for _, e := range m.top {
+		h.String(e.Player)
+		h.Int(e.Score)
 	}
-	board[m.snake[0].Y][m.snake[0].X] = "H"
-	board[m.food.Y][m.food.X] = "F"
+	for _, e := range m.combined {
+		h.String(e.Player)
+		h.Int(e.Points)
+	}
+	t := m.timing()
+	h.Int(int(t.Tick))
+	h.Int(t.InitialSpeed)
+	h.Int(t.TopSpeed)
+	h.Int(int(t.Ramp * 100))
+	st := m.start()
+	h.Int(st.Length)
+	h.String(st.Spawn)
+	h.Int(st.SafeMoves)
+	h.Int(int(m.seed))
+	h.String(m.seedInput)
+	h.String(m.gameMode)
+	h.Int(m.level)
+	h.String(m.event.Name)
+	h.Int(m.hints.Used)
+	h.Bool(m.holdMode)
+	h.Bool(m.slow)
+	h.Int(int(m.Keys.layout))
+	h.Int(m.themeIndex)
+	h.Int(int(m.Latency.RTT().Milliseconds()))
+	h.Int(int(m.inputLag.Milliseconds()))
+	h.Int(m.camera.X)
+	h.Int(m.camera.Y)
+	h.Int(m.camera.Width)
+	h.Int(m.camera.Height)
+	for _, f := range m.foods {
+		h.Int(f.pos.X)
+		h.Int(f.pos.Y)
+		h.Bool(f.golden)
+	}
+	h.Int(len(m.snake))
+	for _, pos := range m.snake {
+		h.Int(pos.X)
+		h.Int(pos.Y)
+	}
+	for _, fx := range m.effects {
+		h.Int(fx.pos.X)
+		h.Int(fx.pos.Y)
+		h.Int(fx.age)
+		h.String(fx.text)
+	}
+	if m.hintCell != nil {
+		h.Int(m.hintCell.X)
+		h.Int(m.hintCell.Y)
+	}
+	if m.powerUp != nil {
+		h.Int(int(m.powerUp.kind))
+		h.Int(m.powerUp.pos.X)
+		h.Int(m.powerUp.pos.Y)
+	}
+	h.String(m.boostStatus())
+	return h.Sum()
+}
+
+func (m Model) render() string {
+	board := grid.New[string](m.boardWidth, m.boardHeight)
 
-	var s strings.Builder
-	for y, row := range board {
-		if y > 0 {
-			s.WriteString("\n")
+	// Draw walls, snake and food
+	for _, pos := range m.obstacles {
+		board.Set(pos, "W")
+	}
+	for _, pos := range m.snake[1:] {
+		board.Set(pos, "S")
+	}
+	board.Set(m.snake[0], "H")
+	for _, f := range m.foods {
+		if f.golden {
+			board.Set(f.pos, "G")
+		} else {
+			board.Set(f.pos, "F")
 		}
-		for _, cell := range row {
-			var renderedCell string
-			switch cell {
-			case "H":
-				renderedCell = "██"
-				s.WriteString(m.SnakeStyle.Render(renderedCell))
-			case "S":
-				renderedCell = "▒▒"
-				s.WriteString(m.SnakeStyle.Render(renderedCell))
-			case "F":
-				renderedCell = "🍎"
-				s.WriteString(m.FoodStyle.Render(renderedCell))
-			default:
-				s.WriteString(m.GameBoardStyle.Render())
+	}
+	if m.powerUp != nil {
+		board.Set(m.powerUp.pos, "P")
+	}
+
+	overlay := m.overlay()
+	_, _, scrolling := m.viewportSize()
+
+	rendered := m.camera.Render(func(x, y int) string {
+		switch board.At(Position{X: x, Y: y}) {
+		case "H":
+			return m.snakeStyle().Render("██")
+		case "S":
+			return m.snakeStyle().Render("▒▒")
+		case "F":
+			return m.FoodStyle.Render(m.foodGlyph())
+		case "G":
+			return m.FoodStyle.Render(GOLDENGLYPH)
+		case "W":
+			return m.wallStyle().Render("▓▓")
+		case "P":
+			return m.powerUpStyle(m.powerUp.kind).Render(powers[m.powerUp.kind].Glyph)
+		default:
+			if fx, ok := overlay[Position{X: x, Y: y}]; ok {
+				return fx.style.Render(fx.text)
+			}
+			if m.hintCell != nil && *m.hintCell == (Position{X: x, Y: y}) {
+				return m.SnakeStyle.Render("··")
 			}
+			return m.emptyCell(x, y)
 		}
+	})
+
+	boardView := m.boardStyle().Render(rendered)
+	if scrolling {
+		boardView = lipgloss.JoinHorizontal(lipgloss.Top, boardView, " ", m.minimap())
+	}
+	if m.showDebug {
+		boardView = lipgloss.JoinHorizontal(lipgloss.Top, boardView, " ", m.debugPanel())
 	}
 
 	gameView := lipgloss.Place(
@@ -360,19 +730,61 @@ func (m Model) View() string {
 		lipgloss.Center, lipgloss.Center,
 		lipgloss.JoinVertical(
 			lipgloss.Center,
-			m.ScoreStyle.Render(fmt.Sprintf("Score: %d", m.score)),
-			m.TxtStyle.Render(s.String())+"\n",
-			m.QuitStyle.Render("Press 'r' to restart | Press 'SPACE' to pause"),
-			m.QuitStyle.Render("Press 'q' to quit"),
+			m.ScoreStyle.Render(m.statusLine()),
+			boardView+"\n",
+			m.QuitStyle.Render(m.hintLine()),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
 		),
 	)
 
+	if m.showOptions {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.optionsView(),
+		)
+	}
+
+	if m.scores != nil {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.scoresView(),
+		)
+	}
+
+	if m.choosingDifficulty {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.difficultiesView(),
+		)
+	}
+
+	if m.choosingSize {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.sizesView(),
+		)
+	}
+
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.GameOverStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
 	if m.gameOver {
 		gameOver := m.GameOverStyle.Render(lipgloss.JoinVertical(
 			lipgloss.Center,
 			"Game Over!",
 			fmt.Sprintf("Score: %d", m.score),
-			"Press 'r' to restart",
+			m.QuitStyle.Render(fmt.Sprintf("Seed %d", m.seed)),
+			fmt.Sprintf("Press '%s' to restart, '%s' to play this seed again", m.Keys.Restart.Help().Key, m.Keys.Replay.Help().Key),
+			m.topView(),
 		))
 
 		return lipgloss.Place(
@@ -0,0 +1,111 @@
+package game
+
+import "slices"
+
+const (
+	STARTLENGTH  = 4
+	MINLENGTH    = 2
+	MAXLENGTH    = 10 // still leaves room to move on the smallest board
+	SAFEMOVES    = 5  // moves straight ahead kept clear at the start
+	MAXSAFEMOVES = 10
+	FOODAHEAD    = 5 // cells in front of the head the first food lands, room permitting
+)
+
+// Where runs start
+const (
+	SPAWNCENTER = "center"
+	SPAWNLEFT   = "left"
+	SPAWNRANDOM = "random"
+)
+
+var spawns = []string{SPAWNCENTER, SPAWNLEFT, SPAWNRANDOM}
+
+// Start sets how a mode's runs begin: how long the snake is, where it
+// spawns, heading right, and how many moves straight ahead are sure to be
+// clear of walls and the board's edge.
+type Start struct {
+	Length    int
+	Spawn     string
+	SafeMoves int
+}
+
+func DefaultStart() Start {
+	return Start{Length: STARTLENGTH, Spawn: SPAWNCENTER, SafeMoves: SAFEMOVES}
+}
+
+// Clamp keeps a start within bounds that fit every board.
+func (s Start) Clamp() Start {
+	s.Length = max(MINLENGTH, min(s.Length, MAXLENGTH))
+	s.SafeMoves = max(0, min(s.SafeMoves, MAXSAFEMOVES))
+	if !slices.Contains(spawns, s.Spawn) {
+		s.Spawn = SPAWNCENTER
+	}
+	return s
+}
+
+// SetStart overrides how runs of a mode (CLASSIC, PRACTICE or CAMPAIGN)
+// begin, from the next one.
+func (m *Model) SetStart(mode string, s Start) {
+	m.starts[mode] = s.Clamp()
+}
+
+func (m Model) start() Start {
+	if s, ok := m.starts[m.mode()]; ok {
+		return s
+	}
+	return DefaultStart()
+}
+
+// safeMoves is how many of the start's clear moves fit between the tail
+// and the right edge of the board.
+func (m Model) safeMoves() int {
+	s := m.start()
+	return max(0, min(s.SafeMoves, m.boardWidth-s.Length))
+}
+
+// spawn lays the snake out for a new run, its head first, so that its tail
+// and the moves it's promised stay on the board.
+func (m Model) spawn() []Position {
+	s := m.start()
+	lo, hi := s.Length-1, m.boardWidth-1-m.safeMoves()
+	head := Position{X: m.boardWidth / 2, Y: m.boardHeight / 2}
+	switch s.Spawn {
+	case SPAWNLEFT:
+		head.X = lo
+	case SPAWNRANDOM:
+		head = Position{X: lo + m.rng.Intn(hi-lo+1), Y: m.rng.Intn(m.boardHeight)}
+	}
+	head.X = max(lo, min(head.X, hi))
+
+	snake := make([]Position, s.Length)
+	for i := range snake {
+		snake[i] = Position{X: head.X - i, Y: head.Y}
+	}
+	return snake
+}
+
+// lane is the cells ahead of the head the snake is sure to cross safely.
+func (m Model) lane() []Position {
+	lane := make([]Position, m.safeMoves())
+	for i := range lane {
+		lane[i] = m.snake[0].Add(Position{X: i + 1})
+	}
+	return lane
+}
+
+// clearLane takes the walls off the lane.
+func (m *Model) clearLane() {
+	lane := m.lane()
+	m.obstacles = slices.DeleteFunc(m.obstacles, func(p Position) bool { return slices.Contains(lane, p) })
+}
+
+// firstFood is where the first food goes: right in front of the snake, as
+// far as FOODAHEAD when the board has room, or anywhere when the head is
+// at the edge.
+func (m Model) firstFood() Position {
+	head := m.snake[0]
+	if ahead := min(FOODAHEAD, m.boardWidth-1-head.X); ahead > 0 {
+		return Position{X: head.X + ahead, Y: head.Y}
+	}
+	return m.newFoodPosition()
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// Theme is the player's palette. Campaign biomes and seasonal events still
+// take over while they apply.
+type Theme struct {
+	Name  string
+	Snake lipgloss.Color
+	Food  lipgloss.Color
+}
+
+var themes = []Theme{
+	{Name: "classic", Snake: "10", Food: "9"},
+	{Name: "ocean", Snake: "14", Food: "11"},
+	{Name: "sunset", Snake: "208", Food: "13"},
+	{Name: "mono", Snake: "15", Food: "7"},
+}
+
+func (m Model) theme() Theme {
+	return themes[m.themeIndex]
+}
+
+// SetTheme switches to the named theme. Unknown names are ignored.
+func (m *Model) SetTheme(name string) {
+	for i, t := range themes {
+		if t.Name == name {
+			m.themeIndex = i
+			m.SnakeStyle = m.SnakeStyle.Foreground(t.Snake)
+			m.FoodStyle = m.FoodStyle.Foreground(t.Food)
+			m.TxtStyle = m.TxtStyle.Foreground(t.Snake)
+			return
+		}
+	}
+}
+
+func (m *Model) cycleTheme(delta int) {
+	m.SetTheme(themes[(m.themeIndex+delta+len(themes))%len(themes)].Name)
+	if m.profile != nil {
+		m.profile.Theme = m.theme().Name
+		m.saveProfile()
+	}
+}
+
+// SetProfile applies a player's saved name, theme and key bindings. Changes
+// to the theme and the finished runs are saved back to store, if set.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store) {
+	m.profile = p
+	m.Profiles = store
+	if p.Name != "" {
+		m.Player = p.Name
+	}
+	m.SetTheme(p.Theme)
+	ui.Rebind(&m.Keys, p.Keys)
+}
+
+// recordRun adds a finished run to the player's stats.
+func (m *Model) recordRun() {
+	if m.profile == nil {
+		return
+	}
+	m.profile.Record(GAMENAME, m.score)
+	m.saveProfile()
+}
+
+func (m *Model) saveProfile() {
+	profile.Save(m.Profiles, m.Fingerprint, m.profile)
+}
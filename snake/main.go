@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	snake "github.com/debemdeboas/games.debem.dev/snake/game"
+	"github.com/debemdeboas/games.debem.dev/ui"
 	"golang.org/x/term"
 )
 
@@ -33,7 +34,7 @@ func initialModel() tea.Model {
 	// Get terminal width and height
 	w, h, _ := term.GetSize(int(os.Stdout.Fd()))
 
-	return snake.NewModel(
+	m := snake.NewModel(
 		os.Getenv("TERM"),
 		"256",
 		w,
@@ -52,4 +53,7 @@ func initialModel() tea.Model {
 			Background(lipgloss.Color("#363636")).
 			Padding(3),
 	)
+	m.SetLayout(ui.LayoutFromEnv(os.Environ()))
+
+	return m
 }
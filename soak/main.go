@@ -0,0 +1,219 @@
+// Command soak hammers a running game server with churn: abrupt
+// disconnects, resize storms and clients that read their output slowly. Run
+// the server built with `-tags soak` and pass its debug endpoint in -metrics
+// to compare goroutine and heap counts before and after the run.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	gossh "golang.org/x/crypto/ssh"
+	mrand "golang.org/x/exp/rand"
+)
+
+var (
+	addr       = flag.String("addr", "localhost:23232", "server address")
+	sessions   = flag.Int("sessions", 20, "concurrent client sessions")
+	duration   = flag.Duration("duration", time.Minute, "how long to run")
+	disconnect = flag.Float64("disconnect", 0.05, "chance per action of dropping the connection")
+	storm      = flag.Float64("storm", 0.1, "chance per action of a resize storm")
+	slow       = flag.Float64("slow", 0.2, "fraction of clients that read output slowly")
+	metrics    = flag.String("metrics", "", "server expvar URL to compare before and after, e.g. http://localhost:6060/debug/vars")
+	settle     = flag.Duration("settle", 5*time.Second, "wait after the run before sampling metrics")
+)
+
+type stats struct {
+	connects    atomic.Int64
+	failures    atomic.Int64
+	disconnects atomic.Int64
+	storms      atomic.Int64
+	keys        atomic.Int64
+	bytes       atomic.Int64
+}
+
+func main() {
+	flag.Parse()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal("Could not generate client key", "error", err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		log.Fatal("Could not create signer", "error", err)
+	}
+	config := &gossh.ClientConfig{
+		User:            "soak",
+		Auth:            []gossh.AuthMethod{gossh.PublicKeys(signer)},
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	}
+
+	before := sample()
+
+	var st stats
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(*duration)
+	for i := range *sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slowReader := float64(i) < float64(*sessions)**slow
+			for time.Now().Before(deadline) {
+				if err := churn(config, slowReader, deadline, &st); err != nil {
+					st.failures.Add(1)
+					log.Debug("Session failed", "error", err)
+					time.Sleep(time.Second)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	log.Info("Soak finished",
+		"connects", st.connects.Load(),
+		"failures", st.failures.Load(),
+		"disconnects", st.disconnects.Load(),
+		"storms", st.storms.Load(),
+		"keys", st.keys.Load(),
+		"bytes", st.bytes.Load(),
+	)
+
+	if before != nil {
+		time.Sleep(*settle)
+		compare(before, sample())
+	}
+}
+
+var keys = []string{"\x1b[A", "\x1b[B", "\x1b[C", "\x1b[D", " ", "r", "?", "\x1bOR"}
+
+// churn runs one session until it is randomly dropped or the run ends.
+func churn(config *gossh.ClientConfig, slowReader bool, deadline time.Time, st *stats) error {
+	client, err := gossh.Dial("tcp", *addr, config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	sess, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	if err := sess.RequestPty("xterm-256color", 40, 120, gossh.TerminalModes{}); err != nil {
+		return err
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := sess.Shell(); err != nil {
+		return err
+	}
+	st.connects.Add(1)
+
+	go drain(stdout, slowReader, st)
+
+	for time.Now().Before(deadline) {
+		switch r := mrand.Float64(); {
+		case r < *disconnect:
+			// Drop the TCP connection without a clean channel close.
+			st.disconnects.Add(1)
+			return client.Conn.Close()
+		case r < *disconnect+*storm:
+			st.storms.Add(1)
+			for range 50 {
+				resize(sess, 10+mrand.Intn(200), 5+mrand.Intn(80))
+			}
+		default:
+			if _, err := io.WriteString(stdin, keys[mrand.Intn(len(keys))]); err != nil {
+				return err
+			}
+			st.keys.Add(1)
+		}
+		time.Sleep(time.Duration(20+mrand.Intn(200)) * time.Millisecond)
+	}
+	return nil
+}
+
+func resize(sess *gossh.Session, w, h int) {
+	_ = sess.WindowChange(h, w)
+}
+
+func drain(r io.Reader, slowReader bool, st *stats) {
+	buf := make([]byte, 32*1024)
+	if slowReader {
+		buf = buf[:16]
+	}
+	for {
+		n, err := r.Read(buf)
+		st.bytes.Add(int64(n))
+		if err != nil {
+			return
+		}
+		if slowReader {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+func sample() map[string]any {
+	if *metrics == "" {
+		return nil
+	}
+	resp, err := http.Get(*metrics)
+	if err != nil {
+		log.Error("Could not fetch metrics", "error", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	vars := map[string]any{}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		log.Error("Could not decode metrics", "error", err)
+		os.Exit(1)
+	}
+	return vars
+}
+
+// compare prints every numeric metric that changed during the run, looking
+// one level into expvar maps such as lifecycle.
+func compare(before, after map[string]any) {
+	for k, v := range after {
+		if nested, ok := v.(map[string]any); ok {
+			prev, _ := before[k].(map[string]any)
+			for nk, nv := range nested {
+				report(k+"."+nk, prev[nk], nv)
+			}
+			continue
+		}
+		report(k, before[k], v)
+	}
+}
+
+func report(name string, before, after any) {
+	a, ok := after.(float64)
+	if !ok {
+		return
+	}
+	b, _ := before.(float64)
+	if a != b {
+		fmt.Printf("%-28s %12.0f -> %12.0f (%+.0f)\n", name, b, a, a-b)
+	}
+}
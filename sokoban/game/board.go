@@ -0,0 +1,116 @@
+package game
+
+import (
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+// step is a move as undo needs it.
+type step struct {
+	dir    grid.Point
+	pushed bool // a box moved along
+}
+
+// Board is a level being played. Every move is kept, so any number of them
+// can be undone.
+type Board struct {
+	walls  *grid.Grid[bool]
+	goals  *grid.Grid[bool]
+	boxes  *grid.Grid[bool]
+	player grid.Point
+
+	history []step
+	pushes  int
+}
+
+func NewBoard(l Level) *Board {
+	w, h := len(l.rows[0]), len(l.rows)
+	b := &Board{
+		walls: grid.New[bool](w, h),
+		goals: grid.New[bool](w, h),
+		boxes: grid.New[bool](w, h),
+	}
+	for y, row := range l.rows {
+		for x, c := range row {
+			p := grid.Point{X: x, Y: y}
+			switch c {
+			case WALL:
+				b.walls.Set(p, true)
+			case PLAYER:
+				b.player = p
+			case PLAYERGOAL:
+				b.player = p
+				b.goals.Set(p, true)
+			case BOX:
+				b.boxes.Set(p, true)
+			case BOXGOAL:
+				b.boxes.Set(p, true)
+				b.goals.Set(p, true)
+			case GOAL:
+				b.goals.Set(p, true)
+			}
+		}
+	}
+	return b
+}
+
+func (b *Board) Width() int  { return b.walls.Width() }
+func (b *Board) Height() int { return b.walls.Height() }
+
+// Moves counts the steps taken, pushes and all, less those undone.
+func (b *Board) Moves() int  { return len(b.history) }
+func (b *Board) Pushes() int { return b.pushes }
+
+// free reports whether p can take the player or a box.
+func (b *Board) free(p grid.Point) bool {
+	return b.walls.InBounds(p) && !b.walls.At(p) && !b.boxes.At(p)
+}
+
+// Move steps the player towards dir, pushing a box if there's one with
+// room behind it, and reports whether they moved.
+func (b *Board) Move(dir grid.Point) bool {
+	next := b.player.Add(dir)
+	if !b.walls.InBounds(next) || b.walls.At(next) {
+		return false
+	}
+	pushed := b.boxes.At(next)
+	if pushed {
+		behind := next.Add(dir)
+		if !b.free(behind) {
+			return false
+		}
+		b.boxes.Set(next, false)
+		b.boxes.Set(behind, true)
+		b.pushes++
+	}
+	b.player = next
+	b.history = append(b.history, step{dir: dir, pushed: pushed})
+	return true
+}
+
+// Undo takes back the last move, reporting false if there's none.
+func (b *Board) Undo() bool {
+	if len(b.history) == 0 {
+		return false
+	}
+	s := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+	if s.pushed {
+		box := b.player.Add(s.dir)
+		b.boxes.Set(box, false)
+		b.boxes.Set(b.player, true)
+		b.pushes--
+	}
+	b.player = b.player.Sub(s.dir)
+	return true
+}
+
+// Solved reports whether every box is on a goal.
+func (b *Board) Solved() bool {
+	solved := true
+	b.boxes.Each(func(p grid.Point, box bool) {
+		if box && !b.goals.At(p) {
+			solved = false
+		}
+	})
+	return solved
+}
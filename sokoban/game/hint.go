@@ -0,0 +1,190 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/hint"
+)
+
+// HINTSTATES bounds the positions a hint looks through, so a big level
+// can't keep the server busy: past it the player gets no hint.
+const HINTSTATES = 200_000
+
+// position is the board as the solver sees it, between pushes: the cells
+// the boxes are on, sorted, and the first cell of the area the player can
+// walk to without pushing, as a comparable key.
+type position struct {
+	player int
+	boxes  string
+}
+
+// solver searches a board for solutions, by push, with cells numbered row
+// by row.
+type solver struct {
+	b    *Board
+	open []bool // not a wall
+	live []bool // a box on it can still reach a goal
+	goal []bool
+	step [4]int // the index offsets of up, down, left and right
+}
+
+func newSolver(b *Board) *solver {
+	w, h := b.Width(), b.Height()
+	s := &solver{
+		b:    b,
+		open: make([]bool, w*h),
+		live: make([]bool, w*h),
+		goal: make([]bool, w*h),
+		step: [4]int{-w, w, -1, 1},
+	}
+	var queue []int
+	b.walls.Each(func(p grid.Point, wall bool) {
+		i := b.index(p)
+		s.open[i] = !wall && p.X > 0 && p.Y > 0 && p.X < w-1 && p.Y < h-1
+		if b.goals.At(p) {
+			s.goal[i], s.live[i] = true, true
+			queue = append(queue, i)
+		}
+	})
+	// A box is live where it can be pulled to from a goal.
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, d := range s.step {
+			if to := i + d; s.open[to] && s.open[to+d] && !s.live[to] {
+				s.live[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return s
+}
+
+// reach marks the cells a player at p can walk to around boxes and returns
+// the first of them.
+func (s *solver) reach(p int, boxes []bool) ([]bool, int) {
+	seen := make([]bool, len(s.open))
+	seen[p] = true
+	queue, first := []int{p}, p
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		first = min(first, i)
+		if !s.open[i] {
+			continue // outside the walls, on the edge
+		}
+		for _, d := range s.step {
+			if to := i + d; s.open[to] && !boxes[to] && !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return seen, first
+}
+
+func (s *solver) settle(p int, boxes []int) position {
+	occupied := make([]bool, len(s.open))
+	for _, c := range boxes {
+		occupied[c] = true
+	}
+	_, first := s.reach(p, occupied)
+	return position{player: first, boxes: encode(boxes)}
+}
+
+// encode packs the cells of boxes, sorted, two bytes each.
+func encode(boxes []int) string {
+	boxes = slices.Clone(boxes)
+	slices.Sort(boxes)
+	key := make([]byte, 0, 2*len(boxes))
+	for _, i := range boxes {
+		key = append(key, byte(i>>8), byte(i))
+	}
+	return string(key)
+}
+
+func decode(key string) []int {
+	boxes := make([]int, 0, len(key)/2)
+	for i := 0; i < len(key); i += 2 {
+		boxes = append(boxes, int(key[i])<<8|int(key[i+1]))
+	}
+	return boxes
+}
+
+// next lists the positions one push from p.
+func (s *solver) next(p position) []position {
+	boxes := decode(p.boxes)
+	occupied := make([]bool, len(s.open))
+	for _, c := range boxes {
+		occupied[c] = true
+	}
+	reach, _ := s.reach(p.player, occupied)
+	var next []position
+	for i, box := range boxes {
+		for _, d := range s.step {
+			to := box + d
+			if !reach[box-d] || !s.open[to] || occupied[to] || !s.live[to] {
+				continue
+			}
+			moved := slices.Clone(boxes)
+			moved[i] = to
+			next = append(next, s.settle(box, moved))
+		}
+	}
+	return next
+}
+
+func (s *solver) solved(p position) bool {
+	for _, c := range decode(p.boxes) {
+		if !s.goal[c] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Board) index(p grid.Point) int {
+	return p.Y*b.Width() + p.X
+}
+
+func (b *Board) point(i int) grid.Point {
+	return grid.Point{X: i % b.Width(), Y: i / b.Width()}
+}
+
+// Hint is the direction of the player's next step towards the first push
+// of a solution with the fewest pushes, false if there's none within
+// HINTSTATES positions.
+func (b *Board) Hint() (grid.Point, bool) {
+	s := newSolver(b)
+	var boxes []int
+	b.boxes.Each(func(p grid.Point, box bool) {
+		if box {
+			boxes = append(boxes, b.index(p))
+		}
+	})
+	path, ok := hint.Solve(s.settle(b.index(b.player), boxes), s.next, s.solved, HINTSTATES)
+	if !ok || len(path) < 2 {
+		return grid.Point{}, false
+	}
+	before, after := decode(path[0].boxes), decode(path[1].boxes)
+	var from, to grid.Point
+	for _, c := range before {
+		if !slices.Contains(after, c) {
+			from = b.point(c)
+		}
+	}
+	for _, c := range after {
+		if !slices.Contains(before, c) {
+			to = b.point(c)
+		}
+	}
+	dir := to.Sub(from)
+	walk, ok := b.walls.Path(b.player, from.Sub(dir), func(c grid.Point, wall bool) bool {
+		return !wall && !b.boxes.At(c)
+	})
+	if !ok || len(walk) < 2 {
+		return dir, true
+	}
+	return walk[1].Sub(b.player), true
+}
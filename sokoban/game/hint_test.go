@@ -0,0 +1,55 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+func TestHintSolves(t *testing.T) {
+	for _, pack := range packs {
+		for i, l := range pack.Levels {
+			b := NewBoard(l)
+			for steps := 0; !b.Solved(); steps++ {
+				dir, ok := b.Hint()
+				if !ok {
+					t.Fatalf("%s: no hint after %d steps", levelID(pack, i), steps)
+				}
+				if !b.Move(dir) {
+					t.Fatalf("%s: hint %v after %d steps doesn't move", levelID(pack, i), dir, steps)
+				}
+			}
+		}
+	}
+}
+
+func TestHintCounted(t *testing.T) {
+	m := NewModel(80, 24, lipgloss.DefaultRenderer())
+	m.play(0, 0)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.hints.Used != 1 || m.hinted == nil || *m.hinted != grid.Left {
+		t.Fatalf("after a hint: used %d, hinted %v, want 1 and left", m.hints.Used, m.hinted)
+	}
+	if !strings.Contains(m.header(), "Hints 1") {
+		t.Errorf("header %q doesn't count the hint", m.header())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if !m.solved || m.record {
+		t.Fatalf("solved %v, record %v, want solved without a best", m.solved, m.record)
+	}
+	if _, ok := m.best(0, 0); ok {
+		t.Error("a hinted solution was kept as a best")
+	}
+
+	m.play(0, 0)
+	if m.hints.Used != 0 {
+		t.Errorf("hints used %d after a restart, want 0", m.hints.Used)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if !m.record {
+		t.Error("a solution without hints wasn't kept as a best")
+	}
+}
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Undo    key.Binding
+	Hint    key.Binding
+	Restart key.Binding
+	Next    key.Binding
+	Levels  key.Binding
+	Select  key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Undo:     key.NewBinding(key.WithKeys("u", "backspace"), key.WithHelp("u", "undo")),
+		Hint:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "hint")),
+		Restart:  key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restart level")),
+		Next:     key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next level")),
+		Levels:   key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "levels")),
+		Select:   key.NewBinding(key.WithKeys("enter", " ", ui.KEYPADENTER), key.WithHelp("enter", "play")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Undo, k.Hint, k.Restart, k.Levels, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Undo, k.Hint, k.Restart, k.Next},
+		{k.Levels, k.Select, k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":      &k.Up,
+		"down":    &k.Down,
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"undo":    &k.Undo,
+		"hint":    &k.Hint,
+		"restart": &k.Restart,
+		"next":    &k.Next,
+		"levels":  &k.Levels,
+		"select":  &k.Select,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
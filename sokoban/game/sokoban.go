@@ -0,0 +1,433 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/hint"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const LISTED = 10 // levels shown at once in the picker
+
+// Score is a solution's count of moves and pushes. Fewer moves is better,
+// then fewer pushes.
+type Score struct {
+	Moves  int `json:"moves"`
+	Pushes int `json:"pushes"`
+}
+
+func (s Score) beats(t Score) bool {
+	return s.Moves < t.Moves || s.Moves == t.Moves && s.Pushes < t.Pushes
+}
+
+// Save is the player's progress: the level they were on and their best
+// solutions, by levelID.
+type Save struct {
+	Pack  string           `json:"pack"`
+	Level int              `json:"level"`
+	Best  map[string]Score `json:"best"`
+}
+
+// levelID names a level across sessions, even as packs are added.
+func levelID(pack Pack, level int) string {
+	return fmt.Sprintf("%s/%d", pack.Name, level+1)
+}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	WallStyle    lipgloss.Style
+	GoalStyle    lipgloss.Style
+	BoxStyle     lipgloss.Style // of boxes, not of the picker
+	PlacedStyle  lipgloss.Style // boxes on goals
+	PlayerStyle  lipgloss.Style
+	TitleStyle   lipgloss.Style
+	CursorStyle  lipgloss.Style
+	DimStyle     lipgloss.Style
+	QuitStyle    lipgloss.Style
+	OverlayStyle lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Profiles, when set, keeps the player's progress and best solutions
+	// in their save.
+	Profiles    profile.Store
+	Fingerprint string
+	profile     *profile.Profile
+	save        Save
+
+	pack   int // into packs
+	level  int // into the pack's levels
+	board  *Board
+	solved bool
+	record bool // the solution beat the player's best
+
+	// Hints are counted per run of a level. A solution that took any isn't
+	// kept as a best.
+	hints  hint.Counter
+	hinted *grid.Point // the step suggested, until the player moves
+	stuck  bool        // no hint was found
+
+	picking    bool
+	pickPack   int
+	pickCursor int
+
+	showHelp bool
+}
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		WallStyle:   r.NewStyle().Foreground(lipgloss.Color("244")),
+		GoalStyle:   r.NewStyle().Foreground(lipgloss.Color("220")),
+		BoxStyle:    r.NewStyle().Foreground(lipgloss.Color("172")).Bold(true),
+		PlacedStyle: r.NewStyle().Foreground(lipgloss.Color("46")).Bold(true),
+		PlayerStyle: r.NewStyle().Foreground(lipgloss.Color("51")).Bold(true),
+		TitleStyle:  r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		CursorStyle: r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220")),
+		DimStyle:    r.NewStyle().Foreground(lipgloss.Color("8")),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		OverlayStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Left).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:    DefaultKeyMap(),
+		profile: &profile.Profile{},
+		save:    Save{Best: make(map[string]Score)},
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.play(0, 0)
+	return m
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetProfile restores the player's progress from their save, going back to
+// the level they were on, and saves back to store.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+
+	var s Save
+	if ok, err := p.GetSave(GAMENAME, &s); err != nil || !ok {
+		if err != nil {
+			log.Warn("Could not read sokoban save", "err", err)
+		}
+		return
+	}
+	if s.Best == nil {
+		s.Best = make(map[string]Score)
+	}
+	m.save = s
+	for i, pack := range packs {
+		if pack.Name == s.Pack && s.Level >= 0 && s.Level < len(pack.Levels) {
+			m.play(i, s.Level)
+			return
+		}
+	}
+}
+
+func (m *Model) store() {
+	m.save.Pack, m.save.Level = packs[m.pack].Name, m.level
+	if err := m.profile.SetSave(GAMENAME, m.save); err != nil {
+		log.Warn("Could not encode sokoban save", "err", err)
+		return
+	}
+	profile.Save(m.Profiles, m.Fingerprint, m.profile)
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// play starts level of pack over.
+func (m *Model) play(pack, level int) {
+	m.pack, m.level = pack, level
+	m.board = NewBoard(packs[pack].Levels[level])
+	m.solved, m.record = false, false
+	m.hints.Reset()
+	m.hinted, m.stuck = nil, false
+}
+
+// next moves on to the level after this one, going into the next pack
+// after a pack's last, and back to the first after the last pack.
+func (m *Model) next() {
+	pack, level := m.pack, m.level+1
+	if level == len(packs[pack].Levels) {
+		pack, level = (pack+1)%len(packs), 0
+	}
+	m.play(pack, level)
+	m.store()
+}
+
+// best is the player's best solution of level of pack.
+func (m Model) best(pack, level int) (Score, bool) {
+	s, ok := m.save.Best[levelID(packs[pack], level)]
+	return s, ok
+}
+
+func (m *Model) move(dir grid.Point) {
+	m.hinted, m.stuck = nil, false
+	if m.board.Move(dir) && m.board.Solved() {
+		m.finish()
+	}
+}
+
+// finish keeps the solution if it's the player's best and took no hints.
+func (m *Model) finish() {
+	m.solved = true
+	s := Score{Moves: m.board.Moves(), Pushes: m.board.Pushes()}
+	if m.hints.Used > 0 {
+		return
+	}
+	if best, ok := m.best(m.pack, m.level); ok && !s.beats(best) {
+		return
+	}
+	m.record = true
+	m.save.Best[levelID(packs[m.pack], m.level)] = s
+	m.store()
+}
+
+// hint suggests the next step of a shortest solution.
+func (m *Model) hint() {
+	if !m.hints.Take() {
+		return
+	}
+	dir, ok := m.board.Hint()
+	m.hinted, m.stuck = nil, !ok
+	if ok {
+		m.hinted = &dir
+	}
+}
+
+func (m *Model) pick() {
+	m.picking = true
+	m.pickPack, m.pickCursor = m.pack, m.level
+}
+
+func (m *Model) updatePicker(msg tea.KeyMsg) {
+	levels := len(packs[m.pickPack].Levels)
+	switch {
+	case key.Matches(msg, m.Keys.Levels):
+		m.picking = false
+	case key.Matches(msg, m.Keys.Up):
+		m.pickCursor = (m.pickCursor + levels - 1) % levels
+	case key.Matches(msg, m.Keys.Down):
+		m.pickCursor = (m.pickCursor + 1) % levels
+	case key.Matches(msg, m.Keys.Left):
+		m.pickPack = (m.pickPack + len(packs) - 1) % len(packs)
+		m.pickCursor = 0
+	case key.Matches(msg, m.Keys.Right):
+		m.pickPack = (m.pickPack + 1) % len(packs)
+		m.pickCursor = 0
+	case key.Matches(msg, m.Keys.Select):
+		m.picking = false
+		m.play(m.pickPack, m.pickCursor)
+		m.store()
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case m.picking:
+			m.updatePicker(msg)
+		case key.Matches(msg, m.Keys.Levels):
+			m.pick()
+		case key.Matches(msg, m.Keys.Undo):
+			if m.board.Undo() {
+				m.solved, m.record = false, false
+				m.hinted, m.stuck = nil, false
+			}
+		case key.Matches(msg, m.Keys.Restart):
+			m.play(m.pack, m.level)
+		case key.Matches(msg, m.Keys.Next):
+			m.next()
+		case m.solved:
+			if key.Matches(msg, m.Keys.Select) {
+				m.next()
+			}
+		case key.Matches(msg, m.Keys.Hint):
+			m.hint()
+		case key.Matches(msg, m.Keys.Up):
+			m.move(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.move(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.move(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.move(grid.Right)
+		}
+	}
+	return m, nil
+}
+
+// glyphs draw each kind of cell, two columns wide when the level fits the
+// terminal that way, one otherwise.
+var glyphs = map[rune][2]string{
+	WALL:       {"██", "█"},
+	FLOOR:      {"  ", " "},
+	GOAL:       {"()", "."},
+	BOX:        {"[]", "$"},
+	BOXGOAL:    {"[]", "*"},
+	PLAYER:     {"<>", "@"},
+	PLAYERGOAL: {"<>", "+"},
+}
+
+func (m Model) boardView() string {
+	b := m.board
+	narrow := 0
+	if 2*b.Width() > m.Width {
+		narrow = 1
+	}
+	var s strings.Builder
+	for y := 0; y < b.Height(); y++ {
+		if y > 0 {
+			s.WriteString("\n")
+		}
+		for x := 0; x < b.Width(); x++ {
+			p := grid.Point{X: x, Y: y}
+			kind, style := FLOOR, m.DimStyle
+			switch goal := b.goals.At(p); {
+			case b.walls.At(p):
+				kind, style = WALL, m.WallStyle
+			case p == b.player && goal:
+				kind, style = PLAYERGOAL, m.PlayerStyle
+			case p == b.player:
+				kind, style = PLAYER, m.PlayerStyle
+			case b.boxes.At(p) && goal:
+				kind, style = BOXGOAL, m.PlacedStyle
+			case b.boxes.At(p):
+				kind, style = BOX, m.BoxStyle
+			case goal:
+				kind, style = GOAL, m.GoalStyle
+			}
+			s.WriteString(style.Render(glyphs[kind][narrow]))
+		}
+	}
+	return s.String()
+}
+
+func (m Model) header() string {
+	pack := packs[m.pack]
+	parts := []string{
+		m.TitleStyle.Render(fmt.Sprintf("%s %d/%d: %s", pack.Name, m.level+1, len(pack.Levels), pack.Levels[m.level].Title)),
+		fmt.Sprintf("Moves %d", m.board.Moves()),
+		fmt.Sprintf("Pushes %d", m.board.Pushes()),
+	}
+	if best, ok := m.best(m.pack, m.level); ok {
+		parts = append(parts, fmt.Sprintf("Best %d moves, %d pushes", best.Moves, best.Pushes))
+	}
+	if m.hints.Used > 0 {
+		parts = append(parts, fmt.Sprintf("Hints %d", m.hints.Used))
+	}
+	return strings.Join(parts, " | ")
+}
+
+var arrows = map[grid.Point]string{grid.Up: "↑", grid.Down: "↓", grid.Left: "←", grid.Right: "→"}
+
+func (m Model) status() string {
+	if !m.solved {
+		switch {
+		case m.hinted != nil:
+			return "Hint: go " + arrows[*m.hinted]
+		case m.stuck:
+			return fmt.Sprintf("No hint found from here, %s to undo", m.Keys.Undo.Help().Key)
+		}
+		return "Push every box onto a goal"
+	}
+	msg := fmt.Sprintf("Solved! Moves %d, pushes %d.", m.board.Moves(), m.board.Pushes())
+	if m.record {
+		msg += " New best!"
+	} else if m.hints.Used > 0 {
+		msg += " Solve it without hints to keep a best."
+	}
+	return msg + fmt.Sprintf(" Press %s for the next level", m.Keys.Select.Help().Key)
+}
+
+func (m Model) pickerView() string {
+	pack := packs[m.pickPack]
+	lines := []string{
+		m.TitleStyle.Render(fmt.Sprintf("← %s (%d/%d) →", pack.Name, m.pickPack+1, len(packs))),
+		"",
+	}
+	first := max(0, min(m.pickCursor-LISTED/2, len(pack.Levels)-LISTED))
+	for i := first; i < min(first+LISTED, len(pack.Levels)); i++ {
+		line := fmt.Sprintf("  %3d. %-24s", i+1, pack.Levels[i].Title)
+		if best, ok := m.best(m.pickPack, i); ok {
+			line += fmt.Sprintf(" ✓ %4d moves", best.Moves)
+		}
+		if i == m.pickCursor {
+			line = m.CursorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	solved := 0
+	for i := range pack.Levels {
+		if _, ok := m.best(m.pickPack, i); ok {
+			solved++
+		}
+	}
+	lines = append(lines, "",
+		m.DimStyle.Render(fmt.Sprintf("%d of %d solved", solved, len(pack.Levels))),
+		m.DimStyle.Render(fmt.Sprintf("%s to play, %s to go back", m.Keys.Select.Help().Key, m.Keys.Levels.Help().Key)),
+	)
+	return m.OverlayStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.OverlayStyle),
+		)
+	}
+	if m.picking {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.pickerView(),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardView(),
+			"",
+			m.QuitStyle.Render(m.status()),
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
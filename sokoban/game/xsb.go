@@ -0,0 +1,288 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// Packs are text files of levels in the XSB format most Sokoban programs
+// share, a row per line:
+//
+//	; Name of the pack
+//	Title: First steps
+//	#####
+//	#@$.#
+//	#####
+//
+// # are walls, @ the player, $ boxes and . goals, with + and * for the
+// player and a box standing on a goal. Spaces, - and _ are floor. A digit
+// before a symbol repeats it, as in 5#, and | splits a line into rows.
+// Levels are separated by any other line. Title: lines name the level
+// right above them, or else the next one. Lines starting with ; are
+// comments, those before the first level naming the pack.
+//
+//go:embed levels/*.xsb
+var packFiles embed.FS
+
+// EXTENSIONS are the file names LoadDir reads packs from.
+var EXTENSIONS = []string{".xsb", ".sok", ".txt"}
+
+const (
+	MAXWIDTH  = 60 // cells, two columns each on screen when they fit
+	MAXHEIGHT = 30
+)
+
+const (
+	WALL        = '#'
+	FLOOR       = ' '
+	PLAYER      = '@'
+	PLAYERGOAL  = '+'
+	BOX         = '$'
+	BOXGOAL     = '*'
+	GOAL        = '.'
+	ROWSEP      = '|'
+	symbols     = "#@+$*. -_"
+	titlePrefix = "title:"
+)
+
+type Level struct {
+	Title string
+	rows  []string // with floor as FLOOR, padded to the widest
+}
+
+type Pack struct {
+	Name   string
+	Levels []Level
+}
+
+var packs = mustLoad(packFiles)
+
+// mustLoad reads the embedded packs, in file name order.
+func mustLoad(fsys fs.FS) []Pack {
+	paths, err := fs.Glob(fsys, "levels/*.xsb")
+	if err != nil {
+		panic(fmt.Sprintf("sokoban: levels: %v", err))
+	}
+	var ps []Pack
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			panic(fmt.Sprintf("sokoban: %s: %v", p, err))
+		}
+		pack, err := parsePack(data, strings.TrimSuffix(path.Base(p), ".xsb"))
+		if err != nil {
+			panic(fmt.Sprintf("sokoban: %s: %v", p, err))
+		}
+		ps = append(ps, pack)
+	}
+	return ps
+}
+
+// parsePack reads a pack file, named name unless a comment names it.
+func parsePack(data []byte, name string) (Pack, error) {
+	p := Pack{Name: name}
+	named := false
+	var (
+		rows  []string // of the level being read
+		title string   // for the next level
+		// attached is the level a Title: line would name: the one just
+		// read, until a blank line.
+		attached = -1
+	)
+	end := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		l, err := parseLevel(rows)
+		if err != nil {
+			return fmt.Errorf("level %d: %w", len(p.Levels)+1, err)
+		}
+		l.Title, title = title, ""
+		p.Levels = append(p.Levels, l)
+		attached = len(p.Levels) - 1
+		rows = nil
+		return nil
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		row, ok, err := boardLine(line)
+		if err != nil {
+			return p, fmt.Errorf("level %d: %w", len(p.Levels)+1, err)
+		}
+		if ok {
+			rows = append(rows, row...)
+			continue
+		}
+		if err := end(); err != nil {
+			return p, err
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			attached = -1
+		case strings.HasPrefix(trimmed, ";"):
+			if !named && len(p.Levels) == 0 {
+				p.Name, named = strings.TrimSpace(trimmed[1:]), true
+			}
+		case len(trimmed) >= len(titlePrefix) && strings.EqualFold(trimmed[:len(titlePrefix)], titlePrefix):
+			t := strings.TrimSpace(trimmed[len(titlePrefix):])
+			if attached >= 0 && p.Levels[attached].Title == "" {
+				p.Levels[attached].Title = t
+			} else {
+				title = t
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return p, err
+	}
+	if err := end(); err != nil {
+		return p, err
+	}
+	if len(p.Levels) == 0 {
+		return p, fmt.Errorf("no levels")
+	}
+	for i := range p.Levels {
+		if p.Levels[i].Title == "" {
+			p.Levels[i].Title = fmt.Sprintf("Level %d", i+1)
+		}
+	}
+	return p, nil
+}
+
+// boardLine expands a line of a level into its rows, reporting false if
+// it isn't one. Rows need a wall, so lines of plain text don't pass. A
+// repeat wider than any level fits is an error, rather than a row that
+// takes the server's memory; only ASCII digits count.
+func boardLine(line string) ([]string, bool, error) {
+	if !strings.ContainsRune(line, WALL) {
+		return nil, false, nil
+	}
+	var rows []string
+	var row strings.Builder
+	repeat := 0
+	wide := false // whether a repeat was wider than any level fits
+	for _, c := range line {
+		switch {
+		case c >= '0' && c <= '9':
+			repeat = min(repeat*10+int(c-'0'), MAXWIDTH+1)
+		case c == ROWSEP:
+			rows = append(rows, row.String())
+			row.Reset()
+			repeat = 0
+		case strings.ContainsRune(symbols, c):
+			if c == '-' || c == '_' {
+				c = FLOOR
+			}
+			if repeat > MAXWIDTH {
+				wide, repeat = true, 1
+			}
+			row.WriteString(strings.Repeat(string(c), max(1, repeat)))
+			repeat = 0
+		default:
+			return nil, false, nil
+		}
+	}
+	rows = append(rows, row.String())
+	for _, r := range rows {
+		if !strings.ContainsRune(r, WALL) {
+			return nil, false, nil
+		}
+	}
+	if wide {
+		return nil, false, fmt.Errorf("a repeat of more than %d cells", MAXWIDTH)
+	}
+	return rows, true, nil
+}
+
+// parseLevel checks a level's rows: one player, as many goals as boxes,
+// and something left to push.
+func parseLevel(rows []string) (Level, error) {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(strings.TrimRight(r, " ")))
+	}
+	switch {
+	case width > MAXWIDTH:
+		return Level{}, fmt.Errorf("%d cells wide, at most %d fit", width, MAXWIDTH)
+	case len(rows) > MAXHEIGHT:
+		return Level{}, fmt.Errorf("%d rows, at most %d fit", len(rows), MAXHEIGHT)
+	}
+
+	players, boxes, goals, placed := 0, 0, 0, 0
+	l := Level{rows: make([]string, len(rows))}
+	for y, r := range rows {
+		r = strings.TrimRight(r, " ")
+		for _, c := range r {
+			switch c {
+			case PLAYER:
+				players++
+			case PLAYERGOAL:
+				players++
+				goals++
+			case BOX:
+				boxes++
+			case BOXGOAL:
+				boxes++
+				goals++
+				placed++
+			case GOAL:
+				goals++
+			}
+		}
+		l.rows[y] = r + strings.Repeat(" ", width-len(r))
+	}
+	switch {
+	case players != 1:
+		return l, fmt.Errorf("%d players, want 1", players)
+	case boxes == 0:
+		return l, fmt.Errorf("no boxes")
+	case boxes != goals:
+		return l, fmt.Errorf("%d boxes for %d goals", boxes, goals)
+	case placed == boxes:
+		return l, fmt.Errorf("already solved")
+	}
+	return l, nil
+}
+
+// LoadDir adds every pack in dir, one file each with any of EXTENSIONS,
+// after the built-in ones, in file name order. Invalid packs are skipped.
+// Call it before serving.
+func LoadDir(dir string) error {
+	var paths []string
+	for _, ext := range EXTENSIONS {
+		matches, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	slices.Sort(paths)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			log.Warn("Skipping Sokoban pack", "path", p, "err", err)
+			continue
+		}
+		pack, err := parsePack(data, strings.TrimSuffix(filepath.Base(p), filepath.Ext(p)))
+		if err != nil {
+			log.Warn("Skipping Sokoban pack", "path", p, "err", err)
+			continue
+		}
+		packs = append(packs, pack)
+		log.Info("Loaded Sokoban pack", "path", p, "name", pack.Name, "levels", len(pack.Levels))
+	}
+	return nil
+}
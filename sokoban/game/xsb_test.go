@@ -0,0 +1,38 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBoardLine(t *testing.T) {
+	for _, tc := range []struct {
+		line string
+		rows []string
+		ok   bool
+	}{
+		{"5#", []string{"#####"}, true},
+		{"#@2-$.#|3#", []string{"#@  $.#", "###"}, true},
+		{"60#", []string{strings.Repeat("#", 60)}, true},
+		// Digits of other scripts aren't repeats.
+		{"٣#", nil, false},
+		{"５#", nil, false},
+		{"2024 #1 pack", nil, false},
+	} {
+		rows, ok, err := boardLine(tc.line)
+		if err != nil || ok != tc.ok || strings.Join(rows, "|") != strings.Join(tc.rows, "|") {
+			t.Errorf("boardLine(%q) = %q, %v, %v; want %q, %v", tc.line, rows, ok, err, tc.rows, tc.ok)
+		}
+	}
+}
+
+func TestOversizedRepeat(t *testing.T) {
+	for _, line := range []string{"61#", "99999999999999999999999#", "#@$.#|1000000000-#"} {
+		if _, _, err := boardLine(line); err == nil {
+			t.Errorf("boardLine(%q) expanded a repeat wider than %d", line, MAXWIDTH)
+		}
+	}
+	if _, err := parsePack([]byte("#####\n#@$.#\n100000000#\n"), "huge"); err == nil {
+		t.Error("parsePack() took a level with an oversized repeat")
+	}
+}
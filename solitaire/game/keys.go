@@ -0,0 +1,74 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Place   key.Binding
+	Draw    key.Binding
+	Home    key.Binding
+	Undo    key.Binding
+	Mode    key.Binding
+	Restart key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Place:    key.NewBinding(key.WithKeys(" ", "enter", ui.KEYPADENTER), key.WithHelp("space", "pick up/put down")),
+		Draw:     key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "draw")),
+		Home:     key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "to foundation")),
+		Undo:     key.NewBinding(key.WithKeys("u", "backspace"), key.WithHelp("u", "undo")),
+		Mode:     key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "draw 1/3")),
+		Restart:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new deal")),
+		Layout:   ui.LayoutKey(),
+		Help:     ui.HelpKey(),
+		Quit:     ui.QuitKeyFor(l),
+		layout:   l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Place, k.Draw, k.Home, k.Undo, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		{k.Place, k.Draw, k.Home, k.Undo},
+		{k.Mode, k.Restart},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":      &k.Up,
+		"down":    &k.Down,
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"place":   &k.Place,
+		"draw":    &k.Draw,
+		"home":    &k.Home,
+		"undo":    &k.Undo,
+		"mode":    &k.Mode,
+		"restart": &k.Restart,
+		"layout":  &k.Layout,
+		"help":    &k.Help,
+		"quit":    &k.Quit,
+	}
+}
@@ -0,0 +1,552 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	CARDWIDTH = 5
+	GAP       = 1
+	FANWIDTH  = 3 // of the waste cards under the top one, drawing three
+	NOPILE    = -1
+
+	// The waste takes two columns, so three drawn cards fit side by side.
+	wasteWidth = 2*CARDWIDTH + GAP
+	tableWidth = COLUMNS*CARDWIDTH + (COLUMNS-1)*GAP
+)
+
+// topRow is the order the cursor visits the piles above the tableau.
+var topRow = []int{STOCK, WASTE, FOUNDATION, FOUNDATION + 1, FOUNDATION + 2, FOUNDATION + 3}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	CardStyle   lipgloss.Style
+	RedStyle    lipgloss.Style
+	BackStyle   lipgloss.Style
+	SlotStyle   lipgloss.Style
+	CursorStyle lipgloss.Style // background only, over the card's own
+	HeldStyle   lipgloss.Style // likewise, for the cards picked up
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// profile, when set, gets every finished deal, won or not, by draw mode,
+	// saved back to Profiles.
+	profile     *profile.Profile
+	Profiles    profile.Store
+	Fingerprint string
+
+	table    Table
+	history  []Table // before each move, for undo
+	draw     int
+	moves    int
+	cursor   int // pile
+	depth    int // card of a tableau pile the cursor is on
+	held     int // pile the picked up cards come from, NOPILE for none
+	heldAt   int // the first of them
+	won      bool
+	recorded bool // whether the deal is in the stats yet
+	showHelp bool
+
+	rng *rand.Rand
+}
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		CardStyle:   r.NewStyle().Background(lipgloss.Color("255")).Foreground(lipgloss.Color("0")),
+		RedStyle:    r.NewStyle().Background(lipgloss.Color("255")).Foreground(lipgloss.Color("160")),
+		BackStyle:   r.NewStyle().Background(lipgloss.Color("25")).Foreground(lipgloss.Color("33")),
+		SlotStyle:   r.NewStyle().Foreground(lipgloss.Color("240")),
+		CursorStyle: r.NewStyle().Background(lipgloss.Color("220")),
+		HeldStyle:   r.NewStyle().Background(lipgloss.Color("153")),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		draw: 1,
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext counts a deal still going when ctx ends, usually the SSH
+// session's, as lost.
+func (m *Model) SetContext(ctx context.Context) {
+	context.AfterFunc(ctx, func() { m.record(false) })
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetProfile applies a player's key bindings and keeps their deals in its
+// stats, saving it back to store if set.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+	ui.Rebind(&m.Keys, p.Keys)
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// statsKey is the game the profile keeps deals under, one per draw mode.
+func (m Model) statsKey() string {
+	return fmt.Sprintf("%s-draw%d", GAMENAME, m.draw)
+}
+
+// record adds the deal to the player's stats once, a win scoring 1. Deals
+// left before the first move don't count.
+func (m *Model) record(won bool) {
+	if m.profile == nil || m.recorded || m.moves == 0 {
+		return
+	}
+	m.recorded = true
+	score := 0
+	if won {
+		score = 1
+	}
+	m.profile.Record(m.statsKey(), score)
+	profile.Save(m.Profiles, m.Fingerprint, m.profile)
+}
+
+// Restart deals anew, counting the deal it leaves as lost.
+func (m *Model) Restart() {
+	m.record(false)
+	m.table = deal(m.rng, m.draw)
+	m.history = nil
+	m.moves = 0
+	m.cursor, m.depth = TABLEAU, 0
+	m.held = NOPILE
+	m.won, m.recorded = false, false
+	m.clampDepth()
+}
+
+// toggleDraw switches between drawing one card and three, dealing anew.
+func (m *Model) toggleDraw() {
+	m.record(false)
+	m.draw = 4 - m.draw
+	m.Restart()
+}
+
+// do plays a move on the table, keeping the table as it was for undo when
+// it's allowed.
+func (m *Model) do(move func(t *Table) bool) {
+	before := m.table.clone()
+	if !move(&m.table) {
+		return
+	}
+	m.history = append(m.history, before)
+	m.moves++
+	m.clampDepth()
+	if m.table.won() {
+		m.won = true
+		m.held = NOPILE
+		m.record(true)
+	}
+}
+
+// Undo takes back the last move. It counts as one more move.
+func (m *Model) Undo() {
+	if len(m.history) == 0 || m.won {
+		return
+	}
+	m.table = m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	m.moves++
+	m.held = NOPILE
+	m.clampDepth()
+}
+
+// index is the card of pile the cursor would pick up: its top card, or
+// the one under the cursor in the tableau.
+func (m Model) index(pile int) int {
+	if isTableau(pile) && pile == m.cursor {
+		return m.depth
+	}
+	return len(m.table.Piles[pile]) - 1
+}
+
+// place picks up the cards under the cursor or puts the ones held down on
+// its pile. Putting them back where they came from, or anywhere they don't
+// go, lets go of them. On the stock it draws.
+func (m *Model) place() {
+	if m.held != NOPILE {
+		from, at := m.held, m.heldAt
+		m.held = NOPILE
+		m.do(func(t *Table) bool { return t.move(from, at, m.cursor) })
+		return
+	}
+	if m.cursor == STOCK {
+		m.do((*Table).turn)
+		return
+	}
+	if i := m.index(m.cursor); m.table.movable(m.cursor, i) {
+		m.held, m.heldAt = m.cursor, i
+	}
+}
+
+// home sends the top card of pile to its foundation.
+func (m *Model) home(pile int) {
+	m.held = NOPILE
+	m.do(func(t *Table) bool { return t.home(pile) })
+}
+
+// firstUp is the first face-up card of a tableau pile.
+func (m Model) firstUp(pile int) int {
+	p := m.table.Piles[pile]
+	for i, c := range p {
+		if c.Up {
+			return i
+		}
+	}
+	return len(p)
+}
+
+// clampDepth keeps the cursor on a face-up card of its tableau pile.
+func (m *Model) clampDepth() {
+	if !isTableau(m.cursor) {
+		return
+	}
+	last := len(m.table.Piles[m.cursor]) - 1
+	m.depth = max(0, min(max(m.depth, m.firstUp(m.cursor)), last))
+}
+
+// column is where a pile is drawn, in card widths from the left.
+func column(pile int) int {
+	switch {
+	case pile == STOCK:
+		return 0
+	case pile == WASTE:
+		return 1
+	case isFoundation(pile):
+		return 3 + pile - FOUNDATION
+	}
+	return pile - TABLEAU
+}
+
+// above is the pile of the top row over a tableau column. The waste is two
+// columns wide.
+func above(col int) int {
+	switch col {
+	case 0:
+		return STOCK
+	case 1, 2:
+		return WASTE
+	}
+	return FOUNDATION + col - 3
+}
+
+func (m *Model) moveCursor(dx, dy int) {
+	switch {
+	case dx != 0 && isTableau(m.cursor):
+		m.cursor = TABLEAU + (column(m.cursor)+dx+COLUMNS)%COLUMNS
+		m.depth = len(m.table.Piles[m.cursor])
+	case dx != 0:
+		for i, pile := range topRow {
+			if pile == m.cursor {
+				m.cursor = topRow[(i+dx+len(topRow))%len(topRow)]
+				break
+			}
+		}
+	case dy < 0 && isTableau(m.cursor) && m.depth > m.firstUp(m.cursor):
+		m.depth--
+	case dy < 0 && isTableau(m.cursor):
+		m.cursor = above(column(m.cursor))
+	case dy > 0 && isTableau(m.cursor):
+		m.depth++
+	case dy > 0:
+		m.cursor = TABLEAU + column(m.cursor)
+		m.depth = len(m.table.Piles[m.cursor])
+	}
+	m.clampDepth()
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.record(false)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case key.Matches(msg, m.Keys.Mode):
+			m.toggleDraw()
+		case m.won:
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(0, -1)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(0, 1)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(-1, 0)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(1, 0)
+		case key.Matches(msg, m.Keys.Place):
+			m.place()
+		case key.Matches(msg, m.Keys.Draw):
+			m.held = NOPILE
+			m.do((*Table).turn)
+		case key.Matches(msg, m.Keys.Home):
+			m.home(m.cursor)
+		case key.Matches(msg, m.Keys.Undo):
+			m.Undo()
+		}
+	case tea.MouseMsg:
+		m.updateMouse(msg)
+	}
+	return m, nil
+}
+
+// updateMouse picks up and puts down cards where the left button clicks,
+// like place under the cursor, and sends the card clicked with the right
+// to its foundation. A click deals anew once the game is won.
+func (m *Model) updateMouse(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress || m.showHelp {
+		return
+	}
+	if m.won {
+		if msg.Button == tea.MouseButtonLeft {
+			m.Restart()
+		}
+		return
+	}
+	pile, i, ok := m.cardAt(msg.X, msg.Y)
+	if !ok {
+		return
+	}
+	m.cursor, m.depth = pile, i
+	m.clampDepth()
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		m.place()
+	case tea.MouseButtonRight:
+		m.home(pile)
+	}
+}
+
+// cardAt finds the pile and card at screen column x and row y, retracing
+// how View centers the table. Clicks under a tableau pile land on its last
+// card.
+func (m Model) cardAt(x, y int) (pile, i int, ok bool) {
+	body := m.body()
+	w, h := lipgloss.Width(body), lipgloss.Height(body)
+	left := max(0, m.Width-w)/2 + (w-tableWidth+1)/2
+	top := max(0, m.Height-h)/2 + 2 // under the header and a blank line
+	x, y = x-left, y-top
+	if x < 0 || y < 0 || x >= tableWidth || y == 1 {
+		return 0, 0, false
+	}
+	col := x / (CARDWIDTH + GAP)
+	if y == 0 {
+		pile = above(col)
+		return pile, len(m.table.Piles[pile]) - 1, true
+	}
+	pile = TABLEAU + col
+	return pile, min(y-2, len(m.table.Piles[pile])-1), true
+}
+
+// cardView draws c, or its back while it's face down, over the background
+// of highlight if set.
+func (m Model) cardView(c Card, width int, highlight *lipgloss.Style) string {
+	style, label := m.BackStyle, strings.Repeat("░", width)
+	if c.Up {
+		style, label = m.CardStyle, c.String()
+		if c.red() {
+			style = m.RedStyle
+		}
+	}
+	if highlight != nil {
+		style = style.Background(highlight.GetBackground())
+	}
+	return style.Width(width).Align(lipgloss.Center).Render(label)
+}
+
+// slotView draws an empty pile, with label in it.
+func (m Model) slotView(label string, cursor bool) string {
+	style := m.SlotStyle
+	if cursor {
+		style = style.Background(m.CursorStyle.GetBackground()).Foreground(lipgloss.Color("0"))
+	}
+	return style.Width(CARDWIDTH).Render("[" + fmt.Sprintf("%-3s", label) + "]")
+}
+
+// highlight is the background a card of pile at index i gets, if any.
+func (m Model) highlight(pile, i int) *lipgloss.Style {
+	switch {
+	case m.won:
+	case pile == m.held && i >= m.heldAt:
+		return &m.HeldStyle
+	case pile == m.cursor && i == m.index(pile):
+		return &m.CursorStyle
+	}
+	return nil
+}
+
+// pileView draws the top card of a pile above the tableau.
+func (m Model) pileView(pile int, empty string) string {
+	p := m.table.Piles[pile]
+	if len(p) == 0 {
+		return m.slotView(empty, pile == m.cursor && !m.won)
+	}
+	return m.cardView(p[len(p)-1], CARDWIDTH, m.highlight(pile, len(p)-1))
+}
+
+// wasteView fans the cards drawn last, when drawing three, left of the one
+// on top.
+func (m Model) wasteView() string {
+	waste := m.table.Piles[WASTE]
+	var s strings.Builder
+	if m.draw > 1 && len(waste) > 0 {
+		for _, c := range waste[max(0, len(waste)-m.draw) : len(waste)-1] {
+			s.WriteString(m.cardView(c, FANWIDTH, nil))
+		}
+	}
+	s.WriteString(m.pileView(WASTE, ""))
+	return s.String() + strings.Repeat(" ", wasteWidth-lipgloss.Width(s.String()))
+}
+
+func (m Model) tableView() string {
+	gap := strings.Repeat(" ", GAP)
+	stockLabel := ""
+	if len(m.table.Piles[WASTE]) > 0 {
+		stockLabel = " ↺"
+	}
+	row := []string{m.pileView(STOCK, stockLabel), gap, m.wasteView()}
+	for f := FOUNDATION; f < TABLEAU; f++ {
+		row = append(row, gap, m.pileView(f, ""))
+	}
+	lines := []string{strings.Join(row, ""), ""}
+
+	rows := 1
+	for col := 0; col < COLUMNS; col++ {
+		rows = max(rows, len(m.table.Piles[TABLEAU+col]))
+	}
+	for r := 0; r < rows; r++ {
+		var s strings.Builder
+		for col := 0; col < COLUMNS; col++ {
+			if col > 0 {
+				s.WriteString(gap)
+			}
+			pile := TABLEAU + col
+			p := m.table.Piles[pile]
+			switch {
+			case r < len(p):
+				s.WriteString(m.cardView(p[r], CARDWIDTH, m.highlight(pile, r)))
+			case r == 0:
+				s.WriteString(m.slotView("", pile == m.cursor && !m.won))
+			default:
+				s.WriteString(strings.Repeat(" ", CARDWIDTH))
+			}
+		}
+		lines = append(lines, s.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// standing is the player's record in the draw mode, when they have a
+// profile.
+func (m Model) standing() string {
+	if m.profile == nil {
+		return ""
+	}
+	s := m.profile.Stats[m.statsKey()]
+	if s.Played == 0 {
+		return "No deals finished yet"
+	}
+	return fmt.Sprintf("Won %d of %d (%d%%)", s.Total, s.Played, 100*s.Total/s.Played)
+}
+
+func (m Model) header() string {
+	header := fmt.Sprintf("Klondike, draw %d | Moves: %d", m.draw, m.moves)
+	if s := m.standing(); s != "" {
+		header += " | " + s
+	}
+	return header
+}
+
+func (m Model) status() string {
+	if m.held == NOPILE {
+		return m.QuitStyle.Render(fmt.Sprintf("%d in the stock", len(m.table.Piles[STOCK])))
+	}
+	cards := m.table.Piles[m.held][m.heldAt:]
+	status := "Holding " + cards[0].String()
+	if len(cards) > 1 {
+		status += fmt.Sprintf(" and %d more", len(cards)-1)
+	}
+	return m.QuitStyle.Render(status + ", " + m.Keys.Place.Help().Key + " to put down")
+}
+
+func (m Model) wonView() string {
+	lines := []string{"You won!", fmt.Sprintf("in %d moves", m.moves)}
+	if s := m.standing(); s != "" {
+		lines = append(lines, s)
+	}
+	lines = append(lines, "", fmt.Sprintf("'%s' new deal • '%s' draw %d", m.Keys.Restart.Help().Key, m.Keys.Mode.Help().Key, 4-m.draw))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+// body is the table with what goes around it, as View centers it.
+func (m Model) body() string {
+	bottom := m.status()
+	if m.won {
+		bottom = m.wonView()
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Center,
+		m.header(),
+		"",
+		m.tableView(),
+		"",
+		bottom,
+		m.help.ShortHelpView(m.Keys.ShortHelp()),
+	)
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		m.body(),
+	)
+}
@@ -0,0 +1,218 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+)
+
+const (
+	RANKS   = 13
+	SUITS   = 4
+	COLUMNS = 7 // tableau piles
+)
+
+// Suits, the red ones in the middle
+const (
+	SPADES = iota
+	HEARTS
+	DIAMONDS
+	CLUBS
+)
+
+var (
+	suitGlyphs = [SUITS]string{"♠", "♥", "♦", "♣"}
+	rankLabels = [RANKS + 1]string{"", "A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+)
+
+type Card struct {
+	Rank int // 1 for aces to RANKS for kings
+	Suit int
+	Up   bool
+}
+
+func (c Card) red() bool {
+	return c.Suit == HEARTS || c.Suit == DIAMONDS
+}
+
+func (c Card) String() string {
+	return rankLabels[c.Rank] + suitGlyphs[c.Suit]
+}
+
+// Piles, in the order the cursor visits them
+const (
+	STOCK = iota
+	WASTE
+	FOUNDATION                      // the first of SUITS foundations
+	TABLEAU    = FOUNDATION + SUITS // the first of COLUMNS tableau piles
+	PILES      = TABLEAU + COLUMNS
+)
+
+// Table is a game of Klondike as it stands. Piles keep their top card last.
+type Table struct {
+	Piles [PILES][]Card
+	Draw  int // cards turned from the stock at a time, 1 or 3
+}
+
+// deal shuffles a deck out onto a new table: a pile more per tableau
+// column, only its top card face up, and the rest in the stock.
+func deal(rng *rand.Rand, draw int) Table {
+	deck := make([]Card, 0, RANKS*SUITS)
+	for suit := 0; suit < SUITS; suit++ {
+		for rank := 1; rank <= RANKS; rank++ {
+			deck = append(deck, Card{Rank: rank, Suit: suit})
+		}
+	}
+	rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+
+	t := Table{Draw: draw}
+	for col := 0; col < COLUMNS; col++ {
+		pile := deck[: col+1 : col+1]
+		deck = deck[col+1:]
+		pile[col].Up = true
+		t.Piles[TABLEAU+col] = pile
+	}
+	t.Piles[STOCK] = deck
+	return t
+}
+
+func (t Table) clone() Table {
+	for i := range t.Piles {
+		t.Piles[i] = slices.Clone(t.Piles[i])
+	}
+	return t
+}
+
+func isFoundation(pile int) bool {
+	return pile >= FOUNDATION && pile < TABLEAU
+}
+
+func isTableau(pile int) bool {
+	return pile >= TABLEAU
+}
+
+// top is the last card of a pile, if any.
+func (t Table) top(pile int) (Card, bool) {
+	p := t.Piles[pile]
+	if len(p) == 0 {
+		return Card{}, false
+	}
+	return p[len(p)-1], true
+}
+
+// turn deals cards from the stock onto the waste or, once it's empty,
+// turns the waste back over into the stock. It reports whether anything
+// moved.
+func (t *Table) turn() bool {
+	stock, waste := t.Piles[STOCK], t.Piles[WASTE]
+	if len(stock) == 0 {
+		if len(waste) == 0 {
+			return false
+		}
+		for i := len(waste) - 1; i >= 0; i-- {
+			c := waste[i]
+			c.Up = false
+			stock = append(stock, c)
+		}
+		t.Piles[STOCK], t.Piles[WASTE] = stock, nil
+		return true
+	}
+	for i := 0; i < t.Draw && len(stock) > 0; i++ {
+		c := stock[len(stock)-1]
+		c.Up = true
+		stock = stock[:len(stock)-1]
+		waste = append(waste, c)
+	}
+	t.Piles[STOCK], t.Piles[WASTE] = stock, waste
+	return true
+}
+
+// movable reports whether the cards of pile from index i up can be picked
+// up together: a single card off the waste or a foundation, or a face-up
+// run of a tableau column.
+func (t Table) movable(pile, i int) bool {
+	p := t.Piles[pile]
+	switch {
+	case i < 0 || i >= len(p) || !p[i].Up:
+		return false
+	case pile == STOCK:
+		return false
+	case !isTableau(pile):
+		return i == len(p)-1
+	}
+	for j := i + 1; j < len(p); j++ {
+		if !stacks(p[j-1], p[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// stacks tells whether c may go on top of under in the tableau: a rank
+// lower and the other color.
+func stacks(under, c Card) bool {
+	return under.Up && under.Rank == c.Rank+1 && under.red() != c.red()
+}
+
+// accepts reports whether cards, the bottom one first, may be put on pile.
+func (t Table) accepts(pile int, cards []Card) bool {
+	if len(cards) == 0 {
+		return false
+	}
+	c := cards[0]
+	top, ok := t.top(pile)
+	switch {
+	case isFoundation(pile):
+		if len(cards) > 1 {
+			return false
+		}
+		if !ok {
+			return c.Rank == 1
+		}
+		return top.Suit == c.Suit && top.Rank+1 == c.Rank
+	case isTableau(pile):
+		if !ok {
+			return c.Rank == RANKS
+		}
+		return stacks(top, c)
+	}
+	return false
+}
+
+// move takes the cards of pile from index i up onto to, if the rules allow
+// it, turning the card they uncover face up.
+func (t *Table) move(from, i, to int) bool {
+	if from == to || !t.movable(from, i) {
+		return false
+	}
+	cards := t.Piles[from][i:]
+	if !t.accepts(to, cards) {
+		return false
+	}
+	t.Piles[to] = append(t.Piles[to], cards...)
+	t.Piles[from] = t.Piles[from][:i:i]
+	if n := len(t.Piles[from]); n > 0 && isTableau(from) {
+		t.Piles[from][n-1].Up = true
+	}
+	return true
+}
+
+// home moves the top card of pile onto the foundation that takes it, if
+// any.
+func (t *Table) home(pile int) bool {
+	i := len(t.Piles[pile]) - 1
+	for f := FOUNDATION; f < TABLEAU; f++ {
+		if t.move(pile, i, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Table) won() bool {
+	for f := FOUNDATION; f < TABLEAU; f++ {
+		if len(t.Piles[f]) < RANKS {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package spectate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const HISTORY = 40 // score samples shown in the caster's graph
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Competitor is one side of a match as casters see it.
+type Competitor struct {
+	Name    string
+	Rating  int
+	Score   int
+	History []int // score over time, oldest first
+}
+
+// Match is the state of a match, published next to its frames. Round is
+// zero for matches that aren't played in rounds.
+type Match struct {
+	Title     string
+	Round     int
+	Rounds    int
+	RoundEnds time.Time
+	Players   []Competitor
+}
+
+// Scoreboard is implemented by games whose matches casters can follow. The
+// wrapping Model publishes what it returns with every frame, once ok.
+type Scoreboard interface {
+	Scoreboard() (m Match, ok bool)
+}
+
+// Track sets the players' scores, adding each to its player's history when
+// it changed. Players are added as scores come for them.
+func (m *Match) Track(scores ...int) {
+	for len(m.Players) < len(scores) {
+		m.Players = append(m.Players, Competitor{})
+	}
+	for i, score := range scores {
+		p := &m.Players[i]
+		p.Score = score
+		if n := len(p.History); n == 0 || p.History[n-1] != score {
+			p.History = append(p.History[max(0, n+1-HISTORY):], score)
+		}
+	}
+}
+
+func (m Match) equal(o Match) bool {
+	return m.Title == o.Title && m.Round == o.Round && m.Rounds == o.Rounds && m.RoundEnds.Equal(o.RoundEnds) &&
+		slices.EqualFunc(m.Players, o.Players, func(a, b Competitor) bool {
+			return a.Name == b.Name && a.Rating == b.Rating && a.Score == b.Score && slices.Equal(a.History, b.History)
+		})
+}
+
+// PublishMatch records the match state. It's delayed like the frames, so
+// the overlay never runs ahead of the board.
+func (b *Broadcast) PublishMatch(m Match) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n := len(b.matches.values); n > 0 && b.matches.values[n-1].value.equal(m) {
+		return
+	}
+
+	m.Players = slices.Clone(m.Players)
+	for i := range m.Players {
+		p := &m.Players[i]
+		p.History = slices.Clone(p.History[max(0, len(p.History)-HISTORY):])
+	}
+	b.matches.push(time.Now(), &m, b.delay)
+}
+
+// Match returns the match state spectators are due to see, if any.
+func (b *Broadcast) Match() (Match, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.matches.ready(time.Now(), b.delay)
+	if !ok {
+		return Match{}, false
+	}
+	return *m, true
+}
+
+// Caster is a spectator view for streaming matches: the board framed by
+// both players' names and ratings, the round timer and a graph of how the
+// score evolved. Games that aren't a Scoreboard show as in a Viewer.
+type Caster struct {
+	Viewer
+	match   Match
+	matched bool
+	// shown is when the frame was published, so the round timer reads what
+	// it did then rather than now.
+	shown time.Time
+
+	Accent lipgloss.Style
+	Dim    lipgloss.Style
+}
+
+func Cast(b *Broadcast, r *lipgloss.Renderer) Caster {
+	return Caster{
+		Viewer: Watch(b),
+		Accent: r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		Dim:    r.NewStyle().Foreground(lipgloss.Color("8")),
+	}
+}
+
+func (c Caster) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(pollMsg); ok {
+		c.match, c.matched = c.broadcast.Match()
+		c.shown = time.Now().Add(-c.broadcast.Delay())
+	}
+	v, cmd := c.Viewer.Update(msg)
+	c.Viewer = v.(Viewer)
+	return c, cmd
+}
+
+func (c Caster) View() string {
+	if !c.matched {
+		return c.Viewer.View()
+	}
+	m := c.match
+
+	var header []string
+	for i, p := range m.Players {
+		if i > 0 {
+			header = append(header, c.Dim.Render("vs"))
+		}
+		name := c.Accent.Render(p.Name)
+		if p.Rating > 0 {
+			name += " " + c.Dim.Render(fmt.Sprintf("(%d)", p.Rating))
+		}
+		header = append(header, name+" "+c.Accent.Render(fmt.Sprint(p.Score)))
+	}
+
+	var round []string
+	if m.Round > 0 {
+		r := fmt.Sprintf("Round %d", m.Round)
+		if m.Rounds > 0 {
+			r += fmt.Sprintf("/%d", m.Rounds)
+		}
+		round = append(round, r)
+	}
+	if !m.RoundEnds.IsZero() {
+		left := max(0, m.RoundEnds.Sub(c.shown)).Round(time.Second)
+		round = append(round, fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60))
+	}
+
+	var s strings.Builder
+	if m.Title != "" {
+		s.WriteString(c.Accent.Render(m.Title) + "\n")
+	}
+	s.WriteString(strings.Join(header, "  ") + "\n")
+	if len(round) > 0 {
+		s.WriteString(c.Dim.Render(strings.Join(round, " • ")) + "\n")
+	}
+	s.WriteString("\n")
+	if c.live {
+		s.WriteString(c.view + "\n\n")
+	}
+	s.WriteString(c.graph())
+	return s.String()
+}
+
+// graph draws every player's score history as a sparkline on a shared scale.
+func (c Caster) graph() string {
+	top := 1
+	width := 0
+	for _, p := range c.match.Players {
+		for _, v := range p.History {
+			top = max(top, v)
+		}
+		width = max(width, lipgloss.Width(p.Name))
+	}
+
+	var s strings.Builder
+	for _, p := range c.match.Players {
+		line := make([]rune, len(p.History))
+		for i, v := range p.History {
+			line[i] = sparks[max(0, v)*(len(sparks)-1)/top]
+		}
+		fmt.Fprintf(&s, "%-*s %s\n", width, p.Name, c.Accent.Render(string(line)))
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
@@ -0,0 +1,65 @@
+package spectate
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTrack(t *testing.T) {
+	var m Match
+	m.Track(0, 0)
+	m.Track(0, 0)
+	m.Track(1, 0)
+	m.Track(1, 2)
+	if len(m.Players) != 2 {
+		t.Fatalf("%d players tracked, want 2", len(m.Players))
+	}
+	if got := m.Players[0].History; !slices.Equal(got, []int{0, 1}) {
+		t.Errorf("history %v, want each change once", got)
+	}
+	if m.Players[1].Score != 2 {
+		t.Errorf("score %d, want the last tracked", m.Players[1].Score)
+	}
+	for i := range 2 * HISTORY {
+		m.Track(i, 0)
+	}
+	if n := len(m.Players[0].History); n != HISTORY {
+		t.Errorf("history of %d scores, want the last %d", n, HISTORY)
+	}
+}
+
+// scored is a game with a scoreboard.
+type scored struct{ match Match }
+
+func (scored) Init() tea.Cmd                         { return nil }
+func (s scored) Update(tea.Msg) (tea.Model, tea.Cmd) { return s, nil }
+func (scored) View() string                          { return "board" }
+func (s scored) Scoreboard() (Match, bool)           { return s.match, true }
+
+func TestWrapPublishesScoreboard(t *testing.T) {
+	var match Match
+	match.Title = "Duel"
+	match.Track(3, 5)
+	match.Players[0].Name, match.Players[1].Name = "alice", "bob"
+
+	b := NewBroadcast(0)
+	Wrap(scored{match}, b).View()
+	got, ok := b.Match()
+	if !ok || !got.equal(match) {
+		t.Fatalf("Match() = %+v, %v, want the game's scoreboard", got, ok)
+	}
+
+	c := Cast(b, lipgloss.NewRenderer(io.Discard))
+	v, _ := c.Update(pollMsg{})
+	view := v.View()
+	for _, want := range []string{"Duel", "alice", "bob", "board"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("caster view lacks %q:\n%s", want, view)
+		}
+	}
+}
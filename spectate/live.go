@@ -0,0 +1,82 @@
+package spectate
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/observe"
+)
+
+// Session is a game in progress that others can watch.
+type Session struct {
+	ID        int
+	Game      string // title
+	Player    string
+	Started   time.Time
+	Broadcast *Broadcast
+}
+
+// Directory lists the sessions open to spectators.
+type Directory struct {
+	// Feed, when set, is told about sessions starting and ending.
+	Feed *observe.Feed
+
+	mu       sync.Mutex
+	next     int
+	sessions map[int]Session
+}
+
+func NewDirectory() *Directory {
+	return &Directory{sessions: make(map[int]Session)}
+}
+
+// Open lists player's session of game, returning its ID for Close.
+func (d *Directory) Open(game, player string, b *Broadcast) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.next++
+	s := Session{ID: d.next, Game: game, Player: player, Started: time.Now(), Broadcast: b}
+	d.sessions[d.next] = s
+	d.Feed.Publish(s.event(observe.SESSIONSTART, s.Started))
+	return d.next
+}
+
+// Close ends a session and takes it off the list.
+func (d *Directory) Close(id int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if s, ok := d.sessions[id]; ok {
+		s.Broadcast.End()
+		delete(d.sessions, id)
+		d.Feed.Publish(s.event(observe.SESSIONEND, time.Now()))
+	}
+}
+
+// Sessions lists the open sessions, oldest first.
+func (d *Directory) Sessions() []Session {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	all := make([]Session, 0, len(d.sessions))
+	for _, s := range d.sessions {
+		all = append(all, s)
+	}
+	slices.SortFunc(all, func(a, b Session) int { return a.ID - b.ID })
+	return all
+}
+
+func (s Session) event(kind string, at time.Time) observe.Event {
+	return observe.Event{Kind: kind, At: at, Game: s.Game, Player: s.Player, Session: s.ID}
+}
+
+// Current reports the open sessions to observers, see observe.Source.
+func (d *Directory) Current() []observe.Event {
+	var events []observe.Event
+	for _, s := range d.Sessions() {
+		events = append(events, s.event(observe.SESSIONSTART, s.Started))
+	}
+	return events
+}
@@ -0,0 +1,232 @@
+// Package spectate broadcasts a session's frames to spectators. Competitive
+// rooms broadcast on a delay so watching a match can't be used to relay the
+// opponent's moves to a player.
+package spectate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	RANKEDDELAY = 10 * time.Second // default delay of ranked and tournament rooms
+	POLL        = 50 * time.Millisecond
+)
+
+// queue holds timestamped values until they're old enough to be shown.
+type queue[T comparable] struct {
+	values []stamped[T] // oldest first
+}
+
+type stamped[T comparable] struct {
+	at    time.Time
+	value T
+}
+
+func (q *queue[T]) push(now time.Time, v T, delay time.Duration) {
+	if n := len(q.values); n > 0 && q.values[n-1].value == v {
+		return
+	}
+	q.values = append(q.values, stamped[T]{at: now, value: v})
+	q.prune(now, delay)
+}
+
+// ready returns the newest value at least delay old, if there is one yet.
+func (q *queue[T]) ready(now time.Time, delay time.Duration) (T, bool) {
+	q.prune(now, delay)
+	if len(q.values) == 0 || now.Sub(q.values[0].at) < delay {
+		var zero T
+		return zero, false
+	}
+	return q.values[0].value, true
+}
+
+// prune drops values superseded by one that is already old enough to show,
+// so the first value is always the one spectators see.
+func (q *queue[T]) prune(now time.Time, delay time.Duration) {
+	i := 0
+	for i+1 < len(q.values) && now.Sub(q.values[i+1].at) >= delay {
+		i++
+	}
+	q.values = q.values[i:]
+}
+
+// Broadcast holds a session's recent frames, and the state of the match they
+// belong to, until they're old enough to be shown. With no delay spectators
+// see the latest frame.
+type Broadcast struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	frames   queue[string]
+	matches  queue[*Match]
+	watchers int
+	ended    time.Time // zero while the session goes on
+}
+
+func NewBroadcast(delay time.Duration) *Broadcast {
+	return &Broadcast{delay: max(0, delay)}
+}
+
+// Delay is how far behind the session spectators are.
+func (b *Broadcast) Delay() time.Duration {
+	return b.delay
+}
+
+// Publish records the session's current view.
+func (b *Broadcast) Publish(view string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.frames.push(time.Now(), view, b.delay)
+}
+
+// Attach counts a spectator until ctx ends.
+func (b *Broadcast) Attach(ctx context.Context) {
+	b.mu.Lock()
+	b.watchers++
+	b.mu.Unlock()
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.watchers--
+	})
+}
+
+// Spectators counts the spectators attached.
+func (b *Broadcast) Spectators() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.watchers
+}
+
+// End marks the session as over, so spectators stop waiting for frames.
+func (b *Broadcast) End() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ended = time.Now()
+}
+
+// Ended reports whether the session is over, once spectators have seen its
+// last frames.
+func (b *Broadcast) Ended() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.ended.IsZero() && time.Since(b.ended) >= b.delay
+}
+
+// Frame returns the newest frame at least Delay old, if there is one yet.
+func (b *Broadcast) Frame() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.frames.ready(time.Now(), b.delay)
+}
+
+// Model wraps a game so every frame it renders is published, with its match
+// if it's a Scoreboard. Players see how many spectators are watching them;
+// spectators don't.
+type Model struct {
+	tea.Model
+	broadcast *Broadcast
+}
+
+func Wrap(m tea.Model, b *Broadcast) Model {
+	return Model{Model: m, broadcast: b}
+}
+
+// Unwrap is the game m broadcasts, for quota to find its Sizer and Stopper.
+func (m Model) Unwrap() tea.Model {
+	return m.Model
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	inner, cmd := m.Model.Update(msg)
+	m.Model = inner
+	return m, cmd
+}
+
+func (m Model) View() string {
+	view := m.Model.View()
+	m.broadcast.Publish(view)
+	if s, ok := m.Model.(Scoreboard); ok {
+		if match, ok := s.Scoreboard(); ok {
+			m.broadcast.PublishMatch(match)
+		}
+	}
+	if n := m.broadcast.Spectators(); n > 0 {
+		view = badge(view, fmt.Sprintf("👁 %d watching", n))
+	}
+	return view
+}
+
+// badge right-aligns label on the first line of view, taking the line over
+// when it's blank, as it is in views centered on the screen.
+func badge(view, label string) string {
+	first, rest, _ := strings.Cut(view, "\n")
+	if strings.TrimSpace(first) != "" {
+		return label + "\n" + view
+	}
+	width := lipgloss.Width(first)
+	return strings.Repeat(" ", max(0, width-lipgloss.Width(label))) + label + "\n" + rest
+}
+
+type pollMsg struct{}
+
+func poll() tea.Cmd {
+	return tea.Tick(POLL, func(time.Time) tea.Msg { return pollMsg{} })
+}
+
+// Viewer shows a broadcast to a spectator. Any of q, esc or ctrl+c stops
+// watching.
+type Viewer struct {
+	broadcast *Broadcast
+	view      string
+	live      bool
+	ended     bool
+}
+
+func Watch(b *Broadcast) Viewer {
+	return Viewer{broadcast: b}
+}
+
+func (v Viewer) Init() tea.Cmd {
+	return poll()
+}
+
+func (v Viewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pollMsg:
+		if v.ended = v.broadcast.Ended(); v.ended {
+			return v, nil
+		}
+		if view, ok := v.broadcast.Frame(); ok {
+			v.view, v.live = view, true
+		}
+		return v, poll()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return v, tea.Quit
+		}
+	}
+	return v, nil
+}
+
+func (v Viewer) View() string {
+	status := "spectating live"
+	if d := v.broadcast.Delay(); d > 0 {
+		status = fmt.Sprintf("spectating • %s delay", d)
+	}
+	switch {
+	case v.ended:
+		return fmt.Sprintf("%s\n\nThe game is over • q leave", v.view)
+	case !v.live:
+		return fmt.Sprintf("Waiting for the broadcast…\n\n%s • q leave", status)
+	}
+	return fmt.Sprintf("%s\n\n%s • q leave", v.view, status)
+}
@@ -0,0 +1,50 @@
+package spectate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueHoldsForDelay(t *testing.T) {
+	start := time.Now()
+	var q queue[string]
+	q.push(start, "a", RANKEDDELAY)
+	q.push(start.Add(time.Second), "b", RANKEDDELAY)
+	q.push(start.Add(2*time.Second), "c", RANKEDDELAY)
+
+	for _, tc := range []struct {
+		after time.Duration
+		want  string
+	}{
+		{0, ""},
+		{RANKEDDELAY - time.Millisecond, ""},
+		{RANKEDDELAY, "a"},
+		{RANKEDDELAY + time.Second - time.Millisecond, "a"},
+		{RANKEDDELAY + time.Second, "b"},
+		{RANKEDDELAY + time.Hour, "c"},
+	} {
+		got, ok := q.ready(start.Add(tc.after), RANKEDDELAY)
+		if ok != (tc.want != "") || got != tc.want {
+			t.Errorf("%s in: got %q, %v, want %q", tc.after, got, ok, tc.want)
+		}
+	}
+}
+
+func TestBroadcastDelay(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	b := NewBroadcast(delay)
+	b.Publish("frame")
+	if f, ok := b.Frame(); ok {
+		t.Fatalf("spectators saw %q right away, before the %s delay", f, delay)
+	}
+	time.Sleep(delay)
+	if f, ok := b.Frame(); !ok || f != "frame" {
+		t.Errorf("Frame() = %q, %v after the delay, want the frame", f, ok)
+	}
+
+	live := NewBroadcast(0)
+	live.Publish("frame")
+	if _, ok := live.Frame(); !ok {
+		t.Error("a broadcast without a delay held its frame")
+	}
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+const (
+	SIZE  = 9
+	BOX   = 3
+	CELLS = SIZE * SIZE
+)
+
+// Difficulty sets how many clues a puzzle keeps. Fewer clues leave longer
+// chains of deductions, though the hardest puzzles may keep a few more than
+// asked when every further removal would allow a second solution.
+type Difficulty struct {
+	Name  string
+	Clues int
+}
+
+var difficulties = []Difficulty{
+	{"Easy", 40},
+	{"Medium", 33},
+	{"Hard", 28},
+	{"Expert", 24},
+}
+
+// Grid holds a digit per cell, row by row, 0 for empty.
+type Grid [CELLS]int
+
+func index(x, y int) int {
+	return y*SIZE + x
+}
+
+func boxOf(i int) int {
+	return i/SIZE/BOX*BOX + i%SIZE/BOX
+}
+
+// peers tells whether cells a and b share a row, column or box.
+func peers(a, b int) bool {
+	return a != b && (a/SIZE == b/SIZE || a%SIZE == b%SIZE || boxOf(a) == boxOf(b))
+}
+
+// solver searches grids for solutions, keeping the digits used in every
+// row, column and box as bits.
+type solver struct {
+	g                 Grid
+	rows, cols, boxes [SIZE]uint16
+	rng               *rand.Rand // shuffles the digits tried, nil tries them in order
+	limit             int        // stop after this many solutions
+	found             int
+	solution          Grid // the first one found
+}
+
+func newSolver(g Grid, rng *rand.Rand, limit int) *solver {
+	s := &solver{rng: rng, limit: limit}
+	for i, v := range g {
+		if v != 0 {
+			s.set(i, v)
+		}
+	}
+	return s
+}
+
+func (s *solver) set(i, v int) {
+	bit := uint16(1) << v
+	s.g[i] = v
+	s.rows[i/SIZE] |= bit
+	s.cols[i%SIZE] |= bit
+	s.boxes[boxOf(i)] |= bit
+}
+
+func (s *solver) unset(i int) {
+	bit := ^(uint16(1) << s.g[i])
+	s.g[i] = 0
+	s.rows[i/SIZE] &= bit
+	s.cols[i%SIZE] &= bit
+	s.boxes[boxOf(i)] &= bit
+}
+
+// candidates are the digits cell i can take, as bits 1 to 9.
+func (s *solver) candidates(i int) uint16 {
+	return ^(s.rows[i/SIZE] | s.cols[i%SIZE] | s.boxes[boxOf(i)]) & 0x3fe
+}
+
+// search fills the empty cell with the fewest candidates first, reporting
+// whether the limit was reached.
+func (s *solver) search() bool {
+	best, bestMask := -1, uint16(0)
+	for i, v := range s.g {
+		if v != 0 {
+			continue
+		}
+		mask := s.candidates(i)
+		if mask == 0 {
+			return false
+		}
+		if best < 0 || bits.OnesCount16(mask) < bits.OnesCount16(bestMask) {
+			best, bestMask = i, mask
+		}
+	}
+	if best < 0 {
+		if s.found == 0 {
+			s.solution = s.g
+		}
+		s.found++
+		return s.found >= s.limit
+	}
+
+	digits := make([]int, 0, SIZE)
+	for v := 1; v <= SIZE; v++ {
+		if bestMask&(1<<v) != 0 {
+			digits = append(digits, v)
+		}
+	}
+	if s.rng != nil {
+		s.rng.Shuffle(len(digits), func(i, j int) { digits[i], digits[j] = digits[j], digits[i] })
+	}
+	for _, v := range digits {
+		s.set(best, v)
+		done := s.search()
+		s.unset(best)
+		if done {
+			return true
+		}
+	}
+	return false
+}
+
+// solutions counts the ways g can be completed, up to limit.
+func solutions(g Grid, limit int) int {
+	s := newSolver(g, nil, limit)
+	s.search()
+	return s.found
+}
+
+// generate deals a puzzle of difficulty d with a single solution, returning
+// both. The solution is a random full grid and clues come off it in random
+// order while the puzzle stays unique.
+func generate(rng *rand.Rand, d Difficulty) (puzzle, solution Grid) {
+	s := newSolver(Grid{}, rng, 1)
+	s.search()
+	solution = s.solution
+
+	puzzle = solution
+	clues := CELLS
+	for _, i := range rng.Perm(CELLS) {
+		if clues <= d.Clues {
+			break
+		}
+		v := puzzle[i]
+		puzzle[i] = 0
+		if solutions(puzzle, 2) != 1 {
+			puzzle[i] = v
+			continue
+		}
+		clues--
+	}
+	return puzzle, solution
+}
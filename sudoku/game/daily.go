@@ -0,0 +1,63 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/daily"
+)
+
+const (
+	DAILYRANKS = 5 // completions listed once the daily puzzle is solved
+	DAILYLEVEL = 1 // into difficulties, the one every daily puzzle has
+)
+
+// StartDaily switches to today's shared puzzle. Every player gets the same
+// one at DAILYLEVEL.
+func (m *Model) StartDaily() {
+	m.day = daily.Today(time.Now())
+	m.level = DAILYLEVEL
+	m.deal(rand.New(rand.NewSource(int64(daily.Seed(GAMENAME, m.day)))))
+}
+
+// completeDaily records today's solve and loads the rankings shown with it.
+func (m *Model) completeDaily() {
+	if m.Daily == nil {
+		return
+	}
+	err := m.Daily.Complete(daily.Result{
+		Game:        GAMENAME,
+		Day:         m.day,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Time:        m.elapsed,
+		Moves:       m.mistakes,
+	})
+	if err != nil {
+		log.Warn("Could not record daily sudoku", "err", err)
+	}
+	m.ranking = m.Daily.Results(GAMENAME, m.day)
+	m.friends = daily.Friends(m.ranking, m.Friends)
+	m.streak = daily.Streak(m.Daily.Days(GAMENAME, m.Fingerprint), m.day)
+}
+
+func (m Model) dailyView() string {
+	var s strings.Builder
+	if m.Fingerprint != "" {
+		fmt.Fprintf(&s, "Streak: %d day(s)\n", m.streak)
+	}
+	list := func(title string, results []daily.Result) {
+		fmt.Fprintf(&s, "\n%s\n", title)
+		for i, r := range results[:min(DAILYRANKS, len(results))] {
+			fmt.Fprintf(&s, "%d. %-12s %8s (%d mistakes)\n", i+1, r.Player, r.Time.Truncate(time.Second), r.Moves)
+		}
+	}
+	list(fmt.Sprintf("Today's fastest (%d solved)", len(m.ranking)), m.ranking)
+	if len(m.friends) > 0 {
+		list("Friends", m.friends)
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// KeyMap keeps the digits off movement, even on the numpad layout, since
+// they fill the grid.
+type KeyMap struct {
+	ui.MoveKeys
+	Digits     [SIZE]key.Binding
+	Clear      key.Binding
+	Pencil     key.Binding
+	Mistakes   key.Binding
+	Difficulty key.Binding
+	Restart    key.Binding
+	Daily      key.Binding
+	Layout     key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	move := ui.MoveKeysFor(l)
+	k := KeyMap{
+		MoveKeys: ui.MoveKeys{
+			Up:    withoutDigits(move.Up),
+			Down:  withoutDigits(move.Down),
+			Left:  withoutDigits(move.Left),
+			Right: withoutDigits(move.Right),
+		},
+		Clear:      key.NewBinding(key.WithKeys("backspace", "delete", "0"), key.WithHelp("backspace", "clear")),
+		Pencil:     key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pencil marks")),
+		Mistakes:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "show mistakes")),
+		Difficulty: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "difficulty")),
+		Restart:    key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new puzzle")),
+		Daily:      key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "daily puzzle")),
+		Layout:     ui.LayoutKey(),
+		Help:       ui.HelpKey(),
+		Quit:       ui.QuitKeyFor(l),
+		layout:     l,
+	}
+	for i := range k.Digits {
+		d := fmt.Sprint(i + 1)
+		k.Digits[i] = key.NewBinding(key.WithKeys(d), key.WithHelp(d, "place "+d))
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+// withoutDigits drops the number row from a movement binding, and from its
+// help.
+func withoutDigits(b key.Binding) key.Binding {
+	digit := func(k string) bool { return len(k) == 1 && k[0] >= '0' && k[0] <= '9' }
+	keys := slices.DeleteFunc(slices.Clone(b.Keys()), digit)
+	help := slices.DeleteFunc(strings.Split(b.Help().Key, "/"), digit)
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(help, "/"), b.Help().Desc))
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	digits := key.NewBinding(key.WithKeys("1"), key.WithHelp("1-9", "place"))
+	return []key.Binding{digits, k.Pencil, k.Clear, k.Restart, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		k.Digits[:],
+		{k.Clear, k.Pencil, k.Mistakes},
+		{k.Difficulty, k.Restart, k.Daily},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	b := map[string]*key.Binding{
+		"up":         &k.Up,
+		"down":       &k.Down,
+		"left":       &k.Left,
+		"right":      &k.Right,
+		"clear":      &k.Clear,
+		"pencil":     &k.Pencil,
+		"mistakes":   &k.Mistakes,
+		"difficulty": &k.Difficulty,
+		"restart":    &k.Restart,
+		"daily":      &k.Daily,
+		"layout":     &k.Layout,
+		"help":       &k.Help,
+		"quit":       &k.Quit,
+	}
+	for i := range k.Digits {
+		b[fmt.Sprintf("digit-%d", i+1)] = &k.Digits[i]
+	}
+	return b
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "sudoku"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Sudoku",
+	Description: "Fill the grid so every row, column and box has the digits 1 to 9",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     30 * time.Minute,
+	Next: func(now time.Time) (string, time.Time) {
+		return "new daily", daily.Next(now)
+	},
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.Daily = env.Daily
+		m.Player = env.Player
+		if env.Profile != nil {
+			m.Friends = env.Profile.Friends
+			m.SetProfile(env.Profile, env.Profiles, env.Fingerprint)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,465 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// CELLWIDTH is how many columns a cell takes, enough for three pencil marks.
+const CELLWIDTH = 3
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	CellStyle   lipgloss.Style
+	GivenStyle  lipgloss.Style // the puzzle's clues
+	EntryStyle  lipgloss.Style // the player's digits
+	MarkStyle   lipgloss.Style
+	CursorStyle lipgloss.Style
+	PeerStyle   lipgloss.Style // the cursor's row, column and box
+	SameStyle   lipgloss.Style // the other cells with the cursor's digit
+	WrongStyle  lipgloss.Style
+	LineStyle   lipgloss.Style
+	TimeStyle   lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// profile, when set, gets every solve's time, by difficulty, saved back
+	// to Profiles.
+	profile     *profile.Profile
+	Profiles    profile.Store
+	Fingerprint string
+	best        time.Duration // of the difficulty, before this solve
+
+	// Daily puzzle: day is set while playing it. Completions go to Daily
+	// under the player's name and key.
+	Daily   daily.Store
+	Player  string
+	Friends []string
+	day     string
+	streak  int
+	ranking []daily.Result
+	friends []daily.Result
+
+	level    int // into difficulties
+	puzzle   Grid
+	solution Grid
+	cells    Grid
+	marks    [CELLS]uint16 // pencil marks, as bits 1 to 9
+	x, y     int
+	pencil   bool // digits toggle marks rather than place
+	check    bool // whether wrong digits are shown
+	mistakes int  // wrong digits placed, shown or not
+	started  time.Time
+	elapsed  time.Duration
+	solved   bool
+	showHelp bool
+
+	rng *rand.Rand
+	ctx context.Context
+}
+
+type clockMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		CellStyle:   r.NewStyle().Foreground(lipgloss.Color("252")),
+		GivenStyle:  r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		EntryStyle:  r.NewStyle().Foreground(lipgloss.Color("39")),
+		MarkStyle:   r.NewStyle().Foreground(lipgloss.Color("244")),
+		CursorStyle: r.NewStyle().Background(lipgloss.Color("220")).Foreground(lipgloss.Color("0")),
+		PeerStyle:   r.NewStyle().Background(lipgloss.Color("236")),
+		SameStyle:   r.NewStyle().Background(lipgloss.Color("24")),
+		WrongStyle:  r.NewStyle().Foreground(lipgloss.Color("160")).Bold(true),
+		LineStyle:   r.NewStyle().Foreground(lipgloss.Color("240")),
+		TimeStyle:   r.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:  DefaultKeyMap(),
+		check: true,
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:   context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext binds the clock to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetProfile applies a player's key bindings and loads their fastest solve.
+// Solves are recorded to the profile and saved back to store, if set.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+	ui.Rebind(&m.Keys, p.Keys)
+	m.loadBest()
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.clock()
+}
+
+func (m Model) clock() tea.Cmd {
+	return ui.Every(m.ctx, time.Second, func(t time.Time) tea.Msg {
+		return clockMsg(t)
+	})
+}
+
+func (m Model) difficulty() Difficulty {
+	return difficulties[m.level]
+}
+
+// statsKey is the game the profile keeps solves under, one per difficulty.
+func (m Model) statsKey() string {
+	return GAMENAME + "-" + strings.ToLower(m.difficulty().Name)
+}
+
+func (m *Model) loadBest() {
+	m.best = 0
+	if m.profile != nil {
+		m.best = m.profile.Stats[m.statsKey()].Fastest
+	}
+}
+
+// Restart deals a new puzzle of the current difficulty.
+func (m *Model) Restart() {
+	m.day = ""
+	m.deal(m.rng)
+}
+
+// deal generates the puzzle of the current difficulty rng draws and starts
+// the clock on it.
+func (m *Model) deal(rng *rand.Rand) {
+	m.puzzle, m.solution = generate(rng, m.difficulty())
+	m.cells = m.puzzle
+	m.marks = [CELLS]uint16{}
+	m.x, m.y = SIZE/2, SIZE/2
+	m.mistakes = 0
+	m.solved = false
+	m.started = time.Now()
+	m.elapsed = 0
+	m.loadBest()
+}
+
+// cycleDifficulty moves on to the next difficulty and deals a puzzle of it.
+func (m *Model) cycleDifficulty() {
+	m.level = (m.level + 1) % len(difficulties)
+	m.Restart()
+}
+
+func (m *Model) move(dx, dy int) {
+	m.x = (m.x + dx + SIZE) % SIZE
+	m.y = (m.y + dy + SIZE) % SIZE
+}
+
+func (m Model) cursor() int {
+	return index(m.x, m.y)
+}
+
+// enter places v under the cursor, or toggles its pencil mark. Placing the
+// digit that's already there takes it off.
+func (m *Model) enter(v int) {
+	i := m.cursor()
+	if m.puzzle[i] != 0 {
+		return
+	}
+	if m.pencil {
+		if m.cells[i] == 0 {
+			m.marks[i] ^= 1 << v
+		}
+		return
+	}
+	if m.cells[i] == v {
+		m.cells[i] = 0
+		return
+	}
+	m.cells[i] = v
+	m.marks[i] = 0
+	for j := range m.marks {
+		if peers(i, j) {
+			m.marks[j] &^= 1 << v
+		}
+	}
+	if v != m.solution[i] {
+		m.mistakes++
+	}
+	m.checkSolved()
+}
+
+// clear empties the cell under the cursor, its digit first and then its
+// marks.
+func (m *Model) clear() {
+	i := m.cursor()
+	switch {
+	case m.puzzle[i] != 0:
+	case m.cells[i] != 0:
+		m.cells[i] = 0
+	default:
+		m.marks[i] = 0
+	}
+}
+
+func (m *Model) checkSolved() {
+	if m.cells != m.solution {
+		return
+	}
+	m.solved = true
+	m.elapsed = time.Since(m.started)
+	m.record()
+	if m.day != "" {
+		m.completeDaily()
+	}
+}
+
+// record adds the solve to the player's stats.
+func (m *Model) record() {
+	if m.profile == nil {
+		return
+	}
+	m.profile.RecordTime(m.statsKey(), m.elapsed)
+	profile.Save(m.Profiles, m.Fingerprint, m.profile)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case key.Matches(msg, m.Keys.Difficulty):
+			m.cycleDifficulty()
+		case key.Matches(msg, m.Keys.Daily):
+			m.StartDaily()
+		case key.Matches(msg, m.Keys.Mistakes):
+			m.check = !m.check
+		case m.solved:
+		case key.Matches(msg, m.Keys.Up):
+			m.move(0, -1)
+		case key.Matches(msg, m.Keys.Down):
+			m.move(0, 1)
+		case key.Matches(msg, m.Keys.Left):
+			m.move(-1, 0)
+		case key.Matches(msg, m.Keys.Right):
+			m.move(1, 0)
+		case key.Matches(msg, m.Keys.Pencil):
+			m.pencil = !m.pencil
+		case key.Matches(msg, m.Keys.Clear):
+			m.clear()
+		default:
+			for i, b := range m.Keys.Digits {
+				if key.Matches(msg, b) {
+					m.enter(i + 1)
+					break
+				}
+			}
+		}
+	case clockMsg:
+		return m, m.clock()
+	}
+	return m, nil
+}
+
+// marksLabel writes a cell's pencil marks, as many as fit and a + when
+// there are more.
+func marksLabel(marks uint16) string {
+	var s strings.Builder
+	n := bits.OnesCount16(marks)
+	for v := 1; v <= SIZE && s.Len() < CELLWIDTH; v++ {
+		if marks&(1<<v) == 0 {
+			continue
+		}
+		if n > CELLWIDTH && s.Len() == CELLWIDTH-1 {
+			s.WriteString("+")
+			break
+		}
+		s.WriteString(strconv.Itoa(v))
+	}
+	return s.String()
+}
+
+func (m Model) cellView(i int) string {
+	cur := m.cursor()
+	style := m.CellStyle
+	switch {
+	case m.solved:
+	case i == cur:
+		style = m.CursorStyle
+	case m.cells[i] != 0 && m.cells[i] == m.cells[cur]:
+		style = m.SameStyle
+	case peers(i, cur):
+		style = m.PeerStyle
+	}
+
+	label := ""
+	switch v := m.cells[i]; {
+	case v == 0:
+		label = marksLabel(m.marks[i])
+		if i != cur {
+			style = style.Inherit(m.MarkStyle)
+		}
+	case m.puzzle[i] != 0:
+		label = strconv.Itoa(v)
+		style = style.Inherit(m.GivenStyle)
+	case m.check && v != m.solution[i]:
+		label = strconv.Itoa(v)
+		style = m.WrongStyle.Inherit(style)
+	default:
+		label = strconv.Itoa(v)
+		if i != cur {
+			style = style.Inherit(m.EntryStyle)
+		}
+	}
+	return style.Width(CELLWIDTH).Align(lipgloss.Center).Render(label)
+}
+
+// rule is a horizontal line of the grid, drawn with the corners given.
+func (m Model) rule(left, mid, right string) string {
+	segment := strings.Repeat("─", BOX*CELLWIDTH)
+	return m.LineStyle.Render(left + strings.Repeat(segment+mid, BOX-1) + segment + right)
+}
+
+func (m Model) gridView() string {
+	bar := m.LineStyle.Render("│")
+	lines := []string{m.rule("┌", "┬", "┐")}
+	for y := 0; y < SIZE; y++ {
+		if y > 0 && y%BOX == 0 {
+			lines = append(lines, m.rule("├", "┼", "┤"))
+		}
+		var s strings.Builder
+		for x := 0; x < SIZE; x++ {
+			if x%BOX == 0 {
+				s.WriteString(bar)
+			}
+			s.WriteString(m.cellView(index(x, y)))
+		}
+		s.WriteString(bar)
+		lines = append(lines, s.String())
+	}
+	lines = append(lines, m.rule("└", "┴", "┘"))
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) header() string {
+	elapsed := time.Since(m.started).Truncate(time.Second)
+	if m.solved {
+		elapsed = m.elapsed.Truncate(time.Second)
+	}
+	name := m.difficulty().Name
+	if m.day != "" {
+		name = "daily " + m.day
+	}
+	header := fmt.Sprintf("Sudoku %s | Time: %s | Mistakes: %d",
+		name, m.TimeStyle.Render(elapsed.String()), m.mistakes)
+	if m.best > 0 {
+		header += " | Best: " + m.best.Truncate(time.Second).String()
+	}
+	return header
+}
+
+// status tells how digits go in, and the marks of the cell under the cursor
+// in full, which a cell may not have room for.
+func (m Model) status() string {
+	mode := "placing digits"
+	if m.pencil {
+		mode = "pencil marks"
+	}
+	status := fmt.Sprintf("Mode: %s (%s)", mode, m.Keys.Pencil.Help().Key)
+	if i := m.cursor(); m.cells[i] == 0 && m.marks[i] != 0 {
+		var marks []string
+		for v := 1; v <= SIZE; v++ {
+			if m.marks[i]&(1<<v) != 0 {
+				marks = append(marks, strconv.Itoa(v))
+			}
+		}
+		status += " • Marks: " + strings.Join(marks, " ")
+	}
+	if !m.check {
+		status += " • mistakes hidden"
+	}
+	return m.QuitStyle.Render(status)
+}
+
+func (m Model) solvedView() string {
+	lines := []string{"Solved!", fmt.Sprintf("%s in %s, %d mistake(s)",
+		m.difficulty().Name, m.elapsed.Truncate(time.Second), m.mistakes)}
+	if m.profile != nil && (m.best == 0 || m.elapsed < m.best) {
+		lines = append(lines, "A new best!")
+	}
+	if m.day != "" && m.Daily != nil {
+		lines = append(lines, "", m.dailyView())
+	}
+	lines = append(lines, "", fmt.Sprintf("'%s' new puzzle • '%s' next difficulty",
+		m.Keys.Restart.Help().Key, m.Keys.Difficulty.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	footer := m.status()
+	if m.solved {
+		footer = m.solvedView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.gridView(),
+			footer,
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
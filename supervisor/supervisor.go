@@ -0,0 +1,176 @@
+// Package supervisor runs a multiplayer room's authoritative loop so that a
+// panic restarts the room from its last snapshot instead of taking every
+// player in it down.
+package supervisor
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	SNAPSHOTEVERY = 10              // ticks between snapshots
+	MAXRESTARTS   = 5               // restarts allowed within RESTARTWINDOW
+	RESTARTWINDOW = 1 * time.Minute // before the room is given up on
+)
+
+var restarts = expvar.NewInt("supervisor.restarts")
+
+// Loop is a room's authoritative state. Tick and the functions passed to Do
+// run on the supervisor's goroutine only, or on the callers of Step and
+// Apply, one at a time.
+type Loop interface {
+	Tick(now time.Time)
+	Snapshot() ([]byte, error)
+	Restore(snapshot []byte) error
+}
+
+// Hiccup tells the room's participants that the loop crashed and was
+// restored to a snapshot, losing the ticks since.
+type Hiccup struct {
+	Room     string
+	Err      error
+	Restarts int       // within the current window
+	Since    time.Time // when the restored snapshot was taken
+}
+
+type Options struct {
+	Tick time.Duration
+	// Notify is called on the supervisor's goroutine after every restart.
+	Notify func(Hiccup)
+}
+
+type Supervisor struct {
+	room string
+	loop Loop
+	opts Options
+	do   chan func(Loop)
+
+	ticks    int
+	snapshot []byte
+	takenAt  time.Time
+	crashes  []time.Time
+}
+
+// New supervises loop for the room. It takes a first snapshot right away so
+// there's always something to restore.
+func New(room string, loop Loop, opts Options) (*Supervisor, error) {
+	s := &Supervisor{room: room, loop: loop, opts: opts, do: make(chan func(Loop), 64)}
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Do runs fn, typically applying a player's input, on the loop's goroutine.
+// A panic in fn is handled like one in Tick.
+func (s *Supervisor) Do(fn func(Loop)) {
+	s.do <- fn
+}
+
+// Run ticks the loop until ctx is done, or returns an error once the loop
+// has crashed MAXRESTARTS times within RESTARTWINDOW.
+func (s *Supervisor) Run(ctx context.Context) error {
+	t := time.NewTicker(s.opts.Tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case fn := <-s.do:
+			if err := s.Apply(fn); err != nil {
+				return err
+			}
+		case now := <-t.C:
+			if err := s.Step(now); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Step ticks the loop on the caller's goroutine, for rooms without one of
+// their own that tick as their players poll. A panic restores the last
+// snapshot as in Run, and the error is Run's once the room is given up on.
+func (s *Supervisor) Step(now time.Time) error {
+	err := s.guard(func() { s.loop.Tick(now) })
+	if err == nil {
+		if s.ticks++; s.ticks%SNAPSHOTEVERY == 0 {
+			err = s.save()
+		}
+	}
+	if err != nil {
+		return s.restart(err)
+	}
+	return nil
+}
+
+// Apply runs fn on the caller's goroutine, like Do for rooms driven by
+// Step.
+func (s *Supervisor) Apply(fn func(Loop)) error {
+	if err := s.guard(func() { fn(s.loop) }); err != nil {
+		return s.restart(err)
+	}
+	return nil
+}
+
+// guard turns a panic in fn into an error.
+func (s *Supervisor) guard(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			log.Error("Room loop panicked", "room", s.room, "err", err, "stack", string(debug.Stack()))
+		}
+	}()
+	fn()
+	return nil
+}
+
+func (s *Supervisor) save() error {
+	snap, err := s.loop.Snapshot()
+	if err != nil {
+		return err
+	}
+	s.snapshot, s.takenAt = snap, time.Now()
+	return nil
+}
+
+func (s *Supervisor) restart(cause error) error {
+	now := time.Now()
+	recent := s.crashes[:0]
+	for _, t := range s.crashes {
+		if now.Sub(t) < RESTARTWINDOW {
+			recent = append(recent, t)
+		}
+	}
+	s.crashes = append(recent, now)
+	if len(s.crashes) > MAXRESTARTS {
+		return fmt.Errorf("room %s crashed %d times within %s: %w", s.room, len(s.crashes), RESTARTWINDOW, cause)
+	}
+
+	if err := s.guard(func() {
+		if err := s.loop.Restore(s.snapshot); err != nil {
+			panic(err)
+		}
+	}); err != nil {
+		return fmt.Errorf("room %s could not be restored: %w", s.room, err)
+	}
+	restarts.Add(1)
+	log.Warn("Room restored from snapshot", "room", s.room, "cause", cause, "snapshot", s.takenAt)
+
+	if s.opts.Notify != nil {
+		s.opts.Notify(Hiccup{Room: s.room, Err: cause, Restarts: len(s.crashes), Since: s.takenAt})
+	}
+	return nil
+}
+
+// String is the message shown to participants.
+func (h Hiccup) String() string {
+	return fmt.Sprintf("The room hit a problem and was rewound %s. Play on!", time.Since(h.Since).Round(100*time.Millisecond))
+}
@@ -0,0 +1,85 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// counter counts its ticks and panics on the one numbered boom.
+type counter struct {
+	n    int
+	boom int
+}
+
+func (c *counter) Tick(time.Time) {
+	c.n++
+	if c.n == c.boom {
+		panic("boom")
+	}
+}
+
+func (c *counter) Snapshot() ([]byte, error) {
+	return json.Marshal(c.n)
+}
+
+func (c *counter) Restore(snapshot []byte) error {
+	return json.Unmarshal(snapshot, &c.n)
+}
+
+func TestStepRestoresAfterPanic(t *testing.T) {
+	c := &counter{boom: SNAPSHOTEVERY + 3}
+	var hiccups []Hiccup
+	s, err := New("test", c, Options{Notify: func(h Hiccup) { hiccups = append(hiccups, h) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range SNAPSHOTEVERY + 2 {
+		if err := s.Step(time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Step(time.Now()); err != nil {
+		t.Fatalf("the first crash gave up on the room: %v", err)
+	}
+	if c.n != SNAPSHOTEVERY {
+		t.Errorf("restored to tick %d, want the snapshot at %d", c.n, SNAPSHOTEVERY)
+	}
+	if len(hiccups) != 1 || hiccups[0].Restarts != 1 || hiccups[0].Room != "test" {
+		t.Errorf("hiccups = %+v, want one for the first restart", hiccups)
+	}
+}
+
+func TestApplyRestoresAfterPanic(t *testing.T) {
+	c := &counter{}
+	s, err := New("test", c, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Apply(func(l Loop) {
+		l.(*counter).n = 42
+		panic("input")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.n != 0 {
+		t.Errorf("n = %d after the input panicked, want the snapshot's 0", c.n)
+	}
+}
+
+func TestStepGivesUp(t *testing.T) {
+	c := &counter{boom: 1}
+	s, err := New("test", c, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= MAXRESTARTS; i++ {
+		if err := s.Step(time.Now()); err != nil {
+			t.Fatalf("gave up after %d crashes: %v", i, err)
+		}
+	}
+	if err := s.Step(time.Now()); err == nil {
+		t.Errorf("still restoring after %d crashes within %s", MAXRESTARTS+1, RESTARTWINDOW)
+	}
+}
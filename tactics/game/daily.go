@@ -0,0 +1,64 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/daily"
+)
+
+const DAILYRANKS = 5 // completions listed once the daily puzzle is solved
+
+// StartDaily switches to today's shared puzzle. Every player gets the same
+// one, whatever their rating.
+func (m *Model) StartDaily() {
+	m.day = daily.Today(time.Now())
+	m.puzzle = puzzles[daily.Seed(GAMENAME, m.day)%uint64(len(puzzles))]
+	m.tried[m.puzzle.ID] = true
+	m.rated = false
+	m.started = time.Now()
+	m.attempts = 0
+	m.Retry()
+}
+
+// completeDaily records today's solve, timed from the first attempt, and
+// loads the rankings shown with it.
+func (m *Model) completeDaily() {
+	if m.Daily == nil {
+		return
+	}
+	err := m.Daily.Complete(daily.Result{
+		Game:        GAMENAME,
+		Day:         m.day,
+		Player:      m.Player,
+		Fingerprint: m.Fingerprint,
+		Time:        time.Since(m.started),
+		Moves:       m.attempts,
+	})
+	if err != nil {
+		log.Warn("Could not record daily puzzle", "err", err)
+	}
+	m.ranking = m.Daily.Results(GAMENAME, m.day)
+	m.friends = daily.Friends(m.ranking, m.Friends)
+	m.streak = daily.Streak(m.Daily.Days(GAMENAME, m.Fingerprint), m.day)
+}
+
+func (m Model) dailyView() string {
+	var s strings.Builder
+	if m.Fingerprint != "" {
+		fmt.Fprintf(&s, "Streak: %d day(s)\n", m.streak)
+	}
+	list := func(title string, results []daily.Result) {
+		fmt.Fprintf(&s, "\n%s\n", title)
+		for i, r := range results[:min(DAILYRANKS, len(results))] {
+			fmt.Fprintf(&s, "%d. %-12s %8s (%d tries)\n", i+1, r.Player, r.Time.Truncate(time.Second), r.Moves)
+		}
+	}
+	list(fmt.Sprintf("Today's fastest (%d solved)", len(m.ranking)), m.ranking)
+	if len(m.friends) > 0 {
+		list("Friends", m.friends)
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
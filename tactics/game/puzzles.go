@@ -0,0 +1,143 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/chess"
+)
+
+//go:embed puzzles.json
+var puzzleSet []byte
+
+// Puzzle is a position and its solution: the solver's moves alternating with
+// the opponent's replies, starting with the solver's.
+type Puzzle struct {
+	ID     string `json:"id"`
+	FEN    string `json:"fen"`
+	Moves  string `json:"moves"`
+	Rating int    `json:"rating"`
+	Theme  string `json:"theme"`
+
+	board chess.Board
+	line  []chess.Move
+}
+
+var puzzles = mustLoad(puzzleSet)
+
+// mustLoad parses the embedded set, checking every solution is legal.
+func mustLoad(data []byte) []Puzzle {
+	var ps []Puzzle
+	if err := json.Unmarshal(data, &ps); err != nil {
+		panic(fmt.Sprintf("tactics: puzzle set: %v", err))
+	}
+	for i := range ps {
+		if err := ps[i].parse(); err != nil {
+			panic(fmt.Sprintf("tactics: puzzle %s: %v", ps[i].ID, err))
+		}
+	}
+	return ps
+}
+
+func (p *Puzzle) parse() error {
+	b, err := chess.ParseFEN(p.FEN)
+	if err != nil {
+		return err
+	}
+	p.board = b
+	for _, uci := range strings.Fields(p.Moves) {
+		m, err := chess.ParseMove(uci)
+		if err != nil {
+			return err
+		}
+		if !b.Legal(m) {
+			return fmt.Errorf("illegal move %s", uci)
+		}
+		b.Play(m)
+		p.line = append(p.line, m)
+	}
+	if len(p.line)%2 == 0 {
+		return fmt.Errorf("solution must end with the solver's move")
+	}
+	return nil
+}
+
+// RatingStore keeps how hard each puzzle turned out to be across restarts.
+type RatingStore interface {
+	// Ratings returns the puzzles' ratings, by ID.
+	Ratings() (map[string]int, error)
+	SetRating(id string, rating int) error
+}
+
+// ratings tracks how hard each puzzle turned out to be for the players who
+// tried it, starting from the set's ratings. It's shared by every session,
+// and kept in store once SetRatings is called.
+var ratings = struct {
+	sync.Mutex
+	byID  map[string]int
+	store RatingStore
+}{byID: make(map[string]int)}
+
+// SetRatings keeps the puzzles' ratings in store, picking up the ones it
+// has. Call it before serving.
+func SetRatings(store RatingStore) error {
+	byID, err := store.Ratings()
+	if err != nil {
+		return err
+	}
+	ratings.Lock()
+	defer ratings.Unlock()
+	for id, r := range byID {
+		ratings.byID[id] = r
+	}
+	ratings.store = store
+	return nil
+}
+
+func puzzleRating(p Puzzle) int {
+	ratings.Lock()
+	defer ratings.Unlock()
+	if r, ok := ratings.byID[p.ID]; ok {
+		return r
+	}
+	return p.Rating
+}
+
+func setPuzzleRating(p Puzzle, r int) {
+	ratings.Lock()
+	ratings.byID[p.ID] = r
+	store := ratings.store
+	ratings.Unlock()
+	if store == nil {
+		return
+	}
+	if err := store.SetRating(p.ID, r); err != nil {
+		log.Warn("Could not save puzzle rating", "puzzle", p.ID, "err", err)
+	}
+}
+
+// pick chooses the puzzle rated closest to the player among those they
+// haven't tried this session, starting over once they've tried them all.
+func pick(player int, tried map[string]bool) Puzzle {
+	if len(tried) >= len(puzzles) {
+		clear(tried)
+	}
+	best := -1
+	for i, p := range puzzles {
+		if tried[p.ID] {
+			continue
+		}
+		if best < 0 || abs(puzzleRating(p)-player) < abs(puzzleRating(puzzles[best])-player) {
+			best = i
+		}
+	}
+	return puzzles[best]
+}
+
+func abs(n int) int {
+	return max(n, -n)
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/debemdeboas/games.debem.dev/chess"
+)
+
+// mates reports whether the side to move mates within n moves.
+func mates(b chess.Board, n int) bool {
+	for _, m := range b.LegalMoves() {
+		if matesWith(b, m, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// matesWith reports whether m mates within n moves whatever the reply.
+func matesWith(b chess.Board, m chess.Move, n int) bool {
+	b.Play(m)
+	replies := b.LegalMoves()
+	if len(replies) == 0 {
+		return b.InCheck()
+	}
+	if n == 1 {
+		return false
+	}
+	for _, r := range replies {
+		after := b
+		after.Play(r)
+		if !mates(after, n-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestPuzzles checks mates in the set are forced and have a single solution,
+// since the trainer takes no other move; mates in three are only played out,
+// their search being slow.
+func TestPuzzles(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, p := range puzzles {
+		if seen[p.ID] {
+			t.Errorf("puzzle %s twice", p.ID)
+		}
+		seen[p.ID] = true
+
+		b := p.board
+		for _, mv := range p.line {
+			b.Play(mv)
+		}
+		mate := strings.Contains(strings.ToLower(p.Theme), "mate")
+		if got := b.Outcome() == chess.CHECKMATE; got != mate {
+			t.Errorf("%s (%s) ends in mate: %v", p.ID, p.Theme, got)
+		}
+		n := (len(p.line) + 1) / 2
+		if !mate || n > 2 {
+			continue
+		}
+		b = p.board
+		for i := 0; i < len(p.line); i += 2 {
+			want, left := p.line[i], n-i/2
+			if !matesWith(b, want, left) {
+				t.Errorf("%s: %s doesn't force mate in %d", p.ID, want, left)
+			}
+			for _, mv := range b.LegalMoves() {
+				if (mv.From != want.From || mv.To != want.To) && matesWith(b, mv, left) {
+					t.Errorf("%s: %s mates in %d too", p.ID, mv, left)
+				}
+			}
+			b.Play(want)
+			if i+1 < len(p.line) {
+				b.Play(p.line[i+1])
+			}
+		}
+	}
+}
+
+func TestRatingStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tactics.db")
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := puzzles[0]
+	defer setPuzzleRating(p, puzzleRating(p))
+	if err := SetRatings(db); err != nil {
+		t.Fatal(err)
+	}
+	setPuzzleRating(p, p.Rating+50)
+	ratings.Lock()
+	ratings.store, ratings.byID = nil, make(map[string]int)
+	ratings.Unlock()
+	db.Close()
+
+	// A restart picks up how hard the puzzle turned out to be.
+	if db, err = OpenSQLite(path); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := SetRatings(db); err != nil {
+		t.Fatal(err)
+	}
+	if got := puzzleRating(p); got != p.Rating+50 {
+		t.Errorf("rating after restart %d, want %d", got, p.Rating+50)
+	}
+	ratings.Lock()
+	ratings.store = nil
+	ratings.Unlock()
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "tactics"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Chess Tactics",
+	Description: "Find the winning continuation",
+	Category:    games.PUZZLE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     5 * time.Minute,
+	Next: func(now time.Time) (string, time.Time) {
+		return "new daily", daily.Next(now)
+	},
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.Daily = env.Daily
+		m.Player = env.Player
+		m.Fingerprint = env.Fingerprint
+		if env.Profile != nil {
+			m.Friends = env.Profile.Friends
+			m.SetProfile(env.Profile, env.Profiles)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS puzzle_ratings (
+	id     TEXT PRIMARY KEY,
+	rating INTEGER NOT NULL
+);
+`
+
+// SQLiteStore keeps puzzle ratings in a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create puzzle ratings schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Ratings() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT id, rating FROM puzzle_ratings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var r int
+		if err := rows.Scan(&id, &r); err != nil {
+			return nil, err
+		}
+		byID[id] = r
+	}
+	return byID, rows.Err()
+}
+
+func (s *SQLiteStore) SetRating(id string, rating int) error {
+	_, err := s.db.Exec(`INSERT INTO puzzle_ratings VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET rating = excluded.rating`, id, rating)
+	return err
+}
@@ -0,0 +1,358 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/chess"
+	"github.com/debemdeboas/games.debem.dev/daily"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/rating"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	SOLVING = iota
+	SOLVED
+	FAILED
+)
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	LightStyle    lipgloss.Style
+	DarkStyle     lipgloss.Style
+	CursorStyle   lipgloss.Style
+	SelectedStyle lipgloss.Style
+	LastStyle     lipgloss.Style
+	QuitStyle     lipgloss.Style
+	WinStyle      lipgloss.Style
+	FailStyle     lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Profiles, when set, keeps the player's tactics rating, which is
+	// separate from any match rating.
+	Profiles    profile.Store
+	Fingerprint string
+	profile     *profile.Profile
+
+	// Daily puzzle: day is set while playing it. Completions go to Daily
+	// under the player's name and key.
+	Daily    daily.Store
+	Player   string
+	Friends  []string
+	day      string
+	started  time.Time
+	attempts int
+	streak   int
+	ranking  []daily.Result
+	friends  []daily.Result
+
+	// Puzzle state
+	puzzle   Puzzle
+	board    chess.Board
+	solver   byte
+	step     int // next move of the solution to play
+	cursor   chess.Square
+	selected *chess.Square
+	last     *chess.Move
+	state    int
+	rated    bool // whether this puzzle already counted towards the rating
+	change   int  // rating change of the last rated attempt
+	tried    map[string]bool
+	showHelp bool
+}
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:         width,
+		Height:        height,
+		LightStyle:    r.NewStyle().Background(lipgloss.Color("180")),
+		DarkStyle:     r.NewStyle().Background(lipgloss.Color("137")),
+		CursorStyle:   r.NewStyle().Background(lipgloss.Color("75")),
+		SelectedStyle: r.NewStyle().Background(lipgloss.Color("114")),
+		LastStyle:     r.NewStyle().Background(lipgloss.Color("186")),
+		QuitStyle:     r.NewStyle().Foreground(lipgloss.Color("8")),
+		WinStyle:      r.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+		FailStyle:     r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+		Keys:          DefaultKeyMap(),
+		profile:       &profile.Profile{},
+		tried:         make(map[string]bool),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.NextPuzzle()
+	return m
+}
+
+// SetProfile rates the player with their saved tactics rating and saves
+// changes back to store, if set.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store) {
+	m.profile = p
+	m.Profiles = store
+	clear(m.tried)
+	m.NextPuzzle()
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// Rating is the player's tactics rating.
+func (m Model) Rating() int {
+	if r, ok := m.profile.Ratings[rating.TACTICS]; ok {
+		return r
+	}
+	return rating.DEFAULT
+}
+
+// NextPuzzle serves the untried puzzle rated closest to the player.
+func (m *Model) NextPuzzle() {
+	m.day = ""
+	m.puzzle = pick(m.Rating(), m.tried)
+	m.tried[m.puzzle.ID] = true
+	m.rated = false
+	m.Retry()
+}
+
+// Retry resets the current puzzle. Only the first attempt is rated.
+func (m *Model) Retry() {
+	m.board = m.puzzle.board
+	m.solver = m.board.Turn
+	m.step = 0
+	m.selected = nil
+	m.last = nil
+	m.state = SOLVING
+	m.attempts++
+	// Start on the first piece of the solver, from their side.
+	for s := chess.Square(0); s < 64; s++ {
+		if p := m.board.At(m.flip(s)); p != 0 && p.Color() == m.solver {
+			m.cursor = m.flip(s)
+			break
+		}
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// flip maps squares between the board and the solver's point of view, so
+// the solver's pieces are always at the bottom.
+func (m Model) flip(s chess.Square) chess.Square {
+	if m.solver == chess.BLACK {
+		return 63 - s
+	}
+	return s
+}
+
+func (m *Model) moveCursor(df, dr int) {
+	view := m.flip(m.cursor)
+	f := max(0, min(7, view.File()+df))
+	r := max(0, min(7, view.Rank()+dr))
+	m.cursor = m.flip(chess.SquareAt(f, r))
+}
+
+func (m *Model) selectSquare() {
+	if m.state != SOLVING {
+		return
+	}
+	p := m.board.At(m.cursor)
+	switch {
+	case p != 0 && p.Color() == m.solver:
+		if m.selected != nil && *m.selected == m.cursor {
+			m.selected = nil
+			return
+		}
+		s := m.cursor
+		m.selected = &s
+	case m.selected != nil:
+		m.attempt(chess.Move{From: *m.selected, To: m.cursor})
+		m.selected = nil
+	}
+}
+
+// attempt checks a move against the solution, playing the opponent's reply
+// when it's right. Promotions take the piece the solution promotes to.
+func (m *Model) attempt(mv chess.Move) {
+	want := m.puzzle.line[m.step]
+	if mv.From != want.From || mv.To != want.To {
+		m.state = FAILED
+		m.rate(0)
+		return
+	}
+
+	m.play(want)
+	if m.step < len(m.puzzle.line) {
+		m.play(m.puzzle.line[m.step])
+		return
+	}
+	m.state = SOLVED
+	m.rate(1)
+	if m.day != "" {
+		m.completeDaily()
+	}
+}
+
+func (m *Model) play(mv chess.Move) {
+	m.board.Play(mv)
+	m.last = &mv
+	m.step++
+}
+
+// rate scores the first attempt at a puzzle as a game between the player and
+// the puzzle, so hard puzzles gain rating when players fail them.
+func (m *Model) rate(score float64) {
+	if m.rated {
+		return
+	}
+	m.rated = true
+
+	player, puzzle := m.Rating(), puzzleRating(m.puzzle)
+	updated := rating.Update(player, puzzle, score)
+	m.change = updated - player
+	setPuzzleRating(m.puzzle, rating.Update(puzzle, player, 1-score))
+
+	profile.Update(m.Profiles, m.Fingerprint, m.profile, func(p *profile.Profile) {
+		if p.Ratings == nil {
+			p.Ratings = make(map[string]int)
+		}
+		p.Ratings[rating.TACTICS] = updated
+	})
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(0, 1)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(0, -1)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(-1, 0)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(1, 0)
+		case key.Matches(msg, m.Keys.Select):
+			m.selectSquare()
+		case key.Matches(msg, m.Keys.Retry):
+			m.Retry()
+		case key.Matches(msg, m.Keys.Next):
+			m.NextPuzzle()
+		case key.Matches(msg, m.Keys.Daily):
+			m.StartDaily()
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		}
+	}
+	return m, nil
+}
+
+func (m Model) square(s chess.Square) string {
+	style := m.LightStyle
+	if (s.File()+s.Rank())%2 == 0 {
+		style = m.DarkStyle
+	}
+	switch {
+	case s == m.cursor:
+		style = m.CursorStyle
+	case m.selected != nil && *m.selected == s:
+		style = m.SelectedStyle
+	case m.last != nil && (m.last.From == s || m.last.To == s):
+		style = m.LastStyle
+	}
+
+	p := m.board.At(s)
+	if p == 0 {
+		return style.Render("   ")
+	}
+	fg := lipgloss.Color("0")
+	if p.Color() == chess.WHITE {
+		fg = lipgloss.Color("15")
+	}
+	return style.Foreground(fg).Render(" " + p.Glyph() + " ")
+}
+
+func (m Model) boardView() string {
+	var s strings.Builder
+	for r := 7; r >= 0; r-- {
+		fmt.Fprintf(&s, "%d ", m.flip(chess.SquareAt(0, r)).Rank()+1)
+		for f := 0; f < 8; f++ {
+			s.WriteString(m.square(m.flip(chess.SquareAt(f, r))))
+		}
+		s.WriteString("\n")
+	}
+	s.WriteString("  ")
+	for f := 0; f < 8; f++ {
+		fmt.Fprintf(&s, " %c ", 'a'+m.flip(chess.SquareAt(f, 0)).File())
+	}
+	return s.String()
+}
+
+func (m Model) statusView() string {
+	side := "White"
+	if m.solver == chess.BLACK {
+		side = "Black"
+	}
+	switch m.state {
+	case SOLVED:
+		solved := m.WinStyle.Render(fmt.Sprintf("Solved! Rating %d (%+d)", m.Rating(), m.change))
+		if m.day != "" && m.Daily != nil {
+			solved = lipgloss.JoinVertical(lipgloss.Center, solved, "", m.dailyView())
+		}
+		return solved
+	case FAILED:
+		return m.FailStyle.Render(fmt.Sprintf("Not quite: the move was %s. Rating %d (%+d)",
+			m.puzzle.line[m.step], m.Rating(), m.change))
+	}
+	return fmt.Sprintf("%s to move • %s", side, m.puzzle.Theme)
+}
+
+func (m Model) titleView() string {
+	title := fmt.Sprintf("Puzzle rated %d", puzzleRating(m.puzzle))
+	if m.day != "" {
+		title = "Daily puzzle " + m.day
+	}
+	return fmt.Sprintf("Tactics | %s | Your rating: %d", title, m.Rating())
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.QuitStyle.Padding(1, 3).Border(lipgloss.RoundedBorder())),
+		)
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.titleView(),
+			"",
+			m.boardView(),
+			"",
+			m.statusView(),
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
@@ -0,0 +1,295 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+const (
+	WIDTH   = 10
+	HEIGHT  = 20
+	PREVIEW = 3 // upcoming pieces shown
+)
+
+// ATTACK is the garbage sent for clearing 0-4 lines at once.
+var ATTACK = [5]int{0, 0, 1, 2, 4}
+
+// COMBO is the extra garbage for clearing lines with consecutive pieces,
+// by the length of the streak. Longer streaks keep the last bonus.
+var COMBO = []int{0, 0, 1, 1, 2, 2, 3, 3, 4}
+
+// BACKTOBACK is the extra garbage for a Tetris right after another.
+const BACKTOBACK = 1
+
+// Board is one player's well.
+type Board struct {
+	cells *grid.Grid[int] // piece kinds
+	kind  int             // falling piece
+	rot   int
+	pos   grid.Point // of the piece's box
+	queue []int      // upcoming pieces
+	bag   *rand.Rand // deals pieces in shuffled bags of all seven
+	holes *rand.Rand // picks the gap of garbage rows
+	seed  int64
+	bags  int // shuffled by bag so far, for a restored board to replay
+	gaps  int // picked by holes so far, likewise
+
+	Pending int // garbage rows waiting to rise
+	Lines   int
+	Sent    int
+	combo   int
+	tetris  bool // whether the last clear was a Tetris
+	Over    bool
+}
+
+// newBoard deals the pieces of seed, so both players of a match get the
+// same sequence.
+func newBoard(seed int64) *Board {
+	b := &Board{
+		cells: grid.New[int](WIDTH, HEIGHT),
+		bag:   rand.New(rand.NewSource(seed)),
+		holes: rand.New(rand.NewSource(seed + 1)),
+		seed:  seed,
+	}
+	b.spawn()
+	return b
+}
+
+func (b *Board) refill() {
+	for len(b.queue) <= PREVIEW {
+		for _, i := range b.bag.Perm(PIECES) {
+			b.queue = append(b.queue, i+1)
+		}
+		b.bags++
+	}
+}
+
+// Next lists the upcoming pieces.
+func (b *Board) Next() []int {
+	return b.queue[:PREVIEW]
+}
+
+// spawn brings in the next piece at the top, topping out if it doesn't fit.
+func (b *Board) spawn() {
+	b.refill()
+	b.kind, b.queue = b.queue[0], b.queue[1:]
+	b.rot = 0
+	b.pos = grid.Point{X: (WIDTH - pieces[b.kind].size) / 2, Y: 0}
+	if !b.fits(b.rot, b.pos) {
+		b.Over = true
+	}
+}
+
+func (b *Board) fits(rot int, pos grid.Point) bool {
+	for _, c := range rotations[b.kind][rot] {
+		p := pos.Add(c)
+		if !b.cells.InBounds(p) || b.cells.At(p) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Piece returns the cells of the falling piece.
+func (b *Board) Piece() (kind int, cells [4]grid.Point) {
+	for i, c := range rotations[b.kind][b.rot] {
+		cells[i] = b.pos.Add(c)
+	}
+	return b.kind, cells
+}
+
+// Ghost returns where the falling piece would land.
+func (b *Board) Ghost() [4]grid.Point {
+	pos := b.pos
+	for b.fits(b.rot, pos.Add(grid.Down)) {
+		pos = pos.Add(grid.Down)
+	}
+	var cells [4]grid.Point
+	for i, c := range rotations[b.kind][b.rot] {
+		cells[i] = pos.Add(c)
+	}
+	return cells
+}
+
+// At is the kind of piece settled at p, 0 if empty.
+func (b *Board) At(p grid.Point) int {
+	return b.cells.At(p)
+}
+
+func (b *Board) shift(d grid.Point) bool {
+	if b.Over || !b.fits(b.rot, b.pos.Add(d)) {
+		return false
+	}
+	b.pos = b.pos.Add(d)
+	return true
+}
+
+func (b *Board) rotate() {
+	if b.Over {
+		return
+	}
+	rot := (b.rot + 1) % 4
+	for _, k := range kicks {
+		if b.fits(rot, b.pos.Add(k)) {
+			b.rot, b.pos = rot, b.pos.Add(k)
+			return
+		}
+	}
+}
+
+// fall moves the piece one row down, locking it when it can't. It returns
+// the garbage the lock sent.
+func (b *Board) fall() int {
+	if b.Over || b.shift(grid.Down) {
+		return 0
+	}
+	return b.lock()
+}
+
+// drop slams the piece to the bottom and locks it.
+func (b *Board) drop() int {
+	if b.Over {
+		return 0
+	}
+	for b.shift(grid.Down) {
+	}
+	return b.lock()
+}
+
+// lock settles the piece, clears full rows and works out the attack.
+// Clears cancel incoming garbage first; a piece that clears nothing lets
+// the garbage rise.
+func (b *Board) lock() int {
+	kind, cells := b.Piece()
+	for _, c := range cells {
+		b.cells.Set(c, kind)
+	}
+
+	cleared := b.clear()
+	attack := 0
+	if cleared > 0 {
+		attack = ATTACK[cleared] + COMBO[min(b.combo, len(COMBO)-1)]
+		if cleared == 4 && b.tetris {
+			attack += BACKTOBACK
+		}
+		b.tetris = cleared == 4
+		b.combo++
+		b.Lines += cleared
+
+		cancel := min(attack, b.Pending)
+		b.Pending -= cancel
+		attack -= cancel
+		b.Sent += attack
+	} else {
+		b.combo = 0
+		b.rise()
+	}
+	b.spawn()
+	return attack
+}
+
+func (b *Board) clear() int {
+	cleared := 0
+	for y := HEIGHT - 1; y >= 0; y-- {
+		full := true
+		for x := 0; x < WIDTH && full; x++ {
+			full = b.cells.At(grid.Point{X: x, Y: y}) != 0
+		}
+		if !full {
+			continue
+		}
+		cleared++
+		for yy := y; yy > 0; yy-- {
+			for x := 0; x < WIDTH; x++ {
+				b.cells.Set(grid.Point{X: x, Y: yy}, b.cells.At(grid.Point{X: x, Y: yy - 1}))
+			}
+		}
+		for x := 0; x < WIDTH; x++ {
+			b.cells.Set(grid.Point{X: x, Y: 0}, 0)
+		}
+		y++ // look at the row that moved down
+	}
+	return cleared
+}
+
+// rise pushes the pending garbage in from the bottom, one gap per batch.
+// Anything pushed off the top tops the player out.
+func (b *Board) rise() {
+	n := min(b.Pending, HEIGHT)
+	if n == 0 {
+		return
+	}
+	b.Pending = 0
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			p := grid.Point{X: x, Y: y}
+			if y < n && b.cells.At(p) != 0 {
+				b.Over = true
+			}
+			b.cells.Set(p, b.cells.At(grid.Point{X: x, Y: y + n}))
+		}
+	}
+	gap := b.holes.Intn(WIDTH)
+	b.gaps++
+	for y := HEIGHT - n; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			if x != gap {
+				b.cells.Set(grid.Point{X: x, Y: y}, GARBAGE)
+			}
+		}
+	}
+}
+
+func (b *Board) clone() *Board {
+	c := *b
+	c.cells = b.cells.Clone()
+	c.queue = append([]int(nil), b.queue[:PREVIEW]...)
+	c.bag, c.holes = nil, nil
+	return &c
+}
+
+// savedBoard is a board as a match's snapshot keeps it.
+type savedBoard struct {
+	Cells   *grid.Grid[int]
+	Kind    int
+	Rot     int
+	Pos     grid.Point
+	Queue   []int
+	Seed    int64
+	Bags    int
+	Gaps    int
+	Pending int
+	Lines   int
+	Sent    int
+	Combo   int
+	Tetris  bool
+	Over    bool
+}
+
+func (b *Board) save() savedBoard {
+	return savedBoard{
+		Cells: b.cells, Kind: b.kind, Rot: b.rot, Pos: b.pos, Queue: b.queue,
+		Seed: b.seed, Bags: b.bags, Gaps: b.gaps,
+		Pending: b.Pending, Lines: b.Lines, Sent: b.Sent, Combo: b.combo, Tetris: b.tetris, Over: b.Over,
+	}
+}
+
+// restore brings back a saved board, replaying its random draws so it goes
+// on dealing what it would have.
+func (s savedBoard) restore() *Board {
+	b := &Board{
+		cells: s.Cells, kind: s.Kind, rot: s.Rot, pos: s.Pos, queue: s.Queue,
+		bag:   rand.New(rand.NewSource(s.Seed)),
+		holes: rand.New(rand.NewSource(s.Seed + 1)),
+		seed:  s.Seed, bags: s.Bags, gaps: s.Gaps,
+		Pending: s.Pending, Lines: s.Lines, Sent: s.Sent, combo: s.Combo, tetris: s.Tetris, Over: s.Over,
+	}
+	for range s.Bags {
+		b.bag.Perm(PIECES)
+	}
+	for range s.Gaps {
+		b.holes.Intn(WIDTH)
+	}
+	return b
+}
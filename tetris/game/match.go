@@ -0,0 +1,502 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/supervisor"
+)
+
+const (
+	COUNTDOWN = 3 * time.Second
+	STALE     = 3 * time.Second  // players not heard from for this long forfeit
+	RECONNECT = 30 * time.Second // how long a dropped ranked player has to come back
+	HICCUP    = 3 * time.Second  // how long players are told the match was rewound
+
+	GRAVITY    = 800 * time.Millisecond // between rows at the start
+	MINGRAVITY = 150 * time.Millisecond
+	SPEEDUP    = 50 * time.Millisecond // gravity gained every SPEEDEVERY
+	SPEEDEVERY = 20 * time.Second
+)
+
+// Queues, each matching its own players
+const (
+	RANKED = iota // rated, pairing players of close ratings
+	CASUAL        // unrated, pairing whoever comes first
+	QUEUES
+)
+
+// ABANDON is the rating a ranked player loses on top of the match when
+// they leave it before the end.
+const ABANDON = 10
+
+// Match phases
+const (
+	COUNTING = iota
+	PLAYING
+	OVER
+)
+
+// VOID is the winner of a match its supervisor gave up on, which nobody is
+// rated for.
+const VOID = -1
+
+type player struct {
+	name        string
+	label       string // the name with the player's title, as wells show it
+	fingerprint string
+	rating      int  // when the match was made
+	placing     bool // still playing placement matches, see rating.Placing
+	board       *Board
+	fall        time.Time // of the next row
+	seen        time.Time
+	away        time.Time // when a dropped player forfeits, zero while they're here
+}
+
+// Match is a versus game between two wells. Like snake duels it has no
+// goroutine: whichever session polls lets the pieces fall for the time that
+// passed. It stands still while a player is away, until they're back or
+// their RECONNECT runs out. Falls and moves run under a supervisor, so a
+// panic rewinds the wells instead of ending both sessions.
+type Match struct {
+	mu      sync.Mutex
+	phase   int
+	players [2]*player
+	start   time.Time
+	winner  int
+	paused  time.Time // since when a player is away, zero while both are here
+	sup     *supervisor.Supervisor
+	hiccup  string    // what players are told after a rewind
+	until   time.Time // of the hiccup
+}
+
+func newMatch(a, b *ticket, now time.Time) *Match {
+	m := &Match{phase: COUNTING, start: now.Add(COUNTDOWN)}
+	seed := now.UnixNano()
+	for i, t := range []*ticket{a, b} {
+		m.players[i] = &player{
+			name:        t.player.Name,
+			label:       t.player.Label(),
+			fingerprint: t.player.Fingerprint,
+			rating:      t.rating,
+			placing:     t.placing,
+			board:       newBoard(seed),
+			fall:        m.start.Add(GRAVITY),
+			seen:        now,
+		}
+	}
+	sup, err := supervisor.New("tetris "+a.player.Name+" vs "+b.player.Name, m, supervisor.Options{
+		Notify: func(h supervisor.Hiccup) { m.hiccup, m.until = h.String(), time.Now().Add(HICCUP) },
+	})
+	if err != nil {
+		log.Warn("Could not supervise match", "err", err)
+	}
+	m.sup = sup
+	return m
+}
+
+// tick lets the rows due by now fall under the supervisor.
+func (m *Match) tick(now time.Time) {
+	if m.sup == nil {
+		m.advance(now)
+		return
+	}
+	m.void(m.sup.Step(now))
+}
+
+// guard plays fn under the supervisor.
+func (m *Match) guard(fn func()) {
+	if m.sup == nil {
+		fn()
+		return
+	}
+	m.void(m.sup.Apply(func(supervisor.Loop) { fn() }))
+}
+
+// void calls the match off once the supervisor gives up on it.
+func (m *Match) void(err error) {
+	if err == nil {
+		return
+	}
+	log.Error("Match given up", "err", err)
+	m.sup = nil
+	m.end(VOID)
+}
+
+// gravity is the time between rows, shrinking as the match goes on.
+func (m *Match) gravity(now time.Time) time.Duration {
+	faster := time.Duration(now.Sub(m.start)/SPEEDEVERY) * SPEEDUP
+	return max(MINGRAVITY, GRAVITY-faster)
+}
+
+// advance drops every row due by now, unless a player is away.
+func (m *Match) advance(now time.Time) {
+	for i, p := range m.players {
+		switch {
+		case m.phase == OVER:
+		case !p.away.IsZero() && now.After(p.away):
+			m.end(1 - i)
+		case p.away.IsZero() && now.Sub(p.seen) > STALE:
+			m.end(1 - i)
+		}
+	}
+	if m.phase == OVER || !m.paused.IsZero() {
+		return
+	}
+	if m.phase == COUNTING && !now.Before(m.start) {
+		m.phase = PLAYING
+	}
+	for i, p := range m.players {
+		for m.phase == PLAYING && !now.Before(p.fall) {
+			m.attack(i, p.board.fall())
+			p.fall = p.fall.Add(m.gravity(p.fall))
+		}
+	}
+}
+
+// attack sends garbage from player i to the other, and settles the match
+// when either tops out.
+func (m *Match) attack(i, lines int) {
+	m.players[1-i].board.Pending += lines
+	for j, p := range m.players {
+		if p.board.Over {
+			m.end(1 - j)
+			return
+		}
+	}
+}
+
+func (m *Match) end(winner int) {
+	m.phase = OVER
+	m.winner = winner
+}
+
+// move plays an input of player i, if the match is on.
+func (m *Match) move(i int, f func(b *Board) int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.tick(now)
+	if m.phase != PLAYING || !m.paused.IsZero() {
+		return
+	}
+	m.guard(func() { m.attack(i, f(m.players[i].board)) })
+}
+
+// softDrop moves the piece down a row and restarts its fall timer.
+func (m *Match) softDrop(i int) {
+	m.move(i, func(b *Board) int {
+		p := m.players[i]
+		p.fall = time.Now().Add(m.gravity(time.Now()))
+		return b.fall()
+	})
+}
+
+// opponent is the player facing side i.
+func (m *Match) opponent(i int) block.Player {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := m.players[1-i]
+	return block.Player{Name: p.name, Fingerprint: p.fingerprint}
+}
+
+// leave forfeits player i, reporting false when the match was already
+// over.
+func (m *Match) leave(i int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.phase == OVER {
+		return false
+	}
+	m.end(1 - i)
+	return true
+}
+
+// drop holds player i's side for RECONNECT after their session ended,
+// reporting false when the match is already over.
+func (m *Match) drop(i int, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tick(now)
+	if m.phase == OVER {
+		return false
+	}
+	m.players[i].away = now.Add(RECONNECT)
+	if m.paused.IsZero() {
+		m.paused = now
+	}
+	return true
+}
+
+// rejoin hands player i their side back, if it's still held, and goes on
+// once nobody is away. The pause doesn't count towards falls or speed.
+func (m *Match) rejoin(i int, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tick(now)
+	p := m.players[i]
+	if m.phase == OVER || p.away.IsZero() {
+		return false
+	}
+	p.away, p.seen = time.Time{}, now
+	for _, o := range m.players {
+		if !o.away.IsZero() {
+			return true
+		}
+	}
+	paused := now.Sub(m.paused)
+	m.start = m.start.Add(paused)
+	for _, o := range m.players {
+		o.fall = o.fall.Add(paused)
+	}
+	m.paused = time.Time{}
+	return true
+}
+
+// forfeited reports whether player i lost by not coming back in time.
+func (m *Match) forfeited(i int, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tick(now)
+	return m.phase == OVER && m.winner != i && m.winner != VOID && !m.players[i].away.IsZero()
+}
+
+func (m *Match) over() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.phase == OVER
+}
+
+// Well is one side of the match as sessions render it.
+type Well struct {
+	Name    string
+	Rating  int
+	Placing bool
+	Board   *Board
+	Away    time.Time // when the player forfeits unless they're back, zero while here
+}
+
+type Snapshot struct {
+	Phase  int
+	Start  time.Time
+	Wells  [2]Well
+	Winner int
+	Hiccup string // set for a while after the match was rewound
+}
+
+// poll marks player i as present, plays what's due and describes the match.
+func (m *Match) poll(i int, now time.Time) Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.players[i].seen = now
+	m.tick(now)
+
+	s := Snapshot{Phase: m.phase, Start: m.start, Winner: m.winner}
+	if now.Before(m.until) {
+		s.Hiccup = m.hiccup
+	}
+	for j, p := range m.players {
+		s.Wells[j] = Well{Name: p.label, Rating: p.rating, Placing: p.placing, Board: p.board.clone(), Away: p.away}
+	}
+	return s
+}
+
+// saved is the part of a match its supervisor rewinds: the wells and their
+// falls, not who plays them or whether they're here.
+type saved struct {
+	Phase  int
+	Start  time.Time
+	Winner int
+	Boards [2]savedBoard
+	Falls  [2]time.Time
+}
+
+// Tick, Snapshot and Restore make the match a supervisor.Loop. The
+// supervisor only calls them from tick and guard, under the match's lock.
+func (m *Match) Tick(now time.Time) {
+	m.advance(now)
+}
+
+func (m *Match) Snapshot() ([]byte, error) {
+	s := saved{Phase: m.phase, Start: m.start, Winner: m.winner}
+	for i, p := range m.players {
+		s.Boards[i], s.Falls[i] = p.board.save(), p.fall
+	}
+	return json.Marshal(s)
+}
+
+func (m *Match) Restore(snapshot []byte) error {
+	var s saved
+	if err := json.Unmarshal(snapshot, &s); err != nil {
+		return err
+	}
+	for _, b := range s.Boards {
+		if b.Cells == nil {
+			return errors.New("snapshot without a well")
+		}
+	}
+	m.phase, m.start, m.winner = s.Phase, s.Start, s.Winner
+	for i, p := range m.players {
+		p.board, p.fall = s.Boards[i].restore(), s.Falls[i]
+	}
+	return nil
+}
+
+const (
+	WINDOW = 100 // rating difference accepted right away
+	WIDEN  = 25  // extra difference accepted per second of waiting
+)
+
+// ticket is a player looking for an opponent. Once matched it points at the
+// match and their side of it.
+type ticket struct {
+	queue   int
+	player  block.Player
+	rating  int
+	placing bool
+	since   time.Time
+	seen    time.Time
+	room    string // lobby room, empty for anyone
+	match   *Match
+	side    int
+}
+
+var (
+	queueMu sync.Mutex
+	queue   []*ticket
+)
+
+// window is how far apart in rating t accepts an opponent, which grows the
+// longer they wait so nobody waits forever.
+func (t *ticket) window(now time.Time) int {
+	return WINDOW + WIDEN*int(now.Sub(t.since).Seconds())
+}
+
+// enqueue starts looking for an opponent for p in a queue. Players from
+// a lobby room only play each other, whatever their ratings.
+func enqueue(q int, room string, p block.Player, rating int, placing bool, now time.Time) *ticket {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+
+	t := &ticket{queue: q, player: p, rating: rating, placing: placing, since: now, seen: now, room: room}
+	queue = append(queue, t)
+	return t
+}
+
+// search pairs t with the closest rated player in the queue that both
+// accept, and neither blocked, returning the match once there is one.
+func (t *ticket) search(now time.Time) (*Match, int) {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+
+	t.seen = now
+	if t.match != nil {
+		return t.match, t.side
+	}
+	queue = slices.DeleteFunc(queue, func(o *ticket) bool { return now.Sub(o.seen) > STALE })
+
+	var best *ticket
+	for _, o := range queue {
+		diff := abs(o.rating - t.rating)
+		switch {
+		case o == t || o.queue != t.queue || o.room != t.room || block.Apart(o.player, t.player):
+			continue
+		case t.queue == RANKED && t.room == "" && (diff > t.window(now) || diff > o.window(now)):
+			continue
+		}
+		if best == nil || diff < abs(best.rating-t.rating) {
+			best = o
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+
+	m := newMatch(best, t, now)
+	best.match, best.side = m, 0
+	t.match, t.side = m, 1
+	queue = slices.DeleteFunc(queue, func(o *ticket) bool { return o == t || o == best })
+	return m, 1
+}
+
+// waiting counts the players looking for an opponent in a queue, leaving
+// out those gathered in rooms.
+func waiting(q int) int {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+
+	now := time.Now()
+	n := 0
+	for _, t := range queue {
+		if t.queue == q && t.room == "" && now.Sub(t.seen) <= STALE {
+			n++
+		}
+	}
+	return n
+}
+
+// cancel stops looking for an opponent.
+func (t *ticket) cancel() {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	queue = slices.DeleteFunc(queue, func(o *ticket) bool { return o == t })
+}
+
+// seat is a dropped player's side of a match.
+type seat struct {
+	match *Match
+	side  int
+}
+
+var (
+	heldMu sync.Mutex
+	held   = make(map[string]seat) // by fingerprint
+)
+
+// hold keeps a dropped player's seat for them to rejoin, forgetting the
+// seats of matches that are over.
+func hold(fingerprint string, m *Match, side int) {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+
+	for fp, s := range held {
+		if s.match.over() {
+			delete(held, fp)
+		}
+	}
+	held[fingerprint] = seat{m, side}
+}
+
+// rejoin gives a player back the match they dropped from, if it waits for
+// them still.
+func rejoin(fingerprint string, now time.Time) (*Match, int, bool) {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+
+	s, ok := held[fingerprint]
+	if !ok {
+		return nil, 0, false
+	}
+	delete(held, fingerprint)
+	if !s.match.rejoin(s.side, now) {
+		return nil, 0, false
+	}
+	return s.match, s.side, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
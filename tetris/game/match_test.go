@@ -0,0 +1,67 @@
+package game
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+)
+
+func TestPanicRewindsWells(t *testing.T) {
+	a := &ticket{player: block.Player{Name: "alice"}}
+	b := &ticket{player: block.Player{Name: "bob"}}
+	// Make the match a countdown and a row ago, so a row is due now.
+	m := newMatch(a, b, time.Now().Add(-COUNTDOWN-GRAVITY))
+	for _, p := range m.players {
+		p.seen = time.Now()
+	}
+	next := slices.Clone(m.players[0].board.Next())
+
+	// A well without cells panics as soon as its piece falls.
+	m.players[0].board.cells = nil
+	snap := m.poll(0, time.Now())
+	if m.players[0].board.cells == nil {
+		t.Fatal("the well wasn't restored")
+	}
+	if !slices.Equal(snap.Wells[0].Board.Next(), next) {
+		t.Errorf("next pieces %v after the rewind, want %v", snap.Wells[0].Board.Next(), next)
+	}
+	if snap.Hiccup == "" {
+		t.Error("players weren't told the match was rewound")
+	}
+}
+
+func TestRestoredBoardDealsTheSame(t *testing.T) {
+	b := newBoard(7)
+	for range 20 {
+		b.drop()
+	}
+	b.Pending = 2
+	b.rise()
+
+	data, err := json.Marshal(b.save())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s savedBoard
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	r := s.restore()
+	for range 20 {
+		if b.kind != r.kind || !slices.Equal(b.Next(), r.Next()) {
+			t.Fatalf("restored board deals %d %v, want %d %v", r.kind, r.Next(), b.kind, b.Next())
+		}
+		b.drop()
+		r.drop()
+	}
+	b.Pending, r.Pending = 1, 1
+	b.rise()
+	r.rise()
+	if b.cells.At(grid.Point{X: 0, Y: HEIGHT - 1}) != r.cells.At(grid.Point{X: 0, Y: HEIGHT - 1}) {
+		t.Error("restored board picked another garbage gap")
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import "github.com/debemdeboas/games.debem.dev/grid"
+
+// Piece kinds; 0 is an empty cell and GARBAGE a row sent by the opponent.
+const (
+	I = iota + 1
+	O
+	T
+	S
+	Z
+	J
+	L
+	GARBAGE
+)
+
+const PIECES = 7
+
+// piece is a tetromino's cells in a size×size box, at spawn rotation.
+type piece struct {
+	size  int
+	cells [4]grid.Point
+	color string
+}
+
+var pieces = [PIECES + 1]piece{
+	I: {size: 4, cells: [4]grid.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 1}}, color: "14"},
+	O: {size: 2, cells: [4]grid.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}, color: "11"},
+	T: {size: 3, cells: [4]grid.Point{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}, color: "13"},
+	S: {size: 3, cells: [4]grid.Point{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}, color: "10"},
+	Z: {size: 3, cells: [4]grid.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}}, color: "9"},
+	J: {size: 3, cells: [4]grid.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}, color: "12"},
+	L: {size: 3, cells: [4]grid.Point{{X: 2, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}, color: "208"},
+}
+
+// garbageColor paints the rows sent by the opponent.
+const garbageColor = "8"
+
+// rotations holds every piece's four orientations, clockwise from spawn.
+var rotations = func() (r [PIECES + 1][4][4]grid.Point) {
+	for kind := I; kind <= PIECES; kind++ {
+		p := pieces[kind]
+		cells := p.cells
+		for rot := 0; rot < 4; rot++ {
+			r[kind][rot] = cells
+			for i, c := range cells {
+				cells[i] = grid.Point{X: p.size - 1 - c.Y, Y: c.X}
+			}
+		}
+	}
+	return r
+}()
+
+// kicks are the offsets a rotation tries when the piece doesn't fit where
+// it is, so pieces can turn against walls and the stack.
+var kicks = []grid.Point{{}, {X: -1}, {X: 1}, {X: -2}, {X: 2}, {Y: -1}}
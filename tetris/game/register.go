@@ -0,0 +1,67 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	GAMENAME   = "tetris-versus"
+	CASUALNAME = "tetris-casual"
+)
+
+// infos describes the game of each queue.
+var infos = [QUEUES]games.Info{
+	RANKED: {
+		ID:          GAMENAME,
+		Title:       "Tetris Versus",
+		Description: "Clear lines to bury a rated opponent in garbage",
+		Category:    games.MULTIPLAYER,
+		MinPlayers:  2,
+		MaxPlayers:  2,
+		Spectating:  true,
+		Delay:       spectate.RANKEDDELAY,
+		Rooms:       true,
+		Session:     3 * time.Minute,
+		Waiting:     func() int { return waiting(RANKED) },
+	},
+	CASUAL: {
+		ID:          CASUALNAME,
+		Title:       "Tetris Casual",
+		Description: "Versus Tetris for fun, with no rating at stake",
+		Category:    games.MULTIPLAYER,
+		MinPlayers:  2,
+		MaxPlayers:  2,
+		Spectating:  true,
+		Rooms:       true,
+		Session:     3 * time.Minute,
+		Waiting:     func() int { return waiting(CASUAL) },
+	},
+}
+
+func init() {
+	for queue, info := range infos {
+		games.Register(info, func(env games.Env) (games.Game, error) {
+			m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+			m.Queue = queue
+			m.SetContext(env.Ctx)
+			m.SetLayout(ui.LayoutFromEnv(env.Environ))
+			m.Room = env.Room
+			if env.Profile != nil {
+				m.SetProfile(env.Profile, env.Profiles, env.Fingerprint)
+			}
+			return m, nil
+		})
+	}
+}
+
+func (m Model) Name() string {
+	return infos[m.Queue].Title
+}
+
+func (m Model) Description() string {
+	return infos[m.Queue].Description
+}
@@ -0,0 +1,507 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/rating"
+	"github.com/debemdeboas/games.debem.dev/spectate"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 50 * time.Millisecond
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	BoardStyle   lipgloss.Style
+	GarbageStyle lipgloss.Style
+	QuitStyle    lipgloss.Style
+	BoxStyle     lipgloss.Style
+	WinStyle     lipgloss.Style
+	FailStyle    lipgloss.Style
+	pieceStyles  [GARBAGE + 1]lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Profiles, when set, keeps the player's versus rating.
+	Profiles    profile.Store
+	Fingerprint string
+	profile     *profile.Profile
+
+	Player block.Player
+	// Queue is RANKED or CASUAL, where the player looks for opponents.
+	Queue int
+	// Room is the lobby room the player came from, if any.
+	Room   string
+	ticket *ticket // while looking for an opponent
+	match  *Match
+	side   int
+	snap   Snapshot
+	rated  bool      // whether this match already counted towards the rating
+	change int       // rating change of the last match
+	until  time.Time // when the player may queue for ranked matches again, after abandons
+	// cast follows the match for casters, see Scoreboard.
+	cast spectate.Match
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:        width,
+		Height:       height,
+		BoardStyle:   r.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")),
+		GarbageStyle: r.NewStyle().Foreground(lipgloss.Color("9")),
+		QuitStyle:    r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		WinStyle:  r.NewStyle().Foreground(lipgloss.Color("10")).Bold(true),
+		FailStyle: r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+		Keys:      DefaultKeyMap(),
+		Player:    player,
+		profile:   &profile.Profile{},
+		ctx:       context.Background(),
+	}
+	for kind := I; kind <= PIECES; kind++ {
+		m.pieceStyles[kind] = r.NewStyle().Foreground(lipgloss.Color(pieces[kind].color))
+	}
+	m.pieceStyles[GARBAGE] = r.NewStyle().Foreground(lipgloss.Color(garbageColor))
+	m.help = ui.NewHelp(m.QuitStyle)
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's. A ranked
+// match still on when ctx ends waits RECONNECT for the player to come back,
+// and is abandoned if they don't.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+	context.AfterFunc(ctx, m.disconnect)
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+// SetProfile matches the player by their saved versus rating and saves
+// changes back to store, if set.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+}
+
+// Rating is the player's versus rating.
+func (m Model) Rating() int {
+	if r, ok := m.profile.Ratings[rating.TETRIS]; ok {
+		return r
+	}
+	return rating.DEFAULT
+}
+
+// placing reports whether the player is still playing their ranked
+// placement matches.
+func (m Model) placing() bool {
+	return rating.Placing(m.profile.Rated[rating.TETRIS])
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Search queues the player for an opponent, close to their rating when
+// ranked. A ranked match they dropped from is picked up again instead, and
+// players cooling down after abandons wait for it to pass.
+func (m *Model) Search() {
+	now := time.Now()
+	m.match = nil
+	m.rated = false
+	if m.Queue == RANKED && m.Fingerprint != "" {
+		if match, side, ok := rejoin(m.Fingerprint, now); ok {
+			m.match, m.side = match, side
+			m.refresh(now)
+			return
+		}
+	}
+	if wait := m.cooldown(now); wait > 0 {
+		m.until = now.Add(wait)
+		return
+	}
+	m.ticket = enqueue(m.Queue, m.Room, m.Player, m.Rating(), m.placing(), now)
+	m.refresh(now)
+}
+
+// cooldown is how long the player waits to queue, after leaving ranked
+// matches early.
+func (m Model) cooldown(now time.Time) time.Duration {
+	if m.Queue != RANKED {
+		return 0
+	}
+	return rating.Cooldown(m.profile.Abandons[rating.TETRIS], now)
+}
+
+func (m *Model) refresh(now time.Time) {
+	if m.match == nil {
+		if m.ticket == nil {
+			m.Search()
+			return
+		}
+		if m.match, m.side = m.ticket.search(now); m.match == nil {
+			return
+		}
+		m.ticket = nil
+	}
+	m.snap = m.match.poll(m.side, now)
+	m.score()
+	switch {
+	case m.snap.Phase == OVER && m.snap.Winner == VOID:
+		m.rated = true
+	case m.snap.Phase == OVER:
+		m.rate(m.snap.Winner == m.side, 0)
+	}
+}
+
+// score follows the match for casters, who see the garbage each side sent
+// and, in ranked matches, their ratings.
+func (m *Model) score() {
+	if m.snap.Phase == COUNTING {
+		m.cast = spectate.Match{Title: m.Name()}
+	}
+	m.cast.Track(m.snap.Wells[0].Board.Sent, m.snap.Wells[1].Board.Sent)
+	for i, w := range m.snap.Wells {
+		m.cast.Players[i].Name = w.Name
+		if m.Queue == RANKED {
+			m.cast.Players[i].Rating = w.Rating
+		}
+	}
+}
+
+// Scoreboard is the match for casters, see spectate.Scoreboard.
+func (m Model) Scoreboard() (spectate.Match, bool) {
+	return m.cast, m.match != nil
+}
+
+// rate scores a ranked match against the opponent's rating when it was
+// made, taking penalty off on top and marking the abandon it's for towards
+// queue cooldowns. Casual matches don't count. Only these fields are
+// written, so the match ending after the player moved on undoes nothing.
+func (m *Model) rate(won bool, penalty int) {
+	if m.rated {
+		return
+	}
+	m.rated = true
+	if m.Queue != RANKED {
+		return
+	}
+
+	score := 0.0
+	if won {
+		score = 1
+	}
+	player := m.Rating()
+	updated := rating.UpdatePlayed(player, m.snap.Wells[1-m.side].Rating, score, m.profile.Rated[rating.TETRIS]) - penalty
+	m.change = updated - player
+
+	profile.Update(m.Profiles, m.Fingerprint, m.profile, func(p *profile.Profile) {
+		if p.Ratings == nil {
+			p.Ratings = make(map[string]int)
+		}
+		if p.Rated == nil {
+			p.Rated = make(map[string]int)
+		}
+		p.Ratings[rating.TETRIS] = updated
+		p.Rated[rating.TETRIS]++
+		if penalty > 0 {
+			if p.Abandons == nil {
+				p.Abandons = make(map[string][]time.Time)
+			}
+			now := time.Now()
+			p.Abandons[rating.TETRIS] = append(rating.Recent(p.Abandons[rating.TETRIS], now), now)
+		}
+	})
+}
+
+// Opponents are the player the current match is against, for the lobby to
+// report.
+func (m *Model) Opponents() []block.Player {
+	if m.match == nil {
+		return nil
+	}
+	return []block.Player{m.match.opponent(m.side)}
+}
+
+// leave forfeits the match, or stops looking for one.
+func (m *Model) leave() {
+	switch {
+	case m.match != nil:
+		if m.match.leave(m.side) {
+			m.abandon()
+		}
+	case m.ticket != nil:
+		m.ticket.cancel()
+	}
+}
+
+// disconnect holds a ranked match for RECONNECT when the player's session
+// ends in the middle of it, and charges the abandon if they're not back in
+// time. Players without a key can't come back, so they leave.
+func (m *Model) disconnect() {
+	now := time.Now()
+	if m.match == nil || m.Queue != RANKED || m.Fingerprint == "" || !m.match.drop(m.side, now) {
+		m.leave()
+		return
+	}
+	match, side := m.match, m.side
+	hold(m.Fingerprint, match, side)
+	time.AfterFunc(RECONNECT+POLL, func() {
+		if match.forfeited(side, time.Now()) {
+			m.abandon()
+		}
+	})
+}
+
+// abandon charges the player for leaving a match before the end: the loss,
+// ABANDON more and, for ranked ones, a mark towards queue cooldowns.
+func (m *Model) abandon() {
+	m.rate(false, ABANDON)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.leave()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case m.match == nil:
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == OVER {
+				m.Search()
+			}
+		case key.Matches(msg, m.Keys.Left):
+			m.match.move(m.side, func(b *Board) int { b.shift(grid.Left); return 0 })
+		case key.Matches(msg, m.Keys.Right):
+			m.match.move(m.side, func(b *Board) int { b.shift(grid.Right); return 0 })
+		case key.Matches(msg, m.Keys.Up), key.Matches(msg, m.Keys.Rotate):
+			m.match.move(m.side, func(b *Board) int { b.rotate(); return 0 })
+		case key.Matches(msg, m.Keys.Down):
+			m.match.softDrop(m.side)
+		case key.Matches(msg, m.Keys.Drop):
+			m.match.move(m.side, (*Board).drop)
+		}
+		if m.match != nil {
+			m.refresh(time.Now())
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func (m Model) wellView(b *Board) string {
+	cells := grid.New[string](WIDTH, HEIGHT)
+	cells.Each(func(p grid.Point, _ string) {
+		if kind := b.At(p); kind != 0 {
+			cells.Set(p, m.pieceStyles[kind].Render("██"))
+		} else {
+			cells.Set(p, m.QuitStyle.Render(" ."))
+		}
+	})
+	if !b.Over {
+		kind, piece := b.Piece()
+		for _, p := range b.Ghost() {
+			cells.Set(p, m.pieceStyles[kind].Faint(true).Render("░░"))
+		}
+		for _, p := range piece {
+			cells.Set(p, m.pieceStyles[kind].Render("██"))
+		}
+	}
+
+	var s strings.Builder
+	for y := 0; y < HEIGHT; y++ {
+		// The gauge beside the well shows garbage about to rise.
+		if HEIGHT-y <= b.Pending {
+			s.WriteString(m.GarbageStyle.Render("▌"))
+		} else {
+			s.WriteString(" ")
+		}
+		for x := 0; x < WIDTH; x++ {
+			s.WriteString(cells.At(grid.Point{X: x, Y: y}))
+		}
+		if y < HEIGHT-1 {
+			s.WriteString("\n")
+		}
+	}
+	return m.BoardStyle.Render(s.String())
+}
+
+func (m Model) nextView(b *Board) string {
+	lines := []string{"Next", ""}
+	for _, kind := range b.Next() {
+		var rows [2][4]string
+		for y := range rows {
+			for x := range rows[y] {
+				rows[y][x] = "  "
+			}
+		}
+		for _, c := range pieces[kind].cells {
+			if c.Y < 2 {
+				rows[c.Y][c.X] = m.pieceStyles[kind].Render("██")
+			}
+		}
+		for _, r := range rows {
+			lines = append(lines, strings.Join(r[:], ""))
+		}
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) sideView(i int) string {
+	w := m.snap.Wells[i]
+	name := w.Name
+	if i == m.side {
+		name += " (you)"
+	}
+	title := name
+	switch {
+	case m.Queue != RANKED:
+	case w.Placing:
+		title += " (placing)"
+	default:
+		title += fmt.Sprintf(" (%d)", w.Rating)
+	}
+	stats := fmt.Sprintf("Lines %d • Sent %d", w.Board.Lines, w.Board.Sent)
+	return lipgloss.JoinVertical(lipgloss.Left, title, m.wellView(w.Board), m.QuitStyle.Render(stats))
+}
+
+func (m Model) status() string {
+	if w := m.snap.Wells[1-m.side]; !w.Away.IsZero() && m.snap.Phase != OVER {
+		left := max(0, time.Until(w.Away))
+		return fmt.Sprintf("%s dropped, waiting %d seconds for them to come back", w.Name, int(left.Seconds())+1)
+	}
+	switch m.snap.Phase {
+	case COUNTING:
+		left := max(0, time.Until(m.snap.Start))
+		return fmt.Sprintf("Starting in %d", int(left.Seconds())+1)
+	case PLAYING:
+		if m.snap.Hiccup != "" {
+			return m.snap.Hiccup
+		}
+		return "Clear lines to send garbage!"
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	if m.snap.Winner == VOID {
+		return m.BoxStyle.Render(fmt.Sprintf("The match was called off after it kept crashing, no rating at stake\n\nPress '%s' for a new match",
+			m.Keys.Rematch.Help().Key))
+	}
+	result := m.FailStyle.Render(m.snap.Wells[m.snap.Winner].Name + " wins")
+	switch other := m.snap.Wells[1-m.side]; {
+	case m.snap.Winner == m.side && !other.Away.IsZero():
+		result = m.WinStyle.Render("You win!") + "\n" + other.Name + " didn't come back"
+	case m.snap.Winner == m.side:
+		result = m.WinStyle.Render("You win!")
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s\n%s\n\nPress '%s' for a new match",
+		result, m.standing(), m.Keys.Rematch.Help().Key))
+}
+
+// standing is where the match left the player's rating.
+func (m Model) standing() string {
+	played := m.profile.Rated[rating.TETRIS]
+	switch {
+	case m.Queue != RANKED:
+		return "Casual match, no rating at stake"
+	case rating.Placing(played):
+		return fmt.Sprintf("Placement match %d of %d", played, rating.PLACEMENTS)
+	case played == rating.PLACEMENTS:
+		return fmt.Sprintf("Placed at %d", m.Rating())
+	}
+	return fmt.Sprintf("Rating %d (%+d)", m.Rating(), m.change)
+}
+
+// searchView tells the player what they're waiting for.
+func (m Model) searchView() string {
+	switch {
+	case m.Queue != RANKED:
+		return "Looking for an opponent..."
+	case m.ticket == nil && time.Now().Before(m.until):
+		left := time.Until(m.until).Truncate(time.Second) + time.Second
+		return fmt.Sprintf("You left %d ranked matches early in the last day.\nRanked play opens again in %s,\n%s is open meanwhile.",
+			len(rating.Recent(m.profile.Abandons[rating.TETRIS], time.Now())), left, infos[CASUAL].Title)
+	case m.placing():
+		return fmt.Sprintf("Looking for an opponent for placement match %d of %d...", m.profile.Rated[rating.TETRIS]+1, rating.PLACEMENTS)
+	}
+	return fmt.Sprintf("Looking for an opponent near %d...", m.Rating())
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.match == nil {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.searchView()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == OVER {
+		bottom = m.resultView()
+	}
+	you := m.snap.Wells[m.side].Board
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			lipgloss.JoinHorizontal(lipgloss.Top, m.sideView(m.side), "  ", m.nextView(you), "  ", m.sideView(1-m.side)),
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
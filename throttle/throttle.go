@@ -0,0 +1,64 @@
+// Package throttle limits how often clients of the HTTP API may ask for
+// work that's expensive to serve, like rendering replays or widgets, and
+// how often players may write in from the lobby.
+package throttle
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limiter allows each client a number of requests per window. Windows start
+// at a client's first request, so there's no rush at the turn of a minute.
+type Limiter struct {
+	window time.Duration
+	limit  int
+
+	mu      sync.Mutex
+	clients map[string]*window
+}
+
+// window counts a client's requests.
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter allows each client limit requests per window of length per.
+func NewLimiter(limit int, per time.Duration) *Limiter {
+	return &Limiter{window: per, limit: limit, clients: make(map[string]*window)}
+}
+
+// Allow counts a request by client, unless it's out of requests for the
+// window. It drops the windows that ended on the way.
+func (l *Limiter) Allow(client string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for c, win := range l.clients {
+		if now.Sub(win.start) > l.window {
+			delete(l.clients, c)
+		}
+	}
+	win, ok := l.clients[client]
+	if !ok {
+		win = &window{start: now}
+		l.clients[client] = win
+	}
+	if win.count >= l.limit {
+		return false
+	}
+	win.count++
+	return true
+}
+
+// Client identifies who asked, by address without the port.
+func Client(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"math/rand"
+)
+
+const SIZE = 3
+
+// Marks
+const (
+	EMPTY = iota
+	X
+	O
+)
+
+// Board is the grid, a mark per cell, row by row.
+type Board [SIZE * SIZE]int
+
+// lines are the rows, columns and diagonals that win.
+var lines = [][SIZE]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// Winner is the mark with a full line and the line, or EMPTY when nobody
+// has one.
+func (b *Board) Winner() (int, [SIZE]int) {
+	for _, l := range lines {
+		if c := b[l[0]]; c != EMPTY && b[l[1]] == c && b[l[2]] == c {
+			return c, l
+		}
+	}
+	return EMPTY, [SIZE]int{}
+}
+
+func (b *Board) Full() bool {
+	for _, c := range b {
+		if c == EMPTY {
+			return false
+		}
+	}
+	return true
+}
+
+// Over reports whether someone won or there's nowhere left to play.
+func (b *Board) Over() bool {
+	w, _ := b.Winner()
+	return w != EMPTY || b.Full()
+}
+
+func other(mark int) int {
+	return X + O - mark
+}
+
+// score rates the board for mark to play next by minimax: positive when
+// mark wins with best play, sooner wins scoring higher, negative when it
+// loses and zero for a draw.
+func (b *Board) score(mark int, depth int) int {
+	if w, _ := b.Winner(); w != EMPTY {
+		// The last move won, so it was the other mark's.
+		return depth - 10
+	}
+	if b.Full() {
+		return 0
+	}
+	best := -10
+	for i, c := range b {
+		if c != EMPTY {
+			continue
+		}
+		b[i] = mark
+		best = max(best, -b.score(other(mark), depth+1))
+		b[i] = EMPTY
+	}
+	return best
+}
+
+// Best picks a move for mark with perfect play, at random between moves
+// that are as good, so the computer doesn't always play the same game.
+func (b Board) Best(mark int, rng *rand.Rand) int {
+	var moves []int
+	best := -11
+	for i, c := range b {
+		if c != EMPTY {
+			continue
+		}
+		b[i] = mark
+		s := -b.score(other(mark), 1)
+		b[i] = EMPTY
+		switch {
+		case s > best:
+			best, moves = s, []int{i}
+		case s == best:
+			moves = append(moves, i)
+		}
+	}
+	if len(moves) == 0 {
+		return -1
+	}
+	return moves[rng.Intn(len(moves))]
+}
@@ -0,0 +1,237 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+const (
+	STALE    = 3 * time.Second        // players not heard from for this long forfeit
+	BOTDELAY = 600 * time.Millisecond // the computer takes to "think"
+)
+
+// Match phases
+const (
+	WAITING = iota
+	PLAYING
+	OVER
+)
+
+// BOTNAME names the computer opponent.
+const BOTNAME = "Computer"
+
+type side struct {
+	name string
+	seat block.Player // who sat down, empty for the computer
+	mark int
+	bot  bool // played by the match itself, never goes quiet
+	seen time.Time
+}
+
+// Match is a board shared by two sessions, or by a player and the
+// computer. Like pong matches it has no goroutine: moves and polls settle
+// it as they come, the computer's moves included.
+type Match struct {
+	mu     sync.Mutex
+	phase  int
+	sides  []*side
+	board  Board
+	turn   int       // the mark to play
+	botAt  time.Time // when the computer moves, if it's its turn
+	winner int       // side, or -1 for a draw
+	rng    *rand.Rand
+}
+
+var (
+	matchesMu sync.Mutex
+	waiting   = make(map[string][]*Match) // by lobby room, empty for anyone
+)
+
+func newMatch(p block.Player, now time.Time) *Match {
+	m := &Match{phase: WAITING, winner: -1, rng: rand.New(rand.NewSource(now.UnixNano()))}
+	m.sides = []*side{{name: p.Label(), seat: p, seen: now}}
+	return m
+}
+
+// join pairs p with the first player waiting for an opponent from the same
+// lobby room who neither blocked nor was blocked by them, or waits for one.
+func join(room string, p block.Player, now time.Time) (*Match, int) {
+	matchesMu.Lock()
+	defer matchesMu.Unlock()
+
+	// Rooms come and go, so forget the matches nobody waits at anymore.
+	for k, waits := range waiting {
+		waits = slices.DeleteFunc(waits, func(m *Match) bool {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.drop(now)
+			return m.phase != WAITING || len(m.sides) == 0
+		})
+		if len(waits) == 0 {
+			delete(waiting, k)
+		} else {
+			waiting[k] = waits
+		}
+	}
+
+	for i, m := range waiting[room] {
+		m.mu.Lock()
+		if m.phase == WAITING && len(m.sides) == 1 && !block.Apart(m.sides[0].seat, p) {
+			waiting[room] = slices.Delete(waiting[room], i, i+1)
+			m.sides = append(m.sides, &side{name: p.Label(), seat: p, seen: now})
+			m.begin(now)
+			m.mu.Unlock()
+			return m, 1
+		}
+		m.mu.Unlock()
+	}
+
+	m := newMatch(p, now)
+	waiting[room] = append(waiting[room], m)
+	return m, 0
+}
+
+// versusBot starts a match between p and the computer.
+func versusBot(p block.Player, now time.Time) (*Match, int) {
+	m := newMatch(p, now)
+	m.sides = append(m.sides, &side{name: BOTNAME, bot: true})
+	m.begin(now)
+	return m, 0
+}
+
+// begin deals the marks at random. X goes first.
+func (m *Match) begin(now time.Time) {
+	m.phase = PLAYING
+	first := m.rng.Intn(2)
+	m.sides[first].mark = X
+	m.sides[1-first].mark = O
+	m.turn = X
+	m.botAt = now.Add(BOTDELAY)
+}
+
+// drop removes players that went quiet while waiting, and makes them
+// forfeit once the match is on.
+func (m *Match) drop(now time.Time) {
+	if m.phase == WAITING {
+		m.sides = slices.DeleteFunc(m.sides, func(s *side) bool { return m.quiet(s, now) })
+		return
+	}
+	if m.phase == OVER {
+		return
+	}
+	for i, s := range m.sides {
+		if m.quiet(s, now) {
+			m.end(1 - i)
+			return
+		}
+	}
+}
+
+func (m *Match) quiet(s *side, now time.Time) bool {
+	return !s.bot && now.Sub(s.seen) > STALE
+}
+
+func (m *Match) end(winner int) {
+	m.phase = OVER
+	m.winner = winner
+}
+
+// mover is the side whose turn it is.
+func (m *Match) mover() int {
+	if m.sides[0].mark == m.turn {
+		return 0
+	}
+	return 1
+}
+
+// play puts the mark of the side to move in cell, ending the match once
+// the board is.
+func (m *Match) play(cell int, now time.Time) {
+	m.board[cell] = m.turn
+	m.turn = other(m.turn)
+	m.botAt = now.Add(BOTDELAY)
+	if w, _ := m.board.Winner(); w != EMPTY {
+		m.end(1 - m.mover())
+	} else if m.board.Full() {
+		m.end(-1)
+	}
+}
+
+// place has side i mark cell, if it's their turn and the cell is free.
+func (m *Match) place(i, cell int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase != PLAYING || i >= len(m.sides) || m.mover() != i || m.board[cell] != EMPTY {
+		return
+	}
+	m.play(cell, time.Now())
+}
+
+// advance has the computer move once it has "thought" long enough.
+func (m *Match) advance(now time.Time) {
+	m.drop(now)
+	if m.phase != PLAYING || !m.sides[m.mover()].bot || now.Before(m.botAt) {
+		return
+	}
+	m.play(m.board.Best(m.turn, m.rng), now)
+}
+
+// opponents are the players facing side i, the computer left out.
+func (m *Match) opponents(i int) []block.Player {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ps []block.Player
+	for j, s := range m.sides {
+		if !s.bot && j != i {
+			ps = append(ps, s.seat)
+		}
+	}
+	return ps
+}
+
+// leave forfeits side i.
+func (m *Match) leave(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < len(m.sides) {
+		m.sides[i].seen = time.Time{}
+	}
+	m.drop(time.Now())
+}
+
+// Player is one side of the board as sessions render it.
+type Player struct {
+	Name string
+	Mark int
+}
+
+type Snapshot struct {
+	Phase   int
+	Board   Board
+	Turn    int // the mark to play
+	Players []Player
+	Winner  int // side, or -1 for a draw
+}
+
+// poll marks side i as present, settles the match and describes it.
+func (m *Match) poll(i int, now time.Time) (Snapshot, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i >= len(m.sides) {
+		return Snapshot{}, false
+	}
+	m.sides[i].seen = now
+	m.advance(now)
+
+	s := Snapshot{Phase: m.phase, Board: m.board, Turn: m.turn, Winner: m.winner}
+	for _, sd := range m.sides {
+		s.Players = append(s.Players, Player{Name: sd.name, Mark: sd.mark})
+	}
+	return s, true
+}
@@ -0,0 +1,295 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/grid"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 100 * time.Millisecond
+
+var markNames = [...]string{EMPTY: " ", X: "X", O: "O"}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles, one per mark
+	MarkStyles [3]lipgloss.Style
+	WinStyle   lipgloss.Style
+	LineStyle  lipgloss.Style
+	QuitStyle  lipgloss.Style
+	BoxStyle   lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Room is the lobby room the player came from, if any.
+	Room   string
+	match  *Match
+	side   int
+	snap   Snapshot
+	cursor grid.Point
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:  width,
+		Height: height,
+		MarkStyles: [3]lipgloss.Style{
+			EMPTY: r.NewStyle(),
+			X:     r.NewStyle().Foreground(lipgloss.Color("9")).Bold(true),
+			O:     r.NewStyle().Foreground(lipgloss.Color("39")).Bold(true),
+		},
+		WinStyle:  r.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("10")).Bold(true),
+		LineStyle: r.NewStyle().Foreground(lipgloss.Color("8")),
+		QuitStyle: r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Align(lipgloss.Center).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		cursor: grid.Point{X: SIZE / 2, Y: SIZE / 2},
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Join waits for an opponent, or takes on the one waiting.
+func (m *Model) Join() {
+	now := time.Now()
+	m.match, m.side = join(m.Room, m.Player, now)
+	m.refresh(now)
+}
+
+// Opponents are the players the current match is against, for the lobby
+// to report.
+func (m *Model) Opponents() []block.Player {
+	return m.match.opponents(m.side)
+}
+
+// SetRoom waits for the opponent of the player's lobby room instead.
+func (m *Model) SetRoom(room string) {
+	m.match.leave(m.side)
+	m.Room = room
+	m.Join()
+}
+
+// PlayBot gives up waiting and plays the computer instead.
+func (m *Model) PlayBot() {
+	m.match.leave(m.side)
+	now := time.Now()
+	m.match, m.side = versusBot(m.Player, now)
+	m.refresh(now)
+}
+
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.match.poll(m.side, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	m.snap = snap
+}
+
+func (m *Model) moveCursor(d grid.Point) {
+	m.cursor.X = max(0, min(SIZE-1, m.cursor.X+d.X))
+	m.cursor.Y = max(0, min(SIZE-1, m.cursor.Y+d.Y))
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.match.leave(m.side)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case key.Matches(msg, m.Keys.Rematch):
+			if m.snap.Phase == OVER {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Bot):
+			if m.snap.Phase == WAITING || m.snap.Phase == OVER {
+				m.PlayBot()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.moveCursor(grid.Up)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveCursor(grid.Down)
+		case key.Matches(msg, m.Keys.Left):
+			m.moveCursor(grid.Left)
+		case key.Matches(msg, m.Keys.Right):
+			m.moveCursor(grid.Right)
+		case key.Matches(msg, m.Keys.Place):
+			m.match.place(m.side, m.cursor.Y*SIZE+m.cursor.X)
+			m.refresh(time.Now())
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+// cell draws a cell five columns wide, the cursor in brackets so it shows
+// without colors too.
+func (m Model) cell(i int, win bool) string {
+	mark := m.snap.Board[i]
+	s := m.MarkStyles[mark].Render(markNames[mark])
+	if win {
+		s = m.WinStyle.Render(markNames[mark])
+	}
+	if i == m.cursor.Y*SIZE+m.cursor.X && m.snap.Phase == PLAYING {
+		return " [" + s + "] "
+	}
+	return "  " + s + "  "
+}
+
+func (m Model) boardView() string {
+	var line []int
+	if w, l := m.snap.Board.Winner(); w != EMPTY {
+		line = l[:]
+	}
+	bar := m.LineStyle.Render("│")
+	rule := m.LineStyle.Render(strings.Repeat("─────┼", SIZE-1) + "─────")
+	var rows []string
+	for y := 0; y < SIZE; y++ {
+		var cells []string
+		for x := 0; x < SIZE; x++ {
+			i := y*SIZE + x
+			cells = append(cells, m.cell(i, slices.Contains(line, i)))
+		}
+		if y > 0 {
+			rows = append(rows, rule)
+		}
+		rows = append(rows, strings.Join(cells, bar))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m Model) header() string {
+	var sides []string
+	for i, p := range m.snap.Players {
+		name := p.Name
+		if i == m.side {
+			name += " (you)"
+		}
+		sides = append(sides, fmt.Sprintf("%s %s", name, m.MarkStyles[p.Mark].Render(markNames[p.Mark])))
+	}
+	return strings.Join(sides, m.QuitStyle.Render("  vs  "))
+}
+
+func (m Model) status() string {
+	switch m.snap.Phase {
+	case WAITING:
+		return fmt.Sprintf("Waiting for an opponent...\n\nPress '%s' to play the computer", m.Keys.Bot.Help().Key)
+	case PLAYING:
+		if m.snap.Players[m.side].Mark == m.snap.Turn {
+			return "Your turn"
+		}
+		return fmt.Sprintf("%s to play...", m.snap.Players[1-m.side].Name)
+	}
+	return ""
+}
+
+func (m Model) resultView() string {
+	var result string
+	switch m.snap.Winner {
+	case -1:
+		result = "Draw"
+	case m.side:
+		result = "You win!"
+		// A match that ends before the board does was forfeited.
+		if !m.snap.Board.Over() {
+			result = m.snap.Players[1-m.side].Name + " left, you win!"
+		}
+	default:
+		result = m.snap.Players[m.snap.Winner].Name + " wins"
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s\n\nPress '%s' for a new match or '%s' to play the computer",
+		result, m.Keys.Rematch.Help().Key, m.Keys.Bot.Help().Key))
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+
+	if m.snap.Phase == WAITING {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			m.BoxStyle.Render(m.status()),
+		)
+	}
+
+	bottom := m.status()
+	if m.snap.Phase == OVER {
+		bottom = m.resultView()
+	}
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			m.boardView(),
+			"",
+			bottom,
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
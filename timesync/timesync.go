@@ -0,0 +1,128 @@
+// Package timesync keeps multiplayer rooms fair between players on
+// different connections.
+//
+// Rooms are server-authoritative and run on a logical clock: the room loop
+// advances it once per tick and only ever reads input through a Buffer, as
+// pong courts and snake duels do.
+// Every input is scheduled for the tick at which the player pressed it plus
+// the room's input delay, estimating the press as its arrival minus the
+// player's one-way latency (half their round trip). The room delay is the
+// largest one-way latency in the room, so a keypress takes the same time to
+// land for everyone: a nearby player's input waits in the buffer for as long
+// as a distant player's spends on the wire. Players slower than MAXDELAY
+// aren't waited for; their inputs land as soon as they arrive.
+//
+// Inputs due on the same tick are applied in arrival order, which only
+// depends on the order of Submit calls, so a room restored from a snapshot
+// replays them identically.
+package timesync
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	MAXDELAY = 150 * time.Millisecond // most the room waits for its slowest player
+	MARGIN   = 1                      // extra ticks of delay to absorb jitter
+)
+
+// Clock is a room's logical clock. It only moves when the room loop ticks.
+type Clock struct {
+	Period time.Duration
+	Tick   uint64
+}
+
+func (c *Clock) Advance() uint64 {
+	c.Tick++
+	return c.Tick
+}
+
+// Ticks converts a duration to whole ticks, rounding up.
+func (c Clock) Ticks(d time.Duration) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return uint64((d + c.Period - 1) / c.Period)
+}
+
+// Input is a player's action scheduled for a tick.
+type Input struct {
+	Player string
+	Tick   uint64
+	Seq    uint64 // arrival order, which breaks ties deterministically
+	Value  string
+}
+
+// Buffer holds inputs until they're due. Like the clock it belongs to the
+// room loop's goroutine, and its exported fields are all a snapshot needs.
+type Buffer struct {
+	Clock   Clock
+	Latency map[string]time.Duration // one-way, per player
+	Pending []Input
+	Seq     uint64
+}
+
+func NewBuffer(period time.Duration) *Buffer {
+	return &Buffer{Clock: Clock{Period: period}, Latency: make(map[string]time.Duration)}
+}
+
+// SetLatency records a player's round trip, e.g. from latency.Meter.RTT.
+func (b *Buffer) SetLatency(player string, rtt time.Duration) {
+	b.Latency[player] = rtt / 2
+}
+
+func (b *Buffer) Leave(player string) {
+	delete(b.Latency, player)
+}
+
+// Delay is the room's input delay: the largest one-way latency up to
+// MAXDELAY, in ticks, plus MARGIN.
+func (b *Buffer) Delay() uint64 {
+	var slowest time.Duration
+	for _, l := range b.Latency {
+		slowest = max(slowest, min(l, MAXDELAY))
+	}
+	return b.Clock.Ticks(slowest) + MARGIN
+}
+
+// Submit schedules an input that just arrived from player and returns the
+// tick it'll apply on.
+func (b *Buffer) Submit(player, value string) uint64 {
+	now := b.Clock.Tick
+	own := b.Clock.Ticks(b.Latency[player])
+	delay := b.Delay()
+
+	// Pressed at now-own, due at press+delay. Players past MAXDELAY have
+	// own > delay and get the earliest tick that isn't in the past.
+	due := now + 1
+	if delay > own {
+		due = max(due, now+delay-own)
+	}
+
+	b.Seq++
+	b.Pending = append(b.Pending, Input{Player: player, Tick: due, Seq: b.Seq, Value: value})
+	return due
+}
+
+// Due removes and returns the inputs scheduled up to the clock's current
+// tick, in the order they must be applied.
+func (b *Buffer) Due() []Input {
+	var due, later []Input
+	for _, in := range b.Pending {
+		if in.Tick <= b.Clock.Tick {
+			due = append(due, in)
+		} else {
+			later = append(later, in)
+		}
+	}
+	b.Pending = later
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].Tick != due[j].Tick {
+			return due[i].Tick < due[j].Tick
+		}
+		return due[i].Seq < due[j].Seq
+	})
+	return due
+}
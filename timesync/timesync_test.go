@@ -0,0 +1,127 @@
+package timesync
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const period = 20 * time.Millisecond
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		rtts map[string]time.Duration
+		want uint64
+	}{
+		{"nobody", nil, MARGIN},
+		{"local", map[string]time.Duration{"a": 0, "b": 0}, MARGIN},
+		{"slowest sets it", map[string]time.Duration{"a": 20 * time.Millisecond, "b": 100 * time.Millisecond}, 3 + MARGIN},
+		{"clamped to MAXDELAY", map[string]time.Duration{"a": 0, "b": 2 * time.Second}, 8 + MARGIN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuffer(period)
+			for p, rtt := range tt.rtts {
+				b.SetLatency(p, rtt)
+			}
+			if got := b.Delay(); got != tt.want {
+				t.Errorf("Delay() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitCompensates(t *testing.T) {
+	b := NewBuffer(period)
+	b.SetLatency("near", 0)
+	b.SetLatency("far", 120*time.Millisecond) // 60ms, 3 ticks, one way
+	b.Clock.Tick = 10
+
+	// Both pressed on tick 10: the near press arrives at once, the far one
+	// three ticks later, and they land together.
+	near := b.Submit("near", "x")
+	b.Clock.Tick = 13
+	far := b.Submit("far", "x")
+	if near != far || near != 10+b.Delay() {
+		t.Errorf("near lands on %d and far on %d, want both on %d", near, far, 10+b.Delay())
+	}
+}
+
+func TestSubmitClampsCompensation(t *testing.T) {
+	b := NewBuffer(period)
+	b.SetLatency("near", 0)
+	b.SetLatency("slow", 2*time.Second)
+	b.Clock.Tick = 100
+
+	// The room only waits MAXDELAY for the slow player, so the near
+	// player's input is held no longer than that.
+	if got, want := b.Submit("near", "x"), 100+b.Clock.Ticks(MAXDELAY)+MARGIN; got != want {
+		t.Errorf("near lands on %d, want %d", got, want)
+	}
+	// The slow player's own lag is past making up for: their input lands
+	// on the next tick, never in the past.
+	if got := b.Submit("slow", "x"); got != 101 {
+		t.Errorf("slow lands on %d, want 101", got)
+	}
+}
+
+func TestDueOrder(t *testing.T) {
+	b := NewBuffer(period)
+	b.SetLatency("near", 0)
+	b.SetLatency("far", 80*time.Millisecond) // 2 ticks one way, delay 3
+
+	b.Submit("near", "n1") // pressed on 0, due 3
+	b.Clock.Advance()
+	b.Submit("near", "n2") // pressed on 1, due 4
+	b.Clock.Advance()
+	b.Submit("far", "f1")  // pressed on 0, arrives late on 2, due 3
+	b.Submit("near", "n3") // pressed on 2, due 5
+
+	var got []string
+	for b.Clock.Tick < 5 {
+		b.Clock.Advance()
+		for _, in := range b.Due() {
+			if in.Tick > b.Clock.Tick {
+				t.Errorf("%s applied on %d, before its tick %d", in.Value, b.Clock.Tick, in.Tick)
+			}
+			got = append(got, in.Value)
+		}
+	}
+	want := []string{"n1", "f1", "n2", "n3"}
+	if len(got) != len(want) {
+		t.Fatalf("applied %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("applied %v, want %v", got, want)
+		}
+	}
+	if len(b.Pending) != 0 {
+		t.Errorf("%d inputs still pending", len(b.Pending))
+	}
+}
+
+func TestDueLateTick(t *testing.T) {
+	// A room that fell behind and catches up applies everything due, in
+	// tick order and by arrival within a tick.
+	b := NewBuffer(period)
+	b.Pending = []Input{
+		{Player: "a", Tick: 3, Seq: 4, Value: "a3"},
+		{Player: "b", Tick: 2, Seq: 2, Value: "b2"},
+		{Player: "a", Tick: 2, Seq: 3, Value: "a2"},
+		{Player: "b", Tick: 9, Seq: 1, Value: "b9"},
+	}
+	b.Clock.Tick = 5
+	due := b.Due()
+	var got []string
+	for _, in := range due {
+		got = append(got, in.Value)
+	}
+	if want := "b2 a2 a3"; strings.Join(got, " ") != want {
+		t.Errorf("Due() = %s, want %s", strings.Join(got, " "), want)
+	}
+	if len(b.Pending) != 1 || b.Pending[0].Value != "b9" {
+		t.Errorf("pending = %v, want only b9", b.Pending)
+	}
+}
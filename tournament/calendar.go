@@ -0,0 +1,215 @@
+package tournament
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/clock"
+	"github.com/debemdeboas/games.debem.dev/trophy"
+)
+
+const (
+	TICK     = time.Second // how often Run looks for news
+	HOOKWAIT = 10 * time.Second
+)
+
+// Announcement is news of a cup, posted to its tournament's webhook as
+// JSON.
+type Announcement struct {
+	Tournament string    `json:"tournament"`
+	Game       string    `json:"game"`
+	Event      string    `json:"event"` // signup, start, cancelled or results
+	Text       string    `json:"message"`
+	Start      time.Time `json:"start"`
+	At         time.Time `json:"at"`
+	Champion   string    `json:"champion,omitempty"`
+	Bracket    [][]Match `json:"bracket,omitempty"`
+}
+
+// hookBody is what webhooks get: the announcement, and its text under the
+// names chat webhooks read it from, "text" for Slack and "content" for
+// Discord.
+type hookBody struct {
+	Announcement
+	Summary string `json:"text"`
+	Content string `json:"content"`
+}
+
+// placeTitles are the trophy titles of the places in a cup, after its
+// name, by place.
+var placeTitles = []string{"", "Champion", "Finalist", "Semifinalist"}
+
+// Calendar keeps the upcoming cup of every tournament, and the last one for
+// its results.
+type Calendar struct {
+	// Trophies, nil for none, awards the cups' placings as their results
+	// are announced.
+	Trophies *trophy.Case
+
+	mu     sync.Mutex
+	specs  []*Spec
+	next   []*Cup // nil for tournaments whose schedule runs no more
+	last   []*Cup
+	client *http.Client
+}
+
+func NewCalendar(specs []Spec) *Calendar {
+	c := &Calendar{client: &http.Client{Timeout: HOOKWAIT}}
+	for _, s := range specs {
+		c.specs = append(c.specs, &s)
+	}
+	c.next = make([]*Cup, len(c.specs))
+	c.last = make([]*Cup, len(c.specs))
+	return c
+}
+
+// Current lists the cup of each tournament that is upcoming or on at now,
+// by the order they were configured in, along with the one before it, if
+// any, for each.
+func (c *Calendar) Current(now time.Time) (next, last []*Cup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, s := range c.specs {
+		if cup := c.next[i]; cup != nil && cup.over(now) {
+			c.last[i], c.next[i] = cup, nil
+		}
+		if c.next[i] == nil {
+			if start := s.Schedule.Next(now); !start.IsZero() {
+				c.next[i] = newCup(s, start)
+			}
+		}
+		if c.next[i] != nil {
+			next = append(next, c.next[i])
+			last = append(last, c.last[i])
+		}
+	}
+	return next, last
+}
+
+// Next is the name and start of the soonest cup to start after now, the
+// zero time when none will.
+func (c *Calendar) Next(now time.Time) (string, time.Time) {
+	next, _ := c.Current(now)
+	var name string
+	var soonest time.Time
+	for _, cup := range next {
+		s := cup.Poll(0, now)
+		start := s.Start
+		if !start.After(now) {
+			start = cup.spec.Schedule.Next(now)
+		}
+		if soonest.IsZero() || start.Before(soonest) {
+			name, soonest = s.Name, start
+		}
+	}
+	return name, soonest
+}
+
+// Headlines are what the lobby shows of the cups at now: the ones taking
+// sign-ups or being played, and the champions of the ones that just ended.
+// Times are in loc.
+func (c *Calendar) Headlines(now time.Time, loc *time.Location) []string {
+	if c == nil {
+		return nil
+	}
+	var lines []string
+	next, last := c.Current(now)
+	for i, cup := range next {
+		s := cup.Poll(0, now)
+		switch s.Phase {
+		case OPEN:
+			lines = append(lines, fmt.Sprintf("%s: sign-ups are open, it starts at %s, in %s",
+				s.Name, clock.At(s.Start, now, loc), clock.Until(s.Start, now)))
+		case PLAYING, INTERVAL:
+			lines = append(lines, fmt.Sprintf("%s: round %d of %d is on", s.Name, s.Round+1, s.Rounds))
+		}
+		if last[i] == nil {
+			continue
+		}
+		if l := last[i].Poll(0, now); l.Champion != "" && now.Sub(l.Until) < RECENT {
+			lines = append(lines, fmt.Sprintf("%s won %s", l.Champion, l.Name))
+		}
+	}
+	return lines
+}
+
+// Run announces the cups' news as it comes, until ctx is done.
+func (c *Calendar) Run(ctx context.Context) {
+	t := time.NewTicker(TICK)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			next, last := c.Current(now)
+			for _, cup := range append(next, last...) {
+				if cup == nil {
+					continue
+				}
+				for _, a := range cup.news(now) {
+					c.announce(cup.spec, a)
+					if a.Event == "results" {
+						c.award(cup, now)
+					}
+				}
+			}
+		}
+	}
+}
+
+// award gives the cup's placings their trophies.
+func (c *Calendar) award(cup *Cup, now time.Time) {
+	s := cup.spec
+	for _, p := range cup.placings(now) {
+		c.Trophies.Award(p.Fingerprint, trophy.Trophy{
+			ID:    fmt.Sprintf("cup/%s/%s/%d", s.Name, cup.start.UTC().Format(time.RFC3339), p.Place),
+			Name:  fmt.Sprintf("%s, %s", s.Name, cup.start.UTC().Format("Mon 2 Jan 2006")),
+			Place: p.Place,
+			Title: s.Name + " " + placeTitles[p.Place],
+			At:    now,
+		})
+	}
+}
+
+func (c *Calendar) announce(s *Spec, a Announcement) {
+	log.Info("Tournament", "name", a.Tournament, "event", a.Event, "message", a.Text)
+	if s.Webhook == "" {
+		return
+	}
+	go func() {
+		if err := c.post(s.Webhook, a); err != nil {
+			log.Error("Could not post tournament news", "error", err)
+		}
+	}()
+}
+
+func (c *Calendar) post(hook string, a Announcement) error {
+	data, err := json.Marshal(hookBody{Announcement: a, Summary: a.Text, Content: a.Text})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), HOOKWAIT)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
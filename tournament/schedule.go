@@ -0,0 +1,138 @@
+package tournament
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LOOKAHEAD is how far Next searches for a matching minute. Schedules that
+// match nothing sooner, like the 31st of February, never run.
+const LOOKAHEAD = 5 * 366 * 24 * time.Hour
+
+// field is the set of values a schedule field matches, by value.
+type field []bool
+
+// Schedule is a cron-like schedule in UTC, five fields apart by spaces:
+// minute, hour, day of the month, month and day of the week, 0 or 7 for
+// Sunday. Each field is "*", a value, a range like "1-5", a step like
+// "*/15" or "10-50/20", or a list of those like "1,15". As with cron, a
+// day matches when either day field does, unless one of them is "*".
+//
+//	0 20 * * 5     Fridays at 20:00
+//	30 18 1 * *    the first of every month at 18:30
+//	0 */6 * * *    every six hours
+type Schedule struct {
+	spec                   string
+	minute, hour, dom, mon field
+	dow                    field
+	anyDom, anyDow         bool
+}
+
+// Parse reads a cron-like schedule, see Schedule.
+func Parse(spec string) (Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("schedule %q: want 5 fields, minute hour day month weekday", spec)
+	}
+	s := Schedule{spec: spec, anyDom: parts[2] == "*", anyDow: parts[4] == "*"}
+	var err error
+	for _, f := range []struct {
+		dst      *field
+		part     string
+		min, max int
+	}{
+		{&s.minute, parts[0], 0, 59},
+		{&s.hour, parts[1], 0, 23},
+		{&s.dom, parts[2], 1, 31},
+		{&s.mon, parts[3], 1, 12},
+		{&s.dow, parts[4], 0, 7},
+	} {
+		if *f.dst, err = parseField(f.part, f.min, f.max); err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+	}
+	s.dow[0] = s.dow[0] || s.dow[7]
+	return s, nil
+}
+
+func parseField(part string, lo, hi int) (field, error) {
+	f := make(field, hi+1)
+	for _, item := range strings.Split(part, ",") {
+		rng, stepText, stepped := strings.Cut(item, "/")
+		step := 1
+		if stepped {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("bad step in %q", item)
+			}
+			step = n
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return nil, fmt.Errorf("bad value in %q", item)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(b); err != nil {
+					return nil, fmt.Errorf("bad value in %q", item)
+				}
+			} else if stepped {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return nil, fmt.Errorf("%q is out of %d-%d", item, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			f[v] = true
+		}
+	}
+	return f, nil
+}
+
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// day reports whether the schedule runs on t's day at all.
+func (s Schedule) day(t time.Time) bool {
+	if !s.mon[t.Month()] {
+		return false
+	}
+	dom, dow := s.dom[t.Day()], s.dow[t.Weekday()]
+	switch {
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	}
+	return dom || dow
+}
+
+// Next is the first minute after now the schedule matches, zero when it
+// matches none within LOOKAHEAD.
+func (s Schedule) Next(now time.Time) time.Time {
+	if s.minute == nil {
+		return time.Time{}
+	}
+	now = now.UTC()
+	t := now.Truncate(time.Minute).Add(time.Minute)
+	for end := now.Add(LOOKAHEAD); t.Before(end); {
+		switch {
+		case !s.day(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hour[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !s.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,41 @@
+package tournament
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"", "0 20 * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "x * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) took it", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 20 * * 5", time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)},
+		{"30 18 1 * *", time.Date(2026, 11, 1, 18, 30, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)},
+		{"10-50/20 12 * * *", time.Date(2026, 10, 14, 12, 50, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when neither is "*".
+		{"0 0 20 * 4", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := s.Next(now); !got.Equal(tt.want) {
+			t.Errorf("%q: Next() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
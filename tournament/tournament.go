@@ -0,0 +1,423 @@
+// Package tournament runs the recurring tournaments operators schedule in
+// the config, such as a Friday evening Snake cup. Each one opens sign-ups
+// ahead of its start, then plays a single-elimination bracket: paired
+// entrants play the same game over a round, and whoever finishes the best
+// run goes through. Tournaments announce themselves to the lobby and to
+// their webhook as sign-ups open, as play starts and with the results.
+//
+// Like decathlon events, cups have no goroutine: their phase follows from
+// the clock. Calendar.Run only ticks them for the announcements.
+package tournament
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/daily"
+)
+
+const (
+	BREAK      = time.Minute     // between bracket rounds, while their results show
+	SIGNUPTIME = time.Hour       // sign-ups open this long before cups start, unless configured
+	ROUNDTIME  = 5 * time.Minute // of a bracket round, unless configured
+	RECENT     = time.Hour       // results stay in the lobby's headlines for this long
+)
+
+// Cup phases
+const (
+	UPCOMING = iota // sign-ups aren't open yet
+	OPEN            // taking sign-ups
+	PLAYING         // a bracket round is on
+	INTERVAL        // the break after a round
+	OVER
+)
+
+// Spec is a recurring tournament.
+type Spec struct {
+	Name string
+	// Game is played in every round, as registered. Its runs are read off
+	// the leaderboard, so it must submit them.
+	Game     string
+	Schedule Schedule
+	Signup   time.Duration // before the start, zero for SIGNUPTIME
+	Round    time.Duration // zero for ROUNDTIME
+	// DailySeed has every run play the seed of the day the cup starts, so
+	// all entrants face the same game.
+	DailySeed bool
+	// Webhook, if set, is posted the cup's announcements.
+	Webhook string
+}
+
+type entrant struct {
+	id          int
+	name        string
+	fingerprint string
+}
+
+// pairing is a bracket match: entrants a and b by index, b -1 for a bye,
+// and the best run each finished in the round.
+type pairing struct {
+	a, b           int
+	scoreA, scoreB int
+	winner         int // entrant index, -1 until the round ends
+}
+
+// Cup is one occurrence of a tournament.
+type Cup struct {
+	mu       sync.Mutex
+	spec     *Spec
+	start    time.Time // of the first round
+	entrants []*entrant
+	planned  int          // rounds, set once the cup starts
+	rounds   [][]*pairing // played or playing, the first first
+	nextID   int
+	told     int // the last phase announced
+}
+
+func newCup(spec *Spec, start time.Time) *Cup {
+	return &Cup{spec: spec, start: start}
+}
+
+func (c *Cup) signup() time.Duration {
+	if c.spec.Signup > 0 {
+		return c.spec.Signup
+	}
+	return SIGNUPTIME
+}
+
+func (c *Cup) round() time.Duration {
+	if c.spec.Round > 0 {
+		return c.spec.Round
+	}
+	return ROUNDTIME
+}
+
+// Seed is what every run of the cup plays, 0 for the game's own seeds.
+func (c *Cup) Seed() uint64 {
+	if !c.spec.DailySeed {
+		return 0
+	}
+	return daily.Seed(c.spec.Game, daily.Today(c.start))
+}
+
+func (c *Cup) Spec() Spec {
+	return *c.spec
+}
+
+// at locates now in the cup: its phase, the round it's on or just played,
+// and when that part ends. Rounds are only known once the cup settled past
+// its start.
+func (c *Cup) at(now time.Time) (phase, round int, until time.Time) {
+	switch {
+	case now.Before(c.start.Add(-c.signup())):
+		return UPCOMING, 0, c.start.Add(-c.signup())
+	case now.Before(c.start):
+		return OPEN, 0, c.start
+	}
+	t := c.start
+	for i := 0; i < c.planned; i++ {
+		if t = t.Add(c.round()); now.Before(t) {
+			return PLAYING, i, t
+		}
+		// The final's results show in the lobby, not in a break.
+		if i == c.planned-1 {
+			break
+		}
+		if t = t.Add(BREAK); now.Before(t) {
+			return INTERVAL, i, t
+		}
+	}
+	return OVER, c.planned, t
+}
+
+// over reports whether the cup is done at now.
+func (c *Cup) over(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settle(now)
+	phase, _, _ := c.at(now)
+	return phase == OVER
+}
+
+// settle brings the bracket up to now: it seeds it once the cup starts,
+// decides each round once it ends and pairs the winners for the next.
+// Byes all fall in the first round, to the earliest sign-ups, so later
+// rounds always pair up.
+func (c *Cup) settle(now time.Time) {
+	if now.Before(c.start) {
+		return
+	}
+	if c.rounds == nil {
+		n := len(c.entrants)
+		if n >= 2 {
+			c.planned = bits.Len(uint(n - 1))
+		}
+		size := 1 << c.planned
+		var first []*pairing
+		for i := 0; i < size/2 && c.planned > 0; i++ {
+			p := &pairing{a: i, b: size - 1 - i, winner: -1}
+			if p.b >= n {
+				p.b = -1
+			}
+			first = append(first, p)
+		}
+		c.rounds = [][]*pairing{first}
+	}
+	for r := 0; r < c.planned; r++ {
+		if r == len(c.rounds) {
+			prev := c.rounds[r-1]
+			var next []*pairing
+			for i := 0; i+1 < len(prev); i += 2 {
+				next = append(next, &pairing{a: prev[i].winner, b: prev[i+1].winner, winner: -1})
+			}
+			c.rounds = append(c.rounds, next)
+		}
+		end := c.start.Add(time.Duration(r+1)*c.round() + time.Duration(r)*BREAK)
+		if now.Before(end) {
+			return
+		}
+		for _, p := range c.rounds[r] {
+			if p.winner >= 0 {
+				continue
+			}
+			// Ties go to the earlier sign-up.
+			p.winner = p.a
+			if p.b >= 0 && (p.scoreB > p.scoreA || p.scoreB == p.scoreA && p.b < p.a) {
+				p.winner = p.b
+			}
+		}
+	}
+}
+
+// Enter signs name up while sign-ups are open, returning their entry, 0 if
+// they're closed or the player is anonymous. Entries go by key, so players
+// keep theirs when they reconnect, see Entry, and can't enter twice.
+func (c *Cup) Enter(name, fingerprint string, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if phase, _, _ := c.at(now); phase != OPEN || fingerprint == "" {
+		return 0
+	}
+	if i := c.find(fingerprint); i >= 0 {
+		return c.entrants[i].id
+	}
+	c.nextID++
+	c.entrants = append(c.entrants, &entrant{id: c.nextID, name: name, fingerprint: fingerprint})
+	return c.nextID
+}
+
+// Entry finds the entry of the player with fingerprint, 0 if they didn't
+// sign up.
+func (c *Cup) Entry(fingerprint string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if i := c.find(fingerprint); i >= 0 {
+		return c.entrants[i].id
+	}
+	return 0
+}
+
+func (c *Cup) find(fingerprint string) int {
+	if fingerprint == "" {
+		return -1
+	}
+	return slices.IndexFunc(c.entrants, func(en *entrant) bool { return en.fingerprint == fingerprint })
+}
+
+func (c *Cup) index(id int) int {
+	return slices.IndexFunc(c.entrants, func(en *entrant) bool { return en.id == id })
+}
+
+// Record keeps score as entry id's run in game, if it was played during a
+// round id still plays in.
+func (c *Cup) Record(id int, game string, score int, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.settle(now)
+	phase, round, _ := c.at(now)
+	i := c.index(id)
+	if phase != PLAYING || i < 0 || game != c.spec.Game {
+		return
+	}
+	for _, p := range c.rounds[round] {
+		switch i {
+		case p.a:
+			p.scoreA = max(p.scoreA, score)
+		case p.b:
+			p.scoreB = max(p.scoreB, score)
+		}
+	}
+}
+
+// Match is a bracket match as players see it.
+type Match struct {
+	A      string `json:"a"`
+	B      string `json:"b,omitempty"`
+	ScoreA int    `json:"score_a"`
+	ScoreB int    `json:"score_b"`
+	Bye    bool   `json:"bye,omitempty"` // A goes through unopposed
+	Winner int    `json:"winner"`        // 0 for A, 1 for B, -1 until the round ends
+	You    bool   `json:"-"`             // the player is A or B
+}
+
+type Snapshot struct {
+	Name     string
+	Game     string
+	Phase    int
+	Round    int
+	Rounds   int // in the bracket, once the cup started
+	Until    time.Time
+	Start    time.Time
+	Entrants []string // by sign-up
+	Entered  bool
+	// Playing is set while the player has a match on in the round.
+	Playing bool
+	Bracket [][]Match
+	// Champion won the final, empty for cups too few signed up for.
+	Champion string
+}
+
+// Poll describes the cup to entry id, who is 0 if they didn't enter.
+func (c *Cup) Poll(id int, now time.Time) Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot(id, now)
+}
+
+func (c *Cup) snapshot(id int, now time.Time) Snapshot {
+	c.settle(now)
+	phase, round, until := c.at(now)
+	s := Snapshot{
+		Name:   c.spec.Name,
+		Game:   c.spec.Game,
+		Phase:  phase,
+		Round:  round,
+		Rounds: c.planned,
+		Until:  until,
+		Start:  c.start,
+	}
+	me := c.index(id)
+	s.Entered = me >= 0
+	for _, en := range c.entrants {
+		s.Entrants = append(s.Entrants, en.name)
+	}
+	for r, ps := range c.rounds {
+		var ms []Match
+		for _, p := range ps {
+			m := Match{A: c.entrants[p.a].name, ScoreA: p.scoreA, ScoreB: p.scoreB, Bye: p.b < 0, Winner: -1}
+			if p.b >= 0 {
+				m.B = c.entrants[p.b].name
+			}
+			switch {
+			case p.winner < 0:
+			case p.winner == p.a:
+				m.Winner = 0
+			default:
+				m.Winner = 1
+			}
+			m.You = me >= 0 && (p.a == me || p.b == me)
+			if m.You && !m.Bye && phase == PLAYING && r == round {
+				s.Playing = true
+			}
+			ms = append(ms, m)
+		}
+		s.Bracket = append(s.Bracket, ms)
+	}
+	if phase == OVER && c.planned > 0 {
+		final := c.rounds[c.planned-1][0]
+		s.Champion = c.entrants[final.winner].name
+	}
+	return s
+}
+
+// news is what the cup has to announce at now since the last call: the
+// sign-ups opening, the start and the results. When phases went by unseen,
+// only the latest is announced.
+func (c *Cup) news(now time.Time) []Announcement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.settle(now)
+	phase, _, _ := c.at(now)
+	if phase == INTERVAL {
+		phase = PLAYING
+	}
+	if phase <= c.told {
+		return nil
+	}
+	c.told = phase
+	s := c.snapshot(0, now)
+	a := Announcement{Tournament: s.Name, Game: s.Game, Start: s.Start, At: now}
+	switch {
+	case phase == OPEN:
+		a.Event = "signup"
+		a.Text = fmt.Sprintf("%s: sign-ups are open, it starts %s UTC.", s.Name, s.Start.Format("Mon 15:04"))
+	case phase == PLAYING:
+		a.Event = "start"
+		a.Text = fmt.Sprintf("%s is on: %d players, %d rounds of %s.", s.Name, len(s.Entrants), s.Rounds, c.round())
+	case s.Champion == "":
+		a.Event = "cancelled"
+		a.Text = fmt.Sprintf("%s is off: %d signed up, it takes 2.", s.Name, len(s.Entrants))
+	default:
+		final := s.Bracket[len(s.Bracket)-1][0]
+		a.Event = "results"
+		a.Champion = s.Champion
+		a.Bracket = s.Bracket
+		a.Text = fmt.Sprintf("%s won %s, %d to %d in the final against %s.",
+			s.Champion, s.Name, max(final.ScoreA, final.ScoreB), min(final.ScoreA, final.ScoreB), final.loser())
+		if final.ScoreA == final.ScoreB {
+			a.Text = fmt.Sprintf("%s won %s, tied at %d with %s in the final and through on the earlier sign-up.",
+				s.Champion, s.Name, final.ScoreA, final.loser())
+		}
+	}
+	return []Announcement{a}
+}
+
+// Placing is an entrant's finish in a cup that's over.
+type Placing struct {
+	Name        string
+	Fingerprint string
+	Place       int // 1 for the champion, 2 for the other finalist, 3 for the semifinalists
+}
+
+// placings lists who placed in the cup, best first. They're only known
+// once it's over, and not at all for cups too few signed up for.
+func (c *Cup) placings(now time.Time) []Placing {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.settle(now)
+	if phase, _, _ := c.at(now); phase != OVER || c.planned == 0 {
+		return nil
+	}
+	var ps []Placing
+	add := func(i, place int) {
+		if i >= 0 {
+			en := c.entrants[i]
+			ps = append(ps, Placing{Name: en.name, Fingerprint: en.fingerprint, Place: place})
+		}
+	}
+	final := c.rounds[c.planned-1][0]
+	add(final.winner, 1)
+	add(final.a+final.b-final.winner, 2)
+	if c.planned >= 2 {
+		for _, p := range c.rounds[c.planned-2] {
+			if p.b >= 0 {
+				add(p.a+p.b-p.winner, 3)
+			}
+		}
+	}
+	return ps
+}
+
+func (m Match) loser() string {
+	if m.Winner == 1 {
+		return m.A
+	}
+	return m.B
+}
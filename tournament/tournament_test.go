@@ -0,0 +1,126 @@
+package tournament
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+
+func testCup() *Cup {
+	return newCup(&Spec{Name: "Friday cup", Game: "snake"}, start)
+}
+
+func TestEnter(t *testing.T) {
+	c := testCup()
+	open := start.Add(-time.Minute)
+
+	if id := c.Enter("ann", "ann-key", start.Add(-2*SIGNUPTIME)); id != 0 {
+		t.Errorf("entered as %d before sign-ups opened", id)
+	}
+	id := c.Enter("ann", "ann-key", open)
+	if id == 0 {
+		t.Fatal("couldn't enter while sign-ups are open")
+	}
+	if again := c.Enter("ann", "ann-key", open); again != id {
+		t.Errorf("entering again = %d, want the same entry %d", again, id)
+	}
+	if got := c.Entry("ann-key"); got != id {
+		t.Errorf("Entry() = %d, want %d", got, id)
+	}
+	for range 3 {
+		if anon := c.Enter("guest", "", open); anon != 0 {
+			t.Errorf("anonymous player entered as %d", anon)
+		}
+	}
+	if got := c.Entry(""); got != 0 {
+		t.Errorf("anonymous Entry() = %d, want 0", got)
+	}
+	if late := c.Enter("bob", "bob-key", start); late != 0 {
+		t.Errorf("entered as %d after the start", late)
+	}
+	if s := c.Poll(id, open); len(s.Entrants) != 1 || !s.Entered {
+		t.Errorf("entrants %v, entered %t, want ann alone and entered", s.Entrants, s.Entered)
+	}
+}
+
+func TestBracket(t *testing.T) {
+	c := testCup()
+	open := start.Add(-time.Minute)
+	ann := c.Enter("ann", "ann-key", open)
+	bob := c.Enter("bob", "bob-key", open)
+	cat := c.Enter("cat", "cat-key", open)
+
+	// Three entrants make two rounds, ann taking the bye.
+	first := start.Add(time.Minute)
+	s := c.Poll(bob, first)
+	if s.Phase != PLAYING || s.Rounds != 2 || !s.Playing {
+		t.Fatalf("phase %d, %d rounds, playing %t, want bob playing the first of 2", s.Phase, s.Rounds, s.Playing)
+	}
+	if s := c.Poll(ann, first); s.Playing || !s.Bracket[0][0].Bye || !s.Bracket[0][0].You {
+		t.Errorf("ann's match %+v, want ann's bye", s.Bracket[0][0])
+	}
+	c.Record(bob, "snake", 5, first)
+	c.Record(cat, "snake", 10, first)
+	c.Record(cat, "snake", 3, first)                    // only the best run counts
+	c.Record(bob, "tetris", 99, first)                  // another game
+	c.Record(bob, "snake", 99, start.Add(-time.Minute)) // before the round
+
+	// In the break, cat is through.
+	brk := start.Add(ROUNDTIME + BREAK/2)
+	s = c.Poll(cat, brk)
+	if s.Phase != INTERVAL {
+		t.Fatalf("phase %d, want the break", s.Phase)
+	}
+	if m := s.Bracket[0][1]; m.A != "bob" || m.B != "cat" || m.ScoreA != 5 || m.ScoreB != 10 || m.Winner != 1 {
+		t.Errorf("first round match %+v, want cat through 10 to 5", m)
+	}
+
+	// The final ties, which goes to the earlier sign-up.
+	final := start.Add(ROUNDTIME + BREAK + time.Minute)
+	c.Record(ann, "snake", 7, final)
+	c.Record(cat, "snake", 7, final)
+	end := start.Add(2*ROUNDTIME + BREAK)
+	s = c.Poll(0, end)
+	if s.Phase != OVER || s.Champion != "ann" {
+		t.Errorf("phase %d, champion %q, want ann champion once over", s.Phase, s.Champion)
+	}
+
+	want := []Placing{{"ann", "ann-key", 1}, {"cat", "cat-key", 2}, {"bob", "bob-key", 3}}
+	got := c.placings(end)
+	if len(got) != len(want) {
+		t.Fatalf("placings %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("placing %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNews(t *testing.T) {
+	c := testCup()
+	var events []string
+	for _, now := range []time.Time{
+		start.Add(-2 * SIGNUPTIME),
+		start.Add(-time.Minute),
+		start.Add(-time.Second), // nothing new
+	} {
+		for _, a := range c.news(now) {
+			events = append(events, a.Event)
+		}
+		if now.Equal(start.Add(-time.Minute)) {
+			c.Enter("ann", "ann-key", now)
+		}
+	}
+	// With one entrant, the cup is off as it starts.
+	for _, a := range c.news(start) {
+		events = append(events, a.Event)
+	}
+	if len(events) != 2 || events[0] != "signup" || events[1] != "cancelled" {
+		t.Errorf("announced %v, want signup and cancelled", events)
+	}
+	if ps := c.placings(start); ps != nil {
+		t.Errorf("cancelled cup placed %v", ps)
+	}
+}
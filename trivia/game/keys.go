@@ -0,0 +1,69 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Choices [MAXCHOICES]key.Binding
+	Select  key.Binding
+	Layout  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Choices: [MAXCHOICES]key.Binding{
+			key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "first answer")),
+			key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "second answer")),
+			key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "third answer")),
+			key.NewBinding(key.WithKeys("4"), key.WithHelp("4", "fourth answer")),
+		},
+		Select: key.NewBinding(key.WithKeys("enter", " ", ui.KEYPADENTER), key.WithHelp("enter", "answer")),
+		Layout: ui.LayoutKey(),
+		Help:   ui.HelpKey(),
+		Quit:   ui.QuitKeyFor(l),
+		layout: l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Select, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		append([]key.Binding{k.Select}, k.Choices[:]...),
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":       &k.Up,
+		"down":     &k.Down,
+		"left":     &k.Left,
+		"right":    &k.Right,
+		"choice-1": &k.Choices[0],
+		"choice-2": &k.Choices[1],
+		"choice-3": &k.Choices[2],
+		"choice-4": &k.Choices[3],
+		"select":   &k.Select,
+		"layout":   &k.Layout,
+		"help":     &k.Help,
+		"quit":     &k.Quit,
+	}
+}
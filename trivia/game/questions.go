@@ -0,0 +1,105 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	MINCHOICES = 2
+	MAXCHOICES = 4
+)
+
+//go:embed questions.json
+var packSet []byte
+
+// Pack is a themed set of questions. The embedded packs hold a list of them;
+// operator files hold one pack each.
+type Pack struct {
+	Name      string     `json:"name"`
+	Questions []Question `json:"questions"`
+}
+
+type Question struct {
+	Question string   `json:"question"`
+	Choices  []string `json:"choices"`
+	Answer   int      `json:"answer"` // index into Choices
+
+	pack string
+}
+
+var questions = mustLoad(packSet)
+
+// mustLoad flattens the embedded packs into one list.
+func mustLoad(data []byte) []Question {
+	var packs []Pack
+	if err := json.Unmarshal(data, &packs); err != nil {
+		panic(fmt.Sprintf("trivia: question packs: %v", err))
+	}
+	var qs []Question
+	for _, p := range packs {
+		pqs, err := p.flatten()
+		if err != nil {
+			panic(fmt.Sprintf("trivia: pack %s: %v", p.Name, err))
+		}
+		qs = append(qs, pqs...)
+	}
+	return qs
+}
+
+func (p Pack) flatten() ([]Question, error) {
+	if len(p.Questions) == 0 {
+		return nil, fmt.Errorf("no questions")
+	}
+	qs := make([]Question, len(p.Questions))
+	for i, q := range p.Questions {
+		if n := len(q.Choices); n < MINCHOICES || n > MAXCHOICES {
+			return nil, fmt.Errorf("question %d: %d choices, want %d to %d", i+1, n, MINCHOICES, MAXCHOICES)
+		}
+		if q.Answer < 0 || q.Answer >= len(q.Choices) {
+			return nil, fmt.Errorf("question %d: answer %d out of range", i+1, q.Answer)
+		}
+		q.pack = p.Name
+		qs[i] = q
+	}
+	return qs, nil
+}
+
+// LoadDir adds every pack in dir, one JSON file each, to the questions
+// rooms draw from. Invalid packs are skipped. Call it before serving.
+func LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		qs, err := loadPack(path)
+		if err != nil {
+			log.Warn("Skipping trivia pack", "path", path, "err", err)
+			continue
+		}
+		questions = append(questions, qs...)
+		log.Info("Loaded trivia pack", "path", path, "questions", len(qs))
+	}
+	return nil
+}
+
+func loadPack(path string) ([]Question, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Pack
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		p.Name = filepath.Base(path)
+	}
+	return p.flatten()
+}
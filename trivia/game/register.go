@@ -0,0 +1,40 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+)
+
+const GAMENAME = "trivia"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Trivia",
+	Description: "Race up to nine others to the right answer",
+	Category:    games.MULTIPLAYER,
+	MinPlayers:  1,
+	MaxPlayers:  MAXPLAYERS,
+	Spectating:  true,
+	Rooms:       true,
+	Session:     5 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+		m.SetContext(env.Ctx)
+		if env.Room != "" {
+			m.SetRoom(env.Room)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
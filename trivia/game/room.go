@@ -0,0 +1,266 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+const (
+	MAXPLAYERS   = 10
+	ROUNDS       = 8 // questions per room
+	LOBBYTIME    = 20 * time.Second
+	PRIVATEWAIT  = 5 * time.Second // lobby time of rooms opened from a lobby room
+	QUESTIONTIME = 15 * time.Second
+	SCORETIME    = 6 * time.Second // scoreboard between questions
+	STALE        = 5 * time.Second // players not heard from for this long left
+	MAXPOINTS    = 1000            // for an instant right answer; half at the buzzer
+)
+
+// Room phases
+const (
+	WAITING = iota
+	ASKING
+	SCORING
+	FINISHED
+)
+
+type player struct {
+	id     int
+	name   string
+	seat   block.Player
+	score  int
+	answer int // choice for the current question, or -1
+	gained int // points the answer is worth, awarded when it closes
+	seen   time.Time
+}
+
+// Room is a trivia match shared by up to MAXPLAYERS sessions. It has no
+// goroutine of its own: every session polls it, and whoever polls first
+// after a deadline moves it to the next phase.
+type Room struct {
+	mu        sync.Mutex
+	questions []Question
+	round     int
+	phase     int
+	deadline  time.Time // end of the current phase
+	players   []*player
+	nextID    int
+	private   string // lobby room key, empty for rooms open to anyone
+}
+
+var (
+	roomsMu sync.Mutex
+	rooms   []*Room
+)
+
+// join seats p in a room still waiting for players, opening one if they're
+// all full, playing or have someone p blocked or was blocked by. Players
+// from a lobby room, with its key in private, only meet each other.
+func join(p block.Player, private string, now time.Time) (*Room, int) {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+
+	open := rooms[:0]
+	var room *Room
+	for _, r := range rooms {
+		r.mu.Lock()
+		r.advance(now)
+		alive := r.phase != FINISHED && len(r.players) > 0
+		joinable := r.phase == WAITING && len(r.players) < MAXPLAYERS && r.private == private && !r.apart(p)
+		r.mu.Unlock()
+		if alive {
+			open = append(open, r)
+		}
+		if joinable && room == nil {
+			room = r
+		}
+	}
+	rooms = open
+	if room == nil {
+		room = newRoom(private, now)
+		rooms = append(rooms, room)
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	room.nextID++
+	room.players = append(room.players, &player{id: room.nextID, name: p.Label(), seat: p, answer: -1, seen: now})
+	if len(room.players) == MAXPLAYERS {
+		room.deadline = now
+	}
+	return room, room.nextID
+}
+
+// newRoom opens a room. Private rooms wait less for players, since theirs
+// all arrive at once from the lobby.
+func newRoom(private string, now time.Time) *Room {
+	qs := slices.Clone(questions)
+	rand.Shuffle(len(qs), func(i, j int) { qs[i], qs[j] = qs[j], qs[i] })
+	wait := LOBBYTIME
+	if private != "" {
+		wait = PRIVATEWAIT
+	}
+	return &Room{
+		questions: qs[:min(ROUNDS, len(qs))],
+		phase:     WAITING,
+		deadline:  now.Add(wait),
+		private:   private,
+	}
+}
+
+// apart reports whether anyone in the room blocked p or was blocked by them.
+func (r *Room) apart(p block.Player) bool {
+	return slices.ContainsFunc(r.players, func(pl *player) bool { return block.Apart(pl.seat, p) })
+}
+
+func (r *Room) find(id int) *player {
+	for _, p := range r.players {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// advance drops players that went quiet and moves through every phase whose
+// deadline passed. A question also closes once everyone answered.
+func (r *Room) advance(now time.Time) {
+	r.players = slices.DeleteFunc(r.players, func(p *player) bool {
+		return now.Sub(p.seen) > STALE
+	})
+	for {
+		switch r.phase {
+		case WAITING:
+			if now.Before(r.deadline) {
+				return
+			}
+			r.ask(0, now)
+		case ASKING:
+			if now.Before(r.deadline) && !r.allAnswered() {
+				return
+			}
+			r.phase = SCORING
+			r.deadline = now.Add(SCORETIME)
+			for _, p := range r.players {
+				p.score += p.gained
+			}
+		case SCORING:
+			if now.Before(r.deadline) {
+				return
+			}
+			if r.round+1 >= len(r.questions) {
+				r.phase = FINISHED
+				return
+			}
+			r.ask(r.round+1, now)
+		default:
+			return
+		}
+	}
+}
+
+func (r *Room) ask(round int, now time.Time) {
+	r.round = round
+	r.phase = ASKING
+	r.deadline = now.Add(QUESTIONTIME)
+	for _, p := range r.players {
+		p.answer = -1
+		p.gained = 0
+	}
+}
+
+func (r *Room) allAnswered() bool {
+	for _, p := range r.players {
+		if p.answer < 0 {
+			return false
+		}
+	}
+	return len(r.players) > 0
+}
+
+// answer locks in player id's choice for the current question. Right
+// answers are worth more the faster they come.
+func (r *Room) answer(id, choice int, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.find(id)
+	if p == nil || r.phase != ASKING || p.answer >= 0 {
+		return
+	}
+	p.answer = choice
+	if choice == r.questions[r.round].Answer {
+		left := max(0, r.deadline.Sub(now))
+		p.gained = MAXPOINTS/2 + int(int64(MAXPOINTS/2)*int64(left)/int64(QUESTIONTIME))
+	}
+}
+
+// opponents are the other players in the room, besides the one with id.
+func (r *Room) opponents(id int) []block.Player {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ps []block.Player
+	for _, p := range r.players {
+		if p.id != id {
+			ps = append(ps, p.seat)
+		}
+	}
+	return ps
+}
+
+func (r *Room) leave(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.players = slices.DeleteFunc(r.players, func(p *player) bool { return p.id == id })
+}
+
+// Standing is a player's line on the scoreboard.
+type Standing struct {
+	Name   string
+	Score  int
+	Answer int
+	Gained int
+	You    bool
+}
+
+// Snapshot is what a session renders of its room.
+type Snapshot struct {
+	Phase     int
+	Round     int
+	Rounds    int
+	Question  Question
+	Deadline  time.Time
+	Answer    int        // the session's own choice, or -1
+	Standings []Standing // best first
+}
+
+// poll marks player id as present, advances the room and describes it.
+func (r *Room) poll(id int, now time.Time) (Snapshot, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.find(id)
+	if p == nil {
+		return Snapshot{}, false
+	}
+	p.seen = now
+	r.advance(now)
+
+	s := Snapshot{
+		Phase:    r.phase,
+		Round:    r.round,
+		Rounds:   len(r.questions),
+		Question: r.questions[r.round],
+		Deadline: r.deadline,
+		Answer:   p.answer,
+	}
+	for _, o := range r.players {
+		s.Standings = append(s.Standings, Standing{Name: o.name, Score: o.score, Answer: o.answer, Gained: o.gained, You: o == p})
+	}
+	slices.SortStableFunc(s.Standings, func(a, b Standing) int { return b.Score - a.Score })
+	return s, true
+}
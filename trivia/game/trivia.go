@@ -0,0 +1,285 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const POLL = 250 * time.Millisecond // how often sessions check their room
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	ChoiceStyle lipgloss.Style
+	CursorStyle lipgloss.Style
+	RightStyle  lipgloss.Style
+	WrongStyle  lipgloss.Style
+	YouStyle    lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	Player block.Player
+	// Private is the lobby room the player came from, if any.
+	Private string
+	room    *Room
+	id      int
+	snap    Snapshot
+	cursor  int
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		ChoiceStyle: r.NewStyle().Padding(0, 1),
+		CursorStyle: r.NewStyle().Padding(0, 1).Reverse(true),
+		RightStyle:  r.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("10")),
+		WrongStyle:  r.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("9")),
+		YouStyle:    r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Align(lipgloss.Left).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Join()
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// SetRoom moves the player to the room of their lobby room.
+func (m *Model) SetRoom(private string) {
+	m.room.leave(m.id)
+	m.Private = private
+	m.Join()
+}
+
+// Join takes a seat in the next room to start.
+func (m *Model) Join() {
+	now := time.Now()
+	m.room, m.id = join(m.Player, m.Private, now)
+	m.cursor = 0
+	m.refresh(now)
+}
+
+// Opponents are the other players in the current room, for the lobby to
+// report.
+func (m *Model) Opponents() []block.Player {
+	return m.room.opponents(m.id)
+}
+
+// refresh reads the room, joining another if this one dropped the player.
+func (m *Model) refresh(now time.Time) {
+	snap, ok := m.room.poll(m.id, now)
+	if !ok {
+		m.Join()
+		return
+	}
+	if snap.Phase == ASKING && (snap.Round != m.snap.Round || m.snap.Phase != ASKING) {
+		m.cursor = 0
+	}
+	m.snap = snap
+}
+
+func (m *Model) answer(choice int) {
+	if m.snap.Phase != ASKING || choice >= len(m.snap.Question.Choices) {
+		return
+	}
+	now := time.Now()
+	m.room.answer(m.id, choice, now)
+	m.cursor = choice
+	m.refresh(now)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.room.leave(m.id)
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case m.snap.Phase == FINISHED:
+			if key.Matches(msg, m.Keys.Select) {
+				m.Join()
+			}
+		case key.Matches(msg, m.Keys.Up):
+			m.cursor = max(0, m.cursor-1)
+		case key.Matches(msg, m.Keys.Down):
+			m.cursor = min(len(m.snap.Question.Choices)-1, m.cursor+1)
+		case key.Matches(msg, m.Keys.Select):
+			m.answer(m.cursor)
+		default:
+			for i, b := range m.Keys.Choices {
+				if key.Matches(msg, b) {
+					m.answer(i)
+				}
+			}
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func countdown(d time.Duration) string {
+	d = max(0, d).Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func (m Model) header() string {
+	s := m.snap
+	left := countdown(time.Until(s.Deadline))
+	switch s.Phase {
+	case WAITING:
+		return fmt.Sprintf("Trivia | Waiting for players (%d/%d) | Starts in %s", len(s.Standings), MAXPLAYERS, left)
+	case ASKING:
+		return fmt.Sprintf("Trivia | Question %d/%d | %s", s.Round+1, s.Rounds, left)
+	case SCORING:
+		return fmt.Sprintf("Trivia | Question %d/%d | Next in %s", s.Round+1, s.Rounds, left)
+	}
+	return "Trivia | Final scores"
+}
+
+func (m Model) questionView() string {
+	s := m.snap
+	q := s.Question
+	lines := []string{m.QuitStyle.Render(q.pack), q.Question, ""}
+	for i, c := range q.Choices {
+		style := m.ChoiceStyle
+		switch {
+		case s.Phase == SCORING && i == q.Answer:
+			style = m.RightStyle
+		case s.Phase == SCORING && i == s.Answer:
+			style = m.WrongStyle
+		case s.Phase == ASKING && s.Answer < 0 && i == m.cursor:
+			style = m.CursorStyle
+		}
+		mark := "  "
+		if i == s.Answer {
+			mark = "▶ "
+		}
+		lines = append(lines, mark+style.Render(fmt.Sprintf("%d. %s", i+1, c)))
+	}
+
+	if s.Phase == ASKING {
+		answered := 0
+		for _, p := range s.Standings {
+			if p.Answer >= 0 {
+				answered++
+			}
+		}
+		lines = append(lines, "", m.QuitStyle.Render(fmt.Sprintf("%d/%d answered", answered, len(s.Standings))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scoreboard ranks the room, showing what each answer earned while the
+// question's results are up.
+func (m Model) scoreboard() string {
+	var s strings.Builder
+	for i, p := range m.snap.Standings {
+		line := fmt.Sprintf("%2d. %-12s %6d", i+1, p.Name, p.Score)
+		if m.snap.Phase == SCORING {
+			switch {
+			case p.Gained > 0:
+				line += fmt.Sprintf(" +%d", p.Gained)
+			case p.Answer < 0:
+				line += " –"
+			}
+		}
+		if p.You {
+			line = m.YouStyle.Render(line)
+		}
+		s.WriteString(line + "\n")
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
+	var body string
+	switch m.snap.Phase {
+	case WAITING:
+		body = m.BoxStyle.Render("Players\n\n" + m.scoreboard())
+	case ASKING:
+		body = m.BoxStyle.Render(m.questionView())
+	case SCORING:
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			m.BoxStyle.Render(m.questionView()), "  ", m.BoxStyle.Render("Scores\n\n"+m.scoreboard()))
+	case FINISHED:
+		winner := m.snap.Standings[0]
+		body = m.BoxStyle.Render(fmt.Sprintf("%s wins!\n\n%s\n\n'%s' to play again",
+			winner.Name, m.scoreboard(), m.Keys.Select.Help().Key))
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			body,
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}
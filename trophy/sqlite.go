@@ -0,0 +1,76 @@
+package trophy
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS trophies (
+	fingerprint TEXT NOT NULL,
+	id          TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	place       INTEGER NOT NULL,
+	title       TEXT NOT NULL,
+	at          INTEGER NOT NULL,
+	PRIMARY KEY (fingerprint, id)
+);
+`
+
+// SQLiteStore keeps trophies in a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create trophies schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Award(fingerprint string, t Trophy) (bool, error) {
+	res, err := s.db.Exec(`INSERT OR IGNORE INTO trophies (fingerprint, id, name, place, title, at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		fingerprint, t.ID, t.Name, t.Place, t.Title, t.At.UnixMilli())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *SQLiteStore) List(fingerprint string) ([]Trophy, error) {
+	rows, err := s.db.Query(`SELECT id, name, place, title, at FROM trophies
+		WHERE fingerprint = ? ORDER BY at, id`, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trophies []Trophy
+	for rows.Next() {
+		var t Trophy
+		var at int64
+		if err := rows.Scan(&t.ID, &t.Name, &t.Place, &t.Title, &at); err != nil {
+			return nil, err
+		}
+		t.At = time.UnixMilli(at)
+		trophies = append(trophies, t)
+	}
+	return trophies, rows.Err()
+}
@@ -0,0 +1,326 @@
+// Package trophy keeps the trophies players win, for placing in the
+// scheduled tournaments and for finishing a leaderboard season on the
+// podium, and the titles those let them wear next to their name: in the
+// lobby, on leaderboards and on match screens. Trophies are kept by the
+// fingerprint of the player's key, so anonymous players can't win them.
+package trophy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/profile"
+)
+
+const (
+	PODIUM      = 3               // places awarded at the end of a season
+	SEASONDEPTH = 100             // entries looked through for the podium's players
+	SEASONCHECK = time.Hour       // how often Seasons looks for one that ended
+	TITLECACHE  = 5 * time.Minute // how long the titles players wear are remembered
+	// NOTITLE is the profile title of a player who chose to wear none.
+	NOTITLE = "-"
+)
+
+// Trophy is a player's placing in a cup or a season.
+type Trophy struct {
+	// ID names what the trophy was won for, and where, so nobody wins the
+	// same one twice.
+	ID    string
+	Name  string // e.g. "Snake Cup, Fri 16 Oct 2026"
+	Place int    // 1 for first
+	Title string // what the trophy lets the player wear, e.g. "Snake Cup Champion"
+	At    time.Time
+}
+
+// Ordinal spells place as players read it, "1st" for 1.
+func Ordinal(place int) string {
+	switch {
+	case place%100 >= 11 && place%100 <= 13:
+		return fmt.Sprintf("%dth", place)
+	case place%10 == 1:
+		return fmt.Sprintf("%dst", place)
+	case place%10 == 2:
+		return fmt.Sprintf("%dnd", place)
+	case place%10 == 3:
+		return fmt.Sprintf("%drd", place)
+	}
+	return fmt.Sprintf("%dth", place)
+}
+
+// Store keeps the trophies of each player.
+type Store interface {
+	// Award gives t to the player with fingerprint, reporting false if
+	// they had one with its ID already.
+	Award(fingerprint string, t Trophy) (bool, error)
+	// List returns the player's trophies, the oldest first.
+	List(fingerprint string) ([]Trophy, error)
+}
+
+// MemoryStore keeps trophies in memory.
+type MemoryStore struct {
+	mu       sync.Mutex
+	trophies map[string][]Trophy
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{trophies: make(map[string][]Trophy)}
+}
+
+func (s *MemoryStore) Award(fingerprint string, t Trophy) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.ContainsFunc(s.trophies[fingerprint], func(o Trophy) bool { return o.ID == t.ID }) {
+		return false, nil
+	}
+	s.trophies[fingerprint] = append(s.trophies[fingerprint], t)
+	return true, nil
+}
+
+func (s *MemoryStore) List(fingerprint string) ([]Trophy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.trophies[fingerprint]), nil
+}
+
+// Best sorts trophies best first: the highest placed, then the latest won.
+func Best(trophies []Trophy) {
+	slices.SortStableFunc(trophies, func(a, b Trophy) int {
+		if a.Place != b.Place {
+			return a.Place - b.Place
+		}
+		return b.At.Compare(a.At)
+	})
+}
+
+// Titles lists the titles trophies let their winner wear, the best first.
+func Titles(trophies []Trophy) []string {
+	trophies = slices.Clone(trophies)
+	Best(trophies)
+	var titles []string
+	for _, t := range trophies {
+		if !slices.Contains(titles, t.Title) {
+			titles = append(titles, t.Title)
+		}
+	}
+	return titles
+}
+
+// Worn is the title a player with trophies wears, having chosen chosen in
+// their profile: that one if they won it, none for NOTITLE, and their best
+// otherwise, so the first trophy they win shows without them picking it.
+func Worn(trophies []Trophy, chosen string) string {
+	titles := Titles(trophies)
+	switch {
+	case chosen == NOTITLE || len(titles) == 0:
+		return ""
+	case slices.Contains(titles, chosen):
+		return chosen
+	}
+	return titles[0]
+}
+
+// Label is name as shown with the title its player wears, if any.
+func Label(name, title string) string {
+	if title == "" {
+		return name
+	}
+	return name + " «" + title + "»"
+}
+
+type worn struct {
+	title string
+	until time.Time
+}
+
+// Case hands out trophies and tells what title each player wears.
+type Case struct {
+	store    Store
+	profiles profile.Store // for the titles players chose, may be nil
+
+	mu     sync.Mutex
+	titles map[string]worn // by fingerprint, until TITLECACHE runs out
+}
+
+func NewCase(store Store, profiles profile.Store) *Case {
+	return &Case{store: store, profiles: profiles, titles: make(map[string]worn)}
+}
+
+// Award gives t to the player with fingerprint, unless they're anonymous
+// or won it already. Failures are only logged: the trophy is awarded again
+// the next time the placing comes round, if it does.
+func (c *Case) Award(fingerprint string, t Trophy) {
+	if c == nil || fingerprint == "" {
+		return
+	}
+	added, err := c.store.Award(fingerprint, t)
+	if err != nil {
+		log.Warn("Could not award trophy", "fingerprint", fingerprint, "trophy", t.ID, "err", err)
+		return
+	}
+	if added {
+		log.Info("Trophy", "fingerprint", fingerprint, "name", t.Name, "place", t.Place)
+		c.mu.Lock()
+		delete(c.titles, fingerprint)
+		c.mu.Unlock()
+	}
+}
+
+// Trophies lists what the player with fingerprint won, the best first.
+func (c *Case) Trophies(fingerprint string) []Trophy {
+	if c == nil || fingerprint == "" {
+		return nil
+	}
+	trophies, err := c.store.List(fingerprint)
+	if err != nil {
+		log.Warn("Could not load trophies", "fingerprint", fingerprint, "err", err)
+	}
+	Best(trophies)
+	return trophies
+}
+
+// Title is what the player with fingerprint wears, empty for none.
+func (c *Case) Title(fingerprint string) string {
+	if c == nil || fingerprint == "" {
+		return ""
+	}
+	now := time.Now()
+	c.mu.Lock()
+	w, ok := c.titles[fingerprint]
+	c.mu.Unlock()
+	if ok && now.Before(w.until) {
+		return w.title
+	}
+
+	var chosen string
+	if c.profiles != nil {
+		p, _, err := c.profiles.Load(fingerprint)
+		if err != nil {
+			log.Warn("Could not load profile", "fingerprint", fingerprint, "err", err)
+		}
+		chosen = p.Title
+	}
+	title := Worn(c.Trophies(fingerprint), chosen)
+	c.mu.Lock()
+	c.titles[fingerprint] = worn{title: title, until: now.Add(TITLECACHE)}
+	c.mu.Unlock()
+	return title
+}
+
+// Wear has the player with fingerprint and profile p wear title from now
+// on, empty for their best and NOTITLE for none, and returns what they
+// wear.
+func (c *Case) Wear(fingerprint string, p *profile.Profile, title string) string {
+	if c == nil || fingerprint == "" || p == nil {
+		return ""
+	}
+	profile.Update(c.profiles, fingerprint, p, func(p *profile.Profile) { p.Title = title })
+	title = Worn(c.Trophies(fingerprint), title)
+	c.mu.Lock()
+	c.titles[fingerprint] = worn{title: title, until: time.Now().Add(TITLECACHE)}
+	c.mu.Unlock()
+	return title
+}
+
+// Scores wraps s so the entries it ranks name their players with the
+// titles they wear. Nil stays nil, for sessions without a leaderboard.
+func (c *Case) Scores(s leaderboard.Store) leaderboard.Store {
+	if c == nil || s == nil {
+		return s
+	}
+	return titled{Store: s, trophies: c}
+}
+
+type titled struct {
+	leaderboard.Store
+	trophies *Case
+}
+
+func (s titled) Top(f leaderboard.Filter, n int) []leaderboard.Entry {
+	top := s.Store.Top(f, n)
+	for i := range top {
+		top[i].Player = Label(top[i].Player, s.trophies.Title(top[i].Fingerprint))
+	}
+	return top
+}
+
+func (s titled) Best(f leaderboard.Filter, fingerprint string) (leaderboard.Entry, bool) {
+	e, ok := s.Store.Best(f, fingerprint)
+	if ok {
+		e.Player = Label(e.Player, s.trophies.Title(e.Fingerprint))
+	}
+	return e, ok
+}
+
+// Seasons awards the podium of every leaderboard once its season ended,
+// looking when it starts and then every SEASONCHECK until ctx is done.
+// Titles names games by their ID.
+func (c *Case) Seasons(ctx context.Context, scores leaderboard.Store, titles func(game string) string) {
+	t := time.NewTicker(SEASONCHECK)
+	defer t.Stop()
+	now := time.Now()
+	for {
+		season, end := lastSeason(now)
+		c.awardSeason(scores, season, end, titles)
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-t.C:
+		}
+	}
+}
+
+// lastSeason names the season before the one now falls in, and says when
+// it ended.
+func lastSeason(now time.Time) (string, time.Time) {
+	end := time.Date(now.Year(), (now.Month()-1)/3*3+1, 1, 0, 0, 0, 0, now.Location())
+	return leaderboard.SeasonOf(end.Add(-time.Nanosecond)), end
+}
+
+// awardSeason gives the PODIUM players of each game and mode in season
+// their trophies, as of end. Boards rank combined and runs with modifiers are left
+// out, as on the leaderboard everyone sees first, and each player places
+// once, with their best run.
+func (c *Case) awardSeason(scores leaderboard.Store, season string, end time.Time, titles func(game string) string) {
+	type board struct{ game, mode string }
+	var boards []board
+	for _, k := range scores.Keys() {
+		b := board{k.Game, k.Mode}
+		if k.Season == season && k.Modifiers == leaderboard.NOMODIFIERS && !slices.Contains(boards, b) {
+			boards = append(boards, b)
+		}
+	}
+	for _, b := range boards {
+		f := leaderboard.Filter{Game: b.game, Mode: b.mode, Modifiers: leaderboard.NOMODIFIERS, Season: season}
+		var placed []string
+		for _, e := range scores.Top(f, SEASONDEPTH) {
+			if len(placed) == PODIUM {
+				break
+			}
+			if e.Fingerprint == "" || slices.Contains(placed, e.Fingerprint) {
+				continue
+			}
+			placed = append(placed, e.Fingerprint)
+			c.Award(e.Fingerprint, seasonTrophy(b.game, titles(b.game), b.mode, season, len(placed), end))
+		}
+	}
+}
+
+func seasonTrophy(game, title, mode, season string, place int, at time.Time) Trophy {
+	name := title
+	if mode != "" {
+		name += " (" + mode + ")"
+	}
+	return Trophy{
+		ID:    fmt.Sprintf("season/%s/%s/%s/%d", season, game, mode, place),
+		Name:  fmt.Sprintf("%s, season %s", name, season),
+		Place: place,
+		Title: fmt.Sprintf("%s #%d", title, place),
+		At:    at,
+	}
+}
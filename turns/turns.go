@@ -0,0 +1,308 @@
+// Package turns runs turn-based board games between two sessions, or a
+// session and the computer: it pairs players by lobby room, deals the
+// seats, takes moves in turn and settles forfeits. Games bring their own
+// positions and moves through Rules.
+//
+// Matches have no goroutine of their own: moves and polls settle them as
+// they come, the computer's moves included.
+package turns
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+const (
+	STALE    = 3 * time.Second        // players not heard from for this long forfeit
+	BOTDELAY = 600 * time.Millisecond // the computer takes to "think"
+)
+
+// Match phases
+const (
+	WAITING = iota
+	PLAYING
+	OVER
+)
+
+// Why matches end besides the board, the game's own reasons aside
+const (
+	RESIGNED = "resignation"
+	LEFT     = "abandonment"
+)
+
+// BOTNAME names the computer opponent.
+const BOTNAME = "Computer"
+
+// Rules are a game's part of a match. Seats number the players in the order
+// they move, 0 first. S must copy by value, or Play must return a fresh S,
+// so snapshots don't change under their readers.
+type Rules[S, M any] struct {
+	// Start is the position matches begin from.
+	Start func() S
+	// ToMove is the seat whose turn it is.
+	ToMove func(s S) int
+	// Play is s after m, false if m isn't legal.
+	Play func(s S, m M) (S, bool)
+	// Result tells whether s ends the match, with the winning seat, or -1
+	// for a draw, and why.
+	Result func(s S) (winner int, reason string, over bool)
+	// Bot picks the computer's move. Nil when a game has no computer
+	// opponent.
+	Bot func(s S, rng *rand.Rand) M
+}
+
+type side struct {
+	name   string
+	player block.Player // who sat down, empty for the computer
+	seat   int
+	bot    bool // played by the match itself, never goes quiet
+	seen   time.Time
+}
+
+type Match[S, M any] struct {
+	rules  *Rules[S, M]
+	mu     sync.Mutex
+	phase  int
+	sides  []*side
+	state  S
+	moves  []M
+	start  time.Time
+	botAt  time.Time // when the computer moves, if it's its turn
+	winner int       // side, or -1 for a draw
+	reason string
+	rng    *rand.Rand
+}
+
+// Lobby pairs the players of one game.
+type Lobby[S, M any] struct {
+	rules   Rules[S, M]
+	mu      sync.Mutex
+	waiting map[string][]*Match[S, M] // by lobby room, empty for anyone
+}
+
+func NewLobby[S, M any](rules Rules[S, M]) *Lobby[S, M] {
+	return &Lobby[S, M]{rules: rules, waiting: make(map[string][]*Match[S, M])}
+}
+
+func (l *Lobby[S, M]) newMatch(p block.Player, now time.Time) *Match[S, M] {
+	m := &Match[S, M]{rules: &l.rules, phase: WAITING, winner: -1, rng: rand.New(rand.NewSource(now.UnixNano()))}
+	m.sides = []*side{{name: p.Label(), player: p, seen: now}}
+	return m
+}
+
+// Join pairs p with the first player waiting for an opponent from the same
+// lobby room who neither blocked nor was blocked by them, or waits for one.
+// It returns the match and the player's side in it.
+func (l *Lobby[S, M]) Join(room string, p block.Player, now time.Time) (*Match[S, M], int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Rooms come and go, so forget the matches nobody waits at anymore.
+	for k, waits := range l.waiting {
+		waits = slices.DeleteFunc(waits, func(m *Match[S, M]) bool {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.drop(now)
+			return m.phase != WAITING || len(m.sides) == 0
+		})
+		if len(waits) == 0 {
+			delete(l.waiting, k)
+		} else {
+			l.waiting[k] = waits
+		}
+	}
+
+	for i, m := range l.waiting[room] {
+		m.mu.Lock()
+		if m.phase == WAITING && len(m.sides) == 1 && !block.Apart(m.sides[0].player, p) {
+			l.waiting[room] = slices.Delete(l.waiting[room], i, i+1)
+			m.sides = append(m.sides, &side{name: p.Label(), player: p, seen: now})
+			m.begin(now)
+			m.mu.Unlock()
+			return m, 1
+		}
+		m.mu.Unlock()
+	}
+
+	m := l.newMatch(p, now)
+	l.waiting[room] = append(l.waiting[room], m)
+	return m, 0
+}
+
+// VersusBot starts a match between p and the computer, side 0 and 1.
+func (l *Lobby[S, M]) VersusBot(p block.Player, now time.Time) (*Match[S, M], int) {
+	m := l.newMatch(p, now)
+	m.sides = append(m.sides, &side{name: BOTNAME, bot: true})
+	m.begin(now)
+	return m, 0
+}
+
+// begin deals the seats at random.
+func (m *Match[S, M]) begin(now time.Time) {
+	m.phase = PLAYING
+	m.start = now
+	m.state = m.rules.Start()
+	first := m.rng.Intn(2)
+	m.sides[first].seat = 0
+	m.sides[1-first].seat = 1
+	m.botAt = now.Add(BOTDELAY)
+}
+
+// drop removes players that went quiet while waiting, and makes them
+// forfeit once the match is on.
+func (m *Match[S, M]) drop(now time.Time) {
+	if m.phase == WAITING {
+		m.sides = slices.DeleteFunc(m.sides, func(s *side) bool { return m.quiet(s, now) })
+		return
+	}
+	if m.phase == OVER {
+		return
+	}
+	for i, s := range m.sides {
+		if m.quiet(s, now) {
+			m.end(1-i, LEFT)
+			return
+		}
+	}
+}
+
+func (m *Match[S, M]) quiet(s *side, now time.Time) bool {
+	return !s.bot && now.Sub(s.seen) > STALE
+}
+
+func (m *Match[S, M]) end(winner int, reason string) {
+	m.phase = OVER
+	m.winner = winner
+	m.reason = reason
+}
+
+// mover is the side whose turn it is.
+func (m *Match[S, M]) mover() int {
+	if m.sides[0].seat == m.rules.ToMove(m.state) {
+		return 0
+	}
+	return 1
+}
+
+// move plays mv for the side to move, if legal, ending the match once the
+// board does.
+func (m *Match[S, M]) move(mv M, now time.Time) bool {
+	next, ok := m.rules.Play(m.state, mv)
+	if !ok {
+		return false
+	}
+	m.state = next
+	m.moves = append(m.moves, mv)
+	m.botAt = now.Add(BOTDELAY)
+	if seat, reason, over := m.rules.Result(m.state); over {
+		winner := -1
+		for i, s := range m.sides {
+			if seat >= 0 && s.seat == seat {
+				winner = i
+			}
+		}
+		m.end(winner, reason)
+	}
+	return true
+}
+
+// Play has side i make mv, if it's their turn and the move is legal, and
+// reports whether it was.
+func (m *Match[S, M]) Play(i int, mv M) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.phase != PLAYING || i >= len(m.sides) || m.mover() != i {
+		return false
+	}
+	return m.move(mv, time.Now())
+}
+
+// advance has the computer move once it has "thought" long enough.
+func (m *Match[S, M]) advance(now time.Time) {
+	m.drop(now)
+	if m.phase != PLAYING || !m.sides[m.mover()].bot || now.Before(m.botAt) || m.rules.Bot == nil {
+		return
+	}
+	m.move(m.rules.Bot(m.state, m.rng), now)
+}
+
+// Resign has side i give the match up.
+func (m *Match[S, M]) Resign(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.phase == PLAYING && i < len(m.sides) {
+		m.end(1-i, RESIGNED)
+	}
+}
+
+// Opponents are the players facing side i, the computer left out.
+func (m *Match[S, M]) Opponents(i int) []block.Player {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ps []block.Player
+	for j, s := range m.sides {
+		if !s.bot && j != i {
+			ps = append(ps, s.player)
+		}
+	}
+	return ps
+}
+
+// Leave forfeits side i.
+func (m *Match[S, M]) Leave(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < len(m.sides) {
+		m.sides[i].seen = time.Time{}
+	}
+	m.drop(time.Now())
+}
+
+// Player is one side of the board as sessions render it.
+type Player struct {
+	Name string
+	Seat int
+	Bot  bool
+}
+
+type Snapshot[S, M any] struct {
+	Phase   int
+	State   S
+	Moves   []M // every move so far, the last one last
+	Start   time.Time
+	Players []Player
+	Winner  int    // side, or -1 for a draw
+	Reason  string // why the match ended, see RESIGNED
+}
+
+// Poll marks side i as present, settles the match and describes it. It
+// reports false once side i is no longer in the match, when it went quiet
+// while waiting.
+func (m *Match[S, M]) Poll(i int, now time.Time) (Snapshot[S, M], bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i >= len(m.sides) {
+		return Snapshot[S, M]{}, false
+	}
+	m.sides[i].seen = now
+	m.advance(now)
+
+	s := Snapshot[S, M]{
+		Phase:  m.phase,
+		State:  m.state,
+		Moves:  slices.Clone(m.moves),
+		Start:  m.start,
+		Winner: m.winner,
+		Reason: m.reason,
+	}
+	for _, sd := range m.sides {
+		s.Players = append(s.Players, Player{Name: sd.name, Seat: sd.seat, Bot: sd.bot})
+	}
+	return s, true
+}
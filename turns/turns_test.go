@@ -0,0 +1,160 @@
+package turns
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+// nim is a pile players take one or two from in turn; whoever takes the
+// last wins.
+type nim struct {
+	left, turn int
+}
+
+var rules = Rules[nim, int]{
+	Start:  func() nim { return nim{left: 5} },
+	ToMove: func(s nim) int { return s.turn },
+	Play: func(s nim, take int) (nim, bool) {
+		if take < 1 || take > 2 || take > s.left {
+			return s, false
+		}
+		return nim{left: s.left - take, turn: 1 - s.turn}, true
+	},
+	Result: func(s nim) (int, string, bool) {
+		if s.left == 0 {
+			return 1 - s.turn, "last taken", true
+		}
+		return 0, "", false
+	},
+	Bot: func(s nim, _ *rand.Rand) int { return 1 },
+}
+
+var (
+	ana = block.Player{Name: "ana", Fingerprint: "SHA256:ana"}
+	bob = block.Player{Name: "bob", Fingerprint: "SHA256:bob"}
+)
+
+// pair starts a match between ana and bob and returns the side to move.
+func pair(t *testing.T, now time.Time) (*Match[nim, int], int) {
+	t.Helper()
+	l := NewLobby(rules)
+	m, a := l.Join("", ana, now)
+	if m2, b := l.Join("", bob, now); m2 != m || a != 0 || b != 1 {
+		t.Fatalf("Join = sides %d and %d of different matches", a, b)
+	}
+	s, _ := m.Poll(0, now)
+	if s.Phase != PLAYING {
+		t.Fatalf("phase %d after pairing, want PLAYING", s.Phase)
+	}
+	return m, m.mover()
+}
+
+func TestPlay(t *testing.T) {
+	now := time.Now()
+	m, first := pair(t, now)
+	if m.Play(1-first, 1) {
+		t.Error("played out of turn")
+	}
+	if m.Play(first, 3) {
+		t.Error("played an illegal move")
+	}
+	for _, take := range []int{2, 2, 1} { // first takes the last
+		if !m.Play(m.mover(), take) {
+			t.Fatalf("move %d rejected", take)
+		}
+	}
+	s, _ := m.Poll(first, now)
+	if s.Phase != OVER || s.Winner != first || s.Reason != "last taken" || len(s.Moves) != 3 {
+		t.Errorf("after the last move: %+v, want side %d won", s, first)
+	}
+	if m.Play(m.mover(), 1) {
+		t.Error("played after the end")
+	}
+}
+
+func TestResign(t *testing.T) {
+	m, _ := pair(t, time.Now())
+	m.Resign(1)
+	m.Resign(0) // too late, it's over
+	if s, _ := m.Poll(0, time.Now()); s.Phase != OVER || s.Winner != 0 || s.Reason != RESIGNED {
+		t.Errorf("after resigning: %+v, want side 0 won by resignation", s)
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	t.Run("quiet", func(t *testing.T) {
+		now := time.Now()
+		m, _ := pair(t, now)
+		m.Poll(1, now.Add(STALE/2))
+		// Side 0 hasn't polled for longer than STALE.
+		s, _ := m.Poll(1, now.Add(STALE+time.Second))
+		if s.Phase != OVER || s.Winner != 1 || s.Reason != LEFT {
+			t.Errorf("after side 0 went quiet: %+v, want side 1 won by abandonment", s)
+		}
+	})
+	t.Run("left", func(t *testing.T) {
+		m, _ := pair(t, time.Now())
+		m.Leave(1)
+		s, _ := m.Poll(0, time.Now())
+		if s.Phase != OVER || s.Winner != 0 || s.Reason != LEFT {
+			t.Errorf("after side 1 left: %+v, want side 0 won by abandonment", s)
+		}
+		// Leaving once it's over changes nothing.
+		m.Leave(0)
+		if s, _ := m.Poll(1, time.Now()); s.Winner != 0 {
+			t.Errorf("winner %d after the winner left too, want 0", s.Winner)
+		}
+	})
+	t.Run("waiting", func(t *testing.T) {
+		now := time.Now()
+		l := NewLobby(rules)
+		m, _ := l.Join("", ana, now)
+		later, side := l.Join("", bob, now.Add(STALE+time.Second))
+		if later == m || side != 0 {
+			t.Fatal("paired with a player who went quiet while waiting")
+		}
+		if _, ok := m.Poll(0, now.Add(STALE+time.Second)); ok {
+			t.Error("the quiet player is still in their match")
+		}
+	})
+}
+
+func TestJoinApart(t *testing.T) {
+	now := time.Now()
+	l := NewLobby(rules)
+	blocker := block.Player{Name: "cy", Fingerprint: "SHA256:cy", Blocked: []string{ana.Fingerprint}}
+	m, _ := l.Join("", ana, now)
+	if other, side := l.Join("", blocker, now); other == m || side != 0 {
+		t.Error("paired a player with someone they blocked")
+	}
+	if other, _ := l.Join("room", bob, now); other == m {
+		t.Error("paired players from different rooms")
+	}
+	if other, side := l.Join("", bob, now); other != m || side != 1 {
+		t.Error("bob wasn't paired with ana")
+	}
+}
+
+func TestVersusBot(t *testing.T) {
+	now := time.Now()
+	l := NewLobby(rules)
+	m, side := l.VersusBot(ana, now)
+	if len(m.Opponents(side)) != 0 {
+		t.Error("the computer is listed as an opponent")
+	}
+	if m.mover() == side {
+		m.Play(side, 1)
+	}
+	before, _ := m.Poll(side, now)
+	if after, _ := m.Poll(side, now.Add(BOTDELAY/2)); len(after.Moves) != len(before.Moves) {
+		t.Error("the computer moved before BOTDELAY")
+	}
+	// Play times the computer's move from the clock, a little after now.
+	after, _ := m.Poll(side, now.Add(BOTDELAY+time.Second))
+	if len(after.Moves) != len(before.Moves)+1 || after.Phase != PLAYING {
+		t.Errorf("after BOTDELAY: %d moves, phase %d, want the computer's move", len(after.Moves), after.Phase)
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	HISTORY   = 100 // runs kept in the profile
+	CHARTRUNS = 30  // latest runs charted
+	CHARTROWS = 6
+)
+
+// Run is a finished test, as kept in the player's profile.
+type Run struct {
+	At       time.Time
+	Mode     string
+	WPM      int
+	Accuracy int // percent of keystrokes that were right
+}
+
+// history is the save typing keeps in the profile.
+type history struct {
+	Runs []Run // oldest first
+}
+
+func (h *history) add(r Run) {
+	h.Runs = append(h.Runs, r)
+	if n := len(h.Runs); n > HISTORY {
+		h.Runs = slices.Clone(h.Runs[n-HISTORY:])
+	}
+}
+
+// of lists the runs of mode, oldest first.
+func (h history) of(mode string) []Run {
+	var rs []Run
+	for _, r := range h.Runs {
+		if r.Mode == mode {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// best is the fastest run of mode, zero for none.
+func (h history) best(mode string) Run {
+	var b Run
+	for _, r := range h.of(mode) {
+		if r.WPM > b.WPM {
+			b = r
+		}
+	}
+	return b
+}
+
+var eighths = []rune(" ▁▂▃▄▅▆▇█")
+
+// chart draws the WPM of runs as bars, a column each, CHARTROWS high and
+// scaled to the fastest, with the scale on the left.
+func chart(runs []Run) string {
+	if len(runs) == 0 {
+		return ""
+	}
+	runs = runs[max(0, len(runs)-CHARTRUNS):]
+	top := 1
+	for _, r := range runs {
+		top = max(top, r.WPM)
+	}
+	var s strings.Builder
+	for row := CHARTROWS - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case CHARTROWS - 1:
+			label = fmt.Sprint(top)
+		case 0:
+			label = "0"
+		}
+		fmt.Fprintf(&s, "%4s │", label)
+		for _, r := range runs {
+			// Height in eighths of a row, of the bar's part in this row.
+			h := r.WPM*CHARTROWS*8/top - row*8
+			s.WriteRune(eighths[min(8, max(0, h))])
+		}
+		s.WriteString("\n")
+	}
+	s.WriteString("     └" + strings.Repeat("─", len(runs)))
+	return s.String()
+}
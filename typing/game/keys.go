@@ -0,0 +1,47 @@
+package game
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap keeps its commands off the printable keys, which all type.
+type KeyMap struct {
+	Erase     key.Binding
+	EraseWord key.Binding
+	Restart   key.Binding
+	Mode      key.Binding
+	Help      key.Binding
+	Quit      key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Erase:     key.NewBinding(key.WithKeys("backspace"), key.WithHelp("backspace", "erase")),
+		EraseWord: key.NewBinding(key.WithKeys("ctrl+w", "alt+backspace"), key.WithHelp("ctrl+w", "erase word")),
+		Restart:   key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "new text")),
+		Mode:      key.NewBinding(key.WithKeys("left", "right"), key.WithHelp("←/→", "words or quotes")),
+		Help:      key.NewBinding(key.WithKeys("f1"), key.WithHelp("f1", "help")),
+		Quit:      key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "quit")),
+	}
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Restart, k.Mode, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Erase, k.EraseWord},
+		{k.Restart, k.Mode},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"erase":      &k.Erase,
+		"erase-word": &k.EraseWord,
+		"restart":    &k.Restart,
+		"mode":       &k.Mode,
+		"help":       &k.Help,
+		"quit":       &k.Quit,
+	}
+}
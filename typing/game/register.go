@@ -0,0 +1,41 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+)
+
+const GAMENAME = "typing"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Typing Test",
+	Description: "Type words or quotes against the clock, with your speed over time",
+	Category:    games.ARCADE,
+	MinPlayers:  1,
+	MaxPlayers:  1,
+	Session:     5 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer)
+		m.SetContext(env.Ctx)
+		m.Scores = env.Scores
+		m.Player = env.Player
+		m.Fingerprint = env.Fingerprint
+		if env.Profile != nil {
+			m.SetProfile(env.Profile, env.Profiles, env.Fingerprint)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
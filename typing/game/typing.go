@@ -0,0 +1,455 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/profile"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+// Modes
+const (
+	WORDS  = "words"
+	QUOTES = "quotes"
+)
+
+const (
+	BOARD     = "english"
+	TICK      = 250 * time.Millisecond // of the live speed
+	TOPSCORES = 5                      // on the results screen
+	MAXWIDTH  = 64                     // of the text, in columns
+)
+
+var modes = []string{WORDS, QUOTES}
+
+type tickMsg time.Time
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	TitleStyle  lipgloss.Style
+	TodoStyle   lipgloss.Style
+	GoodStyle   lipgloss.Style
+	BadStyle    lipgloss.Style
+	CursorStyle lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, ranks finished tests by speed under Player's name
+	// and their key.
+	Scores      leaderboard.Store
+	Player      string
+	Fingerprint string
+	top         []leaderboard.Entry
+
+	profile  *profile.Profile
+	Profiles profile.Store
+	history  history
+
+	rng     *rand.Rand
+	mode    string
+	text    []rune
+	author  string
+	input   []rune
+	keys    int // printable keystrokes, right or wrong
+	misses  int // keystrokes that didn't match the text
+	started time.Time
+	ended   time.Time
+	now     time.Time // of the last tick, for the live speed
+	done    bool
+	best    bool // the finished run is the player's fastest in its mode
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+func NewModel(width, height int, r *lipgloss.Renderer) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		TitleStyle:  r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		TodoStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		GoodStyle:   r.NewStyle().Foreground(lipgloss.Color("15")),
+		BadStyle:    r.NewStyle().Foreground(lipgloss.Color("15")).Background(lipgloss.Color("1")),
+		CursorStyle: r.NewStyle().Reverse(true),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys: DefaultKeyMap(),
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		mode: WORDS,
+		ctx:  context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	m.Restart()
+	return m
+}
+
+// SetContext binds the clock to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetProfile applies a player's key bindings and charts their runs, kept
+// in the profile. Players without a key keep theirs for the session.
+func (m *Model) SetProfile(p *profile.Profile, store profile.Store, fingerprint string) {
+	m.profile = p
+	m.Profiles = store
+	m.Fingerprint = fingerprint
+	ui.Rebind(&m.Keys, p.Keys)
+
+	var h history
+	if _, err := p.GetSave(GAMENAME, &h); err != nil {
+		log.Warn("Could not read typing history", "err", err)
+		h = history{}
+	}
+	m.history = h
+}
+
+func (m *Model) save() {
+	if m.profile == nil {
+		return
+	}
+	if err := m.profile.SetSave(GAMENAME, m.history); err != nil {
+		log.Warn("Could not encode typing history", "err", err)
+		return
+	}
+	profile.Save(m.Profiles, m.Fingerprint, m.profile)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m Model) tick() tea.Cmd {
+	return ui.Every(m.ctx, TICK, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Restart draws a new text in the current mode. The clock starts with the
+// first key.
+func (m *Model) Restart() {
+	text, author := prompt(m.rng, m.mode)
+	m.text, m.author = []rune(text), author
+	m.input = nil
+	m.keys, m.misses = 0, 0
+	m.started, m.ended = time.Time{}, time.Time{}
+	m.done, m.best = false, false
+	m.top = nil
+}
+
+// SetMode switches between words and quotes, starting over.
+func (m *Model) SetMode(mode string) {
+	m.mode = mode
+	m.Restart()
+}
+
+func (m *Model) nextMode() {
+	for i, mode := range modes {
+		if mode == m.mode {
+			m.SetMode(modes[(i+1)%len(modes)])
+			return
+		}
+	}
+}
+
+// write takes a typed character, finishing the test when the text is
+// typed through.
+func (m *Model) write(r rune, now time.Time) {
+	if m.done || len(m.input) >= len(m.text) {
+		return
+	}
+	if m.started.IsZero() {
+		m.started = now
+	}
+	m.keys++
+	if r != m.text[len(m.input)] {
+		m.misses++
+	}
+	m.input = append(m.input, r)
+	if len(m.input) == len(m.text) {
+		m.finish(now)
+	}
+}
+
+func (m *Model) erase() {
+	if n := len(m.input); n > 0 && !m.done {
+		m.input = m.input[:n-1]
+	}
+}
+
+// eraseWord takes back the word being typed, or the space before it and
+// the word before that when right after one.
+func (m *Model) eraseWord() {
+	if m.done {
+		return
+	}
+	n := len(m.input)
+	for n > 0 && m.input[n-1] == ' ' {
+		n--
+	}
+	for n > 0 && m.input[n-1] != ' ' {
+		n--
+	}
+	m.input = m.input[:n]
+}
+
+// correct counts the typed characters that match the text, as they stand.
+func (m Model) correct() int {
+	n := 0
+	for i, r := range m.input {
+		if r == m.text[i] {
+			n++
+		}
+	}
+	return n
+}
+
+// elapsed is how long the test has run at now, or ran.
+func (m Model) elapsed(now time.Time) time.Duration {
+	switch {
+	case m.started.IsZero():
+		return 0
+	case m.done:
+		return m.ended.Sub(m.started)
+	}
+	return now.Sub(m.started)
+}
+
+// wpm is the speed at now, in words of five characters a minute, counting
+// only characters typed right: raw counts every one.
+func (m Model) wpm(now time.Time, raw bool) int {
+	d := m.elapsed(now)
+	if d < time.Second {
+		return 0
+	}
+	chars := m.correct()
+	if raw {
+		chars = len(m.input)
+	}
+	return int(float64(chars) / 5 / d.Minutes())
+}
+
+// accuracy is the percentage of keystrokes that were right, errors fixed
+// since included.
+func (m Model) accuracy() int {
+	if m.keys == 0 {
+		return 100
+	}
+	return (m.keys - m.misses) * 100 / m.keys
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      m.mode,
+		Modifiers: leaderboard.NOMODIFIERS,
+		Board:     BOARD,
+		Season:    leaderboard.SeasonOf(m.ended),
+	}
+}
+
+// finish ends the test, adds it to the player's history and ranks it.
+func (m *Model) finish(now time.Time) {
+	m.done = true
+	m.ended = now
+	run := Run{At: now, Mode: m.mode, WPM: m.wpm(now, false), Accuracy: m.accuracy()}
+	m.best = run.WPM > m.history.best(m.mode).WPM
+	m.history.add(run)
+	if m.profile != nil {
+		m.profile.Record(GAMENAME+"-"+m.mode, run.WPM)
+	}
+	m.save()
+	m.submit(run)
+}
+
+func (m *Model) submit(run Run) {
+	if m.Scores == nil {
+		return
+	}
+	k := m.scoreKey()
+	if run.WPM > 0 {
+		err := m.Scores.Submit(leaderboard.Entry{
+			Key:         k,
+			Player:      m.Player,
+			Fingerprint: m.Fingerprint,
+			Score:       run.WPM,
+			Points:      run.WPM,
+			At:          run.At,
+		})
+		if err != nil {
+			log.Warn("Could not submit score", "err", err)
+		}
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tickMsg:
+		m.now = time.Time(msg)
+		return m, m.tick()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Restart):
+			m.Restart()
+		case key.Matches(msg, m.Keys.Mode):
+			m.nextMode()
+		case key.Matches(msg, m.Keys.EraseWord):
+			m.eraseWord()
+		case key.Matches(msg, m.Keys.Erase):
+			m.erase()
+		case msg.Type == tea.KeySpace:
+			m.write(' ', time.Now())
+		case msg.Type == tea.KeyRunes && !msg.Paste:
+			for _, r := range msg.Runes {
+				m.write(r, time.Now())
+			}
+		}
+	}
+	return m, nil
+}
+
+// lines word-wraps the text to width, by the index each line starts at.
+func (m Model) lines(width int) []int {
+	starts := []int{0}
+	start, space := 0, -1
+	for i, r := range m.text {
+		if r == ' ' {
+			space = i
+		}
+		if i-start >= width && space > start {
+			start = space + 1
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// textView renders the text with what's typed over it: right characters
+// bright, wrong ones marked and the cursor on the next.
+func (m Model) textView() string {
+	width := max(20, min(MAXWIDTH, m.Width-10))
+	starts := append(m.lines(width), len(m.text))
+	var rows []string
+	for l := 0; l+1 < len(starts); l++ {
+		var s strings.Builder
+		for i := starts[l]; i < starts[l+1]; i++ {
+			c := string(m.text[i])
+			switch {
+			case i < len(m.input) && m.input[i] == m.text[i]:
+				s.WriteString(m.GoodStyle.Render(c))
+			case i < len(m.input):
+				s.WriteString(m.BadStyle.Render(c))
+			case i == len(m.input) && !m.done:
+				s.WriteString(m.CursorStyle.Render(c))
+			default:
+				s.WriteString(m.TodoStyle.Render(c))
+			}
+		}
+		rows = append(rows, s.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m Model) header() string {
+	now := m.now
+	if now.Before(m.started) {
+		now = m.started
+	}
+	d := m.elapsed(now)
+	return fmt.Sprintf("Typing Test | %s | WPM: %d | Accuracy: %d%% | Time: %d:%02d",
+		m.mode, m.wpm(now, false), m.accuracy(), int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func (m Model) resultsView() string {
+	d := m.elapsed(m.ended)
+	lines := []string{
+		m.TitleStyle.Render(fmt.Sprintf("%d WPM", m.wpm(m.ended, false))),
+		fmt.Sprintf("%d%% accuracy, %d raw WPM, %.1fs", m.accuracy(), m.wpm(m.ended, true), d.Seconds()),
+		fmt.Sprintf("%d keystrokes, %d missed", m.keys, m.misses),
+	}
+	if m.best {
+		lines = append(lines, m.TitleStyle.Render("Your fastest yet!"))
+	}
+	if len(m.top) > 0 {
+		var s strings.Builder
+		fmt.Fprintf(&s, "Fastest in %s\n", m.mode)
+		for i, e := range m.top {
+			fmt.Fprintf(&s, "%d. %-12s %4d WPM\n", i+1, e.Player, e.Score)
+		}
+		lines = append(lines, "", strings.TrimRight(s.String(), "\n"))
+	}
+	lines = append(lines, "", m.historyView())
+	lines = append(lines, "", fmt.Sprintf("'%s' for a new text", m.Keys.Restart.Help().Key))
+	return m.BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
+
+// historyView charts the player's speed over their latest runs of the
+// mode.
+func (m Model) historyView() string {
+	runs := m.history.of(m.mode)
+	if len(runs) == 0 {
+		return m.QuitStyle.Render("Finish a test to chart your speed")
+	}
+	total := 0
+	for _, r := range runs {
+		total += r.WPM
+	}
+	shown := min(len(runs), CHARTRUNS)
+	return lipgloss.JoinVertical(lipgloss.Left,
+		fmt.Sprintf("Your last %d %s, best %d WPM, average %d", shown, m.mode, m.history.best(m.mode).WPM, total/len(runs)),
+		chart(runs),
+	)
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle),
+		)
+	}
+	body := []string{m.header(), "", m.textView()}
+	if m.author != "" {
+		body = append(body, m.QuitStyle.Render("— "+m.author))
+	}
+	if m.done {
+		body = append(body, "", m.resultsView())
+	} else if m.started.IsZero() {
+		body = append(body, "", m.QuitStyle.Render("The clock starts with your first key"))
+	}
+	body = append(body, "", m.help.ShortHelpView(m.Keys.ShortHelp()))
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, body...),
+	)
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	_ "embed"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+const PROMPTWORDS = 25 // in a text of words
+
+//go:embed words.txt
+var wordList string
+
+//go:embed quotes.txt
+var quoteList string
+
+type quote struct {
+	text   string
+	author string
+}
+
+var (
+	words  = strings.Fields(wordList)
+	quotes = mustLoadQuotes(quoteList)
+)
+
+// mustLoadQuotes reads the quotes, one a line as text, " -- " and the
+// author, skipping blank lines and lines starting with '#'. Quotes must be
+// plain ASCII, which every keyboard types.
+func mustLoadQuotes(list string) []quote {
+	var qs []quote
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		text, author, ok := strings.Cut(line, " -- ")
+		if !ok || strings.TrimFunc(text, func(r rune) bool { return r >= ' ' && r <= '~' }) != "" {
+			panic(fmt.Sprintf("typing: bad quote %q", line))
+		}
+		qs = append(qs, quote{text: text, author: author})
+	}
+	if len(qs) == 0 {
+		panic("typing: no quotes")
+	}
+	return qs
+}
+
+// prompt draws a text to type in mode, and who wrote it if it's a quote.
+func prompt(rng *rand.Rand, mode string) (string, string) {
+	if mode == QUOTES {
+		q := quotes[rng.Intn(len(quotes))]
+		return q.text, q.author
+	}
+	ws := make([]string, PROMPTWORDS)
+	for i := range ws {
+		ws[i] = words[rng.Intn(len(words))]
+	}
+	return strings.Join(ws, " "), ""
+}
@@ -0,0 +1,68 @@
+package ui
+
+// StateHash is an FNV-1a hash that models feed with everything their View
+// depends on.
+type StateHash struct {
+	h uint64
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+func NewStateHash() StateHash {
+	return StateHash{h: fnvOffset}
+}
+
+func (s *StateHash) Int(n int) {
+	u := uint64(n)
+	for i := 0; i < 8; i++ {
+		s.h ^= u & 0xff
+		s.h *= fnvPrime
+		u >>= 8
+	}
+}
+
+func (s *StateHash) Bool(b bool) {
+	if b {
+		s.Int(1)
+	} else {
+		s.Int(0)
+	}
+}
+
+func (s *StateHash) String(str string) {
+	for i := 0; i < len(str); i++ {
+		s.h ^= uint64(str[i])
+		s.h *= fnvPrime
+	}
+	s.Int(len(str))
+}
+
+func (s StateHash) Sum() uint64 {
+	return s.h
+}
+
+// FrameCache remembers the last rendered frame so identical consecutive
+// frames (paused games, menus, game-over screens) skip rendering entirely.
+// Models keep it behind a pointer because View has a value receiver.
+type FrameCache struct {
+	key    uint64
+	frame  string
+	valid  bool
+	Hits   int
+	Misses int
+}
+
+// Frame returns the cached frame when key matches the last one and renders
+// a new frame otherwise.
+func (c *FrameCache) Frame(key uint64, render func() string) string {
+	if c.valid && c.key == key {
+		c.Hits++
+		return c.frame
+	}
+	c.Misses++
+	c.key, c.frame, c.valid = key, render(), true
+	return c.frame
+}
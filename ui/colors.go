@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ColorProfiles are the color profiles players can force when their
+// terminal is misdetected, by the names profiles keep them under, most
+// colors first. The empty name keeps the detected profile.
+var ColorProfiles = []string{"", "truecolor", "256", "16", "ascii"}
+
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+	"ascii":     termenv.Ascii,
+}
+
+var colorLabels = map[termenv.Profile]string{
+	termenv.TrueColor: "True color",
+	termenv.ANSI256:   "256 colors",
+	termenv.ANSI:      "16 colors",
+	termenv.Ascii:     "No colors",
+}
+
+// ColorProfile looks up a forced profile by name, reporting false for the
+// empty name and unknown ones.
+func ColorProfile(name string) (termenv.Profile, bool) {
+	p, ok := colorProfiles[name]
+	return p, ok
+}
+
+// ColorLabel describes a profile to players.
+func ColorLabel(p termenv.Profile) string {
+	return colorLabels[p]
+}
+
+// SetColors forces the named profile on r, or detected when the name is
+// empty or unknown. Styles made by r pick it up on their next render.
+func SetColors(r *lipgloss.Renderer, name string, detected termenv.Profile) {
+	p, ok := ColorProfile(name)
+	if !ok {
+		p = detected
+	}
+	r.SetColorProfile(p)
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/muesli/termenv"
+)
+
+// heavier swaps thin glyphs for bolder ones of the same width, so boards
+// keep their layout.
+var heavier = map[rune]string{
+	'─': "━", '│': "┃", '┼': "╋",
+	'┌': "┏", '┐': "┓", '└': "┗", '┘': "┛",
+	'╭': "┏", '╮': "┓", '╰': "┗", '╯': "┛",
+	'├': "┣", '┤': "┫", '┬': "┳", '┴': "┻",
+	'·': "•", '∙': "•", '◦': "○", '▪': "■", '▫': "□", '░': "▒",
+}
+
+// HighContrast redraws a rendered view for low-vision players: everything
+// bold, heavier glyphs, and every color moved to the brightest of its hue
+// on black, or to black on white for light backgrounds. It works on any
+// view, so games need nothing of their own. Under the Ascii profile, which
+// has no styles to rewrite, only the glyphs change.
+func HighContrast(view string, p termenv.Profile) string {
+	styled := p != termenv.Ascii
+	var s strings.Builder
+	s.Grow(len(view) + len(view)/8)
+	if styled {
+		s.WriteString("\x1b[1m")
+	}
+	for i := 0; i < len(view); {
+		if view[i] == '\x1b' && i+1 < len(view) && view[i+1] == '[' {
+			j := i + 2
+			for j < len(view) && view[j] >= 0x30 && view[j] <= 0x3f {
+				j++
+			}
+			if j < len(view) && view[j] == 'm' && styled {
+				s.WriteString("\x1b[" + contrastSGR(view[i+2:j]) + "m")
+			} else {
+				s.WriteString(view[i:min(j+1, len(view))])
+			}
+			i = j + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(view[i:])
+		if h, ok := heavier[r]; ok {
+			s.WriteString(h)
+		} else {
+			s.WriteString(view[i : i+size])
+		}
+		i += size
+	}
+	return s.String()
+}
+
+// contrastSGR rewrites the parameters of one SGR sequence.
+func contrastSGR(params string) string {
+	ps := strings.Split(params, ";")
+	var out []string
+	fg, bg := "", ""
+	for i := 0; i < len(ps); i++ {
+		switch n, _ := strconv.Atoi(ps[i]); {
+		case n == 0:
+			// Resets drop the bold too, so bring it back.
+			out = append(out, "0", "1")
+		case n == 2 || n == 22:
+			// Faint is what low vision can't read.
+		case n == 38 || n == 48:
+			c, used, ok := extendedColor(ps[i+1:])
+			i += used
+			if !ok {
+				continue
+			}
+			if n == 38 {
+				fg = foreground(c)
+			} else {
+				bg = background(c)
+			}
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			fg = foreground(ansiRGB(n%10 + 8*boolInt(n >= 90)))
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			bg = background(ansiRGB(n%10 + 8*boolInt(n >= 100)))
+		default:
+			out = append(out, ps[i])
+		}
+	}
+	if bg == "107" {
+		fg = "30"
+	}
+	for _, c := range []string{fg, bg} {
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	return strings.Join(out, ";")
+}
+
+type rgb struct{ r, g, b int }
+
+// extendedColor reads a 38 or 48 color's arguments, 5;n or 2;r;g;b,
+// reporting how many it used.
+func extendedColor(ps []string) (rgb, int, bool) {
+	num := func(i int) int {
+		if i >= len(ps) {
+			return 0
+		}
+		n, _ := strconv.Atoi(ps[i])
+		return n
+	}
+	switch num(0) {
+	case 5:
+		return xtermRGB(num(1)), 2, len(ps) >= 2
+	case 2:
+		return rgb{num(1), num(2), num(3)}, 4, len(ps) >= 4
+	}
+	return rgb{}, len(ps), false
+}
+
+// ansiColors are the usual values of the 16 ANSI colors.
+var ansiColors = [16]rgb{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+func ansiRGB(n int) rgb {
+	return ansiColors[n]
+}
+
+func xtermRGB(n int) rgb {
+	switch {
+	case n < 16:
+		return ansiColors[max(0, n)]
+	case n < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return rgb{levels[n/36], levels[n/6%6], levels[n%6]}
+	}
+	v := 8 + 10*(min(n, 255)-232)
+	return rgb{v, v, v}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// foreground is the bright ANSI color of c's hue, white for grays: blue
+// turns cyan, which reads far better on black.
+func foreground(c rgb) string {
+	hi, lo := max(c.r, c.g, c.b), min(c.r, c.g, c.b)
+	if hi == 0 || float64(hi-lo)/float64(hi) < 0.25 {
+		return "97"
+	}
+	switch hue(c, hi, lo) {
+	case 0, 6:
+		return "91" // red
+	case 1:
+		return "93" // yellow
+	case 2:
+		return "92" // green
+	case 3, 4:
+		return "96" // cyan
+	}
+	return "95" // magenta
+}
+
+// hue buckets c's hue in sixths of the wheel, red at 0 and 6.
+func hue(c rgb, hi, lo int) int {
+	d := float64(hi - lo)
+	var h float64
+	switch hi {
+	case c.r:
+		h = float64(c.g-c.b) / d
+		if h < 0 {
+			h += 6
+		}
+	case c.g:
+		h = float64(c.b-c.r)/d + 2
+	default:
+		h = float64(c.r-c.g)/d + 4
+	}
+	return int(h + 0.5)
+}
+
+// background is white for light colors and black for the rest.
+func background(c rgb) string {
+	if 299*c.r+587*c.g+114*c.b > 128*1000 {
+		return "107"
+	}
+	return "40"
+}
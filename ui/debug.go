@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+const memorySampleEvery = time.Second
+
+func DebugKey() key.Binding {
+	return key.NewBinding(key.WithKeys("f3"), key.WithHelp("f3", "debug overlay"))
+}
+
+// DebugStats collects loop timings for the debug overlay. Models keep it
+// behind a pointer so View, which has a value receiver, can record frames.
+type DebugStats struct {
+	lastTick time.Time
+	interval time.Duration
+	Dropped  int
+
+	frameTime time.Duration
+	frames    int
+
+	memAt      time.Time
+	heap       uint64
+	goroutines int
+}
+
+// Tick records a tick arriving; gaps longer than expected count as dropped
+// ticks.
+func (d *DebugStats) Tick(now time.Time, expected time.Duration) {
+	if !d.lastTick.IsZero() {
+		gap := now.Sub(d.lastTick)
+		d.interval = smooth(d.interval, gap)
+		if missed := int((gap+expected/2)/expected) - 1; missed > 0 {
+			d.Dropped += missed
+		}
+	}
+	d.lastTick = now
+}
+
+// Frame records how long a View call that started at start took.
+func (d *DebugStats) Frame(start time.Time) {
+	d.frameTime = smooth(d.frameTime, time.Since(start))
+	d.frames++
+}
+
+// Lines renders the shared part of the overlay. Memory is process-wide: Go
+// can't attribute heap to a single session's program.
+func (d *DebugStats) Lines() []string {
+	if time.Since(d.memAt) > memorySampleEvery {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		d.heap = ms.HeapAlloc
+		d.goroutines = runtime.NumGoroutine()
+		d.memAt = time.Now()
+	}
+
+	rate := 0.0
+	if d.interval > 0 {
+		rate = float64(time.Second) / float64(d.interval)
+	}
+	return []string{
+		fmt.Sprintf("tick rate   %5.1f/s", rate),
+		fmt.Sprintf("frame time  %s", d.frameTime.Round(time.Microsecond)),
+		fmt.Sprintf("frames      %d", d.frames),
+		fmt.Sprintf("dropped     %d", d.Dropped),
+		fmt.Sprintf("heap        %.1f MiB", float64(d.heap)/(1<<20)),
+		fmt.Sprintf("goroutines  %d", d.goroutines),
+	}
+}
+
+func smooth(avg, sample time.Duration) time.Duration {
+	if avg == 0 {
+		return sample
+	}
+	return avg + (sample-avg)/8
+}
@@ -0,0 +1,58 @@
+package ui
+
+import "time"
+
+const (
+	// REPEATDELAY covers the pause most terminals take before auto-repeat
+	// kicks in after the first press.
+	REPEATDELAY = 550 * time.Millisecond
+	// REPEATWINDOW is the longest gap between two auto-repeats of a held key.
+	REPEATWINDOW = 100 * time.Millisecond
+)
+
+// HoldTracker infers which key is being held down. Classic terminals never
+// report key releases, so a key counts as held while its auto-repeats keep
+// arriving; once they stop for longer than the window it is released.
+type HoldTracker struct {
+	key    string
+	since  time.Time
+	last   time.Time
+	repeat bool
+}
+
+// Press records a keypress and reports whether it was an auto-repeat of the
+// key already held rather than a deliberate press.
+func (h *HoldTracker) Press(key string, now time.Time) bool {
+	if key == h.key && h.held(now) {
+		h.last = now
+		h.repeat = true
+		return true
+	}
+
+	h.key = key
+	h.since = now
+	h.last = now
+	h.repeat = false
+	return false
+}
+
+// Held returns the key currently held down, if any.
+func (h *HoldTracker) Held(now time.Time) (string, bool) {
+	if h.key == "" || !h.held(now) {
+		return "", false
+	}
+	return h.key, true
+}
+
+// Release forgets the held key, e.g. when another input takes over.
+func (h *HoldTracker) Release() {
+	*h = HoldTracker{}
+}
+
+func (h *HoldTracker) held(now time.Time) bool {
+	window := REPEATDELAY
+	if h.repeat {
+		window = REPEATWINDOW
+	}
+	return now.Sub(h.last) <= window
+}
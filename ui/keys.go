@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MoveKeys are the directional bindings shared by grid games.
+type MoveKeys struct {
+	Up    key.Binding
+	Down  key.Binding
+	Left  key.Binding
+	Right key.Binding
+}
+
+// Layout is a movement key preset. Presets conflict with each other (j is
+// down in vim-style QWERTY but left in IJKL), so only one is active at a time.
+type Layout int
+
+const (
+	QWERTY Layout = iota // arrows, WASD and vim HJKL
+	IJKL                 // arrows and IJKL, for the right hand with the thumb on space
+	AZERTY               // arrows and ZQSD; quit moves off q
+	NUMPAD               // the numeric keypad alone, actions on its other keys
+	LAYOUTS
+)
+
+// LAYOUTENV is the client environment variable that picks a layout, e.g.
+// `ssh -o SetEnv=GAMES_LAYOUT=azerty ...`.
+const LAYOUTENV = "GAMES_LAYOUT"
+
+// Application-mode keypad sequences (ESC O x) aren't in bubbletea's key
+// table, so they arrive as alt-prefixed runes. Keypad Enter is ESC O M.
+const (
+	keypadUp    = "alt+Ox"
+	keypadDown  = "alt+Or"
+	keypadLeft  = "alt+Ot"
+	keypadRight = "alt+Ov"
+	keypad0     = "alt+Op"
+	keypad5     = "alt+Ou"
+	keypadDot   = "alt+On"
+	keypadPlus  = "alt+Ok"
+	keypadMinus = "alt+Om"
+	keypadStar  = "alt+Oj"
+
+	KEYPADENTER = "alt+OM"
+)
+
+func (l Layout) String() string {
+	switch l {
+	case IJKL:
+		return "IJKL"
+	case AZERTY:
+		return "AZERTY"
+	case NUMPAD:
+		return "Numpad"
+	default:
+		return "QWERTY"
+	}
+}
+
+func (l Layout) Next() Layout {
+	return (l + 1) % LAYOUTS
+}
+
+// LayoutNamed looks up a layout by its LAYOUTENV name, in any case.
+func LayoutNamed(name string) (Layout, bool) {
+	switch strings.ToLower(name) {
+	case "qwerty":
+		return QWERTY, true
+	case "ijkl":
+		return IJKL, true
+	case "azerty", "zqsd":
+		return AZERTY, true
+	case "numpad", "keypad":
+		return NUMPAD, true
+	}
+	return QWERTY, false
+}
+
+// LayoutFromEnv picks the layout from LAYOUTENV, falling back to AZERTY for
+// French and Belgian locales and QWERTY otherwise.
+func LayoutFromEnv(environ []string) Layout {
+	env := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	if l, ok := LayoutNamed(env[LAYOUTENV]); ok {
+		return l
+	}
+
+	lang := env["LC_ALL"]
+	if lang == "" {
+		lang = env["LANG"]
+	}
+	if strings.HasPrefix(lang, "fr_FR") || strings.HasPrefix(lang, "fr_BE") {
+		return AZERTY
+	}
+	return QWERTY
+}
+
+// MoveKeysFor returns the movement bindings of a layout. Numpad digits and
+// application-mode keypad arrows work in every layout.
+func MoveKeysFor(l Layout) MoveKeys {
+	up, down, left, right := "w", "s", "a", "d"
+	extra := [4]string{"k", "j", "h", "l"}
+	switch l {
+	case IJKL:
+		up, down, left, right = "i", "k", "j", "l"
+		extra = [4]string{}
+	case AZERTY:
+		up, left = "z", "q"
+		extra = [4]string{}
+	case NUMPAD:
+		up, down, left, right = "8", "2", "4", "6"
+		extra = [4]string{}
+	}
+
+	binding := func(arrow, glyph, letter, alt, digit, keypad, desc string) key.Binding {
+		keys := []string{arrow, letter, keypad}
+		if digit != letter {
+			keys = append(keys, digit)
+		}
+		helpKeys := glyph + "/" + letter
+		if alt != "" {
+			keys = append(keys, alt)
+			helpKeys += "/" + alt
+		}
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys, desc))
+	}
+
+	return MoveKeys{
+		Up:    binding("up", "↑", up, extra[0], "8", keypadUp, "up"),
+		Down:  binding("down", "↓", down, extra[1], "2", keypadDown, "down"),
+		Left:  binding("left", "←", left, extra[2], "4", keypadLeft, "left"),
+		Right: binding("right", "→", right, extra[3], "6", keypadRight, "right"),
+	}
+}
+
+func DefaultMoveKeys() MoveKeys {
+	return MoveKeysFor(QWERTY)
+}
+
+func (k MoveKeys) All() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Left, k.Right}
+}
+
+func HelpKey() key.Binding {
+	return key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help"))
+}
+
+func QuitKey() key.Binding {
+	return QuitKeyFor(QWERTY)
+}
+
+// QuitKeyFor moves quit to esc on AZERTY, where q is left.
+func QuitKeyFor(l Layout) key.Binding {
+	if l == AZERTY {
+		return key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "quit"))
+	}
+	return key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit"))
+}
+
+func LayoutKey() key.Binding {
+	return key.NewBinding(key.WithKeys("f2"), key.WithHelp("f2", "keyboard layout"))
+}
+
+// presetActions groups the game actions that presets put on one key, by
+// the names key maps give them in Bindings. A game has at most one action
+// of a group, so they never clash.
+var presetActions = [][]string{
+	{"open", "place", "click", "launch", "flap", "drop", "hold", "shuffle", "direction", "toggle", "hit"},
+	{"flag", "rotate", "run", "buy", "double"},
+	{"undo", "step", "split"},
+	{"next", "rematch", "continue", "roll"},
+	{"restart", "retry", "new-maze"},
+	{"difficulty", "levels", "pattern", "category", "rules"},
+}
+
+// PresetKeys are the keys a layout adds to game actions, by action name:
+// the numpad's other keys for NUMPAD and o, next to IJKL, for IJKL. Pass
+// them to Extend.
+func PresetKeys(l Layout) map[string][]string {
+	var keys [][]string
+	switch l {
+	case IJKL:
+		keys = [][]string{nil, {"o"}}
+	case NUMPAD:
+		keys = [][]string{
+			{"0", keypad0},
+			{"5", keypad5},
+			{".", keypadDot},
+			{"+", keypadPlus},
+			{"-", keypadMinus},
+			{"*", keypadStar},
+		}
+	}
+	extra := make(map[string][]string)
+	for i, k := range keys {
+		for _, name := range presetActions[i] {
+			if len(k) > 0 {
+				extra[name] = k
+			}
+		}
+	}
+	return extra
+}
+
+// Extend adds keys to the named actions, keeping the ones they have, and
+// the first new one to the help label. Unknown actions are ignored.
+func Extend(km Rebindable, extra map[string][]string) {
+	bindings := km.Bindings()
+	for name, keys := range extra {
+		b, ok := bindings[name]
+		if !ok {
+			continue
+		}
+		keys = slices.DeleteFunc(slices.Clone(keys), func(k string) bool { return slices.Contains(b.Keys(), k) })
+		if len(keys) == 0 {
+			continue
+		}
+		b.SetKeys(append(b.Keys(), keys...)...)
+		b.SetHelp(b.Help().Key+"/"+keys[0], b.Help().Desc)
+	}
+}
+
+// Rebindable key maps expose their bindings by action name so players can
+// override them.
+type Rebindable interface {
+	Bindings() map[string]*key.Binding
+}
+
+// Rebind replaces the keys of the named actions. Unknown actions are ignored
+// and the help label is regenerated from the new keys.
+func Rebind(km Rebindable, overrides map[string][]string) {
+	bindings := km.Bindings()
+	for name, keys := range overrides {
+		b, ok := bindings[name]
+		if !ok || len(keys) == 0 {
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+}
+
+// NewHelp builds a help model whose styles derive from base, so it renders
+// with the session's renderer.
+func NewHelp(base lipgloss.Style) help.Model {
+	h := help.New()
+	keyStyle := base.Foreground(lipgloss.Color("7"))
+	descStyle := base.Foreground(lipgloss.Color("8"))
+	h.Styles = help.Styles{
+		ShortKey:       keyStyle,
+		ShortDesc:      descStyle,
+		ShortSeparator: descStyle,
+		Ellipsis:       descStyle,
+		FullKey:        keyStyle,
+		FullDesc:       descStyle,
+		FullSeparator:  descStyle,
+	}
+	return h
+}
+
+// HelpOverlay renders the full help of km in a bordered box.
+func HelpOverlay(h help.Model, km help.KeyMap, style lipgloss.Style) string {
+	return style.Render(lipgloss.JoinVertical(
+		lipgloss.Center,
+		"Controls",
+		"",
+		h.FullHelpView(km.FullHelp()),
+		"",
+		"Press '?' to close",
+	))
+}
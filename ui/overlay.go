@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Overlay draws box across the middle of view, centred, in place of the
+// lines it covers. Those lines are blanked on either side of the box rather
+// than cut, since cutting styled text needs its escape codes parsed.
+func Overlay(view, box string) string {
+	lines := strings.Split(view, "\n")
+	boxed := strings.Split(box, "\n")
+	if len(boxed) > len(lines) {
+		return box
+	}
+	width := lipgloss.Width(view)
+	top := (len(lines) - len(boxed)) / 2
+	for i, l := range boxed {
+		lines[top+i] = lipgloss.PlaceHorizontal(width, lipgloss.Center, l)
+	}
+	return strings.Join(lines, "\n")
+}
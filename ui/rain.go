@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rainGlyphs are what the rain is made of: half-width katakana and digits,
+// one column wide each.
+var rainGlyphs = []rune("ｱｲｳｴｵｶｷｸｹｺｻｼｽｾｿﾀﾁﾂﾃﾄﾅﾆﾇﾈﾉﾊﾋﾌﾍﾎﾏﾐﾑﾒﾓﾔﾕﾖﾗﾘﾙﾚﾛﾜﾝ0123456789")
+
+// rainShades go from a drop's head down its tail.
+var rainShades = []string{"15", "46", "40", "34", "28", "22"}
+
+type drop struct {
+	head   int // row of the head, negative before it shows
+	length int
+	speed  int // rows per step, every other step for 0
+	glyphs []rune
+}
+
+// Rain is a falling-glyphs animation in the style of The Matrix, for
+// screensavers: nothing on screen stays lit for long, so idle terminals
+// don't burn a still image in.
+type Rain struct {
+	width, height int
+	drops         []drop // one per column
+	frame         int
+	rng           *rand.Rand
+	shades        []lipgloss.Style
+}
+
+// NewRain fills width by height with rain drawn with r's styles.
+func NewRain(r *lipgloss.Renderer, width, height int, seed int64) *Rain {
+	rain := &Rain{rng: rand.New(rand.NewSource(seed))}
+	for _, c := range rainShades {
+		rain.shades = append(rain.shades, r.NewStyle().Foreground(lipgloss.Color(c)))
+	}
+	rain.shades[0] = rain.shades[0].Bold(true)
+	rain.Resize(width, height)
+	return rain
+}
+
+// Resize refits the rain to the terminal, keeping the columns it has.
+func (r *Rain) Resize(width, height int) {
+	r.width, r.height = max(0, width), max(0, height)
+	for len(r.drops) < r.width {
+		d := r.newDrop()
+		d.head = r.rng.Intn(max(1, r.height*2)) - r.height
+		r.drops = append(r.drops, d)
+	}
+	r.drops = r.drops[:r.width]
+}
+
+func (r *Rain) newDrop() drop {
+	d := drop{
+		head:   -r.rng.Intn(max(1, r.height)),
+		length: 4 + r.rng.Intn(max(1, r.height/2)),
+		speed:  r.rng.Intn(3),
+	}
+	d.glyphs = make([]rune, r.height)
+	for i := range d.glyphs {
+		d.glyphs[i] = rainGlyphs[r.rng.Intn(len(rainGlyphs))]
+	}
+	return d
+}
+
+// Step moves the rain one frame on: drops fall, a few glyphs change and
+// drops that left the screen start again from the top.
+func (r *Rain) Step() {
+	r.frame++
+	for i := range r.drops {
+		d := &r.drops[i]
+		switch {
+		case d.speed > 0:
+			d.head += d.speed
+		case r.frame%2 == 0:
+			d.head++
+		}
+		if len(d.glyphs) > 0 && r.rng.Intn(4) == 0 {
+			d.glyphs[r.rng.Intn(len(d.glyphs))] = rainGlyphs[r.rng.Intn(len(rainGlyphs))]
+		}
+		if d.head-d.length >= r.height {
+			*d = r.newDrop()
+		}
+	}
+}
+
+func (r *Rain) View() string {
+	var s strings.Builder
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			d := r.drops[x]
+			behind := d.head - y
+			if behind < 0 || behind >= d.length || y >= len(d.glyphs) {
+				s.WriteByte(' ')
+				continue
+			}
+			shade := 0
+			if behind > 0 {
+				shade = 1 + behind*(len(r.shades)-1)/d.length
+			}
+			s.WriteString(r.shades[shade].Render(string(d.glyphs[y])))
+		}
+		if y < r.height-1 {
+			s.WriteByte('\n')
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Every is tea.Every bound to ctx: once the session's context is done the
+// pending tick returns nil instead of firing, so an abandoned program's
+// timers don't outlive it.
+func Every(ctx context.Context, d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		n := time.Now()
+		t := time.NewTimer(n.Truncate(d).Add(d).Sub(n))
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-t.C:
+			return fn(now)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package ui holds the rendering, input and timing components shared by the
+// games.
+package ui
+
+import "strings"
+
+// Viewport is a window into a larger world grid. The camera keeps a target
+// at least Margin cells away from the window's edges and never scrolls past
+// the world's bounds.
+type Viewport struct {
+	X, Y          int // world cell shown at the top-left corner
+	Width, Height int // visible cells
+	WorldWidth    int
+	WorldHeight   int
+	Margin        int
+}
+
+func NewViewport(worldWidth, worldHeight, margin int) Viewport {
+	return Viewport{
+		Width:       worldWidth,
+		Height:      worldHeight,
+		WorldWidth:  worldWidth,
+		WorldHeight: worldHeight,
+		Margin:      margin,
+	}
+}
+
+// Resize sets the visible area, clamped to the world, and keeps the window
+// within bounds.
+func (v *Viewport) Resize(width, height int) {
+	v.Width = max(1, min(width, v.WorldWidth))
+	v.Height = max(1, min(height, v.WorldHeight))
+	v.clamp()
+}
+
+// Scrolling reports whether the world is larger than the window.
+func (v Viewport) Scrolling() bool {
+	return v.Width < v.WorldWidth || v.Height < v.WorldHeight
+}
+
+// Follow moves the camera just enough to keep (x, y) inside the margin.
+func (v *Viewport) Follow(x, y int) {
+	v.X = follow(v.X, x, v.Width, v.Margin)
+	v.Y = follow(v.Y, y, v.Height, v.Margin)
+	v.clamp()
+}
+
+// CenterOn puts (x, y) in the middle of the window.
+func (v *Viewport) CenterOn(x, y int) {
+	v.X = x - v.Width/2
+	v.Y = y - v.Height/2
+	v.clamp()
+}
+
+func (v Viewport) Contains(x, y int) bool {
+	return x >= v.X && x < v.X+v.Width && y >= v.Y && y < v.Y+v.Height
+}
+
+// Render draws the visible window row by row, asking cell for the string of
+// each world cell.
+func (v Viewport) Render(cell func(x, y int) string) string {
+	var s strings.Builder
+	for y := v.Y; y < v.Y+v.Height; y++ {
+		if y > v.Y {
+			s.WriteString("\n")
+		}
+		for x := v.X; x < v.X+v.Width; x++ {
+			s.WriteString(cell(x, y))
+		}
+	}
+	return s.String()
+}
+
+func (v *Viewport) clamp() {
+	v.X = max(0, min(v.X, v.WorldWidth-v.Width))
+	v.Y = max(0, min(v.Y, v.WorldHeight-v.Height))
+}
+
+func follow(offset, target, size, margin int) int {
+	margin = min(margin, (size-1)/2)
+	if target < offset+margin {
+		offset = target - margin
+	}
+	if target > offset+size-1-margin {
+		offset = target - size + 1 + margin
+	}
+	return offset
+}
@@ -0,0 +1,108 @@
+package wasm
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+func instantiateHostModule(ctx context.Context, rt wazero.Runtime) error {
+	_, err := rt.NewHostModuleBuilder(HOSTNAME).
+		NewFunctionBuilder().WithFunc(hostWidth).Export("width").
+		NewFunctionBuilder().WithFunc(hostHeight).Export("height").
+		NewFunctionBuilder().WithFunc(hostClear).Export("clear").
+		NewFunctionBuilder().WithFunc(hostDraw).Export("draw").
+		NewFunctionBuilder().WithFunc(hostInput).Export("input").
+		NewFunctionBuilder().WithFunc(hostLoad).Export("load").
+		NewFunctionBuilder().WithFunc(hostSave).Export("save").
+		Instantiate(ctx)
+	return err
+}
+
+func hostWidth(ctx context.Context) int32 {
+	return int32(current(ctx).width)
+}
+
+func hostHeight(ctx context.Context) int32 {
+	return int32(current(ctx).height)
+}
+
+func hostClear(ctx context.Context) {
+	in := current(ctx)
+	clear(in.cells)
+}
+
+func hostDraw(ctx context.Context, m api.Module, x, y int32, ptr, n uint32, color int32) {
+	in := current(ctx)
+	if x < 0 || y < 0 || int(x) >= in.width || int(y) >= in.height {
+		return
+	}
+	text, ok := m.Memory().Read(ptr, n)
+	if !ok {
+		return
+	}
+	in.cells[int(y)*in.width+int(x)] = cell{text: string(text), color: color}
+}
+
+func hostInput(ctx context.Context, m api.Module, ptr, capacity uint32) int32 {
+	in := current(ctx)
+	if len(in.input) == 0 {
+		return 0
+	}
+	k := in.input[0]
+	if uint32(len(k)) > capacity {
+		// Drop keys the guest can't take so it doesn't spin on them.
+		in.input = in.input[1:]
+		return 0
+	}
+	in.input = in.input[1:]
+	m.Memory().Write(ptr, []byte(k))
+	return int32(len(k))
+}
+
+func hostLoad(ctx context.Context, m api.Module, kptr, klen, ptr, capacity uint32) int32 {
+	in := current(ctx)
+	k, ok := m.Memory().Read(kptr, klen)
+	if !ok || in.game.host.store == nil || in.player == "" {
+		return -1
+	}
+	data, found, err := in.game.host.store.Load(in.game.Info.ID, in.player, string(k))
+	if err != nil {
+		log.Warn("Could not load WASM game blob", "game", in.game.Info.ID, "err", err)
+		return -1
+	}
+	if !found {
+		return -1
+	}
+	if uint32(len(data)) <= capacity {
+		m.Memory().Write(ptr, data)
+	}
+	return int32(len(data))
+}
+
+func hostSave(ctx context.Context, m api.Module, kptr, klen, ptr, n uint32) int32 {
+	in := current(ctx)
+	if in.game.host.store == nil || in.player == "" || n > BLOBSIZE {
+		return -1
+	}
+	k, ok := m.Memory().Read(kptr, klen)
+	if !ok {
+		return -1
+	}
+	data, ok := m.Memory().Read(ptr, n)
+	if !ok {
+		return -1
+	}
+	if !in.saved[string(k)] && len(in.saved) >= BLOBKEYS {
+		return -1
+	}
+	// Read returns a view of guest memory, so the store gets a copy.
+	if err := in.game.host.store.Save(in.game.Info.ID, in.player, string(k), append([]byte(nil), data...)); err != nil {
+		log.Warn("Could not save WASM game blob", "game", in.game.Info.ID, "err", err)
+		return -1
+	}
+	in.saved[string(k)] = true
+	return 0
+}
@@ -0,0 +1,180 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/ui"
+	"github.com/tetratelabs/wazero"
+)
+
+// INPUTQUEUE is how many keys wait for a game that isn't reading them.
+const INPUTQUEUE = 64
+
+type tickMsg time.Time
+
+// Model runs a WASM game for one player.
+type Model struct {
+	ctx      context.Context
+	in       *instance
+	renderer *lipgloss.Renderer
+	err      error
+	done     bool
+}
+
+// Start instantiates g for the player with fingerprint, whose blobs it
+// loads and saves. Anonymous players, with none, have nothing kept. The
+// instance is closed when ctx is done, so pass the session's context.
+func Start(ctx context.Context, g *Game, fingerprint string, width, height int, r *lipgloss.Renderer) (*Model, error) {
+	in := &instance{game: g, player: fingerprint, saved: make(map[string]bool)}
+	in.resize(width, height)
+	if g.host.store != nil && fingerprint != "" {
+		keys, err := g.host.store.Keys(g.Info.ID, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			in.saved[k] = true
+		}
+	}
+
+	callCtx, cancel := context.WithTimeout(context.WithValue(ctx, instanceKey{}, in), g.host.limits.Tick)
+	defer cancel()
+
+	// An empty name lets any number of players run the same game at once.
+	mod, err := g.host.rt.InstantiateModule(callCtx, g.module, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize").
+		WithSysWalltime().
+		WithSysNanotime())
+	if err != nil {
+		return nil, budget(callCtx, err)
+	}
+	in.mod = mod
+	in.tick = mod.ExportedFunction("tick")
+
+	if start := mod.ExportedFunction("start"); start != nil {
+		if _, err := start.Call(callCtx); err != nil {
+			mod.Close(ctx)
+			return nil, budget(callCtx, err)
+		}
+	}
+
+	context.AfterFunc(ctx, func() { mod.Close(context.Background()) })
+	return &Model{ctx: ctx, in: in, renderer: r}, nil
+}
+
+// budget turns a call interrupted by its deadline into ErrTickBudget.
+func budget(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTickBudget
+	}
+	return err
+}
+
+func (in *instance) resize(width, height int) {
+	in.width, in.height = max(1, width), max(1, height)
+	in.cells = make([]cell, in.width*in.height)
+}
+
+func (m *Model) tick() tea.Cmd {
+	return ui.Every(m.ctx, TICK, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m *Model) stop(err error) {
+	m.err = err
+	m.in.mod.Close(context.Background())
+	log.Warn("WASM game stopped", "game", m.in.game.Info.ID, "err", err)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		if m.err != nil || m.done {
+			return m, nil
+		}
+		ctx, cancel := context.WithTimeout(context.WithValue(m.ctx, instanceKey{}, m.in), m.in.game.host.limits.Tick)
+		defer cancel()
+
+		res, err := m.in.tick.Call(ctx)
+		if err != nil {
+			m.stop(budget(ctx, err))
+			return m, nil
+		}
+		if len(res) > 0 && int32(res[0]) != 0 {
+			m.done = true
+			return m, tea.Quit
+		}
+		return m, m.tick()
+	case tea.WindowSizeMsg:
+		m.in.resize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		switch {
+		case m.err != nil:
+			// Any key dismisses the error screen.
+			return m, tea.Quit
+		case msg.Type == tea.KeyCtrlC:
+			// The host always owns ctrl+c, whatever the game does with it.
+			return m, tea.Quit
+		case len(m.in.input) < INPUTQUEUE:
+			m.in.input = append(m.in.input, msg.String())
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) View() string {
+	if m.err != nil {
+		return fmt.Sprintf("%s stopped: %s\n\nPress any key to leave.", m.in.game.Info.Title, m.err)
+	}
+
+	var s strings.Builder
+	for y := range m.in.height {
+		if y > 0 {
+			s.WriteString("\n")
+		}
+		for _, c := range m.in.cells[y*m.in.width : (y+1)*m.in.width] {
+			switch {
+			case c.text == "":
+				s.WriteString(" ")
+			case c.color < 0:
+				s.WriteString(c.text)
+			default:
+				s.WriteString(m.renderer.NewStyle().Foreground(lipgloss.Color(strconv.Itoa(int(c.color)))).Render(c.text))
+			}
+		}
+	}
+	return s.String()
+}
+
+// Stop closes the instance.
+func (m *Model) Stop() {
+	m.in.mod.Close(context.Background())
+}
+
+// MemoryUsage reports the size of the instance's linear memory.
+func (m *Model) MemoryUsage() int64 {
+	if mem := m.in.mod.Memory(); mem != nil {
+		return int64(mem.Size())
+	}
+	return 0
+}
+
+func (m *Model) Name() string {
+	return m.in.game.Info.Title
+}
+
+func (m *Model) Description() string {
+	return m.in.game.Info.Description
+}
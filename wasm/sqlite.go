@@ -0,0 +1,82 @@
+package wasm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS blobs (
+	game   TEXT NOT NULL,
+	player TEXT NOT NULL,
+	key    TEXT NOT NULL,
+	data   BLOB NOT NULL,
+	PRIMARY KEY (game, player, key)
+);
+`
+
+// SQLiteStore keeps blobs in a SQLite database, so saves survive restarts.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it if needed.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create wasm schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Load(game, player, key string) ([]byte, bool, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM blobs WHERE game = ? AND player = ? AND key = ?`,
+		game, player, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *SQLiteStore) Save(game, player, key string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	_, err := s.db.Exec(`INSERT INTO blobs VALUES (?, ?, ?, ?)
+		ON CONFLICT (game, player, key) DO UPDATE SET data = excluded.data`, game, player, key, data)
+	return err
+}
+
+func (s *SQLiteStore) Keys(game, player string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT key FROM blobs WHERE game = ? AND player = ? ORDER BY key`, game, player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
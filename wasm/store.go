@@ -0,0 +1,51 @@
+package wasm
+
+import "sync"
+
+// Store persists the small blobs games save per player, by the fingerprint
+// of their key.
+type Store interface {
+	Load(game, player, key string) (data []byte, found bool, err error)
+	Save(game, player, key string, data []byte) error
+	// Keys lists the keys a player has saved for a game.
+	Keys(game, player string) ([]string, error)
+}
+
+// MemoryStore keeps blobs in memory, for tests.
+type MemoryStore struct {
+	mu    sync.Mutex
+	blobs map[[3]string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blobs: make(map[[3]string][]byte)}
+}
+
+func (s *MemoryStore) Load(game, player, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.blobs[[3]string{game, player, key}]
+	return append([]byte(nil), data...), ok, nil
+}
+
+func (s *MemoryStore) Save(game, player, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[[3]string{game, player, key}] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *MemoryStore) Keys(game, player string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for k := range s.blobs {
+		if k[0] == game && k[1] == player {
+			keys = append(keys, k[2])
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,56 @@
+package wasm
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wasm.db")
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "sqlite": db} {
+		t.Run(name, func(t *testing.T) {
+			if _, found, err := s.Load("g", "SHA256:ana", "best"); found || err != nil {
+				t.Fatalf("Load before a save = %v, %v", found, err)
+			}
+			for _, save := range []struct{ game, player, key, data string }{
+				{"g", "SHA256:ana", "best", "1"},
+				{"g", "SHA256:ana", "best", "42"},
+				{"g", "SHA256:ana", "level", ""},
+				{"g", "SHA256:bob", "best", "7"},
+				{"other", "SHA256:ana", "best", "9"},
+			} {
+				if err := s.Save(save.game, save.player, save.key, []byte(save.data)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if data, found, err := s.Load("g", "SHA256:ana", "best"); !found || err != nil || !bytes.Equal(data, []byte("42")) {
+				t.Errorf("Load = %q, %v, %v, want the last save", data, found, err)
+			}
+			if data, found, _ := s.Load("g", "SHA256:ana", "level"); !found || len(data) != 0 {
+				t.Errorf("Load of an empty blob = %q, %v", data, found)
+			}
+			keys, err := s.Keys("g", "SHA256:ana")
+			slices.Sort(keys)
+			if err != nil || !slices.Equal(keys, []string{"best", "level"}) {
+				t.Errorf("Keys = %v, %v, want the player's own in the game", keys, err)
+			}
+		})
+	}
+
+	db.Close()
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if data, found, err := db.Load("g", "SHA256:ana", "best"); !found || err != nil || string(data) != "42" {
+		t.Errorf("Load after a reopen = %q, %v, %v", data, found, err)
+	}
+}
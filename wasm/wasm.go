@@ -0,0 +1,181 @@
+// Package wasm runs sandboxed community games compiled to WebAssembly inside
+// the server process.
+//
+// A game is a module next to a manifest named after it plus ".json". It
+// exports tick() i32, called every TICK, which returns non-zero once the
+// game is over, and may export start(), called once. It may import WASI,
+// with no filesystem or environment, and the host functions of the "games"
+// module:
+//
+//	width() i32, height() i32                  the screen size in cells
+//	clear()                                    blanks the screen
+//	draw(x, y, ptr, len, color i32)            puts the UTF-8 text at ptr in a cell;
+//	                                           color is a 256-color index or -1
+//	input(ptr, cap i32) i32                    pops the next key name into ptr and returns
+//	                                           its length, or 0 when no key is waiting
+//	load(kptr, klen, ptr, cap i32) i32          copies the player's blob under a key into ptr
+//	                                           and returns its length, or -1 if there is none
+//	save(kptr, klen, ptr, len i32) i32          stores a blob, returning 0, or -1 if it's over
+//	                                           BLOBSIZE, the player has BLOBKEYS already
+//	                                           or they have no key to keep it by
+//
+// Keys are named like bubbletea's KeyMsg.String, e.g. "up" or "a".
+package wasm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	TICK     = 50 * time.Millisecond
+	BLOBSIZE = 4 << 10 // bytes per saved blob
+	BLOBKEYS = 16      // blobs per player and game
+	HOSTNAME = "games" // the host module guests import
+)
+
+// Limits bound what one game instance may use.
+type Limits struct {
+	Memory uint32        // 64KiB pages of linear memory
+	Tick   time.Duration // wall time a single call may take before the game is stopped
+}
+
+var DefaultLimits = Limits{Memory: 256, Tick: 20 * time.Millisecond}
+
+// ErrTickBudget reports a call that ran past Limits.Tick.
+var ErrTickBudget = errors.New("the game took too long to draw a frame")
+
+// Game is a compiled community game.
+type Game struct {
+	Info   games.Info
+	host   *Host
+	module wazero.CompiledModule
+}
+
+// Host owns the runtime that community games are compiled and run in.
+type Host struct {
+	rt     wazero.Runtime
+	limits Limits
+	store  Store
+}
+
+// NewHost creates a runtime enforcing limits. Games persist their blobs in
+// store.
+func NewHost(ctx context.Context, limits Limits, store Store) (*Host, error) {
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(limits.Memory).
+		WithCloseOnContextDone(true))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		rt.Close(ctx)
+		return nil, err
+	}
+	if err := instantiateHostModule(ctx, rt); err != nil {
+		rt.Close(ctx)
+		return nil, err
+	}
+	return &Host{rt: rt, limits: limits, store: store}, nil
+}
+
+func (h *Host) Close(ctx context.Context) error {
+	return h.rt.Close(ctx)
+}
+
+// Load compiles every game in dir. Games that don't load are logged and
+// skipped.
+func (h *Host) Load(ctx context.Context, dir string) ([]*Game, error) {
+	manifests, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*Game
+	for _, path := range manifests {
+		g, err := h.compile(ctx, path)
+		if err != nil {
+			log.Warn("Skipping WASM game", "manifest", path, "err", err)
+			continue
+		}
+		found = append(found, g)
+	}
+	return found, nil
+}
+
+func (h *Host) compile(ctx context.Context, path string) (*Game, error) {
+	info, err := games.LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	code, err := os.ReadFile(strings.TrimSuffix(path, ".json") + ".wasm")
+	if err != nil {
+		return nil, err
+	}
+	module, err := h.rt.CompileModule(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := module.ExportedFunctions()["tick"]; !ok {
+		return nil, errors.New("module doesn't export tick")
+	}
+	return &Game{Info: info, host: h, module: module}, nil
+}
+
+// RegisterDir loads the games in dir into a new host and adds them to the
+// games registry.
+func RegisterDir(ctx context.Context, dir string, limits Limits, store Store) error {
+	h, err := NewHost(ctx, limits, store)
+	if err != nil {
+		return err
+	}
+	found, err := h.Load(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range found {
+		if _, taken := games.Lookup(g.Info.ID); taken {
+			log.Warn("Skipping WASM game with a taken ID", "id", g.Info.ID)
+			continue
+		}
+		games.Register(g.Info, func(env games.Env) (games.Game, error) {
+			return Start(env.Ctx, g, env.Fingerprint, env.Width, env.Height, env.Renderer)
+		})
+		log.Info("Registered WASM game", "id", g.Info.ID)
+	}
+	return nil
+}
+
+// instance is one player's running copy of a game. Host functions find it
+// through the context of the call.
+type instance struct {
+	game   *Game
+	player string // the fingerprint blobs are kept by, empty for none
+	mod    api.Module
+	tick   api.Function
+	width  int
+	height int
+	cells  []cell
+	input  []string
+	saved  map[string]bool
+}
+
+type cell struct {
+	text  string
+	color int32
+}
+
+type instanceKey struct{}
+
+func current(ctx context.Context) *instance {
+	return ctx.Value(instanceKey{}).(*instance)
+}
@@ -0,0 +1,302 @@
+// Package widget serves a game's leaderboard in forms other sites can
+// embed: a self-contained HTML page for an iframe, which keeps itself up to
+// date, and a plain SVG image for places that only take pictures.
+//
+// Widgets are public and may sit on busy pages, so renders are cached for a
+// while and each client may only ask for so many fresh ones.
+package widget
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/throttle"
+)
+
+const (
+	TOP        = 10
+	FRESH      = time.Minute // how long a render is served from the cache
+	CACHED     = 128         // renders kept at most
+	RATEWINDOW = time.Minute // span a client's fresh renders are counted over
+	RATELIMIT  = 30          // fresh renders a client may ask for per window
+
+	// ALLSEASONS is the season parameter that ranks every season together.
+	ALLSEASONS = "all"
+)
+
+// Row is one ranked run, as the JSON variant lists it.
+type Row struct {
+	Rank   int       `json:"rank"`
+	Player string    `json:"player"`
+	Score  int       `json:"score"` // or points, when the board spans sizes
+	At     time.Time `json:"at"`
+}
+
+// Board is a rendered leaderboard.
+type Board struct {
+	Game   string `json:"game"`
+	Title  string `json:"title"`
+	Season string `json:"season"`
+	Points bool   `json:"points"` // whether rows rank by points, see leaderboard.Filter.Combined
+	Rows   []Row  `json:"rows"`
+}
+
+type render struct {
+	body []byte
+	at   time.Time
+}
+
+type server struct {
+	store   leaderboard.Store
+	limiter *throttle.Limiter
+
+	mu    sync.Mutex
+	cache map[string]render
+}
+
+// Handler serves store's leaderboards at GET /widget/<game>.html, .svg and
+// .json. The mode, board and season query parameters narrow the board as
+// leaderboard.Filter does; the season defaults to the current one.
+func Handler(store leaderboard.Store) http.Handler {
+	s := &server{
+		store:   store,
+		limiter: throttle.NewLimiter(RATELIMIT, RATEWINDOW),
+		cache:   make(map[string]render),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /widget/{file}", s.serve)
+	return mux
+}
+
+var contentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".svg":  "image/svg+xml",
+	".json": "application/json",
+}
+
+func (s *server) serve(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	contentType, ok := contentTypes[ext]
+	if !ok {
+		http.Error(w, "widgets come as .html, .svg or .json", http.StatusNotFound)
+		return
+	}
+	info, ok := games.Lookup(strings.TrimSuffix(file, ext))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	q := r.URL.Query()
+	f := leaderboard.Filter{Game: info.ID, Mode: q.Get("mode"), Board: q.Get("board"), Season: q.Get("season")}
+	switch f.Season {
+	case "":
+		f.Season = leaderboard.SeasonOf(now)
+	case ALLSEASONS:
+		f.Season = ""
+	}
+	id := fmt.Sprintf("%s|%s|%s|%s|%s", file, f.Mode, f.Board, f.Season, q.Get("theme"))
+
+	body, ok := s.cached(id, now)
+	if !ok {
+		if !s.limiter.Allow(throttle.Client(r), now) {
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "too many widgets, try again later", http.StatusTooManyRequests)
+			return
+		}
+		b := s.board(info, f)
+		var buf bytes.Buffer
+		var err error
+		switch ext {
+		case ".html":
+			err = page.Execute(&buf, pageData{Board: b, Light: q.Get("theme") == "light", Refresh: REFRESH.Milliseconds()})
+		case ".svg":
+			err = SVG(&buf, b)
+		case ".json":
+			err = json.NewEncoder(&buf).Encode(b)
+		}
+		if err != nil {
+			log.Error("Rendering widget failed", "game", info.ID, "format", ext, "error", err)
+			http.Error(w, "widget failed", http.StatusInternalServerError)
+			return
+		}
+		body = buf.Bytes()
+		s.keep(id, body, now)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(FRESH.Seconds())))
+	// The HTML widget fetches the JSON one from wherever it's embedded.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	_, _ = w.Write(body)
+}
+
+func (s *server) board(info games.Info, f leaderboard.Filter) Board {
+	b := Board{Game: info.ID, Title: info.Title, Season: f.Season, Points: f.Combined()}
+	if b.Season == "" {
+		b.Season = ALLSEASONS
+	}
+	for i, e := range s.store.Top(f, TOP) {
+		score := e.Score
+		if b.Points {
+			score = e.Points
+		}
+		b.Rows = append(b.Rows, Row{Rank: i + 1, Player: e.Player, Score: score, At: e.At})
+	}
+	return b
+}
+
+func (s *server) cached(id string, now time.Time) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.cache[id]
+	if !ok || now.Sub(r.at) > FRESH {
+		return nil, false
+	}
+	return r.body, true
+}
+
+// keep caches a render, dropping the stale ones first and the oldest if
+// the cache is still full.
+func (s *server) keep(id string, body []byte, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, r := range s.cache {
+		if now.Sub(r.at) > FRESH {
+			delete(s.cache, k)
+		}
+	}
+	if len(s.cache) >= CACHED {
+		var oldest string
+		for k, r := range s.cache {
+			if oldest == "" || r.at.Before(s.cache[oldest].at) {
+				oldest = k
+			}
+		}
+		delete(s.cache, oldest)
+	}
+	s.cache[id] = render{body: body, at: now}
+}
+
+// Size of the SVG widget, in pixels.
+const (
+	SVGWIDTH = 320
+	SVGROW   = 22
+)
+
+// SVG renders b as a plain table, for sites that embed images only.
+func SVG(w io.Writer, b Board) error {
+	buf := bufio.NewWriter(w)
+	height := SVGROW * (len(b.Rows) + 2)
+	if len(b.Rows) == 0 {
+		height += SVGROW
+	}
+	text := func(x, y int, anchor, fill, s string) {
+		fmt.Fprintf(buf, `<text x="%d" y="%d" text-anchor="%s" fill="%s">`, x, y, anchor, fill)
+		_ = xml.EscapeText(buf, []byte(s))
+		buf.WriteString(`</text>`)
+	}
+
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d" font-family="ui-monospace,Menlo,Consolas,monospace" font-size="13">`,
+		SVGWIDTH, height)
+	fmt.Fprintf(buf, `<rect width="100%%" height="100%%" rx="8" fill="#1c1c1c"/>`)
+	text(12, SVGROW, "start", "#5fd75f", b.Title)
+	text(SVGWIDTH-12, SVGROW, "end", "#808080", b.Season)
+	for i, r := range b.Rows {
+		y := SVGROW * (i + 2)
+		text(12, y, "start", "#808080", fmt.Sprintf("%2d.", r.Rank))
+		text(44, y, "start", "#d0d0d0", r.Player)
+		text(SVGWIDTH-12, y, "end", "#ffffff", fmt.Sprint(r.Score))
+	}
+	if len(b.Rows) == 0 {
+		text(12, SVGROW*2, "start", "#808080", "No scores yet")
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Flush()
+}
+
+// REFRESH is how often the HTML widget reloads its rows.
+const REFRESH = time.Minute
+
+type pageData struct {
+	Board
+	Light   bool  // theme=light, for pages with a light background
+	Refresh int64 // milliseconds
+}
+
+// page is the HTML widget. It's rendered with its rows so it shows up
+// without a round trip, then keeps them fresh from the JSON variant.
+var page = template.Must(template.New("widget").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} leaderboard</title>
+<style>
+body{margin:0;font:13px ui-monospace,Menlo,Consolas,monospace;background:{{if .Light}}#fafafa{{else}}#1c1c1c{{end}};color:{{if .Light}}#303030{{else}}#d0d0d0{{end}}}
+.board{padding:10px 12px}
+h1{font-size:14px;margin:0 0 8px;color:#5fd75f;display:flex;justify-content:space-between}
+h1 small{color:#808080;font-weight:normal}
+table{width:100%;border-collapse:collapse}
+td{padding:2px 0}
+td.rank{color:#808080;width:3ch}
+td.score{text-align:right;font-weight:bold}
+p{color:#808080;margin:0}
+</style>
+</head>
+<body>
+<div class="board">
+<h1>{{.Title}} <small>{{.Season}}</small></h1>
+<table><tbody id="rows">
+{{- range .Rows}}
+<tr><td class="rank">{{.Rank}}.</td><td>{{.Player}}</td><td class="score">{{.Score}}</td></tr>
+{{- end}}
+</tbody></table>
+{{- if not .Rows}}
+<p id="empty">No scores yet</p>
+{{- end}}
+</div>
+<script>
+(function () {
+  var url = location.href.replace(/\.html(\?|$)/, ".json$1");
+  function cell(row, text, cls) {
+    var td = row.insertCell();
+    td.textContent = text;
+    if (cls) td.className = cls;
+  }
+  function refresh() {
+    fetch(url).then(function (r) { return r.json(); }).then(function (b) {
+      var rows = document.getElementById("rows");
+      rows.textContent = "";
+      (b.rows || []).forEach(function (r) {
+        var tr = rows.insertRow();
+        cell(tr, r.rank + ".", "rank");
+        cell(tr, r.player);
+        cell(tr, r.score, "score");
+      });
+      var empty = document.getElementById("empty");
+      if (empty) empty.hidden = (b.rows || []).length > 0;
+    }).catch(function () {});
+  }
+  setInterval(refresh, {{.Refresh}});
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+type KeyMap struct {
+	ui.MoveKeys
+	Dice   [DICE]key.Binding
+	Hold   key.Binding
+	Roll   key.Binding
+	Score  key.Binding
+	Start  key.Binding
+	Menu   key.Binding
+	Layout key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+
+	layout ui.Layout
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMapFor(ui.QWERTY)
+}
+
+func KeyMapFor(l ui.Layout) KeyMap {
+	k := KeyMap{
+		MoveKeys: ui.MoveKeysFor(l),
+		Dice: [DICE]key.Binding{
+			key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "hold die 1")),
+			key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "hold die 2")),
+			key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "hold die 3")),
+			key.NewBinding(key.WithKeys("4"), key.WithHelp("4", "hold die 4")),
+			key.NewBinding(key.WithKeys("5"), key.WithHelp("5", "hold die 5")),
+		},
+		Hold:   key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "hold")),
+		Roll:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "roll")),
+		Score:  key.NewBinding(key.WithKeys("enter", ui.KEYPADENTER), key.WithHelp("enter", "score/select")),
+		Start:  key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "start game")),
+		Menu:   key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new game")),
+		Layout: ui.LayoutKey(),
+		Help:   ui.HelpKey(),
+		Quit:   ui.QuitKeyFor(l),
+		layout: l,
+	}
+	ui.Extend(&k, ui.PresetKeys(l))
+	return k
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Roll, k.Hold, k.Score, k.Help, k.Quit}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		k.MoveKeys.All(),
+		append([]key.Binding{k.Hold}, k.Dice[:]...),
+		{k.Roll, k.Score, k.Start, k.Menu},
+		{k.Layout, k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) Bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":     &k.Up,
+		"down":   &k.Down,
+		"left":   &k.Left,
+		"right":  &k.Right,
+		"die-1":  &k.Dice[0],
+		"die-2":  &k.Dice[1],
+		"die-3":  &k.Dice[2],
+		"die-4":  &k.Dice[3],
+		"die-5":  &k.Dice[4],
+		"hold":   &k.Hold,
+		"roll":   &k.Roll,
+		"score":  &k.Score,
+		"start":  &k.Start,
+		"menu":   &k.Menu,
+		"layout": &k.Layout,
+		"help":   &k.Help,
+		"quit":   &k.Quit,
+	}
+}
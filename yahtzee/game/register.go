@@ -0,0 +1,44 @@
+package game
+
+import (
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/games"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const GAMENAME = "yahtzee"
+
+var info = games.Info{
+	ID:          GAMENAME,
+	Title:       "Yahtzee",
+	Description: "Roll five dice for the best scorecard, alone or with friends",
+	Category:    games.BOARD,
+	MinPlayers:  1,
+	MaxPlayers:  MAXPLAYERS,
+	Spectating:  true,
+	Rooms:       true,
+	Session:     10 * time.Minute,
+}
+
+func init() {
+	games.Register(info, func(env games.Env) (games.Game, error) {
+		m := NewModel(env.Width, env.Height, env.Renderer, env.Seat())
+		m.SetContext(env.Ctx)
+		m.SetLayout(ui.LayoutFromEnv(env.Environ))
+		m.Scores = env.Scores
+		m.Fingerprint = env.Fingerprint
+		if env.Room != "" {
+			m.SetRoom(env.Room)
+		}
+		return m, nil
+	})
+}
+
+func (m Model) Name() string {
+	return info.Title
+}
+
+func (m Model) Description() string {
+	return info.Description
+}
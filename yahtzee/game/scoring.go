@@ -0,0 +1,156 @@
+package game
+
+import "slices"
+
+const (
+	DICE         = 5
+	SIDES        = 6
+	ROLLS        = 3 // per turn
+	UPPERBONUS   = 35
+	UPPERNEEDED  = 63 // upper section points for the bonus
+	YAHTZEEBONUS = 100
+)
+
+// Category is a box of the scorecard.
+type Category struct {
+	Name  string
+	Score func(dice [DICE]int) int
+}
+
+var categories = [BOXES]Category{
+	{Name: "Ones", Score: upper(1)},
+	{Name: "Twos", Score: upper(2)},
+	{Name: "Threes", Score: upper(3)},
+	{Name: "Fours", Score: upper(4)},
+	{Name: "Fives", Score: upper(5)},
+	{Name: "Sixes", Score: upper(6)},
+	{Name: "Three of a kind", Score: ofAKind(3)},
+	{Name: "Four of a kind", Score: ofAKind(4)},
+	{Name: "Full house", Score: fullHouse},
+	{Name: "Small straight", Score: straight(4, 30)},
+	{Name: "Large straight", Score: straight(5, 40)},
+	{Name: "Yahtzee", Score: yahtzee},
+	{Name: "Chance", Score: sum},
+}
+
+// BOXES is how many categories the scorecard has, UPPER how many of them
+// are in the upper section, and YAHTZEE the index of the Yahtzee box.
+const (
+	BOXES   = 13
+	UPPER   = 6
+	YAHTZEE = 11
+)
+
+func counts(dice [DICE]int) [SIDES + 1]int {
+	var c [SIDES + 1]int
+	for _, d := range dice {
+		c[d]++
+	}
+	return c
+}
+
+func sum(dice [DICE]int) int {
+	total := 0
+	for _, d := range dice {
+		total += d
+	}
+	return total
+}
+
+func upper(face int) func([DICE]int) int {
+	return func(dice [DICE]int) int {
+		return counts(dice)[face] * face
+	}
+}
+
+func ofAKind(n int) func([DICE]int) int {
+	return func(dice [DICE]int) int {
+		c := counts(dice)
+		if slices.Max(c[:]) >= n {
+			return sum(dice)
+		}
+		return 0
+	}
+}
+
+func fullHouse(dice [DICE]int) int {
+	c := counts(dice)
+	if slices.Contains(c[:], 3) && slices.Contains(c[:], 2) {
+		return 25
+	}
+	return 0
+}
+
+// straight scores points for a run of at least n consecutive faces.
+func straight(n, points int) func([DICE]int) int {
+	return func(dice [DICE]int) int {
+		c := counts(dice)
+		run := 0
+		for face := 1; face <= SIDES; face++ {
+			if c[face] == 0 {
+				run = 0
+				continue
+			}
+			if run++; run >= n {
+				return points
+			}
+		}
+		return 0
+	}
+}
+
+func yahtzee(dice [DICE]int) int {
+	c := counts(dice)
+	if slices.Max(c[:]) == DICE {
+		return 50
+	}
+	return 0
+}
+
+// Card is a player's scorecard.
+type Card struct {
+	Scores  [BOXES]int
+	Filled  [BOXES]bool
+	Bonuses int // extra Yahtzees scored after the first
+}
+
+// Fill scores dice in category c. Another Yahtzee, once the Yahtzee box
+// holds 50, earns a bonus on top of whatever box it's scored in.
+func (c *Card) Fill(cat int, dice [DICE]int) bool {
+	if c.Filled[cat] {
+		return false
+	}
+	if yahtzee(dice) > 0 && c.Filled[YAHTZEE] && c.Scores[YAHTZEE] > 0 {
+		c.Bonuses++
+	}
+	c.Scores[cat] = categories[cat].Score(dice)
+	c.Filled[cat] = true
+	return true
+}
+
+func (c Card) Done() bool {
+	return !slices.Contains(c.Filled[:], false)
+}
+
+func (c Card) Upper() int {
+	total := 0
+	for _, s := range c.Scores[:UPPER] {
+		total += s
+	}
+	return total
+}
+
+func (c Card) Bonus() int {
+	if c.Upper() >= UPPERNEEDED {
+		return UPPERBONUS
+	}
+	return 0
+}
+
+func (c Card) Total() int {
+	total := c.Bonus() + c.Bonuses*YAHTZEEBONUS
+	for _, s := range c.Scores {
+		total += s
+	}
+	return total
+}
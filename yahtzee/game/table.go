@@ -0,0 +1,272 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/debemdeboas/games.debem.dev/block"
+)
+
+const (
+	MAXPLAYERS = 4
+	STALE      = 10 * time.Second // players not heard from for this long left
+)
+
+// Table phases
+const (
+	WAITING = iota
+	PLAYING
+	FINISHED
+)
+
+type seat struct {
+	id     int
+	name   string
+	player block.Player
+	card   Card
+	seen   time.Time
+}
+
+// Table is a game of Yahtzee, played in turns around it. Solo games sit at
+// a table of their own; multiplayer tables are shared and, like trivia
+// rooms, only change when a player acts or polls.
+type Table struct {
+	mu     sync.Mutex
+	phase  int
+	seats  []*seat
+	turn   int // index into seats
+	dice   [DICE]int
+	held   [DICE]bool
+	rolls  int // this turn
+	nextID int
+	rng    *rand.Rand
+	room   string // lobby room the table is for, empty for anyone
+}
+
+var (
+	tablesMu sync.Mutex
+	open     []*Table // multiplayer tables still waiting for players
+)
+
+func newTable(now time.Time) *Table {
+	return &Table{phase: WAITING, rng: rand.New(rand.NewSource(now.UnixNano()))}
+}
+
+// solo sits p alone at a new table and starts right away.
+func solo(p block.Player, now time.Time) (*Table, int) {
+	t := newTable(now)
+	id := t.sit(p, now)
+	t.phase = PLAYING
+	return t, id
+}
+
+// join seats p at a multiplayer table of room that hasn't started, opening
+// one if they're all full or have someone p blocked or was blocked by.
+func join(room string, p block.Player, now time.Time) (*Table, int) {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+
+	waiting := open[:0]
+	var table *Table
+	for _, t := range open {
+		t.mu.Lock()
+		t.drop(now)
+		if t.phase == WAITING && len(t.seats) > 0 {
+			waiting = append(waiting, t)
+			if table == nil && t.room == room && len(t.seats) < MAXPLAYERS && !t.apart(p) {
+				table = t
+			}
+		}
+		t.mu.Unlock()
+	}
+	open = waiting
+	if table == nil {
+		table = newTable(now)
+		table.room = room
+		open = append(open, table)
+	}
+
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	return table, table.sit(p, now)
+}
+
+func (t *Table) sit(p block.Player, now time.Time) int {
+	t.nextID++
+	t.seats = append(t.seats, &seat{id: t.nextID, name: p.Label(), player: p, seen: now})
+	return t.nextID
+}
+
+// apart reports whether anyone at the table blocked p or was blocked by
+// them.
+func (t *Table) apart(p block.Player) bool {
+	return slices.ContainsFunc(t.seats, func(s *seat) bool { return block.Apart(s.player, p) })
+}
+
+func (t *Table) find(id int) int {
+	return slices.IndexFunc(t.seats, func(s *seat) bool { return s.id == id })
+}
+
+// drop removes players that went quiet, passing the dice on if it was their
+// turn.
+func (t *Table) drop(now time.Time) {
+	for i := len(t.seats) - 1; i >= 0; i-- {
+		if now.Sub(t.seats[i].seen) > STALE {
+			t.remove(i)
+		}
+	}
+}
+
+func (t *Table) remove(i int) {
+	t.seats = slices.Delete(t.seats, i, i+1)
+	switch {
+	case len(t.seats) == 0:
+		if t.phase == PLAYING {
+			t.phase = FINISHED
+		}
+		return
+	case i < t.turn:
+		t.turn--
+	case i == t.turn:
+		t.turn %= len(t.seats)
+		t.newTurn()
+	}
+	t.checkFinished()
+}
+
+func (t *Table) newTurn() {
+	t.held = [DICE]bool{}
+	t.rolls = 0
+}
+
+// playing reports whether id may act: the game is on and it's their turn.
+func (t *Table) playing(id int) bool {
+	return t.phase == PLAYING && len(t.seats) > 0 && t.seats[t.turn].id == id
+}
+
+// start begins a multiplayer game once at least two players sat down.
+func (t *Table) start(id int) {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.phase != WAITING || len(t.seats) < 2 || t.find(id) < 0 {
+		return
+	}
+	t.phase = PLAYING
+	open = slices.DeleteFunc(open, func(o *Table) bool { return o == t })
+}
+
+// roll rolls every die that isn't held, up to ROLLS times a turn.
+func (t *Table) roll(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.playing(id) || t.rolls >= ROLLS {
+		return
+	}
+	for i := range t.dice {
+		if !t.held[i] {
+			t.dice[i] = t.rng.Intn(SIDES) + 1
+		}
+	}
+	t.rolls++
+}
+
+// hold keeps or releases die i for the next roll.
+func (t *Table) hold(id, i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.playing(id) && t.rolls > 0 && t.rolls < ROLLS {
+		t.held[i] = !t.held[i]
+	}
+}
+
+// score fills a box with the dice and passes the turn.
+func (t *Table) score(id, cat int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.playing(id) || t.rolls == 0 || !t.seats[t.turn].card.Fill(cat, t.dice) {
+		return false
+	}
+	t.turn = (t.turn + 1) % len(t.seats)
+	t.newTurn()
+	t.checkFinished()
+	return true
+}
+
+func (t *Table) checkFinished() {
+	if t.phase != PLAYING {
+		return
+	}
+	for _, s := range t.seats {
+		if !s.card.Done() {
+			return
+		}
+	}
+	t.phase = FINISHED
+}
+
+// opponents are the other players at the table, besides the one with id.
+func (t *Table) opponents(id int) []block.Player {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var ps []block.Player
+	for _, s := range t.seats {
+		if s.id != id {
+			ps = append(ps, s.player)
+		}
+	}
+	return ps
+}
+
+func (t *Table) leave(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if i := t.find(id); i >= 0 {
+		t.remove(i)
+	}
+}
+
+// Player is a seat as sessions render it.
+type Player struct {
+	Name string
+	Card Card
+	You  bool
+}
+
+type Snapshot struct {
+	Phase   int
+	Players []Player
+	Turn    int
+	Dice    [DICE]int
+	Held    [DICE]bool
+	Rolls   int
+	// Yours is whether the player polling may act.
+	Yours bool
+}
+
+// poll marks player id as present, drops the players that left and
+// describes the table.
+func (t *Table) poll(id int, now time.Time) (Snapshot, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := t.find(id)
+	if i < 0 {
+		return Snapshot{}, false
+	}
+	t.seats[i].seen = now
+	t.drop(now)
+
+	s := Snapshot{Phase: t.phase, Turn: t.turn, Dice: t.dice, Held: t.held, Rolls: t.rolls, Yours: t.playing(id)}
+	for _, st := range t.seats {
+		s.Players = append(s.Players, Player{Name: st.name, Card: st.card, You: st.id == id})
+	}
+	return s, true
+}
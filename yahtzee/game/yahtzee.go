@@ -0,0 +1,449 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/debemdeboas/games.debem.dev/block"
+	"github.com/debemdeboas/games.debem.dev/leaderboard"
+	"github.com/debemdeboas/games.debem.dev/ui"
+)
+
+const (
+	POLL      = 250 * time.Millisecond // how often sessions check their table
+	TOPSCORES = 5                      // shown after a solo game
+)
+
+// Modes offered on the menu
+const (
+	SOLO = iota
+	MULTIPLAYER
+)
+
+var modes = []string{"Solo (high scores)", "Multiplayer table"}
+
+type Model struct {
+	Width  int
+	Height int
+
+	// Styles
+	DieStyle    lipgloss.Style
+	HeldStyle   lipgloss.Style
+	CursorStyle lipgloss.Style
+	FilledStyle lipgloss.Style
+	YouStyle    lipgloss.Style
+	QuitStyle   lipgloss.Style
+	BoxStyle    lipgloss.Style
+
+	Keys KeyMap
+	help help.Model
+
+	// Scores, when set, ranks solo games under Player's name and
+	// Fingerprint.
+	Scores      leaderboard.Store
+	Player      block.Player
+	Fingerprint string
+	top         []leaderboard.Entry
+	submitted   bool
+
+	// Room is the lobby room the player came from, if any.
+	Room string
+
+	mode      int
+	table     *Table // nil while choosing a mode
+	id        int
+	met       []block.Player // at the last table left, see Opponents
+	snap      Snapshot
+	dieCursor int
+	catCursor int
+
+	showHelp bool
+
+	ctx context.Context
+}
+
+type pollMsg time.Time
+
+func NewModel(width, height int, r *lipgloss.Renderer, player block.Player) *Model {
+	m := &Model{
+		Width:       width,
+		Height:      height,
+		DieStyle:    r.NewStyle().Padding(0, 1).Border(lipgloss.RoundedBorder()),
+		HeldStyle:   r.NewStyle().Padding(0, 1).Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("11")).Foreground(lipgloss.Color("11")),
+		CursorStyle: r.NewStyle().Reverse(true),
+		FilledStyle: r.NewStyle().Foreground(lipgloss.Color("8")),
+		YouStyle:    r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		QuitStyle:   r.NewStyle().Foreground(lipgloss.Color("8")),
+		BoxStyle: r.NewStyle().
+			Align(lipgloss.Left).
+			Background(lipgloss.Color("#363636")).
+			Padding(1, 3),
+		Keys:   DefaultKeyMap(),
+		Player: player,
+		ctx:    context.Background(),
+	}
+	m.help = ui.NewHelp(m.QuitStyle)
+	return m
+}
+
+// SetContext binds polling to ctx, usually the SSH session's.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetLayout swaps the movement keys for another keyboard layout.
+func (m *Model) SetLayout(l ui.Layout) {
+	m.Keys = KeyMapFor(l)
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m Model) poll() tea.Cmd {
+	return ui.Every(m.ctx, POLL, func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+// Play sits down at a table for mode: a fresh one for solo games, the next
+// multiplayer table to start otherwise.
+func (m *Model) Play(mode int) {
+	now := time.Now()
+	m.mode = mode
+	if mode == SOLO {
+		m.table, m.id = solo(m.Player, now)
+	} else {
+		m.table, m.id = join(m.Room, m.Player, now)
+	}
+	m.top = nil
+	m.submitted = false
+	m.dieCursor, m.catCursor = 0, 0
+	m.refresh(now)
+}
+
+// SetRoom sits down at the multiplayer table of the player's lobby room.
+func (m *Model) SetRoom(room string) {
+	m.Menu()
+	m.Room = room
+	m.Play(MULTIPLAYER)
+}
+
+// Menu leaves the table, if any, to pick a mode again.
+func (m *Model) Menu() {
+	if m.table != nil {
+		m.met = m.table.opponents(m.id)
+		m.table.leave(m.id)
+	}
+	m.table = nil
+	m.snap = Snapshot{}
+}
+
+// Opponents are the players at the table, or at the last one left, for
+// the lobby to report.
+func (m *Model) Opponents() []block.Player {
+	if m.table == nil {
+		return m.met
+	}
+	return m.table.opponents(m.id)
+}
+
+// refresh reads the table, falling back to the menu if it dropped the
+// player.
+func (m *Model) refresh(now time.Time) {
+	if m.table == nil {
+		return
+	}
+	snap, ok := m.table.poll(m.id, now)
+	if !ok {
+		m.Menu()
+		return
+	}
+	m.snap = snap
+	if snap.Phase == FINISHED && m.mode == SOLO && !m.submitted {
+		m.submit()
+	}
+}
+
+func (m Model) scoreKey() leaderboard.Key {
+	return leaderboard.Key{
+		Game:      GAMENAME,
+		Mode:      "solo",
+		Modifiers: leaderboard.NOMODIFIERS,
+		Board:     "standard",
+		Season:    leaderboard.SeasonOf(time.Now()),
+	}
+}
+
+// submit records a finished solo game on the leaderboard.
+func (m *Model) submit() {
+	m.submitted = true
+	if m.Scores == nil || len(m.snap.Players) == 0 {
+		return
+	}
+	k := m.scoreKey()
+	score := m.snap.Players[0].Card.Total()
+	err := m.Scores.Submit(leaderboard.Entry{
+		Key:         k,
+		Player:      m.Player.Name,
+		Fingerprint: m.Fingerprint,
+		Score:       score,
+		Points:      score,
+		At:          time.Now(),
+	})
+	if err != nil {
+		log.Warn("Could not submit score", "err", err)
+	}
+	m.top = m.Scores.Top(leaderboard.Filter(k), TOPSCORES)
+}
+
+func (m *Model) act(f func()) {
+	f()
+	m.refresh(time.Now())
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			m.Menu()
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.Keys.Layout):
+			m.SetLayout(m.Keys.layout.Next())
+		case m.table == nil:
+			m.updateMenu(msg)
+		case key.Matches(msg, m.Keys.Menu):
+			m.Menu()
+		case key.Matches(msg, m.Keys.Start):
+			m.act(func() { m.table.start(m.id) })
+		case key.Matches(msg, m.Keys.Left):
+			m.dieCursor = (m.dieCursor + DICE - 1) % DICE
+		case key.Matches(msg, m.Keys.Right):
+			m.dieCursor = (m.dieCursor + 1) % DICE
+		case key.Matches(msg, m.Keys.Up):
+			m.catCursor = (m.catCursor + BOXES - 1) % BOXES
+		case key.Matches(msg, m.Keys.Down):
+			m.catCursor = (m.catCursor + 1) % BOXES
+		case key.Matches(msg, m.Keys.Hold):
+			m.act(func() { m.table.hold(m.id, m.dieCursor) })
+		case key.Matches(msg, m.Keys.Roll):
+			m.act(func() { m.table.roll(m.id) })
+		case key.Matches(msg, m.Keys.Score):
+			m.act(func() { m.table.score(m.id, m.catCursor) })
+		default:
+			for i, b := range m.Keys.Dice {
+				if key.Matches(msg, b) {
+					m.dieCursor = i
+					m.act(func() { m.table.hold(m.id, i) })
+				}
+			}
+		}
+	case pollMsg:
+		m.refresh(time.Time(msg))
+		return m, m.poll()
+	}
+	return m, nil
+}
+
+func (m *Model) updateMenu(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.Keys.Up):
+		m.mode = max(0, m.mode-1)
+	case key.Matches(msg, m.Keys.Down):
+		m.mode = min(len(modes)-1, m.mode+1)
+	case key.Matches(msg, m.Keys.Score):
+		m.Play(m.mode)
+	}
+}
+
+func (m Model) menuView() string {
+	lines := []string{"How do you want to play?", ""}
+	for i, name := range modes {
+		if i == m.mode {
+			lines = append(lines, "▶ "+m.CursorStyle.Render(name))
+		} else {
+			lines = append(lines, "  "+name)
+		}
+	}
+	return m.BoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (m Model) diceView() string {
+	if m.snap.Rolls == 0 {
+		return m.QuitStyle.Render(fmt.Sprintf("Press '%s' to roll", m.Keys.Roll.Help().Key))
+	}
+	dice := make([]string, DICE)
+	for i, d := range m.snap.Dice {
+		style := m.DieStyle
+		if m.snap.Held[i] {
+			style = m.HeldStyle
+		}
+		label := fmt.Sprint(d)
+		if i == m.dieCursor && m.snap.Yours {
+			label = m.CursorStyle.Render(label)
+		}
+		dice[i] = style.Render(label)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, dice...)
+}
+
+// you is the polling player's card.
+func (m Model) you() Card {
+	for _, p := range m.snap.Players {
+		if p.You {
+			return p.Card
+		}
+	}
+	return Card{}
+}
+
+// cardView lists the player's scorecard, previewing what the dice would
+// score in the boxes still open.
+func (m Model) cardView() string {
+	card := m.you()
+	preview := m.snap.Yours && m.snap.Rolls > 0
+	var s strings.Builder
+	for i, c := range categories {
+		var line string
+		switch {
+		case card.Filled[i]:
+			line = m.FilledStyle.Render(fmt.Sprintf("%-16s %3d", c.Name, card.Scores[i]))
+		case preview:
+			line = fmt.Sprintf("%-16s %3d", c.Name, c.Score(m.snap.Dice))
+		default:
+			line = fmt.Sprintf("%-16s %3s", c.Name, "–")
+		}
+		if i == m.catCursor && m.snap.Yours {
+			line = "▶ " + line
+		} else {
+			line = "  " + line
+		}
+		s.WriteString(line + "\n")
+		if i == UPPER-1 {
+			fmt.Fprintf(&s, "  %-16s %3d\n", fmt.Sprintf("Bonus (%d/%d)", card.Upper(), UPPERNEEDED), card.Bonus())
+		}
+	}
+	if card.Bonuses > 0 {
+		fmt.Fprintf(&s, "  %-16s %3d\n", "Yahtzee bonus", card.Bonuses*YAHTZEEBONUS)
+	}
+	fmt.Fprintf(&s, "\n  %-16s %3d", "Total", card.Total())
+	return s.String()
+}
+
+func (m Model) playersView() string {
+	var s strings.Builder
+	for i, p := range m.snap.Players {
+		mark := "  "
+		if i == m.snap.Turn && m.snap.Phase == PLAYING {
+			mark = "▶ "
+		}
+		line := fmt.Sprintf("%s%-12s %4d", mark, p.Name, p.Card.Total())
+		if p.You {
+			line = m.YouStyle.Render(line)
+		}
+		s.WriteString(line + "\n")
+	}
+	return strings.TrimRight(s.String(), "\n")
+}
+
+func (m Model) topView() string {
+	if len(m.top) == 0 {
+		return ""
+	}
+	lines := []string{"", "Top scores"}
+	for i, e := range m.top {
+		lines = append(lines, fmt.Sprintf("%d. %-12s %4d", i+1, e.Player, e.Score))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) finishedView() string {
+	s := m.snap
+	if m.mode == SOLO {
+		total := 0
+		if len(s.Players) > 0 {
+			total = s.Players[0].Card.Total()
+		}
+		return m.BoxStyle.Render(fmt.Sprintf("Final score: %d\n%s\n\n'%s' for a new game",
+			total, m.topView(), m.Keys.Menu.Help().Key))
+	}
+	if len(s.Players) == 0 {
+		return m.BoxStyle.Render(fmt.Sprintf("Everyone left.\n\n'%s' for a new game", m.Keys.Menu.Help().Key))
+	}
+	winner := s.Players[0]
+	for _, p := range s.Players[1:] {
+		if p.Card.Total() > winner.Card.Total() {
+			winner = p
+		}
+	}
+	return m.BoxStyle.Render(fmt.Sprintf("%s wins!\n\n%s\n\n'%s' for a new game",
+		winner.Name, m.playersView(), m.Keys.Menu.Help().Key))
+}
+
+func (m Model) header() string {
+	s := m.snap
+	switch {
+	case m.table == nil:
+		return "Yahtzee"
+	case s.Phase == WAITING:
+		return fmt.Sprintf("Yahtzee | Waiting for players (%d/%d) | '%s' to start",
+			len(s.Players), MAXPLAYERS, m.Keys.Start.Help().Key)
+	case s.Phase == FINISHED:
+		return "Yahtzee | Game over"
+	case s.Yours:
+		return fmt.Sprintf("Yahtzee | Your turn | Roll %d/%d", s.Rolls, ROLLS)
+	}
+	return fmt.Sprintf("Yahtzee | %s's turn | Roll %d/%d", s.Players[s.Turn].Name, s.Rolls, ROLLS)
+}
+
+func (m Model) View() string {
+	if m.showHelp {
+		return lipgloss.Place(
+			m.Width, m.Height,
+			lipgloss.Center, lipgloss.Center,
+			ui.HelpOverlay(m.help, m.Keys, m.BoxStyle.Foreground(lipgloss.Color("15"))),
+		)
+	}
+
+	var body string
+	switch {
+	case m.table == nil:
+		body = m.menuView()
+	case m.snap.Phase == WAITING:
+		body = m.BoxStyle.Render("Players\n\n" + m.playersView())
+	case m.snap.Phase == FINISHED:
+		body = m.finishedView()
+	default:
+		card := m.BoxStyle.Render(m.cardView())
+		if m.mode == MULTIPLAYER {
+			card = lipgloss.JoinHorizontal(lipgloss.Top, card, "  ", m.BoxStyle.Render("Players\n\n"+m.playersView()))
+		}
+		body = lipgloss.JoinVertical(lipgloss.Center, m.diceView(), "", card)
+	}
+
+	return lipgloss.Place(
+		m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			m.header(),
+			"",
+			body,
+			"",
+			m.help.ShortHelpView(m.Keys.ShortHelp()),
+		),
+	)
+}